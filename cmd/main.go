@@ -14,11 +14,18 @@ import (
 	"github.com/minisource/scheduler/config"
 	_ "github.com/minisource/scheduler/docs" // Swagger docs
 	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/features"
 	"github.com/minisource/scheduler/internal/handler"
+	"github.com/minisource/scheduler/internal/mail"
+	"github.com/minisource/scheduler/internal/middleware"
+	"github.com/minisource/scheduler/internal/notification"
+	"github.com/minisource/scheduler/internal/preflight"
 	"github.com/minisource/scheduler/internal/repository"
 	"github.com/minisource/scheduler/internal/router"
 	"github.com/minisource/scheduler/internal/scheduler"
+	"github.com/minisource/scheduler/internal/secrets"
 	"github.com/minisource/scheduler/internal/service"
+	"github.com/minisource/scheduler/internal/tracing"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -33,7 +40,10 @@ import (
 // @name Authorization
 func main() {
 	// Load configuration
-	cfg := config.LoadConfig()
+	cfg, loadErr := config.Load()
+	if err := preflight.Config(cfg, loadErr); err != nil {
+		log.Fatalf("Preflight failed: %v", err)
+	}
 
 	// Initialize database
 	db, err := database.NewPostgresConnection(&cfg.Postgres)
@@ -47,43 +57,167 @@ func main() {
 		log.Fatalf("Failed to auto-migrate: %v", err)
 	}
 
-	// Initialize Redis
-	redisClient := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
-	})
-	defer redisClient.Close()
-
-	// Test Redis connection
+	// Initialize Redis: standalone, Sentinel or Cluster. A single node runs without it and
+	// leaves the client nil, so locks, rate limits, sessions and dedupe stay in process.
 	ctx := context.Background()
-	if err := redisClient.Ping(ctx).Err(); err != nil {
-		log.Fatalf("Failed to connect to Redis: %v", err)
+	var redisClient redis.UniversalClient
+	var lockNodes []redis.UniversalClient
+	if cfg.Scheduler.SingleNode {
+		log.Printf("Running as a single node without Redis")
+	} else {
+		redisClient = database.NewRedisClient(&cfg.Redis)
+		defer redisClient.Close()
+
+		// Independent Redis primaries for Redlock locks, if configured
+		lockNodes = database.NewRedlockClients(&cfg.Redis)
+		for _, node := range lockNodes {
+			defer node.Close()
+		}
+
+		// Test Redis connection; advisory locks keep the scheduler running without it
+		if err := redisClient.Ping(ctx).Err(); err != nil {
+			if cfg.Locker.Backend != scheduler.LockerBackendPostgres {
+				log.Fatalf("Failed to connect to Redis: %v", err)
+			}
+			log.Printf("Redis is unavailable: %v", err)
+		}
+	}
+
+	// With row-level security, work that spans tenants uses a pool that bypasses it
+	systemDB := db
+	if cfg.Postgres.TenantIsolation == database.TenantIsolationRLS {
+		systemDB, err = database.NewSystemConnection(&cfg.Postgres)
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer database.Close(systemDB)
+	}
+
+	// Check schema version and Redis scripting before anything depends on them
+	if err := preflight.Dependencies(ctx, cfg, db, redisClient, lockNodes); err != nil {
+		log.Fatalf("Preflight failed: %v", err)
+	}
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
 	}
 
 	// Initialize repositories
 	jobRepo := repository.NewJobRepository(db)
 	executionRepo := repository.NewExecutionRepository(db)
 	historyRepo := repository.NewHistoryRepository(db)
+	incidentRepo := repository.NewIncidentRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	tenantRepo := repository.NewTenantRepository(db)
+	secretRepo := repository.NewSecretRepository(db)
+	sealRepo := repository.NewExecutionSealRepository(db)
+	revisionRepo := repository.NewJobRevisionRepository(db)
+	shiftRepo := repository.NewScheduleShiftRepository(db)
+	rolloutRepo := repository.NewRolloutRepository(db)
+	alertRepo := repository.NewAlertRepository(db)
+	callbackRepo := repository.NewCallbackRepository(db)
+	settingsRepo := repository.NewSettingsRepository(db)
+	clusterRepo := repository.NewClusterRepository(db)
+	calendarRepo := repository.NewCalendarRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	admissionRepo := repository.NewAdmissionRepository(db)
+	janitorRepo := repository.NewJanitorRepository(db)
 
 	// Initialize distributed locker
-	workerID := fmt.Sprintf("worker-%s", uuid.New().String()[:8])
-	locker := scheduler.NewDistributedLocker(redisClient, workerID)
+	var locker scheduler.Locker
+	switch {
+	case cfg.Scheduler.SingleNode:
+		locker = scheduler.NewLocalLocker()
+	case cfg.Locker.Backend == scheduler.LockerBackendPostgres:
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("Failed to initialize locker: %v", err)
+		}
+		locker = scheduler.NewAdvisoryLocker(sqlDB)
+	default:
+		workerID := fmt.Sprintf("worker-%s", uuid.New().String()[:8])
+		if len(lockNodes) > 0 {
+			locker = scheduler.NewRedlockLocker(lockNodes, workerID)
+		} else {
+			locker = scheduler.NewDistributedLocker(redisClient, workerID)
+		}
+	}
+
+	// Initialize notification dispatcher
+	notifier := notification.NewDispatcher(cfg.Notifications, cfg.Egress, notificationRepo, incidentRepo, redisClient, mail.NewMailer(cfg.SMTP))
+
+	// Initialize per-tenant rate limits
+	rateLimiter := scheduler.NewRateLimiter(redisClient)
+
+	// Initialize feature flags, overridable at runtime through Redis
+	flags, err := features.New(cfg.Scheduler.FeatureFlags, redisClient)
+	if err != nil {
+		log.Fatalf("Failed to initialize feature flags: %v", err)
+	}
+	tenantService := service.NewTenantService(cfg.Limits, tenantRepo, executionRepo, rateLimiter)
+
+	// Initialize secrets, resolved only by the executor at call time
+	secretCipher, err := secrets.NewCipher(cfg.Secrets)
+	if err != nil {
+		log.Fatalf("Failed to initialize secrets: %v", err)
+	}
+	secretService := service.NewSecretService(secretRepo, secretCipher)
 
 	// Initialize scheduler
-	sched := scheduler.NewScheduler(cfg, jobRepo, executionRepo, historyRepo, locker)
+	sched := scheduler.NewScheduler(cfg, jobRepo, executionRepo, historyRepo, incidentRepo, sealRepo, shiftRepo, rolloutRepo, alertRepo, callbackRepo, settingsRepo, clusterRepo, revisionRepo, calendarRepo, janitorRepo, locker, rateLimiter, flags, tenantService, notifier, secretService)
 
 	// Initialize services
-	jobService := service.NewJobService(jobRepo, sched)
-	executionService := service.NewExecutionService(executionRepo)
-	historyService := service.NewHistoryService(historyRepo)
+	calendarService := service.NewCalendarService(calendarRepo, jobRepo, sched)
+	admissionService := service.NewAdmissionService(cfg.Admission, cfg.Egress, admissionRepo, secretService)
+	jobService := service.NewJobService(jobRepo, executionRepo, historyRepo, revisionRepo, tenantService, calendarService, admissionService, sched)
+	shiftService := service.NewShiftService(shiftRepo, jobRepo, sched)
+	rolloutService := service.NewRolloutService(rolloutRepo, jobRepo, jobService, sched)
+	executionService := service.NewExecutionService(executionRepo, jobRepo, incidentRepo, sealRepo, tenantService, sched)
+	historyService := service.NewHistoryService(historyRepo, tenantService)
+	incidentService := service.NewIncidentService(incidentRepo)
+	notificationService := service.NewNotificationService(notificationRepo, jobRepo, notifier)
+	alertService := service.NewAlertService(alertRepo, notificationRepo, jobRepo)
+	callbackService := service.NewCallbackService(callbackRepo)
+	systemService := service.NewSystemService(cfg, settingsRepo, flags, sched)
+	clusterService := service.NewClusterService(sched)
+	shareService := service.NewShareService(cfg.Sharing, jobRepo, executionRepo)
+	oidcService := service.NewOIDCService(cfg.OIDC)
+	sessionService := service.NewSessionService(cfg.Session, redisClient)
+	tokenService := service.NewTokenService(cfg.Auth)
+	slackService := service.NewSlackService(cfg.Notifications, cfg.Egress, notificationRepo, jobService, executionService, incidentService)
+	auditService := service.NewAuditService(auditRepo, map[string]service.AuditLoader{
+		"jobs": func(ctx context.Context, tenantID, id uuid.UUID) (interface{}, error) {
+			return jobService.GetByID(ctx, tenantID, id)
+		},
+		"calendars": func(ctx context.Context, tenantID, id uuid.UUID) (interface{}, error) {
+			return calendarService.Get(ctx, tenantID, id)
+		},
+	})
 
 	// Initialize handlers
 	handlers := &router.Handlers{
-		Job:       handler.NewJobHandler(jobService),
-		Execution: handler.NewExecutionHandler(executionService),
-		History:   handler.NewHistoryHandler(historyService),
-		Health:    handler.NewHealthHandler(db, sched),
+		Job:          handler.NewJobHandler(jobService),
+		Execution:    handler.NewExecutionHandler(executionService),
+		History:      handler.NewHistoryHandler(historyService),
+		Incident:     handler.NewIncidentHandler(incidentService),
+		Notification: handler.NewNotificationHandler(notificationService),
+		Slack:        handler.NewSlackHandler(slackService),
+		Share:        handler.NewShareHandler(shareService),
+		Auth:         handler.NewAuthHandler(oidcService, sessionService),
+		Tenant:       handler.NewTenantHandler(tenantService, jobService),
+		Secret:       handler.NewSecretHandler(secretService),
+		Shift:        handler.NewShiftHandler(shiftService),
+		Rollout:      handler.NewRolloutHandler(rolloutService),
+		Alert:        handler.NewAlertHandler(alertService),
+		Callback:     handler.NewCallbackHandler(callbackService),
+		Admission:    handler.NewAdmissionHandler(admissionService),
+		Calendar:     handler.NewCalendarHandler(calendarService),
+		Audit:        handler.NewAuditHandler(auditService),
+		System:       handler.NewSystemHandler(systemService),
+		Cluster:      handler.NewClusterHandler(clusterService),
+		Health:       handler.NewHealthHandler(db, sched),
 	}
 
 	// Initialize Fiber app
@@ -92,13 +226,29 @@ func main() {
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  120 * time.Second,
+		BodyLimit:    cfg.Server.BodyLimit,
 	})
 
+	// Initialize middleware
+	middlewares := &router.Middlewares{
+		AccessLog: middleware.AccessLog(cfg.AccessLog),
+		CORS:      middleware.CORS(cfg.CORS),
+		Security:  middleware.SecurityHeaders(cfg.Security),
+		Session:   middleware.Session(sessionService),
+		Token:     middleware.Token(tokenService),
+		Auth:      middleware.Authenticated(tokenService),
+		Actor:     middleware.Actor(),
+		Audit:     middleware.Audit(auditService, systemDB),
+		Tenant:    middleware.TenantScope(db, cfg.Postgres.TenantIsolation),
+		System:    middleware.SystemScope(systemDB, cfg.Postgres.TenantIsolation),
+		Drain:     middleware.SharedToken(cfg.Scheduler.DrainToken),
+	}
+
 	// Setup routes
-	router.SetupRouter(app, handlers)
+	router.SetupRouter(app, handlers, middlewares, sched.ServesAPI())
 
-	// Start scheduler
-	if err := sched.Start(ctx); err != nil {
+	// Start scheduler; its work spans tenants
+	if err := sched.Start(database.WithConn(ctx, systemDB)); err != nil {
 		log.Fatalf("Failed to start scheduler: %v", err)
 	}
 
@@ -118,6 +268,9 @@ func main() {
 
 	log.Println("Shutting down scheduler service...")
 
+	// Let running executions finish, unless a preStop hook already drained the instance
+	sched.Drain(context.Background(), sched.DrainTimeout())
+
 	// Stop scheduler
 	sched.Stop()
 
@@ -129,5 +282,10 @@ func main() {
 		log.Printf("Server shutdown error: %v", err)
 	}
 
+	// Flush pending spans
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Printf("Tracing shutdown error: %v", err)
+	}
+
 	log.Println("Scheduler service stopped")
 }
@@ -1,19 +1,45 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server    ServerConfig
-	Postgres  PostgresConfig
-	Redis     RedisConfig
-	Scheduler SchedulerConfig
-	Tracing   TracingConfig
+	Server        ServerConfig
+	Postgres      PostgresConfig
+	Redis         RedisConfig
+	Locker        LockerConfig
+	Scheduler     SchedulerConfig
+	Egress        EgressConfig
+	Incidents     IncidentConfig
+	Notifications NotificationConfig
+	Callbacks     CallbackConfig
+	Janitor       JanitorConfig
+	Admission     AdmissionConfig
+	Sharing       SharingConfig
+	Secrets       SecretsConfig
+	Kafka         KafkaConfig
+	NATS          NATSConfig
+	AMQP          AMQPConfig
+	Commands      CommandConfig
+	SQL           SQLConfig
+	SMTP          SMTPConfig
+	ObjectStorage ObjectStorageConfig
+	Tracing       TracingConfig
+	Auth          AuthConfig
+	OIDC          OIDCConfig
+	Session       SessionConfig
+	Limits        LimitsConfig
+	CORS          CORSConfig
+	Security      SecurityConfig
+	AccessLog     AccessLogConfig
 }
 
 type ServerConfig struct {
@@ -22,26 +48,40 @@ type ServerConfig struct {
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
+	BodyLimit       int // Largest request body accepted, in bytes
 }
 
 type PostgresConfig struct {
 	Host               string
 	Port               string
 	User               string
-	Password           string
+	Password           string `redact:"true"`
+	UserFile           string // Re-read on every new connection for rotated credentials; overrides User
+	PasswordFile       string // Re-read on every new connection for rotated credentials; overrides Password
 	DBName             string
 	SSLMode            string
 	MaxOpenConns       int
 	MaxIdleConns       int
 	MaxLifetimeMinutes int
 	LogLevel           string
+	TenantIsolation    string // none, or rls to enforce tenant boundaries with row-level security
 }
 
 type RedisConfig struct {
-	Host     string
-	Port     int
-	Password string
-	DB       int
+	Mode             string // standalone, sentinel or cluster
+	Host             string
+	Port             int
+	Addrs            string // Comma-separated host:port of the Sentinels or cluster nodes
+	MasterName       string // Primary monitored by the Sentinels
+	SentinelPassword string `redact:"true"`
+	Password         string `redact:"true"`
+	PasswordFile     string // Re-read on every new connection for rotated credentials; overrides Password
+	DB               int
+	LockNodes        string // Comma-separated host:port of independent primaries for Redlock locks
+}
+
+type LockerConfig struct {
+	Backend string // redis, or postgres for advisory locks that keep working without Redis
 }
 
 type SchedulerConfig struct {
@@ -49,9 +89,140 @@ type SchedulerConfig struct {
 	MaxRetries        int
 	RetryDelaySeconds int
 	LockTTLSeconds    int
-	HeartbeatSeconds  int
+	HeartbeatSeconds  int // How often the leader refreshes its lock
+	LeaderTTLSeconds  int // How long the leader lock outlives the leader's last heartbeat
+	TickIntervalMs    int // How often the scheduling loop looks for due jobs
 	CleanupDays       int
+	PurgeGraceDays    int  // Days a deleted job can be restored before it is purged
+	ExecutionChain    bool // Hash-chain finished executions so tampering can be detected
 	Timezone          string
+	DispatchMode      string // leader, or claim for every instance to dispatch the due jobs it claims
+	Role              string // all, or api, dispatcher or worker to run one part of the service
+	SingleNode        bool   // Run as the only instance, without Redis: locks, rate limits and sessions stay in process
+	WorkerGroup       string // Group of this instance's workers; they only run jobs pinned to it
+	WorkerGroups      string // Comma-separated groups that pin jobs tagged with their name
+	FeatureFlags      string // Comma-separated name=rule feature flags, e.g. claim_dispatch=on
+
+	StartupGateSeconds  int    // Longest /ready waits for recovery and catch-up after startup, 0 to not wait
+	DrainTimeoutSeconds int    // Longest a drain waits for local executions to finish
+	DrainToken          string `redact:"true"` // Bearer token /drain requires; /drain is refused without one
+
+	MisfireThresholdSeconds  int  // How late a run can start before it counts as misfired
+	MaxCatchUpRuns           int  // Cap on missed runs replayed by the fire_all misfire policy
+	MaxPerHost               int  // Executions in flight per target host on an instance, 0 for no limit
+	StuckGraceSeconds        int  // How long past its timeout a running execution is presumed dead
+	MaxJobTimeoutSeconds     int  // Largest timeout a job can set
+	HTTPClientTimeoutSeconds int  // Cap on a single call by the executor's HTTP client, whatever the job's timeout
+	RescheduleStuck          bool // Run timed out stuck executions again while they have retries left
+
+	DBRetryAttempts          int // Attempts per scheduling loop database call
+	DBRetryBackoffMs         int // Backoff before the first retry, doubled after each attempt
+	DBFailureThreshold       int // Failed ticks in a row before dispatch is paused
+	DBCircuitCooldownSeconds int // How long dispatch stays paused before probing the database again
+}
+
+type EgressConfig struct {
+	ProxyURL string `redact:"true"` // http, https or socks5 proxy the executor's HTTP requests go through; HTTP_PROXY and HTTPS_PROXY apply when empty
+	NoProxy  string // Comma-separated hosts, domains and CIDRs reached without the proxy
+	Deny     string // Comma-separated hosts, *.domains, IPs and CIDRs no job may call, whatever its tenant's rules
+}
+
+type IncidentConfig struct {
+	GroupBy      string
+	SampleErrors int
+}
+
+type NotificationConfig struct {
+	DedupeWindowSeconds    int
+	MinFailures            int
+	EscalateAfterMinutes   int
+	EscalationCheckSeconds int
+	AlertCheckSeconds      int // How often not_run alert rules are evaluated
+	TimeoutSeconds         int
+	SlackSigningSecret     string `redact:"true"` // Verifies Slack interaction callbacks
+}
+
+type JanitorConfig struct {
+	ReportHours   int // How often each tenant is notified of its stale jobs, 0 disables the notification
+	OverdueFactor int // Recurring jobs that haven't run in this many schedule periods are stale
+	NotFoundDays  int // Jobs whose endpoint has answered 404 this long are stale
+	PausedDays    int // Paused jobs unchanged this long are stale
+}
+
+type CallbackConfig struct {
+	TimeoutSeconds int // Timeout of each callback request
+	MaxAttempts    int // Attempts before a callback is marked failed
+	PollSeconds    int // How often queued callbacks are delivered
+}
+
+type AdmissionConfig struct {
+	WebhookURL     string // Operator admission webhook called before every tenant's webhooks; none when empty
+	WebhookToken   string `redact:"true"` // Sent to the operator webhook as a bearer token
+	FailurePolicy  string // fail or ignore: whether job changes are refused while the operator webhook is unavailable
+	TimeoutSeconds int    // Timeout of each admission webhook request
+}
+
+type SharingConfig struct {
+	Secret          string `redact:"true"` // Signs public share links; sharing is disabled when empty
+	BaseURL         string // Public base URL used to build share links
+	DefaultTTLHours int
+	MaxTTLHours     int
+}
+
+type SecretsConfig struct {
+	Key     string `redact:"true"` // Base64 AES-256 key encrypting stored secrets; secrets are disabled when no key is set
+	KeyFile string // File holding the base64 key, e.g. mounted from a KMS or secret manager; overrides Key
+}
+
+type KafkaConfig struct {
+	Brokers      string // Comma-separated brokers kafka jobs produce to
+	ClientID     string
+	TLS          bool
+	SASLUsername string // SASL/PLAIN authentication, disabled when empty
+	SASLPassword string `redact:"true"`
+}
+
+type NATSConfig struct {
+	URL        string // Comma-separated server URLs; nats jobs fail while empty
+	ClientName string
+	Username   string // User/password authentication, disabled when empty
+	Password   string `redact:"true"`
+	Token      string `redact:"true"`
+	CredsFile  string // JWT and NKey credentials file, e.g. from NGS or an operator
+}
+
+type AMQPConfig struct {
+	URL        string `redact:"true"` // amqp:// or amqps:// broker URL with credentials and vhost; amqp jobs fail while empty
+	ClientName string
+}
+
+type CommandConfig struct {
+	Allowlist   string // Comma-separated name=/absolute/path pairs command jobs may run; command jobs fail while empty
+	WorkDir     string // Working directory of commands, the service's own when empty
+	OutputBytes int    // Stdout and stderr kept per run, each
+}
+
+type SQLConfig struct {
+	Datasources  string `redact:"true"` // Comma-separated name=postgres://... pairs sql jobs run against
+	Tenants      string // Comma-separated datasource=tenant-id pairs naming the tenants each datasource serves
+	MaxOpenConns int    // Connections kept open per datasource
+}
+
+type SMTPConfig struct {
+	Host     string // Email jobs and email channels are disabled when empty
+	Port     int
+	Username string
+	Password string `redact:"true"`
+	From     string // Sender address, e.g. "Scheduler <scheduler@example.com>"
+	TLS      string // starttls, tls or none
+}
+
+type ObjectStorageConfig struct {
+	Endpoint  string // S3-compatible host[:port]; response streaming is disabled when empty
+	Region    string
+	AccessKey string `redact:"true"`
+	SecretKey string `redact:"true"`
+	UseSSL    bool
 }
 
 type TracingConfig struct {
@@ -61,39 +232,150 @@ type TracingConfig struct {
 	SampleRate  float64
 }
 
+type AuthConfig struct {
+	Mode          string // jwt to require verified bearer tokens, or header to trust X-Tenant-ID and X-User-ID
+	JWTSecret     string `redact:"true"` // Shared key for HS256, HS384 and HS512 tokens
+	JWKSURL       string // Key set for RS*, PS*, ES* and EdDSA tokens, refreshed when a new key ID appears
+	Issuer        string // Required iss claim, not checked when empty
+	Audience      string // Required aud claim, not checked when empty
+	TenantClaim   string // Claim carrying the tenant ID
+	UserClaim     string // Claim carrying the user ID
+	RolesClaim    string // Claim listing the caller's roles
+	DefaultRole   string // Role for tokens listing no known role, empty denies them
+	LeewaySeconds int    // Clock skew tolerated on exp, nbf and iat
+}
+
+type OIDCConfig struct {
+	IssuerURL    string // OIDC login is disabled when empty
+	ClientID     string
+	ClientSecret string `redact:"true"`
+	RedirectURL  string
+	Scopes       string // Comma-separated, "openid" is always requested
+	GroupsClaim  string // ID token claim listing the user's groups
+	TenantClaim  string // ID token claim carrying the user's tenant ID
+	RoleMapping  string // Comma-separated group=role pairs
+	DefaultRole  string // Role for users matching no mapped group, empty denies login
+}
+
+type SessionConfig struct {
+	TTLHours     int
+	CookieSecure bool   // Only send session cookies over HTTPS
+	PostLoginURL string // Where the browser is sent after SSO login
+}
+
+type LimitsConfig struct {
+	MaxPayloadBytes int // Default job payload limit, overridable per tenant
+	MaxHeadersBytes int // Default job headers limit, overridable per tenant
+
+	ExecutionsPerMinute int // Default per-tenant execution rate, 0 is unlimited; overridable per tenant
+
+	TriggersPerMinute    int // Default manual triggers per job per minute, 0 is unlimited; overridable per job
+	TriggerDedupeSeconds int // Default window in which identical manual triggers of a job collapse, 0 disables; overridable per job
+}
+
+type CORSConfig struct {
+	AllowOrigins     string // Comma-separated origins, "*" allows any
+	AllowMethods     string
+	AllowHeaders     string
+	ExposeHeaders    string
+	AllowCredentials bool // Ignored when AllowOrigins is "*"
+	MaxAgeSeconds    int  // How long browsers may cache preflight results, 0 disables
+}
+
+type SecurityConfig struct {
+	HSTSMaxAgeSeconds     int // Strict-Transport-Security max-age on HTTPS responses, 0 disables
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+	FrameOptions          string
+	ReferrerPolicy        string
+	ContentSecurityPolicy string // Empty sends no policy
+}
+
+type AccessLogConfig struct {
+	Enabled     bool
+	Format      string // text or json
+	ScrubParams string // Comma-separated query and path parameter names whose values are redacted
+}
+
+// GroupList returns the worker groups in WORKER_GROUPS
+func (c SchedulerConfig) GroupList() []string {
+	var groups []string
+	for _, group := range strings.Split(c.WorkerGroups, ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// DatasourceTenants returns the tenants each datasource in SQL_DATASOURCES serves, from
+// SQL_DATASOURCE_TENANTS. A datasource without tenants serves none.
+func (c SQLConfig) DatasourceTenants() map[string][]string {
+	tenants := make(map[string][]string)
+	for _, entry := range strings.Split(c.Datasources, ",") {
+		if name, _, ok := strings.Cut(strings.TrimSpace(entry), "="); ok {
+			tenants[strings.TrimSpace(name)] = nil
+		}
+	}
+	for _, entry := range strings.Split(c.Tenants, ",") {
+		name, tenant, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		name = strings.TrimSpace(name)
+		if _, configured := tenants[name]; ok && configured {
+			tenants[name] = append(tenants[name], strings.ToLower(strings.TrimSpace(tenant)))
+		}
+	}
+	return tenants
+}
+
 func LoadConfig() *Config {
 	cfg, _ := Load()
 	return cfg
 }
 
+// Load reads the configuration from the environment. The error lists variables whose values
+// could not be parsed; their defaults are used in the returned config.
 func Load() (*Config, error) {
 	_ = godotenv.Load()
+	invalidEnv = nil
 
-	return &Config{
+	cfg := &Config{
 		Server: ServerConfig{
 			Port:            getEnvInt("SERVER_PORT", 5003),
 			Host:            getEnv("SERVER_HOST", "0.0.0.0"),
 			ReadTimeout:     getDuration("SERVER_READ_TIMEOUT", 30*time.Second),
 			WriteTimeout:    getDuration("SERVER_WRITE_TIMEOUT", 30*time.Second),
 			ShutdownTimeout: getDuration("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
+			BodyLimit:       getEnvInt("SERVER_BODY_LIMIT_BYTES", 4*1024*1024),
 		},
 		Postgres: PostgresConfig{
 			Host:               getEnv("POSTGRES_HOST", "localhost"),
 			Port:               getEnv("POSTGRES_PORT", "5432"),
 			User:               getEnv("POSTGRES_USER", "scheduler_user"),
 			Password:           getEnv("POSTGRES_PASSWORD", "scheduler_password"),
+			UserFile:           getEnv("POSTGRES_USER_FILE", ""),
+			PasswordFile:       getEnv("POSTGRES_PASSWORD_FILE", ""),
 			DBName:             getEnv("POSTGRES_DB", "scheduler_db"),
 			SSLMode:            getEnv("POSTGRES_SSL_MODE", "disable"),
 			MaxOpenConns:       getEnvInt("POSTGRES_MAX_OPEN_CONNS", 25),
 			MaxIdleConns:       getEnvInt("POSTGRES_MAX_IDLE_CONNS", 10),
 			MaxLifetimeMinutes: getEnvInt("POSTGRES_MAX_LIFETIME_MINS", 30),
 			LogLevel:           getEnv("POSTGRES_LOG_LEVEL", "warn"),
+			TenantIsolation:    getEnv("POSTGRES_TENANT_ISOLATION", "none"),
 		},
 		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", "localhost"),
-			Port:     getEnvInt("REDIS_PORT", 6379),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getEnvInt("REDIS_DB", 2),
+			Mode:             getEnv("REDIS_MODE", "standalone"),
+			Host:             getEnv("REDIS_HOST", "localhost"),
+			Port:             getEnvInt("REDIS_PORT", 6379),
+			Addrs:            getEnv("REDIS_ADDRS", ""),
+			MasterName:       getEnv("REDIS_MASTER_NAME", ""),
+			SentinelPassword: getEnv("REDIS_SENTINEL_PASSWORD", ""),
+			Password:         getEnv("REDIS_PASSWORD", ""),
+			PasswordFile:     getEnv("REDIS_PASSWORD_FILE", ""),
+			DB:               getEnvInt("REDIS_DB", 2),
+			LockNodes:        getEnv("REDIS_LOCK_NODES", ""),
+		},
+		Locker: LockerConfig{
+			Backend: getEnv("LOCKER_BACKEND", "redis"),
 		},
 		Scheduler: SchedulerConfig{
 			WorkerCount:       getEnvInt("SCHEDULER_WORKER_COUNT", 10),
@@ -101,8 +383,124 @@ func Load() (*Config, error) {
 			RetryDelaySeconds: getEnvInt("SCHEDULER_RETRY_DELAY_SECONDS", 60),
 			LockTTLSeconds:    getEnvInt("SCHEDULER_LOCK_TTL_SECONDS", 300),
 			HeartbeatSeconds:  getEnvInt("SCHEDULER_HEARTBEAT_SECONDS", 30),
+			LeaderTTLSeconds:  getEnvInt("SCHEDULER_LEADER_TTL_SECONDS", 90),
+			TickIntervalMs:    getEnvInt("SCHEDULER_TICK_INTERVAL_MS", 1000),
 			CleanupDays:       getEnvInt("SCHEDULER_CLEANUP_DAYS", 30),
+			PurgeGraceDays:    getEnvInt("SCHEDULER_PURGE_GRACE_DAYS", 30),
+			ExecutionChain:    getEnvBool("SCHEDULER_EXECUTION_CHAIN", false),
 			Timezone:          getEnv("SCHEDULER_TIMEZONE", "UTC"),
+			DispatchMode:      getEnv("SCHEDULER_DISPATCH_MODE", "claim"),
+			Role:              getEnv("SCHEDULER_ROLE", "all"),
+			SingleNode:        getEnvBool("SCHEDULER_SINGLE_NODE", false),
+			WorkerGroup:       getEnv("WORKER_GROUP", ""),
+			WorkerGroups:      getEnv("WORKER_GROUPS", ""),
+			FeatureFlags:      getEnv("FEATURE_FLAGS", ""),
+
+			StartupGateSeconds:  getEnvInt("SCHEDULER_STARTUP_GATE_SECONDS", 300),
+			DrainTimeoutSeconds: getEnvInt("SCHEDULER_DRAIN_TIMEOUT_SECONDS", 25),
+			DrainToken:          getEnv("SCHEDULER_DRAIN_TOKEN", ""),
+
+			MisfireThresholdSeconds:  getEnvInt("SCHEDULER_MISFIRE_THRESHOLD_SECONDS", 60),
+			MaxCatchUpRuns:           getEnvInt("SCHEDULER_MAX_CATCH_UP_RUNS", 100),
+			MaxPerHost:               getEnvInt("SCHEDULER_MAX_PER_HOST", 0),
+			StuckGraceSeconds:        getEnvInt("SCHEDULER_STUCK_GRACE_SECONDS", 120),
+			MaxJobTimeoutSeconds:     getEnvInt("SCHEDULER_MAX_JOB_TIMEOUT_SECONDS", 3600),
+			HTTPClientTimeoutSeconds: getEnvInt("SCHEDULER_HTTP_CLIENT_TIMEOUT_SECONDS", 3600),
+			RescheduleStuck:          getEnvBool("SCHEDULER_RESCHEDULE_STUCK", false),
+
+			DBRetryAttempts:          getEnvInt("SCHEDULER_DB_RETRY_ATTEMPTS", 3),
+			DBRetryBackoffMs:         getEnvInt("SCHEDULER_DB_RETRY_BACKOFF_MS", 100),
+			DBFailureThreshold:       getEnvInt("SCHEDULER_DB_FAILURE_THRESHOLD", 5),
+			DBCircuitCooldownSeconds: getEnvInt("SCHEDULER_DB_CIRCUIT_COOLDOWN_SECONDS", 30),
+		},
+		Egress: EgressConfig{
+			ProxyURL: getEnv("EGRESS_PROXY_URL", ""),
+			NoProxy:  getEnv("EGRESS_NO_PROXY", ""),
+			Deny:     getEnv("EGRESS_DENY", "169.254.169.254,fd00:ec2::254,metadata.google.internal"),
+		},
+		Incidents: IncidentConfig{
+			GroupBy:      getEnv("INCIDENT_GROUP_BY", "job"),
+			SampleErrors: getEnvInt("INCIDENT_SAMPLE_ERRORS", 5),
+		},
+		Notifications: NotificationConfig{
+			DedupeWindowSeconds:    getEnvInt("NOTIFY_DEDUPE_WINDOW_SECONDS", 900),
+			MinFailures:            getEnvInt("NOTIFY_MIN_FAILURES", 1),
+			EscalateAfterMinutes:   getEnvInt("NOTIFY_ESCALATE_AFTER_MINUTES", 0),
+			EscalationCheckSeconds: getEnvInt("NOTIFY_ESCALATION_CHECK_SECONDS", 60),
+			AlertCheckSeconds:      getEnvInt("NOTIFY_ALERT_CHECK_SECONDS", 60),
+			TimeoutSeconds:         getEnvInt("NOTIFY_TIMEOUT_SECONDS", 10),
+			SlackSigningSecret:     getEnv("SLACK_SIGNING_SECRET", ""),
+		},
+		Callbacks: CallbackConfig{
+			TimeoutSeconds: getEnvInt("CALLBACK_TIMEOUT_SECONDS", 10),
+			MaxAttempts:    getEnvInt("CALLBACK_MAX_ATTEMPTS", 8),
+			PollSeconds:    getEnvInt("CALLBACK_POLL_SECONDS", 5),
+		},
+		Janitor: JanitorConfig{
+			ReportHours:   getEnvInt("JANITOR_REPORT_HOURS", 168),
+			OverdueFactor: getEnvInt("JANITOR_OVERDUE_FACTOR", 5),
+			NotFoundDays:  getEnvInt("JANITOR_NOT_FOUND_DAYS", 30),
+			PausedDays:    getEnvInt("JANITOR_PAUSED_DAYS", 90),
+		},
+		Admission: AdmissionConfig{
+			WebhookURL:     getEnv("ADMISSION_WEBHOOK_URL", ""),
+			WebhookToken:   getEnv("ADMISSION_WEBHOOK_TOKEN", ""),
+			FailurePolicy:  getEnv("ADMISSION_FAILURE_POLICY", "fail"),
+			TimeoutSeconds: getEnvInt("ADMISSION_TIMEOUT_SECONDS", 5),
+		},
+		Sharing: SharingConfig{
+			Secret:          getEnv("SHARE_LINK_SECRET", ""),
+			BaseURL:         getEnv("SHARE_LINK_BASE_URL", "http://localhost:5003"),
+			DefaultTTLHours: getEnvInt("SHARE_LINK_DEFAULT_TTL_HOURS", 72),
+			MaxTTLHours:     getEnvInt("SHARE_LINK_MAX_TTL_HOURS", 720),
+		},
+		Secrets: SecretsConfig{
+			Key:     getEnv("SECRETS_KEY", ""),
+			KeyFile: getEnv("SECRETS_KEY_FILE", ""),
+		},
+		Kafka: KafkaConfig{
+			Brokers:      getEnv("KAFKA_BROKERS", ""),
+			ClientID:     getEnv("KAFKA_CLIENT_ID", "minisource-scheduler"),
+			TLS:          getEnvBool("KAFKA_TLS_ENABLED", false),
+			SASLUsername: getEnv("KAFKA_SASL_USERNAME", ""),
+			SASLPassword: getEnv("KAFKA_SASL_PASSWORD", ""),
+		},
+		NATS: NATSConfig{
+			URL:        getEnv("NATS_URL", ""),
+			ClientName: getEnv("NATS_CLIENT_NAME", "minisource-scheduler"),
+			Username:   getEnv("NATS_USERNAME", ""),
+			Password:   getEnv("NATS_PASSWORD", ""),
+			Token:      getEnv("NATS_TOKEN", ""),
+			CredsFile:  getEnv("NATS_CREDS_FILE", ""),
+		},
+		AMQP: AMQPConfig{
+			URL:        getEnv("AMQP_URL", ""),
+			ClientName: getEnv("AMQP_CLIENT_NAME", "minisource-scheduler"),
+		},
+		Commands: CommandConfig{
+			Allowlist:   getEnv("COMMAND_ALLOWLIST", ""),
+			WorkDir:     getEnv("COMMAND_WORKDIR", ""),
+			OutputBytes: getEnvInt("COMMAND_OUTPUT_BYTES", 65536),
+		},
+		SQL: SQLConfig{
+			Datasources:  getEnv("SQL_DATASOURCES", ""),
+			Tenants:      getEnv("SQL_DATASOURCE_TENANTS", ""),
+			MaxOpenConns: getEnvInt("SQL_MAX_OPEN_CONNS", 2),
+		},
+		SMTP: SMTPConfig{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getEnvInt("SMTP_PORT", 587),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("SMTP_FROM", ""),
+			TLS:      getEnv("SMTP_TLS", "starttls"),
+		},
+		ObjectStorage: ObjectStorageConfig{
+			Endpoint:  getEnv("OBJECT_STORAGE_ENDPOINT", ""),
+			Region:    getEnv("OBJECT_STORAGE_REGION", "us-east-1"),
+			AccessKey: getEnv("OBJECT_STORAGE_ACCESS_KEY", ""),
+			SecretKey: getEnv("OBJECT_STORAGE_SECRET_KEY", ""),
+			UseSSL:    getEnvBool("OBJECT_STORAGE_USE_SSL", true),
 		},
 		Tracing: TracingConfig{
 			Enabled:     getEnvBool("TRACING_ENABLED", true),
@@ -110,7 +508,75 @@ func Load() (*Config, error) {
 			Endpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318"),
 			SampleRate:  getEnvFloat("TRACING_SAMPLE_RATE", 1.0),
 		},
-	}, nil
+		Auth: AuthConfig{
+			Mode:          getEnv("AUTH_MODE", "jwt"),
+			JWTSecret:     getEnv("JWT_SECRET", ""),
+			JWKSURL:       getEnv("JWT_JWKS_URL", ""),
+			Issuer:        getEnv("JWT_ISSUER", ""),
+			Audience:      getEnv("JWT_AUDIENCE", ""),
+			TenantClaim:   getEnv("JWT_TENANT_CLAIM", "tenant_id"),
+			UserClaim:     getEnv("JWT_USER_CLAIM", "user_id"),
+			RolesClaim:    getEnv("JWT_ROLES_CLAIM", "roles"),
+			DefaultRole:   getEnv("JWT_DEFAULT_ROLE", "viewer"),
+			LeewaySeconds: getEnvInt("JWT_LEEWAY_SECONDS", 30),
+		},
+		OIDC: OIDCConfig{
+			IssuerURL:    getEnv("OIDC_ISSUER_URL", ""),
+			ClientID:     getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:  getEnv("OIDC_REDIRECT_URL", "http://localhost:5003/api/v1/auth/oidc/callback"),
+			Scopes:       getEnv("OIDC_SCOPES", "openid,profile,email,groups"),
+			GroupsClaim:  getEnv("OIDC_GROUPS_CLAIM", "groups"),
+			TenantClaim:  getEnv("OIDC_TENANT_CLAIM", "tenant_id"),
+			RoleMapping:  getEnv("OIDC_ROLE_MAPPING", ""),
+			DefaultRole:  getEnv("OIDC_DEFAULT_ROLE", ""),
+		},
+		Session: SessionConfig{
+			TTLHours:     getEnvInt("SESSION_TTL_HOURS", 8),
+			CookieSecure: getEnvBool("SESSION_COOKIE_SECURE", true),
+			PostLoginURL: getEnv("SESSION_POST_LOGIN_URL", "/api/v1/auth/session"),
+		},
+		Limits: LimitsConfig{
+			MaxPayloadBytes: getEnvInt("JOB_MAX_PAYLOAD_BYTES", 256*1024),
+			MaxHeadersBytes: getEnvInt("JOB_MAX_HEADERS_BYTES", 16*1024),
+
+			ExecutionsPerMinute: getEnvInt("TENANT_EXECUTIONS_PER_MINUTE", 0),
+
+			TriggersPerMinute:    getEnvInt("JOB_TRIGGERS_PER_MINUTE", 20),
+			TriggerDedupeSeconds: getEnvInt("JOB_TRIGGER_DEDUPE_SECONDS", 10),
+		},
+		CORS: CORSConfig{
+			AllowOrigins:     getEnv("CORS_ALLOW_ORIGINS", "*"),
+			AllowMethods:     getEnv("CORS_ALLOW_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS"),
+			AllowHeaders:     getEnv("CORS_ALLOW_HEADERS", "Origin,Content-Type,Accept,Authorization,X-Tenant-ID,X-User-ID,X-Request-ID,Idempotency-Key,X-CSRF-Token,If-None-Match,If-Modified-Since"),
+			ExposeHeaders:    getEnv("CORS_EXPOSE_HEADERS", "ETag,Last-Modified,X-Total-Count-Estimated,X-Job-Revision,X-Trigger-Deduplicated,Retry-After"),
+			AllowCredentials: getEnvBool("CORS_ALLOW_CREDENTIALS", false),
+			MaxAgeSeconds:    getEnvInt("CORS_MAX_AGE_SECONDS", 0),
+		},
+		Security: SecurityConfig{
+			HSTSMaxAgeSeconds:     getEnvInt("SECURITY_HSTS_MAX_AGE_SECONDS", 31536000),
+			HSTSIncludeSubdomains: getEnvBool("SECURITY_HSTS_INCLUDE_SUBDOMAINS", true),
+			HSTSPreload:           getEnvBool("SECURITY_HSTS_PRELOAD", false),
+			FrameOptions:          getEnv("SECURITY_FRAME_OPTIONS", "DENY"),
+			ReferrerPolicy:        getEnv("SECURITY_REFERRER_POLICY", "no-referrer"),
+			ContentSecurityPolicy: getEnv("SECURITY_CONTENT_SECURITY_POLICY", ""),
+		},
+		AccessLog: AccessLogConfig{
+			Enabled:     getEnvBool("ACCESS_LOG_ENABLED", true),
+			Format:      getEnv("ACCESS_LOG_FORMAT", "text"),
+			ScrubParams: getEnv("ACCESS_LOG_SCRUB_PARAMS", "token,access_token,id_token,refresh_token,code,state,password,secret,api_key,apikey,key,signature,sig"),
+		},
+	}
+
+	return cfg, errors.Join(invalidEnv...)
+}
+
+// invalidEnv collects variables whose values could not be parsed during Load
+var invalidEnv []error
+
+// invalid records an unparseable variable
+func invalid(key, value, want string) {
+	invalidEnv = append(invalidEnv, fmt.Errorf("%s=%q is not %s", key, value, want))
 }
 
 func getEnv(key, defaultValue string) string {
@@ -125,6 +591,7 @@ func getEnvInt(key string, defaultValue int) int {
 		if intValue, err := strconv.Atoi(value); err == nil {
 			return intValue
 		}
+		invalid(key, value, "an integer")
 	}
 	return defaultValue
 }
@@ -134,6 +601,7 @@ func getEnvBool(key string, defaultValue bool) bool {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
 			return boolValue
 		}
+		invalid(key, value, "a boolean")
 	}
 	return defaultValue
 }
@@ -143,6 +611,7 @@ func getEnvFloat(key string, defaultValue float64) float64 {
 		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
 			return floatValue
 		}
+		invalid(key, value, "a number")
 	}
 	return defaultValue
 }
@@ -152,6 +621,7 @@ func getDuration(key string, defaultValue time.Duration) time.Duration {
 		if duration, err := time.ParseDuration(value); err == nil {
 			return duration
 		}
+		invalid(key, value, "a duration such as 30s")
 	}
 	return defaultValue
 }
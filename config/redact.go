@@ -0,0 +1,61 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// redactedValue replaces secrets that are set in the redacted view
+const redactedValue = "[redacted]"
+
+// Redacted returns the configuration as nested maps with snake_case keys, for display.
+// Fields tagged redact:"true" show whether they are set but never their value.
+func (c *Config) Redacted() map[string]interface{} {
+	return redactStruct(reflect.ValueOf(*c))
+}
+
+// redactStruct converts a config struct, redacting its tagged fields
+func redactStruct(v reflect.Value) map[string]interface{} {
+	out := make(map[string]interface{}, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		value := v.Field(i)
+		key := snakeCase(field.Name)
+
+		switch {
+		case field.Tag.Get("redact") == "true":
+			if value.IsZero() {
+				out[key] = ""
+			} else {
+				out[key] = redactedValue
+			}
+		case value.Kind() == reflect.Struct:
+			out[key] = redactStruct(value)
+		case value.Type() == reflect.TypeOf(time.Duration(0)):
+			out[key] = value.Interface().(time.Duration).String()
+		default:
+			out[key] = value.Interface()
+		}
+	}
+	return out
+}
+
+// snakeCase converts a Go field name such as HTTPClientTimeoutSeconds to
+// http_client_timeout_seconds
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
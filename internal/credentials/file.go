@@ -0,0 +1,60 @@
+package credentials
+
+import (
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// File is a credential read from a file, such as one written by a secret manager agent or a
+// mounted Kubernetes secret. The file is re-read whenever it changes, so new connections pick
+// up rotated credentials without a restart.
+type File struct {
+	path     string
+	fallback string
+
+	mu      sync.Mutex
+	value   string
+	modTime time.Time
+}
+
+// NewFile creates a file credential. With an empty path the fallback is always used.
+func NewFile(path, fallback string) *File {
+	return &File{path: path, fallback: fallback, value: fallback}
+}
+
+// Value returns the current credential. If the file can't be read, for example while it
+// is being replaced, the last value read is kept.
+func (f *File) Value() string {
+	if f.path == "" {
+		return f.fallback
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		log.Printf("credentials: failed to stat %s, keeping the previous value: %v", f.path, err)
+		return f.value
+	}
+	if info.ModTime().Equal(f.modTime) {
+		return f.value
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		log.Printf("credentials: failed to read %s, keeping the previous value: %v", f.path, err)
+		return f.value
+	}
+
+	value := strings.TrimSpace(string(data))
+	if !f.modTime.IsZero() && value != f.value {
+		log.Printf("credentials: %s changed, new connections use the rotated value", f.path)
+	}
+	f.value = value
+	f.modTime = info.ModTime()
+	return f.value
+}
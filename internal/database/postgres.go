@@ -1,20 +1,36 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/credentials"
 	"github.com/minisource/scheduler/internal/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-// NewPostgresConnection creates a new PostgreSQL connection
+// NewPostgresConnection creates a new PostgreSQL connection. With TenantIsolation set to
+// rls, its connections only see rows of the tenant bound with BeginTenant.
 func NewPostgresConnection(cfg *config.PostgresConfig) (*gorm.DB, error) {
+	return open(cfg, cfg.TenantIsolation != TenantIsolationRLS)
+}
+
+// NewSystemConnection creates a PostgreSQL connection that bypasses row-level security, for
+// the scheduler loop and other work that spans tenants
+func NewSystemConnection(cfg *config.PostgresConfig) (*gorm.DB, error) {
+	return open(cfg, true)
+}
+
+// open connects to PostgreSQL; bypassRLS sets app.bypass_rls on every session
+func open(cfg *config.PostgresConfig, bypassRLS bool) (*gorm.DB, error) {
 	dsn := fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=UTC",
 		cfg.Host,
@@ -48,17 +64,31 @@ func NewPostgresConnection(cfg *config.PostgresConfig) (*gorm.DB, error) {
 		),
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	connConfig, err := pgx.ParseConfig(dsn)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, fmt.Errorf("invalid database configuration: %w", err)
+	}
+	if bypassRLS {
+		connConfig.RuntimeParams["options"] = "-c " + bypassSetting + "=on"
 	}
 
-	// Configure connection pool
-	sqlDB, err := db.DB()
+	// Read the user and password for every new connection, so rotated credentials are used
+	// by connections dialed after the rotation, including re-dials after auth failures
+	user := credentials.NewFile(cfg.UserFile, cfg.User)
+	password := credentials.NewFile(cfg.PasswordFile, cfg.Password)
+	sqlDB := stdlib.OpenDB(*connConfig, stdlib.OptionBeforeConnect(func(ctx context.Context, cc *pgx.ConnConfig) error {
+		cc.User = user.Value()
+		cc.Password = password.Value()
+		return nil
+	}))
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), gormConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get underlying db: %w", err)
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Configure connection pool
+
 	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
 	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
 	sqlDB.SetConnMaxLifetime(time.Duration(cfg.MaxLifetimeMinutes) * time.Minute)
@@ -72,6 +102,29 @@ func AutoMigrate(db *gorm.DB) error {
 		&models.Job{},
 		&models.JobExecution{},
 		&models.JobHistory{},
+		&models.Incident{},
+		&models.NotificationChannel{},
+		&models.NotificationPolicy{},
+		&models.NotificationSubscription{},
+		&models.TenantSettings{},
+		&models.Secret{},
+		&models.ExecutionSeal{},
+		&models.JobRevision{},
+		&models.ScheduleShift{},
+		&models.Rollout{},
+		&models.RolloutJob{},
+		&models.AlertRule{},
+		&models.AlertFiring{},
+		&models.CallbackRoute{},
+		&models.CallbackDelivery{},
+		&models.RuntimeSetting{},
+		&models.ConfigChange{},
+		&models.ClusterLeader{},
+		&models.ClusterMember{},
+		&models.Calendar{},
+		&models.AuditLog{},
+		&models.AdmissionWebhook{},
+		&models.JanitorReportState{},
 	)
 }
 
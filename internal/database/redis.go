@@ -0,0 +1,90 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/credentials"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis deployments for REDIS_MODE
+const (
+	RedisModeStandalone = "standalone"
+	RedisModeSentinel   = "sentinel"
+	RedisModeCluster    = "cluster"
+)
+
+// NewRedisClient creates a Redis client for a standalone server, a Sentinel-managed primary
+// that is followed across failovers, or a Redis Cluster. The password is read on every new
+// connection, so rotated passwords apply without a restart, except under Sentinel, where it
+// is read once at startup.
+func NewRedisClient(cfg *config.RedisConfig) redis.UniversalClient {
+	password := credentials.NewFile(cfg.PasswordFile, cfg.Password)
+	provider := func() (string, string) {
+		return "", password.Value()
+	}
+
+	switch cfg.Mode {
+	case RedisModeSentinel:
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.MasterName,
+			SentinelAddrs:    SplitAddrs(cfg.Addrs),
+			SentinelPassword: cfg.SentinelPassword,
+			Password:         password.Value(),
+			DB:               cfg.DB,
+		})
+	case RedisModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:               SplitAddrs(cfg.Addrs),
+			CredentialsProvider: provider,
+		})
+	default:
+		return redis.NewClient(&redis.Options{
+			Addr:                fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+			DB:                  cfg.DB,
+			CredentialsProvider: provider,
+		})
+	}
+}
+
+// NewRedlockClients creates a client for each independent Redis primary in REDIS_LOCK_NODES,
+// which share the Redis password and database
+func NewRedlockClients(cfg *config.RedisConfig) []redis.UniversalClient {
+	password := credentials.NewFile(cfg.PasswordFile, cfg.Password)
+	var clients []redis.UniversalClient
+	for _, addr := range SplitAddrs(cfg.LockNodes) {
+		clients = append(clients, redis.NewClient(&redis.Options{
+			Addr: addr,
+			DB:   cfg.DB,
+			CredentialsProvider: func() (string, string) {
+				return "", password.Value()
+			},
+		}))
+	}
+	return clients
+}
+
+// RedisAddr describes where a Redis client connects, for log and error messages
+func RedisAddr(cfg *config.RedisConfig) string {
+	switch cfg.Mode {
+	case RedisModeSentinel:
+		return fmt.Sprintf("master %q via sentinels %s", cfg.MasterName, cfg.Addrs)
+	case RedisModeCluster:
+		return fmt.Sprintf("cluster %s", cfg.Addrs)
+	default:
+		return fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+	}
+}
+
+// SplitAddrs splits a comma-separated list of host:port addresses
+func SplitAddrs(list string) []string {
+	var addrs []string
+	for _, addr := range strings.Split(list, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// SchemaVersion is the migration the code expects, the highest number in migrations/.
+// Bump it with every new migration.
+const SchemaVersion = 55
+
+// SchemaStatus reads the version recorded by golang-migrate. found is false when the
+// migrations table doesn't exist, e.g. when the schema is managed by AutoMigrate alone.
+func SchemaStatus(db *gorm.DB) (version int, dirty bool, found bool, err error) {
+	if !db.Migrator().HasTable("schema_migrations") {
+		return 0, false, false, nil
+	}
+
+	row := db.Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Row()
+	if err := row.Scan(&version, &dirty); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, true, nil
+		}
+		return 0, false, true, err
+	}
+	return version, dirty, true, nil
+}
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tenant isolation modes for POSTGRES_TENANT_ISOLATION
+const (
+	TenantIsolationNone = "none"
+	TenantIsolationRLS  = "rls"
+)
+
+// Session settings read by the tenant_isolation row-level security policies
+const (
+	tenantSetting = "app.tenant_id"
+	bypassSetting = "app.bypass_rls"
+)
+
+// connKey is the context key of a request's bound connection or tenant transaction
+type connKey struct{}
+
+// TenantTx is a transaction that only sees one tenant's rows
+type TenantTx struct {
+	tx          *gorm.DB
+	mu          sync.Mutex
+	afterCommit []func()
+}
+
+// WithConn returns a context whose repository calls run on conn
+func WithConn(ctx context.Context, conn *gorm.DB) context.Context {
+	return context.WithValue(ctx, connKey{}, conn)
+}
+
+// BindConn binds conn to a request context, such as *fasthttp.RequestCtx, whose Value
+// method reads user values
+func BindConn(c interface{ SetUserValue(key, value any) }, conn *gorm.DB) {
+	c.SetUserValue(connKey{}, conn)
+}
+
+// Conn returns the connection or tenant transaction bound to ctx, or db when none is bound
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	switch bound := ctx.Value(connKey{}).(type) {
+	case *gorm.DB:
+		return bound.WithContext(ctx)
+	case *TenantTx:
+		return bound.tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// AfterCommit runs fn once the tenant transaction bound to ctx commits, or right away when
+// none is bound. Work handed to other goroutines, which can't see uncommitted rows, waits
+// for the commit this way; it is dropped if the transaction rolls back.
+func AfterCommit(ctx context.Context, fn func()) {
+	tx, ok := ctx.Value(connKey{}).(*TenantTx)
+	if !ok {
+		fn()
+		return
+	}
+	tx.mu.Lock()
+	tx.afterCommit = append(tx.afterCommit, fn)
+	tx.mu.Unlock()
+}
+
+// BeginTenant starts a transaction that only sees rows of tenantID, or no rows for uuid.Nil.
+// The setting is local to the transaction, so it never leaks to the next user of the pooled
+// connection.
+func BeginTenant(ctx context.Context, db *gorm.DB, tenantID uuid.UUID) (*TenantTx, error) {
+	tx := db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	value := ""
+	if tenantID != uuid.Nil {
+		value = tenantID.String()
+	}
+	if err := tx.Exec("SELECT set_config(?, ?, true)", tenantSetting, value).Error; err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to bind tenant: %w", err)
+	}
+	return &TenantTx{tx: tx}, nil
+}
+
+// Bind binds the transaction to a request context, like BindConn
+func (t *TenantTx) Bind(c interface{ SetUserValue(key, value any) }) {
+	c.SetUserValue(connKey{}, t)
+}
+
+// Commit commits the transaction and runs the functions registered with AfterCommit
+func (t *TenantTx) Commit() error {
+	if err := t.tx.Commit().Error; err != nil {
+		return err
+	}
+	t.mu.Lock()
+	fns := t.afterCommit
+	t.afterCommit = nil
+	t.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+	return nil
+}
+
+// Rollback rolls the transaction back, discarding functions registered with AfterCommit
+func (t *TenantTx) Rollback() error {
+	t.mu.Lock()
+	t.afterCommit = nil
+	t.mu.Unlock()
+	return t.tx.Rollback().Error
+}
+
+// RoleBypassesRLS reports whether the connected role ignores row-level security, which
+// would silently disable tenant isolation
+func RoleBypassesRLS(db *gorm.DB) (bool, error) {
+	var bypass bool
+	err := db.Raw("SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user").Scan(&bypass).Error
+	return bypass, err
+}
@@ -0,0 +1,230 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"regexp"
+	"strings"
+)
+
+// ErrDenied is returned when egress rules don't let a job's requests reach a destination
+var ErrDenied = errors.New("destination is not allowed by egress rules")
+
+// hostPattern is the syntax of host names in rules, after an optional "*." prefix
+var hostPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*$`)
+
+// Rule matches destinations by host name, by domain with "*.example.com", or by address
+// with an IP or CIDR such as 10.0.0.0/8
+type Rule struct {
+	host   string       // Host name matched exactly
+	suffix string       // Domain whose subdomains match, with its leading dot
+	prefix netip.Prefix // Addresses matched, when valid
+}
+
+// ParseRule reads a rule
+func ParseRule(entry string) (Rule, error) {
+	entry = strings.ToLower(strings.TrimSpace(entry))
+	if prefix, err := netip.ParsePrefix(entry); err == nil {
+		return Rule{prefix: prefix.Masked()}, nil
+	}
+	if addr, err := netip.ParseAddr(entry); err == nil {
+		addr = addr.Unmap()
+		return Rule{prefix: netip.PrefixFrom(addr, addr.BitLen())}, nil
+	}
+	entry = strings.TrimSuffix(entry, ".")
+	if domain, ok := strings.CutPrefix(entry, "*."); ok {
+		if !hostPattern.MatchString(domain) {
+			return Rule{}, fmt.Errorf("invalid egress rule %q", entry)
+		}
+		return Rule{suffix: "." + domain}, nil
+	}
+	if !hostPattern.MatchString(entry) {
+		return Rule{}, fmt.Errorf("invalid egress rule %q: use a host, *.domain, IP or CIDR", entry)
+	}
+	return Rule{host: entry}, nil
+}
+
+// ParseRules reads a list of rules, skipping blank entries
+func ParseRules(entries []string) ([]Rule, error) {
+	var rules []Rule
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		rule, err := ParseRule(entry)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// SplitList splits a comma-separated list of rules, as configured in EGRESS_DENY
+func SplitList(list string) []string {
+	var entries []string
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// matchesName reports whether the rule matches a host name
+func (r Rule) matchesName(name string) bool {
+	return (r.host != "" && r.host == name) || (r.suffix != "" && strings.HasSuffix(name, r.suffix))
+}
+
+// matchesAddr reports whether the rule matches an address
+func (r Rule) matchesAddr(addr netip.Addr) bool {
+	return r.prefix.IsValid() && r.prefix.Contains(addr)
+}
+
+// Policy decides which destinations a tenant's jobs may call. A destination is refused when
+// its host name or any address it resolves to matches a deny rule. When there are allow rules,
+// it must also match one of them by name or by every address it resolves to.
+type Policy struct {
+	Deny  []Rule
+	Allow []Rule
+}
+
+// Empty reports whether the policy has no rules, so every destination is allowed
+func (p *Policy) Empty() bool {
+	return p == nil || (len(p.Deny) == 0 && len(p.Allow) == 0)
+}
+
+// CheckHost checks a destination without resolving it: IP addresses against every rule and
+// host names against the name rules. A name the allow rules only cover by address passes
+// until it is resolved; CheckAddr then decides.
+func (p *Policy) CheckHost(host string) error {
+	if p.Empty() {
+		return nil
+	}
+	if addr, ok := parseAddr(host); ok {
+		return p.CheckAddr(host, addr)
+	}
+	name := normalize(host)
+	for _, rule := range p.Deny {
+		if rule.matchesName(name) {
+			return denied(host)
+		}
+	}
+	if len(p.Allow) == 0 || p.allowsName(name) {
+		return nil
+	}
+	for _, rule := range p.Allow {
+		if rule.prefix.IsValid() {
+			return nil
+		}
+	}
+	return denied(host)
+}
+
+// CheckAddr checks an address a destination resolved to
+func (p *Policy) CheckAddr(host string, addr netip.Addr) error {
+	if p.Empty() {
+		return nil
+	}
+	addr = addr.Unmap().WithZone("")
+	for _, rule := range p.Deny {
+		if rule.matchesAddr(addr) {
+			return deniedAddr(host, addr)
+		}
+	}
+	if len(p.Allow) == 0 || p.allowsName(normalize(host)) {
+		return nil
+	}
+	for _, rule := range p.Allow {
+		if rule.matchesAddr(addr) {
+			return nil
+		}
+	}
+	return deniedAddr(host, addr)
+}
+
+// CheckName checks a destination whose addresses can't be known, such as one only a proxy
+// resolves: beyond CheckHost, a host name must match an allow rule by name
+func (p *Policy) CheckName(host string) error {
+	if err := p.CheckHost(host); err != nil {
+		return err
+	}
+	if _, ok := parseAddr(host); ok || len(p.Allow) == 0 || p.allowsName(normalize(host)) {
+		return nil
+	}
+	return denied(host)
+}
+
+// CheckResolved resolves a destination and checks it and each of its addresses
+func (p *Policy) CheckResolved(ctx context.Context, host string) error {
+	if err := p.CheckHost(host); err != nil || p.Empty() {
+		return err
+	}
+	if _, ok := parseAddr(host); ok {
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if err := p.CheckAddr(host, addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allowsName reports whether an allow rule matches a host name
+func (p *Policy) allowsName(name string) bool {
+	for _, rule := range p.Allow {
+		if rule.matchesName(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAddr reads a host that is an IP address, bracketed or not
+func parseAddr(host string) (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(strings.Trim(host, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap().WithZone(""), true
+}
+
+// normalize lowercases a host name and drops its trailing dot
+func normalize(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// denied wraps ErrDenied with the refused destination
+func denied(destination string) error {
+	return fmt.Errorf("%w: %s", ErrDenied, destination)
+}
+
+// deniedAddr wraps ErrDenied with a refused host and the address it resolved to
+func deniedAddr(host string, addr netip.Addr) error {
+	if literal, ok := parseAddr(host); ok && literal == addr {
+		return denied(host)
+	}
+	return denied(fmt.Sprintf("%s (%s)", host, addr))
+}
+
+// policyKey is the context key of the policy applied to a job's requests
+type policyKey struct{}
+
+// WithPolicy returns a context whose requests are checked against a policy
+func WithPolicy(ctx context.Context, policy *Policy) context.Context {
+	return context.WithValue(ctx, policyKey{}, policy)
+}
+
+// PolicyFrom returns the policy requests made with a context are checked against, if any
+func PolicyFrom(ctx context.Context) *Policy {
+	policy, _ := ctx.Value(policyKey{}).(*Policy)
+	return policy
+}
@@ -0,0 +1,169 @@
+package egress
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		entry   string
+		want    Rule
+		wantErr bool
+	}{
+		{entry: "api.example.com", want: Rule{host: "api.example.com"}},
+		{entry: " API.Example.COM. ", want: Rule{host: "api.example.com"}},
+		{entry: "*.example.com", want: Rule{suffix: ".example.com"}},
+		{entry: "10.1.2.3", want: Rule{prefix: netip.MustParsePrefix("10.1.2.3/32")}},
+		{entry: "::ffff:10.1.2.3", want: Rule{prefix: netip.MustParsePrefix("10.1.2.3/32")}},
+		{entry: "10.1.2.3/8", want: Rule{prefix: netip.MustParsePrefix("10.0.0.0/8")}},
+		{entry: "fd00::/8", want: Rule{prefix: netip.MustParsePrefix("fd00::/8")}},
+		{entry: "*", wantErr: true},
+		{entry: "*.", wantErr: true},
+		{entry: "api.*.com", wantErr: true},
+		{entry: "http://example.com", wantErr: true},
+		{entry: "-example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			got, err := ParseRule(tt.entry)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	assert.Equal(t, []string{"a.com", "10.0.0.0/8"}, SplitList(" a.com, ,10.0.0.0/8 ,"))
+	assert.Empty(t, SplitList(""))
+}
+
+// mustPolicy builds a policy from deny and allow entries
+func mustPolicy(t *testing.T, deny, allow []string) *Policy {
+	t.Helper()
+	denyRules, err := ParseRules(deny)
+	require.NoError(t, err)
+	allowRules, err := ParseRules(allow)
+	require.NoError(t, err)
+	return &Policy{Deny: denyRules, Allow: allowRules}
+}
+
+func TestPolicyCheckHost(t *testing.T) {
+	deny := []string{"169.254.169.254", "metadata.google.internal", "*.internal.example.com", "10.0.0.0/8"}
+
+	tests := []struct {
+		name    string
+		allow   []string
+		host    string
+		allowed bool
+	}{
+		{name: "public host", host: "api.example.com", allowed: true},
+		{name: "denied address", host: "169.254.169.254"},
+		{name: "denied address in a CIDR", host: "10.20.30.40"},
+		{name: "denied IPv4-mapped address", host: "[::ffff:10.0.0.1]"},
+		{name: "denied name", host: "metadata.google.internal"},
+		{name: "denied name in another case with a dot", host: "Metadata.Google.Internal."},
+		{name: "denied subdomain", host: "db.internal.example.com"},
+		{name: "domain itself isn't its subdomain", host: "internal.example.com", allowed: true},
+		{name: "allowed by name", allow: []string{"*.partner.com"}, host: "api.partner.com", allowed: true},
+		{name: "not on the allowlist", allow: []string{"*.partner.com"}, host: "api.example.com"},
+		{name: "deny wins over allow", allow: []string{"*.internal.example.com"}, host: "db.internal.example.com"},
+		{name: "name passes until resolved with an address allowlist", allow: []string{"203.0.113.0/24"}, host: "api.example.com", allowed: true},
+		{name: "address on the allowlist", allow: []string{"203.0.113.0/24"}, host: "203.0.113.9", allowed: true},
+		{name: "address off the allowlist", allow: []string{"203.0.113.0/24"}, host: "198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mustPolicy(t, deny, tt.allow).CheckHost(tt.host)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrDenied)
+			}
+		})
+	}
+}
+
+func TestPolicyCheckAddr(t *testing.T) {
+	tests := []struct {
+		name    string
+		deny    []string
+		allow   []string
+		host    string
+		addr    string
+		allowed bool
+	}{
+		{name: "no rules", host: "example.com", addr: "10.0.0.1", allowed: true},
+		{name: "resolves to a denied address", deny: []string{"10.0.0.0/8"}, host: "example.com", addr: "10.0.0.1"},
+		{name: "resolves to a mapped denied address", deny: []string{"10.0.0.0/8"}, host: "example.com", addr: "::ffff:10.0.0.1"},
+		{name: "resolves to an allowed address", allow: []string{"203.0.113.0/24"}, host: "example.com", addr: "203.0.113.5", allowed: true},
+		{name: "resolves outside the allowlist", allow: []string{"203.0.113.0/24"}, host: "example.com", addr: "198.51.100.1"},
+		{name: "allowed by name", allow: []string{"example.com"}, host: "example.com", addr: "198.51.100.1", allowed: true},
+		{name: "allowed by name but denied address", deny: []string{"198.51.100.0/24"}, allow: []string{"example.com"}, host: "example.com", addr: "198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mustPolicy(t, tt.deny, tt.allow).CheckAddr(tt.host, netip.MustParseAddr(tt.addr))
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrDenied)
+			}
+		})
+	}
+}
+
+func TestPolicyCheckName(t *testing.T) {
+	tests := []struct {
+		name    string
+		allow   []string
+		host    string
+		allowed bool
+	}{
+		{name: "no allowlist", host: "example.com", allowed: true},
+		{name: "allowed by name", allow: []string{"example.com"}, host: "example.com", allowed: true},
+		{name: "only allowed by address", allow: []string{"203.0.113.0/24"}, host: "example.com"},
+		{name: "address literal", allow: []string{"203.0.113.0/24"}, host: "203.0.113.1", allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mustPolicy(t, nil, tt.allow).CheckName(tt.host)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrDenied)
+			}
+		})
+	}
+}
+
+func TestPolicyCheckResolvedLiteral(t *testing.T) {
+	ctx := context.Background()
+	policy := mustPolicy(t, []string{"127.0.0.0/8"}, nil)
+
+	assert.ErrorIs(t, policy.CheckResolved(ctx, "127.0.0.1"), ErrDenied)
+	assert.NoError(t, policy.CheckResolved(ctx, "203.0.113.1"))
+}
+
+func TestEmptyPolicy(t *testing.T) {
+	var policy *Policy
+	assert.True(t, policy.Empty())
+	assert.NoError(t, policy.CheckHost("169.254.169.254"))
+	assert.True(t, (&Policy{}).Empty())
+	assert.Nil(t, PolicyFrom(context.Background()))
+
+	ctx := WithPolicy(context.Background(), &Policy{})
+	assert.NotNil(t, PolicyFrom(ctx))
+}
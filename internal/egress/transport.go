@@ -0,0 +1,177 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/minisource/scheduler/config"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Transport checks requests against the egress policy of their context before sending them,
+// including the requests of redirects. Hosts are resolved and each address checked;
+// connections dialed by a CheckedTransport are checked again at dial time, so a host can't
+// resolve to an allowed address here and a denied one when dialed. When a proxy resolves the
+// host instead, and the host can't be resolved locally, the allow rules must match it by name.
+type Transport struct {
+	Base    http.RoundTripper
+	Proxy   func(*http.Request) (*url.URL, error) // Proxy of the requests, nil when there is none
+	Default *Policy                               // Policy of requests whose context carries none
+}
+
+func (t Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	policy := PolicyFrom(req.Context())
+	if policy == nil && t.Default != nil {
+		// Carried to the dialer, which checks the addresses it dials against it
+		policy = t.Default
+		req = req.WithContext(WithPolicy(req.Context(), policy))
+	}
+	if policy.Empty() {
+		return t.Base.RoundTrip(req)
+	}
+
+	host := req.URL.Hostname()
+	err := policy.CheckResolved(req.Context(), host)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && t.proxied(req) {
+		err = policy.CheckName(host)
+	}
+	if err != nil {
+		closeBody(req)
+		return nil, err
+	}
+	return t.Base.RoundTrip(req)
+}
+
+// proxied reports whether a request is sent through a proxy
+func (t Transport) proxied(req *http.Request) bool {
+	if t.Proxy == nil {
+		return false
+	}
+	proxyURL, err := t.Proxy(req)
+	return err == nil && proxyURL != nil
+}
+
+// closeBody closes the body of a request that won't be sent, as RoundTrip must
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// ProxyFromConfig returns the proxy function of outgoing requests: EGRESS_PROXY_URL with
+// EGRESS_NO_PROXY, or the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment when it is empty.
+// It also returns the proxy addresses, which dial-time checks leave alone.
+func ProxyFromConfig(cfg config.EgressConfig) (func(*http.Request) (*url.URL, error), map[string]bool) {
+	proxyConfig := httpproxy.FromEnvironment()
+	if cfg.ProxyURL != "" {
+		proxyConfig = &httpproxy.Config{HTTPProxy: cfg.ProxyURL, HTTPSProxy: cfg.ProxyURL, NoProxy: cfg.NoProxy}
+	}
+
+	addrs := make(map[string]bool)
+	for _, raw := range []string{proxyConfig.HTTPProxy, proxyConfig.HTTPSProxy} {
+		if raw == "" {
+			continue
+		}
+		if !strings.Contains(raw, "://") {
+			raw = "http://" + raw
+		}
+		if parsed, err := url.Parse(raw); err == nil && parsed.Hostname() != "" {
+			addrs[proxyAddr(parsed)] = true
+		}
+	}
+
+	proxyFunc := proxyConfig.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}, addrs
+}
+
+// proxyAddr returns the host:port a proxy URL is dialed at
+func proxyAddr(proxyURL *url.URL) string {
+	port := proxyURL.Port()
+	if port == "" {
+		switch proxyURL.Scheme {
+		case "https":
+			port = "443"
+		case "socks5", "socks5h":
+			port = "1080"
+		default:
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(proxyURL.Hostname(), port)
+}
+
+// CheckedTransport sets up a transport to send requests through proxy and check the addresses
+// it dials, other than the proxies', against the egress policy of the request
+func CheckedTransport(base *http.Transport, proxy func(*http.Request) (*url.URL, error), proxies map[string]bool) *http.Transport {
+	transport := base.Clone()
+	transport.Proxy = proxy
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if proxies[addr] {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		return DialContext(ctx, dialer, network, addr)
+	}
+	return transport
+}
+
+// DialContext dials addr with dialer, refusing to connect to an address the egress policy of
+// ctx denies. The address is checked once resolved, right before it is dialed.
+func DialContext(ctx context.Context, dialer *net.Dialer, network, addr string) (net.Conn, error) {
+	policy := PolicyFrom(ctx)
+	if policy.Empty() {
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	checked := *dialer
+	checked.Control = func(_, address string, _ syscall.RawConn) error {
+		dialed, err := netip.ParseAddrPort(address)
+		if err != nil {
+			return err
+		}
+		return policy.CheckAddr(host, dialed.Addr())
+	}
+	return checked.DialContext(ctx, network, addr)
+}
+
+// DenyRules reads the service-wide deny rules of EGRESS_DENY, which config validation has
+// checked
+func DenyRules(cfg config.EgressConfig) []Rule {
+	rules, err := ParseRules(SplitList(cfg.Deny))
+	if err != nil {
+		log.Printf("egress: ignoring EGRESS_DENY: %v", err)
+	}
+	return rules
+}
+
+// NewClient returns a client for requests the service sends outside job executions to URLs
+// tenants set, such as notifications, result callbacks and admission webhooks. Like job
+// requests they go through the configured proxy and are checked against the egress policy of
+// their context, or against EGRESS_DENY when their context carries none.
+func NewClient(cfg config.EgressConfig, timeout time.Duration) *http.Client {
+	proxy, proxies := ProxyFromConfig(cfg)
+	return &http.Client{
+		Timeout: timeout,
+		Transport: Transport{
+			Base:    CheckedTransport(http.DefaultTransport.(*http.Transport), proxy, proxies),
+			Proxy:   proxy,
+			Default: &Policy{Deny: DenyRules(cfg)},
+		},
+	}
+}
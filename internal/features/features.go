@@ -0,0 +1,295 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Feature flags gating new scheduling behaviour
+const (
+	// ClaimDispatch dispatches a tenant's due jobs by SKIP LOCKED claiming on every instance
+	// when SCHEDULER_DISPATCH_MODE is claim; other tenants' jobs are dispatched by the leader.
+	ClaimDispatch = "claim_dispatch"
+	// ClaimDispatchShadow compares the jobs the leader dispatches for a tenant with those claim
+	// dispatch would claim at the same moment, without claiming them.
+	ClaimDispatchShadow = "claim_dispatch_shadow"
+)
+
+// overridesKey is the Redis hash holding flag overrides set through the API
+const overridesKey = "scheduler:features"
+
+// Flag describes a feature flag and its rule when neither FEATURE_FLAGS nor an override sets one
+type Flag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     Rule   `json:"default"`
+}
+
+// Flags lists the known feature flags
+var Flags = []Flag{
+	{
+		Name:        ClaimDispatch,
+		Description: "Claim due jobs on every instance with SKIP LOCKED in claim dispatch mode",
+		Default:     Rule{Enabled: true},
+	},
+	{
+		Name:        ClaimDispatchShadow,
+		Description: "Compare the jobs the leader dispatches with those claim dispatch would claim, without claiming them",
+		Default:     Rule{},
+	},
+}
+
+// Rule decides which tenants a flag is on for: every tenant, none, or only those listed
+type Rule struct {
+	Enabled bool        `json:"enabled"`
+	Tenants []uuid.UUID `json:"tenants,omitempty"` // When set, the flag is only on for these tenants
+}
+
+// All reports whether the flag is on for every tenant
+func (r Rule) All() bool {
+	return r.Enabled && len(r.Tenants) == 0
+}
+
+// On reports whether the flag is on for a tenant
+func (r Rule) On(tenantID uuid.UUID) bool {
+	if !r.Enabled {
+		return false
+	}
+	if len(r.Tenants) == 0 {
+		return true
+	}
+	for _, id := range r.Tenants {
+		if id == tenantID {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats a rule the way FEATURE_FLAGS spells it
+func (r Rule) String() string {
+	if !r.Enabled {
+		return "off"
+	}
+	if len(r.Tenants) == 0 {
+		return "on"
+	}
+	ids := make([]string, len(r.Tenants))
+	for i, id := range r.Tenants {
+		ids[i] = id.String()
+	}
+	return strings.Join(ids, "|")
+}
+
+// ParseRule parses "on", "off" or a "|"-separated list of tenant IDs
+func ParseRule(value string) (Rule, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "on", "true":
+		return Rule{Enabled: true}, nil
+	case "off", "false":
+		return Rule{}, nil
+	}
+	rule := Rule{Enabled: true}
+	for _, part := range strings.Split(value, "|") {
+		id, err := uuid.Parse(strings.TrimSpace(part))
+		if err != nil {
+			return Rule{}, fmt.Errorf("%q is not on, off or a list of tenant IDs", value)
+		}
+		rule.Tenants = append(rule.Tenants, id)
+	}
+	return rule, nil
+}
+
+// Parse parses FEATURE_FLAGS, a comma-separated list of name=rule pairs
+func Parse(spec string) (map[string]Rule, error) {
+	rules := make(map[string]Rule)
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok {
+			return nil, fmt.Errorf("feature flag %q has no rule", name)
+		}
+		if Lookup(name) == nil {
+			return nil, fmt.Errorf("unknown feature flag %q", name)
+		}
+		rule, err := ParseRule(value)
+		if err != nil {
+			return nil, fmt.Errorf("feature flag %s: %w", name, err)
+		}
+		rules[name] = rule
+	}
+	return rules, nil
+}
+
+// Lookup finds a known flag by name
+func Lookup(name string) *Flag {
+	for i := range Flags {
+		if Flags[i].Name == name {
+			return &Flags[i]
+		}
+	}
+	return nil
+}
+
+// Status is a flag's rules from each source and the one in effect
+type Status struct {
+	Flag
+	Env       *Rule `json:"env,omitempty"`      // Set by FEATURE_FLAGS
+	Override  *Rule `json:"override,omitempty"` // Set through the API
+	Effective Rule  `json:"effective"`
+}
+
+// Store evaluates feature flags. Rules come from overrides set through the API, then
+// FEATURE_FLAGS, then each flag's default. Overrides are kept in Redis so every instance
+// picks them up on its next sync; while Redis is unavailable the last synced overrides stay
+// in effect. Without a Redis client, on a single node, overrides only last until a restart.
+type Store struct {
+	client    redis.UniversalClient
+	env       map[string]Rule
+	mu        sync.RWMutex
+	overrides map[string]Rule
+}
+
+// New creates a flag store from FEATURE_FLAGS
+func New(spec string, client redis.UniversalClient) (*Store, error) {
+	env, err := Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{
+		client:    client,
+		env:       env,
+		overrides: make(map[string]Rule),
+	}, nil
+}
+
+// Rule returns the rule in effect for a flag
+func (s *Store) Rule(name string) Rule {
+	s.mu.RLock()
+	rule, ok := s.overrides[name]
+	s.mu.RUnlock()
+	if ok {
+		return rule
+	}
+	if rule, ok := s.env[name]; ok {
+		return rule
+	}
+	if flag := Lookup(name); flag != nil {
+		return flag.Default
+	}
+	return Rule{}
+}
+
+// Enabled reports whether a flag is on for a tenant
+func (s *Store) Enabled(name string, tenantID uuid.UUID) bool {
+	return s.Rule(name).On(tenantID)
+}
+
+// List returns every known flag with its rules
+func (s *Store) List() []Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(Flags))
+	for _, flag := range Flags {
+		status := Status{Flag: flag, Effective: flag.Default}
+		if rule, ok := s.env[flag.Name]; ok {
+			status.Env = &rule
+			status.Effective = rule
+		}
+		if rule, ok := s.overrides[flag.Name]; ok {
+			status.Override = &rule
+			status.Effective = rule
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Effective returns the rule in effect for every known flag, formatted as in FEATURE_FLAGS
+func (s *Store) Effective() map[string]string {
+	rules := make(map[string]string, len(Flags))
+	for _, flag := range Flags {
+		rules[flag.Name] = s.Rule(flag.Name).String()
+	}
+	return rules
+}
+
+// Set overrides a flag's rule on every instance
+func (s *Store) Set(ctx context.Context, name string, rule Rule) error {
+	value, err := json.Marshal(rule)
+	if err != nil {
+		return err
+	}
+	if s.client != nil {
+		if err := s.client.HSet(ctx, overridesKey, name, value).Err(); err != nil {
+			return fmt.Errorf("failed to save feature flag override: %w", err)
+		}
+	}
+	s.mu.Lock()
+	s.overrides[name] = rule
+	s.mu.Unlock()
+	return nil
+}
+
+// Clear removes a flag's override, so FEATURE_FLAGS or its default applies again
+func (s *Store) Clear(ctx context.Context, name string) error {
+	if s.client != nil {
+		if err := s.client.HDel(ctx, overridesKey, name).Err(); err != nil {
+			return fmt.Errorf("failed to clear feature flag override: %w", err)
+		}
+	}
+	s.mu.Lock()
+	delete(s.overrides, name)
+	s.mu.Unlock()
+	return nil
+}
+
+// Sync loads the overrides set through the API, logging flags whose rule changed
+func (s *Store) Sync(ctx context.Context) error {
+	if s.client == nil {
+		return nil // Overrides are only set on this instance
+	}
+	values, err := s.client.HGetAll(ctx, overridesKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to load feature flag overrides: %w", err)
+	}
+
+	overrides := make(map[string]Rule, len(values))
+	for name, value := range values {
+		var rule Rule
+		if Lookup(name) == nil || json.Unmarshal([]byte(value), &rule) != nil {
+			continue // Flags removed in this version, or malformed
+		}
+		overrides[name] = rule
+	}
+
+	before := s.Effective()
+	s.mu.Lock()
+	s.overrides = overrides
+	s.mu.Unlock()
+
+	after := s.Effective()
+	names := make([]string, 0, len(after))
+	for name := range after {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if before[name] != after[name] {
+			log.Printf("features: %s changed from %s to %s", name, before[name], after[name])
+		}
+	}
+	return nil
+}
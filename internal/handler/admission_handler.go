@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/service"
+	"gorm.io/gorm"
+)
+
+// AdmissionHandler handles admission webhook HTTP requests
+type AdmissionHandler struct {
+	admissionService *service.AdmissionService
+}
+
+// NewAdmissionHandler creates a new admission handler
+func NewAdmissionHandler(admissionService *service.AdmissionService) *AdmissionHandler {
+	return &AdmissionHandler{
+		admissionService: admissionService,
+	}
+}
+
+// List lists the tenant's admission webhooks
+// @Summary List admission webhooks
+// @Description List the tenant's admission webhooks in the order they are called
+// @Tags admission
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.AdmissionWebhook}
+// @Failure 500 {object} response.Response
+// @Router /api/v1/admission/webhooks [get]
+func (h *AdmissionHandler) List(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	webhooks, err := h.admissionService.List(c.Context(), tenantID)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, webhooks)
+}
+
+// Get retrieves an admission webhook
+// @Summary Get an admission webhook
+// @Description Get an admission webhook
+// @Tags admission
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} response.Response{data=models.AdmissionWebhook}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/admission/webhooks/{id} [get]
+func (h *AdmissionHandler) Get(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid webhook ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	webhook, err := h.admissionService.Get(c.Context(), tenantID, id)
+	if err != nil {
+		return admissionError(c, err)
+	}
+
+	return response.OK(c, webhook)
+}
+
+// Create creates an admission webhook
+// @Summary Create an admission webhook
+// @Description Call a URL with every create and update request for the tenant's jobs; it can reject the request or patch it. Webhooks are called by position, after the operator's. Needs the admin role.
+// @Tags admission
+// @Accept json
+// @Produce json
+// @Param request body models.CreateAdmissionWebhookRequest true "Webhook"
+// @Success 201 {object} response.Response{data=models.AdmissionWebhook}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/admission/webhooks [post]
+func (h *AdmissionHandler) Create(c *fiber.Ctx) error {
+	var req models.CreateAdmissionWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
+	}
+
+	tenantID := getTenantID(c)
+
+	webhook, err := h.admissionService.Create(c.Context(), tenantID, &req)
+	if err != nil {
+		return admissionError(c, err)
+	}
+
+	return response.Created(c, webhook)
+}
+
+// Update updates an admission webhook
+// @Summary Update an admission webhook
+// @Description Update an admission webhook. Needs the admin role.
+// @Tags admission
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param request body models.UpdateAdmissionWebhookRequest true "Webhook update"
+// @Success 200 {object} response.Response{data=models.AdmissionWebhook}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/admission/webhooks/{id} [put]
+func (h *AdmissionHandler) Update(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid webhook ID")
+	}
+
+	var req models.UpdateAdmissionWebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+
+	tenantID := getTenantID(c)
+
+	webhook, err := h.admissionService.Update(c.Context(), tenantID, id, &req)
+	if err != nil {
+		return admissionError(c, err)
+	}
+
+	return response.OK(c, webhook)
+}
+
+// Delete deletes an admission webhook
+// @Summary Delete an admission webhook
+// @Description Delete an admission webhook. Needs the admin role.
+// @Tags admission
+// @Param id path string true "Webhook ID"
+// @Success 204
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/admission/webhooks/{id} [delete]
+func (h *AdmissionHandler) Delete(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid webhook ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	if err := h.admissionService.Delete(c.Context(), tenantID, id); err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.NoContent(c)
+}
+
+// admissionError maps admission service errors to responses
+func admissionError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, "Admission webhook not found")
+	case errors.Is(err, service.ErrInvalidAdmissionWebhook):
+		return response.BadRequest(c, "VALIDATION_ERROR", err.Error())
+	}
+	return response.InternalError(c, err.Error())
+}
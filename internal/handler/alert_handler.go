@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/service"
+	"gorm.io/gorm"
+)
+
+// AlertHandler handles alert rule HTTP requests
+type AlertHandler struct {
+	alertService *service.AlertService
+}
+
+// NewAlertHandler creates a new alert handler
+func NewAlertHandler(alertService *service.AlertService) *AlertHandler {
+	return &AlertHandler{
+		alertService: alertService,
+	}
+}
+
+// List lists the tenant's alert rules
+// @Summary List alert rules
+// @Description List the tenant's alert rules
+// @Tags alerts
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.AlertRule}
+// @Failure 500 {object} response.Response
+// @Router /api/v1/alerts [get]
+func (h *AlertHandler) List(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	rules, err := h.alertService.ListRules(c.Context(), tenantID)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, rules)
+}
+
+// ListFiring lists the tenant's firing alerts
+// @Summary List firing alerts
+// @Description List the rules currently firing and the jobs they fire for
+// @Tags alerts
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.AlertFiring}
+// @Failure 500 {object} response.Response
+// @Router /api/v1/alerts/firing [get]
+func (h *AlertHandler) ListFiring(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	firings, err := h.alertService.ListFiring(c.Context(), tenantID)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, firings)
+}
+
+// Get retrieves an alert rule
+// @Summary Get an alert rule
+// @Description Get an alert rule
+// @Tags alerts
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} response.Response{data=models.AlertRule}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/alerts/{id} [get]
+func (h *AlertHandler) Get(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid rule ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	rule, err := h.alertService.GetRule(c.Context(), tenantID, id)
+	if err != nil {
+		return alertError(c, err)
+	}
+
+	return response.OK(c, rule)
+}
+
+// Create creates an alert rule
+// @Summary Create an alert rule
+// @Description Alert channels when a job, or jobs matching a tag selector, fail a number of times in a row (consecutive_failures) or haven't run for a number of minutes (not_run)
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param request body models.CreateAlertRuleRequest true "Rule"
+// @Success 201 {object} response.Response{data=models.AlertRule}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/alerts [post]
+func (h *AlertHandler) Create(c *fiber.Ctx) error {
+	var req models.CreateAlertRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
+	}
+
+	tenantID := getTenantID(c)
+
+	rule, err := h.alertService.CreateRule(c.Context(), tenantID, &req)
+	if err != nil {
+		return alertError(c, err)
+	}
+
+	return response.Created(c, rule)
+}
+
+// Update updates an alert rule
+// @Summary Update an alert rule
+// @Description Update an alert rule's name, threshold, window, channels or enabled flag
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param request body models.UpdateAlertRuleRequest true "Rule update"
+// @Success 200 {object} response.Response{data=models.AlertRule}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/alerts/{id} [put]
+func (h *AlertHandler) Update(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid rule ID")
+	}
+
+	var req models.UpdateAlertRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+
+	tenantID := getTenantID(c)
+
+	rule, err := h.alertService.UpdateRule(c.Context(), tenantID, id, &req)
+	if err != nil {
+		return alertError(c, err)
+	}
+
+	return response.OK(c, rule)
+}
+
+// Delete deletes an alert rule
+// @Summary Delete an alert rule
+// @Description Delete an alert rule; firing alerts are dropped without a resolve notification
+// @Tags alerts
+// @Param id path string true "Rule ID"
+// @Success 204
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/alerts/{id} [delete]
+func (h *AlertHandler) Delete(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid rule ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	if err := h.alertService.DeleteRule(c.Context(), tenantID, id); err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.NoContent(c)
+}
+
+// alertError maps alert service errors to responses
+func alertError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, "Alert rule not found")
+	case errors.Is(err, service.ErrInvalidAlertRule):
+		return response.BadRequest(c, "VALIDATION_ERROR", err.Error())
+	}
+	return response.InternalError(c, err.Error())
+}
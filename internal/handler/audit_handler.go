@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/service"
+)
+
+// AuditHandler handles audit log HTTP requests
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// List lists audit log entries with filtering
+// @Summary List audit log entries
+// @Description List the tenant's mutating API calls, newest first, with the acting user, the route, the entity acted on and, for jobs and calendars, the entity before and after the call. Needs the admin role.
+// @Tags audit
+// @Produce json
+// @Param entity_type query string false "Filter by entity type, e.g. jobs"
+// @Param entity_id query string false "Filter by entity ID or name"
+// @Param actor_id query string false "Filter by acting user ID"
+// @Param action query string false "Filter by method and route, e.g. DELETE /api/v1/jobs/:id"
+// @Param from query string false "Start time (RFC3339)"
+// @Param to query string false "End time (RFC3339)"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} response.Response{data=[]models.AuditLog}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/audit [get]
+func (h *AuditHandler) List(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	filter := models.AuditFilter{
+		TenantID:   &tenantID,
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		Page:       c.QueryInt("page", 1),
+		PageSize:   c.QueryInt("page_size", 20),
+	}
+
+	if actorIDStr := c.Query("actor_id"); actorIDStr != "" {
+		actorID, err := uuid.Parse(actorIDStr)
+		if err != nil {
+			return response.BadRequest(c, "BAD_REQUEST", "Invalid actor ID")
+		}
+		filter.ActorID = &actorID
+	}
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return response.BadRequest(c, "BAD_REQUEST", "from must be an RFC3339 time")
+		}
+		filter.From = &from
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return response.BadRequest(c, "BAD_REQUEST", "to must be an RFC3339 time")
+		}
+		filter.To = &to
+	}
+
+	result, err := h.auditService.List(c.Context(), filter)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OKWithPagination(c, result.Entries, &response.Pagination{
+		Page:    result.Page,
+		PerPage: result.PageSize,
+		Total:   result.TotalCount,
+		HasNext: result.HasMore,
+	})
+}
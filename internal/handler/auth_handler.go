@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/middleware"
+	"github.com/minisource/scheduler/internal/service"
+)
+
+const (
+	oidcStateCookie = "scheduler_oidc_state"
+	oidcNonceCookie = "scheduler_oidc_nonce"
+	oidcCookiePath  = "/api/v1/auth/oidc"
+	oidcCookieTTL   = 10 * time.Minute
+)
+
+// AuthHandler handles operator login and browser sessions
+type AuthHandler struct {
+	oidcService    *service.OIDCService
+	sessionService *service.SessionService
+}
+
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler(oidcService *service.OIDCService, sessionService *service.SessionService) *AuthHandler {
+	return &AuthHandler{
+		oidcService:    oidcService,
+		sessionService: sessionService,
+	}
+}
+
+// OIDCLogin redirects the browser to the identity provider
+// @Summary Start OIDC login
+// @Description Starts the OpenID Connect authorization code flow
+// @Tags auth
+// @Success 302
+// @Failure 503 {object} response.Response
+// @Router /api/v1/auth/oidc/login [get]
+func (h *AuthHandler) OIDCLogin(c *fiber.Ctx) error {
+	authURL, state, nonce, err := h.oidcService.Begin(c.Context())
+	if err != nil {
+		return response.ServiceUnavailable(c, err.Error())
+	}
+
+	h.setCookie(c, oidcStateCookie, state, oidcCookieTTL)
+	h.setCookie(c, oidcNonceCookie, nonce, oidcCookieTTL)
+
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// OIDCCallback completes the login and starts a browser session
+// @Summary OIDC callback
+// @Description Exchanges the authorization code, verifies the ID token, maps groups to roles and sets the session cookies
+// @Tags auth
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "Login state"
+// @Success 302
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /api/v1/auth/oidc/callback [get]
+func (h *AuthHandler) OIDCCallback(c *fiber.Ctx) error {
+	state := c.Cookies(oidcStateCookie)
+	nonce := c.Cookies(oidcNonceCookie)
+
+	// The state and nonce are single use
+	h.setCookie(c, oidcStateCookie, "", -time.Hour)
+	h.setCookie(c, oidcNonceCookie, "", -time.Hour)
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		message := c.Query("error_description")
+		if message == "" {
+			message = providerErr
+		}
+		return errorResponse(c, fiber.StatusUnauthorized, "LOGIN_FAILED", message)
+	}
+
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(c.Query("state"))) != 1 {
+		return response.BadRequest(c, "INVALID_STATE", "Login state is missing or does not match, start the login again")
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		return response.BadRequest(c, "BAD_REQUEST", "Missing authorization code")
+	}
+
+	identity, err := h.oidcService.Complete(c.Context(), code, nonce)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOIDCNotConfigured):
+			return response.ServiceUnavailable(c, err.Error())
+		case errors.Is(err, service.ErrOIDCAccessDenied):
+			return errorResponse(c, fiber.StatusForbidden, "ACCESS_DENIED", err.Error())
+		case errors.Is(err, service.ErrOIDCLoginFailed):
+			return errorResponse(c, fiber.StatusUnauthorized, "LOGIN_FAILED", err.Error())
+		}
+		return response.ServiceUnavailable(c, err.Error())
+	}
+
+	session, err := h.sessionService.Create(c.Context(), identity)
+	if err != nil {
+		return response.ServiceUnavailable(c, err.Error())
+	}
+
+	middleware.SetSessionCookies(c, session, h.sessionService.CookieSecure())
+	return c.Redirect(h.sessionService.PostLoginURL(), fiber.StatusFound)
+}
+
+// GetSession returns the current browser session
+// @Summary Current session
+// @Description Returns the logged-in operator's identity, roles and CSRF token
+// @Tags auth
+// @Produce json
+// @Success 200 {object} response.Response{data=models.Session}
+// @Failure 401 {object} response.Response
+// @Router /api/v1/auth/session [get]
+func (h *AuthHandler) GetSession(c *fiber.Ctx) error {
+	session := middleware.SessionFrom(c)
+	if session == nil {
+		return errorResponse(c, fiber.StatusUnauthorized, "UNAUTHENTICATED", "Not logged in")
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return response.OK(c, session)
+}
+
+// Logout ends the current browser session
+// @Summary Log out
+// @Description Revokes the session; requires the X-CSRF-Token header
+// @Tags auth
+// @Param X-CSRF-Token header string true "CSRF token"
+// @Success 204
+// @Failure 403 {object} response.Response
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	if session := middleware.SessionFrom(c); session != nil {
+		if err := h.sessionService.Delete(c.Context(), session.ID); err != nil {
+			return response.ServiceUnavailable(c, err.Error())
+		}
+	}
+
+	middleware.ClearSessionCookies(c, h.sessionService.CookieSecure())
+	return response.NoContent(c)
+}
+
+// setCookie sets a short-lived cookie scoped to the OIDC endpoints
+func (h *AuthHandler) setCookie(c *fiber.Ctx, name, value string, ttl time.Duration) {
+	c.Cookie(&fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     oidcCookiePath,
+		Expires:  time.Now().Add(ttl),
+		Secure:   c.Protocol() == "https",
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/service"
+	"gorm.io/gorm"
+)
+
+// CalendarHandler handles calendar HTTP requests
+type CalendarHandler struct {
+	calendarService *service.CalendarService
+}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler(calendarService *service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+// List lists the tenant's calendars
+// @Summary List calendars
+// @Description List the tenant's calendars by name
+// @Tags calendars
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.Calendar}
+// @Failure 500 {object} response.Response
+// @Router /api/v1/calendars [get]
+func (h *CalendarHandler) List(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	calendars, err := h.calendarService.List(c.Context(), tenantID)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, calendars)
+}
+
+// Get retrieves a calendar
+// @Summary Get a calendar
+// @Description Get a calendar
+// @Tags calendars
+// @Produce json
+// @Param id path string true "Calendar ID"
+// @Success 200 {object} response.Response{data=models.Calendar}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/calendars/{id} [get]
+func (h *CalendarHandler) Get(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid calendar ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	calendar, err := h.calendarService.Get(c.Context(), tenantID, id)
+	if err != nil {
+		return calendarError(c, err)
+	}
+
+	return response.OK(c, calendar)
+}
+
+// Create creates a calendar
+// @Summary Create a calendar
+// @Description Create a blackout or holiday calendar. Jobs naming a blackout calendar in blackout_calendar skip or defer runs due inside its windows, and a tenant-wide one applies to every job of the tenant. Cron jobs naming a holiday calendar in holiday_calendar skip occurrences on its dates.
+// @Tags calendars
+// @Accept json
+// @Produce json
+// @Param request body models.CreateCalendarRequest true "Calendar"
+// @Success 201 {object} response.Response{data=models.Calendar}
+// @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/calendars [post]
+func (h *CalendarHandler) Create(c *fiber.Ctx) error {
+	var req models.CreateCalendarRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+
+	tenantID := getTenantID(c)
+
+	calendar, err := h.calendarService.Create(c.Context(), tenantID, &req)
+	if err != nil {
+		return calendarError(c, err)
+	}
+
+	return response.Created(c, calendar)
+}
+
+// Update updates a calendar
+// @Summary Update a calendar
+// @Description Update a calendar's description, windows, dates or tenant-wide setting. Blackout changes apply from the next tick; jobs following a holiday calendar have their next run recalculated.
+// @Tags calendars
+// @Accept json
+// @Produce json
+// @Param id path string true "Calendar ID"
+// @Param request body models.UpdateCalendarRequest true "Calendar update"
+// @Success 200 {object} response.Response{data=models.Calendar}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/calendars/{id} [put]
+func (h *CalendarHandler) Update(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid calendar ID")
+	}
+
+	var req models.UpdateCalendarRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+
+	tenantID := getTenantID(c)
+
+	calendar, err := h.calendarService.Update(c.Context(), tenantID, id, &req)
+	if err != nil {
+		return calendarError(c, err)
+	}
+
+	return response.OK(c, calendar)
+}
+
+// Delete deletes a calendar
+// @Summary Delete a calendar
+// @Description Delete a calendar that no job names in blackout_calendar or holiday_calendar
+// @Tags calendars
+// @Param id path string true "Calendar ID"
+// @Success 204
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/calendars/{id} [delete]
+func (h *CalendarHandler) Delete(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid calendar ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	if err := h.calendarService.Delete(c.Context(), tenantID, id); err != nil {
+		return calendarError(c, err)
+	}
+
+	return response.NoContent(c)
+}
+
+// calendarError maps calendar service errors to responses
+func calendarError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, "Calendar not found")
+	case errors.Is(err, service.ErrInvalidCalendar):
+		return response.BadRequest(c, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, service.ErrCalendarExists):
+		return errorResponse(c, fiber.StatusConflict, "CALENDAR_EXISTS", err.Error())
+	case errors.Is(err, service.ErrCalendarInUse):
+		return errorResponse(c, fiber.StatusConflict, "CALENDAR_IN_USE", err.Error())
+	}
+	return response.InternalError(c, err.Error())
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/service"
+	"gorm.io/gorm"
+)
+
+// CallbackHandler handles callback route and delivery HTTP requests
+type CallbackHandler struct {
+	callbackService *service.CallbackService
+}
+
+// NewCallbackHandler creates a new callback handler
+func NewCallbackHandler(callbackService *service.CallbackService) *CallbackHandler {
+	return &CallbackHandler{
+		callbackService: callbackService,
+	}
+}
+
+// ListRoutes lists the tenant's callback routes
+// @Summary List callback routes
+// @Description List the tenant's callback routes in the order they are matched
+// @Tags callbacks
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.CallbackRoute}
+// @Failure 500 {object} response.Response
+// @Router /api/v1/callbacks/routes [get]
+func (h *CallbackHandler) ListRoutes(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	routes, err := h.callbackService.ListRoutes(c.Context(), tenantID)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, routes)
+}
+
+// GetRoute retrieves a callback route
+// @Summary Get a callback route
+// @Description Get a callback route
+// @Tags callbacks
+// @Produce json
+// @Param id path string true "Route ID"
+// @Success 200 {object} response.Response{data=models.CallbackRoute}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/callbacks/routes/{id} [get]
+func (h *CallbackHandler) GetRoute(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid route ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	route, err := h.callbackService.GetRoute(c.Context(), tenantID, id)
+	if err != nil {
+		return callbackError(c, err)
+	}
+
+	return response.OK(c, route)
+}
+
+// CreateRoute creates a callback route
+// @Summary Create a callback route
+// @Description Post the results of jobs carrying all of the route's tags to its URL. Each finished execution goes to the first matching route by position; a route without tags matches every job.
+// @Tags callbacks
+// @Accept json
+// @Produce json
+// @Param request body models.CreateCallbackRouteRequest true "Route"
+// @Success 201 {object} response.Response{data=models.CallbackRoute}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/callbacks/routes [post]
+func (h *CallbackHandler) CreateRoute(c *fiber.Ctx) error {
+	var req models.CreateCallbackRouteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
+	}
+
+	tenantID := getTenantID(c)
+
+	route, err := h.callbackService.CreateRoute(c.Context(), tenantID, &req)
+	if err != nil {
+		return callbackError(c, err)
+	}
+
+	return response.Created(c, route)
+}
+
+// UpdateRoute updates a callback route
+// @Summary Update a callback route
+// @Description Update a callback route; callbacks already queued keep their URL and headers
+// @Tags callbacks
+// @Accept json
+// @Produce json
+// @Param id path string true "Route ID"
+// @Param request body models.UpdateCallbackRouteRequest true "Route update"
+// @Success 200 {object} response.Response{data=models.CallbackRoute}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/callbacks/routes/{id} [put]
+func (h *CallbackHandler) UpdateRoute(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid route ID")
+	}
+
+	var req models.UpdateCallbackRouteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+
+	tenantID := getTenantID(c)
+
+	route, err := h.callbackService.UpdateRoute(c.Context(), tenantID, id, &req)
+	if err != nil {
+		return callbackError(c, err)
+	}
+
+	return response.OK(c, route)
+}
+
+// DeleteRoute deletes a callback route
+// @Summary Delete a callback route
+// @Description Delete a callback route; callbacks already queued are still delivered
+// @Tags callbacks
+// @Param id path string true "Route ID"
+// @Success 204
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/callbacks/routes/{id} [delete]
+func (h *CallbackHandler) DeleteRoute(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid route ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	if err := h.callbackService.DeleteRoute(c.Context(), tenantID, id); err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.NoContent(c)
+}
+
+// ListDeliveries lists the tenant's callbacks
+// @Summary List callback deliveries
+// @Description List queued, delivered and failed callbacks, newest first
+// @Tags callbacks
+// @Produce json
+// @Param route_id query string false "Filter by route ID"
+// @Param execution_id query string false "Filter by execution ID"
+// @Param status query string false "Filter by status (pending, delivered, failed)"
+// @Param limit query int false "Maximum deliveries returned" default(50)
+// @Success 200 {object} response.Response{data=[]models.CallbackDelivery}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/callbacks/deliveries [get]
+func (h *CallbackHandler) ListDeliveries(c *fiber.Ctx) error {
+	filter := models.CallbackDeliveryFilter{
+		TenantID: getTenantID(c),
+		Status:   models.CallbackStatus(c.Query("status")),
+		Limit:    c.QueryInt("limit", 0),
+	}
+	if routeIDStr := c.Query("route_id"); routeIDStr != "" {
+		routeID, err := uuid.Parse(routeIDStr)
+		if err != nil {
+			return response.BadRequest(c, "BAD_REQUEST", "Invalid route ID")
+		}
+		filter.RouteID = &routeID
+	}
+	if executionIDStr := c.Query("execution_id"); executionIDStr != "" {
+		executionID, err := uuid.Parse(executionIDStr)
+		if err != nil {
+			return response.BadRequest(c, "BAD_REQUEST", "Invalid execution ID")
+		}
+		filter.ExecutionID = &executionID
+	}
+
+	deliveries, err := h.callbackService.ListDeliveries(c.Context(), filter)
+	if err != nil {
+		return callbackError(c, err)
+	}
+
+	return response.OK(c, deliveries)
+}
+
+// Redeliver queues a failed callback again
+// @Summary Redeliver a callback
+// @Description Queue a failed callback for another round of attempts
+// @Tags callbacks
+// @Produce json
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} response.Response{data=models.CallbackDelivery}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/callbacks/deliveries/{id}/redeliver [post]
+func (h *CallbackHandler) Redeliver(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid delivery ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	delivery, err := h.callbackService.Redeliver(c.Context(), tenantID, id)
+	if err != nil {
+		return callbackError(c, err)
+	}
+
+	return response.OK(c, delivery)
+}
+
+// callbackError maps callback service errors to responses
+func callbackError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, "Callback not found")
+	case errors.Is(err, service.ErrInvalidCallbackRoute):
+		return response.BadRequest(c, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, service.ErrNotRedeliverable):
+		return errorResponse(c, fiber.StatusConflict, "NOT_REDELIVERABLE", err.Error())
+	}
+	return response.InternalError(c, err.Error())
+}
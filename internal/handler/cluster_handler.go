@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/service"
+)
+
+// ClusterHandler handles cluster state requests
+type ClusterHandler struct {
+	clusterService *service.ClusterService
+}
+
+// NewClusterHandler creates a new cluster handler
+func NewClusterHandler(clusterService *service.ClusterService) *ClusterHandler {
+	return &ClusterHandler{
+		clusterService: clusterService,
+	}
+}
+
+// Leader reports the leader
+// @Summary Get the leader
+// @Description Get the instance holding scheduler:leader, how long it has held it and its last heartbeat, and whether the instance serving the request is the leader. Needs the platform role.
+// @Tags cluster
+// @Produce json
+// @Success 200 {object} response.Response{data=scheduler.LeaderStatus}
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/cluster/leader [get]
+func (h *ClusterHandler) Leader(c *fiber.Ctx) error {
+	status, err := h.clusterService.Leader(c.Context())
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, status)
+}
+
+// Workers lists the registered instances
+// @Summary List cluster workers
+// @Description List registered instances with their role, phase, capacity, current load and last heartbeat. Instances that missed several heartbeats without shutting down cleanly are listed as dead for a day. Needs the platform role.
+// @Tags cluster
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.ClusterMember}
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/cluster/workers [get]
+func (h *ClusterHandler) Workers(c *fiber.Ctx) error {
+	workers, err := h.clusterService.Workers(c.Context())
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, workers)
+}
+
+// Shadow reports shadow dispatch comparisons
+// @Summary Get shadow dispatch results
+// @Description Get how the jobs this instance dispatched as leader compared with those claim dispatch would have claimed, for tenants the claim_dispatch_shadow flag is on for, with the latest mismatches. Needs the platform role.
+// @Tags cluster
+// @Produce json
+// @Success 200 {object} response.Response{data=scheduler.ShadowStatus}
+// @Failure 403 {object} response.Response
+// @Router /api/v1/cluster/shadow [get]
+func (h *ClusterHandler) Shadow(c *fiber.Ctx) error {
+	return response.OK(c, h.clusterService.Shadow())
+}
+
+// Metrics serves cluster metrics in the Prometheus text format
+// @Summary Prometheus metrics
+// @Description Leader election gauges and shadow dispatch counters in the Prometheus text exposition format
+// @Tags health
+// @Produce plain
+// @Success 200 {string} string
+// @Router /metrics [get]
+func (h *ClusterHandler) Metrics(c *fiber.Ctx) error {
+	status, err := h.clusterService.Leader(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+
+	var b strings.Builder
+	self := fmt.Sprintf(`instance=%q`, status.Instance)
+	metric(&b, "scheduler_is_leader", "gauge", "Whether this instance holds scheduler:leader", self, boolGauge(status.IsLeader))
+	metric(&b, "scheduler_leader_elections_total", "counter", "Times this instance has taken scheduler:leader since it started", self, float64(status.Elections))
+	metric(&b, "scheduler_leader_elected", "gauge", "Whether an instance holds scheduler:leader and is heartbeating", "", boolGauge(status.Elected))
+	if status.InstanceID != "" {
+		leader := fmt.Sprintf(`leader=%q,hostname=%q`, status.InstanceID, status.Hostname)
+		metric(&b, "scheduler_leader_held_seconds", "gauge", "How long the recorded leader has held scheduler:leader", leader, status.HeldSeconds)
+		metric(&b, "scheduler_leader_heartbeat_age_seconds", "gauge", "Seconds since the recorded leader's last heartbeat", leader, status.HeartbeatAgeSeconds)
+	}
+	shadow := h.clusterService.Shadow()
+	metric(&b, "scheduler_shadow_comparisons_total", "counter", "Ticks whose leader dispatch was compared with shadow claim dispatch", self, float64(shadow.Comparisons))
+	metric(&b, "scheduler_shadow_mismatches_total", "counter", "Compared ticks where shadow claim dispatch picked different jobs than the leader", self, float64(shadow.Mismatches))
+
+	c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+	return c.SendString(b.String())
+}
+
+// metric writes one sample with its HELP and TYPE lines
+func metric(b *strings.Builder, name, kind, help, labels string, value float64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	if labels != "" {
+		fmt.Fprintf(b, "%s{%s} %g\n", name, labels, value)
+	} else {
+		fmt.Fprintf(b, "%s %g\n", name, value)
+	}
+}
+
+// boolGauge converts a condition to a 0 or 1 gauge value
+func boolGauge(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -1,13 +1,29 @@
 package handler
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/minisource/go-common/response"
 	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/scheduler"
 	"github.com/minisource/scheduler/internal/service"
+	"gorm.io/gorm"
+)
+
+const (
+	// eventsInterval is how often an execution's events stream checks it for changes
+	eventsInterval = 2 * time.Second
+	// eventsStreamDuration is how long an events stream lasts before the client has to
+	// reconnect, kept below the server's write timeout
+	eventsStreamDuration = 25 * time.Second
 )
 
 // ExecutionHandler handles execution-related HTTP requests
@@ -39,7 +55,9 @@ func (h *ExecutionHandler) Get(c *fiber.Ctx) error {
 		return response.BadRequest(c, "BAD_REQUEST", "Invalid execution ID")
 	}
 
-	execution, err := h.executionService.GetByID(c.Context(), id)
+	tenantID := getTenantID(c)
+
+	execution, err := h.executionService.GetByID(c.Context(), tenantID, id)
 	if err != nil {
 		return response.NotFound(c, "Execution not found")
 	}
@@ -47,28 +65,112 @@ func (h *ExecutionHandler) Get(c *fiber.Ctx) error {
 	return response.OK(c, execution)
 }
 
+// Events streams an execution's progress
+// @Summary Stream execution progress
+// @Description Server-sent events with the execution's status, queue position and estimated start, sent when any of them changes. The stream ends once the execution has finished, and otherwise after 25 seconds, when EventSource clients reconnect.
+// @Tags executions
+// @Produce text/event-stream
+// @Param id path string true "Execution ID"
+// @Success 200 {object} models.ExecutionProgress
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/executions/{id}/events [get]
+func (h *ExecutionHandler) Events(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid execution ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	execution, err := h.executionService.GetByID(c.Context(), tenantID, id)
+	if err != nil {
+		return response.NotFound(c, "Execution not found")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set("X-Accel-Buffering", "no")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		h.streamProgress(w, execution)
+	})
+	return nil
+}
+
+// streamProgress writes an execution's progress events until it finishes, the client goes
+// away or the stream has to end before the server's write timeout. It runs after the request's
+// tenant transaction has ended, so it reads outside of it.
+func (h *ExecutionHandler) streamProgress(w *bufio.Writer, execution *models.JobExecution) {
+	ctx, cancel := context.WithTimeout(context.Background(), eventsStreamDuration)
+	defer cancel()
+
+	fmt.Fprintf(w, "retry: %d\n\n", eventsInterval.Milliseconds())
+	var last []byte
+	ticker := time.NewTicker(eventsInterval)
+	defer ticker.Stop()
+	for {
+		data, err := json.Marshal(execution.Progress())
+		if err != nil {
+			return
+		}
+		if !bytes.Equal(data, last) {
+			fmt.Fprintf(w, "event: progress\ndata: %s\n\n", data)
+			last = data
+		} else {
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		if err := w.Flush(); err != nil || execution.Status.Finished() {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		next, err := h.executionService.GetByID(ctx, execution.TenantID, execution.ID)
+		if err != nil {
+			return
+		}
+		execution = next
+	}
+}
+
 // List lists executions with filtering
 // @Summary List executions
-// @Description List executions with optional filtering
+// @Description List executions with optional filtering. Send Accept: application/msgpack for a MessagePack-encoded models.ExecutionListResult.
 // @Tags executions
 // @Produce json
+// @Produce application/msgpack
 // @Param job_id query string false "Filter by job ID"
+// @Param correlation_id query string false "Filter by correlation ID"
 // @Param status query string false "Filter by status"
+// @Param acknowledged query bool false "Filter by acknowledgement"
 // @Param start_time query string false "Filter by start time (RFC3339)"
 // @Param end_time query string false "Filter by end time (RFC3339)"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,status,scheduled_at,duration_ms"
+// @Param count query string false "Total count mode (estimated, exact)" default(estimated)
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(20)
 // @Success 200 {object} response.Response{data=[]models.JobExecution}
+// @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/executions [get]
 func (h *ExecutionHandler) List(c *fiber.Ctx) error {
 	tenantID := getTenantID(c)
 
 	filter := models.ExecutionFilter{
-		TenantID: &tenantID,
-		Status:   models.ExecutionStatus(c.Query("status")),
-		Page:     c.QueryInt("page", 1),
-		PageSize: c.QueryInt("page_size", 20),
+		TenantID:      &tenantID,
+		CorrelationID: c.Query("correlation_id"),
+		Status:        models.ExecutionStatus(c.Query("status")),
+		Page:          c.QueryInt("page", 1),
+		PageSize:      c.QueryInt("page_size", 20),
+	}
+
+	// Parse acknowledgement filter
+	if ackStr := c.Query("acknowledged"); ackStr != "" {
+		acknowledged := c.QueryBool("acknowledged")
+		filter.Acknowledged = &acknowledged
 	}
 
 	// Parse job ID
@@ -92,12 +194,40 @@ func (h *ExecutionHandler) List(c *fiber.Ctx) error {
 		}
 	}
 
+	fields, err := parseFields(c, models.ExecutionFieldColumns)
+	if err != nil {
+		return response.BadRequest(c, "INVALID_FIELDS", err.Error())
+	}
+	filter.Fields = fields
+
+	if filter.Count, err = parseCountMode(c); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", err.Error())
+	}
+
 	result, err := h.executionService.List(c.Context(), filter)
 	if err != nil {
 		return response.InternalError(c, err.Error())
 	}
 
-	return response.OKWithPagination(c, result.Executions, &response.Pagination{
+	executions, err := projectFields(result.Executions, fields)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	setTotalEstimated(c, result.TotalEstimated)
+
+	if wantsMsgpack(c) {
+		return sendMsgpack(c, fiber.Map{
+			"executions":      executions,
+			"total_count":     result.TotalCount,
+			"total_estimated": result.TotalEstimated,
+			"page":            result.Page,
+			"page_size":       result.PageSize,
+			"has_more":        result.HasMore,
+		})
+	}
+
+	return response.OKWithPagination(c, executions, &response.Pagination{
 		Page:    result.Page,
 		PerPage: result.PageSize,
 		Total:   result.TotalCount,
@@ -107,9 +237,10 @@ func (h *ExecutionHandler) List(c *fiber.Ctx) error {
 
 // ListByJob lists executions for a specific job
 // @Summary List executions by job
-// @Description List executions for a specific job
+// @Description List executions for a specific job. Send Accept: application/msgpack for a MessagePack-encoded array.
 // @Tags executions
 // @Produce json
+// @Produce application/msgpack
 // @Param job_id path string true "Job ID"
 // @Param limit query int false "Limit" default(10)
 // @Success 200 {object} response.Response{data=[]models.JobExecution}
@@ -124,12 +255,17 @@ func (h *ExecutionHandler) ListByJob(c *fiber.Ctx) error {
 	}
 
 	limit := c.QueryInt("limit", 10)
+	tenantID := getTenantID(c)
 
-	executions, err := h.executionService.GetByJobID(c.Context(), jobID, limit)
+	executions, err := h.executionService.GetByJobID(c.Context(), tenantID, jobID, limit)
 	if err != nil {
 		return response.InternalError(c, err.Error())
 	}
 
+	if wantsMsgpack(c) {
+		return sendMsgpack(c, executions)
+	}
+
 	return response.OK(c, executions)
 }
 
@@ -150,13 +286,104 @@ func (h *ExecutionHandler) Cancel(c *fiber.Ctx) error {
 		return response.BadRequest(c, "BAD_REQUEST", "Invalid execution ID")
 	}
 
-	if err := h.executionService.Cancel(c.Context(), id); err != nil {
+	tenantID := getTenantID(c)
+
+	if err := h.executionService.Cancel(c.Context(), tenantID, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFound(c, "Execution not found")
+		}
 		return response.InternalError(c, err.Error())
 	}
 
 	return response.OK(c, map[string]bool{"cancelled": true})
 }
 
+// Acknowledge acknowledges a failed execution
+// @Summary Acknowledge an execution
+// @Description Acknowledge a failed execution and attach an operator comment
+// @Tags executions
+// @Accept json
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Param request body models.AcknowledgeExecutionRequest false "Acknowledgement"
+// @Success 200 {object} response.Response{data=models.JobExecution}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/executions/{id}/acknowledge [post]
+func (h *ExecutionHandler) Acknowledge(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid execution ID")
+	}
+
+	var req models.AcknowledgeExecutionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+		}
+	}
+
+	tenantID := getTenantID(c)
+
+	execution, err := h.executionService.Acknowledge(c.Context(), tenantID, id, getUserID(c), req.Comment)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFound(c, "Execution not found")
+		}
+		if errors.Is(err, service.ErrNotAcknowledgeable) {
+			return response.BadRequest(c, "NOT_ACKNOWLEDGEABLE", err.Error())
+		}
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, execution)
+}
+
+// Replay runs a previous execution's request again
+// @Summary Replay an execution
+// @Description Create a new execution that sends the request recorded by a previous execution, not the job's current definition
+// @Tags executions
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 201 {object} response.Response{data=models.JobExecution}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/executions/{id}/replay [post]
+func (h *ExecutionHandler) Replay(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid execution ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	execution, err := h.executionService.Replay(c.Context(), tenantID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFound(c, "Execution not found")
+		}
+		if errors.Is(err, service.ErrNotReplayable) {
+			return errorResponse(c, fiber.StatusConflict, "NOT_REPLAYABLE", err.Error())
+		}
+		if errors.Is(err, service.ErrConflict) {
+			return errorResponse(c, fiber.StatusConflict, "CONFLICT", err.Error())
+		}
+		var limitErr *scheduler.TriggerLimitError
+		if errors.As(err, &limitErr) {
+			return triggerLimited(c, limitErr)
+		}
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.Created(c, execution)
+}
+
 // GetStats retrieves execution statistics
 // @Summary Get execution statistics
 // @Description Get statistics about executions
@@ -193,3 +420,60 @@ func (h *ExecutionHandler) GetStats(c *fiber.Ctx) error {
 
 	return response.OK(c, stats)
 }
+
+// Compare diffs two executions of the same job
+// @Summary Compare two executions
+// @Description Diff the outcome, recorded request, response and timing of two executions of the same job, listing changes from a to b
+// @Tags executions
+// @Produce json
+// @Param a query string true "Execution ID to compare from, e.g. the last good run"
+// @Param b query string true "Execution ID to compare to, e.g. the failing run"
+// @Success 200 {object} response.Response{data=models.ExecutionComparison}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/executions/compare [get]
+func (h *ExecutionHandler) Compare(c *fiber.Ctx) error {
+	a, err := uuid.Parse(c.Query("a"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid execution ID in a")
+	}
+	b, err := uuid.Parse(c.Query("b"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid execution ID in b")
+	}
+
+	tenantID := getTenantID(c)
+
+	comparison, err := h.executionService.Compare(c.Context(), tenantID, a, b)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFound(c, "Execution not found")
+		}
+		if errors.Is(err, service.ErrNotComparable) {
+			return response.BadRequest(c, "NOT_COMPARABLE", err.Error())
+		}
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, comparison)
+}
+
+// VerifyChain verifies the tenant's execution hash chain
+// @Summary Verify the execution chain
+// @Description Check that sealed executions and the chain linking them are unaltered
+// @Tags executions
+// @Produce json
+// @Success 200 {object} response.Response{data=models.ChainVerification}
+// @Failure 500 {object} response.Response
+// @Router /api/v1/executions/verify [get]
+func (h *ExecutionHandler) VerifyChain(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	result, err := h.executionService.VerifyChain(c.Context(), tenantID)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, result)
+}
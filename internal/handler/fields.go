@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// totalEstimatedHeader marks listings whose total count is a planner estimate
+const totalEstimatedHeader = "X-Total-Count-Estimated"
+
+// parseFields reads the comma-separated fields query parameter, rejecting unknown names
+func parseFields(c *fiber.Ctx, allowed map[string]string) ([]string, error) {
+	return parseNames(c.Query("fields"), "field", func(name string) bool {
+		_, ok := allowed[name]
+		return ok
+	})
+}
+
+// parseIncludes reads the comma-separated include query parameter, rejecting unknown names
+func parseIncludes(c *fiber.Ctx, valid func(string) bool) ([]string, error) {
+	return parseNames(c.Query("include"), "include", valid)
+}
+
+// parseNames splits a comma-separated list of names, dropping duplicates
+func parseNames(value, kind string, valid func(string) bool) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if !valid(name) {
+			return nil, fmt.Errorf("unknown %s %q", kind, name)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// projectFields reduces a list of items to the requested fields; all fields are kept when none are requested
+func projectFields(items interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return items, nil
+	}
+
+	generic, err := toGeneric(items)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := generic.([]interface{})
+
+	projected := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		values, _ := row.(map[string]interface{})
+		item := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			if value, ok := values[field]; ok {
+				item[field] = value
+			}
+		}
+		projected = append(projected, item)
+	}
+	return projected, nil
+}
+
+// parseCountMode reads the count query parameter; totals are estimated unless exact is requested
+func parseCountMode(c *fiber.Ctx) (models.CountMode, error) {
+	switch mode := models.CountMode(c.Query("count", string(models.CountEstimated))); mode {
+	case models.CountExact, models.CountEstimated:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("count must be exact or estimated")
+	}
+}
+
+// setTotalEstimated flags a listing whose total count is an estimate
+func setTotalEstimated(c *fiber.Ctx, estimated bool) {
+	if estimated {
+		c.Set(totalEstimatedHeader, "true")
+	}
+}
@@ -3,6 +3,8 @@ package handler
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/buildinfo"
+	"github.com/minisource/scheduler/internal/database"
 	"github.com/minisource/scheduler/internal/scheduler"
 	"gorm.io/gorm"
 )
@@ -21,6 +23,21 @@ func NewHealthHandler(db *gorm.DB, sched *scheduler.Scheduler) *HealthHandler {
 	}
 }
 
+// VersionInfo describes the running build and its database schema. Feature flags, whose rules
+// name tenants, are only listed under /api/v1/system/features.
+type VersionInfo struct {
+	buildinfo.Info
+	Schema SchemaInfo `json:"schema"`
+}
+
+// SchemaInfo compares the schema this build needs with the one applied to the database
+type SchemaInfo struct {
+	Expected int    `json:"expected"`          // Highest migration this build ships
+	Applied  *int   `json:"applied,omitempty"` // Recorded by golang-migrate; absent when only AutoMigrate manages the schema
+	Dirty    bool   `json:"dirty"`             // A migration failed halfway
+	Error    string `json:"error,omitempty"`
+}
+
 // Health returns the service health status
 // @Summary Health check
 // @Description Check service health
@@ -33,6 +50,8 @@ func (h *HealthHandler) Health(c *fiber.Ctx) error {
 	healthData := map[string]interface{}{
 		"status":    "healthy",
 		"scheduler": h.scheduler.IsRunning(),
+		"role":      h.scheduler.Role(),
+		"dispatch":  h.scheduler.DispatchHealth(),
 	}
 
 	// Check database connection
@@ -67,6 +86,14 @@ func (h *HealthHandler) Ready(c *fiber.Ctx) error {
 		return response.ServiceUnavailable(c, "Scheduler is not running")
 	}
 
+	// Hold traffic back until startup recovery is done, and shed it while draining
+	switch lifecycle := h.scheduler.Lifecycle(); lifecycle.Phase {
+	case scheduler.PhaseStarting:
+		return response.ServiceUnavailable(c, "Scheduler is recovering executions and catching up on missed runs")
+	case scheduler.PhaseDraining:
+		return response.ServiceUnavailable(c, "Scheduler is draining")
+	}
+
 	sqlDB, err := h.db.DB()
 	if err != nil {
 		return response.ServiceUnavailable(c, "Database connection error")
@@ -76,7 +103,71 @@ func (h *HealthHandler) Ready(c *fiber.Ctx) error {
 		return response.ServiceUnavailable(c, "Database ping failed")
 	}
 
-	return response.OK(c, map[string]string{"status": "ready"})
+	// Report database trouble seen by the scheduling loop, even if the ping now succeeds
+	dispatch := h.scheduler.DispatchHealth()
+	switch dispatch.State {
+	case scheduler.DispatchStatePaused:
+		return response.ServiceUnavailable(c, "Scheduler dispatch paused after database errors: "+dispatch.LastError)
+	case scheduler.DispatchStateDegraded:
+		return response.OK(c, map[string]interface{}{"status": "degraded", "dispatch": dispatch})
+	}
+
+	return response.OK(c, map[string]interface{}{"status": "ready", "dispatch": dispatch})
+}
+
+// Startup returns whether startup recovery has completed
+// @Summary Startup check
+// @Description Startup probe: fails until orphaned executions have been checked and the runs missed while no instance was dispatching have been dispatched, or SCHEDULER_STARTUP_GATE_SECONDS has passed
+// @Tags health
+// @Produce json
+// @Success 200 {object} response.Response{data=scheduler.LifecycleStatus}
+// @Failure 503 {object} response.Response
+// @Router /startup [get]
+func (h *HealthHandler) Startup(c *fiber.Ctx) error {
+	lifecycle := h.scheduler.Lifecycle()
+	if lifecycle.Phase == scheduler.PhaseStarting {
+		return response.ServiceUnavailable(c, "Scheduler is recovering executions and catching up on missed runs")
+	}
+	return response.OK(c, lifecycle)
+}
+
+// Drain stops the instance from dispatching and waits for its executions to finish
+// @Summary Drain the instance
+// @Description preStop hook: stop dispatching due jobs on this instance, fail /ready, and wait up to SCHEDULER_DRAIN_TIMEOUT_SECONDS for executions running or queued here to finish. Other instances take over dispatching. Draining can't be undone; the instance is expected to be stopped. Needs SCHEDULER_DRAIN_TOKEN as a bearer token.
+// @Tags health
+// @Produce json
+// @Param Authorization header string true "Bearer SCHEDULER_DRAIN_TOKEN"
+// @Success 200 {object} response.Response{data=scheduler.LifecycleStatus}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /drain [post]
+func (h *HealthHandler) Drain(c *fiber.Ctx) error {
+	return response.OK(c, h.scheduler.Drain(c.Context(), h.scheduler.DrainTimeout()))
+}
+
+// Version returns the build and schema the instance runs
+// @Summary Build and schema version
+// @Description Get the git commit, build time and Go version of the running build, and the database schema version it needs and the one applied
+// @Tags health
+// @Produce json
+// @Success 200 {object} response.Response{data=VersionInfo}
+// @Router /version [get]
+func (h *HealthHandler) Version(c *fiber.Ctx) error {
+	info := VersionInfo{
+		Info:   buildinfo.Get(),
+		Schema: SchemaInfo{Expected: database.SchemaVersion},
+	}
+
+	version, dirty, found, err := database.SchemaStatus(h.db.WithContext(c.Context()))
+	switch {
+	case err != nil:
+		info.Schema.Error = err.Error()
+	case found:
+		info.Schema.Applied = &version
+		info.Schema.Dirty = dirty
+	}
+
+	return response.OK(c, info)
 }
 
 // Live returns the liveness status
@@ -1,12 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/minisource/go-common/response"
-	_ "github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/models"
 	"github.com/minisource/scheduler/internal/service"
 )
 
@@ -29,6 +30,7 @@ func NewHistoryHandler(historyService *service.HistoryService) *HistoryHandler {
 // @Produce json
 // @Param job_id path string true "Job ID"
 // @Param days query int false "Number of days" default(30)
+// @Param timezone query string false "Timezone of the days, UTC or the tenant's timezone; defaults to the tenant's"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
@@ -41,10 +43,11 @@ func (h *HistoryHandler) GetByJob(c *fiber.Ctx) error {
 	}
 
 	days := c.QueryInt("days", 30)
+	tenantID := getTenantID(c)
 
-	history, err := h.historyService.GetByJobID(c.Context(), jobID, days)
+	history, err := h.historyService.GetByJobID(c.Context(), tenantID, jobID, c.Query("timezone"), days)
 	if err != nil {
-		return response.InternalError(c, err.Error())
+		return historyError(c, err)
 	}
 
 	return response.OK(c, history)
@@ -58,7 +61,9 @@ func (h *HistoryHandler) GetByJob(c *fiber.Ctx) error {
 // @Param job_id query string false "Filter by job ID"
 // @Param start_date query string false "Start date (YYYY-MM-DD)"
 // @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Param timezone query string false "Timezone of the days, UTC or the tenant's timezone; defaults to the tenant's"
 // @Success 200 {object} response.Response{data=models.AggregatedHistoryStats}
+// @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/history/stats [get]
 func (h *HistoryHandler) GetAggregated(c *fiber.Ctx) error {
@@ -71,9 +76,8 @@ func (h *HistoryHandler) GetAggregated(c *fiber.Ctx) error {
 		}
 	}
 
-	// Default to last 30 days
-	endDate := time.Now()
-	startDate := endDate.AddDate(0, 0, -30)
+	// Zero dates default to the last 30 days of the timezone
+	var startDate, endDate time.Time
 
 	if startDateStr := c.Query("start_date"); startDateStr != "" {
 		if t, err := time.Parse("2006-01-02", startDateStr); err == nil {
@@ -87,9 +91,11 @@ func (h *HistoryHandler) GetAggregated(c *fiber.Ctx) error {
 		}
 	}
 
-	stats, err := h.historyService.GetAggregated(c.Context(), jobID, startDate, endDate)
+	tenantID := getTenantID(c)
+
+	stats, err := h.historyService.GetAggregated(c.Context(), tenantID, jobID, c.Query("timezone"), startDate, endDate)
 	if err != nil {
-		return response.InternalError(c, err.Error())
+		return historyError(c, err)
 	}
 
 	return response.OK(c, stats)
@@ -102,6 +108,7 @@ func (h *HistoryHandler) GetAggregated(c *fiber.Ctx) error {
 // @Produce json
 // @Param start_date query string true "Start date (YYYY-MM-DD)"
 // @Param end_date query string true "End date (YYYY-MM-DD)"
+// @Param timezone query string false "Timezone of the days, UTC or the tenant's timezone; defaults to the tenant's"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
@@ -124,10 +131,90 @@ func (h *HistoryHandler) GetDateRange(c *fiber.Ctx) error {
 		return response.BadRequest(c, "BAD_REQUEST", "Invalid end_date format (use YYYY-MM-DD)")
 	}
 
-	history, err := h.historyService.GetByDateRange(c.Context(), startDate, endDate)
+	tenantID := getTenantID(c)
+
+	history, err := h.historyService.GetByDateRange(c.Context(), tenantID, c.Query("timezone"), startDate, endDate)
 	if err != nil {
-		return response.InternalError(c, err.Error())
+		return historyError(c, err)
 	}
 
 	return response.OK(c, history)
 }
+
+// historyError maps history read errors to responses: a timezone history isn't kept in to 400
+func historyError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrInvalidHistoryTimezone) {
+		return response.BadRequest(c, "INVALID_TIMEZONE", err.Error())
+	}
+	return response.InternalError(c, err.Error())
+}
+
+// GetUsage retrieves every tenant's resource usage
+// @Summary Get resource usage by tenant
+// @Description Sum the wall time, traffic and retries of every tenant's executions over a period, from job history, busiest tenant first. Feeds metering and capacity planning. Needs the platform role.
+// @Tags system
+// @Produce json
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} response.Response{data=[]models.TenantResourceUsage}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/system/usage [get]
+func (h *HistoryHandler) GetUsage(c *fiber.Ctx) error {
+	// Default to last 30 days
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -30)
+
+	if startDateStr := c.Query("start_date"); startDateStr != "" {
+		t, err := time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			return response.BadRequest(c, "BAD_REQUEST", "Invalid start_date format (use YYYY-MM-DD)")
+		}
+		startDate = t
+	}
+
+	if endDateStr := c.Query("end_date"); endDateStr != "" {
+		t, err := time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			return response.BadRequest(c, "BAD_REQUEST", "Invalid end_date format (use YYYY-MM-DD)")
+		}
+		endDate = t
+	}
+
+	usage, err := h.historyService.UsageByTenant(c.Context(), startDate, endDate)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, usage)
+}
+
+// Rebuild rebuilds job history from executions
+// @Summary Rebuild job history
+// @Description Replace the job history of a range of days in a timezone, UTC by default, for every tenant or only one tenant or job, with records aggregated from the executions that finished on them. Use it to repair history that drifted from the executions, or to fill in the history of a tenant that set its timezone. At most 366 days at once; executions removed by retention cleanup can't be counted. Needs the platform role.
+// @Tags system
+// @Accept json
+// @Produce json
+// @Param request body models.RebuildHistoryRequest true "Days to rebuild"
+// @Success 200 {object} response.Response{data=models.HistoryRebuildResult}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/system/history/rebuild [post]
+func (h *HistoryHandler) Rebuild(c *fiber.Ctx) error {
+	var req models.RebuildHistoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+
+	result, err := h.historyService.Rebuild(c.Context(), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRebuild) {
+			return response.BadRequest(c, "VALIDATION_ERROR", err.Error())
+		}
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, result)
+}
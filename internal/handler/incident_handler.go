@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/service"
+	"gorm.io/gorm"
+)
+
+// IncidentHandler handles incident-related HTTP requests
+type IncidentHandler struct {
+	incidentService *service.IncidentService
+}
+
+// NewIncidentHandler creates a new incident handler
+func NewIncidentHandler(incidentService *service.IncidentService) *IncidentHandler {
+	return &IncidentHandler{
+		incidentService: incidentService,
+	}
+}
+
+// List lists incidents with filtering
+// @Summary List incidents
+// @Description List grouped failure incidents with optional filtering
+// @Tags incidents
+// @Produce json
+// @Param status query string false "Filter by status (open, resolved)"
+// @Param job_id query string false "Filter by job ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} response.Response{data=[]models.Incident}
+// @Failure 500 {object} response.Response
+// @Router /api/v1/incidents [get]
+func (h *IncidentHandler) List(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	filter := models.IncidentFilter{
+		TenantID: &tenantID,
+		Status:   models.IncidentStatus(c.Query("status")),
+		Page:     c.QueryInt("page", 1),
+		PageSize: c.QueryInt("page_size", 20),
+	}
+
+	if jobIDStr := c.Query("job_id"); jobIDStr != "" {
+		jobID, err := uuid.Parse(jobIDStr)
+		if err == nil {
+			filter.JobID = &jobID
+		}
+	}
+
+	result, err := h.incidentService.List(c.Context(), filter)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OKWithPagination(c, result.Incidents, &response.Pagination{
+		Page:    result.Page,
+		PerPage: result.PageSize,
+		Total:   result.TotalCount,
+		HasNext: result.HasMore,
+	})
+}
+
+// Get retrieves an incident by ID
+// @Summary Get an incident
+// @Description Get an incident by ID
+// @Tags incidents
+// @Produce json
+// @Param id path string true "Incident ID"
+// @Success 200 {object} response.Response{data=models.Incident}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/incidents/{id} [get]
+func (h *IncidentHandler) Get(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid incident ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	incident, err := h.incidentService.GetByID(c.Context(), tenantID, id)
+	if err != nil {
+		return response.NotFound(c, "Incident not found")
+	}
+
+	return response.OK(c, incident)
+}
+
+// Acknowledge acknowledges an open incident
+// @Summary Acknowledge an incident
+// @Description Acknowledge an open incident, suppressing repeat alerts and escalation
+// @Tags incidents
+// @Produce json
+// @Param id path string true "Incident ID"
+// @Success 200 {object} response.Response{data=models.Incident}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/incidents/{id}/acknowledge [post]
+func (h *IncidentHandler) Acknowledge(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid incident ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	incident, err := h.incidentService.Acknowledge(c.Context(), tenantID, id, getUserID(c))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFound(c, "Incident not found")
+		}
+		if errors.Is(err, service.ErrIncidentResolved) {
+			return response.BadRequest(c, "INCIDENT_RESOLVED", err.Error())
+		}
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, incident)
+}
@@ -1,11 +1,24 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/middleware"
 	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/scheduler"
 	"github.com/minisource/scheduler/internal/service"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
 )
 
 // JobHandler handles job-related HTTP requests
@@ -22,13 +35,16 @@ func NewJobHandler(jobService *service.JobService) *JobHandler {
 
 // Create creates a new job
 // @Summary Create a job
-// @Description Create a new scheduled job
+// @Description Create a new scheduled job. Retries with the same Idempotency-Key return the existing job.
 // @Tags jobs
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Idempotency key, unique per tenant"
 // @Param request body models.CreateJobRequest true "Job creation request"
+// @Success 200 {object} response.Response{data=models.Job} "Existing job for the idempotency key"
 // @Success 201 {object} response.Response{data=models.Job}
 // @Failure 400 {object} response.Response
+// @Failure 413 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/jobs [post]
 func (h *JobHandler) Create(c *fiber.Ctx) error {
@@ -37,12 +53,27 @@ func (h *JobHandler) Create(c *fiber.Ctx) error {
 		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
 	}
 
+	// The Idempotency-Key header is an alternative to client_reference in the body
+	if key := c.Get("Idempotency-Key"); key != "" {
+		if req.ClientReference != "" && req.ClientReference != key {
+			return response.BadRequest(c, "BAD_REQUEST", "Idempotency-Key header and client_reference differ")
+		}
+		req.ClientReference = key
+	}
+	if len(req.ClientReference) > 255 {
+		return response.BadRequest(c, "BAD_REQUEST", "Idempotency key must be at most 255 characters")
+	}
+
 	// Get tenant ID from context
 	tenantID := getTenantID(c)
 
-	job, err := h.jobService.Create(c.Context(), tenantID, &req)
+	job, created, err := h.jobService.CreateIdempotent(c.Context(), tenantID, &req)
 	if err != nil {
-		return response.InternalError(c, err.Error())
+		return jobError(c, err)
+	}
+
+	if !created {
+		return response.OK(c, job)
 	}
 
 	return response.Created(c, job)
@@ -50,11 +81,16 @@ func (h *JobHandler) Create(c *fiber.Ctx) error {
 
 // Get retrieves a job by ID
 // @Summary Get a job
-// @Description Get a job by ID
+// @Description Get a job by ID. Responses carry ETag and Last-Modified; send If-None-Match or If-Modified-Since to get 304 when the job hasn't changed.
 // @Tags jobs
 // @Produce json
 // @Param id path string true "Job ID"
+// @Param If-None-Match header string false "ETag of the cached job"
+// @Param If-Modified-Since header string false "Last-Modified of the cached job"
+// @Param as_of query string false "Return the definition as it was at this time (RFC3339)"
 // @Success 200 {object} response.Response{data=models.Job}
+// @Success 304
+// @Failure 400 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/jobs/{id} [get]
@@ -67,9 +103,33 @@ func (h *JobHandler) Get(c *fiber.Ctx) error {
 
 	tenantID := getTenantID(c)
 
+	if asOf := c.Query("as_of"); asOf != "" {
+		at, err := time.Parse(time.RFC3339, asOf)
+		if err != nil {
+			return response.BadRequest(c, "BAD_REQUEST", "as_of must be an RFC3339 timestamp")
+		}
+		job, revision, err := h.jobService.GetAsOf(c.Context(), tenantID, id, at)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return response.NotFound(c, "Job has no revision at that time")
+			}
+			return response.InternalError(c, err.Error())
+		}
+		c.Set("X-Job-Revision", strconv.Itoa(revision))
+		return response.OK(c, job)
+	}
+
 	job, err := h.jobService.GetByID(c.Context(), tenantID, id)
 	if err != nil {
-		return response.NotFound(c, "Job not found")
+		return jobError(c, err)
+	}
+
+	notModified, err := setValidators(c, job, job.UpdatedAt)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+	if notModified {
+		return c.SendStatus(fiber.StatusNotModified)
 	}
 
 	return response.OK(c, job)
@@ -83,21 +143,48 @@ func (h *JobHandler) Get(c *fiber.Ctx) error {
 // @Param status query string false "Filter by status"
 // @Param type query string false "Filter by type"
 // @Param name query string false "Filter by name"
+// @Param correlation_id query string false "Filter by correlation ID"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,name,status,next_run_at"
+// @Param include query string false "Comma-separated related resources to embed (last_execution, history_7d)"
+// @Param count query string false "Total count mode (estimated, exact)" default(estimated)
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(20)
-// @Success 200 {object} response.Response{data=[]models.Job}
+// @Success 200 {object} response.Response{data=[]models.JobView}
+// @Failure 400 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/jobs [get]
 func (h *JobHandler) List(c *fiber.Ctx) error {
 	tenantID := getTenantID(c)
 
 	filter := models.JobFilter{
-		TenantID: &tenantID,
-		Status:   models.JobStatus(c.Query("status")),
-		Type:     models.JobType(c.Query("type")),
-		Name:     c.Query("name"),
-		Page:     c.QueryInt("page", 1),
-		PageSize: c.QueryInt("page_size", 20),
+		TenantID:      &tenantID,
+		Status:        models.JobStatus(c.Query("status")),
+		Type:          models.JobType(c.Query("type")),
+		Name:          c.Query("name"),
+		CorrelationID: c.Query("correlation_id"),
+		Page:          c.QueryInt("page", 1),
+		PageSize:      c.QueryInt("page_size", 20),
+	}
+
+	fields, err := parseFields(c, models.JobFieldColumns)
+	if err != nil {
+		return response.BadRequest(c, "INVALID_FIELDS", err.Error())
+	}
+	include, err := parseIncludes(c, models.ValidJobInclude)
+	if err != nil {
+		return response.BadRequest(c, "INVALID_INCLUDE", err.Error())
+	}
+	if filter.Count, err = parseCountMode(c); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", err.Error())
+	}
+
+	filter.Fields = fields
+	if len(fields) > 0 && len(include) > 0 {
+		// Related resources are looked up by job ID, and embedded ones are always returned
+		if !slices.Contains(fields, "id") {
+			filter.Fields = append([]string{"id"}, fields...)
+		}
+		fields = append(fields, include...)
 	}
 
 	result, err := h.jobService.List(c.Context(), filter)
@@ -105,7 +192,21 @@ func (h *JobHandler) List(c *fiber.Ctx) error {
 		return response.InternalError(c, err.Error())
 	}
 
-	return response.OKWithPagination(c, result.Jobs, &response.Pagination{
+	var page interface{} = result.Jobs
+	if len(include) > 0 {
+		if page, err = h.jobService.Embed(c.Context(), tenantID, result.Jobs, include); err != nil {
+			return response.InternalError(c, err.Error())
+		}
+	}
+
+	jobs, err := projectFields(page, fields)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	setTotalEstimated(c, result.TotalEstimated)
+
+	return response.OKWithPagination(c, jobs, &response.Pagination{
 		Page:    result.Page,
 		PerPage: result.PageSize,
 		Total:   result.TotalCount,
@@ -124,6 +225,7 @@ func (h *JobHandler) List(c *fiber.Ctx) error {
 // @Success 200 {object} response.Response{data=models.Job}
 // @Failure 400 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 413 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/jobs/{id} [put]
 func (h *JobHandler) Update(c *fiber.Ctx) error {
@@ -142,7 +244,7 @@ func (h *JobHandler) Update(c *fiber.Ctx) error {
 
 	job, err := h.jobService.Update(c.Context(), tenantID, id, &req)
 	if err != nil {
-		return response.InternalError(c, err.Error())
+		return jobError(c, err)
 	}
 
 	return response.OK(c, job)
@@ -150,7 +252,7 @@ func (h *JobHandler) Update(c *fiber.Ctx) error {
 
 // Delete deletes a job
 // @Summary Delete a job
-// @Description Soft-delete a job
+// @Description Soft-delete a job; it can be restored until it is purged
 // @Tags jobs
 // @Param id path string true "Job ID"
 // @Success 204 "No Content"
@@ -168,20 +270,234 @@ func (h *JobHandler) Delete(c *fiber.Ctx) error {
 	tenantID := getTenantID(c)
 
 	if err := h.jobService.Delete(c.Context(), tenantID, id); err != nil {
-		return response.InternalError(c, err.Error())
+		return jobError(c, err)
 	}
 
 	return response.NoContent(c)
 }
 
+// Validate checks a job definition without creating it
+// @Summary Validate a job
+// @Description Run every check a create request goes through without creating the job. The result lists each problem with the request field it concerns and, for a valid job, when it would first run. With check_endpoint=true, an http job's endpoint must also accept a TCP connection; no request is sent to it.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param request body models.CreateJobRequest true "Job definition"
+// @Param check_endpoint query bool false "Check that the endpoint accepts connections" default(false)
+// @Success 200 {object} response.Response{data=models.JobValidation}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/jobs/validate [post]
+func (h *JobHandler) Validate(c *fiber.Ctx) error {
+	var req models.CreateJobRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+
+	tenantID := getTenantID(c)
+
+	validation, err := h.jobService.Validate(c.Context(), tenantID, &req, c.QueryBool("check_endpoint"))
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, validation)
+}
+
+// PreviewSchedule lists a schedule's next fire times
+// @Summary Preview a schedule
+// @Description List the next fire times of a schedule before saving a job with it, computed the way the scheduler computes them, including the dates a holiday calendar skips
+// @Tags jobs
+// @Produce json
+// @Param type query string false "Job type (cron, interval, one_time)" default(cron)
+// @Param schedule query string true "Cron expression, interval or RFC3339 timestamp"
+// @Param timezone query string false "Zone the fire times are returned in" default(UTC)
+// @Param holiday_calendar query string false "Holiday calendar whose dates a cron schedule skips"
+// @Param count query int false "Number of fire times, up to 100" default(10)
+// @Success 200 {object} response.Response{data=models.SchedulePreview}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/schedule/preview [get]
+func (h *JobHandler) PreviewSchedule(c *fiber.Ctx) error {
+	req := models.SchedulePreviewRequest{
+		Type:            models.JobType(c.Query("type")),
+		Schedule:        c.Query("schedule"),
+		Timezone:        c.Query("timezone"),
+		HolidayCalendar: c.Query("holiday_calendar"),
+		Count:           c.QueryInt("count", 0),
+	}
+	if req.Schedule == "" {
+		return response.BadRequest(c, "BAD_REQUEST", "schedule is required")
+	}
+
+	tenantID := getTenantID(c)
+
+	preview, err := h.jobService.PreviewSchedule(c.Context(), tenantID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPreview) {
+			return response.BadRequest(c, "INVALID_SCHEDULE", err.Error())
+		}
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, preview)
+}
+
+// SimulateSchedule previews a schedule change
+// @Summary Simulate a schedule change
+// @Description Compare a job's upcoming occurrences under its current schedule with a proposed one, without changing the job
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param request body models.SimulateScheduleRequest true "Proposed schedule"
+// @Success 200 {object} response.Response{data=models.ScheduleSimulation}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/jobs/{id}/simulate [post]
+func (h *JobHandler) SimulateSchedule(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid job ID")
+	}
+
+	var req models.SimulateScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+	if req.Schedule == "" {
+		return response.BadRequest(c, "BAD_REQUEST", "schedule is required")
+	}
+
+	tenantID := getTenantID(c)
+
+	simulation, err := h.jobService.SimulateSchedule(c.Context(), tenantID, id, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return response.NotFound(c, "Job not found")
+		case errors.Is(err, service.ErrInvalidSimulation):
+			return response.BadRequest(c, "INVALID_SCHEDULE", err.Error())
+		}
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, simulation)
+}
+
+// ListRevisions lists a job's revisions
+// @Summary List job revisions
+// @Description List the revisions recorded for every change to a job, newest first, with the user who made each change and the fields it changed against the previous revision
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param limit query int false "Maximum revisions to return, up to 100" default(20)
+// @Success 200 {object} response.Response{data=[]models.JobRevision}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/jobs/{id}/revisions [get]
+func (h *JobHandler) ListRevisions(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid job ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	revisions, err := h.jobService.ListRevisions(c.Context(), tenantID, id, c.QueryInt("limit", 20))
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	return response.OK(c, revisions)
+}
+
+// Rollback rolls a job back to an earlier revision
+// @Summary Roll a job back to a revision
+// @Description Restore a job's definition as it was at an earlier revision, keeping its status and counters. The rollback is recorded as a new revision.
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} response.Response{data=models.Job}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/jobs/{id}/revisions/{rev}/rollback [post]
+func (h *JobHandler) Rollback(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid job ID")
+	}
+	revision, err := c.ParamsInt("rev")
+	if err != nil || revision < 1 {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid revision number")
+	}
+
+	tenantID := getTenantID(c)
+
+	job, err := h.jobService.Rollback(c.Context(), tenantID, id, revision)
+	if err != nil {
+		if errors.Is(err, service.ErrRevisionNotFound) {
+			return response.NotFound(c, "Job revision not found")
+		}
+		return jobError(c, err)
+	}
+
+	return response.OK(c, job)
+}
+
+// Restore restores a deleted job
+// @Summary Restore a deleted job
+// @Description Undelete a job before its purge; it comes back paused
+// @Tags jobs
+// @Param id path string true "Job ID"
+// @Success 200 {object} response.Response{data=models.Job}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/jobs/{id}/restore [post]
+func (h *JobHandler) Restore(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid job ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	job, err := h.jobService.Restore(c.Context(), tenantID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return response.NotFound(c, "Job not found")
+		case errors.Is(err, service.ErrJobNotDeleted):
+			return errorResponse(c, fiber.StatusConflict, "JOB_NOT_DELETED", err.Error())
+		}
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, job)
+}
+
 // Trigger manually triggers a job
 // @Summary Trigger a job
-// @Description Manually trigger a job execution
+// @Description Manually trigger a job execution, optionally tied to a different business entity than the job's correlation_id. Triggers over the job's trigger_limit per minute are refused with 429, and an identical trigger within trigger_dedupe_seconds whose execution hasn't started returns that execution.
 // @Tags jobs
+// @Accept json
 // @Param id path string true "Job ID"
+// @Param request body models.TriggerJobRequest false "Correlation ID for this execution"
 // @Success 200 {object} response.Response{data=models.JobExecution}
+// @Header 200 {string} X-Trigger-Deduplicated "true when an identical pending trigger's execution was returned"
 // @Failure 400 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 429 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/jobs/{id}/trigger [post]
 func (h *JobHandler) Trigger(c *fiber.Ctx) error {
@@ -191,21 +507,37 @@ func (h *JobHandler) Trigger(c *fiber.Ctx) error {
 		return response.BadRequest(c, "BAD_REQUEST", "Invalid job ID")
 	}
 
+	var req models.TriggerJobRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+		}
+	}
+
 	tenantID := getTenantID(c)
 
-	execution, err := h.jobService.Trigger(c.Context(), tenantID, id)
+	execution, deduplicated, err := h.jobService.Trigger(c.Context(), tenantID, id, req.CorrelationID)
 	if err != nil {
-		return response.InternalError(c, err.Error())
+		var limitErr *scheduler.TriggerLimitError
+		if errors.As(err, &limitErr) {
+			return triggerLimited(c, limitErr)
+		}
+		return jobError(c, err)
 	}
 
+	if deduplicated {
+		c.Set("X-Trigger-Deduplicated", "true")
+	}
 	return response.OK(c, execution)
 }
 
 // Pause pauses a job
 // @Summary Pause a job
-// @Description Pause a job from executing
+// @Description Pause a job from executing, optionally until resume_at, when it resumes on its own. Pausing a paused job again replaces or clears its resume time.
 // @Tags jobs
+// @Accept json
 // @Param id path string true "Job ID"
+// @Param request body models.PauseJobRequest false "Resume time"
 // @Success 200 {object} response.Response{data=models.Job}
 // @Failure 400 {object} response.Response
 // @Failure 404 {object} response.Response
@@ -218,11 +550,18 @@ func (h *JobHandler) Pause(c *fiber.Ctx) error {
 		return response.BadRequest(c, "BAD_REQUEST", "Invalid job ID")
 	}
 
+	var req models.PauseJobRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+		}
+	}
+
 	tenantID := getTenantID(c)
 
-	job, err := h.jobService.UpdateStatus(c.Context(), tenantID, id, models.JobStatusPaused)
+	job, err := h.jobService.Pause(c.Context(), tenantID, id, req.ResumeAt)
 	if err != nil {
-		return response.InternalError(c, err.Error())
+		return jobError(c, err)
 	}
 
 	return response.OK(c, job)
@@ -249,7 +588,65 @@ func (h *JobHandler) Resume(c *fiber.Ctx) error {
 
 	job, err := h.jobService.UpdateStatus(c.Context(), tenantID, id, models.JobStatusActive)
 	if err != nil {
-		return response.InternalError(c, err.Error())
+		return jobError(c, err)
+	}
+
+	return response.OK(c, job)
+}
+
+// StartCanary runs a canary execution before a job's schedule starts
+// @Summary Enable a job with a canary
+// @Description Hold a paused, disabled or held job's schedule and run a canary execution of it now. The canary runs once, without retries, and isn't counted in the job's history, incidents, alerts or callbacks. The job is activated when the canary passes; otherwise it stays in the canary status until another canary passes or it is confirmed.
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} response.Response{data=models.JobExecution}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/jobs/{id}/canary [post]
+func (h *JobHandler) StartCanary(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid job ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	execution, err := h.jobService.StartCanary(c.Context(), tenantID, id)
+	if err != nil {
+		return jobError(c, err)
+	}
+
+	return response.OK(c, execution)
+}
+
+// ConfirmCanary starts the schedule of a job held for its canary
+// @Summary Confirm a job's canary
+// @Description Start the schedule of a job in the canary status whatever its canary's outcome, e.g. after a failure that is expected
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} response.Response{data=models.Job}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/jobs/{id}/canary/confirm [post]
+func (h *JobHandler) ConfirmCanary(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid job ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	job, err := h.jobService.ConfirmCanary(c.Context(), tenantID, id)
+	if err != nil {
+		return jobError(c, err)
 	}
 
 	return response.OK(c, job)
@@ -274,16 +671,221 @@ func (h *JobHandler) GetStats(c *fiber.Ctx) error {
 	return response.OK(c, stats)
 }
 
+// GetStale lists jobs that look dead
+// @Summary List stale jobs
+// @Description List the tenant's jobs that look dead and may be ready to clean up: recurring jobs that haven't run in many times their schedule period, jobs whose endpoint has answered 404 for a long time and paused jobs left untouched
+// @Tags jobs
+// @Produce json
+// @Success 200 {object} response.Response{data=models.JanitorReport}
+// @Failure 500 {object} response.Response
+// @Router /api/v1/jobs/stale [get]
+func (h *JobHandler) GetStale(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	report, err := h.jobService.StaleReport(c.Context(), tenantID)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, report)
+}
+
+// Export exports all jobs for the tenant
+// @Summary Export jobs
+// @Description Export the tenant's jobs as a portable JSON or YAML document, or a CSV sheet, without IDs or run counters
+// @Tags jobs
+// @Produce json
+// @Produce application/yaml
+// @Produce text/csv
+// @Param format query string false "Document format (json, yaml, csv)" default(json)
+// @Success 200 {object} models.JobExport
+// @Failure 500 {object} response.Response
+// @Router /api/v1/jobs/export [get]
+func (h *JobHandler) Export(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	export, err := h.jobService.Export(c.Context(), tenantID)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	filename := fmt.Sprintf("jobs-%s", time.Now().UTC().Format("20060102-150405"))
+
+	if wantsCSV(c.Query("format"), c.Get(fiber.HeaderAccept)) {
+		body, err := jobsToCSV(export.Jobs)
+		if err != nil {
+			return response.InternalError(c, err.Error())
+		}
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+		c.Set(fiber.HeaderContentType, mimeCSV)
+		return c.Send(body)
+	}
+
+	if wantsYAML(c.Query("format"), c.Get(fiber.HeaderAccept)) {
+		body, err := toYAML(export)
+		if err != nil {
+			return response.InternalError(c, err.Error())
+		}
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.yaml"`, filename))
+		c.Set(fiber.HeaderContentType, "application/yaml")
+		return c.Send(body)
+	}
+
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+	return c.JSON(export)
+}
+
+// Import imports jobs from an export document
+// @Summary Import jobs
+// @Description Recreate jobs from an export document. All jobs are validated before any is written.
+// @Tags jobs
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Param on_conflict query string false "Handling of jobs whose name already exists (skip, update, create)" default(skip)
+// @Param request body models.JobExport true "Export document"
+// @Success 200 {object} response.Response{data=models.JobImportResult}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/jobs/import [post]
+func (h *JobHandler) Import(c *fiber.Ctx) error {
+	var doc models.JobExport
+
+	var err error
+	if wantsYAML("", c.Get(fiber.HeaderContentType)) {
+		err = fromYAML(c.Body(), &doc)
+	} else {
+		err = json.Unmarshal(c.Body(), &doc)
+	}
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid export document")
+	}
+
+	tenantID := getTenantID(c)
+
+	result, err := h.jobService.Import(c.Context(), tenantID, &doc, c.Query("on_conflict"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", err.Error())
+	}
+
+	// Nothing is written when validation fails, so report it as a bad request
+	if result.Created == 0 && result.Updated == 0 && len(result.Errors) > 0 {
+		messages := make([]string, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			messages = append(messages, fmt.Sprintf("jobs[%d] %q: %s", e.Index, e.Name, e.Error))
+		}
+		return response.BadRequest(c, "IMPORT_FAILED", strings.Join(messages, "; "))
+	}
+
+	return response.OK(c, result)
+}
+
+// BatchUpsert creates or updates many jobs keyed by external_id
+// @Summary Batch upsert jobs
+// @Description Create or update up to 5000 jobs keyed by external_id in one transaction, for syncing from an upstream catalog. All jobs are validated before any is written, jobs whose definition already matches are left alone, and a failure writing any job rolls the whole batch back.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param request body models.BatchUpsertJobsRequest true "Jobs to upsert"
+// @Success 200 {object} response.Response{data=models.BatchUpsertResult}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/jobs/batch [put]
+func (h *JobHandler) BatchUpsert(c *fiber.Ctx) error {
+	var req models.BatchUpsertJobsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+
+	tenantID := getTenantID(c)
+
+	result, err := h.jobService.BatchUpsert(c.Context(), tenantID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBatch) {
+			return response.BadRequest(c, "BAD_REQUEST", err.Error())
+		}
+		return response.InternalError(c, err.Error())
+	}
+
+	// Nothing is written when validation fails, so report it as a bad request
+	if len(result.Errors) > 0 {
+		messages := make([]string, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			messages = append(messages, fmt.Sprintf("jobs[%d] %q: %s", e.Index, e.ExternalID, e.Error))
+		}
+		return response.BadRequest(c, "BATCH_UPSERT_FAILED", strings.Join(messages, "; "))
+	}
+
+	return response.OK(c, result)
+}
+
 // getTenantID extracts the tenant ID from context
 func getTenantID(c *fiber.Ctx) uuid.UUID {
-	tenantIDStr := c.Get("X-Tenant-ID")
-	if tenantIDStr == "" {
-		tenantIDStr = c.Query("tenant_id")
+	return middleware.TenantID(c)
+}
+
+// getUserID extracts the acting user ID from context, if present
+func getUserID(c *fiber.Ctx) *uuid.UUID {
+	return middleware.UserID(c)
+}
+
+// wantsYAML reports whether a format parameter or media type asks for YAML
+func wantsYAML(format, mediaType string) bool {
+	if format != "" {
+		return strings.EqualFold(format, "yaml") || strings.EqualFold(format, "yml")
 	}
+	return strings.Contains(mediaType, "yaml")
+}
 
-	tenantID, err := uuid.Parse(tenantIDStr)
+// toYAML renders v as YAML using its JSON field names
+func toYAML(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
 	if err != nil {
-		return uuid.Nil
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(generic)
+}
+
+// fromYAML decodes a YAML document into v using its JSON field names
+func fromYAML(body []byte, v interface{}) error {
+	var generic interface{}
+	if err := yaml.Unmarshal(body, &generic); err != nil {
+		return err
 	}
-	return tenantID
+	raw, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// jobError maps job service errors to responses: validation failures to 400 with the fields at
+// fault, a missing job to 404 and a conflict with the job's state to 409
+func jobError(c *fiber.Ctx, err error) error {
+	var invalid *service.ValidationError
+	switch {
+	case errors.Is(err, service.ErrPayloadTooLarge):
+		return errorResponse(c, fiber.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", err.Error())
+	case errors.As(err, &invalid):
+		return validationErrorResponse(c, err.Error(), invalid.Fields)
+	case errors.Is(err, service.ErrValidation):
+		return response.BadRequest(c, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, service.ErrNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, "Job not found")
+	case errors.Is(err, service.ErrConflict):
+		return errorResponse(c, fiber.StatusConflict, "CONFLICT", err.Error())
+	case errors.Is(err, service.ErrAdmissionUnavailable):
+		return errorResponse(c, fiber.StatusServiceUnavailable, "ADMISSION_UNAVAILABLE", err.Error())
+	}
+	return response.InternalError(c, err.Error())
+}
+
+// triggerLimited answers a request over a job's manual trigger limit with 429 and when to retry
+func triggerLimited(c *fiber.Ctx, limitErr *scheduler.TriggerLimitError) error {
+	retryAfter := int(math.Ceil(time.Until(limitErr.RetryAt).Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(retryAfter, 1)))
+	return errorResponse(c, fiber.StatusTooManyRequests, "TRIGGER_RATE_LIMITED", limitErr.Error())
 }
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	mimeCSV     = "text/csv"
+	mimeMsgpack = "application/msgpack"
+)
+
+// jobCSVColumns are the export columns, in order; object and array fields are JSON-encoded
+var jobCSVColumns = []string{
+	"client_reference", "name", "description", "type", "schedule", "timezone",
+	"target_type", "target_config", "endpoint", "method", "headers", "payload", "content_type", "body", "timeout", "max_retries",
+	"retry_delay", "priority", "tags", "metadata", "response_projection",
+	"concurrency_policy", "misfire_policy", "status",
+}
+
+// wantsMsgpack reports whether the Accept header prefers MessagePack over JSON
+func wantsMsgpack(c *fiber.Ctx) bool {
+	switch c.Accepts(fiber.MIMEApplicationJSON, mimeMsgpack, "application/x-msgpack") {
+	case mimeMsgpack, "application/x-msgpack":
+		return true
+	}
+	return false
+}
+
+// wantsCSV reports whether a format parameter or media type asks for CSV
+func wantsCSV(format, mediaType string) bool {
+	if format != "" {
+		return strings.EqualFold(format, "csv")
+	}
+	return strings.Contains(mediaType, mimeCSV)
+}
+
+// sendMsgpack writes v as MessagePack using its JSON field names
+func sendMsgpack(c *fiber.Ctx, v interface{}) error {
+	generic, err := toGeneric(v)
+	if err != nil {
+		return err
+	}
+	body, err := msgpack.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, mimeMsgpack)
+	return c.Send(body)
+}
+
+// jobsToCSV renders exported jobs as CSV with a header row
+func jobsToCSV(jobs []models.JobSpec) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(jobCSVColumns); err != nil {
+		return nil, err
+	}
+
+	for _, job := range jobs {
+		generic, err := toGeneric(job)
+		if err != nil {
+			return nil, err
+		}
+		fields, _ := generic.(map[string]interface{})
+
+		record := make([]string, len(jobCSVColumns))
+		for i, column := range jobCSVColumns {
+			if record[i], err = csvValue(fields[column]); err != nil {
+				return nil, err
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// csvValue formats a decoded JSON value as a CSV cell
+func csvValue(v interface{}) (string, error) {
+	switch value := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case int64, float64, bool:
+		return fmt.Sprint(value), nil
+	}
+	raw, err := json.Marshal(v)
+	return string(raw), err
+}
+
+// toGeneric round-trips v through JSON so other encoders see its JSON field names
+// and embedded raw JSON as structured values. Integers stay integers.
+func toGeneric(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return normalizeNumbers(generic), nil
+}
+
+// normalizeNumbers replaces json.Number values with int64 or float64
+func normalizeNumbers(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = normalizeNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalizeNumbers(item)
+		}
+	case json.Number:
+		if n, err := value.Int64(); err == nil {
+			return n
+		}
+		f, _ := value.Float64()
+		return f
+	}
+	return v
+}
@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/service"
+	"gorm.io/gorm"
+)
+
+// NotificationHandler handles notification channel and policy HTTP requests
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// GetPolicy retrieves the tenant's notification policy
+// @Summary Get notification policy
+// @Description Get the tenant's dedupe, quiet hours and escalation policy
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} response.Response{data=models.NotificationPolicy}
+// @Router /api/v1/notifications/policy [get]
+func (h *NotificationHandler) GetPolicy(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	return response.OK(c, h.notificationService.GetPolicy(c.Context(), tenantID))
+}
+
+// UpdatePolicy updates the tenant's notification policy
+// @Summary Update notification policy
+// @Description Update the tenant's dedupe, quiet hours and escalation policy
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body models.UpdatePolicyRequest true "Policy update"
+// @Success 200 {object} response.Response{data=models.NotificationPolicy}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/notifications/policy [put]
+func (h *NotificationHandler) UpdatePolicy(c *fiber.Ctx) error {
+	var req models.UpdatePolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+
+	tenantID := getTenantID(c)
+
+	policy, err := h.notificationService.UpdatePolicy(c.Context(), tenantID, &req)
+	if err != nil {
+		return notificationError(c, err)
+	}
+
+	return response.OK(c, policy)
+}
+
+// ListChannels lists the tenant's notification channels
+// @Summary List notification channels
+// @Description List the tenant's notification channels
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.NotificationChannel}
+// @Failure 500 {object} response.Response
+// @Router /api/v1/notifications/channels [get]
+func (h *NotificationHandler) ListChannels(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	channels, err := h.notificationService.ListChannels(c.Context(), tenantID)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, channels)
+}
+
+// CreateChannel creates a notification channel
+// @Summary Create a notification channel
+// @Description Create a notification channel for the tenant
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body models.CreateChannelRequest true "Channel to create"
+// @Success 201 {object} response.Response{data=models.NotificationChannel}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/notifications/channels [post]
+func (h *NotificationHandler) CreateChannel(c *fiber.Ctx) error {
+	var req models.CreateChannelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
+	}
+
+	tenantID := getTenantID(c)
+
+	channel, err := h.notificationService.CreateChannel(c.Context(), tenantID, &req)
+	if err != nil {
+		return notificationError(c, err)
+	}
+
+	return response.Created(c, channel)
+}
+
+// UpdateChannel updates a notification channel
+// @Summary Update a notification channel
+// @Description Update a notification channel
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "Channel ID"
+// @Param request body models.UpdateChannelRequest true "Channel update"
+// @Success 200 {object} response.Response{data=models.NotificationChannel}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/notifications/channels/{id} [put]
+func (h *NotificationHandler) UpdateChannel(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid channel ID")
+	}
+
+	var req models.UpdateChannelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+
+	tenantID := getTenantID(c)
+
+	channel, err := h.notificationService.UpdateChannel(c.Context(), tenantID, id, &req)
+	if err != nil {
+		return notificationError(c, err)
+	}
+
+	return response.OK(c, channel)
+}
+
+// DeleteChannel deletes a notification channel
+// @Summary Delete a notification channel
+// @Description Delete a notification channel
+// @Tags notifications
+// @Param id path string true "Channel ID"
+// @Success 204
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/notifications/channels/{id} [delete]
+func (h *NotificationHandler) DeleteChannel(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid channel ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	if err := h.notificationService.DeleteChannel(c.Context(), tenantID, id); err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.NoContent(c)
+}
+
+// ListSubscriptions lists the current user's subscriptions
+// @Summary List notification subscriptions
+// @Description List the calling user's job and tag subscriptions
+// @Tags notifications
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Success 200 {object} response.Response{data=[]models.NotificationSubscription}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/notifications/subscriptions [get]
+func (h *NotificationHandler) ListSubscriptions(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	if userID == nil {
+		return response.BadRequest(c, "BAD_REQUEST", "X-User-ID header is required")
+	}
+
+	tenantID := getTenantID(c)
+
+	subscriptions, err := h.notificationService.ListSubscriptions(c.Context(), tenantID, *userID)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, subscriptions)
+}
+
+// Subscribe subscribes the current user to a job or tag selector
+// @Summary Create a notification subscription
+// @Description Route events for a job, or for jobs carrying all given tags, to one of the tenant's channels
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param request body models.CreateSubscriptionRequest true "Subscription"
+// @Success 201 {object} response.Response{data=models.NotificationSubscription}
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/notifications/subscriptions [post]
+func (h *NotificationHandler) Subscribe(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	if userID == nil {
+		return response.BadRequest(c, "BAD_REQUEST", "X-User-ID header is required")
+	}
+
+	var req models.CreateSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
+	}
+
+	tenantID := getTenantID(c)
+
+	subscription, err := h.notificationService.Subscribe(c.Context(), tenantID, *userID, &req)
+	if err != nil {
+		return notificationError(c, err)
+	}
+
+	return response.Created(c, subscription)
+}
+
+// Unsubscribe deletes one of the current user's subscriptions
+// @Summary Delete a notification subscription
+// @Description Delete one of the calling user's subscriptions
+// @Tags notifications
+// @Param X-User-ID header string true "User ID"
+// @Param id path string true "Subscription ID"
+// @Success 204
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/notifications/subscriptions/{id} [delete]
+func (h *NotificationHandler) Unsubscribe(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	if userID == nil {
+		return response.BadRequest(c, "BAD_REQUEST", "X-User-ID header is required")
+	}
+
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid subscription ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	if err := h.notificationService.Unsubscribe(c.Context(), tenantID, *userID, id); err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.NoContent(c)
+}
+
+// notificationError maps notification service errors to responses
+func notificationError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return response.NotFound(c, "Channel not found")
+	}
+	if errors.Is(err, service.ErrInvalidNotificationConfig) {
+		return response.BadRequest(c, "VALIDATION_ERROR", err.Error())
+	}
+	return response.InternalError(c, err.Error())
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/validation"
+)
+
+// errorResponse writes the standard error envelope for statuses without a response helper
+func errorResponse(c *fiber.Ctx, status int, code, message string) error {
+	return c.Status(status).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+// validationErrorResponse writes a 400 in the standard error envelope, with the request fields
+// that failed validation in details
+func validationErrorResponse(c *fiber.Ctx, message string, fields []models.FieldError) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    "VALIDATION_ERROR",
+			"message": message,
+			"details": fields,
+		},
+	})
+}
+
+// validateRequest checks a request body against its validate tags. When any field fails one, it
+// writes a 400 listing them and reports false.
+func validateRequest(c *fiber.Ctx, req interface{}) (bool, error) {
+	fields := validation.Struct(req)
+	if len(fields) == 0 {
+		return true, nil
+	}
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	return false, validationErrorResponse(c, strings.Join(messages, "; "), fields)
+}
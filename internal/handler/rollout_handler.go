@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/service"
+	"gorm.io/gorm"
+)
+
+// RolloutHandler handles rollout HTTP requests
+type RolloutHandler struct {
+	rolloutService *service.RolloutService
+}
+
+// NewRolloutHandler creates a new rollout handler
+func NewRolloutHandler(rolloutService *service.RolloutService) *RolloutHandler {
+	return &RolloutHandler{
+		rolloutService: rolloutService,
+	}
+}
+
+// List lists rollouts
+// @Summary List rollouts
+// @Description List rollouts across tenants, newest first. Needs the platform role.
+// @Tags rollouts
+// @Produce json
+// @Param status query string false "Only rollouts in this status (active, halted, completed, rolled_back)"
+// @Success 200 {object} response.Response{data=[]models.Rollout}
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/rollouts [get]
+func (h *RolloutHandler) List(c *fiber.Ctx) error {
+	rollouts, err := h.rolloutService.List(c.Context(), models.RolloutStatus(c.Query("status")))
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, rollouts)
+}
+
+// Get retrieves a rollout
+// @Summary Get a rollout
+// @Description Get a rollout with its progress and the failure rate of the jobs it changed so far. Needs the platform role.
+// @Tags rollouts
+// @Produce json
+// @Param id path string true "Rollout ID"
+// @Success 200 {object} response.Response{data=models.Rollout}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/rollouts/{id} [get]
+func (h *RolloutHandler) Get(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid rollout ID")
+	}
+
+	rollout, err := h.rolloutService.Get(c.Context(), id)
+	if err != nil {
+		return rolloutError(c, err)
+	}
+
+	return response.OK(c, rollout)
+}
+
+// ListJobs lists a rollout's jobs
+// @Summary List a rollout's jobs
+// @Description List the jobs of a rollout in the order they are changed, with the schedule and endpoint each had before. Needs the platform role.
+// @Tags rollouts
+// @Produce json
+// @Param id path string true "Rollout ID"
+// @Success 200 {object} response.Response{data=[]models.RolloutJob}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/rollouts/{id}/jobs [get]
+func (h *RolloutHandler) ListJobs(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid rollout ID")
+	}
+
+	jobs, err := h.rolloutService.ListJobs(c.Context(), id)
+	if err != nil {
+		return rolloutError(c, err)
+	}
+
+	return response.OK(c, jobs)
+}
+
+// Create starts a rollout
+// @Summary Start a rollout
+// @Description Roll a schedule or endpoint change out to every job with an external ID, across tenants or in the listed ones, a share of them per hour. Before each batch the failure rate of the jobs already changed is compared with the fleet's over the day before, and the rollout halts when it rose by more than failure_threshold. Needs the platform role.
+// @Tags rollouts
+// @Accept json
+// @Produce json
+// @Param request body models.CreateRolloutRequest true "Fleet, change and pace"
+// @Success 201 {object} response.Response{data=models.Rollout}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/rollouts [post]
+func (h *RolloutHandler) Create(c *fiber.Ctx) error {
+	var req models.CreateRolloutRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
+	}
+
+	rollout, err := h.rolloutService.Create(c.Context(), getUserID(c), &req)
+	if err != nil {
+		return rolloutError(c, err)
+	}
+
+	return response.Created(c, rollout)
+}
+
+// Halt stops a rollout
+// @Summary Halt a rollout
+// @Description Stop a rollout before its next batch. Jobs it already changed keep the change until it is rolled back. Needs the platform role.
+// @Tags rollouts
+// @Accept json
+// @Produce json
+// @Param id path string true "Rollout ID"
+// @Param request body models.HaltRolloutRequest false "Halt reason"
+// @Success 200 {object} response.Response{data=models.Rollout}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/rollouts/{id}/halt [post]
+func (h *RolloutHandler) Halt(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid rollout ID")
+	}
+
+	var req models.HaltRolloutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+		}
+	}
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
+	}
+
+	rollout, err := h.rolloutService.Halt(c.Context(), id, req.Reason)
+	if err != nil {
+		return rolloutError(c, err)
+	}
+
+	return response.OK(c, rollout)
+}
+
+// Resume resumes a halted rollout
+// @Summary Resume a rollout
+// @Description Start a halted rollout again with its next batch. Only executions from now on are judged. Needs the platform role.
+// @Tags rollouts
+// @Produce json
+// @Param id path string true "Rollout ID"
+// @Success 200 {object} response.Response{data=models.Rollout}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/rollouts/{id}/resume [post]
+func (h *RolloutHandler) Resume(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid rollout ID")
+	}
+
+	rollout, err := h.rolloutService.Resume(c.Context(), id)
+	if err != nil {
+		return rolloutError(c, err)
+	}
+
+	return response.OK(c, rollout)
+}
+
+// Rollback rolls a rollout back
+// @Summary Roll back a rollout
+// @Description Stop a rollout and put the jobs it changed back on their previous schedule and endpoint. Jobs changed again since keep the newer values. Needs the platform role.
+// @Tags rollouts
+// @Produce json
+// @Param id path string true "Rollout ID"
+// @Success 200 {object} response.Response{data=models.Rollout}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/rollouts/{id}/rollback [post]
+func (h *RolloutHandler) Rollback(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid rollout ID")
+	}
+
+	rollout, err := h.rolloutService.Rollback(c.Context(), id)
+	if err != nil {
+		return rolloutError(c, err)
+	}
+
+	return response.OK(c, rollout)
+}
+
+// rolloutError maps rollout service errors to responses
+func rolloutError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, "Rollout not found")
+	case errors.Is(err, service.ErrInvalidRollout):
+		return response.BadRequest(c, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, service.ErrJobInRollout):
+		return errorResponse(c, fiber.StatusConflict, "JOB_IN_ROLLOUT", err.Error())
+	case errors.Is(err, service.ErrRolloutNotActive):
+		return errorResponse(c, fiber.StatusConflict, "ROLLOUT_NOT_ACTIVE", err.Error())
+	case errors.Is(err, service.ErrRolloutNotHalted):
+		return errorResponse(c, fiber.StatusConflict, "ROLLOUT_NOT_HALTED", err.Error())
+	case errors.Is(err, service.ErrRolloutRolledBack):
+		return errorResponse(c, fiber.StatusConflict, "ROLLOUT_ROLLED_BACK", err.Error())
+	}
+	return response.InternalError(c, err.Error())
+}
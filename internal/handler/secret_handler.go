@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/secrets"
+	"github.com/minisource/scheduler/internal/service"
+	"gorm.io/gorm"
+)
+
+// SecretHandler handles secret HTTP requests
+type SecretHandler struct {
+	secretService *service.SecretService
+}
+
+// NewSecretHandler creates a new secret handler
+func NewSecretHandler(secretService *service.SecretService) *SecretHandler {
+	return &SecretHandler{
+		secretService: secretService,
+	}
+}
+
+// List lists the tenant's secrets
+// @Summary List secrets
+// @Description List the tenant's secrets. Values are never returned.
+// @Tags secrets
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.Secret}
+// @Failure 500 {object} response.Response
+// @Router /api/v1/secrets [get]
+func (h *SecretHandler) List(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	list, err := h.secretService.List(c.Context(), tenantID)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, list)
+}
+
+// Get retrieves a secret
+// @Summary Get a secret
+// @Description Get a secret's metadata. The value is never returned.
+// @Tags secrets
+// @Produce json
+// @Param name path string true "Secret name"
+// @Success 200 {object} response.Response{data=models.Secret}
+// @Failure 404 {object} response.Response
+// @Router /api/v1/secrets/{name} [get]
+func (h *SecretHandler) Get(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	secret, err := h.secretService.Get(c.Context(), tenantID, c.Params("name"))
+	if err != nil {
+		return secretError(c, err)
+	}
+
+	return response.OK(c, secret)
+}
+
+// Create stores a secret
+// @Summary Create a secret
+// @Description Encrypt and store a secret that jobs reference as {{secret:NAME}}
+// @Tags secrets
+// @Accept json
+// @Produce json
+// @Param request body models.CreateSecretRequest true "Secret to create"
+// @Success 201 {object} response.Response{data=models.Secret}
+// @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /api/v1/secrets [post]
+func (h *SecretHandler) Create(c *fiber.Ctx) error {
+	var req models.CreateSecretRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
+	}
+
+	tenantID := getTenantID(c)
+
+	secret, err := h.secretService.Create(c.Context(), tenantID, getUserID(c), &req)
+	if err != nil {
+		return secretError(c, err)
+	}
+
+	return response.Created(c, secret)
+}
+
+// Update replaces a secret's value or description
+// @Summary Update a secret
+// @Description Replace a secret's value or description
+// @Tags secrets
+// @Accept json
+// @Produce json
+// @Param name path string true "Secret name"
+// @Param request body models.UpdateSecretRequest true "Secret update"
+// @Success 200 {object} response.Response{data=models.Secret}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /api/v1/secrets/{name} [put]
+func (h *SecretHandler) Update(c *fiber.Ctx) error {
+	var req models.UpdateSecretRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+
+	tenantID := getTenantID(c)
+
+	secret, err := h.secretService.Update(c.Context(), tenantID, c.Params("name"), &req)
+	if err != nil {
+		return secretError(c, err)
+	}
+
+	return response.OK(c, secret)
+}
+
+// Delete deletes a secret
+// @Summary Delete a secret
+// @Description Delete a secret. Jobs still referencing it fail at their next run.
+// @Tags secrets
+// @Param name path string true "Secret name"
+// @Success 204
+// @Failure 404 {object} response.Response
+// @Router /api/v1/secrets/{name} [delete]
+func (h *SecretHandler) Delete(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	if err := h.secretService.Delete(c.Context(), tenantID, c.Params("name")); err != nil {
+		return secretError(c, err)
+	}
+
+	return response.NoContent(c)
+}
+
+// secretError maps secret service errors to responses
+func secretError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, "Secret not found")
+	case errors.Is(err, service.ErrInvalidSecret):
+		return response.BadRequest(c, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, service.ErrSecretExists):
+		return errorResponse(c, fiber.StatusConflict, "SECRET_EXISTS", err.Error())
+	case errors.Is(err, secrets.ErrDisabled):
+		return response.ServiceUnavailable(c, err.Error())
+	}
+	return response.InternalError(c, err.Error())
+}
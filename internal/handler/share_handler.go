@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/service"
+	"gorm.io/gorm"
+)
+
+// ShareHandler handles public job share links
+type ShareHandler struct {
+	shareService *service.ShareService
+}
+
+// NewShareHandler creates a new share handler
+func NewShareHandler(shareService *service.ShareService) *ShareHandler {
+	return &ShareHandler{
+		shareService: shareService,
+	}
+}
+
+// CreateLink creates a public share link for a job
+// @Summary Create a share link
+// @Description Create an expiring, signed read-only link to a job's status and recent executions
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param request body models.CreateShareLinkRequest false "Link options"
+// @Success 201 {object} response.Response{data=models.ShareLink}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /api/v1/jobs/{id}/share [post]
+func (h *ShareHandler) CreateLink(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid job ID")
+	}
+
+	var req models.CreateShareLinkRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+		}
+	}
+
+	tenantID := getTenantID(c)
+
+	link, err := h.shareService.CreateLink(c.Context(), tenantID, id, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrSharingDisabled) {
+			return response.ServiceUnavailable(c, err.Error())
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.NotFound(c, "Job not found")
+		}
+		return response.BadRequest(c, "BAD_REQUEST", err.Error())
+	}
+
+	return response.Created(c, link)
+}
+
+// PublicStatus shows a shared job's status without authentication
+// @Summary Shared job status
+// @Description Read-only job status and recent executions for a share link
+// @Tags public
+// @Produce json
+// @Param token path string true "Share token"
+// @Success 200 {object} response.Response{data=models.PublicJobStatus}
+// @Failure 404 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /api/v1/public/jobs/{token} [get]
+func (h *ShareHandler) PublicStatus(c *fiber.Ctx) error {
+	status, err := h.shareService.Resolve(c.Context(), c.Params("token"))
+	if err != nil {
+		if errors.Is(err, service.ErrSharingDisabled) {
+			return response.ServiceUnavailable(c, err.Error())
+		}
+		if errors.Is(err, service.ErrInvalidShareToken) {
+			return response.NotFound(c, err.Error())
+		}
+		return response.InternalError(c, err.Error())
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return response.OK(c, status)
+}
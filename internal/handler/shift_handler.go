@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/service"
+	"gorm.io/gorm"
+)
+
+// ShiftHandler handles schedule shift HTTP requests
+type ShiftHandler struct {
+	shiftService *service.ShiftService
+}
+
+// NewShiftHandler creates a new schedule shift handler
+func NewShiftHandler(shiftService *service.ShiftService) *ShiftHandler {
+	return &ShiftHandler{
+		shiftService: shiftService,
+	}
+}
+
+// List lists the tenant's schedule shifts
+// @Summary List schedule shifts
+// @Description List the tenant's active and reverted schedule shifts, newest first
+// @Tags shifts
+// @Produce json
+// @Success 200 {object} response.Response{data=[]models.ScheduleShift}
+// @Failure 500 {object} response.Response
+// @Router /api/v1/shifts [get]
+func (h *ShiftHandler) List(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	shifts, err := h.shiftService.List(c.Context(), tenantID)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, shifts)
+}
+
+// Get retrieves a schedule shift
+// @Summary Get a schedule shift
+// @Description Get a schedule shift and the jobs it was applied to
+// @Tags shifts
+// @Produce json
+// @Param id path string true "Shift ID"
+// @Success 200 {object} response.Response{data=models.ScheduleShift}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/shifts/{id} [get]
+func (h *ShiftHandler) Get(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid shift ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	shift, err := h.shiftService.Get(c.Context(), tenantID, id)
+	if err != nil {
+		return shiftError(c, err)
+	}
+
+	return response.OK(c, shift)
+}
+
+// Create shifts a set of jobs' schedules
+// @Summary Shift job schedules
+// @Description Move the occurrences of the picked jobs that fall in a window by an offset, e.g. during a regional failover. The shift is reverted automatically once its moved occurrences have passed.
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param request body models.CreateScheduleShiftRequest true "Jobs, offset and window"
+// @Success 201 {object} response.Response{data=models.ScheduleShift}
+// @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/shifts [post]
+func (h *ShiftHandler) Create(c *fiber.Ctx) error {
+	var req models.CreateScheduleShiftRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+
+	tenantID := getTenantID(c)
+
+	shift, err := h.shiftService.Create(c.Context(), tenantID, getUserID(c), &req)
+	if err != nil {
+		return shiftError(c, err)
+	}
+
+	return response.Created(c, shift)
+}
+
+// Revert ends a schedule shift early
+// @Summary Revert a schedule shift
+// @Description Put a shift's jobs back on their own schedules before the shift ends
+// @Tags shifts
+// @Produce json
+// @Param id path string true "Shift ID"
+// @Success 200 {object} response.Response{data=models.ScheduleShift}
+// @Failure 400 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/shifts/{id}/revert [post]
+func (h *ShiftHandler) Revert(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid shift ID")
+	}
+
+	tenantID := getTenantID(c)
+
+	shift, err := h.shiftService.Revert(c.Context(), tenantID, id)
+	if err != nil {
+		return shiftError(c, err)
+	}
+
+	return response.OK(c, shift)
+}
+
+// shiftError maps schedule shift service errors to responses
+func shiftError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, "Schedule shift not found")
+	case errors.Is(err, service.ErrInvalidShift):
+		return response.BadRequest(c, "VALIDATION_ERROR", err.Error())
+	case errors.Is(err, service.ErrJobShifted):
+		return errorResponse(c, fiber.StatusConflict, "JOB_SHIFTED", err.Error())
+	case errors.Is(err, service.ErrShiftReverted):
+		return errorResponse(c, fiber.StatusConflict, "SHIFT_REVERTED", err.Error())
+	}
+	return response.InternalError(c, err.Error())
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/notification"
+	"github.com/minisource/scheduler/internal/service"
+)
+
+// SlackHandler handles Slack interaction callbacks
+type SlackHandler struct {
+	slackService *service.SlackService
+}
+
+// NewSlackHandler creates a new Slack handler
+func NewSlackHandler(slackService *service.SlackService) *SlackHandler {
+	return &SlackHandler{
+		slackService: slackService,
+	}
+}
+
+// Interactions handles button clicks on Slack alert messages
+// @Summary Slack interactions
+// @Description Receives signed Slack block_actions callbacks for the Retry, Pause and Ack buttons
+// @Tags integrations
+// @Accept x-www-form-urlencoded
+// @Param payload formData string true "Slack interaction payload"
+// @Success 200
+// @Failure 400 {object} response.Response
+// @Failure 401
+// @Failure 503 {object} response.Response
+// @Router /api/v1/integrations/slack/interactions [post]
+func (h *SlackHandler) Interactions(c *fiber.Ctx) error {
+	err := h.slackService.Verify(
+		c.Get("X-Slack-Request-Timestamp"),
+		c.Get("X-Slack-Signature"),
+		c.Body(),
+	)
+	if errors.Is(err, service.ErrSlackNotConfigured) {
+		return response.ServiceUnavailable(c, err.Error())
+	}
+	if err != nil {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	var payload notification.SlackInteraction
+	if err := json.Unmarshal([]byte(c.FormValue("payload")), &payload); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid interaction payload")
+	}
+
+	text, err := h.slackService.HandleInteraction(c.Context(), &payload)
+	if err != nil {
+		text = fmt.Sprintf("Action failed: %v", err)
+	}
+
+	// Slack expects an empty 200 within 3 seconds; the outcome is posted to response_url
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := h.slackService.Respond(ctx, payload.ResponseURL, text); err != nil {
+			log.Printf("slack: failed to post interaction response: %v", err)
+		}
+	}()
+
+	return c.SendStatus(fiber.StatusOK)
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/features"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/service"
+)
+
+// SystemHandler handles service-wide configuration
+type SystemHandler struct {
+	systemService *service.SystemService
+}
+
+// NewSystemHandler creates a new system handler
+func NewSystemHandler(systemService *service.SystemService) *SystemHandler {
+	return &SystemHandler{
+		systemService: systemService,
+	}
+}
+
+// GetConfig retrieves the effective configuration
+// @Summary Get the effective configuration
+// @Description Get the configuration in effect on the instance serving the request, with secrets redacted. Secrets that are set show as "[redacted]". Needs the platform role.
+// @Tags system
+// @Produce json
+// @Success 200 {object} response.Response{data=map[string]interface{}}
+// @Failure 403 {object} response.Response
+// @Router /api/v1/system/config [get]
+func (h *SystemHandler) GetConfig(c *fiber.Ctx) error {
+	return response.OK(c, h.systemService.GetConfig())
+}
+
+// UpdateConfig changes runtime settings
+// @Summary Change runtime settings
+// @Description Change the worker count, tick interval or cleanup retention of every instance without a redeploy. Instances apply changes within a few seconds; each change is recorded. Needs the platform role.
+// @Tags system
+// @Accept json
+// @Produce json
+// @Param request body models.UpdateRuntimeConfigRequest true "Settings update"
+// @Success 200 {object} response.Response{data=map[string]interface{}}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/system/config [patch]
+func (h *SystemHandler) UpdateConfig(c *fiber.Ctx) error {
+	var req models.UpdateRuntimeConfigRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+
+	cfg, err := h.systemService.UpdateConfig(c.Context(), &req, getUserID(c))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSetting) {
+			return response.BadRequest(c, "VALIDATION_ERROR", err.Error())
+		}
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, cfg)
+}
+
+// ListChanges lists runtime setting changes
+// @Summary List configuration changes
+// @Description List changes of runtime settings with who made them, newest first. Needs the platform role.
+// @Tags system
+// @Produce json
+// @Param limit query int false "Maximum changes returned" default(50)
+// @Success 200 {object} response.Response{data=[]models.ConfigChange}
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/system/config/changes [get]
+func (h *SystemHandler) ListChanges(c *fiber.Ctx) error {
+	changes, err := h.systemService.ListChanges(c.Context(), c.QueryInt("limit", 0))
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, changes)
+}
+
+// ListFlags lists feature flags
+// @Summary List feature flags
+// @Description List feature flags with their rules from FEATURE_FLAGS, overrides and defaults, and the rule in effect. Needs the platform role.
+// @Tags system
+// @Produce json
+// @Success 200 {object} response.Response{data=[]features.Status}
+// @Failure 403 {object} response.Response
+// @Router /api/v1/system/features [get]
+func (h *SystemHandler) ListFlags(c *fiber.Ctx) error {
+	return response.OK(c, h.systemService.ListFlags())
+}
+
+// SetFlag overrides a feature flag
+// @Summary Override a feature flag
+// @Description Turn a feature flag on or off for every tenant, or on for the listed tenants only, on every instance. Instances apply overrides within a few seconds. Needs the platform role.
+// @Tags system
+// @Accept json
+// @Produce json
+// @Param name path string true "Flag name"
+// @Param request body features.Rule true "Rule"
+// @Success 200 {object} response.Response{data=features.Status}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/system/features/{name} [put]
+func (h *SystemHandler) SetFlag(c *fiber.Ctx) error {
+	var rule features.Rule
+	if err := c.BodyParser(&rule); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+
+	status, err := h.systemService.SetFlag(c.Context(), c.Params("name"), rule, getUserID(c))
+	if err != nil {
+		return flagError(c, err)
+	}
+
+	return response.OK(c, status)
+}
+
+// ClearFlag removes a feature flag override
+// @Summary Clear a feature flag override
+// @Description Remove a flag's override, so its rule from FEATURE_FLAGS or its default applies again. Needs the platform role.
+// @Tags system
+// @Produce json
+// @Param name path string true "Flag name"
+// @Success 200 {object} response.Response{data=features.Status}
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/system/features/{name} [delete]
+func (h *SystemHandler) ClearFlag(c *fiber.Ctx) error {
+	status, err := h.systemService.ClearFlag(c.Context(), c.Params("name"), getUserID(c))
+	if err != nil {
+		return flagError(c, err)
+	}
+
+	return response.OK(c, status)
+}
+
+// flagError maps feature flag errors to responses
+func flagError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrUnknownFlag):
+		return response.NotFound(c, err.Error())
+	case errors.Is(err, service.ErrInvalidSetting):
+		return response.BadRequest(c, "VALIDATION_ERROR", err.Error())
+	}
+	return response.InternalError(c, err.Error())
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/minisource/go-common/response"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/service"
+)
+
+// TenantHandler handles per-tenant settings
+type TenantHandler struct {
+	tenantService *service.TenantService
+	jobService    *service.JobService
+}
+
+// NewTenantHandler creates a new tenant handler
+func NewTenantHandler(tenantService *service.TenantService, jobService *service.JobService) *TenantHandler {
+	return &TenantHandler{
+		tenantService: tenantService,
+		jobService:    jobService,
+	}
+}
+
+// GetSettings retrieves the tenant's settings
+// @Summary Get tenant settings
+// @Description Get the tenant's overrides of service-wide limits and the limits in effect
+// @Tags tenant
+// @Produce json
+// @Success 200 {object} response.Response{data=models.TenantSettingsView}
+// @Router /api/v1/tenant/settings [get]
+func (h *TenantHandler) GetSettings(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	return response.OK(c, h.tenantService.Get(c.Context(), tenantID))
+}
+
+// UpdateSettings updates the tenant's settings
+// @Summary Update tenant settings
+// @Description Override service-wide limits for the tenant; 0 restores the global default. Needs the admin role.
+// @Tags tenant
+// @Accept json
+// @Produce json
+// @Param request body models.UpdateTenantSettingsRequest true "Settings update"
+// @Success 200 {object} response.Response{data=models.TenantSettingsView}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/tenant/settings [put]
+func (h *TenantHandler) UpdateSettings(c *fiber.Ctx) error {
+	var req models.UpdateTenantSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+
+	tenantID := getTenantID(c)
+
+	settings, err := h.tenantService.Update(c.Context(), tenantID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTenantSettings) {
+			return response.BadRequest(c, "VALIDATION_ERROR", err.Error())
+		}
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, settings)
+}
+
+// GetUsage retrieves the tenant's execution rate usage
+// @Summary Get tenant usage
+// @Description Get executions started in the current one-minute window, the rate limit in effect and how many executions are queued
+// @Tags tenant
+// @Produce json
+// @Success 200 {object} response.Response{data=models.TenantUsage}
+// @Failure 503 {object} response.Response
+// @Router /api/v1/tenant/usage [get]
+func (h *TenantHandler) GetUsage(c *fiber.Ctx) error {
+	tenantID := getTenantID(c)
+
+	usage, err := h.tenantService.Usage(c.Context(), tenantID)
+	if err != nil {
+		return response.ServiceUnavailable(c, err.Error())
+	}
+
+	return response.OK(c, usage)
+}
+
+// UpdateGrants changes what a tenant's jobs may access
+// @Summary Update tenant grants
+// @Description Grant a tenant's jobs buckets and bucket/prefixes in object storage, which response sinks, multipart file parts and report deliveries read and write with the service's credentials, and the COMMAND_ALLOWLIST entries command jobs may run. Each list replaces the current one; an empty list revokes it. Needs the platform role.
+// @Tags system
+// @Accept json
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Param request body models.UpdateTenantGrantsRequest true "Grants update"
+// @Success 200 {object} response.Response{data=models.TenantSettingsView}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/tenants/{id}/grants [put]
+func (h *TenantHandler) UpdateGrants(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid tenant ID")
+	}
+
+	var req models.UpdateTenantGrantsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+	}
+
+	settings, err := h.tenantService.UpdateGrants(c.Context(), tenantID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTenantSettings) {
+			return response.BadRequest(c, "VALIDATION_ERROR", err.Error())
+		}
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, settings)
+}
+
+// Pause pauses a tenant
+// @Summary Pause a tenant
+// @Description Suspend dispatch of all of a tenant's jobs, e.g. for a delinquent account or a freeze the tenant asked for. Job definitions and schedules are kept; executions already handed to workers finish, rate-limited ones stay queued and manual triggers are refused with 409. Needs the platform role.
+// @Tags system
+// @Accept json
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Param request body models.PauseTenantRequest false "Pause reason"
+// @Success 200 {object} response.Response{data=models.TenantPause}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/tenants/{id}/pause [post]
+func (h *TenantHandler) Pause(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid tenant ID")
+	}
+
+	var req models.PauseTenantRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "BAD_REQUEST", "Invalid request body")
+		}
+	}
+	if ok, err := validateRequest(c, &req); !ok {
+		return err
+	}
+
+	pause, err := h.jobService.PauseTenant(c.Context(), tenantID, &req)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, pause)
+}
+
+// Resume resumes a paused tenant
+// @Summary Resume a tenant
+// @Description Dispatch a paused tenant's jobs again. Jobs whose next run passed while the tenant was paused have it recalculated from now instead of firing for the missed runs. Needs the platform role.
+// @Tags system
+// @Produce json
+// @Param id path string true "Tenant ID"
+// @Success 200 {object} response.Response{data=models.TenantPause}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/tenants/{id}/resume [post]
+func (h *TenantHandler) Resume(c *fiber.Ctx) error {
+	tenantID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return response.BadRequest(c, "BAD_REQUEST", "Invalid tenant ID")
+	}
+
+	pause, err := h.jobService.ResumeTenant(c.Context(), tenantID)
+	if err != nil {
+		return response.InternalError(c, err.Error())
+	}
+
+	return response.OK(c, pause)
+}
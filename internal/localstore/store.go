@@ -0,0 +1,134 @@
+// Package localstore keeps expiring keys in process memory. It stands in for Redis when a
+// single instance runs without it, where nothing has to be shared with other instances.
+package localstore
+
+import (
+	"sync"
+	"time"
+)
+
+// pruneInterval is how often expired keys are swept out on write
+const pruneInterval = time.Minute
+
+// entry is a value, or a counter, and when it expires; a zero expiry never does
+type entry struct {
+	value     string
+	count     int64
+	expiresAt time.Time
+}
+
+// live reports whether an entry hasn't expired at now
+func (e entry) live(now time.Time) bool {
+	return e.expiresAt.IsZero() || now.Before(e.expiresAt)
+}
+
+// Store is a map of string keys to values with optional expiry, safe for concurrent use
+type Store struct {
+	mu       sync.Mutex
+	entries  map[string]entry
+	prunedAt time.Time
+}
+
+// New creates an empty store
+func New() *Store {
+	return &Store{
+		entries:  make(map[string]entry),
+		prunedAt: time.Now(),
+	}
+}
+
+// Get returns a key's value, reporting false if it isn't set or expired
+func (s *Store) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || !e.live(time.Now()) {
+		return "", false
+	}
+	return e.value, true
+}
+
+// Set sets a key's value for ttl, or without expiry if ttl is 0
+func (s *Store) Set(key, value string, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.prune(now)
+	s.entries[key] = entry{value: value, expiresAt: expiry(now, ttl)}
+}
+
+// SetNX sets a key's value for ttl unless it is already set, reporting whether it was set
+func (s *Store) SetNX(key, value string, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.prune(now)
+	if e, ok := s.entries[key]; ok && e.live(now) {
+		return false
+	}
+	s.entries[key] = entry{value: value, expiresAt: expiry(now, ttl)}
+	return true
+}
+
+// Delete removes a key
+func (s *Store) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+// Reserve counts one more use of a key if it is below limit, reporting whether it did. A new
+// counter expires after ttl; the uses of an expired one start again from 0.
+func (s *Store) Reserve(key string, limit int64, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.prune(now)
+	e, ok := s.entries[key]
+	if !ok || !e.live(now) {
+		e = entry{expiresAt: expiry(now, ttl)}
+	}
+	if e.count >= limit {
+		return false
+	}
+	e.count++
+	s.entries[key] = e
+	return true
+}
+
+// Count returns the uses counted on a key by Reserve
+func (s *Store) Count(key string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || !e.live(time.Now()) {
+		return 0
+	}
+	return e.count
+}
+
+// prune removes expired entries at most once per pruneInterval. Callers hold s.mu.
+func (s *Store) prune(now time.Time) {
+	if now.Sub(s.prunedAt) < pruneInterval {
+		return
+	}
+	s.prunedAt = now
+	for key, e := range s.entries {
+		if !e.live(now) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// expiry returns when a value set at now for ttl expires
+func expiry(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
@@ -0,0 +1,327 @@
+// Package mail sends email over SMTP for email job targets and notification channels.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minisource/scheduler/config"
+)
+
+// TLS modes of the SMTP connection
+const (
+	TLSStartTLS = "starttls" // Upgrade a plain connection, required
+	TLSImplicit = "tls"      // Connect over TLS, usually port 465
+	TLSNone     = "none"     // Plain connection, for local relays only
+)
+
+// ErrDisabled is returned when sending while SMTP_HOST is not set
+var ErrDisabled = errors.New("email is disabled: set SMTP_HOST")
+
+// Message is an email to send. At least one of Text and HTML must be set; with both, the
+// message is sent as multipart/alternative. Attachments wrap the body in multipart/mixed.
+type Message struct {
+	To          []string
+	Cc          []string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+	MessageID   string // Generated when empty
+}
+
+// Attachment is a file attached to a message
+type Attachment struct {
+	Filename    string
+	ContentType string // Defaults to application/octet-stream
+	Data        []byte
+}
+
+// Mailer sends messages through the configured SMTP server
+type Mailer struct {
+	config config.SMTPConfig
+}
+
+// NewMailer creates a mailer; it connects for each message
+func NewMailer(cfg config.SMTPConfig) *Mailer {
+	return &Mailer{config: cfg}
+}
+
+// Enabled reports whether an SMTP server is configured
+func (m *Mailer) Enabled() bool {
+	return m != nil && m.config.Host != ""
+}
+
+// ParseAddresses checks a list of recipient addresses, returning the bare addresses
+func ParseAddresses(addresses []string) ([]string, error) {
+	parsed := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		addr, err := mail.ParseAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid email address %q", address)
+		}
+		parsed = append(parsed, addr.Address)
+	}
+	return parsed, nil
+}
+
+// Send delivers a message to its To and Cc recipients
+func (m *Mailer) Send(ctx context.Context, msg *Message) error {
+	if !m.Enabled() {
+		return ErrDisabled
+	}
+
+	to, err := ParseAddresses(msg.To)
+	if err != nil {
+		return err
+	}
+	cc, err := ParseAddresses(msg.Cc)
+	if err != nil {
+		return err
+	}
+	if len(to) == 0 {
+		return fmt.Errorf("email needs at least one recipient")
+	}
+	from, err := mail.ParseAddress(m.config.From)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP_FROM: %w", err)
+	}
+
+	data, err := m.build(from, to, cc, msg)
+	if err != nil {
+		return err
+	}
+
+	client, err := m.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	for _, rcpt := range append(to, cc...) {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("smtp RCPT TO %s failed: %w", rcpt, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp server rejected the message: %w", err)
+	}
+	return client.Quit()
+}
+
+// dial connects and authenticates to the SMTP server, bounded by the context
+func (m *Mailer) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+	tlsConfig := &tls.Config{ServerName: m.config.Host, MinVersion: tls.VersionTLS12}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to smtp server %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if m.config.TLS == TLSImplicit {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp handshake with %s failed: %w", addr, err)
+	}
+	if m.config.TLS == TLSStartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp STARTTLS with %s failed: %w", addr, err)
+		}
+	}
+	if m.config.Username != "" {
+		auth := smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp authentication failed: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// build renders a message with its headers and MIME parts
+func (m *Mailer) build(from *mail.Address, to, cc []string, msg *Message) ([]byte, error) {
+	if msg.Text == "" && msg.HTML == "" {
+		return nil, fmt.Errorf("email needs a text or html body")
+	}
+
+	messageID := msg.MessageID
+	if messageID == "" {
+		random := make([]byte, 12)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		messageID = fmt.Sprintf("<%s@%s>", hex.EncodeToString(random), m.config.Host)
+	}
+
+	var buf bytes.Buffer
+	header := textproto.MIMEHeader{}
+	header.Set("From", from.String())
+	header.Set("To", strings.Join(to, ", "))
+	if len(cc) > 0 {
+		header.Set("Cc", strings.Join(cc, ", "))
+	}
+	// Line breaks in the subject would start new headers
+	subject := strings.Join(strings.Fields(msg.Subject), " ")
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-ID", messageID)
+	header.Set("MIME-Version", "1.0")
+
+	bodyHeader, body, err := buildBody(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(msg.Attachments) == 0 {
+		for key, values := range bodyHeader {
+			header[key] = values
+		}
+		writeHeader(&buf, header)
+		buf.Write(body)
+		return buf.Bytes(), nil
+	}
+
+	var mixed bytes.Buffer
+	parts := multipart.NewWriter(&mixed)
+	header.Set("Content-Type", "multipart/mixed; boundary="+parts.Boundary())
+	w, err := parts.CreatePart(bodyHeader)
+	if err != nil {
+		return nil, err
+	}
+	w.Write(body)
+
+	for _, attachment := range msg.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(w, attachment.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	writeHeader(&buf, header)
+	buf.Write(mixed.Bytes())
+	return buf.Bytes(), nil
+}
+
+// buildBody renders a message's text and HTML bodies with the headers of their MIME part
+func buildBody(msg *Message) (textproto.MIMEHeader, []byte, error) {
+	if msg.Text != "" && msg.HTML != "" {
+		var body bytes.Buffer
+		parts := multipart.NewWriter(&body)
+		for _, part := range []struct{ contentType, body string }{
+			{"text/plain; charset=utf-8", msg.Text},
+			{"text/html; charset=utf-8", msg.HTML},
+		} {
+			w, err := parts.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part.contentType},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := writeQuotedPrintable(w, part.body); err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := parts.Close(); err != nil {
+			return nil, nil, err
+		}
+		return textproto.MIMEHeader{
+			"Content-Type": {"multipart/alternative; boundary=" + parts.Boundary()},
+		}, body.Bytes(), nil
+	}
+
+	contentType, content := "text/plain; charset=utf-8", msg.Text
+	if msg.HTML != "" {
+		contentType, content = "text/html; charset=utf-8", msg.HTML
+	}
+	var body bytes.Buffer
+	if err := writeQuotedPrintable(&body, content); err != nil {
+		return nil, nil, err
+	}
+	return textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	}, body.Bytes(), nil
+}
+
+// writeBase64 writes an attachment in the base64 transfer encoding, wrapped at 76 characters
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := io.WriteString(w, encoded[:76]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := io.WriteString(w, encoded+"\r\n")
+	return err
+}
+
+// writeQuotedPrintable writes a body part in the quoted-printable transfer encoding
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// writeHeader writes message headers followed by the blank line that ends them
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for _, key := range []string{"From", "To", "Cc", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type", "Content-Transfer-Encoding"} {
+		for _, value := range header.Values(key) {
+			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+		}
+	}
+	buf.WriteString("\r\n")
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/service"
+	"gorm.io/gorm"
+)
+
+// apiPrefix is the path prefix of the routes audited
+const apiPrefix = "/api/v1/"
+
+// auditSkipped are the routes that take a body but change nothing, so they aren't audited
+var auditSkipped = map[string]bool{
+	"POST /api/v1/jobs/validate":     true,
+	"POST /api/v1/jobs/:id/simulate": true,
+}
+
+// Audit records every mutating API call in the audit log: who made it, for which tenant, the
+// route, the entity acted on and the response status. Entities the audit service snapshots
+// are recorded before and after the call. db, which bypasses row-level security, loads
+// snapshots and writes entries outside the request's tenant transaction, so calls that fail
+// and roll back are recorded too.
+func Audit(audit *service.AuditService, db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if safeMethod(c.Method()) {
+			return c.Next()
+		}
+
+		ctx := database.WithConn(c.Context(), db)
+		tenantID := TenantID(c)
+		entityType, id := auditEntity(c.Path())
+
+		var before json.RawMessage
+		if id != uuid.Nil {
+			var err error
+			if before, err = audit.Snapshot(ctx, entityType, tenantID, id); err != nil {
+				log.Printf("audit: failed to snapshot %s %s: %v", entityType, id, err)
+			}
+		}
+
+		chainErr := c.Next()
+		var fiberErr *fiber.Error
+		if errors.As(chainErr, &fiberErr) && fiberErr.Code == fiber.StatusNotFound {
+			// No route matched, so there was no call to record
+			return chainErr
+		}
+		if chainErr != nil {
+			// Render the error now so the recorded status is the one sent
+			if err := c.App().ErrorHandler(c, chainErr); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		action := c.Method() + " " + strings.TrimSuffix(c.Route().Path, "/")
+		if auditSkipped[action] {
+			return nil
+		}
+
+		// The entity is named by the route's first parameter, or by the response of a create
+		status := c.Response().StatusCode()
+		var entityID string
+		if params := c.Route().Params; len(params) > 0 {
+			entityID = c.Params(params[0])
+		} else if status < fiber.StatusMultipleChoices {
+			entityID = createdID(c.Response().Body())
+		}
+
+		entry := &models.AuditLog{
+			TenantID:   tenantID,
+			ActorID:    UserID(c),
+			Action:     action,
+			EntityType: entityType,
+			EntityID:   entityID,
+			StatusCode: status,
+			RequestID:  c.GetRespHeader(fiber.HeaderXRequestID),
+			IP:         c.IP(),
+			Before:     before,
+		}
+		if id, err := uuid.Parse(entityID); err == nil {
+			after, err := audit.Snapshot(ctx, entityType, tenantID, id)
+			if err != nil {
+				log.Printf("audit: failed to snapshot %s %s: %v", entityType, id, err)
+			}
+			entry.After = after
+		}
+
+		if err := audit.Record(ctx, entry); err != nil {
+			log.Printf("audit: failed to record %s: %v", action, err)
+		}
+		return nil
+	}
+}
+
+// auditEntity reads the resource a request path acts on, the first segment after /api/v1 such
+// as jobs, and the ID following it, if any, e.g. the job's ID for /api/v1/jobs/<id>/trigger
+func auditEntity(path string) (string, uuid.UUID) {
+	rest, ok := strings.CutPrefix(path, apiPrefix)
+	if !ok {
+		return "", uuid.Nil
+	}
+	entityType, rest, _ := strings.Cut(rest, "/")
+	segment, _, _ := strings.Cut(rest, "/")
+	id, err := uuid.Parse(segment)
+	if err != nil {
+		return entityType, uuid.Nil
+	}
+	return entityType, id
+}
+
+// createdID reads the ID of a created entity from a response envelope, if any
+func createdID(body []byte) string {
+	var envelope struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(body, &envelope) != nil {
+		return ""
+	}
+	return envelope.Data.ID
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/helmet"
+	"github.com/minisource/scheduler/config"
+)
+
+// CORS returns the cross-origin resource sharing middleware
+func CORS(cfg config.CORSConfig) fiber.Handler {
+	allowCredentials := cfg.AllowCredentials
+	if allowCredentials && cfg.AllowOrigins == "*" {
+		// Browsers reject credentialed responses for a wildcard origin
+		log.Printf("cors: ignoring CORS_ALLOW_CREDENTIALS because CORS_ALLOW_ORIGINS is \"*\"")
+		allowCredentials = false
+	}
+
+	return cors.New(cors.Config{
+		AllowOrigins:     cfg.AllowOrigins,
+		AllowMethods:     cfg.AllowMethods,
+		AllowHeaders:     cfg.AllowHeaders,
+		ExposeHeaders:    cfg.ExposeHeaders,
+		AllowCredentials: allowCredentials,
+		MaxAge:           cfg.MaxAgeSeconds,
+	})
+}
+
+// SecurityHeaders returns middleware setting standard security response headers
+func SecurityHeaders(cfg config.SecurityConfig) fiber.Handler {
+	return helmet.New(helmet.Config{
+		ContentTypeNosniff:    "nosniff",
+		XFrameOptions:         cfg.FrameOptions,
+		ReferrerPolicy:        cfg.ReferrerPolicy,
+		ContentSecurityPolicy: cfg.ContentSecurityPolicy,
+		HSTSMaxAge:            cfg.HSTSMaxAgeSeconds,
+		HSTSExcludeSubdomains: !cfg.HSTSIncludeSubdomains,
+		HSTSPreloadEnabled:    cfg.HSTSPreload,
+		// The API is meant to be called from other origins
+		CrossOriginResourcePolicy: "cross-origin",
+	})
+}
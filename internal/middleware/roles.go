@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// headerRole is the role of requests without a session or token that name their tenant in
+// headers, as AUTH_MODE=header allows. The gateway setting the headers is trusted to run
+// jobs, but not to change a tenant's settings or the service's.
+const headerRole = models.RoleOperator
+
+// RequireRole rejects requests whose session or token doesn't grant role. Requests trusted
+// by their headers pass as an operator; any other request without an identity is rejected.
+func RequireRole(role models.Role) fiber.Handler {
+	message := fmt.Sprintf("This requires the %s role", role)
+
+	return func(c *fiber.Ctx) error {
+		if !hasRole(c, role) {
+			return abort(c, fiber.StatusForbidden, "FORBIDDEN", message)
+		}
+		return c.Next()
+	}
+}
+
+// RequireWriteRole rejects state-changing requests (POST, PUT, PATCH and DELETE) whose
+// session or token doesn't grant role, like RequireRole. Reads pass through.
+func RequireWriteRole(role models.Role) fiber.Handler {
+	message := fmt.Sprintf("Changes here require the %s role", role)
+
+	return func(c *fiber.Ctx) error {
+		if !safeMethod(c.Method()) && !hasRole(c, role) {
+			return abort(c, fiber.StatusForbidden, "FORBIDDEN", message)
+		}
+		return c.Next()
+	}
+}
+
+// hasRole reports whether a request's session or token, or trusted headers, grant role
+func hasRole(c *fiber.Ctx, role models.Role) bool {
+	if identity := IdentityFrom(c); identity != nil {
+		return identity.HasRole(role)
+	}
+	return trustHeaders(c) && headerRole.Includes(role)
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// caller describes how a test request is authenticated
+type caller struct {
+	roles   []models.Role // Roles of the request's token, when it has one
+	token   bool
+	headers bool // Trusted to name its tenant in headers
+}
+
+// roleApp serves GET and POST on / behind a role check, authenticating requests as c
+func roleApp(c caller, check fiber.Handler) *fiber.App {
+	app := fiber.New()
+	app.Use(func(ctx *fiber.Ctx) error {
+		if c.token {
+			ctx.Locals(tokenLocal, &models.Identity{Roles: c.roles})
+		}
+		if c.headers {
+			ctx.Locals(trustHeadersLocal, true)
+		}
+		return ctx.Next()
+	})
+	app.Use(check)
+	handler := func(ctx *fiber.Ctx) error { return ctx.SendStatus(fiber.StatusOK) }
+	app.Get("/", handler)
+	app.Post("/", handler)
+	return app
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller caller
+		role   models.Role
+		want   int
+	}{
+		{name: "viewer reads", caller: caller{token: true, roles: []models.Role{models.RoleViewer}}, role: models.RoleViewer, want: http.StatusOK},
+		{name: "viewer isn't an operator", caller: caller{token: true, roles: []models.Role{models.RoleViewer}}, role: models.RoleOperator, want: http.StatusForbidden},
+		{name: "admin includes operator", caller: caller{token: true, roles: []models.Role{models.RoleAdmin}}, role: models.RoleOperator, want: http.StatusOK},
+		{name: "admin isn't platform", caller: caller{token: true, roles: []models.Role{models.RoleAdmin}}, role: models.RolePlatform, want: http.StatusForbidden},
+		{name: "platform includes admin", caller: caller{token: true, roles: []models.Role{models.RolePlatform}}, role: models.RoleAdmin, want: http.StatusOK},
+		{name: "any of several roles", caller: caller{token: true, roles: []models.Role{models.RoleViewer, models.RoleAdmin}}, role: models.RoleAdmin, want: http.StatusOK},
+		{name: "token without roles", caller: caller{token: true}, role: models.RoleViewer, want: http.StatusForbidden},
+		{name: "headers act as operator", caller: caller{headers: true}, role: models.RoleOperator, want: http.StatusOK},
+		{name: "headers aren't admin", caller: caller{headers: true}, role: models.RoleAdmin, want: http.StatusForbidden},
+		{name: "headers aren't platform", caller: caller{headers: true}, role: models.RolePlatform, want: http.StatusForbidden},
+		{name: "no identity", caller: caller{}, role: models.RoleViewer, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := roleApp(tt.caller, RequireRole(tt.role))
+			for _, method := range []string{http.MethodGet, http.MethodPost} {
+				resp, err := app.Test(httptest.NewRequest(method, "/", nil))
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, resp.StatusCode, method)
+			}
+		})
+	}
+}
+
+func TestRequireWriteRole(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller caller
+		method string
+		want   int
+	}{
+		{name: "viewer reads", caller: caller{token: true, roles: []models.Role{models.RoleViewer}}, method: http.MethodGet, want: http.StatusOK},
+		{name: "viewer can't write", caller: caller{token: true, roles: []models.Role{models.RoleViewer}}, method: http.MethodPost, want: http.StatusForbidden},
+		{name: "operator writes", caller: caller{token: true, roles: []models.Role{models.RoleOperator}}, method: http.MethodPost, want: http.StatusOK},
+		{name: "admin writes", caller: caller{token: true, roles: []models.Role{models.RoleAdmin}}, method: http.MethodPost, want: http.StatusOK},
+		{name: "headers write", caller: caller{headers: true}, method: http.MethodPost, want: http.StatusOK},
+		{name: "no identity can't write", caller: caller{}, method: http.MethodPost, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := roleApp(tt.caller, RequireWriteRole(models.RoleOperator))
+			resp, err := app.Test(httptest.NewRequest(tt.method, "/", nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/service"
+)
+
+const (
+	SessionCookie = "scheduler_session" // HttpOnly session ID
+	CSRFCookie    = "scheduler_csrf"    // Readable by the dashboard so it can echo the token
+	CSRFHeader    = "X-CSRF-Token"
+)
+
+// sessionLocal is the request local holding the authenticated session
+const sessionLocal = "session"
+
+// Session authenticates browser requests carrying a session cookie and requires a matching
+// CSRF token on their state-changing requests. Requests without the cookie, such as API
+// clients, pass through untouched.
+func Session(sessions *service.SessionService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Cookies(SessionCookie)
+		if id == "" {
+			return c.Next()
+		}
+
+		session, err := sessions.Get(c.Context(), id)
+		if errors.Is(err, service.ErrSessionNotFound) {
+			ClearSessionCookies(c, sessions.CookieSecure())
+			return abort(c, fiber.StatusUnauthorized, "SESSION_EXPIRED", "Session expired, log in again")
+		}
+		if err != nil {
+			return abort(c, fiber.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Session store unavailable")
+		}
+
+		if !safeMethod(c.Method()) && !sessions.VerifyCSRF(session, c.Get(CSRFHeader)) {
+			return abort(c, fiber.StatusForbidden, "CSRF_TOKEN_INVALID", "Missing or invalid CSRF token")
+		}
+
+		c.Locals(sessionLocal, session)
+		return c.Next()
+	}
+}
+
+// SessionFrom returns the request's browser session, if any
+func SessionFrom(c *fiber.Ctx) *models.Session {
+	session, _ := c.Locals(sessionLocal).(*models.Session)
+	return session
+}
+
+// IdentityFrom returns the identity of a request authenticated by a session or a bearer
+// token, if any
+func IdentityFrom(c *fiber.Ctx) *models.Identity {
+	if session := SessionFrom(c); session != nil {
+		return &session.Identity
+	}
+	return TokenIdentity(c)
+}
+
+// SetSessionCookies issues the session and CSRF cookies
+func SetSessionCookies(c *fiber.Ctx, session *models.Session, secure bool) {
+	c.Cookie(&fiber.Cookie{
+		Name:     SessionCookie,
+		Value:    session.ID,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		Secure:   secure,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	c.Cookie(&fiber.Cookie{
+		Name:     CSRFCookie,
+		Value:    session.CSRFToken,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		Secure:   secure,
+		SameSite: fiber.CookieSameSiteStrictMode,
+	})
+}
+
+// ClearSessionCookies expires the session and CSRF cookies
+func ClearSessionCookies(c *fiber.Ctx, secure bool) {
+	for _, name := range []string{SessionCookie, CSRFCookie} {
+		c.Cookie(&fiber.Cookie{
+			Name:     name,
+			Value:    "",
+			Path:     "/",
+			Expires:  time.Unix(0, 0),
+			Secure:   secure,
+			HTTPOnly: name == SessionCookie,
+		})
+	}
+}
+
+// safeMethod reports whether an HTTP method is read-only
+func safeMethod(method string) bool {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// abort ends the request with the standard error envelope
+func abort(c *fiber.Ctx, status int, code, message string) error {
+	return c.Status(status).JSON(fiber.Map{
+		"success": false,
+		"error": fiber.Map{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SharedToken rejects requests whose "Authorization: Bearer" token isn't the configured one,
+// for operational routes that the orchestrator calls rather than API clients. Without a
+// configured token every request is rejected.
+func SharedToken(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token == "" {
+			return abort(c, fiber.StatusForbidden, "FORBIDDEN", "This route is disabled until a token is configured")
+		}
+		raw, ok := bearerToken(c)
+		if !ok || subtle.ConstantTimeCompare([]byte(raw), []byte(token)) != 1 {
+			return abort(c, fiber.StatusUnauthorized, "UNAUTHORIZED", "A valid bearer token is required")
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		want          int
+	}{
+		{name: "matching token", token: "drain-secret", authorization: "Bearer drain-secret", want: http.StatusOK},
+		{name: "scheme in another case", token: "drain-secret", authorization: "bearer drain-secret", want: http.StatusOK},
+		{name: "wrong token", token: "drain-secret", authorization: "Bearer guess", want: http.StatusUnauthorized},
+		{name: "no token", token: "drain-secret", want: http.StatusUnauthorized},
+		{name: "basic auth", token: "drain-secret", authorization: "Basic ZHJhaW4tc2VjcmV0", want: http.StatusUnauthorized},
+		{name: "none configured", authorization: "Bearer ", want: http.StatusForbidden},
+		{name: "none configured or sent", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Post("/drain", SharedToken(tt.token), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodPost, "/drain", nil)
+			if tt.authorization != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.authorization)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/service"
+	"gorm.io/gorm"
+)
+
+// TenantID returns the tenant a request acts for: the tenant of its session or bearer token,
+// otherwise, when Token trusts headers, the X-Tenant-ID header or tenant_id query parameter.
+// It is uuid.Nil when none is given.
+func TenantID(c *fiber.Ctx) uuid.UUID {
+	// Sessions and tokens are bound to the tenant they were issued for
+	if identity := IdentityFrom(c); identity != nil {
+		return identity.TenantID
+	}
+	if !trustHeaders(c) {
+		return uuid.Nil
+	}
+
+	tenantIDStr := c.Get("X-Tenant-ID")
+	if tenantIDStr == "" {
+		tenantIDStr = c.Query("tenant_id")
+	}
+
+	tenantID, err := uuid.Parse(tenantIDStr)
+	if err != nil {
+		return uuid.Nil
+	}
+	return tenantID
+}
+
+// UserID returns the user a request acts for: the user of its session or bearer token,
+// otherwise, when Token trusts headers, the X-User-ID header. It is nil when none is given.
+func UserID(c *fiber.Ctx) *uuid.UUID {
+	if identity := IdentityFrom(c); identity != nil {
+		userID := identity.UserID
+		return &userID
+	}
+	if !trustHeaders(c) {
+		return nil
+	}
+
+	userID, err := uuid.Parse(c.Get("X-User-ID"))
+	if err != nil {
+		return nil
+	}
+	return &userID
+}
+
+// trustHeaders reports whether a request may name its tenant and user in headers
+func trustHeaders(c *fiber.Ctx) bool {
+	trusted, _ := c.Locals(trustHeadersLocal).(bool)
+	return trusted
+}
+
+// Actor attributes the changes a request makes, such as job revisions, to the user it acts for
+func Actor() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		service.BindActor(c.Context(), UserID(c))
+		return c.Next()
+	}
+}
+
+// TenantScope runs each request in a transaction bound to the request's tenant when
+// isolation is rls, so row-level security hides other tenants' rows even from queries that
+// forget to filter by tenant. Requests without a tenant see no rows. The transaction
+// commits unless the handler fails with an error or a 5xx status.
+func TenantScope(db *gorm.DB, isolation string) fiber.Handler {
+	if isolation != database.TenantIsolationRLS {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+
+	return func(c *fiber.Ctx) error {
+		tx, err := database.BeginTenant(c.Context(), db, TenantID(c))
+		if err != nil {
+			log.Printf("tenancy: %v", err)
+			return abort(c, fiber.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Database unavailable")
+		}
+		tx.Bind(c.Context())
+
+		err = c.Next()
+		if err != nil || c.Response().StatusCode() >= fiber.StatusInternalServerError {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				log.Printf("tenancy: rollback failed: %v", rbErr)
+			}
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			log.Printf("tenancy: commit failed: %v", err)
+			return abort(c, fiber.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Failed to commit changes")
+		}
+		return nil
+	}
+}
+
+// SystemScope runs requests that find their tenant from the data itself, such as share
+// links and integration callbacks, on db, which bypasses row-level security when isolation
+// is rls
+func SystemScope(db *gorm.DB, isolation string) fiber.Handler {
+	if isolation != database.TenantIsolationRLS {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+
+	return func(c *fiber.Ctx) error {
+		database.BindConn(c.Context(), db)
+		return c.Next()
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/service"
+)
+
+const (
+	tokenLocal        = "token_identity" // Identity read from a verified bearer token
+	trustHeadersLocal = "trust_headers"  // Set when X-Tenant-ID and X-User-ID may name the tenant and user
+)
+
+// Token authenticates API requests carrying an "Authorization: Bearer" JWT, so their tenant,
+// user and roles come from the token's verified claims. Invalid tokens are rejected. Requests
+// already authenticated by a session cookie are left to it. Only when tokens aren't required
+// may requests without one name their tenant and user in headers.
+func Token(tokens *service.TokenService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if SessionFrom(c) != nil {
+			return c.Next()
+		}
+
+		raw, ok := bearerToken(c)
+		if !ok || !tokens.Enabled() {
+			if !tokens.Required() {
+				c.Locals(trustHeadersLocal, true)
+			}
+			return c.Next()
+		}
+
+		identity, err := tokens.Verify(c.Context(), raw)
+		if errors.Is(err, service.ErrTokenAccessDenied) {
+			return abort(c, fiber.StatusForbidden, "ACCESS_DENIED", err.Error())
+		}
+		if err != nil {
+			log.Printf("auth: rejected bearer token: %v", err)
+			return abort(c, fiber.StatusUnauthorized, "TOKEN_INVALID", "Invalid or expired bearer token")
+		}
+
+		c.Locals(tokenLocal, identity)
+		return c.Next()
+	}
+}
+
+// Authenticated rejects requests that have neither a session nor a verified token when tokens
+// are required. Routes that find their tenant from the data, such as share links, don't use it.
+func Authenticated(tokens *service.TokenService) fiber.Handler {
+	if !tokens.Required() {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+
+	return func(c *fiber.Ctx) error {
+		if IdentityFrom(c) == nil {
+			return abort(c, fiber.StatusUnauthorized, "UNAUTHORIZED", "A bearer token is required")
+		}
+		return c.Next()
+	}
+}
+
+// TokenIdentity returns the identity of a token-authenticated request, if any
+func TokenIdentity(c *fiber.Ctx) *models.Identity {
+	identity, _ := c.Locals(tokenLocal).(*models.Identity)
+	return identity
+}
+
+// bearerToken reads the token of an "Authorization: Bearer" header
+func bearerToken(c *fiber.Ctx) (string, bool) {
+	scheme, token, ok := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
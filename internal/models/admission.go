@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AdmissionFailurePolicy decides what happens to a job change while an admission webhook is
+// unavailable
+type AdmissionFailurePolicy string
+
+const (
+	AdmissionFail   AdmissionFailurePolicy = "fail"   // Refuse the change
+	AdmissionIgnore AdmissionFailurePolicy = "ignore" // Admit the change without the webhook
+)
+
+// AdmissionOperation is the kind of job change sent for admission
+type AdmissionOperation string
+
+const (
+	AdmissionCreate AdmissionOperation = "create"
+	AdmissionUpdate AdmissionOperation = "update"
+)
+
+// AdmissionWebhook is called with every create and update request for a tenant's jobs before
+// it is applied, and can reject the request or change it, so a tenant can enforce its own
+// policy such as naming conventions or allowed endpoints. Webhooks are called in order of
+// position, each seeing the request as changed by the ones before.
+type AdmissionWebhook struct {
+	ID            uuid.UUID              `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID      uuid.UUID              `json:"tenant_id" gorm:"type:uuid;not null;index:idx_admission_webhooks_tenant"`
+	Name          string                 `json:"name" gorm:"type:varchar(255);not null"`
+	Position      int                    `json:"position" gorm:"default:0"`           // Lower positions are called first
+	URL           string                 `json:"url" gorm:"type:text;not null"`       // Receives AdmissionReview requests with POST
+	Headers       json.RawMessage        `json:"headers,omitempty" gorm:"type:jsonb"` // Sent with each review; may reference secrets
+	FailurePolicy AdmissionFailurePolicy `json:"failure_policy" gorm:"type:varchar(10);not null;default:'fail'"`
+	Enabled       bool                   `json:"enabled" gorm:"default:true"`
+	CreatedAt     time.Time              `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time              `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (AdmissionWebhook) TableName() string {
+	return "admission_webhooks"
+}
+
+// AdmissionReview is posted to admission webhooks for every job create and update request
+type AdmissionReview struct {
+	UID       uuid.UUID          `json:"uid"`
+	Operation AdmissionOperation `json:"operation"`
+	DryRun    bool               `json:"dry_run"` // The request is only validated; nothing is changed
+	TenantID  uuid.UUID          `json:"tenant_id"`
+	UserID    *uuid.UUID         `json:"user_id,omitempty"`
+	JobID     *uuid.UUID         `json:"job_id,omitempty"` // The job updated
+	Request   json.RawMessage    `json:"request"`          // The create or update request body
+	Job       *Job               `json:"job,omitempty"`    // The job as it is before an update
+}
+
+// AdmissionResponse is an admission webhook's answer to a review
+type AdmissionResponse struct {
+	Allowed bool            `json:"allowed"`
+	Message string          `json:"message,omitempty"` // Why the request was rejected
+	Field   string          `json:"field,omitempty"`   // The request field a rejection concerns
+	Patch   json.RawMessage `json:"patch,omitempty"`   // JSON merge patch (RFC 7386) applied to an allowed request
+}
+
+// CreateAdmissionWebhookRequest represents a request to create an admission webhook
+type CreateAdmissionWebhookRequest struct {
+	Name          string                 `json:"name" validate:"required,min=1,max=255"`
+	Position      int                    `json:"position,omitempty"`
+	URL           string                 `json:"url" validate:"required"`
+	Headers       map[string]string      `json:"headers,omitempty"`
+	FailurePolicy AdmissionFailurePolicy `json:"failure_policy,omitempty"`
+	Enabled       *bool                  `json:"enabled,omitempty"`
+}
+
+// UpdateAdmissionWebhookRequest represents a request to update an admission webhook
+type UpdateAdmissionWebhookRequest struct {
+	Name          *string                 `json:"name,omitempty"`
+	Position      *int                    `json:"position,omitempty"`
+	URL           *string                 `json:"url,omitempty"`
+	Headers       *map[string]string      `json:"headers,omitempty"`
+	FailurePolicy *AdmissionFailurePolicy `json:"failure_policy,omitempty"`
+	Enabled       *bool                   `json:"enabled,omitempty"`
+}
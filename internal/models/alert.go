@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AlertRuleType is the condition an alert rule watches for
+type AlertRuleType string
+
+const (
+	AlertRuleConsecutiveFailures AlertRuleType = "consecutive_failures" // A job failed Threshold times in a row
+	AlertRuleNotRun              AlertRuleType = "not_run"              // A job hasn't finished a run in WindowMinutes
+)
+
+// AlertRule alerts a set of channels when a job, or any job matching a tag selector, meets
+// its condition. Each job fires a rule once and resolves it once the condition clears.
+type AlertRule struct {
+	ID            uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID      uuid.UUID       `json:"tenant_id" gorm:"type:uuid;not null;index:idx_alert_rules_tenant"`
+	Name          string          `json:"name" gorm:"type:varchar(255);not null"`
+	Type          AlertRuleType   `json:"type" gorm:"type:varchar(30);not null"`
+	JobID         *uuid.UUID      `json:"job_id,omitempty" gorm:"type:uuid"`
+	Tags          json.RawMessage `json:"tags,omitempty" gorm:"type:jsonb"`          // Matches jobs carrying all of these tags
+	Threshold     int             `json:"threshold,omitempty" gorm:"default:0"`      // Failures in a row, for consecutive_failures
+	WindowMinutes int             `json:"window_minutes,omitempty" gorm:"default:0"` // Minutes without a run, for not_run
+	ChannelIDs    json.RawMessage `json:"channel_ids" gorm:"type:jsonb;not null"`    // Notification channels alerted
+	Enabled       bool            `json:"enabled" gorm:"default:true"`
+	CreatedAt     time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (AlertRule) TableName() string {
+	return "alert_rules"
+}
+
+// Matches reports whether the rule selects a job
+func (r *AlertRule) Matches(job *Job) bool {
+	if r.JobID != nil {
+		return *r.JobID == job.ID
+	}
+	return matchesTags(r.Tags, job.TagList())
+}
+
+// Channels returns the IDs of the channels the rule alerts
+func (r *AlertRule) Channels() []uuid.UUID {
+	var ids []uuid.UUID
+	_ = json.Unmarshal(r.ChannelIDs, &ids)
+	return ids
+}
+
+// AlertFiring records that a rule is firing for a job
+type AlertFiring struct {
+	RuleID   uuid.UUID `json:"rule_id" gorm:"type:uuid;primaryKey"`
+	JobID    uuid.UUID `json:"job_id" gorm:"type:uuid;primaryKey;index:idx_alert_firings_job"`
+	TenantID uuid.UUID `json:"tenant_id" gorm:"type:uuid;not null;index:idx_alert_firings_tenant"`
+	FiredAt  time.Time `json:"fired_at" gorm:"not null"`
+}
+
+// TableName returns the table name for GORM
+func (AlertFiring) TableName() string {
+	return "alert_firings"
+}
+
+// CreateAlertRuleRequest represents a request to create an alert rule
+type CreateAlertRuleRequest struct {
+	Name          string        `json:"name" validate:"required,min=1,max=255"`
+	Type          AlertRuleType `json:"type" validate:"required"`
+	JobID         *uuid.UUID    `json:"job_id,omitempty"`
+	Tags          []string      `json:"tags,omitempty"`
+	Threshold     int           `json:"threshold,omitempty"`
+	WindowMinutes int           `json:"window_minutes,omitempty"`
+	ChannelIDs    []uuid.UUID   `json:"channel_ids" validate:"required"`
+	Enabled       *bool         `json:"enabled,omitempty"`
+}
+
+// UpdateAlertRuleRequest represents a request to update an alert rule
+type UpdateAlertRuleRequest struct {
+	Name          *string      `json:"name,omitempty"`
+	Threshold     *int         `json:"threshold,omitempty"`
+	WindowMinutes *int         `json:"window_minutes,omitempty"`
+	ChannelIDs    *[]uuid.UUID `json:"channel_ids,omitempty"`
+	Enabled       *bool        `json:"enabled,omitempty"`
+}
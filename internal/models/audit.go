@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLog records a mutating API call. Entries are append-only: the database rejects
+// updates and deletes.
+type AuditLog struct {
+	ID         uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID   uuid.UUID       `json:"tenant_id" gorm:"type:uuid;not null;index:idx_audit_logs_tenant_created,priority:1;index:idx_audit_logs_entity,priority:1"`
+	ActorID    *uuid.UUID      `json:"actor_id,omitempty" gorm:"type:uuid"`                                                 // User the request acted for
+	Action     string          `json:"action" gorm:"type:varchar(255);not null"`                                            // Method and route, e.g. POST /api/v1/jobs/:id/trigger
+	EntityType string          `json:"entity_type" gorm:"type:varchar(50);not null;index:idx_audit_logs_entity,priority:2"` // Resource the route belongs to, e.g. jobs
+	EntityID   string          `json:"entity_id,omitempty" gorm:"type:varchar(255);index:idx_audit_logs_entity,priority:3"` // ID or name of the entity acted on
+	StatusCode int             `json:"status_code" gorm:"not null"`
+	RequestID  string          `json:"request_id,omitempty" gorm:"type:varchar(100)"`
+	IP         string          `json:"ip,omitempty" gorm:"type:varchar(100)"`
+	Before     json.RawMessage `json:"before,omitempty" gorm:"type:jsonb"` // The entity before the call, for entities that are snapshotted
+	After      json.RawMessage `json:"after,omitempty" gorm:"type:jsonb"`  // The entity after the call
+	CreatedAt  time.Time       `json:"created_at" gorm:"autoCreateTime;index:idx_audit_logs_tenant_created,priority:2,sort:desc"`
+}
+
+// TableName returns the table name for GORM
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// AuditFilter represents query filters for audit logs
+type AuditFilter struct {
+	TenantID   *uuid.UUID `json:"tenant_id,omitempty"`
+	ActorID    *uuid.UUID `json:"actor_id,omitempty"`
+	Action     string     `json:"action,omitempty"`
+	EntityType string     `json:"entity_type,omitempty"`
+	EntityID   string     `json:"entity_id,omitempty"`
+	From       *time.Time `json:"from,omitempty"`
+	To         *time.Time `json:"to,omitempty"`
+	Page       int        `json:"page,omitempty"`
+	PageSize   int        `json:"page_size,omitempty"`
+}
+
+// AuditListResult represents paginated audit log results
+type AuditListResult struct {
+	Entries    []AuditLog `json:"entries"`
+	TotalCount int64      `json:"total_count"`
+	Page       int        `json:"page"`
+	PageSize   int        `json:"page_size"`
+	HasMore    bool       `json:"has_more"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Role is an access level granted to an operator
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"   // Read-only access to the tenant's jobs, executions, history and settings
+	RoleOperator Role = "operator" // Viewer plus changing, triggering and cancelling jobs and their runs
+	RoleAdmin    Role = "admin"    // Operator plus the tenant's settings, secrets, notifications and integrations
+	RolePlatform Role = "platform" // Admin plus service-wide settings and other tenants; for the service's operators
+)
+
+// roleRank orders roles from least to most privileged
+var roleRank = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+	RolePlatform: 4,
+}
+
+// Valid reports whether the role is known
+func (r Role) Valid() bool {
+	_, ok := roleRank[r]
+	return ok
+}
+
+// Includes reports whether the role grants at least the access of other
+func (r Role) Includes(other Role) bool {
+	return roleRank[r] >= roleRank[other] && r.Valid()
+}
+
+// Identity is an authenticated operator
+type Identity struct {
+	UserID   uuid.UUID `json:"user_id"` // Stable ID derived from the issuer and subject
+	Subject  string    `json:"subject"`
+	Email    string    `json:"email,omitempty"`
+	Name     string    `json:"name,omitempty"`
+	TenantID uuid.UUID `json:"tenant_id"`
+	Groups   []string  `json:"groups,omitempty"`
+	Roles    []Role    `json:"roles"`
+}
+
+// HasRole reports whether any of the identity's roles grants the given role
+func (i *Identity) HasRole(role Role) bool {
+	for _, r := range i.Roles {
+		if r.Includes(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Session is a browser login backed by a secure cookie
+type Session struct {
+	ID        string    `json:"-"`
+	Identity  Identity  `json:"identity"`
+	CSRFToken string    `json:"csrf_token"` // Must be echoed in X-CSRF-Token on state-changing requests
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
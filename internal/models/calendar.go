@@ -0,0 +1,236 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarKind says what a calendar is used for
+type CalendarKind string
+
+const (
+	CalendarKindBlackout CalendarKind = "blackout" // Windows during which jobs don't run
+	CalendarKindHoliday  CalendarKind = "holiday"  // Dates skipped by cron schedules
+)
+
+// BlackoutAction is what happens to a run that falls in a blackout window
+type BlackoutAction string
+
+const (
+	BlackoutSkip  BlackoutAction = "skip"  // Drop the run and wait for the next occurrence
+	BlackoutDefer BlackoutAction = "defer" // Run once when the window ends
+)
+
+// Calendar is a named set of blackout windows or holiday dates belonging to a tenant. Jobs
+// reference a calendar by name; a tenant-wide blackout calendar applies to every job of the
+// tenant.
+type Calendar struct {
+	ID          uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID    uuid.UUID       `json:"tenant_id" gorm:"type:uuid;not null;uniqueIndex:idx_calendars_tenant_name,priority:1"`
+	Name        string          `json:"name" gorm:"type:varchar(100);not null;uniqueIndex:idx_calendars_tenant_name,priority:2"`
+	Description string          `json:"description,omitempty" gorm:"type:text"`
+	Kind        CalendarKind    `json:"kind" gorm:"type:varchar(20);not null;default:'blackout'"`
+	TenantWide  bool            `json:"tenant_wide" gorm:"default:false"`    // Applies to every job of the tenant, not just those referencing it
+	Windows     json.RawMessage `json:"windows,omitempty" gorm:"type:jsonb"` // Blackout windows
+	Dates       json.RawMessage `json:"dates,omitempty" gorm:"type:jsonb"`   // Holiday dates
+	CreatedAt   time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (Calendar) TableName() string {
+	return "calendars"
+}
+
+// BlackoutWindowList returns the calendar's blackout windows
+func (c *Calendar) BlackoutWindowList() []BlackoutWindow {
+	var windows []BlackoutWindow
+	if len(c.Windows) > 0 {
+		_ = json.Unmarshal(c.Windows, &windows)
+	}
+	return windows
+}
+
+// HolidayList returns the calendar's holiday dates
+func (c *Calendar) HolidayList() []string {
+	var dates []string
+	if len(c.Dates) > 0 {
+		_ = json.Unmarshal(c.Dates, &dates)
+	}
+	return dates
+}
+
+// Holidays is a set of dates, each either a single day ("2025-12-25") or a day every year
+// ("12-25")
+type Holidays map[string]bool
+
+// NewHolidays builds a set of holiday dates
+func NewHolidays(dates []string) Holidays {
+	holidays := make(Holidays, len(dates))
+	for _, date := range dates {
+		holidays[date] = true
+	}
+	return holidays
+}
+
+// On reports whether t falls on a holiday, by its date in t's location
+func (h Holidays) On(t time.Time) bool {
+	return h[t.Format("2006-01-02")] || h[t.Format("01-02")]
+}
+
+// ValidateHolidayDate checks that a holiday date is YYYY-MM-DD or MM-DD
+func ValidateHolidayDate(date string) error {
+	if _, err := time.Parse("2006-01-02", date); err == nil {
+		return nil
+	}
+	// Parsed in a leap year so 02-29 is accepted
+	if _, err := time.Parse("2006-01-02", "2024-"+date); err == nil && len(date) == 5 {
+		return nil
+	}
+	return fmt.Errorf("%q is not YYYY-MM-DD or MM-DD", date)
+}
+
+// BlackoutWindow is a period during which runs are skipped or deferred. It either recurs
+// weekly, from Start to End on Days (every day when empty) in Timezone, or is a single period
+// from From to Until. A weekly window whose End is before its Start ends the next day.
+type BlackoutWindow struct {
+	Days     []string       `json:"days,omitempty"`     // mon, tue, wed, thu, fri, sat, sun
+	Start    string         `json:"start,omitempty"`    // HH:MM
+	End      string         `json:"end,omitempty"`      // HH:MM
+	Timezone string         `json:"timezone,omitempty"` // IANA name, UTC when empty
+	From     *time.Time     `json:"from,omitempty"`
+	Until    *time.Time     `json:"until,omitempty"`
+	Action   BlackoutAction `json:"action,omitempty"` // skip or defer, skip when empty
+}
+
+// weekdays maps day names in blackout windows to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Validate checks that a window is either weekly or a single period, and can be evaluated
+func (w *BlackoutWindow) Validate() error {
+	switch w.Action {
+	case "", BlackoutSkip, BlackoutDefer:
+	default:
+		return fmt.Errorf("action must be skip or defer")
+	}
+
+	if w.From != nil || w.Until != nil {
+		if w.From == nil || w.Until == nil || !w.Until.After(*w.From) {
+			return fmt.Errorf("a one-off window needs from before until")
+		}
+		if len(w.Days) > 0 || w.Start != "" || w.End != "" {
+			return fmt.Errorf("a window is either weekly (days, start, end) or one-off (from, until)")
+		}
+		return nil
+	}
+
+	for _, day := range w.Days {
+		if _, ok := weekdays[strings.ToLower(day)]; !ok {
+			return fmt.Errorf("invalid day %q (use mon, tue, wed, thu, fri, sat or sun)", day)
+		}
+	}
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return fmt.Errorf("invalid start: %w", err)
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return fmt.Errorf("invalid end: %w", err)
+	}
+	if start == end {
+		return fmt.Errorf("start and end can't be the same time")
+	}
+	if _, err := time.LoadLocation(w.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q", w.Timezone)
+	}
+	return nil
+}
+
+// Covers reports whether t falls in the window, and when that occurrence of the window ends
+func (w *BlackoutWindow) Covers(t time.Time) (time.Time, bool) {
+	if w.From != nil && w.Until != nil {
+		if !t.Before(*w.From) && t.Before(*w.Until) {
+			return *w.Until, true
+		}
+		return time.Time{}, false
+	}
+
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return time.Time{}, false
+	}
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	local := t.In(loc)
+	// A window that runs past midnight may have started the day before
+	for _, offset := range []int{0, -1} {
+		day := local.Day() + offset
+		from := time.Date(local.Year(), local.Month(), day, 0, start, 0, 0, loc)
+		if !w.on(from.Weekday()) {
+			continue
+		}
+		if end < start {
+			day++
+		}
+		until := time.Date(local.Year(), local.Month(), day, 0, end, 0, 0, loc)
+		if !t.Before(from) && t.Before(until) {
+			return until, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// on reports whether a weekly window starts on a weekday
+func (w *BlackoutWindow) on(weekday time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, day := range w.Days {
+		if weekdays[strings.ToLower(day)] == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock parses an HH:MM time of day into minutes after midnight
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// CreateCalendarRequest represents a request to create a calendar
+type CreateCalendarRequest struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Kind        CalendarKind     `json:"kind,omitempty"` // blackout or holiday, blackout when empty
+	TenantWide  bool             `json:"tenant_wide,omitempty"`
+	Windows     []BlackoutWindow `json:"windows,omitempty"` // For blackout calendars
+	Dates       []string         `json:"dates,omitempty"`   // For holiday calendars
+}
+
+// UpdateCalendarRequest represents a request to update a calendar; the name can't change
+// because jobs reference it
+type UpdateCalendarRequest struct {
+	Description *string           `json:"description,omitempty"`
+	TenantWide  *bool             `json:"tenant_wide,omitempty"`
+	Windows     *[]BlackoutWindow `json:"windows,omitempty"`
+	Dates       *[]string         `json:"dates,omitempty"`
+}
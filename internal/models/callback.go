@@ -0,0 +1,122 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CallbackRoute sends the results of a tenant's jobs carrying all of its tags to a URL, so
+// each team of a tenant can receive its own jobs' results. A finished execution goes to the
+// first enabled route, by position, that matches its job; a route without tags matches
+// every job and serves as the tenant's default.
+type CallbackRoute struct {
+	ID              uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID        uuid.UUID       `json:"tenant_id" gorm:"type:uuid;not null;index:idx_callback_routes_tenant"`
+	Name            string          `json:"name" gorm:"type:varchar(255);not null"`
+	Position        int             `json:"position" gorm:"default:0"`             // Lower positions are matched first
+	Tags            json.RawMessage `json:"tags,omitempty" gorm:"type:jsonb"`      // Matches jobs carrying all of these tags
+	URL             string          `json:"url" gorm:"type:text;not null"`         // Receives results with POST
+	Headers         json.RawMessage `json:"headers,omitempty" gorm:"type:jsonb"`   // Sent with each callback; may reference secrets
+	Statuses        json.RawMessage `json:"statuses,omitempty" gorm:"type:jsonb"`  // Execution statuses sent; all final statuses when empty
+	IncludeResponse bool            `json:"include_response" gorm:"default:false"` // Send the execution's recorded response
+	Enabled         bool            `json:"enabled" gorm:"default:true"`
+	CreatedAt       time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (CallbackRoute) TableName() string {
+	return "callback_routes"
+}
+
+// Matches reports whether the route selects a job
+func (r *CallbackRoute) Matches(job *Job) bool {
+	var selector []string
+	if err := json.Unmarshal(r.Tags, &selector); err != nil || len(selector) == 0 {
+		return true
+	}
+	return matchesTags(r.Tags, job.TagList())
+}
+
+// Sends reports whether the route sends results of executions that finished with a status
+func (r *CallbackRoute) Sends(status ExecutionStatus) bool {
+	var statuses []ExecutionStatus
+	if err := json.Unmarshal(r.Statuses, &statuses); err != nil || len(statuses) == 0 {
+		return true
+	}
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// CallbackStatus is the delivery state of a queued callback
+type CallbackStatus string
+
+const (
+	CallbackStatusPending   CallbackStatus = "pending"   // Waiting for its first or next attempt
+	CallbackStatusDelivered CallbackStatus = "delivered" // The route answered with a 2xx status
+	CallbackStatusFailed    CallbackStatus = "failed"    // Every attempt failed
+)
+
+// CallbackDelivery is an execution result queued for a callback route. Results are queued
+// when executions finish and delivered in the background, so they survive restarts and are
+// retried while the route's endpoint is down.
+type CallbackDelivery struct {
+	ID            uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID      uuid.UUID       `json:"tenant_id" gorm:"type:uuid;not null;index:idx_callback_deliveries_tenant"`
+	RouteID       uuid.UUID       `json:"route_id" gorm:"type:uuid;not null"`
+	JobID         uuid.UUID       `json:"job_id" gorm:"type:uuid;not null"`
+	ExecutionID   uuid.UUID       `json:"execution_id" gorm:"type:uuid;not null"`
+	URL           string          `json:"url" gorm:"type:text;not null"`
+	Headers       json.RawMessage `json:"-" gorm:"type:jsonb"` // The route's headers when queued, secrets unresolved
+	Payload       json.RawMessage `json:"payload" gorm:"type:jsonb;not null"`
+	Status        CallbackStatus  `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	Attempts      int             `json:"attempts" gorm:"default:0"`
+	NextAttemptAt time.Time       `json:"next_attempt_at" gorm:"not null;index:idx_callback_deliveries_due"`
+	LastError     string          `json:"last_error,omitempty" gorm:"type:text"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName returns the table name for GORM
+func (CallbackDelivery) TableName() string {
+	return "callback_deliveries"
+}
+
+// CreateCallbackRouteRequest represents a request to create a callback route
+type CreateCallbackRouteRequest struct {
+	Name            string            `json:"name" validate:"required,min=1,max=255"`
+	Position        int               `json:"position,omitempty"`
+	Tags            []string          `json:"tags,omitempty"`
+	URL             string            `json:"url" validate:"required"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Statuses        []ExecutionStatus `json:"statuses,omitempty"`
+	IncludeResponse bool              `json:"include_response,omitempty"`
+	Enabled         *bool             `json:"enabled,omitempty"`
+}
+
+// UpdateCallbackRouteRequest represents a request to update a callback route
+type UpdateCallbackRouteRequest struct {
+	Name            *string            `json:"name,omitempty"`
+	Position        *int               `json:"position,omitempty"`
+	Tags            *[]string          `json:"tags,omitempty"`
+	URL             *string            `json:"url,omitempty"`
+	Headers         *map[string]string `json:"headers,omitempty"`
+	Statuses        *[]ExecutionStatus `json:"statuses,omitempty"`
+	IncludeResponse *bool              `json:"include_response,omitempty"`
+	Enabled         *bool              `json:"enabled,omitempty"`
+}
+
+// CallbackDeliveryFilter filters a tenant's callback deliveries
+type CallbackDeliveryFilter struct {
+	TenantID    uuid.UUID
+	RouteID     *uuid.UUID
+	ExecutionID *uuid.UUID
+	Status      CallbackStatus
+	Limit       int
+}
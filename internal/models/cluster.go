@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// ClusterLeader records which instance holds a cluster-wide lock such as scheduler:leader.
+// The holder keeps HeartbeatAt current while it leads and removes the row when it resigns.
+type ClusterLeader struct {
+	LockKey     string    `json:"lock_key" gorm:"type:varchar(100);primaryKey"`
+	InstanceID  string    `json:"instance_id" gorm:"type:varchar(100);not null"`
+	Hostname    string    `json:"hostname" gorm:"type:varchar(255)"`
+	AcquiredAt  time.Time `json:"acquired_at" gorm:"not null"`
+	HeartbeatAt time.Time `json:"heartbeat_at" gorm:"not null"`
+}
+
+// TableName returns the table name for GORM
+func (ClusterLeader) TableName() string {
+	return "cluster_leaders"
+}
+
+// Member statuses derived from heartbeats
+const (
+	MemberStatusAlive = "alive"
+	MemberStatusDead  = "dead" // Missed several heartbeats without leaving the cluster
+)
+
+// ClusterMember is a running instance in the worker registry. Each instance refreshes its
+// row on every heartbeat and removes it when it shuts down.
+type ClusterMember struct {
+	InstanceID  string    `json:"instance_id" gorm:"type:varchar(100);primaryKey"`
+	Hostname    string    `json:"hostname" gorm:"type:varchar(255)"`
+	Role        string    `json:"role" gorm:"type:varchar(20);not null"`
+	WorkerGroup string    `json:"worker_group,omitempty" gorm:"type:varchar(100)"`
+	Phase       string    `json:"phase" gorm:"type:varchar(20);not null"` // starting, ready or draining
+	Capacity    int       `json:"capacity"`                               // Workers; 0 for instances that don't run executions
+	Running     int       `json:"running"`                                // Executions running at the last heartbeat
+	Queued      int       `json:"queued"`                                 // Executions waiting for a worker at the last heartbeat
+	StartedAt   time.Time `json:"started_at" gorm:"not null"`
+	HeartbeatAt time.Time `json:"heartbeat_at" gorm:"not null;index:idx_cluster_members_heartbeat"`
+
+	// Derived when listed
+	Status              string  `json:"status" gorm:"-"`
+	HeartbeatAgeSeconds float64 `json:"heartbeat_age_seconds" gorm:"-"`
+	Leader              bool    `json:"leader" gorm:"-"`
+}
+
+// TableName returns the table name for GORM
+func (ClusterMember) TableName() string {
+	return "cluster_members"
+}
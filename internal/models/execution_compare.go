@@ -0,0 +1,151 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ComparisonChangeLimit is the most changes listed per part of an execution comparison
+const ComparisonChangeLimit = 200
+
+// ExecutionComparison is a diff between two executions of the same job, to see what changed
+// between a good run and a failing one
+type ExecutionComparison struct {
+	JobID    uuid.UUID           `json:"job_id"`
+	A        ComparedExecution   `json:"a"`
+	B        ComparedExecution   `json:"b"`
+	Same     bool                `json:"same"`     // Nothing differs but the timing
+	Outcome  ComparisonChanges   `json:"outcome"`  // status, status_code, error, attempt, worker_group and correlation_id
+	Request  ComparisonChanges   `json:"request"`  // Recorded request definitions
+	Response ComparisonChanges   `json:"response"` // Responses received
+	Timing   ExecutionTimingDiff `json:"timing"`
+}
+
+// ComparedExecution identifies one side of an execution comparison
+type ComparedExecution struct {
+	ID          uuid.UUID       `json:"id"`
+	Status      ExecutionStatus `json:"status"`
+	StatusCode  *int            `json:"status_code,omitempty"`
+	ScheduledAt time.Time       `json:"scheduled_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+}
+
+// ComparisonChanges lists the values that differ between two JSON documents
+type ComparisonChanges struct {
+	Changes   []ValueChange `json:"changes"`
+	Truncated bool          `json:"truncated,omitempty"` // More than ComparisonChangeLimit changes; the rest are left out
+}
+
+// ValueChange is a value that differs between the two sides of a comparison. Path is a
+// JSONPath such as $.headers.Authorization or $.items[2].id.
+type ValueChange struct {
+	Path string          `json:"path"`
+	A    json.RawMessage `json:"a"` // null when missing from a
+	B    json.RawMessage `json:"b"` // null when missing from b
+}
+
+// ExecutionTimingDiff compares the timings and traffic of two executions
+type ExecutionTimingDiff struct {
+	Duration      MetricDiff `json:"duration_ms"`
+	StartDelay    MetricDiff `json:"start_delay_ms"` // From scheduled_at to started_at
+	WallTime      MetricDiff `json:"wall_time_ms"`
+	BytesSent     MetricDiff `json:"bytes_sent"`
+	BytesReceived MetricDiff `json:"bytes_received"`
+}
+
+// MetricDiff is a measure of both executions and how much b differs from a. Delta is left out
+// when either side wasn't measured.
+type MetricDiff struct {
+	A     *int64 `json:"a"`
+	B     *int64 `json:"b"`
+	Delta *int64 `json:"delta"`
+}
+
+// NewMetricDiff compares a measure of two executions
+func NewMetricDiff(a, b *int64) MetricDiff {
+	diff := MetricDiff{A: a, B: b}
+	if a != nil && b != nil {
+		delta := *b - *a
+		diff.Delta = &delta
+	}
+	return diff
+}
+
+// DiffJSON lists the values that differ between two JSON documents, walking objects by key
+// and arrays by index, sorted by path. Values that aren't valid JSON compare as null.
+func DiffJSON(a, b json.RawMessage) ComparisonChanges {
+	var from, to any
+	_ = json.Unmarshal(canonicalJSON(a), &from)
+	_ = json.Unmarshal(canonicalJSON(b), &to)
+
+	result := ComparisonChanges{Changes: []ValueChange{}}
+	diffValues("$", from, to, &result)
+	return result
+}
+
+// diffValues appends the differences between two decoded JSON values under a path
+func diffValues(path string, a, b any, result *ComparisonChanges) {
+	switch from := a.(type) {
+	case map[string]any:
+		if to, ok := b.(map[string]any); ok {
+			keys := make([]string, 0, len(from)+len(to))
+			for key := range from {
+				keys = append(keys, key)
+			}
+			for key := range to {
+				if _, ok := from[key]; !ok {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				diffValues(childPath(path, key), from[key], to[key], result)
+			}
+			return
+		}
+	case []any:
+		if to, ok := b.([]any); ok {
+			for i := 0; i < len(from) || i < len(to); i++ {
+				var x, y any
+				if i < len(from) {
+					x = from[i]
+				}
+				if i < len(to) {
+					y = to[i]
+				}
+				diffValues(fmt.Sprintf("%s[%d]", path, i), x, y, result)
+			}
+			return
+		}
+	}
+
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	if bytes.Equal(x, y) {
+		return
+	}
+	if len(result.Changes) == ComparisonChangeLimit {
+		result.Truncated = true
+		return
+	}
+	result.Changes = append(result.Changes, ValueChange{Path: path, A: x, B: y})
+}
+
+// plainKey matches object keys that can follow a dot in a JSONPath
+var plainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// childPath returns the JSONPath of an object's member, bracketing keys that need quoting
+func childPath(path, key string) string {
+	if plainKey.MatchString(key) {
+		return path + "." + key
+	}
+	quoted, _ := json.Marshal(key)
+	return path + "[" + string(quoted) + "]"
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExecutionSeal links a finished execution into its tenant's hash chain. Each seal hashes the
+// execution's content together with the previous seal's hash, so altering or removing an
+// execution, or rewriting a seal, breaks every later link.
+type ExecutionSeal struct {
+	TenantID    uuid.UUID `json:"tenant_id" gorm:"type:uuid;primaryKey"`
+	Seq         int64     `json:"seq" gorm:"primaryKey;autoIncrement:false"` // Position in the tenant's chain, from 1
+	ExecutionID uuid.UUID `json:"execution_id" gorm:"type:uuid;not null;uniqueIndex:idx_execution_seals_execution"`
+	ContentHash string    `json:"content_hash" gorm:"type:varchar(64);not null"` // ExecutionContentHash of the sealed execution
+	PrevHash    string    `json:"prev_hash" gorm:"type:varchar(64);not null"`    // Hash of the previous seal, empty for the first
+	Hash        string    `json:"hash" gorm:"type:varchar(64);not null"`
+	SealedAt    time.Time `json:"sealed_at" gorm:"not null"`
+}
+
+// TableName returns the table name for GORM
+func (ExecutionSeal) TableName() string {
+	return "execution_seals"
+}
+
+// ComputeHash returns the chain hash of the seal's fields
+func (s *ExecutionSeal) ComputeHash() string {
+	sum := sha256.New()
+	for _, part := range []string{
+		s.PrevHash,
+		strconv.FormatInt(s.Seq, 10),
+		s.ExecutionID.String(),
+		s.ContentHash,
+		s.SealedAt.UTC().Format(time.RFC3339Nano),
+	} {
+		sum.Write([]byte(part))
+		sum.Write([]byte{'|'})
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// sealedExecution is the part of an execution covered by its seal. Acknowledgements are left
+// out, since operators add them after the run.
+type sealedExecution struct {
+	ID          uuid.UUID       `json:"id"`
+	JobID       uuid.UUID       `json:"job_id"`
+	TenantID    uuid.UUID       `json:"tenant_id"`
+	Status      ExecutionStatus `json:"status"`
+	ScheduledAt string          `json:"scheduled_at"`
+	StartedAt   string          `json:"started_at"`
+	CompletedAt string          `json:"completed_at"`
+	Duration    *int64          `json:"duration_ms"`
+	Attempt     int             `json:"attempt"`
+	WorkerID    string          `json:"worker_id"`
+	Request     json.RawMessage `json:"request"`
+	Response    json.RawMessage `json:"response"`
+	StatusCode  *int            `json:"status_code"`
+	Error       string          `json:"error"`
+	TraceID     string          `json:"trace_id"`
+	CreatedAt   string          `json:"created_at"`
+	ReplayOf    *uuid.UUID      `json:"replay_of,omitempty"` // Omitted when unset so earlier seals still verify
+}
+
+// ExecutionContentHash hashes the sealed fields of an execution as read from the database
+func ExecutionContentHash(e *JobExecution) string {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+
+	content, _ := json.Marshal(sealedExecution{
+		ID:          e.ID,
+		JobID:       e.JobID,
+		TenantID:    e.TenantID,
+		Status:      e.Status,
+		ScheduledAt: formatTime(&e.ScheduledAt),
+		StartedAt:   formatTime(e.StartedAt),
+		CompletedAt: formatTime(e.CompletedAt),
+		Duration:    e.Duration,
+		Attempt:     e.Attempt,
+		WorkerID:    e.WorkerID,
+		Request:     nullIfEmpty(e.Request),
+		Response:    nullIfEmpty(e.Response),
+		StatusCode:  e.StatusCode,
+		Error:       e.Error,
+		TraceID:     e.TraceID,
+		CreatedAt:   formatTime(&e.CreatedAt),
+		ReplayOf:    e.ReplayOf,
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// nullIfEmpty keeps empty JSON columns from failing to marshal
+func nullIfEmpty(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return nil
+	}
+	return raw
+}
+
+// ChainVerification is the result of checking a tenant's execution chain
+type ChainVerification struct {
+	Valid       bool        `json:"valid"`                   // No broken links and no altered executions
+	Seals       int64       `json:"seals"`                   // Seals checked
+	Verified    int64       `json:"verified"`                // Sealed executions whose content matches
+	Missing     int64       `json:"missing"`                 // Sealed executions since deleted, e.g. by retention cleanup
+	Altered     []uuid.UUID `json:"altered,omitempty"`       // Executions whose content no longer matches their seal
+	BrokenAtSeq *int64      `json:"broken_at_seq,omitempty"` // First seal whose link or hash doesn't match
+	HeadSeq     int64       `json:"head_seq"`                // Last seal in the chain
+	HeadHash    string      `json:"head_hash,omitempty"`     // Publish this to detect a rewritten chain later
+	Unsealed    int64       `json:"unsealed"`                // Finished executions waiting to be sealed
+	CheckedAt   time.Time   `json:"checked_at"`
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// sealChain seals executions in order, the way the seal repository links them
+func sealChain(executions []JobExecution, sealedAt time.Time) []ExecutionSeal {
+	seals := make([]ExecutionSeal, len(executions))
+	prev := ""
+	for i := range executions {
+		seals[i] = ExecutionSeal{
+			TenantID:    executions[i].TenantID,
+			Seq:         int64(i + 1),
+			ExecutionID: executions[i].ID,
+			ContentHash: ExecutionContentHash(&executions[i]),
+			PrevHash:    prev,
+			SealedAt:    sealedAt,
+		}
+		seals[i].Hash = seals[i].ComputeHash()
+		prev = seals[i].Hash
+	}
+	return seals
+}
+
+// brokenAt returns the first seal whose link or hash doesn't match, or 0, as VerifyChain checks
+func brokenAt(seals []ExecutionSeal) int64 {
+	var seq int64
+	prev := ""
+	for _, seal := range seals {
+		if seal.Seq != seq+1 || seal.PrevHash != prev || seal.ComputeHash() != seal.Hash {
+			return seal.Seq
+		}
+		seq, prev = seal.Seq, seal.Hash
+	}
+	return 0
+}
+
+func TestExecutionSealChain(t *testing.T) {
+	tenantID := uuid.New()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	duration := int64(120)
+	executions := make([]JobExecution, 3)
+	for i := range executions {
+		executions[i] = JobExecution{
+			ID:          uuid.New(),
+			JobID:       uuid.New(),
+			TenantID:    tenantID,
+			Status:      ExecutionStatusCompleted,
+			ScheduledAt: now.Add(time.Duration(i) * time.Minute),
+			Duration:    &duration,
+			Attempt:     1,
+			Response:    json.RawMessage(`{"ok":true}`),
+			CreatedAt:   now,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(seals []ExecutionSeal) []ExecutionSeal
+		want   int64
+	}{
+		{name: "intact", tamper: func(seals []ExecutionSeal) []ExecutionSeal { return seals }},
+		{name: "rewritten content hash", want: 2, tamper: func(seals []ExecutionSeal) []ExecutionSeal {
+			seals[1].ContentHash = "0000"
+			return seals
+		}},
+		{name: "rehashed seal breaks the next link", want: 3, tamper: func(seals []ExecutionSeal) []ExecutionSeal {
+			seals[1].ContentHash = "0000"
+			seals[1].Hash = seals[1].ComputeHash()
+			return seals
+		}},
+		{name: "removed seal", want: 3, tamper: func(seals []ExecutionSeal) []ExecutionSeal {
+			return append(seals[:1], seals[2:]...)
+		}},
+		{name: "reordered seals", want: 3, tamper: func(seals []ExecutionSeal) []ExecutionSeal {
+			seals[1], seals[2] = seals[2], seals[1]
+			return seals
+		}},
+		{name: "changed seal time", want: 1, tamper: func(seals []ExecutionSeal) []ExecutionSeal {
+			seals[0].SealedAt = seals[0].SealedAt.Add(time.Second)
+			return seals
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seals := tt.tamper(sealChain(executions, now))
+			assert.Equal(t, tt.want, brokenAt(seals))
+		})
+	}
+}
+
+func TestExecutionContentHash(t *testing.T) {
+	completedAt := time.Date(2026, 3, 1, 12, 0, 1, 0, time.UTC)
+	execution := JobExecution{
+		ID:          uuid.New(),
+		TenantID:    uuid.New(),
+		Status:      ExecutionStatusCompleted,
+		CompletedAt: &completedAt,
+		Response:    json.RawMessage(`{"ok":true}`),
+	}
+	original := ExecutionContentHash(&execution)
+
+	// The same instant in another zone hashes the same
+	local := completedAt.In(time.FixedZone("UTC+2", 2*60*60))
+	sameInstant := execution
+	sameInstant.CompletedAt = &local
+	assert.Equal(t, original, ExecutionContentHash(&sameInstant))
+
+	tests := []struct {
+		name   string
+		change func(e *JobExecution)
+	}{
+		{name: "status", change: func(e *JobExecution) { e.Status = ExecutionStatusFailed }},
+		{name: "response", change: func(e *JobExecution) { e.Response = json.RawMessage(`{"ok":false}`) }},
+		{name: "error", change: func(e *JobExecution) { e.Error = "boom" }},
+		{name: "attempt", change: func(e *JobExecution) { e.Attempt = 2 }},
+		{name: "completion time", change: func(e *JobExecution) {
+			later := completedAt.Add(time.Millisecond)
+			e.CompletedAt = &later
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := execution
+			tt.change(&changed)
+			assert.NotEqual(t, original, ExecutionContentHash(&changed))
+		})
+	}
+}
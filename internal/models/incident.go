@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IncidentStatus represents the status of an incident
+type IncidentStatus string
+
+const (
+	IncidentStatusOpen     IncidentStatus = "open"
+	IncidentStatusResolved IncidentStatus = "resolved"
+)
+
+// Incident groups consecutive failures of a job or target host
+type Incident struct {
+	ID               uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID         uuid.UUID       `json:"tenant_id" gorm:"type:uuid;not null;index:idx_incidents_tenant"`
+	GroupKey         string          `json:"group_key" gorm:"type:varchar(600);not null;index:idx_incidents_group"` // job:<id> or host:<host>
+	JobID            *uuid.UUID      `json:"job_id,omitempty" gorm:"type:uuid;index:idx_incidents_job"`             // Set when grouped by job
+	TargetHost       string          `json:"target_host,omitempty" gorm:"type:varchar(255)"`
+	Status           IncidentStatus  `json:"status" gorm:"type:varchar(20);not null;default:'open';index:idx_incidents_status"`
+	StartedAt        time.Time       `json:"started_at" gorm:"not null"`
+	EndedAt          *time.Time      `json:"ended_at,omitempty"`
+	FailureCount     int64           `json:"failure_count" gorm:"default:0"`
+	SampleErrors     json.RawMessage `json:"sample_errors,omitempty" gorm:"type:jsonb"` // Most recent error messages
+	FirstExecutionID uuid.UUID       `json:"first_execution_id" gorm:"type:uuid"`
+	LastExecutionID  uuid.UUID       `json:"last_execution_id" gorm:"type:uuid"`
+	AcknowledgedAt   *time.Time      `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy   *uuid.UUID      `json:"acknowledged_by,omitempty" gorm:"type:uuid"`
+	EscalatedAt      *time.Time      `json:"escalated_at,omitempty"`
+	CreatedAt        time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (Incident) TableName() string {
+	return "incidents"
+}
+
+// IncidentFilter represents query filters for incidents
+type IncidentFilter struct {
+	TenantID *uuid.UUID     `json:"tenant_id,omitempty"`
+	JobID    *uuid.UUID     `json:"job_id,omitempty"`
+	Status   IncidentStatus `json:"status,omitempty"`
+	Page     int            `json:"page,omitempty"`
+	PageSize int            `json:"page_size,omitempty"`
+}
+
+// IncidentListResult represents paginated incident results
+type IncidentListResult struct {
+	Incidents  []Incident `json:"incidents"`
+	TotalCount int64      `json:"total_count"`
+	Page       int        `json:"page"`
+	PageSize   int        `json:"page_size"`
+	HasMore    bool       `json:"has_more"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StaleReason is why a job is listed in a janitor report
+type StaleReason string
+
+const (
+	StaleOverdue  StaleReason = "overdue"   // Hasn't run in many times its schedule period
+	StaleNotFound StaleReason = "not_found" // Its endpoint has answered 404 for a long time
+	StalePaused   StaleReason = "paused"    // Paused and left untouched for a long time
+)
+
+// StaleJob is a job a janitor report suggests cleaning up
+type StaleJob struct {
+	JobID     uuid.UUID   `json:"job_id"`
+	Name      string      `json:"name"`
+	Status    JobStatus   `json:"status"`
+	Schedule  string      `json:"schedule"`
+	Endpoint  string      `json:"endpoint,omitempty"`
+	Reason    StaleReason `json:"reason"`
+	Since     time.Time   `json:"since"`            // Last run, first 404 or last change, by reason
+	Period    string      `json:"period,omitempty"` // Longest gap between the schedule's runs, for overdue jobs
+	LastRunAt *time.Time  `json:"last_run_at,omitempty"`
+}
+
+// JanitorReport lists a tenant's jobs that look dead, to help clean up schedules nobody needs
+type JanitorReport struct {
+	TenantID    uuid.UUID  `json:"tenant_id"`
+	GeneratedAt time.Time  `json:"generated_at"`
+	Jobs        []StaleJob `json:"jobs"`
+}
+
+// JanitorReportState records when a tenant was last sent its janitor report, so only one
+// instance sends each report
+type JanitorReportState struct {
+	TenantID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	SentAt   time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for GORM
+func (JanitorReportState) TableName() string {
+	return "janitor_reports"
+}
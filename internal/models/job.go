@@ -20,16 +20,61 @@ const (
 type JobStatus string
 
 const (
-	JobStatusActive   JobStatus = "active"
-	JobStatusPaused   JobStatus = "paused"
-	JobStatusDisabled JobStatus = "disabled"
-	JobStatusDeleted  JobStatus = "deleted"
+	JobStatusActive    JobStatus = "active"
+	JobStatusPaused    JobStatus = "paused"
+	JobStatusDisabled  JobStatus = "disabled"
+	JobStatusDeleted   JobStatus = "deleted"
+	JobStatusCompleted JobStatus = "completed" // One-time job that has run
+	JobStatusCanary    JobStatus = "canary"    // Holding its schedule until its canary execution passes or is confirmed
+)
+
+// ConcurrencyPolicy controls what happens when a scheduled run is due while a previous one is still active
+type ConcurrencyPolicy string
+
+const (
+	ConcurrencyAllow   ConcurrencyPolicy = "allow"   // Run concurrently with active executions
+	ConcurrencyForbid  ConcurrencyPolicy = "forbid"  // Skip the run while an execution is active
+	ConcurrencyReplace ConcurrencyPolicy = "replace" // Cancel active executions, then run
+)
+
+// MisfirePolicy controls what happens to runs missed while the scheduler was down
+type MisfirePolicy string
+
+const (
+	MisfireFireOnce MisfirePolicy = "fire_once" // Run once to catch up, however many runs were missed
+	MisfireFireAll  MisfirePolicy = "fire_all"  // Run every missed occurrence
+	MisfireSkip     MisfirePolicy = "skip"      // Drop missed runs and wait for the next occurrence
+)
+
+// TargetType selects how a job is delivered
+type TargetType string
+
+const (
+	TargetHTTP    TargetType = "http"    // Call the job's endpoint over HTTP
+	TargetKafka   TargetType = "kafka"   // Produce a message to a Kafka topic
+	TargetNATS    TargetType = "nats"    // Publish a message to a NATS subject
+	TargetAMQP    TargetType = "amqp"    // Publish a message to an AMQP (RabbitMQ) exchange
+	TargetCommand TargetType = "command" // Run an allowlisted command on the scheduler host
+	TargetSQL     TargetType = "sql"     // Run a SQL statement against a configured datasource
+	TargetEmail   TargetType = "email"   // Send a templated email through the configured SMTP server
+	TargetGraphQL TargetType = "graphql" // Send a GraphQL query or mutation to the job's endpoint
+	TargetSOAP    TargetType = "soap"    // Post a SOAP envelope to the job's endpoint
+	TargetReport  TargetType = "report"  // Render a document from fetched data and deliver it
+)
+
+// BodyEncoding is how a job's raw body is stored
+type BodyEncoding string
+
+const (
+	BodyEncodingText   BodyEncoding = "text"   // Sent as is
+	BodyEncodingBase64 BodyEncoding = "base64" // Decoded before sending, for binary bodies
 )
 
 // ExecutionStatus represents the status of a job execution
 type ExecutionStatus string
 
 const (
+	ExecutionStatusQueued    ExecutionStatus = "queued" // Held back by the tenant's rate limit
 	ExecutionStatusPending   ExecutionStatus = "pending"
 	ExecutionStatusRunning   ExecutionStatus = "running"
 	ExecutionStatusCompleted ExecutionStatus = "completed"
@@ -39,33 +84,76 @@ const (
 	ExecutionStatusTimeout   ExecutionStatus = "timeout"
 )
 
+// Finished reports whether an execution with the status has ended for good
+func (s ExecutionStatus) Finished() bool {
+	switch s {
+	case ExecutionStatusCompleted, ExecutionStatusFailed, ExecutionStatusCancelled, ExecutionStatusTimeout:
+		return true
+	}
+	return false
+}
+
 // Job represents a scheduled job
 type Job struct {
-	ID          uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	TenantID    uuid.UUID       `json:"tenant_id" gorm:"type:uuid;index:idx_jobs_tenant"`
-	Name        string          `json:"name" gorm:"type:varchar(255);not null"`
-	Description string          `json:"description,omitempty" gorm:"type:text"`
-	Type        JobType         `json:"type" gorm:"type:varchar(20);not null;index:idx_jobs_type"`
-	Status      JobStatus       `json:"status" gorm:"type:varchar(20);not null;default:'active';index:idx_jobs_status"`
-	Schedule    string          `json:"schedule" gorm:"type:varchar(100)"` // Cron expression or interval
-	Timezone    string          `json:"timezone" gorm:"type:varchar(50);default:'UTC'"`
-	Endpoint    string          `json:"endpoint" gorm:"type:varchar(500);not null"`        // HTTP endpoint to call
-	Method      string          `json:"method" gorm:"type:varchar(10);default:'POST'"`     // HTTP method
-	Headers     json.RawMessage `json:"headers,omitempty" gorm:"type:jsonb"`               // HTTP headers
-	Payload     json.RawMessage `json:"payload,omitempty" gorm:"type:jsonb"`               // Request body
-	Timeout     int             `json:"timeout" gorm:"default:30"`                         // Timeout in seconds
-	MaxRetries  int             `json:"max_retries" gorm:"default:3"`                      // Max retry attempts
-	RetryDelay  int             `json:"retry_delay" gorm:"default:60"`                     // Delay between retries in seconds
-	Priority    int             `json:"priority" gorm:"default:5;index:idx_jobs_priority"` // 1-10, higher is more important
-	Tags        json.RawMessage `json:"tags,omitempty" gorm:"type:jsonb"`                  // Job tags for filtering
-	Metadata    json.RawMessage `json:"metadata,omitempty" gorm:"type:jsonb"`              // Additional metadata
-	NextRunAt   *time.Time      `json:"next_run_at,omitempty" gorm:"index:idx_jobs_next_run"`
-	LastRunAt   *time.Time      `json:"last_run_at,omitempty"`
-	RunCount    int64           `json:"run_count" gorm:"default:0"`
-	FailCount   int64           `json:"fail_count" gorm:"default:0"`
-	CreatedBy   *uuid.UUID      `json:"created_by,omitempty" gorm:"type:uuid"`
-	CreatedAt   time.Time       `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	ID                  uuid.UUID         `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID            uuid.UUID         `json:"tenant_id" gorm:"type:uuid;index:idx_jobs_tenant;uniqueIndex:idx_jobs_client_reference,priority:1"`
+	ClientReference     *string           `json:"client_reference,omitempty" gorm:"type:varchar(255);uniqueIndex:idx_jobs_client_reference,priority:2"` // Idempotency key, unique per tenant
+	ExternalID          *string           `json:"external_id,omitempty" gorm:"type:varchar(255)"`                                                       // Key in an upstream catalog, unique per tenant among jobs that aren't deleted
+	CorrelationID       string            `json:"correlation_id,omitempty" gorm:"type:varchar(255);index:idx_jobs_correlation"`                         // Business entity the job acts on, e.g. order-1234
+	Name                string            `json:"name" gorm:"type:varchar(255);not null"`
+	Description         string            `json:"description,omitempty" gorm:"type:text"`
+	Type                JobType           `json:"type" gorm:"type:varchar(20);not null;index:idx_jobs_type"`
+	Status              JobStatus         `json:"status" gorm:"type:varchar(20);not null;default:'active';index:idx_jobs_status"`
+	Schedule            string            `json:"schedule" gorm:"type:varchar(100)"` // Cron expression, interval or RFC3339 timestamp
+	Timezone            string            `json:"timezone" gorm:"type:varchar(50);default:'UTC'"`
+	TargetType          TargetType        `json:"target_type" gorm:"type:varchar(20);default:'http'"`         // How the job is delivered
+	TargetConfig        json.RawMessage   `json:"target_config,omitempty" gorm:"type:jsonb"`                  // Settings for non-HTTP targets
+	Endpoint            string            `json:"endpoint" gorm:"type:varchar(500);not null"`                 // HTTP endpoint to call
+	Method              string            `json:"method" gorm:"type:varchar(10);default:'POST'"`              // HTTP method
+	Headers             json.RawMessage   `json:"headers,omitempty" gorm:"type:jsonb"`                        // HTTP headers
+	Payload             json.RawMessage   `json:"payload,omitempty" gorm:"type:jsonb"`                        // JSON request body
+	ContentType         string            `json:"content_type,omitempty" gorm:"type:varchar(255)"`            // Request Content-Type, defaults by body kind
+	Body                string            `json:"body,omitempty" gorm:"type:text"`                            // Raw request body, used instead of payload
+	BodyEncoding        BodyEncoding      `json:"body_encoding,omitempty" gorm:"type:varchar(10)"`            // How body is stored, text when empty
+	Timeout             int               `json:"timeout" gorm:"default:30"`                                  // Timeout in seconds
+	MaxRetries          int               `json:"max_retries" gorm:"default:3"`                               // Max retry attempts
+	RetryDelay          int               `json:"retry_delay" gorm:"default:60"`                              // Delay between retries in seconds
+	Priority            int               `json:"priority" gorm:"default:5;index:idx_jobs_priority"`          // 1-10, higher is more important
+	Tags                json.RawMessage   `json:"tags,omitempty" gorm:"type:jsonb"`                           // Job tags for filtering
+	Metadata            json.RawMessage   `json:"metadata,omitempty" gorm:"type:jsonb"`                       // Additional metadata
+	ResponseProjection  string            `json:"response_projection,omitempty" gorm:"type:text"`             // JSONPath projection applied before storing responses
+	ResponseSink        json.RawMessage   `json:"response_sink,omitempty" gorm:"type:jsonb"`                  // Object storage that successful responses are streamed to
+	TLS                 json.RawMessage   `json:"tls,omitempty" gorm:"type:jsonb"`                            // Client certificate secrets for mutual TLS
+	ConcurrencyPolicy   ConcurrencyPolicy `json:"concurrency_policy" gorm:"type:varchar(20);default:'allow'"` // allow, forbid or replace
+	MisfirePolicy       MisfirePolicy     `json:"misfire_policy" gorm:"type:varchar(20);default:'fire_once'"` // fire_once, fire_all or skip
+	WorkerGroup         string            `json:"worker_group,omitempty" gorm:"type:varchar(100)"`            // Only workers started with this WORKER_GROUP run the job
+	BlackoutCalendar    string            `json:"blackout_calendar,omitempty" gorm:"type:varchar(100)"`       // Name of a blackout calendar the job follows
+	BlackoutWindows     json.RawMessage   `json:"blackout_windows,omitempty" gorm:"type:jsonb"`               // The job's own blackout windows
+	HolidayCalendar     string            `json:"holiday_calendar,omitempty" gorm:"type:varchar(100)"`        // Name of a holiday calendar whose dates a cron job skips
+	NextRunAt           *time.Time        `json:"next_run_at,omitempty" gorm:"index:idx_jobs_next_run"`
+	LastRunAt           *time.Time        `json:"last_run_at,omitempty"`
+	RunCount            int64             `json:"run_count" gorm:"default:0"`
+	FailCount           int64             `json:"fail_count" gorm:"default:0"`
+	ConsecutiveFailures int64             `json:"consecutive_failures" gorm:"default:0"` // Final failures since the last success
+	NotFoundSince       *time.Time        `json:"not_found_since,omitempty"`             // When the endpoint started answering 404, cleared by any other status
+	StartAt             *time.Time        `json:"start_at,omitempty"`                    // When a recurring job begins firing
+	EndAt               *time.Time        `json:"end_at,omitempty"`                      // When a recurring job is disabled
+	MaxRuns             int64             `json:"max_runs,omitempty" gorm:"default:0"`   // Successful runs after which a recurring job is disabled; 0 for no limit
+	TriggerLimit        *int              `json:"trigger_limit,omitempty"`               // Manual triggers per minute, 0 is unlimited; JOB_TRIGGERS_PER_MINUTE when unset
+	TriggerDedupe       *int              `json:"trigger_dedupe_seconds,omitempty"`      // Identical manual triggers within this many seconds collapse, 0 never; JOB_TRIGGER_DEDUPE_SECONDS when unset
+	CreatedBy           *uuid.UUID        `json:"created_by,omitempty" gorm:"type:uuid"`
+	ResumeAt            *time.Time        `json:"resume_at,omitempty" gorm:"index:idx_jobs_resume_at"` // When a paused job resumes on its own
+	CanaryExecutionID   *uuid.UUID        `json:"canary_execution_id,omitempty" gorm:"type:uuid"`      // Latest canary execution, run before the job's schedule started
+	DeletedAt           *time.Time        `json:"deleted_at,omitempty"`
+	PurgeAt             *time.Time        `json:"purge_at,omitempty" gorm:"index:idx_jobs_purge_at"`        // When a deleted job and its executions and history are removed
+	ShiftID             *uuid.UUID        `json:"shift_id,omitempty" gorm:"type:uuid;index:idx_jobs_shift"` // Active schedule shift moving the job's occurrences
+	ShiftOffsetSeconds  int64             `json:"shift_offset_seconds,omitempty" gorm:"default:0"`
+	ShiftStartsAt       *time.Time        `json:"shift_starts_at,omitempty"`
+	ShiftEndsAt         *time.Time        `json:"shift_ends_at,omitempty"`
+	ClaimedBy           string            `json:"-" gorm:"type:varchar(100);<-:false"` // Instance dispatching the job in claim mode
+	ClaimedUntil        *time.Time        `json:"-" gorm:"<-:false"`                   // When an unreleased claim lapses
+	CreatedAt           time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 // TableName returns the table name for GORM
@@ -73,25 +161,57 @@ func (Job) TableName() string {
 	return "jobs"
 }
 
+// BlackoutWindowList returns the job's own blackout windows
+func (j *Job) BlackoutWindowList() []BlackoutWindow {
+	var windows []BlackoutWindow
+	if len(j.BlackoutWindows) > 0 {
+		_ = json.Unmarshal(j.BlackoutWindows, &windows)
+	}
+	return windows
+}
+
+// TagList returns the job's tags as a string slice
+func (j *Job) TagList() []string {
+	var tags []string
+	if len(j.Tags) > 0 {
+		_ = json.Unmarshal(j.Tags, &tags)
+	}
+	return tags
+}
+
 // JobExecution represents a single execution of a job
 type JobExecution struct {
-	ID          uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	JobID       uuid.UUID       `json:"job_id" gorm:"type:uuid;not null;index:idx_executions_job"`
-	TenantID    uuid.UUID       `json:"tenant_id" gorm:"type:uuid;index:idx_executions_tenant"`
-	Status      ExecutionStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index:idx_executions_status"`
-	ScheduledAt time.Time       `json:"scheduled_at" gorm:"not null;index:idx_executions_scheduled"`
-	StartedAt   *time.Time      `json:"started_at,omitempty"`
-	CompletedAt *time.Time      `json:"completed_at,omitempty"`
-	Duration    *int64          `json:"duration_ms,omitempty"`                        // Duration in milliseconds
-	Attempt     int             `json:"attempt" gorm:"default:1"`                     // Current attempt number
-	WorkerID    string          `json:"worker_id,omitempty" gorm:"type:varchar(100)"` // ID of worker executing
-	Request     json.RawMessage `json:"request,omitempty" gorm:"type:jsonb"`          // Request sent
-	Response    json.RawMessage `json:"response,omitempty" gorm:"type:jsonb"`         // Response received
-	StatusCode  *int            `json:"status_code,omitempty"`                        // HTTP status code
-	Error       string          `json:"error,omitempty" gorm:"type:text"`             // Error message
-	TraceID     string          `json:"trace_id,omitempty" gorm:"type:varchar(64)"`   // Distributed trace ID
-	CreatedAt   time.Time       `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	ID             uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	JobID          uuid.UUID       `json:"job_id" gorm:"type:uuid;not null;index:idx_executions_job"`
+	TenantID       uuid.UUID       `json:"tenant_id" gorm:"type:uuid;index:idx_executions_tenant"`
+	Status         ExecutionStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index:idx_executions_status"`
+	ScheduledAt    time.Time       `json:"scheduled_at" gorm:"not null;index:idx_executions_scheduled"`
+	StartedAt      *time.Time      `json:"started_at,omitempty"`
+	CompletedAt    *time.Time      `json:"completed_at,omitempty"`
+	WorkerGroup    string          `json:"worker_group,omitempty" gorm:"type:varchar(100)"`
+	CorrelationID  string          `json:"correlation_id,omitempty" gorm:"type:varchar(255);index:idx_executions_correlation"`
+	Duration       *int64          `json:"duration_ms,omitempty"`                          // Duration in milliseconds
+	Attempt        int             `json:"attempt" gorm:"default:1"`                       // Current attempt number
+	WallTime       int64           `json:"wall_time_ms" gorm:"default:0"`                  // Milliseconds workers spent on all attempts
+	BytesSent      int64           `json:"bytes_sent" gorm:"default:0"`                    // Bytes sent to the target by all attempts
+	BytesReceived  int64           `json:"bytes_received" gorm:"default:0"`                // Bytes received from the target by all attempts
+	WorkerID       string          `json:"worker_id,omitempty" gorm:"type:varchar(100)"`   // ID of worker executing
+	ClaimedBy      string          `json:"-" gorm:"type:varchar(100)"`                     // Instance whose workers will run the pending execution
+	ClaimedUntil   *time.Time      `json:"-"`                                              // When other instances' workers may pick the pending execution up
+	Request        json.RawMessage `json:"request,omitempty" gorm:"type:jsonb"`            // Request definition at creation, secrets unresolved
+	ReplayOf       *uuid.UUID      `json:"replay_of,omitempty" gorm:"type:uuid"`           // Execution whose request this one replays
+	Canary         bool            `json:"canary,omitempty" gorm:"not null;default:false"` // Run once before the job's schedule starts, not counted in its history
+	Response       json.RawMessage `json:"response,omitempty" gorm:"type:jsonb"`           // Response received
+	StatusCode     *int            `json:"status_code,omitempty"`                          // HTTP status code
+	Error          string          `json:"error,omitempty" gorm:"type:text"`               // Error message
+	TraceID        string          `json:"trace_id,omitempty" gorm:"type:varchar(64)"`     // Distributed trace ID
+	AcknowledgedAt *time.Time      `json:"acknowledged_at,omitempty"`                      // When an operator acknowledged the failure
+	AcknowledgedBy *uuid.UUID      `json:"acknowledged_by,omitempty" gorm:"type:uuid"`     // Operator who acknowledged the failure
+	AckComment     string          `json:"ack_comment,omitempty" gorm:"type:text"`         // Operator comment attached on acknowledgement
+	CreatedAt      time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	QueuePosition  *int64          `json:"queue_position,omitempty" gorm:"-"`     // Executions ahead of a pending or queued one in the dispatch queue
+	EstimatedStart *time.Time      `json:"estimated_start_at,omitempty" gorm:"-"` // When a pending or queued execution is expected to start
 }
 
 // TableName returns the table name for GORM
@@ -99,6 +219,28 @@ func (JobExecution) TableName() string {
 	return "job_executions"
 }
 
+// ExecutionProgress is an event on an execution's events stream
+type ExecutionProgress struct {
+	ID             uuid.UUID       `json:"id"`
+	Status         ExecutionStatus `json:"status"`
+	QueuePosition  *int64          `json:"queue_position,omitempty"`
+	EstimatedStart *time.Time      `json:"estimated_start_at,omitempty"`
+	StartedAt      *time.Time      `json:"started_at,omitempty"`
+	CompletedAt    *time.Time      `json:"completed_at,omitempty"`
+}
+
+// Progress returns the execution's progress event
+func (e *JobExecution) Progress() ExecutionProgress {
+	return ExecutionProgress{
+		ID:             e.ID,
+		Status:         e.Status,
+		QueuePosition:  e.QueuePosition,
+		EstimatedStart: e.EstimatedStart,
+		StartedAt:      e.StartedAt,
+		CompletedAt:    e.CompletedAt,
+	}
+}
+
 // JobSchedule represents a calculated schedule entry
 type JobSchedule struct {
 	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
@@ -116,12 +258,17 @@ func (JobSchedule) TableName() string {
 	return "job_schedules"
 }
 
+// HistoryTimezoneUTC is the timezone every tenant's history is bucketed in. Tenants with
+// another timezone also have history bucketed in theirs.
+const HistoryTimezoneUTC = "UTC"
+
 // JobHistory represents historical job statistics
 type JobHistory struct {
 	ID            uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	JobID         uuid.UUID `json:"job_id" gorm:"type:uuid;not null;index:idx_history_job"`
+	JobID         uuid.UUID `json:"job_id" gorm:"type:uuid;not null;index:idx_history_job;uniqueIndex:idx_history_job_timezone_date,priority:1"`
 	TenantID      uuid.UUID `json:"tenant_id" gorm:"type:uuid;index:idx_history_tenant"`
-	Date          time.Time `json:"date" gorm:"type:date;not null;index:idx_history_date"`
+	Date          time.Time `json:"date" gorm:"type:date;not null;index:idx_history_date;uniqueIndex:idx_history_job_timezone_date,priority:3"`
+	Timezone      string    `json:"timezone" gorm:"type:varchar(64);not null;default:'UTC';uniqueIndex:idx_history_job_timezone_date,priority:2"` // Zone whose calendar day Date is
 	TotalRuns     int64     `json:"total_runs" gorm:"default:0"`
 	SuccessCount  int64     `json:"success_count" gorm:"default:0"`
 	FailureCount  int64     `json:"failure_count" gorm:"default:0"`
@@ -129,6 +276,10 @@ type JobHistory struct {
 	AvgDuration   int64     `json:"avg_duration_ms" gorm:"default:0"`
 	MinDuration   int64     `json:"min_duration_ms"`
 	MaxDuration   int64     `json:"max_duration_ms"`
+	WallTime      int64     `json:"wall_time_ms" gorm:"default:0"`   // Milliseconds workers spent on all attempts of the day's executions
+	BytesSent     int64     `json:"bytes_sent" gorm:"default:0"`     // Bytes sent to the job's target
+	BytesReceived int64     `json:"bytes_received" gorm:"default:0"` // Bytes received from the job's target
+	Retries       int64     `json:"retries" gorm:"default:0"`        // Attempts after the first
 	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
@@ -140,61 +291,161 @@ func (JobHistory) TableName() string {
 
 // CreateJobRequest represents a request to create a new job
 type CreateJobRequest struct {
-	Name        string          `json:"name" validate:"required,min=1,max=255"`
-	Description string          `json:"description,omitempty"`
-	Type        JobType         `json:"type" validate:"required,oneof=cron one_time interval"`
-	Schedule    string          `json:"schedule" validate:"required"`
-	Timezone    string          `json:"timezone,omitempty"`
-	Endpoint    string          `json:"endpoint" validate:"required,url"`
-	Method      string          `json:"method,omitempty"`
-	Headers     json.RawMessage `json:"headers,omitempty"`
-	Payload     json.RawMessage `json:"payload,omitempty"`
-	Timeout     int             `json:"timeout,omitempty"`
-	MaxRetries  int             `json:"max_retries,omitempty"`
-	RetryDelay  int             `json:"retry_delay,omitempty"`
-	Priority    int             `json:"priority,omitempty"`
-	Tags        json.RawMessage `json:"tags,omitempty"`
-	Metadata    json.RawMessage `json:"metadata,omitempty"`
+	ClientReference    string            `json:"client_reference,omitempty" validate:"max=255"` // Idempotency key; retried creates return the existing job
+	ExternalID         string            `json:"external_id,omitempty" validate:"max=255"`      // Key in an upstream catalog; batch upserts match jobs by it
+	CorrelationID      string            `json:"correlation_id,omitempty" validate:"max=255"`   // Business entity the job acts on, carried onto its executions
+	Name               string            `json:"name" validate:"required,min=1,max=255"`
+	Description        string            `json:"description,omitempty"`
+	Type               JobType           `json:"type" validate:"required,oneof=cron one_time interval"`
+	Schedule           string            `json:"schedule" validate:"required"`
+	Timezone           string            `json:"timezone,omitempty"`
+	TargetType         TargetType        `json:"target_type,omitempty"` // Defaults to http
+	TargetConfig       json.RawMessage   `json:"target_config,omitempty"`
+	Endpoint           string            `json:"endpoint,omitempty" validate:"omitempty,url"` // Required for http targets
+	Method             string            `json:"method,omitempty" validate:"omitempty,oneof=GET HEAD POST PUT PATCH DELETE OPTIONS"`
+	Headers            json.RawMessage   `json:"headers,omitempty"`
+	Payload            json.RawMessage   `json:"payload,omitempty"`
+	ContentType        string            `json:"content_type,omitempty"`
+	Body               string            `json:"body,omitempty"`
+	BodyEncoding       BodyEncoding      `json:"body_encoding,omitempty" validate:"omitempty,oneof=text base64"`
+	Timeout            int               `json:"timeout,omitempty"`
+	MaxRetries         int               `json:"max_retries,omitempty"`
+	RetryDelay         int               `json:"retry_delay,omitempty"`
+	Priority           int               `json:"priority,omitempty"`
+	Tags               json.RawMessage   `json:"tags,omitempty"`
+	Metadata           json.RawMessage   `json:"metadata,omitempty"`
+	ResponseProjection string            `json:"response_projection,omitempty"`
+	ResponseSink       json.RawMessage   `json:"response_sink,omitempty"`
+	TLS                json.RawMessage   `json:"tls,omitempty"`
+	ConcurrencyPolicy  ConcurrencyPolicy `json:"concurrency_policy,omitempty" validate:"omitempty,oneof=allow forbid replace"`
+	MisfirePolicy      MisfirePolicy     `json:"misfire_policy,omitempty" validate:"omitempty,oneof=fire_once fire_all skip"`
+	WorkerGroup        string            `json:"worker_group,omitempty"`
+	BlackoutCalendar   string            `json:"blackout_calendar,omitempty"`
+	BlackoutWindows    []BlackoutWindow  `json:"blackout_windows,omitempty"`
+	HolidayCalendar    string            `json:"holiday_calendar,omitempty"`
+	StartAt            *time.Time        `json:"start_at,omitempty"`               // Fire a cron or interval job only from this time
+	EndAt              *time.Time        `json:"end_at,omitempty"`                 // Disable a cron or interval job at this time
+	MaxRuns            int64             `json:"max_runs,omitempty"`               // Disable a cron or interval job after this many successful runs
+	TriggerLimit       *int              `json:"trigger_limit,omitempty"`          // Manual triggers per minute, 0 is unlimited
+	TriggerDedupe      *int              `json:"trigger_dedupe_seconds,omitempty"` // Window collapsing identical manual triggers, 0 disables
+	Canary             bool              `json:"canary,omitempty"`                 // Run once right away and start the schedule only once that run passes or is confirmed
 }
 
 // UpdateJobRequest represents a request to update a job
 type UpdateJobRequest struct {
-	Name        *string          `json:"name,omitempty"`
-	Description *string          `json:"description,omitempty"`
-	Schedule    *string          `json:"schedule,omitempty"`
-	Timezone    *string          `json:"timezone,omitempty"`
-	Endpoint    *string          `json:"endpoint,omitempty"`
-	Method      *string          `json:"method,omitempty"`
-	Headers     *json.RawMessage `json:"headers,omitempty"`
-	Payload     *json.RawMessage `json:"payload,omitempty"`
-	Timeout     *int             `json:"timeout,omitempty"`
-	MaxRetries  *int             `json:"max_retries,omitempty"`
-	RetryDelay  *int             `json:"retry_delay,omitempty"`
-	Priority    *int             `json:"priority,omitempty"`
-	Tags        *json.RawMessage `json:"tags,omitempty"`
-	Metadata    *json.RawMessage `json:"metadata,omitempty"`
+	Name               *string            `json:"name,omitempty" validate:"omitempty,max=255"`
+	CorrelationID      *string            `json:"correlation_id,omitempty" validate:"omitempty,max=255"`
+	Description        *string            `json:"description,omitempty"`
+	Schedule           *string            `json:"schedule,omitempty"`
+	Timezone           *string            `json:"timezone,omitempty"`
+	TargetType         *TargetType        `json:"target_type,omitempty"`
+	TargetConfig       *json.RawMessage   `json:"target_config,omitempty"`
+	Endpoint           *string            `json:"endpoint,omitempty" validate:"omitempty,url"`
+	Method             *string            `json:"method,omitempty" validate:"omitempty,oneof=GET HEAD POST PUT PATCH DELETE OPTIONS"`
+	Headers            *json.RawMessage   `json:"headers,omitempty"`
+	Payload            *json.RawMessage   `json:"payload,omitempty"`
+	ContentType        *string            `json:"content_type,omitempty"`
+	Body               *string            `json:"body,omitempty"`
+	BodyEncoding       *BodyEncoding      `json:"body_encoding,omitempty" validate:"omitempty,oneof=text base64"`
+	Timeout            *int               `json:"timeout,omitempty"`
+	MaxRetries         *int               `json:"max_retries,omitempty"`
+	RetryDelay         *int               `json:"retry_delay,omitempty"`
+	Priority           *int               `json:"priority,omitempty"`
+	Tags               *json.RawMessage   `json:"tags,omitempty"`
+	Metadata           *json.RawMessage   `json:"metadata,omitempty"`
+	ResponseProjection *string            `json:"response_projection,omitempty"`
+	ResponseSink       *json.RawMessage   `json:"response_sink,omitempty"` // {} stores responses on the execution again
+	TLS                *json.RawMessage   `json:"tls,omitempty"`           // {} removes the TLS settings
+	ConcurrencyPolicy  *ConcurrencyPolicy `json:"concurrency_policy,omitempty"`
+	MisfirePolicy      *MisfirePolicy     `json:"misfire_policy,omitempty"`
+	WorkerGroup        *string            `json:"worker_group,omitempty"`      // Empty unpins the job
+	BlackoutCalendar   *string            `json:"blackout_calendar,omitempty"` // Empty stops following a calendar
+	BlackoutWindows    *[]BlackoutWindow  `json:"blackout_windows,omitempty"`  // Empty removes the job's own windows
+	HolidayCalendar    *string            `json:"holiday_calendar,omitempty"`  // Empty stops skipping holidays
+	StartAt            *string            `json:"start_at,omitempty"`          // RFC3339; empty removes the start date
+	EndAt              *string            `json:"end_at,omitempty"`            // RFC3339; empty removes the end date
+	MaxRuns            *int64             `json:"max_runs,omitempty"`          // 0 removes the limit
+	TriggerLimit       *int               `json:"trigger_limit,omitempty"`
+	TriggerDedupe      *int               `json:"trigger_dedupe_seconds,omitempty"`
+}
+
+// TriggerJobRequest represents a request to trigger a job
+type TriggerJobRequest struct {
+	CorrelationID string `json:"correlation_id,omitempty"` // Overrides the job's correlation ID for this execution
+}
+
+// PauseJobRequest represents a request to pause a job
+type PauseJobRequest struct {
+	ResumeAt *time.Time `json:"resume_at,omitempty"` // Resume the job automatically at this time; paused until resumed when unset
+}
+
+// AcknowledgeExecutionRequest represents a request to acknowledge a failed execution
+type AcknowledgeExecutionRequest struct {
+	Comment string `json:"comment,omitempty"`
 }
 
 // JobFilter represents query filters for jobs
 type JobFilter struct {
-	TenantID *uuid.UUID `json:"tenant_id,omitempty"`
-	Status   JobStatus  `json:"status,omitempty"`
-	Type     JobType    `json:"type,omitempty"`
-	Name     string     `json:"name,omitempty"`
-	Tags     []string   `json:"tags,omitempty"`
-	Page     int        `json:"page,omitempty"`
-	PageSize int        `json:"page_size,omitempty"`
+	TenantID      *uuid.UUID `json:"tenant_id,omitempty"`
+	Status        JobStatus  `json:"status,omitempty"`
+	Type          JobType    `json:"type,omitempty"`
+	Name          string     `json:"name,omitempty"`
+	CorrelationID string     `json:"correlation_id,omitempty"`
+	Tags          []string   `json:"tags,omitempty"`
+	Fields        []string   `json:"fields,omitempty"` // JSON field names to load; all when empty
+	Count         CountMode  `json:"count,omitempty"`
+	Page          int        `json:"page,omitempty"`
+	PageSize      int        `json:"page_size,omitempty"`
 }
 
 // ExecutionFilter represents query filters for executions
 type ExecutionFilter struct {
-	JobID     *uuid.UUID      `json:"job_id,omitempty"`
-	TenantID  *uuid.UUID      `json:"tenant_id,omitempty"`
-	Status    ExecutionStatus `json:"status,omitempty"`
-	StartTime *time.Time      `json:"start_time,omitempty"`
-	EndTime   *time.Time      `json:"end_time,omitempty"`
-	Page      int             `json:"page,omitempty"`
-	PageSize  int             `json:"page_size,omitempty"`
+	JobID         *uuid.UUID      `json:"job_id,omitempty"`
+	TenantID      *uuid.UUID      `json:"tenant_id,omitempty"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	Status        ExecutionStatus `json:"status,omitempty"`
+	Acknowledged  *bool           `json:"acknowledged,omitempty"`
+	StartTime     *time.Time      `json:"start_time,omitempty"`
+	EndTime       *time.Time      `json:"end_time,omitempty"`
+	Fields        []string        `json:"fields,omitempty"` // JSON field names to load; all when empty
+	Count         CountMode       `json:"count,omitempty"`
+	Page          int             `json:"page,omitempty"`
+	PageSize      int             `json:"page_size,omitempty"`
+}
+
+// CountMode selects how listings compute their total count
+type CountMode string
+
+const (
+	CountExact     CountMode = "exact"     // COUNT(*) over the matching rows
+	CountEstimated CountMode = "estimated" // Query planner estimate for large results, exact for small ones
+)
+
+// JobFieldColumns maps selectable job fields to their columns
+var JobFieldColumns = map[string]string{
+	"id": "id", "tenant_id": "tenant_id", "client_reference": "client_reference", "correlation_id": "correlation_id",
+	"name": "name", "description": "description", "type": "type", "status": "status",
+	"schedule": "schedule", "timezone": "timezone", "target_type": "target_type", "target_config": "target_config",
+	"endpoint": "endpoint", "method": "method",
+	"headers": "headers", "payload": "payload", "content_type": "content_type", "body": "body", "body_encoding": "body_encoding", "timeout": "timeout", "max_retries": "max_retries",
+	"retry_delay": "retry_delay", "priority": "priority", "tags": "tags", "metadata": "metadata",
+	"response_projection": "response_projection", "response_sink": "response_sink", "tls": "tls", "concurrency_policy": "concurrency_policy",
+	"misfire_policy": "misfire_policy", "next_run_at": "next_run_at", "last_run_at": "last_run_at",
+	"run_count": "run_count", "fail_count": "fail_count", "start_at": "start_at", "end_at": "end_at", "max_runs": "max_runs",
+	"consecutive_failures": "consecutive_failures", "created_by": "created_by",
+	"shift_id": "shift_id", "shift_offset_seconds": "shift_offset_seconds",
+	"shift_starts_at": "shift_starts_at", "shift_ends_at": "shift_ends_at",
+	"created_at": "created_at", "updated_at": "updated_at",
+}
+
+// ExecutionFieldColumns maps selectable execution fields to their columns
+var ExecutionFieldColumns = map[string]string{
+	"id": "id", "job_id": "job_id", "tenant_id": "tenant_id", "correlation_id": "correlation_id", "status": "status",
+	"scheduled_at": "scheduled_at", "started_at": "started_at", "completed_at": "completed_at",
+	"duration_ms": "duration", "attempt": "attempt", "worker_id": "worker_id",
+	"request": "request", "replay_of": "replay_of", "response": "response", "status_code": "status_code", "error": "error",
+	"trace_id": "trace_id", "acknowledged_at": "acknowledged_at", "acknowledged_by": "acknowledged_by",
+	"ack_comment": "ack_comment", "created_at": "created_at", "updated_at": "updated_at",
 }
 
 // JobStats represents job statistics
@@ -213,20 +464,40 @@ type JobStats struct {
 
 // JobListResult represents paginated job results
 type JobListResult struct {
-	Jobs       []Job `json:"jobs"`
-	TotalCount int64 `json:"total_count"`
-	Page       int   `json:"page"`
-	PageSize   int   `json:"page_size"`
-	HasMore    bool  `json:"has_more"`
+	Jobs           []Job `json:"jobs"`
+	TotalCount     int64 `json:"total_count"`
+	TotalEstimated bool  `json:"total_estimated,omitempty"`
+	Page           int   `json:"page"`
+	PageSize       int   `json:"page_size"`
+	HasMore        bool  `json:"has_more"`
+}
+
+// Related resources that can be embedded in job listings
+const (
+	JobIncludeLastExecution = "last_execution" // Most recent execution
+	JobIncludeHistory7d     = "history_7d"     // Run statistics for the last 7 days
+)
+
+// ValidJobInclude reports whether name is a related resource that can be embedded in job listings
+func ValidJobInclude(name string) bool {
+	return name == JobIncludeLastExecution || name == JobIncludeHistory7d
+}
+
+// JobView is a job with requested related resources embedded
+type JobView struct {
+	Job
+	LastExecution *JobExecution           `json:"last_execution,omitempty"`
+	History7d     *AggregatedHistoryStats `json:"history_7d,omitempty"`
 }
 
 // ExecutionListResult represents paginated execution results
 type ExecutionListResult struct {
-	Executions []JobExecution `json:"executions"`
-	TotalCount int64          `json:"total_count"`
-	Page       int            `json:"page"`
-	PageSize   int            `json:"page_size"`
-	HasMore    bool           `json:"has_more"`
+	Executions     []JobExecution `json:"executions"`
+	TotalCount     int64          `json:"total_count"`
+	TotalEstimated bool           `json:"total_estimated,omitempty"`
+	Page           int            `json:"page"`
+	PageSize       int            `json:"page_size"`
+	HasMore        bool           `json:"has_more"`
 }
 
 // AggregatedHistoryStats contains aggregated statistics
@@ -238,4 +509,204 @@ type AggregatedHistoryStats struct {
 	MinDuration   int64   `json:"min_duration"`
 	MaxDuration   int64   `json:"max_duration"`
 	SuccessRate   float64 `json:"success_rate"`
+	WallTime      int64   `json:"wall_time_ms"`
+	BytesSent     int64   `json:"bytes_sent"`
+	BytesReceived int64   `json:"bytes_received"`
+	Retries       int64   `json:"retries"`
+}
+
+// TenantResourceUsage is the resources a tenant's executions used over a period, for metering
+// and capacity planning
+type TenantResourceUsage struct {
+	TenantID      uuid.UUID `json:"tenant_id"`
+	Executions    int64     `json:"executions"`
+	Retries       int64     `json:"retries"`
+	WallTime      int64     `json:"wall_time_ms"`
+	BytesSent     int64     `json:"bytes_sent"`
+	BytesReceived int64     `json:"bytes_received"`
+}
+
+// FieldError is a problem with one field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// JobValidation is the outcome of validating a job definition without creating it
+type JobValidation struct {
+	Valid     bool         `json:"valid"`
+	Errors    []FieldError `json:"errors"`
+	NextRunAt *time.Time   `json:"next_run_at,omitempty"` // When the job would first run if created now
+}
+
+// RebuildHistoryRequest represents a request to rebuild job history from executions
+type RebuildHistoryRequest struct {
+	From     string     `json:"from"`                // First day rebuilt (YYYY-MM-DD)
+	To       string     `json:"to"`                  // Last day rebuilt (YYYY-MM-DD)
+	Timezone string     `json:"timezone,omitempty"`  // Zone whose days are rebuilt, UTC by default
+	TenantID *uuid.UUID `json:"tenant_id,omitempty"` // Only rebuild this tenant's history
+	JobID    *uuid.UUID `json:"job_id,omitempty"`    // Only rebuild this job's history
+}
+
+// HistoryRebuildResult reports what a history rebuild replaced
+type HistoryRebuildResult struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Timezone string `json:"timezone"`
+	Days     int    `json:"days"`
+	Deleted  int64  `json:"deleted"` // History records removed
+	Created  int64  `json:"created"` // History records rebuilt from executions
+}
+
+// JobExportVersion is the current version of the job export document format
+const JobExportVersion = 1
+
+// Import conflict handling modes
+const (
+	ImportConflictSkip   = "skip"   // Keep the existing job
+	ImportConflictUpdate = "update" // Overwrite the existing job with the imported definition
+	ImportConflictCreate = "create" // Create a new job alongside the existing one
+)
+
+// JobSpec is the portable definition of a job, without IDs or run counters
+type JobSpec struct {
+	CreateJobRequest
+	Status JobStatus `json:"status,omitempty"`
+}
+
+// JobExport is a portable document of a tenant's jobs
+type JobExport struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	Jobs       []JobSpec `json:"jobs"`
+}
+
+// JobImportError describes a job that could not be imported
+type JobImportError struct {
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// JobImportResult summarizes an import
+type JobImportResult struct {
+	Created int              `json:"created"`
+	Updated int              `json:"updated"`
+	Skipped int              `json:"skipped"`
+	Errors  []JobImportError `json:"errors,omitempty"`
+}
+
+// Outcomes of an item of a batch upsert
+const (
+	UpsertCreated   = "created"
+	UpsertUpdated   = "updated"
+	UpsertUnchanged = "unchanged"
+)
+
+// BatchUpsertJobsRequest represents a request to create or update many jobs keyed by external_id
+type BatchUpsertJobsRequest struct {
+	Jobs []JobSpec `json:"jobs"`
+}
+
+// BatchUpsertItem is the outcome of one job of a batch upsert
+type BatchUpsertItem struct {
+	Index      int       `json:"index"`
+	ExternalID string    `json:"external_id"`
+	JobID      uuid.UUID `json:"job_id"`
+	Result     string    `json:"result"` // created, updated or unchanged
+}
+
+// BatchUpsertError describes a job of a batch upsert that failed validation
+type BatchUpsertError struct {
+	Index      int    `json:"index"`
+	ExternalID string `json:"external_id"`
+	Error      string `json:"error"`
+}
+
+// BatchUpsertResult summarizes a batch upsert
+type BatchUpsertResult struct {
+	Created   int                `json:"created"`
+	Updated   int                `json:"updated"`
+	Unchanged int                `json:"unchanged"`
+	Items     []BatchUpsertItem  `json:"items,omitempty"`
+	Errors    []BatchUpsertError `json:"errors,omitempty"`
+}
+
+// CreateShareLinkRequest represents a request to create a public share link for a job
+type CreateShareLinkRequest struct {
+	ExpiresInHours int `json:"expires_in_hours,omitempty"`
+}
+
+// ShareLink is a signed, expiring read-only link to a job's status
+type ShareLink struct {
+	URL       string    `json:"url"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PublicExecution is the subset of an execution exposed through share links
+type PublicExecution struct {
+	Status      ExecutionStatus `json:"status"`
+	ScheduledAt time.Time       `json:"scheduled_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	Duration    *int64          `json:"duration_ms,omitempty"`
+	StatusCode  *int            `json:"status_code,omitempty"`
+}
+
+// PublicJobStatus is the read-only job view exposed through share links
+type PublicJobStatus struct {
+	Name             string            `json:"name"`
+	Type             JobType           `json:"type"`
+	Schedule         string            `json:"schedule"`
+	Timezone         string            `json:"timezone,omitempty"`
+	Status           JobStatus         `json:"status"`
+	Healthy          bool              `json:"healthy"`
+	SuccessRate      float64           `json:"success_rate"` // Over the recent executions shown
+	NextRunAt        *time.Time        `json:"next_run_at,omitempty"`
+	LastRunAt        *time.Time        `json:"last_run_at,omitempty"`
+	RecentExecutions []PublicExecution `json:"recent_executions"`
+	LinkExpiresAt    time.Time         `json:"link_expires_at"`
+}
+
+// SimulateScheduleRequest proposes a schedule change to preview
+type SimulateScheduleRequest struct {
+	Schedule string `json:"schedule" validate:"required"`
+	Days     int    `json:"days,omitempty"` // Window to compare, 30 days by default
+}
+
+// SchedulePreviewRequest describes a schedule to list the next fire times of
+type SchedulePreviewRequest struct {
+	Type            JobType // cron when empty
+	Schedule        string
+	Timezone        string // Fire times are returned in this zone, UTC when empty
+	HolidayCalendar string // Holiday calendar whose dates a cron schedule skips
+	Count           int    // 10 when 0
+}
+
+// SchedulePreview lists a schedule's next fire times
+type SchedulePreview struct {
+	Type     JobType     `json:"type"`
+	Schedule string      `json:"schedule"`
+	Timezone string      `json:"timezone"`
+	Runs     []time.Time `json:"runs"`
+}
+
+// ScheduleMove is an occurrence the proposed schedule fires at a different time
+type ScheduleMove struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// ScheduleSimulation compares a job's upcoming occurrences under its current and a proposed schedule
+type ScheduleSimulation struct {
+	From      time.Time      `json:"from"`
+	Until     time.Time      `json:"until"`
+	Current   int            `json:"current"`   // Occurrences under the current schedule
+	Proposed  int            `json:"proposed"`  // Occurrences under the proposed schedule
+	Unchanged int            `json:"unchanged"` // Occurrences both schedules share
+	Added     []time.Time    `json:"added"`     // Only in the proposed schedule
+	Removed   []time.Time    `json:"removed"`   // Only in the current schedule
+	Moved     []ScheduleMove `json:"moved"`     // Shifted to a nearby time
+	Truncated bool           `json:"truncated"` // A schedule fires more often than can be compared; the window was cut short
 }
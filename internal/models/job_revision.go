@@ -0,0 +1,121 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobRevisionChange says what produced a job revision
+type JobRevisionChange string
+
+const (
+	JobRevisionBaseline        JobRevisionChange = "baseline" // State when revisions were introduced
+	JobRevisionCreated         JobRevisionChange = "created"
+	JobRevisionUpdated         JobRevisionChange = "updated"
+	JobRevisionPaused          JobRevisionChange = "paused"
+	JobRevisionResumed         JobRevisionChange = "resumed"
+	JobRevisionDeleted         JobRevisionChange = "deleted"
+	JobRevisionRestored        JobRevisionChange = "restored"
+	JobRevisionImported        JobRevisionChange = "imported"
+	JobRevisionSynced          JobRevisionChange = "synced"           // Updated by a batch upsert
+	JobRevisionEnded           JobRevisionChange = "ended"            // Disabled on reaching its end_at or max_runs
+	JobRevisionCanary          JobRevisionChange = "canary"           // Held for a canary execution
+	JobRevisionActivated       JobRevisionChange = "activated"        // Started its schedule after its canary passed or was confirmed
+	JobRevisionRolledBack      JobRevisionChange = "rolled_back"      // Definition restored from an earlier revision
+	JobRevisionRolledOut       JobRevisionChange = "rolled_out"       // Schedule or endpoint changed by a rollout
+	JobRevisionRolloutReverted JobRevisionChange = "rollout_reverted" // Schedule or endpoint put back when a rollout was rolled back
+)
+
+// JobRevision is a snapshot of a job taken after each change to it
+type JobRevision struct {
+	ID        uuid.UUID         `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	JobID     uuid.UUID         `json:"job_id" gorm:"type:uuid;not null;uniqueIndex:idx_job_revisions_job_revision,priority:1"`
+	TenantID  uuid.UUID         `json:"tenant_id" gorm:"type:uuid;not null;index:idx_job_revisions_tenant"`
+	Revision  int               `json:"revision" gorm:"not null;uniqueIndex:idx_job_revisions_job_revision,priority:2"` // Counts up from 1 per job
+	Change    JobRevisionChange `json:"change" gorm:"type:varchar(20);not null"`
+	Snapshot  json.RawMessage   `json:"snapshot" gorm:"type:jsonb;not null"`   // The job as JSON
+	ChangedBy *uuid.UUID        `json:"changed_by,omitempty" gorm:"type:uuid"` // User whose request made the change; empty for the scheduler's own changes
+	Diff      json.RawMessage   `json:"diff,omitempty" gorm:"type:jsonb"`      // JobFieldChange list against the previous revision
+	CreatedAt time.Time         `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName returns the table name for GORM
+func (JobRevision) TableName() string {
+	return "job_revisions"
+}
+
+// JobFieldChange is a field of a job that a revision changed, with its values before and after
+type JobFieldChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"` // null when the field was unset
+	To    json.RawMessage `json:"to"`   // null when the field was cleared
+}
+
+// revisionRuntimeFields are the job fields the scheduler maintains as the job runs; changes to
+// them aren't listed in revision diffs
+var revisionRuntimeFields = map[string]bool{
+	"id":                   true,
+	"tenant_id":            true,
+	"next_run_at":          true,
+	"last_run_at":          true,
+	"run_count":            true,
+	"fail_count":           true,
+	"consecutive_failures": true,
+	"created_by":           true,
+	"created_at":           true,
+	"updated_at":           true,
+}
+
+// DiffJobSnapshots lists the fields that differ between two job snapshots, sorted by name.
+// Runtime fields such as next_run_at and run_count are left out.
+func DiffJobSnapshots(before, after json.RawMessage) ([]JobFieldChange, error) {
+	var from, to map[string]json.RawMessage
+	if err := json.Unmarshal(before, &from); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(after, &to); err != nil {
+		return nil, err
+	}
+
+	fields := make([]string, 0, len(to))
+	for field := range to {
+		fields = append(fields, field)
+	}
+	for field := range from {
+		if _, ok := to[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := []JobFieldChange{}
+	for _, field := range fields {
+		if revisionRuntimeFields[field] {
+			continue
+		}
+		oldValue, newValue := canonicalJSON(from[field]), canonicalJSON(to[field])
+		if bytes.Equal(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, JobFieldChange{Field: field, From: oldValue, To: newValue})
+	}
+	return changes, nil
+}
+
+// canonicalJSON re-encodes a JSON value so equal values encode to the same bytes, whatever
+// their key order or spacing. Missing values encode to null.
+func canonicalJSON(raw json.RawMessage) json.RawMessage {
+	var v any
+	if len(raw) == 0 || json.Unmarshal(raw, &v) != nil {
+		return json.RawMessage("null")
+	}
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return raw
+	}
+	return canonical
+}
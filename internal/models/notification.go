@@ -0,0 +1,135 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChannelType represents a notification delivery channel
+type ChannelType string
+
+const (
+	ChannelTypeWebhook   ChannelType = "webhook"   // Generic JSON webhook
+	ChannelTypeSlack     ChannelType = "slack"     // Slack incoming webhook with action buttons
+	ChannelTypeTeams     ChannelType = "teams"     // Microsoft Teams incoming webhook or workflow
+	ChannelTypePagerDuty ChannelType = "pagerduty" // PagerDuty Events API v2
+	ChannelTypeOpsgenie  ChannelType = "opsgenie"  // Opsgenie Alert API
+	ChannelTypeEmail     ChannelType = "email"     // Email through the configured SMTP server
+)
+
+// NotificationChannel is a tenant-configured destination for notifications
+type NotificationChannel struct {
+	ID                uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID          uuid.UUID       `json:"tenant_id" gorm:"type:uuid;not null;index:idx_channels_tenant"`
+	Name              string          `json:"name" gorm:"type:varchar(255);not null"`
+	Type              ChannelType     `json:"type" gorm:"type:varchar(20);not null"`
+	Config            json.RawMessage `json:"config,omitempty" gorm:"type:jsonb"` // Channel specific settings (url, headers, ...)
+	Enabled           bool            `json:"enabled" gorm:"default:true"`
+	SubscriptionsOnly bool            `json:"subscriptions_only" gorm:"default:false"` // Only receive events matched by subscriptions
+	CreatedAt         time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (NotificationChannel) TableName() string {
+	return "notification_channels"
+}
+
+// NotificationSubscription routes events for a job, or jobs matching a tag selector, to a user's channel
+type NotificationSubscription struct {
+	ID        uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID  uuid.UUID       `json:"tenant_id" gorm:"type:uuid;not null;index:idx_subscriptions_tenant_user"`
+	UserID    uuid.UUID       `json:"user_id" gorm:"type:uuid;not null;index:idx_subscriptions_tenant_user"`
+	ChannelID uuid.UUID       `json:"channel_id" gorm:"type:uuid;not null;index:idx_subscriptions_channel"`
+	JobID     *uuid.UUID      `json:"job_id,omitempty" gorm:"type:uuid"`
+	Tags      json.RawMessage `json:"tags,omitempty" gorm:"type:jsonb"` // Matches jobs carrying all of these tags
+	CreatedAt time.Time       `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName returns the table name for GORM
+func (NotificationSubscription) TableName() string {
+	return "notification_subscriptions"
+}
+
+// Matches reports whether the subscription selects a job with the given ID and tags
+func (s *NotificationSubscription) Matches(jobID *uuid.UUID, jobTags []string) bool {
+	if s.JobID != nil {
+		return jobID != nil && *s.JobID == *jobID
+	}
+	return matchesTags(s.Tags, jobTags)
+}
+
+// matchesTags reports whether a job carries every tag of a non-empty JSON tag selector
+func matchesTags(tags json.RawMessage, jobTags []string) bool {
+	var selector []string
+	if err := json.Unmarshal(tags, &selector); err != nil || len(selector) == 0 {
+		return false
+	}
+
+	have := make(map[string]bool, len(jobTags))
+	for _, tag := range jobTags {
+		have[tag] = true
+	}
+	for _, tag := range selector {
+		if !have[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+// NotificationPolicy controls when a tenant's notifications are sent
+type NotificationPolicy struct {
+	TenantID             uuid.UUID  `json:"tenant_id" gorm:"type:uuid;primaryKey"`
+	DedupeWindowSeconds  int        `json:"dedupe_window_seconds" gorm:"default:900"` // Suppress identical notifications within this window
+	MinFailures          int        `json:"min_failures" gorm:"default:1"`            // Failures in an incident before alerting
+	EscalateAfterMinutes int        `json:"escalate_after_minutes" gorm:"default:0"`  // Escalate unacknowledged incidents, 0 disables
+	EscalationChannelID  *uuid.UUID `json:"escalation_channel_id,omitempty" gorm:"type:uuid"`
+	QuietHoursStart      string     `json:"quiet_hours_start,omitempty" gorm:"type:varchar(5)"` // HH:MM
+	QuietHoursEnd        string     `json:"quiet_hours_end,omitempty" gorm:"type:varchar(5)"`   // HH:MM
+	Timezone             string     `json:"timezone,omitempty" gorm:"type:varchar(50);default:'UTC'"`
+	CreatedAt            time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt            time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (NotificationPolicy) TableName() string {
+	return "notification_policies"
+}
+
+// CreateChannelRequest represents a request to create a notification channel
+type CreateChannelRequest struct {
+	Name              string          `json:"name" validate:"required,min=1,max=255"`
+	Type              ChannelType     `json:"type" validate:"required"`
+	Config            json.RawMessage `json:"config,omitempty"`
+	Enabled           *bool           `json:"enabled,omitempty"`
+	SubscriptionsOnly bool            `json:"subscriptions_only,omitempty"`
+}
+
+// UpdateChannelRequest represents a request to update a notification channel
+type UpdateChannelRequest struct {
+	Name              *string          `json:"name,omitempty"`
+	Config            *json.RawMessage `json:"config,omitempty"`
+	Enabled           *bool            `json:"enabled,omitempty"`
+	SubscriptionsOnly *bool            `json:"subscriptions_only,omitempty"`
+}
+
+// CreateSubscriptionRequest represents a request to subscribe to a job or tag selector
+type CreateSubscriptionRequest struct {
+	ChannelID uuid.UUID  `json:"channel_id" validate:"required"`
+	JobID     *uuid.UUID `json:"job_id,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+}
+
+// UpdatePolicyRequest represents a request to update a tenant's notification policy
+type UpdatePolicyRequest struct {
+	DedupeWindowSeconds  *int       `json:"dedupe_window_seconds,omitempty"`
+	MinFailures          *int       `json:"min_failures,omitempty"`
+	EscalateAfterMinutes *int       `json:"escalate_after_minutes,omitempty"`
+	EscalationChannelID  *uuid.UUID `json:"escalation_channel_id,omitempty"`
+	QuietHoursStart      *string    `json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd        *string    `json:"quiet_hours_end,omitempty"`
+	Timezone             *string    `json:"timezone,omitempty"`
+}
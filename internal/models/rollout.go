@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RolloutStatus is the lifecycle state of a rollout
+type RolloutStatus string
+
+const (
+	RolloutActive     RolloutStatus = "active"      // Batches are applied each hour
+	RolloutHalted     RolloutStatus = "halted"      // Stopped by an operator or a failure spike; changed jobs keep the change
+	RolloutCompleted  RolloutStatus = "completed"   // Every job was changed and stayed healthy for an hour
+	RolloutRolledBack RolloutStatus = "rolled_back" // Changed jobs were put back on their previous schedule and endpoint
+)
+
+// Rollout applies a schedule or endpoint change to a fleet of jobs created from the same
+// upstream catalog entry, i.e. sharing an external ID across tenants, a share of them per
+// hour. Before each batch the failure rate of the jobs already changed is compared with the
+// fleet's failure rate before the rollout, and the rollout halts when it rose by more than
+// FailureThreshold.
+type Rollout struct {
+	ID                  uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ExternalID          string         `json:"external_id" gorm:"type:varchar(255);not null"`
+	Schedule            string         `json:"schedule,omitempty" gorm:"type:varchar(100)"` // New schedule, empty to keep the jobs' own
+	Endpoint            string         `json:"endpoint,omitempty" gorm:"type:varchar(500)"` // New endpoint, empty to keep the jobs' own
+	PercentPerHour      int            `json:"percent_per_hour" gorm:"not null"`
+	FailureThreshold    float64        `json:"failure_threshold" gorm:"not null"` // Rise in failure rate over the baseline that halts the rollout
+	MinExecutions       int            `json:"min_executions" gorm:"not null"`    // Finished executions of changed jobs needed before the rate is judged
+	BaselineFailureRate float64        `json:"baseline_failure_rate" gorm:"not null;default:0"`
+	Status              RolloutStatus  `json:"status" gorm:"type:varchar(20);not null;default:'active';index:idx_rollouts_status"`
+	TotalJobs           int            `json:"total_jobs" gorm:"not null"`
+	AppliedJobs         int            `json:"applied_jobs" gorm:"not null;default:0"`
+	NextBatchAt         *time.Time     `json:"next_batch_at,omitempty"`
+	HealthSince         time.Time      `json:"health_since" gorm:"not null"` // Executions before this aren't judged, moved on resume
+	HaltReason          string         `json:"halt_reason,omitempty" gorm:"type:text"`
+	HaltedAt            *time.Time     `json:"halted_at,omitempty"`
+	CompletedAt         *time.Time     `json:"completed_at,omitempty"`
+	RolledBackAt        *time.Time     `json:"rolled_back_at,omitempty"`
+	Reason              string         `json:"reason,omitempty" gorm:"type:text"`
+	CreatedBy           *uuid.UUID     `json:"created_by,omitempty" gorm:"type:uuid"`
+	CreatedAt           time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	Health              *RolloutHealth `json:"health,omitempty" gorm:"-"` // Failure rate of the changed jobs, filled in on get
+}
+
+// TableName returns the table name for GORM
+func (Rollout) TableName() string {
+	return "rollouts"
+}
+
+// RolloutJob is a job in a rollout, in the order the jobs are changed, with the schedule
+// and endpoint it had before
+type RolloutJob struct {
+	RolloutID        uuid.UUID  `json:"rollout_id" gorm:"type:uuid;primaryKey"`
+	JobID            uuid.UUID  `json:"job_id" gorm:"type:uuid;primaryKey;index:idx_rollout_jobs_job"`
+	TenantID         uuid.UUID  `json:"tenant_id" gorm:"type:uuid;not null"`
+	Position         int        `json:"position" gorm:"not null"`
+	PreviousSchedule string     `json:"previous_schedule,omitempty" gorm:"type:varchar(100)"`
+	PreviousEndpoint string     `json:"previous_endpoint,omitempty" gorm:"type:varchar(500)"`
+	AppliedAt        *time.Time `json:"applied_at,omitempty"`
+	Skipped          bool       `json:"skipped,omitempty" gorm:"not null;default:false"` // Deleted before its batch, left alone
+	RevertedAt       *time.Time `json:"reverted_at,omitempty"`
+}
+
+// TableName returns the table name for GORM
+func (RolloutJob) TableName() string {
+	return "rollout_jobs"
+}
+
+// RolloutHealth is the failure rate of a rollout's changed jobs since they were changed
+type RolloutHealth struct {
+	Executions  int64   `json:"executions"` // Finished executions judged
+	Failures    int64   `json:"failures"`
+	FailureRate float64 `json:"failure_rate"`
+}
+
+// CreateRolloutRequest represents a request to roll a schedule or endpoint change out to
+// every job with an external ID, in all tenants or only the listed ones
+type CreateRolloutRequest struct {
+	ExternalID       string      `json:"external_id" validate:"required,max=255"`
+	TenantIDs        []uuid.UUID `json:"tenant_ids,omitempty"`
+	Schedule         string      `json:"schedule,omitempty"`
+	Endpoint         string      `json:"endpoint,omitempty" validate:"omitempty,url"`
+	PercentPerHour   int         `json:"percent_per_hour,omitempty" validate:"min=0,max=100"`         // Defaults to 10
+	FailureThreshold *float64    `json:"failure_threshold,omitempty" validate:"omitempty,gt=0,lte=1"` // Defaults to 0.1
+	MinExecutions    int         `json:"min_executions,omitempty" validate:"min=0"`                   // Defaults to 10
+	Reason           string      `json:"reason,omitempty"`
+}
+
+// HaltRolloutRequest represents a request to stop a rollout before its next batch
+type HaltRolloutRequest struct {
+	Reason string `json:"reason,omitempty" validate:"max=500"`
+}
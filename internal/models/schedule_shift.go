@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScheduleShiftStatus is the lifecycle state of a schedule shift
+type ScheduleShiftStatus string
+
+const (
+	ScheduleShiftActive   ScheduleShiftStatus = "active"   // Occurrences in the window are moved
+	ScheduleShiftReverted ScheduleShiftStatus = "reverted" // Jobs are back on their own schedules
+)
+
+// ScheduleShift moves the occurrences of a set of jobs that fall between StartsAt and EndsAt
+// by an offset, e.g. during a regional failover. It is reverted automatically once every
+// moved occurrence has passed.
+type ScheduleShift struct {
+	ID            uuid.UUID           `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID      uuid.UUID           `json:"tenant_id" gorm:"type:uuid;not null;index:idx_schedule_shifts_tenant"`
+	OffsetSeconds int64               `json:"offset_seconds" gorm:"not null"` // Added to each occurrence in the window, may be negative
+	StartsAt      time.Time           `json:"starts_at" gorm:"not null"`
+	EndsAt        time.Time           `json:"ends_at" gorm:"not null"`
+	RevertAt      time.Time           `json:"revert_at" gorm:"not null;index:idx_schedule_shifts_revert"` // When the last moved occurrence has passed
+	Reason        string              `json:"reason,omitempty" gorm:"type:text"`
+	JobIDs        json.RawMessage     `json:"job_ids" gorm:"type:jsonb;not null"` // Jobs the shift was applied to
+	Status        ScheduleShiftStatus `json:"status" gorm:"type:varchar(20);not null;default:'active'"`
+	RevertedAt    *time.Time          `json:"reverted_at,omitempty"`
+	CreatedBy     *uuid.UUID          `json:"created_by,omitempty" gorm:"type:uuid"`
+	CreatedAt     time.Time           `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName returns the table name for GORM
+func (ScheduleShift) TableName() string {
+	return "schedule_shifts"
+}
+
+// CreateScheduleShiftRequest represents a request to shift a set of jobs' schedules.
+// Jobs are picked by ID, by tag, or both.
+type CreateScheduleShiftRequest struct {
+	JobIDs   []uuid.UUID `json:"job_ids,omitempty"`
+	Tag      string      `json:"tag,omitempty"`       // Shift every job carrying this tag
+	Offset   string      `json:"offset"`              // Duration such as "2h" or "-30m"
+	StartsAt *time.Time  `json:"starts_at,omitempty"` // Defaults to now
+	EndsAt   time.Time   `json:"ends_at"`
+	Reason   string      `json:"reason,omitempty"`
+}
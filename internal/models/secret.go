@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Secret is an encrypted value jobs reference as {{secret:NAME}} in headers, payloads and bodies.
+// The value is never returned by the API.
+type Secret struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	TenantID    uuid.UUID  `json:"tenant_id" gorm:"type:uuid;not null;uniqueIndex:idx_secrets_tenant_name"`
+	Name        string     `json:"name" gorm:"type:varchar(100);not null;uniqueIndex:idx_secrets_tenant_name"`
+	Description string     `json:"description,omitempty" gorm:"type:text"`
+	Ciphertext  []byte     `json:"-" gorm:"type:bytea;not null"`            // Nonce followed by the AES-GCM sealed value
+	KeyID       string     `json:"key_id" gorm:"type:varchar(16);not null"` // Identifies the key the value is sealed with
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty" gorm:"type:uuid"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (Secret) TableName() string {
+	return "secrets"
+}
+
+// CreateSecretRequest represents a request to store a secret
+type CreateSecretRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Value       string `json:"value" validate:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+// UpdateSecretRequest represents a request to replace a secret's value or description
+type UpdateSecretRequest struct {
+	Value       *string `json:"value,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
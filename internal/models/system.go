@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Runtime settings changeable through the configuration API
+const (
+	SettingWorkerCount    = "worker_count"     // Workers per instance
+	SettingTickIntervalMs = "tick_interval_ms" // How often the scheduling loop looks for due jobs
+	SettingCleanupDays    = "cleanup_days"     // Days finished executions and history are kept
+)
+
+// RuntimeSetting overrides a setting from the environment for every instance, until it is
+// changed again. Instances pick overrides up within a few seconds.
+type RuntimeSetting struct {
+	Name      string     `json:"name" gorm:"type:varchar(100);primaryKey"`
+	Value     int        `json:"value" gorm:"not null"`
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty" gorm:"type:uuid"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (RuntimeSetting) TableName() string {
+	return "runtime_settings"
+}
+
+// ConfigChange records a change of a runtime setting
+type ConfigChange struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Setting   string     `json:"setting" gorm:"type:varchar(100);not null"`
+	OldValue  int        `json:"old_value"`
+	NewValue  int        `json:"new_value"`
+	ChangedBy *uuid.UUID `json:"changed_by,omitempty" gorm:"type:uuid"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime;index:idx_config_changes_created"`
+}
+
+// TableName returns the table name for GORM
+func (ConfigChange) TableName() string {
+	return "config_changes"
+}
+
+// UpdateRuntimeConfigRequest represents a request to change runtime settings
+type UpdateRuntimeConfigRequest struct {
+	WorkerCount    *int `json:"worker_count,omitempty"`
+	TickIntervalMs *int `json:"tick_interval_ms,omitempty"`
+	CleanupDays    *int `json:"cleanup_days,omitempty"`
+}
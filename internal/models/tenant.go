@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TenantSettings holds per-tenant overrides of service-wide limits. Zero values use the global default.
+type TenantSettings struct {
+	TenantID        uuid.UUID `json:"tenant_id" gorm:"type:uuid;primaryKey"`
+	MaxPayloadBytes int       `json:"max_payload_bytes" gorm:"default:0"` // Largest job payload accepted
+	MaxHeadersBytes int       `json:"max_headers_bytes" gorm:"default:0"` // Largest job header set accepted
+
+	ExecutionsPerMinute int `json:"executions_per_minute" gorm:"default:0"` // Executions started per minute
+
+	AllowInsecureTLS bool `json:"allow_insecure_tls" gorm:"not null;default:false"` // Jobs may set tls.insecure_skip_verify
+
+	// Hosts, *.domains, IPs and CIDRs the tenant's jobs may call, besides the service-wide EGRESS_DENY
+	EgressAllow json.RawMessage `json:"egress_allow,omitempty" gorm:"type:jsonb"` // When set, destinations must match one
+	EgressDeny  json.RawMessage `json:"egress_deny,omitempty" gorm:"type:jsonb"`  // Destinations refused on top of EGRESS_DENY
+
+	// Buckets and bucket/prefixes in object storage the tenant's jobs may read and write, granted
+	// by platform operators; without any the tenant can't use object storage
+	StorageAllow json.RawMessage `json:"storage_allow,omitempty" gorm:"type:jsonb"`
+	// Names of COMMAND_ALLOWLIST entries the tenant's command jobs may run, granted by platform
+	// operators; without any the tenant can't run commands
+	CommandAllow json.RawMessage `json:"command_allow,omitempty" gorm:"type:jsonb"`
+
+	Timezone string `json:"timezone,omitempty" gorm:"type:varchar(64);not null;default:''"` // IANA zone history days are bucketed in besides UTC
+
+	// Set by operators; none of the tenant's jobs are dispatched while it is paused
+	PausedAt    *time.Time `json:"paused_at,omitempty"`
+	PauseReason string     `json:"pause_reason,omitempty" gorm:"type:text;not null;default:''"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName returns the table name for GORM
+func (TenantSettings) TableName() string {
+	return "tenant_settings"
+}
+
+// TenantLimits are the limits in effect for a tenant after applying overrides
+type TenantLimits struct {
+	MaxPayloadBytes     int      `json:"max_payload_bytes"`
+	MaxHeadersBytes     int      `json:"max_headers_bytes"`
+	ExecutionsPerMinute int      `json:"executions_per_minute"` // 0 is unlimited
+	AllowInsecureTLS    bool     `json:"allow_insecure_tls"`
+	Timezone            string   `json:"timezone"`                // Zone of the tenant's history days, UTC by default
+	EgressAllow         []string `json:"egress_allow,omitempty"`  // Empty allows every destination not denied
+	EgressDeny          []string `json:"egress_deny,omitempty"`   // The tenant's rules, EGRESS_DENY applying as well
+	StorageAllow        []string `json:"storage_allow,omitempty"` // Empty refuses all object storage
+	CommandAllow        []string `json:"command_allow,omitempty"` // Empty refuses all commands
+}
+
+// TenantSettingsView shows a tenant's overrides alongside the limits in effect
+type TenantSettingsView struct {
+	Settings  TenantSettings `json:"settings"`
+	Effective TenantLimits   `json:"effective"`
+}
+
+// UpdateTenantSettingsRequest represents a request to update a tenant's overrides
+type UpdateTenantSettingsRequest struct {
+	MaxPayloadBytes     *int      `json:"max_payload_bytes,omitempty"`
+	MaxHeadersBytes     *int      `json:"max_headers_bytes,omitempty"`
+	ExecutionsPerMinute *int      `json:"executions_per_minute,omitempty"`
+	AllowInsecureTLS    *bool     `json:"allow_insecure_tls,omitempty"`
+	Timezone            *string   `json:"timezone,omitempty"`     // IANA zone, e.g. Asia/Tokyo; empty restores UTC
+	EgressAllow         *[]string `json:"egress_allow,omitempty"` // Replaces the allowlist; an empty list removes it
+	EgressDeny          *[]string `json:"egress_deny,omitempty"`  // Replaces the denylist
+}
+
+// UpdateTenantGrantsRequest represents a request to change what a tenant's jobs may access
+// with the service's own credentials
+type UpdateTenantGrantsRequest struct {
+	StorageAllow *[]string `json:"storage_allow,omitempty"` // Replaces the granted buckets and bucket/prefixes
+	CommandAllow *[]string `json:"command_allow,omitempty"` // Replaces the granted COMMAND_ALLOWLIST names
+}
+
+// PauseTenantRequest represents a request to pause a tenant
+type PauseTenantRequest struct {
+	Reason string `json:"reason,omitempty" validate:"max=500"` // e.g. delinquent account or requested freeze
+}
+
+// TenantPause reports whether a tenant's jobs are dispatched
+type TenantPause struct {
+	TenantID    uuid.UUID  `json:"tenant_id"`
+	Paused      bool       `json:"paused"`
+	PausedAt    *time.Time `json:"paused_at,omitempty"`
+	Reason      string     `json:"reason,omitempty"`
+	Rescheduled int        `json:"rescheduled,omitempty"` // Jobs whose next run was recalculated on resume
+}
+
+// TenantUsage reports a tenant's execution rate in the current one-minute window
+type TenantUsage struct {
+	ExecutionsPerMinute int       `json:"executions_per_minute"` // 0 is unlimited
+	Started             int64     `json:"started"`               // Executions started in the current window
+	Remaining           int64     `json:"remaining"`             // -1 when unlimited
+	Queued              int64     `json:"queued"`                // Executions waiting for capacity
+	WindowResetsAt      time.Time `json:"window_resets_at"`
+}
@@ -0,0 +1,387 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/egress"
+	"github.com/minisource/scheduler/internal/localstore"
+	"github.com/minisource/scheduler/internal/mail"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/repository"
+	"github.com/redis/go-redis/v9"
+)
+
+// Sender delivers events to one type of notification channel
+type Sender interface {
+	// Validate checks that a channel's configuration is usable
+	Validate(channel *models.NotificationChannel) error
+	// Send delivers an event to the channel
+	Send(ctx context.Context, channel *models.NotificationChannel, event Event) error
+}
+
+// Dispatcher applies tenant notification policies and delivers events to channels
+type Dispatcher struct {
+	config       config.NotificationConfig
+	repo         *repository.NotificationRepository
+	incidentRepo *repository.IncidentRepository
+	redis        redis.UniversalClient
+	local        *localstore.Store // Dedupe keys without Redis, on a single node
+	client       *http.Client
+
+	senders map[models.ChannelType]Sender
+	mu      sync.RWMutex
+}
+
+// NewDispatcher creates a new notification dispatcher. Channels are called within the
+// service-wide egress rules. Without a Redis client, as on a single node, dedupe windows are
+// kept in process memory.
+func NewDispatcher(
+	cfg config.NotificationConfig,
+	egressCfg config.EgressConfig,
+	repo *repository.NotificationRepository,
+	incidentRepo *repository.IncidentRepository,
+	redisClient redis.UniversalClient,
+	mailer *mail.Mailer,
+) *Dispatcher {
+	client := egress.NewClient(egressCfg, time.Duration(cfg.TimeoutSeconds)*time.Second)
+
+	d := &Dispatcher{
+		config:       cfg,
+		repo:         repo,
+		incidentRepo: incidentRepo,
+		redis:        redisClient,
+		client:       client,
+		senders:      make(map[models.ChannelType]Sender),
+	}
+	if redisClient == nil {
+		d.local = localstore.New()
+	}
+
+	d.RegisterSender(models.ChannelTypeWebhook, NewWebhookSender(client))
+	d.RegisterSender(models.ChannelTypeSlack, NewSlackSender(client))
+	d.RegisterSender(models.ChannelTypeTeams, NewTeamsSender(client))
+	d.RegisterSender(models.ChannelTypePagerDuty, NewPagerDutySender(client))
+	d.RegisterSender(models.ChannelTypeOpsgenie, NewOpsgenieSender(client))
+	d.RegisterSender(models.ChannelTypeEmail, NewEmailSender(mailer))
+
+	return d
+}
+
+// RegisterSender registers the sender used for a channel type
+func (d *Dispatcher) RegisterSender(channelType models.ChannelType, sender Sender) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.senders[channelType] = sender
+}
+
+// sender returns the sender for a channel type
+func (d *Dispatcher) sender(channelType models.ChannelType) (Sender, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	sender, ok := d.senders[channelType]
+	return sender, ok
+}
+
+// ValidateChannel checks that a channel has a known type and valid configuration
+func (d *Dispatcher) ValidateChannel(channel *models.NotificationChannel) error {
+	sender, ok := d.sender(channel.Type)
+	if !ok {
+		return fmt.Errorf("unsupported channel type: %s", channel.Type)
+	}
+	return sender.Validate(channel)
+}
+
+// DefaultPolicy returns the policy used for tenants without one
+func (d *Dispatcher) DefaultPolicy(tenantID uuid.UUID) models.NotificationPolicy {
+	return models.NotificationPolicy{
+		TenantID:             tenantID,
+		DedupeWindowSeconds:  d.config.DedupeWindowSeconds,
+		MinFailures:          d.config.MinFailures,
+		EscalateAfterMinutes: d.config.EscalateAfterMinutes,
+		Timezone:             "UTC",
+	}
+}
+
+// Policy returns a tenant's notification policy, falling back to defaults
+func (d *Dispatcher) Policy(ctx context.Context, tenantID uuid.UUID) models.NotificationPolicy {
+	policy, err := d.repo.FindPolicy(ctx, tenantID)
+	if err != nil {
+		return d.DefaultPolicy(tenantID)
+	}
+	return *policy
+}
+
+// Notify applies the tenant's policy to an event and delivers it if it passes
+func (d *Dispatcher) Notify(ctx context.Context, event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	policy := d.Policy(ctx, event.TenantID)
+
+	if !d.shouldSend(ctx, &policy, event) {
+		return
+	}
+
+	channels, err := d.repo.FindEnabledChannels(ctx, event.TenantID)
+	if err != nil {
+		log.Printf("notification: failed to load channels for tenant %s: %v", event.TenantID, err)
+		return
+	}
+
+	// Escalations go to the dedicated escalation channel when one is configured
+	if event.Kind == EventIncidentEscalated && policy.EscalationChannelID != nil {
+		channels = filterChannels(channels, *policy.EscalationChannelID)
+	}
+
+	// Reports aren't worth paging anyone for
+	if event.IsReport() {
+		channels = withoutPagers(channels)
+	}
+
+	channels = d.routeSubscriptions(ctx, channels, event)
+
+	d.deliver(ctx, channels, event)
+}
+
+// NotifyChannels delivers an alert rule's event straight to the rule's enabled channels.
+// Rules pick their own channels and thresholds, so the tenant policy doesn't apply.
+func (d *Dispatcher) NotifyChannels(ctx context.Context, event Event, channelIDs []uuid.UUID) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	channels, err := d.repo.FindEnabledChannels(ctx, event.TenantID)
+	if err != nil {
+		log.Printf("notification: failed to load channels for tenant %s: %v", event.TenantID, err)
+		return
+	}
+
+	wanted := make(map[uuid.UUID]bool, len(channelIDs))
+	for _, id := range channelIDs {
+		wanted[id] = true
+	}
+	picked := channels[:0:0]
+	for _, channel := range channels {
+		if wanted[channel.ID] {
+			picked = append(picked, channel)
+		}
+	}
+
+	d.deliver(ctx, picked, event)
+}
+
+// shouldSend evaluates min-failure, acknowledgement, quiet hours and dedupe rules
+func (d *Dispatcher) shouldSend(ctx context.Context, policy *models.NotificationPolicy, event Event) bool {
+	// Incidents below the failure threshold are not worth alerting on (nor resolving)
+	if event.Kind != EventIncidentEscalated && !event.IsReport() && event.FailureCount < int64(policy.MinFailures) {
+		return false
+	}
+
+	// Acknowledged incidents don't produce repeat failure alerts
+	if event.IsFailure() && event.IncidentID != nil && d.incidentRepo != nil {
+		if incident, err := d.incidentRepo.FindByTenantAndID(ctx, event.TenantID, *event.IncidentID); err == nil && incident.AcknowledgedAt != nil {
+			return false
+		}
+	}
+
+	// Quiet hours hold back regular alerts; escalations still go out
+	if event.Kind != EventIncidentEscalated && InQuietHours(policy, event.Timestamp) {
+		return false
+	}
+
+	return d.claimDedupe(ctx, policy, event)
+}
+
+// claimDedupe returns false if an equivalent event was sent within the dedupe window
+func (d *Dispatcher) claimDedupe(ctx context.Context, policy *models.NotificationPolicy, event Event) bool {
+	if policy.DedupeWindowSeconds <= 0 {
+		return true
+	}
+
+	key := fmt.Sprintf("notify:dedupe:%s:%s", event.TenantID, event.DedupeKey())
+	window := time.Duration(policy.DedupeWindowSeconds) * time.Second
+	if d.local != nil {
+		return d.local.SetNX(key, strconv.FormatInt(event.Timestamp.Unix(), 10), window)
+	}
+
+	claimed, err := d.redis.SetNX(ctx, key, event.Timestamp.Unix(), window).Result()
+	if err != nil {
+		// Prefer a duplicate alert over a lost one
+		return true
+	}
+	return claimed
+}
+
+// routeSubscriptions drops subscription-only channels with no subscription matching the event
+func (d *Dispatcher) routeSubscriptions(ctx context.Context, channels []models.NotificationChannel, event Event) []models.NotificationChannel {
+	var subscribed []uuid.UUID
+	for _, channel := range channels {
+		if channel.SubscriptionsOnly {
+			subscribed = append(subscribed, channel.ID)
+		}
+	}
+	if len(subscribed) == 0 {
+		return channels
+	}
+
+	subscriptions, err := d.repo.FindSubscriptionsByChannels(ctx, event.TenantID, subscribed)
+	if err != nil {
+		log.Printf("notification: failed to load subscriptions for tenant %s: %v", event.TenantID, err)
+	}
+
+	matched := make(map[uuid.UUID]bool)
+	for i := range subscriptions {
+		if subscriptions[i].Matches(event.JobID, event.JobTags) {
+			matched[subscriptions[i].ChannelID] = true
+		}
+	}
+
+	routed := channels[:0:0]
+	for _, channel := range channels {
+		if !channel.SubscriptionsOnly || matched[channel.ID] {
+			routed = append(routed, channel)
+		}
+	}
+	return routed
+}
+
+// deliver sends an event to each channel, logging failures
+func (d *Dispatcher) deliver(ctx context.Context, channels []models.NotificationChannel, event Event) {
+	for i := range channels {
+		channel := &channels[i]
+
+		sender, ok := d.sender(channel.Type)
+		if !ok {
+			continue
+		}
+
+		if err := sender.Send(ctx, channel, event); err != nil {
+			log.Printf("notification: failed to send %s to channel %s: %v", event.Kind, channel.ID, err)
+		}
+	}
+}
+
+// Escalate escalates open incidents that have gone unacknowledged past the tenant's threshold
+func (d *Dispatcher) Escalate(ctx context.Context) {
+	if d.incidentRepo == nil {
+		return
+	}
+
+	now := time.Now()
+	candidates, err := d.incidentRepo.FindEscalationCandidates(ctx, now, 200)
+	if err != nil {
+		return
+	}
+
+	policies := make(map[uuid.UUID]models.NotificationPolicy)
+	for _, incident := range candidates {
+		policy, ok := policies[incident.TenantID]
+		if !ok {
+			policy = d.Policy(ctx, incident.TenantID)
+			policies[incident.TenantID] = policy
+		}
+
+		if policy.EscalateAfterMinutes <= 0 || incident.FailureCount < int64(policy.MinFailures) {
+			continue
+		}
+
+		if incident.StartedAt.Add(time.Duration(policy.EscalateAfterMinutes) * time.Minute).After(now) {
+			continue
+		}
+
+		escalated, err := d.incidentRepo.MarkEscalated(ctx, incident.ID)
+		if err != nil || !escalated {
+			continue
+		}
+
+		incidentID := incident.ID
+		d.Notify(ctx, Event{
+			Kind:         EventIncidentEscalated,
+			TenantID:     incident.TenantID,
+			JobID:        incident.JobID,
+			IncidentID:   &incidentID,
+			GroupKey:     incident.GroupKey,
+			FailureCount: incident.FailureCount,
+			Title:        fmt.Sprintf("Incident unacknowledged for %d minutes", policy.EscalateAfterMinutes),
+			Message:      fmt.Sprintf("%d consecutive failures for %s", incident.FailureCount, incident.GroupKey),
+			Timestamp:    now,
+		})
+	}
+}
+
+// InQuietHours reports whether t falls inside the policy's quiet hours
+func InQuietHours(policy *models.NotificationPolicy, t time.Time) bool {
+	if policy.QuietHoursStart == "" || policy.QuietHoursEnd == "" {
+		return false
+	}
+
+	start, err := parseClock(policy.QuietHoursStart)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(policy.QuietHoursEnd)
+	if err != nil {
+		return false
+	}
+
+	loc := time.UTC
+	if policy.Timezone != "" {
+		if l, err := time.LoadLocation(policy.Timezone); err == nil {
+			loc = l
+		}
+	}
+
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	// Window wraps past midnight, e.g. 22:00-07:00
+	return minute >= start || minute < end
+}
+
+// ValidateClock checks an HH:MM time of day
+func ValidateClock(value string) error {
+	_, err := parseClock(value)
+	return err
+}
+
+// parseClock parses HH:MM into minutes since midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q (use HH:MM)", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// withoutPagers drops PagerDuty and Opsgenie channels
+func withoutPagers(channels []models.NotificationChannel) []models.NotificationChannel {
+	kept := channels[:0:0]
+	for _, channel := range channels {
+		if channel.Type != models.ChannelTypePagerDuty && channel.Type != models.ChannelTypeOpsgenie {
+			kept = append(kept, channel)
+		}
+	}
+	return kept
+}
+
+// filterChannels returns only the channel with the given ID, or all channels if it is not enabled
+func filterChannels(channels []models.NotificationChannel, id uuid.UUID) []models.NotificationChannel {
+	for _, channel := range channels {
+		if channel.ID == id {
+			return []models.NotificationChannel{channel}
+		}
+	}
+	return channels
+}
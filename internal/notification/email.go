@@ -0,0 +1,95 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/minisource/scheduler/internal/mail"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// emailConfig is the channel config for email channels
+type emailConfig struct {
+	To []string `json:"to"`
+	Cc []string `json:"cc,omitempty"`
+}
+
+// EmailSender emails events to a list of recipients through the configured SMTP server
+type EmailSender struct {
+	mailer *mail.Mailer
+}
+
+// NewEmailSender creates a new email sender
+func NewEmailSender(mailer *mail.Mailer) *EmailSender {
+	return &EmailSender{mailer: mailer}
+}
+
+// Validate checks an email channel configuration
+func (s *EmailSender) Validate(channel *models.NotificationChannel) error {
+	if !s.mailer.Enabled() {
+		return fmt.Errorf("email channels need an SMTP server: set SMTP_HOST")
+	}
+	var cfg emailConfig
+	if err := json.Unmarshal(channel.Config, &cfg); err != nil || len(cfg.To) == 0 {
+		return fmt.Errorf("email channel requires config.to")
+	}
+	if _, err := mail.ParseAddresses(append(cfg.To, cfg.Cc...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Send emails an event to the channel's recipients
+func (s *EmailSender) Send(ctx context.Context, channel *models.NotificationChannel, event Event) error {
+	var cfg emailConfig
+	if err := json.Unmarshal(channel.Config, &cfg); err != nil {
+		return fmt.Errorf("invalid email config: %w", err)
+	}
+
+	subject := "[scheduler] " + event.Title
+	if event.Kind == EventIncidentEscalated {
+		subject = "[scheduler] ESCALATED: " + event.Title
+	}
+
+	return s.mailer.Send(ctx, &mail.Message{
+		To:      cfg.To,
+		Cc:      cfg.Cc,
+		Subject: subject,
+		Text:    emailText(event),
+	})
+}
+
+// emailText renders an event as a plain text email body
+func emailText(event Event) string {
+	var b strings.Builder
+	b.WriteString(event.Title + "\n")
+	if event.Message != "" {
+		b.WriteString("\n" + event.Message + "\n")
+	}
+	b.WriteString("\n")
+
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%-14s %s\n", name+":", value)
+		}
+	}
+	field("Event", string(event.Kind))
+	field("Job", event.JobName)
+	if event.JobID != nil {
+		field("Job ID", event.JobID.String())
+	}
+	if event.IncidentID != nil {
+		field("Incident ID", event.IncidentID.String())
+	}
+	if event.ExecutionID != nil {
+		field("Execution ID", event.ExecutionID.String())
+	}
+	if event.FailureCount > 0 {
+		field("Failures", fmt.Sprintf("%d", event.FailureCount))
+	}
+	field("Time", event.Timestamp.UTC().Format(time.RFC3339))
+	return b.String()
+}
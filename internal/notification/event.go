@@ -0,0 +1,68 @@
+package notification
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// EventKind identifies what a notification is about
+type EventKind string
+
+const (
+	EventIncidentOpened    EventKind = "incident.opened"
+	EventIncidentUpdated   EventKind = "incident.updated"
+	EventIncidentResolved  EventKind = "incident.resolved"
+	EventIncidentEscalated EventKind = "incident.escalated"
+	EventAlertFiring       EventKind = "alert.firing"
+	EventAlertResolved     EventKind = "alert.resolved"
+	EventJanitorReport     EventKind = "janitor.report" // Periodic list of jobs that look dead
+)
+
+// Event is a notification produced by the scheduler
+type Event struct {
+	Kind         EventKind         `json:"kind"`
+	TenantID     uuid.UUID         `json:"tenant_id"`
+	JobID        *uuid.UUID        `json:"job_id,omitempty"`
+	JobName      string            `json:"job_name,omitempty"`
+	JobTags      []string          `json:"job_tags,omitempty"`
+	IncidentID   *uuid.UUID        `json:"incident_id,omitempty"`
+	RuleID       *uuid.UUID        `json:"rule_id,omitempty"` // Alert rule, for alert events
+	ExecutionID  *uuid.UUID        `json:"execution_id,omitempty"`
+	GroupKey     string            `json:"group_key,omitempty"`
+	FailureCount int64             `json:"failure_count,omitempty"`
+	Title        string            `json:"title"`
+	Message      string            `json:"message,omitempty"`
+	StaleJobs    []models.StaleJob `json:"stale_jobs,omitempty"` // Jobs listed by a janitor report
+	Timestamp    time.Time         `json:"timestamp"`
+}
+
+// DedupeKey identifies notifications that should be collapsed within the dedupe window
+func (e Event) DedupeKey() string {
+	key := e.GroupKey
+	if key == "" && e.IncidentID != nil {
+		key = e.IncidentID.String()
+	}
+	// Opened and updated alerts for the same failure collapse together
+	if e.IsFailure() {
+		return "failure:" + key
+	}
+	return string(e.Kind) + ":" + key
+}
+
+// IsResolved reports whether the event clears an earlier incident or alert
+func (e Event) IsResolved() bool {
+	return e.Kind == EventIncidentResolved || e.Kind == EventAlertResolved
+}
+
+// IsReport reports whether the event is a periodic report rather than an alert, so it is
+// never held back by the failure threshold or sent to paging channels
+func (e Event) IsReport() bool {
+	return e.Kind == EventJanitorReport
+}
+
+// IsFailure reports whether the event reports an ongoing failure
+func (e Event) IsFailure() bool {
+	return e.Kind == EventIncidentOpened || e.Kind == EventIncidentUpdated
+}
@@ -0,0 +1,111 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// Opsgenie API endpoints per region
+var opsgenieAPIURLs = map[string]string{
+	"":   "https://api.opsgenie.com",
+	"us": "https://api.opsgenie.com",
+	"eu": "https://api.eu.opsgenie.com",
+}
+
+// opsgenieConfig is the channel config for Opsgenie channels
+type opsgenieConfig struct {
+	APIKey   string `json:"api_key"`
+	Region   string `json:"region,omitempty"`   // us (default) or eu
+	Priority string `json:"priority,omitempty"` // P1-P5
+}
+
+// OpsgenieSender creates and closes Opsgenie alerts through the Alert API
+type OpsgenieSender struct {
+	client *http.Client
+}
+
+// NewOpsgenieSender creates a new Opsgenie sender
+func NewOpsgenieSender(client *http.Client) *OpsgenieSender {
+	return &OpsgenieSender{client: client}
+}
+
+// Validate checks an Opsgenie channel configuration
+func (s *OpsgenieSender) Validate(channel *models.NotificationChannel) error {
+	var cfg opsgenieConfig
+	if err := json.Unmarshal(channel.Config, &cfg); err != nil || cfg.APIKey == "" {
+		return fmt.Errorf("opsgenie channel requires config.api_key")
+	}
+	if _, ok := opsgenieAPIURLs[cfg.Region]; !ok {
+		return fmt.Errorf("invalid opsgenie region: %s", cfg.Region)
+	}
+	switch cfg.Priority {
+	case "", "P1", "P2", "P3", "P4", "P5":
+		return nil
+	}
+	return fmt.Errorf("invalid opsgenie priority: %s", cfg.Priority)
+}
+
+// Send creates or closes the Opsgenie alert for the event's group
+func (s *OpsgenieSender) Send(ctx context.Context, channel *models.NotificationChannel, event Event) error {
+	var cfg opsgenieConfig
+	if err := json.Unmarshal(channel.Config, &cfg); err != nil {
+		return fmt.Errorf("invalid opsgenie config: %w", err)
+	}
+
+	baseURL := opsgenieAPIURLs[cfg.Region]
+	headers := map[string]string{"Authorization": "GenieKey " + cfg.APIKey}
+	alias := externalAlertKey(event)
+
+	if event.IsResolved() {
+		body, err := json.Marshal(map[string]string{
+			"source": "minisource-scheduler",
+			"note":   event.Title,
+		})
+		if err != nil {
+			return err
+		}
+		closeURL := fmt.Sprintf("%s/v2/alerts/%s/close?identifierType=alias", baseURL, url.PathEscape(alias))
+		return postJSON(ctx, s.client, closeURL, body, headers)
+	}
+
+	priority := cfg.Priority
+	if priority == "" {
+		priority = "P3"
+	}
+	if event.Kind == EventIncidentEscalated {
+		priority = "P1"
+	}
+
+	// Opsgenie deduplicates open alerts with the same alias
+	body, err := json.Marshal(map[string]interface{}{
+		"message":     truncate(event.Title, 130),
+		"alias":       alias,
+		"description": event.Message,
+		"source":      "minisource-scheduler",
+		"priority":    priority,
+		"tags":        []string{"scheduler", string(event.Kind)},
+		"details": map[string]string{
+			"tenant_id": event.TenantID.String(),
+			"group_key": event.GroupKey,
+			"job_name":  event.JobName,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return postJSON(ctx, s.client, baseURL+"/v2/alerts", body, headers)
+}
+
+// truncate shortens s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
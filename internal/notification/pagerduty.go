@@ -0,0 +1,91 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// pagerDutyConfig is the channel config for PagerDuty channels
+type pagerDutyConfig struct {
+	RoutingKey string `json:"routing_key"`
+	Severity   string `json:"severity,omitempty"` // critical, error, warning or info
+}
+
+// PagerDutySender opens and resolves PagerDuty incidents through the Events API
+type PagerDutySender struct {
+	client *http.Client
+}
+
+// NewPagerDutySender creates a new PagerDuty sender
+func NewPagerDutySender(client *http.Client) *PagerDutySender {
+	return &PagerDutySender{client: client}
+}
+
+// Validate checks a PagerDuty channel configuration
+func (s *PagerDutySender) Validate(channel *models.NotificationChannel) error {
+	var cfg pagerDutyConfig
+	if err := json.Unmarshal(channel.Config, &cfg); err != nil || cfg.RoutingKey == "" {
+		return fmt.Errorf("pagerduty channel requires config.routing_key")
+	}
+	switch cfg.Severity {
+	case "", "critical", "error", "warning", "info":
+		return nil
+	}
+	return fmt.Errorf("invalid pagerduty severity: %s", cfg.Severity)
+}
+
+// Send triggers or resolves the PagerDuty incident for the event's group
+func (s *PagerDutySender) Send(ctx context.Context, channel *models.NotificationChannel, event Event) error {
+	var cfg pagerDutyConfig
+	if err := json.Unmarshal(channel.Config, &cfg); err != nil {
+		return fmt.Errorf("invalid pagerduty config: %w", err)
+	}
+
+	severity := cfg.Severity
+	if severity == "" {
+		severity = "error"
+	}
+	if event.Kind == EventIncidentEscalated {
+		severity = "critical"
+	}
+
+	action := "trigger"
+	if event.IsResolved() {
+		action = "resolve"
+	}
+
+	payload := map[string]interface{}{
+		"routing_key":  cfg.RoutingKey,
+		"event_action": action,
+		"dedup_key":    externalAlertKey(event),
+	}
+	if action == "trigger" {
+		payload["payload"] = map[string]interface{}{
+			"summary":        event.Title,
+			"source":         "minisource-scheduler",
+			"severity":       severity,
+			"component":      event.JobName,
+			"group":          event.GroupKey,
+			"custom_details": event,
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return postJSON(ctx, s.client, pagerDutyEventsURL, body, nil)
+}
+
+// externalAlertKey keys alerts in external tools so repeats update, and resolves close, the same alert
+func externalAlertKey(event Event) string {
+	return fmt.Sprintf("scheduler:%s:%s", event.TenantID, event.GroupKey)
+}
@@ -0,0 +1,186 @@
+package notification
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// Slack interaction action IDs
+const (
+	SlackActionRetry = "retry_job"
+	SlackActionPause = "pause_job"
+	SlackActionAck   = "ack_incident"
+)
+
+// slackMaxSkew is how old a signed Slack request may be before it is rejected
+const slackMaxSkew = 5 * time.Minute
+
+// ErrInvalidSlackSignature is returned when a Slack callback fails verification
+var ErrInvalidSlackSignature = errors.New("invalid slack signature")
+
+// slackConfig is the channel config for Slack channels
+type slackConfig struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
+// SlackActionValue is the context carried by each action button
+type SlackActionValue struct {
+	TenantID    uuid.UUID  `json:"tenant_id"`
+	ChannelID   uuid.UUID  `json:"channel_id"`
+	JobID       *uuid.UUID `json:"job_id,omitempty"`
+	IncidentID  *uuid.UUID `json:"incident_id,omitempty"`
+	ExecutionID *uuid.UUID `json:"execution_id,omitempty"`
+}
+
+// SlackInteraction is the subset of a Slack block_actions payload the scheduler uses
+type SlackInteraction struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	ResponseURL string `json:"response_url"`
+	Actions     []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// SlackSender posts events to a Slack incoming webhook with interactive buttons
+type SlackSender struct {
+	client *http.Client
+}
+
+// NewSlackSender creates a new Slack sender
+func NewSlackSender(client *http.Client) *SlackSender {
+	return &SlackSender{client: client}
+}
+
+// Validate checks a Slack channel configuration
+func (s *SlackSender) Validate(channel *models.NotificationChannel) error {
+	var cfg slackConfig
+	if err := json.Unmarshal(channel.Config, &cfg); err != nil || cfg.WebhookURL == "" {
+		return fmt.Errorf("slack channel requires config.webhook_url")
+	}
+	return nil
+}
+
+// Send delivers an event to the Slack webhook
+func (s *SlackSender) Send(ctx context.Context, channel *models.NotificationChannel, event Event) error {
+	var cfg slackConfig
+	if err := json.Unmarshal(channel.Config, &cfg); err != nil {
+		return fmt.Errorf("invalid slack config: %w", err)
+	}
+
+	body, err := json.Marshal(slackMessage(channel, event))
+	if err != nil {
+		return err
+	}
+
+	return postJSON(ctx, s.client, cfg.WebhookURL, body, nil)
+}
+
+// slackMessage builds a Block Kit message, adding action buttons for open incidents
+func slackMessage(channel *models.NotificationChannel, event Event) map[string]interface{} {
+	text := event.Title
+	if event.Message != "" {
+		text += "\n" + event.Message
+	}
+
+	blocks := []map[string]interface{}{
+		{
+			"type": "section",
+			"text": map[string]string{"type": "mrkdwn", "text": text},
+		},
+	}
+
+	if !event.IsResolved() && event.JobID != nil {
+		value, _ := json.Marshal(SlackActionValue{
+			TenantID:    event.TenantID,
+			ChannelID:   channel.ID,
+			JobID:       event.JobID,
+			IncidentID:  event.IncidentID,
+			ExecutionID: event.ExecutionID,
+		})
+
+		elements := []map[string]interface{}{
+			slackButton("Retry now", SlackActionRetry, string(value), "primary"),
+			slackButton("Pause job", SlackActionPause, string(value), "danger"),
+		}
+		// Alerts for jobs that haven't run have nothing to acknowledge
+		if event.IncidentID != nil || event.ExecutionID != nil {
+			elements = append(elements, slackButton("Ack", SlackActionAck, string(value), ""))
+		}
+		blocks = append(blocks, map[string]interface{}{
+			"type":     "actions",
+			"elements": elements,
+		})
+	}
+
+	return map[string]interface{}{
+		"text":   event.Title,
+		"blocks": blocks,
+	}
+}
+
+// slackButton builds a Block Kit button element
+func slackButton(label, actionID, value, style string) map[string]interface{} {
+	button := map[string]interface{}{
+		"type":      "button",
+		"text":      map[string]string{"type": "plain_text", "text": label},
+		"action_id": actionID,
+		"value":     value,
+	}
+	if style != "" {
+		button["style"] = style
+	}
+	return button
+}
+
+// VerifySlackSignature checks the X-Slack-Signature of an interaction callback
+func VerifySlackSignature(secret, timestamp, signature string, body []byte, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSlackSignature
+	}
+
+	// Reject stale requests to prevent replay
+	skew := now.Sub(time.Unix(ts, 0))
+	if skew > slackMaxSkew || skew < -slackMaxSkew {
+		return ErrInvalidSlackSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSlackSignature
+	}
+	return nil
+}
+
+// RespondSlack posts a follow-up message to an interaction's response_url
+func RespondSlack(ctx context.Context, client *http.Client, responseURL, text string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"response_type":    "in_channel",
+		"replace_original": false,
+		"text":             text,
+	})
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, client, responseURL, body, nil)
+}
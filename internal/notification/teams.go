@@ -0,0 +1,108 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// teamsConfig is the channel config for Microsoft Teams channels
+type teamsConfig struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
+// TeamsSender posts events to a Microsoft Teams incoming webhook or Workflows webhook as an
+// Adaptive Card
+type TeamsSender struct {
+	client *http.Client
+}
+
+// NewTeamsSender creates a new Teams sender
+func NewTeamsSender(client *http.Client) *TeamsSender {
+	return &TeamsSender{client: client}
+}
+
+// Validate checks a Teams channel configuration
+func (s *TeamsSender) Validate(channel *models.NotificationChannel) error {
+	var cfg teamsConfig
+	if err := json.Unmarshal(channel.Config, &cfg); err != nil || cfg.WebhookURL == "" {
+		return fmt.Errorf("teams channel requires config.webhook_url")
+	}
+	return nil
+}
+
+// Send delivers an event to the Teams webhook
+func (s *TeamsSender) Send(ctx context.Context, channel *models.NotificationChannel, event Event) error {
+	var cfg teamsConfig
+	if err := json.Unmarshal(channel.Config, &cfg); err != nil {
+		return fmt.Errorf("invalid teams config: %w", err)
+	}
+
+	body, err := json.Marshal(teamsMessage(event))
+	if err != nil {
+		return err
+	}
+
+	return postJSON(ctx, s.client, cfg.WebhookURL, body, nil)
+}
+
+// teamsMessage wraps an Adaptive Card describing the event in a Teams message
+func teamsMessage(event Event) map[string]interface{} {
+	color := "attention"
+	if event.IsResolved() {
+		color = "good"
+	}
+
+	items := []map[string]interface{}{
+		{
+			"type":   "TextBlock",
+			"text":   event.Title,
+			"weight": "bolder",
+			"size":   "medium",
+			"color":  color,
+			"wrap":   true,
+		},
+	}
+	if event.Message != "" {
+		items = append(items, map[string]interface{}{
+			"type": "TextBlock",
+			"text": event.Message,
+			"wrap": true,
+		})
+	}
+
+	var facts []map[string]string
+	if event.JobName != "" {
+		facts = append(facts, map[string]string{"title": "Job", "value": event.JobName})
+	}
+	if event.FailureCount > 0 {
+		facts = append(facts, map[string]string{"title": "Failures", "value": strconv.FormatInt(event.FailureCount, 10)})
+	}
+	if event.GroupKey != "" {
+		facts = append(facts, map[string]string{"title": "Group", "value": event.GroupKey})
+	}
+	facts = append(facts, map[string]string{"title": "Time", "value": event.Timestamp.UTC().Format("2006-01-02 15:04:05 MST")})
+	items = append(items, map[string]interface{}{
+		"type":  "FactSet",
+		"facts": facts,
+	})
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    items,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// webhookConfig is the channel config for generic webhooks
+type webhookConfig struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// WebhookSender posts events as JSON to an arbitrary URL
+type WebhookSender struct {
+	client *http.Client
+}
+
+// NewWebhookSender creates a new webhook sender
+func NewWebhookSender(client *http.Client) *WebhookSender {
+	return &WebhookSender{client: client}
+}
+
+// Validate checks a webhook channel configuration
+func (s *WebhookSender) Validate(channel *models.NotificationChannel) error {
+	var cfg webhookConfig
+	if err := json.Unmarshal(channel.Config, &cfg); err != nil || cfg.URL == "" {
+		return fmt.Errorf("webhook channel requires config.url")
+	}
+	return nil
+}
+
+// Send delivers an event to the webhook URL
+func (s *WebhookSender) Send(ctx context.Context, channel *models.NotificationChannel, event Event) error {
+	var cfg webhookConfig
+	if err := json.Unmarshal(channel.Config, &cfg); err != nil {
+		return fmt.Errorf("invalid webhook config: %w", err)
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return postJSON(ctx, s.client, cfg.URL, body, cfg.Headers)
+}
+
+// postJSON posts a JSON body and treats non-2xx responses as errors
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Minisource-Scheduler/1.0")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notification endpoint returned HTTP %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+// Package preflight checks configuration and dependencies at startup, so problems are
+// reported with a fix instead of surfacing deep inside the first scheduler tick.
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// checkTimeout bounds each dependency check
+const checkTimeout = 5 * time.Second
+
+// Config validates the loaded configuration. loadErr is the error returned by config.Load
+// for values that could not be parsed.
+func Config(cfg *config.Config, loadErr error) error {
+	var problems []error
+	if loadErr != nil {
+		problems = append(problems, loadErr)
+	}
+	if err := cfg.Validate(); err != nil {
+		problems = append(problems, err)
+	}
+	return report("configuration", errors.Join(problems...))
+}
+
+// Dependencies checks the database schema and Redis features the scheduler relies on. With
+// LOCKER_BACKEND=postgres, Redis problems are reported as warnings since locks don't need it.
+// Redlock nodes only need a majority reachable, like the locks taken on them. A single node
+// runs without Redis, passing a nil client, and only has its database checked.
+func Dependencies(ctx context.Context, cfg *config.Config, db *gorm.DB, redisClient redis.UniversalClient, lockNodes []redis.UniversalClient) error {
+	var problems []error
+	if err := checkSchema(db); err != nil {
+		problems = append(problems, err)
+	}
+	if cfg.Postgres.TenantIsolation == database.TenantIsolationRLS {
+		if err := checkRLSRole(db); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	if redisClient == nil {
+		return report("dependencies", errors.Join(problems...))
+	}
+	if err := checkRedis(ctx, redisClient, database.RedisAddr(&cfg.Redis)); err != nil {
+		if cfg.Locker.Backend == "postgres" {
+			log.Printf("preflight: warning: %v; continuing with LOCKER_BACKEND=postgres, but rate limits, sessions and notification dedupe need Redis", err)
+		} else {
+			problems = append(problems, err)
+		}
+	}
+	if cfg.Locker.Backend == "redis" && len(lockNodes) > 0 {
+		if err := checkRedlock(ctx, lockNodes, database.SplitAddrs(cfg.Redis.LockNodes)); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	return report("dependencies", errors.Join(problems...))
+}
+
+// checkSchema compares the migrated schema version with the one the code expects
+func checkSchema(db *gorm.DB) error {
+	version, dirty, found, err := database.SchemaStatus(db)
+	switch {
+	case err != nil:
+		return fmt.Errorf("failed to read schema version: %w", err)
+	case !found:
+		log.Printf("preflight: no schema_migrations table; relying on AutoMigrate, run `make migrate-up` for constraints and indexes")
+		return nil
+	case dirty:
+		return fmt.Errorf("schema migration %d is dirty (a migration failed halfway); fix the schema, then run `migrate force %d`", version, version)
+	case version < database.SchemaVersion:
+		return fmt.Errorf("schema is at version %d but this build needs %d; run `make migrate-up`", version, database.SchemaVersion)
+	case version > database.SchemaVersion:
+		log.Printf("preflight: schema version %d is newer than this build's %d (rolling deploy?)", version, database.SchemaVersion)
+	}
+	return nil
+}
+
+// checkRLSRole verifies row-level security applies to the database role
+func checkRLSRole(db *gorm.DB) error {
+	bypass, err := database.RoleBypassesRLS(db)
+	switch {
+	case err != nil:
+		return fmt.Errorf("failed to read database role attributes: %w", err)
+	case bypass:
+		return fmt.Errorf("POSTGRES_TENANT_ISOLATION=rls but the database role is a superuser or has BYPASSRLS, which ignores row-level security; use a plain role")
+	}
+	return nil
+}
+
+// checkRedis verifies Redis is reachable and allows Lua scripts, which locks and rate limits use
+func checkRedis(ctx context.Context, redisClient redis.UniversalClient, addr string) error {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	if err := redisClient.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis at %s is unreachable: %w; check REDIS_MODE, REDIS_HOST, REDIS_PORT, REDIS_ADDRS and REDIS_PASSWORD", addr, err)
+	}
+
+	result, err := redisClient.Eval(ctx, "return ARGV[1]", nil, "ok").Text()
+	if err != nil || result != "ok" {
+		if err == nil {
+			err = fmt.Errorf("unexpected result %q", result)
+		}
+		return fmt.Errorf("redis does not run Lua scripts (EVAL): %w; distributed locks and rate limits need scripting enabled", err)
+	}
+	return nil
+}
+
+// checkRedlock verifies a majority of the Redlock nodes are reachable and run Lua scripts,
+// warning about the others
+func checkRedlock(ctx context.Context, clients []redis.UniversalClient, addrs []string) error {
+	healthy := 0
+	for i, client := range clients {
+		if err := checkRedis(ctx, client, addrs[i]); err != nil {
+			log.Printf("preflight: warning: Redlock node %v", err)
+			continue
+		}
+		healthy++
+	}
+	if quorum := len(clients)/2 + 1; healthy < quorum {
+		return fmt.Errorf("only %d of %d REDIS_LOCK_NODES are usable but locks need %d; check the nodes in REDIS_LOCK_NODES", healthy, len(clients), quorum)
+	}
+	return nil
+}
+
+// report logs each problem on its own line and returns a summary error
+func report(phase string, err error) error {
+	if err == nil {
+		log.Printf("preflight: %s ok", phase)
+		return nil
+	}
+
+	lines := strings.Split(err.Error(), "\n")
+	for _, line := range lines {
+		log.Printf("preflight: %s", line)
+	}
+	return fmt.Errorf("%d %s problem(s) found", len(lines), phase)
+}
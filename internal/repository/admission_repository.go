@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// AdmissionRepository handles admission webhook persistence
+type AdmissionRepository struct {
+	db *gorm.DB
+}
+
+// NewAdmissionRepository creates a new admission repository
+func NewAdmissionRepository(db *gorm.DB) *AdmissionRepository {
+	return &AdmissionRepository{db: db}
+}
+
+// Create creates an admission webhook
+func (r *AdmissionRepository) Create(ctx context.Context, webhook *models.AdmissionWebhook) error {
+	return database.Conn(ctx, r.db).Create(webhook).Error
+}
+
+// Update updates an admission webhook
+func (r *AdmissionRepository) Update(ctx context.Context, webhook *models.AdmissionWebhook) error {
+	return database.Conn(ctx, r.db).Save(webhook).Error
+}
+
+// Delete deletes a tenant's admission webhook
+func (r *AdmissionRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) (int64, error) {
+	result := database.Conn(ctx, r.db).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		Delete(&models.AdmissionWebhook{})
+	return result.RowsAffected, result.Error
+}
+
+// FindByTenantAndID retrieves an admission webhook by tenant and ID
+func (r *AdmissionRepository) FindByTenantAndID(ctx context.Context, tenantID, id uuid.UUID) (*models.AdmissionWebhook, error) {
+	var webhook models.AdmissionWebhook
+	err := database.Conn(ctx, r.db).First(&webhook, "id = ? AND tenant_id = ?", id, tenantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// FindByTenant retrieves a tenant's admission webhooks in the order they are called
+func (r *AdmissionRepository) FindByTenant(ctx context.Context, tenantID uuid.UUID) ([]models.AdmissionWebhook, error) {
+	var webhooks []models.AdmissionWebhook
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		Order("position ASC, created_at ASC").
+		Find(&webhooks).Error
+	return webhooks, err
+}
+
+// FindEnabled retrieves a tenant's enabled admission webhooks in the order they are called
+func (r *AdmissionRepository) FindEnabled(ctx context.Context, tenantID uuid.UUID) ([]models.AdmissionWebhook, error) {
+	var webhooks []models.AdmissionWebhook
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND enabled = ?", tenantID, true).
+		Order("position ASC, created_at ASC").
+		Find(&webhooks).Error
+	return webhooks, err
+}
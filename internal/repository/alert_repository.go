@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AlertRepository handles alert rule and firing persistence
+type AlertRepository struct {
+	db *gorm.DB
+}
+
+// NewAlertRepository creates a new alert repository
+func NewAlertRepository(db *gorm.DB) *AlertRepository {
+	return &AlertRepository{db: db}
+}
+
+// CreateRule creates an alert rule
+func (r *AlertRepository) CreateRule(ctx context.Context, rule *models.AlertRule) error {
+	return database.Conn(ctx, r.db).Create(rule).Error
+}
+
+// UpdateRule updates an alert rule
+func (r *AlertRepository) UpdateRule(ctx context.Context, rule *models.AlertRule) error {
+	return database.Conn(ctx, r.db).Save(rule).Error
+}
+
+// DeleteRule deletes a tenant's alert rule; its firings go with it
+func (r *AlertRepository) DeleteRule(ctx context.Context, tenantID, id uuid.UUID) (int64, error) {
+	result := database.Conn(ctx, r.db).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		Delete(&models.AlertRule{})
+	return result.RowsAffected, result.Error
+}
+
+// FindRuleByTenantAndID retrieves an alert rule by tenant and ID
+func (r *AlertRepository) FindRuleByTenantAndID(ctx context.Context, tenantID, id uuid.UUID) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	err := database.Conn(ctx, r.db).First(&rule, "id = ? AND tenant_id = ?", id, tenantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindRulesByTenant retrieves all alert rules for a tenant
+func (r *AlertRepository) FindRulesByTenant(ctx context.Context, tenantID uuid.UUID) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		Order("created_at ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+// FindEnabledRules retrieves a tenant's enabled alert rules of a type
+func (r *AlertRepository) FindEnabledRules(ctx context.Context, tenantID uuid.UUID, ruleType models.AlertRuleType) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND type = ? AND enabled = ?", tenantID, ruleType, true).
+		Find(&rules).Error
+	return rules, err
+}
+
+// FindEnabledRulesByType retrieves every tenant's enabled alert rules of a type
+func (r *AlertRepository) FindEnabledRulesByType(ctx context.Context, ruleType models.AlertRuleType) ([]models.AlertRule, error) {
+	var rules []models.AlertRule
+	err := database.Conn(ctx, r.db).
+		Where("type = ? AND enabled = ?", ruleType, true).
+		Find(&rules).Error
+	return rules, err
+}
+
+// FindFiringsByTenant retrieves a tenant's firing alerts, oldest first
+func (r *AlertRepository) FindFiringsByTenant(ctx context.Context, tenantID uuid.UUID) ([]models.AlertFiring, error) {
+	var firings []models.AlertFiring
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		Order("fired_at ASC").
+		Find(&firings).Error
+	return firings, err
+}
+
+// FindFiringsByRule retrieves the jobs a rule is firing for
+func (r *AlertRepository) FindFiringsByRule(ctx context.Context, ruleID uuid.UUID) ([]models.AlertFiring, error) {
+	var firings []models.AlertFiring
+	err := database.Conn(ctx, r.db).
+		Where("rule_id = ?", ruleID).
+		Find(&firings).Error
+	return firings, err
+}
+
+// FindFiringsByJob retrieves the rules firing for a job
+func (r *AlertRepository) FindFiringsByJob(ctx context.Context, jobID uuid.UUID) ([]models.AlertFiring, error) {
+	var firings []models.AlertFiring
+	err := database.Conn(ctx, r.db).
+		Where("job_id = ?", jobID).
+		Find(&firings).Error
+	return firings, err
+}
+
+// Fire records a rule firing for a job. It returns false when the rule was already firing
+// for the job, so each firing is announced once.
+func (r *AlertRepository) Fire(ctx context.Context, firing *models.AlertFiring) (bool, error) {
+	result := database.Conn(ctx, r.db).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(firing)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Resolve clears a rule's firing for a job. It returns false when it wasn't firing.
+func (r *AlertRepository) Resolve(ctx context.Context, ruleID, jobID uuid.UUID) (bool, error) {
+	result := database.Conn(ctx, r.db).
+		Where("rule_id = ? AND job_id = ?", ruleID, jobID).
+		Delete(&models.AlertFiring{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ResolveRule clears all of a rule's firings
+func (r *AlertRepository) ResolveRule(ctx context.Context, ruleID uuid.UUID) error {
+	return database.Conn(ctx, r.db).
+		Where("rule_id = ?", ruleID).
+		Delete(&models.AlertFiring{}).Error
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// AuditRepository handles audit log persistence
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create appends an audit log entry
+func (r *AuditRepository) Create(ctx context.Context, entry *models.AuditLog) error {
+	return database.Conn(ctx, r.db).Create(entry).Error
+}
+
+// Query retrieves audit log entries with filtering and pagination, newest first
+func (r *AuditRepository) Query(ctx context.Context, filter models.AuditFilter) (*models.AuditListResult, error) {
+	var entries []models.AuditLog
+	var total int64
+
+	query := database.Conn(ctx, r.db).Model(&models.AuditLog{})
+
+	if filter.TenantID != nil {
+		query = query.Where("tenant_id = ?", filter.TenantID)
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	// Get total count
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	// Apply pagination
+	page := filter.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := filter.PageSize
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuditListResult{
+		Entries:    entries,
+		TotalCount: total,
+		Page:       page,
+		PageSize:   pageSize,
+		HasMore:    int64(page*pageSize) < total,
+	}, nil
+}
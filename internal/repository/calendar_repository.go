@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// CalendarRepository handles calendar persistence
+type CalendarRepository struct {
+	db *gorm.DB
+}
+
+// NewCalendarRepository creates a new calendar repository
+func NewCalendarRepository(db *gorm.DB) *CalendarRepository {
+	return &CalendarRepository{db: db}
+}
+
+// Create creates a calendar
+func (r *CalendarRepository) Create(ctx context.Context, calendar *models.Calendar) error {
+	return database.Conn(ctx, r.db).Create(calendar).Error
+}
+
+// Update saves a calendar
+func (r *CalendarRepository) Update(ctx context.Context, calendar *models.Calendar) error {
+	return database.Conn(ctx, r.db).Save(calendar).Error
+}
+
+// Delete deletes a tenant's calendar
+func (r *CalendarRepository) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	return database.Conn(ctx, r.db).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		Delete(&models.Calendar{}).Error
+}
+
+// FindByTenantAndID retrieves a calendar by tenant and ID
+func (r *CalendarRepository) FindByTenantAndID(ctx context.Context, tenantID, id uuid.UUID) (*models.Calendar, error) {
+	var calendar models.Calendar
+	err := database.Conn(ctx, r.db).First(&calendar, "id = ? AND tenant_id = ?", id, tenantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &calendar, nil
+}
+
+// FindByTenantAndName retrieves a calendar by tenant and name
+func (r *CalendarRepository) FindByTenantAndName(ctx context.Context, tenantID uuid.UUID, name string) (*models.Calendar, error) {
+	var calendar models.Calendar
+	err := database.Conn(ctx, r.db).First(&calendar, "tenant_id = ? AND name = ?", tenantID, name).Error
+	if err != nil {
+		return nil, err
+	}
+	return &calendar, nil
+}
+
+// FindByTenant retrieves a tenant's calendars by name
+func (r *CalendarRepository) FindByTenant(ctx context.Context, tenantID uuid.UUID) ([]models.Calendar, error) {
+	var calendars []models.Calendar
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		Order("name ASC").
+		Find(&calendars).Error
+	return calendars, err
+}
+
+// FindByTenants retrieves the calendars of a kind belonging to any of the tenants
+func (r *CalendarRepository) FindByTenants(ctx context.Context, tenantIDs []uuid.UUID, kind models.CalendarKind) ([]models.Calendar, error) {
+	var calendars []models.Calendar
+	if len(tenantIDs) == 0 {
+		return calendars, nil
+	}
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id IN ? AND kind = ?", tenantIDs, kind).
+		Find(&calendars).Error
+	return calendars, err
+}
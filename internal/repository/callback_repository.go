@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CallbackRepository handles callback route and delivery persistence
+type CallbackRepository struct {
+	db *gorm.DB
+}
+
+// NewCallbackRepository creates a new callback repository
+func NewCallbackRepository(db *gorm.DB) *CallbackRepository {
+	return &CallbackRepository{db: db}
+}
+
+// CreateRoute creates a callback route
+func (r *CallbackRepository) CreateRoute(ctx context.Context, route *models.CallbackRoute) error {
+	return database.Conn(ctx, r.db).Create(route).Error
+}
+
+// UpdateRoute updates a callback route
+func (r *CallbackRepository) UpdateRoute(ctx context.Context, route *models.CallbackRoute) error {
+	return database.Conn(ctx, r.db).Save(route).Error
+}
+
+// DeleteRoute deletes a tenant's callback route. Queued deliveries keep their URL and are
+// still delivered.
+func (r *CallbackRepository) DeleteRoute(ctx context.Context, tenantID, id uuid.UUID) (int64, error) {
+	result := database.Conn(ctx, r.db).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		Delete(&models.CallbackRoute{})
+	return result.RowsAffected, result.Error
+}
+
+// FindRouteByTenantAndID retrieves a callback route by tenant and ID
+func (r *CallbackRepository) FindRouteByTenantAndID(ctx context.Context, tenantID, id uuid.UUID) (*models.CallbackRoute, error) {
+	var route models.CallbackRoute
+	err := database.Conn(ctx, r.db).First(&route, "id = ? AND tenant_id = ?", id, tenantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &route, nil
+}
+
+// FindRoutesByTenant retrieves a tenant's callback routes in the order they are matched
+func (r *CallbackRepository) FindRoutesByTenant(ctx context.Context, tenantID uuid.UUID) ([]models.CallbackRoute, error) {
+	var routes []models.CallbackRoute
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		Order("position ASC, created_at ASC").
+		Find(&routes).Error
+	return routes, err
+}
+
+// FindEnabledRoutes retrieves a tenant's enabled callback routes in the order they are matched
+func (r *CallbackRepository) FindEnabledRoutes(ctx context.Context, tenantID uuid.UUID) ([]models.CallbackRoute, error) {
+	var routes []models.CallbackRoute
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND enabled = ?", tenantID, true).
+		Order("position ASC, created_at ASC").
+		Find(&routes).Error
+	return routes, err
+}
+
+// Enqueue queues a callback delivery
+func (r *CallbackRepository) Enqueue(ctx context.Context, delivery *models.CallbackDelivery) error {
+	return database.Conn(ctx, r.db).Create(delivery).Error
+}
+
+// ClaimDue claims pending deliveries whose next attempt is due, oldest first, by pushing
+// their next attempt back by lease. Instances claiming at the same time get different rows,
+// and a delivery whose instance dies mid-attempt is picked up again once the lease ends.
+func (r *CallbackRepository) ClaimDue(ctx context.Context, lease time.Duration, limit int) ([]models.CallbackDelivery, error) {
+	var deliveries []models.CallbackDelivery
+	err := database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.CallbackStatusPending, time.Now()).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+		}
+		return tx.Model(&models.CallbackDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(lease)).Error
+	})
+	return deliveries, err
+}
+
+// MarkDelivered records a successful delivery
+func (r *CallbackRepository) MarkDelivered(ctx context.Context, id uuid.UUID, attempts int) error {
+	now := time.Now()
+	return database.Conn(ctx, r.db).
+		Model(&models.CallbackDelivery{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       models.CallbackStatusDelivered,
+			"attempts":     attempts,
+			"delivered_at": now,
+			"last_error":   "",
+		}).Error
+}
+
+// MarkAttemptFailed records a failed attempt. The delivery is tried again at next, or
+// given up on when next is nil.
+func (r *CallbackRepository) MarkAttemptFailed(ctx context.Context, id uuid.UUID, attempts int, errMsg string, next *time.Time) error {
+	updates := map[string]interface{}{
+		"attempts":   attempts,
+		"last_error": errMsg,
+	}
+	if next != nil {
+		updates["next_attempt_at"] = *next
+	} else {
+		updates["status"] = models.CallbackStatusFailed
+	}
+	return database.Conn(ctx, r.db).
+		Model(&models.CallbackDelivery{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+// Redeliver queues a tenant's failed delivery for another round of attempts
+func (r *CallbackRepository) Redeliver(ctx context.Context, tenantID, id uuid.UUID) (bool, error) {
+	result := database.Conn(ctx, r.db).
+		Model(&models.CallbackDelivery{}).
+		Where("id = ? AND tenant_id = ? AND status = ?", id, tenantID, models.CallbackStatusFailed).
+		Updates(map[string]interface{}{
+			"status":          models.CallbackStatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindDeliveryByTenantAndID retrieves a callback delivery by tenant and ID
+func (r *CallbackRepository) FindDeliveryByTenantAndID(ctx context.Context, tenantID, id uuid.UUID) (*models.CallbackDelivery, error) {
+	var delivery models.CallbackDelivery
+	err := database.Conn(ctx, r.db).First(&delivery, "id = ? AND tenant_id = ?", id, tenantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// FindDeliveries retrieves a tenant's callback deliveries, newest first
+func (r *CallbackRepository) FindDeliveries(ctx context.Context, filter models.CallbackDeliveryFilter) ([]models.CallbackDelivery, error) {
+	query := database.Conn(ctx, r.db).Where("tenant_id = ?", filter.TenantID)
+	if filter.RouteID != nil {
+		query = query.Where("route_id = ?", *filter.RouteID)
+	}
+	if filter.ExecutionID != nil {
+		query = query.Where("execution_id = ?", *filter.ExecutionID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var deliveries []models.CallbackDelivery
+	err := query.Order("created_at DESC").Limit(filter.Limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+// CleanupOld deletes finished deliveries created before a cutoff
+func (r *CallbackRepository) CleanupOld(ctx context.Context, before time.Time) (int64, error) {
+	result := database.Conn(ctx, r.db).
+		Where("created_at < ? AND status <> ?", before, models.CallbackStatusPending).
+		Delete(&models.CallbackDelivery{})
+	return result.RowsAffected, result.Error
+}
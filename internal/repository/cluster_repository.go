@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ClusterRepository handles persistence of cluster state shared by instances
+type ClusterRepository struct {
+	db *gorm.DB
+}
+
+// NewClusterRepository creates a new cluster repository
+func NewClusterRepository(db *gorm.DB) *ClusterRepository {
+	return &ClusterRepository{db: db}
+}
+
+// SaveLeader records the instance that took a lock, replacing the previous holder
+func (r *ClusterRepository) SaveLeader(ctx context.Context, leader *models.ClusterLeader) error {
+	return database.Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "lock_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"instance_id", "hostname", "acquired_at", "heartbeat_at"}),
+	}).Create(leader).Error
+}
+
+// TouchLeader records a heartbeat of the instance holding a lock. It reports false when
+// the lock is recorded for another instance.
+func (r *ClusterRepository) TouchLeader(ctx context.Context, lockKey, instanceID string, at time.Time) (bool, error) {
+	result := database.Conn(ctx, r.db).Model(&models.ClusterLeader{}).
+		Where("lock_key = ? AND instance_id = ?", lockKey, instanceID).
+		Update("heartbeat_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteLeader removes the record of a lock if it is held by the given instance
+func (r *ClusterRepository) DeleteLeader(ctx context.Context, lockKey, instanceID string) error {
+	return database.Conn(ctx, r.db).
+		Where("lock_key = ? AND instance_id = ?", lockKey, instanceID).
+		Delete(&models.ClusterLeader{}).Error
+}
+
+// FindLeader retrieves the recorded holder of a lock
+func (r *ClusterRepository) FindLeader(ctx context.Context, lockKey string) (*models.ClusterLeader, error) {
+	var leader models.ClusterLeader
+	err := database.Conn(ctx, r.db).Where("lock_key = ?", lockKey).First(&leader).Error
+	if err != nil {
+		return nil, err
+	}
+	return &leader, nil
+}
+
+// SaveMember registers an instance or refreshes its registration
+func (r *ClusterRepository) SaveMember(ctx context.Context, member *models.ClusterMember) error {
+	return database.Conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "instance_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"hostname", "role", "phase", "capacity", "running", "queued", "heartbeat_at"}),
+	}).Create(member).Error
+}
+
+// DeleteMember removes an instance from the registry
+func (r *ClusterRepository) DeleteMember(ctx context.Context, instanceID string) error {
+	return database.Conn(ctx, r.db).Where("instance_id = ?", instanceID).Delete(&models.ClusterMember{}).Error
+}
+
+// FindMembers retrieves every registered instance, oldest first
+func (r *ClusterRepository) FindMembers(ctx context.Context) ([]models.ClusterMember, error) {
+	var members []models.ClusterMember
+	err := database.Conn(ctx, r.db).Order("started_at ASC").Find(&members).Error
+	return members, err
+}
+
+// DeleteMembersBefore removes instances whose last heartbeat is older than the cutoff
+func (r *ClusterRepository) DeleteMembersBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := database.Conn(ctx, r.db).Where("heartbeat_at < ?", cutoff).Delete(&models.ClusterMember{})
+	return result.RowsAffected, result.Error
+}
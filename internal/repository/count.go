@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// exactCountThreshold is the planner estimate below which an exact count is cheap enough to run anyway
+const exactCountThreshold = 10000
+
+// countRows counts the rows a listing query matches. In estimated mode it uses the
+// query planner's row estimate, falling back to COUNT(*) for small results.
+// It reports whether the total is an estimate.
+func countRows(ctx context.Context, query *gorm.DB, mode models.CountMode) (int64, bool, error) {
+	if mode == models.CountEstimated {
+		if estimate, err := estimateRows(ctx, query); err == nil && estimate >= exactCountThreshold {
+			return estimate, true, nil
+		}
+	}
+
+	var total int64
+	err := query.Count(&total).Error
+	return total, false, err
+}
+
+// estimateRows asks the planner how many rows a query returns without running it
+func estimateRows(ctx context.Context, query *gorm.DB) (int64, error) {
+	var rows []int
+	stmt := query.Session(&gorm.Session{DryRun: true}).Select("1").Find(&rows).Statement
+
+	sqlDB, err := query.DB()
+	if err != nil {
+		return 0, err
+	}
+
+	var raw []byte
+	if err := sqlDB.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+stmt.SQL.String(), stmt.Vars...).Scan(&raw); err != nil {
+		return 0, err
+	}
+
+	var plan []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(raw, &plan); err != nil || len(plan) == 0 {
+		return 0, err
+	}
+	return int64(plan[0].Plan.Rows), nil
+}
@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
 	"github.com/minisource/scheduler/internal/models"
 	"gorm.io/gorm"
 )
@@ -21,18 +23,28 @@ func NewExecutionRepository(db *gorm.DB) *ExecutionRepository {
 
 // Create creates a new execution record
 func (r *ExecutionRepository) Create(ctx context.Context, execution *models.JobExecution) error {
-	return r.db.WithContext(ctx).Create(execution).Error
+	return database.Conn(ctx, r.db).Create(execution).Error
 }
 
 // Update updates an execution record
 func (r *ExecutionRepository) Update(ctx context.Context, execution *models.JobExecution) error {
-	return r.db.WithContext(ctx).Save(execution).Error
+	return database.Conn(ctx, r.db).Save(execution).Error
 }
 
 // FindByID retrieves an execution by ID
 func (r *ExecutionRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.JobExecution, error) {
 	var execution models.JobExecution
-	err := r.db.WithContext(ctx).First(&execution, "id = ?", id).Error
+	err := database.Conn(ctx, r.db).First(&execution, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// FindByTenantAndID retrieves an execution by tenant and ID
+func (r *ExecutionRepository) FindByTenantAndID(ctx context.Context, tenantID, id uuid.UUID) (*models.JobExecution, error) {
+	var execution models.JobExecution
+	err := database.Conn(ctx, r.db).First(&execution, "id = ? AND tenant_id = ?", id, tenantID).Error
 	if err != nil {
 		return nil, err
 	}
@@ -42,12 +54,12 @@ func (r *ExecutionRepository) FindByID(ctx context.Context, id uuid.UUID) (*mode
 // Query finds executions matching the filter
 func (r *ExecutionRepository) Query(ctx context.Context, filter models.ExecutionFilter) (*models.ExecutionListResult, error) {
 	var executions []models.JobExecution
-	var total int64
 
-	query := r.buildQuery(filter)
+	query := r.buildQuery(ctx, filter)
 
 	// Get total count
-	if err := query.Count(&total).Error; err != nil {
+	total, estimated, err := countRows(ctx, query, filter.Count)
+	if err != nil {
 		return nil, err
 	}
 
@@ -61,24 +73,35 @@ func (r *ExecutionRepository) Query(ctx context.Context, filter models.Execution
 		pageSize = 20
 	}
 
+	if len(filter.Fields) > 0 {
+		query = query.Select(selectColumns(filter.Fields, models.ExecutionFieldColumns))
+	}
+
+	// Fetch one extra row so has_more stays exact when the total is estimated
 	offset := (page - 1) * pageSize
-	err := query.Order("scheduled_at DESC").Offset(offset).Limit(pageSize).Find(&executions).Error
+	err = query.Order("scheduled_at DESC").Offset(offset).Limit(pageSize + 1).Find(&executions).Error
 	if err != nil {
 		return nil, err
 	}
 
+	hasMore := len(executions) > pageSize
+	if hasMore {
+		executions = executions[:pageSize]
+	}
+
 	return &models.ExecutionListResult{
-		Executions: executions,
-		TotalCount: total,
-		Page:       page,
-		PageSize:   pageSize,
-		HasMore:    int64((page)*pageSize) < total,
+		Executions:     executions,
+		TotalCount:     total,
+		TotalEstimated: estimated,
+		Page:           page,
+		PageSize:       pageSize,
+		HasMore:        hasMore,
 	}, nil
 }
 
 // buildQuery creates the GORM query from filter
-func (r *ExecutionRepository) buildQuery(filter models.ExecutionFilter) *gorm.DB {
-	query := r.db.Model(&models.JobExecution{})
+func (r *ExecutionRepository) buildQuery(ctx context.Context, filter models.ExecutionFilter) *gorm.DB {
+	query := database.Conn(ctx, r.db).Model(&models.JobExecution{})
 
 	if filter.JobID != nil {
 		query = query.Where("job_id = ?", filter.JobID)
@@ -88,10 +111,22 @@ func (r *ExecutionRepository) buildQuery(filter models.ExecutionFilter) *gorm.DB
 		query = query.Where("tenant_id = ?", filter.TenantID)
 	}
 
+	if filter.CorrelationID != "" {
+		query = query.Where("correlation_id = ?", filter.CorrelationID)
+	}
+
 	if filter.Status != "" {
 		query = query.Where("status = ?", filter.Status)
 	}
 
+	if filter.Acknowledged != nil {
+		if *filter.Acknowledged {
+			query = query.Where("acknowledged_at IS NOT NULL")
+		} else {
+			query = query.Where("acknowledged_at IS NULL")
+		}
+	}
+
 	if filter.StartTime != nil {
 		query = query.Where("scheduled_at >= ?", filter.StartTime)
 	}
@@ -106,7 +141,7 @@ func (r *ExecutionRepository) buildQuery(filter models.ExecutionFilter) *gorm.DB
 // FindByJobID retrieves executions for a job
 func (r *ExecutionRepository) FindByJobID(ctx context.Context, jobID uuid.UUID, limit int) ([]models.JobExecution, error) {
 	var executions []models.JobExecution
-	err := r.db.WithContext(ctx).
+	err := database.Conn(ctx, r.db).
 		Where("job_id = ?", jobID).
 		Order("scheduled_at DESC").
 		Limit(limit).
@@ -114,10 +149,44 @@ func (r *ExecutionRepository) FindByJobID(ctx context.Context, jobID uuid.UUID,
 	return executions, err
 }
 
+// FindByTenantAndJobID retrieves executions for a job owned by a tenant
+func (r *ExecutionRepository) FindByTenantAndJobID(ctx context.Context, tenantID, jobID uuid.UUID, limit int) ([]models.JobExecution, error) {
+	var executions []models.JobExecution
+	err := database.Conn(ctx, r.db).
+		Where("job_id = ? AND tenant_id = ?", jobID, tenantID).
+		Order("scheduled_at DESC").
+		Limit(limit).
+		Find(&executions).Error
+	return executions, err
+}
+
+// FindLatestByJobIDs retrieves the most recent execution of each of a tenant's jobs
+func (r *ExecutionRepository) FindLatestByJobIDs(ctx context.Context, tenantID uuid.UUID, jobIDs []uuid.UUID) (map[uuid.UUID]models.JobExecution, error) {
+	latest := make(map[uuid.UUID]models.JobExecution, len(jobIDs))
+	if len(jobIDs) == 0 {
+		return latest, nil
+	}
+
+	var executions []models.JobExecution
+	err := database.Conn(ctx, r.db).
+		Raw(`SELECT DISTINCT ON (job_id) * FROM job_executions
+			WHERE tenant_id = ? AND job_id IN ?
+			ORDER BY job_id, scheduled_at DESC`, tenantID, jobIDs).
+		Scan(&executions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, execution := range executions {
+		latest[execution.JobID] = execution
+	}
+	return latest, nil
+}
+
 // FindPending finds pending executions
 func (r *ExecutionRepository) FindPending(ctx context.Context, before time.Time, limit int) ([]models.JobExecution, error) {
 	var executions []models.JobExecution
-	err := r.db.WithContext(ctx).
+	err := database.Conn(ctx, r.db).
 		Where("status = ?", models.ExecutionStatusPending).
 		Where("scheduled_at <= ?", before).
 		Order("scheduled_at ASC").
@@ -126,37 +195,186 @@ func (r *ExecutionRepository) FindPending(ctx context.Context, before time.Time,
 	return executions, err
 }
 
+// FindQueued finds executions held back by rate limits, oldest first. Paused tenants'
+// executions stay queued.
+func (r *ExecutionRepository) FindQueued(ctx context.Context, limit int) ([]models.JobExecution, error) {
+	var executions []models.JobExecution
+	err := database.Conn(ctx, r.db).
+		Where("status = ?", models.ExecutionStatusQueued).
+		Where(notPaused).
+		Order("scheduled_at ASC").
+		Limit(limit).
+		Find(&executions).Error
+	return executions, err
+}
+
+// CountQueued counts a tenant's executions held back by rate limits
+func (r *ExecutionRepository) CountQueued(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	var count int64
+	err := database.Conn(ctx, r.db).
+		Model(&models.JobExecution{}).
+		Where("tenant_id = ? AND status = ?", tenantID, models.ExecutionStatusQueued).
+		Count(&count).Error
+	return count, err
+}
+
+// CountPendingAhead counts the pending executions of a worker group that are due and would be
+// picked up before one scheduled at scheduledAt with the given ID
+func (r *ExecutionRepository) CountPendingAhead(ctx context.Context, group string, scheduledAt time.Time, id uuid.UUID) (int64, error) {
+	var count int64
+	err := database.Conn(ctx, r.db).
+		Model(&models.JobExecution{}).
+		Where("status = ? AND COALESCE(worker_group, '') = ?", models.ExecutionStatusPending, group).
+		Where("scheduled_at <= ?", time.Now()).
+		Where("scheduled_at < ? OR (scheduled_at = ? AND id < ?)", scheduledAt, scheduledAt, id).
+		Count(&count).Error
+	return count, err
+}
+
+// CountQueuedAhead counts a tenant's queued executions that are released before one scheduled
+// at scheduledAt with the given ID
+func (r *ExecutionRepository) CountQueuedAhead(ctx context.Context, tenantID uuid.UUID, scheduledAt time.Time, id uuid.UUID) (int64, error) {
+	var count int64
+	err := database.Conn(ctx, r.db).
+		Model(&models.JobExecution{}).
+		Where("tenant_id = ? AND status = ?", tenantID, models.ExecutionStatusQueued).
+		Where("scheduled_at < ? OR (scheduled_at = ? AND id < ?)", scheduledAt, scheduledAt, id).
+		Count(&count).Error
+	return count, err
+}
+
+// CountStartedSince counts the executions of a worker group that started since a time
+func (r *ExecutionRepository) CountStartedSince(ctx context.Context, group string, since time.Time) (int64, error) {
+	var count int64
+	err := database.Conn(ctx, r.db).
+		Model(&models.JobExecution{}).
+		Where("COALESCE(worker_group, '') = ? AND started_at >= ?", group, since).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkQueuedAsPending releases a queued execution to the workers, claimed for an instance's
+// workers until claimedUntil, or for any instance's when claimedUntil is nil.
+// It reports false if the execution is no longer queued.
+func (r *ExecutionRepository) MarkQueuedAsPending(ctx context.Context, id uuid.UUID, claimedBy string, claimedUntil *time.Time) (bool, error) {
+	result := database.Conn(ctx, r.db).
+		Model(&models.JobExecution{}).
+		Where("id = ? AND status = ?", id, models.ExecutionStatusQueued).
+		Updates(map[string]interface{}{
+			"status":        models.ExecutionStatusPending,
+			"claimed_by":    claimedBy,
+			"claimed_until": claimedUntil,
+			"updated_at":    time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ClaimPending claims up to limit pending executions for an instance's workers until lease
+// has passed, oldest first. Executions claimed by other instances are skipped until their
+// claim lapses, e.g. because the instance that created them died before running them.
+func (r *ExecutionRepository) ClaimPending(ctx context.Context, owner, group string, lease time.Duration, limit int) ([]models.JobExecution, error) {
+	var executions []models.JobExecution
+	now := time.Now()
+	err := database.Conn(ctx, r.db).Raw(`
+		UPDATE job_executions SET claimed_by = ?, claimed_until = ?
+		WHERE id IN (
+			SELECT id FROM job_executions
+			WHERE status = ? AND scheduled_at <= ? AND (claimed_until IS NULL OR claimed_until < ?)
+				AND COALESCE(worker_group, '') = ?
+			ORDER BY scheduled_at ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		owner, now.Add(lease), models.ExecutionStatusPending, now, now, group, limit,
+	).Scan(&executions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING doesn't keep the subquery's order
+	sort.SliceStable(executions, func(i, j int) bool {
+		return executions[i].ScheduledAt.Before(executions[j].ScheduledAt)
+	})
+	return executions, nil
+}
+
 // FindRunning finds running executions
 func (r *ExecutionRepository) FindRunning(ctx context.Context) ([]models.JobExecution, error) {
 	var executions []models.JobExecution
-	err := r.db.WithContext(ctx).
+	err := database.Conn(ctx, r.db).
 		Where("status = ?", models.ExecutionStatusRunning).
 		Find(&executions).Error
 	return executions, err
 }
 
-// MarkAsRunning marks an execution as running
-func (r *ExecutionRepository) MarkAsRunning(ctx context.Context, id uuid.UUID, workerID string) error {
+// FindStuck finds running executions that started longer ago than their job's timeout plus
+// a grace period, oldest first. Their worker has most likely died.
+func (r *ExecutionRepository) FindStuck(ctx context.Context, grace time.Duration, limit int) ([]models.JobExecution, error) {
+	var executions []models.JobExecution
+	err := database.Conn(ctx, r.db).
+		Where("status = ?", models.ExecutionStatusRunning).
+		Where("started_at < NOW() - make_interval(secs => COALESCE((SELECT timeout FROM jobs WHERE jobs.id = job_executions.job_id), 0) + ?)", grace.Seconds()).
+		Order("started_at ASC").
+		Limit(limit).
+		Find(&executions).Error
+	return executions, err
+}
+
+// MarkAsTimedOut marks a running execution as timed out.
+// It reports false if the execution is no longer running.
+func (r *ExecutionRepository) MarkAsTimedOut(ctx context.Context, id uuid.UUID, errMsg string) (bool, error) {
+	now := time.Now()
+
+	var execution models.JobExecution
+	if err := database.Conn(ctx, r.db).First(&execution, "id = ?", id).Error; err != nil {
+		return false, err
+	}
+
+	var duration int64
+	if execution.StartedAt != nil {
+		duration = now.Sub(*execution.StartedAt).Milliseconds()
+	}
+
+	result := database.Conn(ctx, r.db).
+		Model(&models.JobExecution{}).
+		Where("id = ? AND status = ?", id, models.ExecutionStatusRunning).
+		Updates(map[string]interface{}{
+			"status":       models.ExecutionStatusTimeout,
+			"completed_at": now,
+			"duration":     duration,
+			"error":        errMsg,
+			"updated_at":   now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkAsRunning claims a pending or retrying execution and records the trace it runs under.
+// It reports false if the execution was cancelled or claimed by another worker.
+func (r *ExecutionRepository) MarkAsRunning(ctx context.Context, id uuid.UUID, workerID, traceID string) (bool, error) {
 	now := time.Now()
-	return r.db.WithContext(ctx).
+	result := database.Conn(ctx, r.db).
 		Model(&models.JobExecution{}).
 		Where("id = ?", id).
-		Where("status = ?", models.ExecutionStatusPending).
+		Where("status IN ?", []models.ExecutionStatus{models.ExecutionStatusPending, models.ExecutionStatusRetrying}).
 		Updates(map[string]interface{}{
 			"status":     models.ExecutionStatusRunning,
 			"started_at": now,
 			"worker_id":  workerID,
+			"trace_id":   traceID,
 			"updated_at": now,
-		}).Error
+		})
+	return result.RowsAffected > 0, result.Error
 }
 
-// MarkAsCompleted marks an execution as completed
-func (r *ExecutionRepository) MarkAsCompleted(ctx context.Context, id uuid.UUID, statusCode int, response []byte) error {
+// MarkAsCompleted marks an execution as completed.
+// It reports false if the execution was cancelled or timed out in the meantime.
+func (r *ExecutionRepository) MarkAsCompleted(ctx context.Context, id uuid.UUID, statusCode int, response []byte) (bool, error) {
 	now := time.Now()
 
 	var execution models.JobExecution
-	if err := r.db.WithContext(ctx).First(&execution, "id = ?", id).Error; err != nil {
-		return err
+	if err := database.Conn(ctx, r.db).First(&execution, "id = ?", id).Error; err != nil {
+		return false, err
 	}
 
 	var duration int64
@@ -164,9 +382,10 @@ func (r *ExecutionRepository) MarkAsCompleted(ctx context.Context, id uuid.UUID,
 		duration = now.Sub(*execution.StartedAt).Milliseconds()
 	}
 
-	return r.db.WithContext(ctx).
+	result := database.Conn(ctx, r.db).
 		Model(&models.JobExecution{}).
 		Where("id = ?", id).
+		Where("status NOT IN ?", settledExecutionStatuses).
 		Updates(map[string]interface{}{
 			"status":       models.ExecutionStatusCompleted,
 			"completed_at": now,
@@ -174,16 +393,18 @@ func (r *ExecutionRepository) MarkAsCompleted(ctx context.Context, id uuid.UUID,
 			"status_code":  statusCode,
 			"response":     response,
 			"updated_at":   now,
-		}).Error
+		})
+	return result.RowsAffected > 0, result.Error
 }
 
-// MarkAsFailed marks an execution as failed
-func (r *ExecutionRepository) MarkAsFailed(ctx context.Context, id uuid.UUID, errMsg string, statusCode *int) error {
+// MarkAsFailed marks an execution as failed.
+// It reports false if the execution was cancelled or timed out in the meantime.
+func (r *ExecutionRepository) MarkAsFailed(ctx context.Context, id uuid.UUID, errMsg string, statusCode *int) (bool, error) {
 	now := time.Now()
 
 	var execution models.JobExecution
-	if err := r.db.WithContext(ctx).First(&execution, "id = ?", id).Error; err != nil {
-		return err
+	if err := database.Conn(ctx, r.db).First(&execution, "id = ?", id).Error; err != nil {
+		return false, err
 	}
 
 	var duration int64
@@ -203,17 +424,20 @@ func (r *ExecutionRepository) MarkAsFailed(ctx context.Context, id uuid.UUID, er
 		updates["status_code"] = *statusCode
 	}
 
-	return r.db.WithContext(ctx).
+	result := database.Conn(ctx, r.db).
 		Model(&models.JobExecution{}).
 		Where("id = ?", id).
-		Updates(updates).Error
+		Where("status NOT IN ?", settledExecutionStatuses).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
 }
 
 // MarkAsRetrying marks an execution for retry
 func (r *ExecutionRepository) MarkAsRetrying(ctx context.Context, id uuid.UUID, errMsg string) error {
-	return r.db.WithContext(ctx).
+	return database.Conn(ctx, r.db).
 		Model(&models.JobExecution{}).
 		Where("id = ?", id).
+		Where("status NOT IN ?", settledExecutionStatuses).
 		Updates(map[string]interface{}{
 			"status":     models.ExecutionStatusRetrying,
 			"error":      errMsg,
@@ -222,12 +446,61 @@ func (r *ExecutionRepository) MarkAsRetrying(ctx context.Context, id uuid.UUID,
 		}).Error
 }
 
+// AddUsage adds the wall time and traffic of an attempt to an execution's usage
+func (r *ExecutionRepository) AddUsage(ctx context.Context, id uuid.UUID, wallTime, bytesSent, bytesReceived int64) error {
+	return database.Conn(ctx, r.db).
+		Model(&models.JobExecution{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"wall_time":      gorm.Expr("wall_time + ?", wallTime),
+			"bytes_sent":     gorm.Expr("bytes_sent + ?", bytesSent),
+			"bytes_received": gorm.Expr("bytes_received + ?", bytesReceived),
+		}).Error
+}
+
+// settledExecutionStatuses are final statuses set outside the worker running an execution,
+// which the worker must not overwrite when it finishes late
+var settledExecutionStatuses = []models.ExecutionStatus{
+	models.ExecutionStatusCancelled,
+	models.ExecutionStatusTimeout,
+}
+
+// activeExecutionStatuses are the statuses of executions that have not finished
+var activeExecutionStatuses = []models.ExecutionStatus{
+	models.ExecutionStatusQueued,
+	models.ExecutionStatusPending,
+	models.ExecutionStatusRunning,
+	models.ExecutionStatusRetrying,
+}
+
+// FindActiveByJobID finds a job's unfinished executions scheduled since the given time
+func (r *ExecutionRepository) FindActiveByJobID(ctx context.Context, jobID uuid.UUID, since time.Time) ([]models.JobExecution, error) {
+	var executions []models.JobExecution
+	err := database.Conn(ctx, r.db).
+		Where("job_id = ? AND status IN ? AND scheduled_at >= ?", jobID, activeExecutionStatuses, since).
+		Find(&executions).Error
+	return executions, err
+}
+
+// FindCancelledIDs returns which of the given executions have been cancelled
+func (r *ExecutionRepository) FindCancelledIDs(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	var cancelled []uuid.UUID
+	if len(ids) == 0 {
+		return cancelled, nil
+	}
+	err := database.Conn(ctx, r.db).
+		Model(&models.JobExecution{}).
+		Where("id IN ? AND status = ?", ids, models.ExecutionStatusCancelled).
+		Pluck("id", &cancelled).Error
+	return cancelled, err
+}
+
 // CancelExecution cancels an execution
 func (r *ExecutionRepository) CancelExecution(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).
+	return database.Conn(ctx, r.db).
 		Model(&models.JobExecution{}).
 		Where("id = ?", id).
-		Where("status IN ?", []models.ExecutionStatus{models.ExecutionStatusPending, models.ExecutionStatusRunning}).
+		Where("status IN ?", activeExecutionStatuses).
 		Updates(map[string]interface{}{
 			"status":       models.ExecutionStatusCancelled,
 			"completed_at": time.Now(),
@@ -235,9 +508,23 @@ func (r *ExecutionRepository) CancelExecution(ctx context.Context, id uuid.UUID)
 		}).Error
 }
 
+// Acknowledge records an operator acknowledgement and comment on a finished execution
+func (r *ExecutionRepository) Acknowledge(ctx context.Context, id uuid.UUID, userID *uuid.UUID, comment string) error {
+	now := time.Now()
+	return database.Conn(ctx, r.db).
+		Model(&models.JobExecution{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"acknowledged_at": now,
+			"acknowledged_by": userID,
+			"ack_comment":     comment,
+			"updated_at":      now,
+		}).Error
+}
+
 // CleanupOld removes old execution records
 func (r *ExecutionRepository) CleanupOld(ctx context.Context, before time.Time) (int64, error) {
-	result := r.db.WithContext(ctx).
+	result := database.Conn(ctx, r.db).
 		Where("created_at < ?", before).
 		Where("status IN ?", []models.ExecutionStatus{
 			models.ExecutionStatusCompleted,
@@ -252,7 +539,7 @@ func (r *ExecutionRepository) CleanupOld(ctx context.Context, before time.Time)
 func (r *ExecutionRepository) GetExecutionStats(ctx context.Context, tenantID *uuid.UUID, startTime, endTime time.Time) (map[string]int64, error) {
 	stats := make(map[string]int64)
 
-	query := r.db.WithContext(ctx).Model(&models.JobExecution{}).
+	query := database.Conn(ctx, r.db).Model(&models.JobExecution{}).
 		Where("scheduled_at >= ? AND scheduled_at <= ?", startTime, endTime)
 
 	if tenantID != nil {
@@ -271,10 +558,13 @@ func (r *ExecutionRepository) GetExecutionStats(ctx context.Context, tenantID *u
 		models.ExecutionStatusCancelled,
 	} {
 		var count int64
-		r.db.Model(&models.JobExecution{}).
+		statusQuery := database.Conn(ctx, r.db).Model(&models.JobExecution{}).
 			Where("scheduled_at >= ? AND scheduled_at <= ?", startTime, endTime).
-			Where("status = ?", status).
-			Count(&count)
+			Where("status = ?", status)
+		if tenantID != nil {
+			statusQuery = statusQuery.Where("tenant_id = ?", tenantID)
+		}
+		statusQuery.Count(&count)
 		stats[string(status)] = count
 	}
 
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// finishedExecutionStatuses are the final statuses of executions, which are sealed
+var finishedExecutionStatuses = []models.ExecutionStatus{
+	models.ExecutionStatusCompleted,
+	models.ExecutionStatusFailed,
+	models.ExecutionStatusCancelled,
+	models.ExecutionStatusTimeout,
+}
+
+// ExecutionSealRepository handles the execution hash chain
+type ExecutionSealRepository struct {
+	db *gorm.DB
+}
+
+// NewExecutionSealRepository creates a new execution seal repository
+func NewExecutionSealRepository(db *gorm.DB) *ExecutionSealRepository {
+	return &ExecutionSealRepository{db: db}
+}
+
+// SealPending appends up to limit finished, unsealed executions to their tenants' chains and
+// returns how many were sealed. Executions are locked while they are sealed and each
+// tenant's chain is extended under an advisory lock, so concurrent sealers never fork a chain.
+func (r *ExecutionSealRepository) SealPending(ctx context.Context, limit int) (int, error) {
+	sealed := 0
+	err := database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var executions []models.JobExecution
+		if err := tx.Raw(`SELECT * FROM job_executions e
+			WHERE e.status IN ?
+			AND NOT EXISTS (SELECT 1 FROM execution_seals s WHERE s.execution_id = e.id)
+			ORDER BY e.tenant_id, e.completed_at NULLS LAST, e.id
+			LIMIT ? FOR UPDATE SKIP LOCKED`, finishedExecutionStatuses, limit).
+			Scan(&executions).Error; err != nil {
+			return err
+		}
+
+		var (
+			tenantID uuid.UUID
+			last     models.ExecutionSeal
+			seals    []models.ExecutionSeal
+		)
+		for i := range executions {
+			execution := &executions[i]
+			if i == 0 || execution.TenantID != tenantID {
+				tenantID = execution.TenantID
+				if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "execution_seals:"+tenantID.String()).Error; err != nil {
+					return err
+				}
+				last = models.ExecutionSeal{}
+				if err := tx.Where("tenant_id = ?", tenantID).Order("seq DESC").Limit(1).Find(&last).Error; err != nil {
+					return err
+				}
+			}
+
+			seal := models.ExecutionSeal{
+				TenantID:    tenantID,
+				Seq:         last.Seq + 1,
+				ExecutionID: execution.ID,
+				ContentHash: models.ExecutionContentHash(execution),
+				PrevHash:    last.Hash,
+				SealedAt:    time.Now().UTC().Truncate(time.Microsecond), // Postgres precision
+			}
+			seal.Hash = seal.ComputeHash()
+			seals = append(seals, seal)
+			last = seal
+		}
+
+		if len(seals) == 0 {
+			return nil
+		}
+		sealed = len(seals)
+		return tx.Create(&seals).Error
+	})
+	return sealed, err
+}
+
+// FindByTenant returns up to limit of a tenant's seals after afterSeq, in chain order
+func (r *ExecutionSealRepository) FindByTenant(ctx context.Context, tenantID uuid.UUID, afterSeq int64, limit int) ([]models.ExecutionSeal, error) {
+	var seals []models.ExecutionSeal
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND seq > ?", tenantID, afterSeq).
+		Order("seq ASC").
+		Limit(limit).
+		Find(&seals).Error
+	return seals, err
+}
+
+// FindExecutions returns a tenant's executions with the given IDs, keyed by ID
+func (r *ExecutionSealRepository) FindExecutions(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) (map[uuid.UUID]models.JobExecution, error) {
+	executions := make(map[uuid.UUID]models.JobExecution, len(ids))
+	if len(ids) == 0 {
+		return executions, nil
+	}
+
+	var rows []models.JobExecution
+	if err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND id IN ?", tenantID, ids).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, execution := range rows {
+		executions[execution.ID] = execution
+	}
+	return executions, nil
+}
+
+// CountUnsealed counts a tenant's finished executions that are not sealed yet
+func (r *ExecutionSealRepository) CountUnsealed(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	var count int64
+	err := database.Conn(ctx, r.db).
+		Model(&models.JobExecution{}).
+		Where("tenant_id = ? AND status IN ?", tenantID, finishedExecutionStatuses).
+		Where("NOT EXISTS (SELECT 1 FROM execution_seals s WHERE s.execution_id = job_executions.id)").
+		Count(&count).Error
+	return count, err
+}
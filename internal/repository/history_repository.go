@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
 	"github.com/minisource/scheduler/internal/models"
 	"gorm.io/gorm"
 )
@@ -21,119 +22,110 @@ func NewHistoryRepository(db *gorm.DB) *HistoryRepository {
 
 // Upsert creates or updates a history record
 func (r *HistoryRepository) Upsert(ctx context.Context, history *models.JobHistory) error {
-	return r.db.WithContext(ctx).
-		Where("job_id = ? AND date = ?", history.JobID, history.Date).
+	return database.Conn(ctx, r.db).
+		Where("job_id = ? AND timezone = ? AND date = ?", history.JobID, history.Timezone, history.Date).
 		Assign(*history).
 		FirstOrCreate(history).Error
 }
 
-// IncrementSuccess increments the success count for a job on a date
-func (r *HistoryRepository) IncrementSuccess(ctx context.Context, jobID uuid.UUID, date time.Time, duration int64) error {
-	dateOnly := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
-
-	var history models.JobHistory
-	err := r.db.WithContext(ctx).
-		Where("job_id = ? AND date = ?", jobID, dateOnly).
-		First(&history).Error
-
-	if err == gorm.ErrRecordNotFound {
-		history = models.JobHistory{
-			ID:            uuid.New(),
-			JobID:         jobID,
-			Date:          dateOnly,
-			SuccessCount:  1,
-			TotalDuration: duration,
-			MinDuration:   duration,
-			MaxDuration:   duration,
-		}
-		return r.db.WithContext(ctx).Create(&history).Error
-	}
-
-	if err != nil {
-		return err
-	}
-
-	// Update statistics
-	newCount := history.SuccessCount + 1
-	totalDuration := history.TotalDuration + duration
-	avgDuration := float64(totalDuration) / float64(newCount+history.FailureCount)
-
-	minDuration := history.MinDuration
-	if duration < minDuration || minDuration == 0 {
-		minDuration = duration
-	}
-
-	maxDuration := history.MaxDuration
-	if duration > maxDuration {
-		maxDuration = duration
-	}
+// IncrementSuccess increments the success count for a job on a date, the calendar day of date
+// in its own location, in the history kept for a timezone. The record is created or updated
+// in one statement, so concurrent executions don't lose counts.
+func (r *HistoryRepository) IncrementSuccess(ctx context.Context, tenantID, jobID uuid.UUID, timezone string, date time.Time, duration int64) error {
+	return database.Conn(ctx, r.db).Exec(`
+		INSERT INTO job_history (id, job_id, tenant_id, date, timezone, total_runs, success_count,
+			total_duration, avg_duration, min_duration, max_duration, created_at, updated_at)
+		VALUES (gen_random_uuid(), ?, ?, ?::date, ?, 1, 1, ?, ?, ?, ?, NOW(), NOW())
+		ON CONFLICT (job_id, timezone, date) DO UPDATE SET
+			total_runs = job_history.total_runs + 1,
+			success_count = job_history.success_count + 1,
+			total_duration = job_history.total_duration + EXCLUDED.total_duration,
+			avg_duration = (job_history.total_duration + EXCLUDED.total_duration) /
+				(job_history.success_count + 1 + job_history.failure_count),
+			min_duration = CASE
+				WHEN job_history.min_duration = 0 OR EXCLUDED.min_duration < job_history.min_duration
+				THEN EXCLUDED.min_duration ELSE job_history.min_duration END,
+			max_duration = GREATEST(job_history.max_duration, EXCLUDED.max_duration),
+			updated_at = NOW()`,
+		jobID, tenantID, date.Format("2006-01-02"), timezone, duration, duration, duration, duration,
+	).Error
+}
 
-	return r.db.WithContext(ctx).
-		Model(&models.JobHistory{}).
-		Where("id = ?", history.ID).
-		Updates(map[string]interface{}{
-			"success_count":  newCount,
-			"total_duration": totalDuration,
-			"avg_duration":   avgDuration,
-			"min_duration":   minDuration,
-			"max_duration":   maxDuration,
-		}).Error
+// IncrementFailure increments the failure count for a job on a date, the calendar day of date
+// in its own location, in the history kept for a timezone. Like IncrementSuccess, it is one
+// statement.
+func (r *HistoryRepository) IncrementFailure(ctx context.Context, tenantID, jobID uuid.UUID, timezone string, date time.Time) error {
+	return database.Conn(ctx, r.db).Exec(`
+		INSERT INTO job_history (id, job_id, tenant_id, date, timezone, total_runs, failure_count,
+			min_duration, max_duration, created_at, updated_at)
+		VALUES (gen_random_uuid(), ?, ?, ?::date, ?, 1, 1, 0, 0, NOW(), NOW())
+		ON CONFLICT (job_id, timezone, date) DO UPDATE SET
+			total_runs = job_history.total_runs + 1,
+			failure_count = job_history.failure_count + 1,
+			updated_at = NOW()`,
+		jobID, tenantID, date.Format("2006-01-02"), timezone,
+	).Error
 }
 
-// IncrementFailure increments the failure count for a job on a date
-func (r *HistoryRepository) IncrementFailure(ctx context.Context, jobID uuid.UUID, date time.Time) error {
+// AddUsage adds the resources an execution used to its job's history record for a date in a
+// timezone, which IncrementSuccess or IncrementFailure has created
+func (r *HistoryRepository) AddUsage(ctx context.Context, jobID uuid.UUID, timezone string, date time.Time, execution *models.JobExecution) error {
 	dateOnly := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
 
-	var history models.JobHistory
-	err := r.db.WithContext(ctx).
-		Where("job_id = ? AND date = ?", jobID, dateOnly).
-		First(&history).Error
-
-	if err == gorm.ErrRecordNotFound {
-		history = models.JobHistory{
-			ID:           uuid.New(),
-			JobID:        jobID,
-			Date:         dateOnly,
-			FailureCount: 1,
-		}
-		return r.db.WithContext(ctx).Create(&history).Error
-	}
-
-	if err != nil {
-		return err
+	var retries int64
+	if execution.Attempt > 1 {
+		retries = int64(execution.Attempt - 1)
 	}
-
-	return r.db.WithContext(ctx).
+	return database.Conn(ctx, r.db).
 		Model(&models.JobHistory{}).
-		Where("id = ?", history.ID).
-		Update("failure_count", gorm.Expr("failure_count + 1")).Error
+		Where("job_id = ? AND timezone = ? AND date = ?", jobID, timezone, dateOnly).
+		Updates(map[string]interface{}{
+			"wall_time":      gorm.Expr("wall_time + ?", execution.WallTime),
+			"bytes_sent":     gorm.Expr("bytes_sent + ?", execution.BytesSent),
+			"bytes_received": gorm.Expr("bytes_received + ?", execution.BytesReceived),
+			"retries":        gorm.Expr("retries + ?", retries),
+		}).Error
 }
 
-// FindByJobID retrieves history records for a job
+// FindByJobID retrieves the UTC history records of a job
 func (r *HistoryRepository) FindByJobID(ctx context.Context, jobID uuid.UUID, days int) ([]models.JobHistory, error) {
 	var history []models.JobHistory
 	startDate := time.Now().AddDate(0, 0, -days)
 
-	err := r.db.WithContext(ctx).
-		Where("job_id = ? AND date >= ?", jobID, startDate).
+	err := database.Conn(ctx, r.db).
+		Where("job_id = ? AND timezone = ? AND date >= ?", jobID, models.HistoryTimezoneUTC, startDate).
+		Order("date DESC").
+		Find(&history).Error
+	return history, err
+}
+
+// FindByTenantAndJobID retrieves the history records of a job owned by a tenant, kept for a
+// timezone, from a date on
+func (r *HistoryRepository) FindByTenantAndJobID(ctx context.Context, tenantID, jobID uuid.UUID, timezone string, startDate time.Time) ([]models.JobHistory, error) {
+	var history []models.JobHistory
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND job_id = ? AND timezone = ? AND date >= ?", tenantID, jobID, timezone, startDate).
 		Order("date DESC").
 		Find(&history).Error
 	return history, err
 }
 
-// FindByDateRange retrieves history records for a date range
-func (r *HistoryRepository) FindByDateRange(ctx context.Context, startDate, endDate time.Time) ([]models.JobHistory, error) {
+// FindByDateRange retrieves a tenant's history records kept for a timezone for a date range
+func (r *HistoryRepository) FindByDateRange(ctx context.Context, tenantID uuid.UUID, timezone string, startDate, endDate time.Time) ([]models.JobHistory, error) {
 	var history []models.JobHistory
-	err := r.db.WithContext(ctx).
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND timezone = ?", tenantID, timezone).
 		Where("date >= ? AND date <= ?", startDate, endDate).
 		Order("date DESC, job_id").
 		Find(&history).Error
 	return history, err
 }
 
-// GetAggregatedStats gets aggregated statistics for a period
-func (r *HistoryRepository) GetAggregatedStats(ctx context.Context, jobID *uuid.UUID, startDate, endDate time.Time) (*models.AggregatedHistoryStats, error) {
-	query := r.db.WithContext(ctx).Model(&models.JobHistory{}).
+// GetAggregatedStats gets aggregated statistics for a tenant over a period, from the history
+// kept for a timezone
+func (r *HistoryRepository) GetAggregatedStats(ctx context.Context, tenantID uuid.UUID, jobID *uuid.UUID, timezone string, startDate, endDate time.Time) (*models.AggregatedHistoryStats, error) {
+	query := database.Conn(ctx, r.db).Model(&models.JobHistory{}).
+		Where("tenant_id = ? AND timezone = ?", tenantID, timezone).
 		Where("date >= ? AND date <= ?", startDate, endDate)
 
 	if jobID != nil {
@@ -146,6 +138,10 @@ func (r *HistoryRepository) GetAggregatedStats(ctx context.Context, jobID *uuid.
 		TotalDuration int64
 		MinDuration   int64
 		MaxDuration   int64
+		WallTime      int64
+		BytesSent     int64
+		BytesReceived int64
+		Retries       int64
 	}
 
 	err := query.Select(`
@@ -153,7 +149,8 @@ func (r *HistoryRepository) GetAggregatedStats(ctx context.Context, jobID *uuid.
 		COALESCE(SUM(failure_count), 0) as total_failure,
 		COALESCE(SUM(total_duration), 0) as total_duration,
 		COALESCE(MIN(min_duration), 0) as min_duration,
-		COALESCE(MAX(max_duration), 0) as max_duration
+		COALESCE(MAX(max_duration), 0) as max_duration,
+		` + usageColumns + `
 	`).Scan(&result).Error
 
 	if err != nil {
@@ -173,6 +170,10 @@ func (r *HistoryRepository) GetAggregatedStats(ctx context.Context, jobID *uuid.
 		AvgDuration:   avgDuration,
 		MinDuration:   result.MinDuration,
 		MaxDuration:   result.MaxDuration,
+		WallTime:      result.WallTime,
+		BytesSent:     result.BytesSent,
+		BytesReceived: result.BytesReceived,
+		Retries:       result.Retries,
 	}
 
 	if totalExecutions > 0 {
@@ -182,9 +183,163 @@ func (r *HistoryRepository) GetAggregatedStats(ctx context.Context, jobID *uuid.
 	return stats, nil
 }
 
+// GetAggregatedStatsByJob gets aggregated statistics for each of a tenant's jobs over a period
+// of UTC days
+func (r *HistoryRepository) GetAggregatedStatsByJob(ctx context.Context, tenantID uuid.UUID, jobIDs []uuid.UUID, startDate, endDate time.Time) (map[uuid.UUID]*models.AggregatedHistoryStats, error) {
+	statsByJob := make(map[uuid.UUID]*models.AggregatedHistoryStats, len(jobIDs))
+	if len(jobIDs) == 0 {
+		return statsByJob, nil
+	}
+
+	var results []struct {
+		JobID         uuid.UUID
+		TotalSuccess  int64
+		TotalFailure  int64
+		TotalDuration int64
+		MinDuration   int64
+		MaxDuration   int64
+		WallTime      int64
+		BytesSent     int64
+		BytesReceived int64
+		Retries       int64
+	}
+
+	err := database.Conn(ctx, r.db).Model(&models.JobHistory{}).
+		Where("tenant_id = ? AND job_id IN ? AND timezone = ?", tenantID, jobIDs, models.HistoryTimezoneUTC).
+		Where("date >= ? AND date <= ?", startDate, endDate).
+		Select(`
+			job_id,
+			COALESCE(SUM(success_count), 0) as total_success,
+			COALESCE(SUM(failure_count), 0) as total_failure,
+			COALESCE(SUM(total_duration), 0) as total_duration,
+			COALESCE(MIN(min_duration), 0) as min_duration,
+			COALESCE(MAX(max_duration), 0) as max_duration,
+			` + usageColumns + `
+		`).
+		Group("job_id").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		stats := &models.AggregatedHistoryStats{
+			TotalSuccess:  result.TotalSuccess,
+			TotalFailure:  result.TotalFailure,
+			TotalDuration: result.TotalDuration,
+			MinDuration:   result.MinDuration,
+			MaxDuration:   result.MaxDuration,
+			WallTime:      result.WallTime,
+			BytesSent:     result.BytesSent,
+			BytesReceived: result.BytesReceived,
+			Retries:       result.Retries,
+		}
+		if total := result.TotalSuccess + result.TotalFailure; total > 0 {
+			stats.AvgDuration = float64(result.TotalDuration) / float64(total)
+			stats.SuccessRate = float64(result.TotalSuccess) / float64(total) * 100
+		}
+		statsByJob[result.JobID] = stats
+	}
+	return statsByJob, nil
+}
+
+// usageColumns sums the usage totals of history records
+const usageColumns = `COALESCE(SUM(wall_time), 0) as wall_time,
+	COALESCE(SUM(bytes_sent), 0) as bytes_sent,
+	COALESCE(SUM(bytes_received), 0) as bytes_received,
+	COALESCE(SUM(retries), 0) as retries`
+
+// GetUsageByTenant sums the resources every tenant's executions used over a period of UTC
+// days, busiest tenant first
+func (r *HistoryRepository) GetUsageByTenant(ctx context.Context, startDate, endDate time.Time) ([]models.TenantResourceUsage, error) {
+	var usage []models.TenantResourceUsage
+	err := database.Conn(ctx, r.db).Model(&models.JobHistory{}).
+		Where("timezone = ?", models.HistoryTimezoneUTC).
+		Where("date >= ? AND date <= ?", startDate, endDate).
+		Select(`
+			tenant_id,
+			COALESCE(SUM(success_count + failure_count), 0) as executions,
+			` + usageColumns + `
+		`).
+		Group("tenant_id").
+		Order("wall_time DESC, tenant_id").
+		Scan(&usage).Error
+	return usage, err
+}
+
+// RebuildDay replaces the history records of a day in a timezone with ones aggregated from
+// the executions that finished that day, optionally only for a tenant or a job. Like the
+// records kept as executions finish, completed executions count as successes and their
+// durations make up the duration statistics, and failed and timed out ones count as failures.
+// Every one of them counts in the usage totals; canary executions don't count. Days in another timezone than UTC are only
+// rebuilt for the tenants whose history is kept in it.
+func (r *HistoryRepository) RebuildDay(ctx context.Context, day time.Time, zone *time.Location, tenantID, jobID *uuid.UUID) (deleted, created int64, err error) {
+	timezone := zone.String()
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, zone)
+	end := start.AddDate(0, 0, 1)
+
+	err = database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		del := tx.Where("date = ? AND timezone = ?", day, timezone)
+		if tenantID != nil {
+			del = del.Where("tenant_id = ?", *tenantID)
+		}
+		if jobID != nil {
+			del = del.Where("job_id = ?", *jobID)
+		}
+		result := del.Delete(&models.JobHistory{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+
+		filter := ""
+		completed, failed, timeout := models.ExecutionStatusCompleted, models.ExecutionStatusFailed, models.ExecutionStatusTimeout
+		args := []interface{}{
+			day.Format("2006-01-02"), timezone, completed, failed, timeout, completed, completed, completed, completed,
+			start, end, completed, failed, timeout,
+		}
+		if timezone != models.HistoryTimezoneUTC {
+			filter += " AND tenant_id IN (SELECT tenant_id FROM tenant_settings WHERE timezone = ?)"
+			args = append(args, timezone)
+		}
+		if tenantID != nil {
+			filter += " AND tenant_id = ?"
+			args = append(args, *tenantID)
+		}
+		if jobID != nil {
+			filter += " AND job_id = ?"
+			args = append(args, *jobID)
+		}
+		result = tx.Exec(`
+			INSERT INTO job_history (id, job_id, tenant_id, date, timezone, total_runs, success_count, failure_count,
+				total_duration, avg_duration, min_duration, max_duration,
+				wall_time, bytes_sent, bytes_received, retries, created_at, updated_at)
+			SELECT gen_random_uuid(), job_id, tenant_id, ?::date, ?, COUNT(*),
+				COUNT(*) FILTER (WHERE status = ?),
+				COUNT(*) FILTER (WHERE status IN (?, ?)),
+				COALESCE(SUM(duration) FILTER (WHERE status = ?), 0),
+				COALESCE(SUM(duration) FILTER (WHERE status = ?), 0) / COUNT(*),
+				COALESCE(MIN(duration) FILTER (WHERE status = ?), 0),
+				COALESCE(MAX(duration) FILTER (WHERE status = ?), 0),
+				SUM(wall_time), SUM(bytes_sent), SUM(bytes_received), SUM(GREATEST(attempt - 1, 0)),
+				NOW(), NOW()
+			FROM job_executions
+			WHERE completed_at >= ? AND completed_at < ? AND status IN (?, ?, ?) AND NOT canary`+filter+`
+			GROUP BY job_id, tenant_id`,
+			args...,
+		)
+		if result.Error != nil {
+			return result.Error
+		}
+		created = result.RowsAffected
+		return nil
+	})
+	return deleted, created, err
+}
+
 // CleanupOld removes old history records
 func (r *HistoryRepository) CleanupOld(ctx context.Context, before time.Time) (int64, error) {
-	result := r.db.WithContext(ctx).
+	result := database.Conn(ctx, r.db).
 		Where("date < ?", before).
 		Delete(&models.JobHistory{})
 	return result.RowsAffected, result.Error
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// IncidentRepository handles incident persistence
+type IncidentRepository struct {
+	db *gorm.DB
+}
+
+// NewIncidentRepository creates a new incident repository
+func NewIncidentRepository(db *gorm.DB) *IncidentRepository {
+	return &IncidentRepository{db: db}
+}
+
+// Create creates a new incident
+func (r *IncidentRepository) Create(ctx context.Context, incident *models.Incident) error {
+	return database.Conn(ctx, r.db).Create(incident).Error
+}
+
+// FindByTenantAndID retrieves an incident by tenant and ID
+func (r *IncidentRepository) FindByTenantAndID(ctx context.Context, tenantID, id uuid.UUID) (*models.Incident, error) {
+	var incident models.Incident
+	err := database.Conn(ctx, r.db).First(&incident, "id = ? AND tenant_id = ?", id, tenantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
+
+// FindOpenByGroupKey retrieves the open incident for a group, if any
+func (r *IncidentRepository) FindOpenByGroupKey(ctx context.Context, tenantID uuid.UUID, groupKey string) (*models.Incident, error) {
+	var incident models.Incident
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND group_key = ? AND status = ?", tenantID, groupKey, models.IncidentStatusOpen).
+		First(&incident).Error
+	if err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
+
+// RecordFailure adds a failed execution to an open incident
+func (r *IncidentRepository) RecordFailure(ctx context.Context, incident *models.Incident, executionID uuid.UUID, errMsg string, maxSamples int) error {
+	var samples []string
+	if len(incident.SampleErrors) > 0 {
+		_ = json.Unmarshal(incident.SampleErrors, &samples)
+	}
+
+	samples = append(samples, errMsg)
+	if maxSamples > 0 && len(samples) > maxSamples {
+		samples = samples[len(samples)-maxSamples:]
+	}
+
+	sampleJSON, err := json.Marshal(samples)
+	if err != nil {
+		return err
+	}
+
+	incident.FailureCount++
+	incident.LastExecutionID = executionID
+	incident.SampleErrors = sampleJSON
+
+	return database.Conn(ctx, r.db).
+		Model(&models.Incident{}).
+		Where("id = ?", incident.ID).
+		Updates(map[string]interface{}{
+			"failure_count":     gorm.Expr("failure_count + 1"),
+			"last_execution_id": executionID,
+			"sample_errors":     sampleJSON,
+			"updated_at":        time.Now(),
+		}).Error
+}
+
+// Resolve closes the open incident for a group
+func (r *IncidentRepository) Resolve(ctx context.Context, tenantID uuid.UUID, groupKey string, endedAt time.Time) (int64, error) {
+	result := database.Conn(ctx, r.db).
+		Model(&models.Incident{}).
+		Where("tenant_id = ? AND group_key = ? AND status = ?", tenantID, groupKey, models.IncidentStatusOpen).
+		Updates(map[string]interface{}{
+			"status":     models.IncidentStatusResolved,
+			"ended_at":   endedAt,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// Acknowledge marks a tenant's incident as acknowledged
+func (r *IncidentRepository) Acknowledge(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID) error {
+	now := time.Now()
+	return database.Conn(ctx, r.db).
+		Model(&models.Incident{}).
+		Where("id = ? AND tenant_id = ? AND acknowledged_at IS NULL", id, tenantID).
+		Updates(map[string]interface{}{
+			"acknowledged_at": now,
+			"acknowledged_by": userID,
+			"updated_at":      now,
+		}).Error
+}
+
+// AcknowledgeOpenForExecution acknowledges the open incident an execution failure belongs to
+func (r *IncidentRepository) AcknowledgeOpenForExecution(ctx context.Context, tenantID, jobID, executionID uuid.UUID, userID *uuid.UUID) error {
+	now := time.Now()
+	return database.Conn(ctx, r.db).
+		Model(&models.Incident{}).
+		Where("tenant_id = ? AND status = ? AND acknowledged_at IS NULL", tenantID, models.IncidentStatusOpen).
+		Where("job_id = ? OR last_execution_id = ?", jobID, executionID).
+		Updates(map[string]interface{}{
+			"acknowledged_at": now,
+			"acknowledged_by": userID,
+			"updated_at":      now,
+		}).Error
+}
+
+// FindEscalationCandidates finds open, unacknowledged incidents that have not been escalated
+func (r *IncidentRepository) FindEscalationCandidates(ctx context.Context, startedBefore time.Time, limit int) ([]models.Incident, error) {
+	var incidents []models.Incident
+	err := database.Conn(ctx, r.db).
+		Where("status = ?", models.IncidentStatusOpen).
+		Where("acknowledged_at IS NULL AND escalated_at IS NULL").
+		Where("started_at <= ?", startedBefore).
+		Order("started_at ASC").
+		Limit(limit).
+		Find(&incidents).Error
+	return incidents, err
+}
+
+// MarkEscalated records an escalation, returning false if another instance already escalated it
+func (r *IncidentRepository) MarkEscalated(ctx context.Context, id uuid.UUID) (bool, error) {
+	now := time.Now()
+	result := database.Conn(ctx, r.db).
+		Model(&models.Incident{}).
+		Where("id = ? AND escalated_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"escalated_at": now,
+			"updated_at":   now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Query finds incidents matching the filter
+func (r *IncidentRepository) Query(ctx context.Context, filter models.IncidentFilter) (*models.IncidentListResult, error) {
+	var incidents []models.Incident
+	var total int64
+
+	query := database.Conn(ctx, r.db).Model(&models.Incident{})
+
+	if filter.TenantID != nil {
+		query = query.Where("tenant_id = ?", filter.TenantID)
+	}
+
+	if filter.JobID != nil {
+		query = query.Where("job_id = ?", filter.JobID)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	// Get total count
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	// Apply pagination
+	page := filter.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := filter.PageSize
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("started_at DESC").Offset(offset).Limit(pageSize).Find(&incidents).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.IncidentListResult{
+		Incidents:  incidents,
+		TotalCount: total,
+		Page:       page,
+		PageSize:   pageSize,
+		HasMore:    int64((page)*pageSize) < total,
+	}, nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"gorm.io/gorm"
+)
+
+// JanitorRepository records when tenants were sent their janitor reports
+type JanitorRepository struct {
+	db *gorm.DB
+}
+
+// NewJanitorRepository creates a new janitor repository
+func NewJanitorRepository(db *gorm.DB) *JanitorRepository {
+	return &JanitorRepository{db: db}
+}
+
+// ClaimReport records that a tenant's report is sent now, returning false when it was already
+// sent after dueBefore, e.g. by another instance
+func (r *JanitorRepository) ClaimReport(ctx context.Context, tenantID uuid.UUID, now, dueBefore time.Time) (bool, error) {
+	result := database.Conn(ctx, r.db).Exec(`
+		INSERT INTO janitor_reports (tenant_id, sent_at)
+		VALUES (?, ?)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET sent_at = EXCLUDED.sent_at
+		WHERE janitor_reports.sent_at <= ?`,
+		tenantID, now, dueBefore,
+	)
+	return result.RowsAffected > 0, result.Error
+}
@@ -2,10 +2,13 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
 	"github.com/minisource/scheduler/internal/models"
 	"gorm.io/gorm"
 )
@@ -22,18 +25,18 @@ func NewJobRepository(db *gorm.DB) *JobRepository {
 
 // Create creates a new job
 func (r *JobRepository) Create(ctx context.Context, job *models.Job) error {
-	return r.db.WithContext(ctx).Create(job).Error
+	return database.Conn(ctx, r.db).Create(job).Error
 }
 
 // Update updates a job
 func (r *JobRepository) Update(ctx context.Context, job *models.Job) error {
-	return r.db.WithContext(ctx).Save(job).Error
+	return database.Conn(ctx, r.db).Save(job).Error
 }
 
 // FindByID retrieves a job by ID
 func (r *JobRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Job, error) {
 	var job models.Job
-	err := r.db.WithContext(ctx).First(&job, "id = ?", id).Error
+	err := database.Conn(ctx, r.db).First(&job, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -43,22 +46,172 @@ func (r *JobRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Job
 // FindByTenantAndID retrieves a job by tenant and ID
 func (r *JobRepository) FindByTenantAndID(ctx context.Context, tenantID, id uuid.UUID) (*models.Job, error) {
 	var job models.Job
-	err := r.db.WithContext(ctx).First(&job, "id = ? AND tenant_id = ?", id, tenantID).Error
+	err := database.Conn(ctx, r.db).First(&job, "id = ? AND tenant_id = ?", id, tenantID).Error
 	if err != nil {
 		return nil, err
 	}
 	return &job, nil
 }
 
+// FindByTenant retrieves all of a tenant's jobs that have not been deleted
+func (r *JobRepository) FindByTenant(ctx context.Context, tenantID uuid.UUID) ([]models.Job, error) {
+	var jobs []models.Job
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND status != ?", tenantID, models.JobStatusDeleted).
+		Order("created_at ASC").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// FindOverdueByTenant retrieves a tenant's active jobs whose next run is at or before a time
+func (r *JobRepository) FindOverdueByTenant(ctx context.Context, tenantID uuid.UUID, before time.Time) ([]models.Job, error) {
+	var jobs []models.Job
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND status = ? AND next_run_at <= ?", tenantID, models.JobStatusActive, before).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// FindByTenantAndName retrieves a tenant's oldest non-deleted job with the given name
+func (r *JobRepository) FindByTenantAndName(ctx context.Context, tenantID uuid.UUID, name string) (*models.Job, error) {
+	var job models.Job
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND name = ? AND status != ?", tenantID, name, models.JobStatusDeleted).
+		Order("created_at ASC").
+		First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindByTenantAndClientReference retrieves a job by its tenant-scoped idempotency key
+func (r *JobRepository) FindByTenantAndClientReference(ctx context.Context, tenantID uuid.UUID, clientReference string) (*models.Job, error) {
+	var job models.Job
+	err := database.Conn(ctx, r.db).First(&job, "tenant_id = ? AND client_reference = ?", tenantID, clientReference).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindByTenantAndExternalIDs retrieves a tenant's non-deleted jobs with the given external IDs
+func (r *JobRepository) FindByTenantAndExternalIDs(ctx context.Context, tenantID uuid.UUID, externalIDs []string) ([]models.Job, error) {
+	var jobs []models.Job
+	if len(externalIDs) == 0 {
+		return jobs, nil
+	}
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND external_id IN ? AND status != ?", tenantID, externalIDs, models.JobStatusDeleted).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// FindByExternalID retrieves the non-deleted jobs with an external ID across tenants, or only
+// in the given tenants, ordered by ID
+func (r *JobRepository) FindByExternalID(ctx context.Context, externalID string, tenantIDs []uuid.UUID) ([]models.Job, error) {
+	var jobs []models.Job
+	query := database.Conn(ctx, r.db).
+		Where("external_id = ? AND status != ?", externalID, models.JobStatusDeleted)
+	if len(tenantIDs) > 0 {
+		query = query.Where("tenant_id IN ?", tenantIDs)
+	}
+	err := query.Order("id ASC").Find(&jobs).Error
+	return jobs, err
+}
+
+// Transaction runs fn in a transaction; repository calls made with the context passed to fn
+// take part in it
+func (r *JobRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		return fn(database.WithConn(ctx, tx))
+	})
+}
+
+// FindByTenantAndIDs retrieves a tenant's non-deleted jobs with the given IDs
+func (r *JobRepository) FindByTenantAndIDs(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) ([]models.Job, error) {
+	var jobs []models.Job
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND id IN ? AND status != ?", tenantID, ids, models.JobStatusDeleted).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// FindByTenantAndTag retrieves a tenant's non-deleted jobs carrying a tag
+func (r *JobRepository) FindByTenantAndTag(ctx context.Context, tenantID uuid.UUID, tag string) ([]models.Job, error) {
+	return r.FindByTenantAndTags(ctx, tenantID, []string{tag})
+}
+
+// FindByTenantAndTags retrieves a tenant's non-deleted jobs carrying all of the tags
+func (r *JobRepository) FindByTenantAndTags(ctx context.Context, tenantID uuid.UUID, tags []string) ([]models.Job, error) {
+	selector, err := json.Marshal(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []models.Job
+	err = database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND tags @> ? AND status != ?", tenantID, string(selector), models.JobStatusDeleted).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// FindByShift retrieves the jobs a schedule shift currently applies to
+func (r *JobRepository) FindByShift(ctx context.Context, shiftID uuid.UUID) ([]models.Job, error) {
+	var jobs []models.Job
+	err := database.Conn(ctx, r.db).
+		Where("shift_id = ?", shiftID).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// SetShift applies a schedule shift to a job, or clears it when shift is nil, together with
+// the next run recalculated under it
+func (r *JobRepository) SetShift(ctx context.Context, id uuid.UUID, shift *models.ScheduleShift, nextRunAt *time.Time) error {
+	updates := map[string]interface{}{
+		"shift_id":             nil,
+		"shift_offset_seconds": 0,
+		"shift_starts_at":      nil,
+		"shift_ends_at":        nil,
+		"next_run_at":          nextRunAt,
+		"updated_at":           time.Now(),
+	}
+	if shift != nil {
+		updates["shift_id"] = shift.ID
+		updates["shift_offset_seconds"] = shift.OffsetSeconds
+		updates["shift_starts_at"] = shift.StartsAt
+		updates["shift_ends_at"] = shift.EndsAt
+	}
+
+	return database.Conn(ctx, r.db).
+		Model(&models.Job{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+// SetScheduleAndEndpoint changes a job's schedule and endpoint together with the next run
+// recalculated under them
+func (r *JobRepository) SetScheduleAndEndpoint(ctx context.Context, id uuid.UUID, schedule, endpoint string, nextRunAt *time.Time) error {
+	return database.Conn(ctx, r.db).
+		Model(&models.Job{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"schedule":    schedule,
+			"endpoint":    endpoint,
+			"next_run_at": nextRunAt,
+			"updated_at":  time.Now(),
+		}).Error
+}
+
 // Query finds jobs matching the filter
 func (r *JobRepository) Query(ctx context.Context, filter models.JobFilter) (*models.JobListResult, error) {
 	var jobs []models.Job
-	var total int64
 
-	query := r.buildJobQuery(filter)
+	query := r.buildJobQuery(ctx, filter)
 
 	// Get total count
-	if err := query.Count(&total).Error; err != nil {
+	total, estimated, err := countRows(ctx, query, filter.Count)
+	if err != nil {
 		return nil, err
 	}
 
@@ -72,24 +225,35 @@ func (r *JobRepository) Query(ctx context.Context, filter models.JobFilter) (*mo
 		pageSize = 20
 	}
 
+	if len(filter.Fields) > 0 {
+		query = query.Select(selectColumns(filter.Fields, models.JobFieldColumns))
+	}
+
+	// Fetch one extra row so has_more stays exact when the total is estimated
 	offset := (page - 1) * pageSize
-	err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&jobs).Error
+	err = query.Order("created_at DESC").Offset(offset).Limit(pageSize + 1).Find(&jobs).Error
 	if err != nil {
 		return nil, err
 	}
 
+	hasMore := len(jobs) > pageSize
+	if hasMore {
+		jobs = jobs[:pageSize]
+	}
+
 	return &models.JobListResult{
-		Jobs:       jobs,
-		TotalCount: total,
-		Page:       page,
-		PageSize:   pageSize,
-		HasMore:    int64((page)*pageSize) < total,
+		Jobs:           jobs,
+		TotalCount:     total,
+		TotalEstimated: estimated,
+		Page:           page,
+		PageSize:       pageSize,
+		HasMore:        hasMore,
 	}, nil
 }
 
 // buildJobQuery creates the GORM query from filter
-func (r *JobRepository) buildJobQuery(filter models.JobFilter) *gorm.DB {
-	query := r.db.Model(&models.Job{})
+func (r *JobRepository) buildJobQuery(ctx context.Context, filter models.JobFilter) *gorm.DB {
+	query := database.Conn(ctx, r.db).Model(&models.Job{})
 
 	if filter.TenantID != nil {
 		query = query.Where("tenant_id = ?", filter.TenantID)
@@ -110,33 +274,122 @@ func (r *JobRepository) buildJobQuery(filter models.JobFilter) *gorm.DB {
 		query = query.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(filter.Name)+"%")
 	}
 
+	if filter.CorrelationID != "" {
+		query = query.Where("correlation_id = ?", filter.CorrelationID)
+	}
+
 	return query
 }
 
+// selectColumns returns the columns behind a set of JSON field names, skipping unknown ones
+func selectColumns(fields []string, columns map[string]string) []string {
+	selected := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if column, ok := columns[field]; ok {
+			selected = append(selected, column)
+		}
+	}
+	return selected
+}
+
 // FindActiveJobs finds all active jobs
 func (r *JobRepository) FindActiveJobs(ctx context.Context) ([]models.Job, error) {
 	var jobs []models.Job
-	err := r.db.WithContext(ctx).
+	err := database.Conn(ctx, r.db).
 		Where("status = ?", models.JobStatusActive).
 		Find(&jobs).Error
 	return jobs, err
 }
 
-// FindJobsDueForExecution finds jobs that are due to run
-func (r *JobRepository) FindJobsDueForExecution(ctx context.Context, before time.Time, limit int) ([]models.Job, error) {
+// FindJobsDueForExecution finds jobs that are due to run, leaving out those of paused and
+// excluded tenants
+func (r *JobRepository) FindJobsDueForExecution(ctx context.Context, before time.Time, excluded []uuid.UUID, limit int) ([]models.Job, error) {
 	var jobs []models.Job
-	err := r.db.WithContext(ctx).
+	query := database.Conn(ctx, r.db).
 		Where("status = ?", models.JobStatusActive).
 		Where("next_run_at <= ?", before).
+		Where(notPaused)
+	if len(excluded) > 0 {
+		query = query.Where("tenant_id NOT IN ?", excluded)
+	}
+	err := query.
 		Order("priority DESC, next_run_at ASC").
 		Limit(limit).
 		Find(&jobs).Error
 	return jobs, err
 }
 
+// ClaimDueJobs claims up to limit due jobs for an instance until lease has passed, highest
+// priority first. Jobs claimed by other instances are skipped, as are rows another instance
+// is claiming at the same moment, so concurrent instances claim disjoint sets. A claim that
+// is never released, e.g. because its instance died, lapses after the lease. With tenants
+// set, only those tenants' jobs are claimed. Paused tenants' jobs aren't claimed.
+func (r *JobRepository) ClaimDueJobs(ctx context.Context, owner string, before time.Time, lease time.Duration, tenants []uuid.UUID, limit int) ([]models.Job, error) {
+	var jobs []models.Job
+	now := time.Now()
+	scope, args := "", []interface{}{owner, now.Add(lease), models.JobStatusActive, before, now}
+	if len(tenants) > 0 {
+		scope = "AND tenant_id IN ?"
+		args = append(args, tenants)
+	}
+	err := database.Conn(ctx, r.db).Raw(`
+		UPDATE jobs SET claimed_by = ?, claimed_until = ?
+		WHERE id IN (
+			SELECT id FROM jobs
+			WHERE status = ? AND next_run_at <= ? AND (claimed_until IS NULL OR claimed_until < ?) AND `+notPaused+` `+scope+`
+			ORDER BY priority DESC, next_run_at ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		append(args, limit)...,
+	).Scan(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING doesn't keep the subquery's order
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if jobs[i].Priority != jobs[j].Priority {
+			return jobs[i].Priority > jobs[j].Priority
+		}
+		return jobs[i].NextRunAt.Before(*jobs[j].NextRunAt)
+	})
+	return jobs, nil
+}
+
+// FindClaimableJobs finds the due jobs ClaimDueJobs would claim, without claiming them. With
+// tenants set, only those tenants' jobs are found; the excluded tenants' jobs are left out.
+func (r *JobRepository) FindClaimableJobs(ctx context.Context, before time.Time, tenants, excluded []uuid.UUID, limit int) ([]models.Job, error) {
+	var jobs []models.Job
+	query := database.Conn(ctx, r.db).
+		Where("status = ? AND next_run_at <= ?", models.JobStatusActive, before).
+		Where("claimed_until IS NULL OR claimed_until < ?", time.Now()).
+		Where(notPaused)
+	if len(tenants) > 0 {
+		query = query.Where("tenant_id IN ?", tenants)
+	}
+	if len(excluded) > 0 {
+		query = query.Where("tenant_id NOT IN ?", excluded)
+	}
+	err := query.
+		Order("priority DESC, next_run_at ASC").
+		Limit(limit).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// ReleaseClaims releases an instance's claims on jobs
+func (r *JobRepository) ReleaseClaims(ctx context.Context, owner string, ids []uuid.UUID) error {
+	return database.Conn(ctx, r.db).Exec(
+		"UPDATE jobs SET claimed_by = NULL, claimed_until = NULL WHERE id IN ? AND claimed_by = ?",
+		ids, owner,
+	).Error
+}
+
 // UpdateNextRunAt updates the next run time for a job
 func (r *JobRepository) UpdateNextRunAt(ctx context.Context, id uuid.UUID, nextRunAt time.Time) error {
-	return r.db.WithContext(ctx).
+	return database.Conn(ctx, r.db).
 		Model(&models.Job{}).
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
@@ -145,6 +398,29 @@ func (r *JobRepository) UpdateNextRunAt(ctx context.Context, id uuid.UUID, nextR
 		}).Error
 }
 
+// ClearNextRunAt removes a job's next run time once it has nothing left to run
+func (r *JobRepository) ClearNextRunAt(ctx context.Context, id uuid.UUID) error {
+	return database.Conn(ctx, r.db).
+		Model(&models.Job{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"next_run_at": nil,
+			"updated_at":  time.Now(),
+		}).Error
+}
+
+// MarkCompleted moves a one-time job with no pending run to the completed status
+func (r *JobRepository) MarkCompleted(ctx context.Context, id uuid.UUID) error {
+	return database.Conn(ctx, r.db).
+		Model(&models.Job{}).
+		Where("id = ? AND next_run_at IS NULL", id).
+		Where("status IN ?", []models.JobStatus{models.JobStatusActive, models.JobStatusPaused}).
+		Updates(map[string]interface{}{
+			"status":     models.JobStatusCompleted,
+			"updated_at": time.Now(),
+		}).Error
+}
+
 // UpdateLastRunAt updates the last run time and counters
 func (r *JobRepository) UpdateLastRunAt(ctx context.Context, id uuid.UUID, success bool) error {
 	updates := map[string]interface{}{
@@ -154,30 +430,224 @@ func (r *JobRepository) UpdateLastRunAt(ctx context.Context, id uuid.UUID, succe
 
 	if success {
 		updates["run_count"] = gorm.Expr("run_count + 1")
+		updates["consecutive_failures"] = 0
 	} else {
 		updates["fail_count"] = gorm.Expr("fail_count + 1")
+		updates["consecutive_failures"] = gorm.Expr("consecutive_failures + 1")
 	}
 
-	return r.db.WithContext(ctx).
+	return database.Conn(ctx, r.db).
 		Model(&models.Job{}).
 		Where("id = ?", id).
 		Updates(updates).Error
 }
 
+// TrackNotFound records whether a job's endpoint answered 404, keeping the time of the first
+// 404 in a row
+func (r *JobRepository) TrackNotFound(ctx context.Context, id uuid.UUID, notFound bool, at time.Time) error {
+	var since interface{}
+	if notFound {
+		since = gorm.Expr("COALESCE(not_found_since, ?)", at)
+	}
+	return database.Conn(ctx, r.db).
+		Model(&models.Job{}).
+		Where("id = ?", id).
+		UpdateColumn("not_found_since", since).Error
+}
+
+// FindStaleCandidates retrieves a tenant's jobs that may be stale: active recurring jobs that
+// haven't run since runBefore, jobs whose endpoint has answered 404 since notFoundBefore and
+// paused jobs unchanged since pausedBefore
+func (r *JobRepository) FindStaleCandidates(ctx context.Context, tenantID uuid.UUID, runBefore, notFoundBefore, pausedBefore time.Time) ([]models.Job, error) {
+	var jobs []models.Job
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND status != ?", tenantID, models.JobStatusDeleted).
+		Where(
+			"(status = ? AND type IN ? AND COALESCE(last_run_at, created_at) <= ?) OR not_found_since <= ? OR (status = ? AND updated_at <= ?)",
+			models.JobStatusActive, []models.JobType{models.JobTypeCron, models.JobTypeInterval}, runBefore,
+			notFoundBefore,
+			models.JobStatusPaused, pausedBefore,
+		).
+		Order("name ASC").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// FindTenantIDs retrieves the tenants that have jobs that haven't been deleted
+func (r *JobRepository) FindTenantIDs(ctx context.Context) ([]uuid.UUID, error) {
+	var tenantIDs []uuid.UUID
+	err := database.Conn(ctx, r.db).
+		Model(&models.Job{}).
+		Where("status != ?", models.JobStatusDeleted).
+		Distinct("tenant_id").
+		Pluck("tenant_id", &tenantIDs).Error
+	return tenantIDs, err
+}
+
+// DisableEnded disables an active recurring job whose end time has passed or that has had
+// its maximum number of successful runs. It returns false when the job hasn't ended.
+func (r *JobRepository) DisableEnded(ctx context.Context, id uuid.UUID, now time.Time) (bool, error) {
+	result := database.Conn(ctx, r.db).
+		Model(&models.Job{}).
+		Where("id = ? AND status = ?", id, models.JobStatusActive).
+		Where("(end_at IS NOT NULL AND end_at <= ?) OR (max_runs > 0 AND run_count >= max_runs)", now).
+		Updates(map[string]interface{}{
+			"status":      models.JobStatusDisabled,
+			"next_run_at": nil,
+			"updated_at":  time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
 // UpdateStatus updates job status
 func (r *JobRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status models.JobStatus) error {
-	return r.db.WithContext(ctx).
+	return database.Conn(ctx, r.db).
 		Model(&models.Job{}).
 		Where("id = ?", id).
 		Update("status", status).Error
 }
 
-// Delete soft-deletes a job
-func (r *JobRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).
+// Delete soft-deletes a job and schedules its purge
+func (r *JobRepository) Delete(ctx context.Context, id uuid.UUID, purgeAt time.Time) error {
+	now := time.Now()
+	return database.Conn(ctx, r.db).
 		Model(&models.Job{}).
 		Where("id = ?", id).
-		Update("status", models.JobStatusDeleted).Error
+		Updates(map[string]interface{}{
+			"status":      models.JobStatusDeleted,
+			"deleted_at":  now,
+			"purge_at":    purgeAt,
+			"next_run_at": nil,
+			"updated_at":  now,
+		}).Error
+}
+
+// Restore undeletes a deleted job that has not been purged yet, leaving it paused. It
+// returns false when the job isn't deleted.
+func (r *JobRepository) Restore(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := database.Conn(ctx, r.db).
+		Model(&models.Job{}).
+		Where("id = ? AND status = ?", id, models.JobStatusDeleted).
+		Updates(map[string]interface{}{
+			"status":     models.JobStatusPaused,
+			"deleted_at": nil,
+			"purge_at":   nil,
+			"resume_at":  nil,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// CountByCalendar counts a tenant's jobs, other than deleted ones, that reference a calendar
+// as their blackout or holiday calendar
+func (r *JobRepository) CountByCalendar(ctx context.Context, tenantID uuid.UUID, name string) (int64, error) {
+	var count int64
+	err := database.Conn(ctx, r.db).
+		Model(&models.Job{}).
+		Where("tenant_id = ? AND (blackout_calendar = ? OR holiday_calendar = ?) AND status <> ?", tenantID, name, name, models.JobStatusDeleted).
+		Count(&count).Error
+	return count, err
+}
+
+// FindByHolidayCalendar finds a tenant's active and paused jobs that follow a holiday calendar
+func (r *JobRepository) FindByHolidayCalendar(ctx context.Context, tenantID uuid.UUID, name string) ([]models.Job, error) {
+	var jobs []models.Job
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND holiday_calendar = ?", tenantID, name).
+		Where("status IN ?", []models.JobStatus{models.JobStatusActive, models.JobStatusPaused}).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// FindDueForResume finds up to limit paused jobs whose resume time has passed
+func (r *JobRepository) FindDueForResume(ctx context.Context, before time.Time, limit int) ([]models.Job, error) {
+	var jobs []models.Job
+	err := database.Conn(ctx, r.db).
+		Where("status = ? AND resume_at <= ?", models.JobStatusPaused, before).
+		Order("resume_at ASC").
+		Limit(limit).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// ResumeDue resumes a paused job whose resume time has passed. It returns false when the job
+// was resumed, paused again or changed otherwise in the meantime.
+func (r *JobRepository) ResumeDue(ctx context.Context, id uuid.UUID, before time.Time) (bool, error) {
+	result := database.Conn(ctx, r.db).
+		Model(&models.Job{}).
+		Where("id = ? AND status = ? AND resume_at <= ?", id, models.JobStatusPaused, before).
+		Updates(map[string]interface{}{
+			"status":     models.JobStatusActive,
+			"resume_at":  nil,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// HoldForCanary puts a job on hold for a canary execution, so it isn't dispatched until the
+// canary passes or is confirmed
+func (r *JobRepository) HoldForCanary(ctx context.Context, id, executionID uuid.UUID) error {
+	return database.Conn(ctx, r.db).
+		Model(&models.Job{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":              models.JobStatusCanary,
+			"canary_execution_id": executionID,
+			"resume_at":           nil,
+			"updated_at":          time.Now(),
+		}).Error
+}
+
+// ActivateCanary starts the schedule of a job held for its canary from its next run. With
+// executionID set, the job is only activated while that execution is still its canary. It
+// returns false when the job was activated, paused or given another canary meanwhile.
+func (r *JobRepository) ActivateCanary(ctx context.Context, id uuid.UUID, executionID *uuid.UUID, nextRunAt *time.Time) (bool, error) {
+	query := database.Conn(ctx, r.db).
+		Model(&models.Job{}).
+		Where("id = ? AND status = ?", id, models.JobStatusCanary)
+	if executionID != nil {
+		query = query.Where("canary_execution_id = ?", *executionID)
+	}
+	result := query.Updates(map[string]interface{}{
+		"status":      models.JobStatusActive,
+		"next_run_at": nextRunAt,
+		"updated_at":  time.Now(),
+	})
+	return result.RowsAffected > 0, result.Error
+}
+
+// PurgeDeleted permanently removes up to limit deleted jobs whose purge time has passed,
+// with their executions, history and revisions. Jobs are locked while they are purged, so a
+// concurrent restore either wins or finds the job gone, and concurrent purges skip each
+// other's jobs.
+func (r *JobRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	var purged int64
+	err := database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var ids []uuid.UUID
+		if err := tx.Raw(
+			"SELECT id FROM jobs WHERE status = ? AND purge_at <= ? ORDER BY purge_at LIMIT ? FOR UPDATE SKIP LOCKED",
+			models.JobStatusDeleted, before, limit,
+		).Scan(&ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := tx.Where("job_id IN ?", ids).Delete(&models.JobExecution{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("job_id IN ?", ids).Delete(&models.JobHistory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("job_id IN ?", ids).Delete(&models.JobRevision{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id IN ?", ids).Delete(&models.Job{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
 }
 
 // GetStats retrieves job statistics
@@ -187,7 +657,7 @@ func (r *JobRepository) GetStats(ctx context.Context, tenantID *uuid.UUID) (*mod
 		JobsByStatus: make(map[models.JobStatus]int64),
 	}
 
-	query := r.db.WithContext(ctx).Model(&models.Job{})
+	query := database.Conn(ctx, r.db).Model(&models.Job{})
 	if tenantID != nil {
 		query = query.Where("tenant_id = ?", tenantID)
 	}
@@ -196,17 +666,17 @@ func (r *JobRepository) GetStats(ctx context.Context, tenantID *uuid.UUID) (*mod
 	query.Where("status != ?", models.JobStatusDeleted).Count(&stats.TotalJobs)
 
 	// Active jobs
-	r.db.Model(&models.Job{}).Where("status = ?", models.JobStatusActive).Count(&stats.ActiveJobs)
+	database.Conn(ctx, r.db).Model(&models.Job{}).Where("status = ?", models.JobStatusActive).Count(&stats.ActiveJobs)
 
 	// Paused jobs
-	r.db.Model(&models.Job{}).Where("status = ?", models.JobStatusPaused).Count(&stats.PausedJobs)
+	database.Conn(ctx, r.db).Model(&models.Job{}).Where("status = ?", models.JobStatusPaused).Count(&stats.PausedJobs)
 
 	// Jobs by type
 	var typeResults []struct {
 		Type  models.JobType
 		Count int64
 	}
-	r.db.Model(&models.Job{}).
+	database.Conn(ctx, r.db).Model(&models.Job{}).
 		Select("type, COUNT(*) as count").
 		Where("status != ?", models.JobStatusDeleted).
 		Group("type").Scan(&typeResults)
@@ -220,7 +690,7 @@ func (r *JobRepository) GetStats(ctx context.Context, tenantID *uuid.UUID) (*mod
 		Status models.JobStatus
 		Count  int64
 	}
-	r.db.Model(&models.Job{}).
+	database.Conn(ctx, r.db).Model(&models.Job{}).
 		Select("status, COUNT(*) as count").
 		Group("status").Scan(&statusResults)
 
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// JobRevisionRepository handles job revision persistence
+type JobRevisionRepository struct {
+	db *gorm.DB
+}
+
+// NewJobRevisionRepository creates a new job revision repository
+func NewJobRevisionRepository(db *gorm.DB) *JobRevisionRepository {
+	return &JobRevisionRepository{db: db}
+}
+
+// Create appends a revision, numbering it after the job's latest one and recording the fields
+// changed since that one in its diff
+func (r *JobRevisionRepository) Create(ctx context.Context, revision *models.JobRevision) error {
+	if revision.ID == uuid.Nil {
+		revision.ID = uuid.New()
+	}
+	if revision.CreatedAt.IsZero() {
+		revision.CreatedAt = time.Now()
+	}
+
+	var previous models.JobRevision
+	err := database.Conn(ctx, r.db).
+		Where("job_id = ?", revision.JobID).
+		Order("revision DESC").
+		Limit(1).
+		Find(&previous).Error
+	if err != nil {
+		return err
+	}
+	var diff interface{}
+	if previous.ID != uuid.Nil {
+		changes, err := models.DiffJobSnapshots(previous.Snapshot, revision.Snapshot)
+		if err != nil {
+			return err
+		}
+		if revision.Diff, err = json.Marshal(changes); err != nil {
+			return err
+		}
+		diff = string(revision.Diff)
+	}
+
+	return database.Conn(ctx, r.db).Exec(`INSERT INTO job_revisions (id, job_id, tenant_id, revision, change, snapshot, changed_by, diff, created_at)
+		SELECT ?, ?, ?, COALESCE(MAX(revision), 0) + 1, ?, ?, ?, ?, ? FROM job_revisions WHERE job_id = ?`,
+		revision.ID, revision.JobID, revision.TenantID, revision.Change, string(revision.Snapshot), revision.ChangedBy, diff, revision.CreatedAt, revision.JobID,
+	).Error
+}
+
+// FindByJob lists the revisions of a tenant's job, newest first
+func (r *JobRevisionRepository) FindByJob(ctx context.Context, tenantID, jobID uuid.UUID, limit int) ([]models.JobRevision, error) {
+	var revisions []models.JobRevision
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND job_id = ?", tenantID, jobID).
+		Order("revision DESC").
+		Limit(limit).
+		Find(&revisions).Error
+	return revisions, err
+}
+
+// FindByRevision retrieves a revision of a tenant's job by its number
+func (r *JobRevisionRepository) FindByRevision(ctx context.Context, tenantID, jobID uuid.UUID, number int) (*models.JobRevision, error) {
+	var revision models.JobRevision
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND job_id = ? AND revision = ?", tenantID, jobID, number).
+		First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// FindAsOf retrieves the revision of a tenant's job that was current at the given time
+func (r *JobRevisionRepository) FindAsOf(ctx context.Context, tenantID, jobID uuid.UUID, at time.Time) (*models.JobRevision, error) {
+	var revision models.JobRevision
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND job_id = ? AND created_at <= ?", tenantID, jobID, at).
+		Order("revision DESC").
+		First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
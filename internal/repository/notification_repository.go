@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// NotificationRepository handles notification channel and policy persistence
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// CreateChannel creates a notification channel
+func (r *NotificationRepository) CreateChannel(ctx context.Context, channel *models.NotificationChannel) error {
+	return database.Conn(ctx, r.db).Create(channel).Error
+}
+
+// UpdateChannel updates a notification channel
+func (r *NotificationRepository) UpdateChannel(ctx context.Context, channel *models.NotificationChannel) error {
+	return database.Conn(ctx, r.db).Save(channel).Error
+}
+
+// DeleteChannel deletes a tenant's notification channel
+func (r *NotificationRepository) DeleteChannel(ctx context.Context, tenantID, id uuid.UUID) (int64, error) {
+	result := database.Conn(ctx, r.db).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		Delete(&models.NotificationChannel{})
+	return result.RowsAffected, result.Error
+}
+
+// FindChannelByTenantAndID retrieves a notification channel by tenant and ID
+func (r *NotificationRepository) FindChannelByTenantAndID(ctx context.Context, tenantID, id uuid.UUID) (*models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	err := database.Conn(ctx, r.db).First(&channel, "id = ? AND tenant_id = ?", id, tenantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+// FindChannelsByTenant retrieves all notification channels for a tenant
+func (r *NotificationRepository) FindChannelsByTenant(ctx context.Context, tenantID uuid.UUID) ([]models.NotificationChannel, error) {
+	var channels []models.NotificationChannel
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		Order("created_at ASC").
+		Find(&channels).Error
+	return channels, err
+}
+
+// FindEnabledChannels retrieves the enabled notification channels for a tenant
+func (r *NotificationRepository) FindEnabledChannels(ctx context.Context, tenantID uuid.UUID) ([]models.NotificationChannel, error) {
+	var channels []models.NotificationChannel
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND enabled = ?", tenantID, true).
+		Find(&channels).Error
+	return channels, err
+}
+
+// CreateSubscription creates a notification subscription
+func (r *NotificationRepository) CreateSubscription(ctx context.Context, subscription *models.NotificationSubscription) error {
+	return database.Conn(ctx, r.db).Create(subscription).Error
+}
+
+// DeleteSubscription deletes a user's notification subscription
+func (r *NotificationRepository) DeleteSubscription(ctx context.Context, tenantID, userID, id uuid.UUID) (int64, error) {
+	result := database.Conn(ctx, r.db).
+		Where("id = ? AND tenant_id = ? AND user_id = ?", id, tenantID, userID).
+		Delete(&models.NotificationSubscription{})
+	return result.RowsAffected, result.Error
+}
+
+// FindSubscriptionsByUser retrieves a user's notification subscriptions
+func (r *NotificationRepository) FindSubscriptionsByUser(ctx context.Context, tenantID, userID uuid.UUID) ([]models.NotificationSubscription, error) {
+	var subscriptions []models.NotificationSubscription
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND user_id = ?", tenantID, userID).
+		Order("created_at ASC").
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// FindSubscriptionsByChannels retrieves the subscriptions routed to the given channels
+func (r *NotificationRepository) FindSubscriptionsByChannels(ctx context.Context, tenantID uuid.UUID, channelIDs []uuid.UUID) ([]models.NotificationSubscription, error) {
+	var subscriptions []models.NotificationSubscription
+	if len(channelIDs) == 0 {
+		return subscriptions, nil
+	}
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND channel_id IN ?", tenantID, channelIDs).
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// FindPolicy retrieves a tenant's notification policy
+func (r *NotificationRepository) FindPolicy(ctx context.Context, tenantID uuid.UUID) (*models.NotificationPolicy, error) {
+	var policy models.NotificationPolicy
+	err := database.Conn(ctx, r.db).First(&policy, "tenant_id = ?", tenantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// SavePolicy creates or updates a tenant's notification policy
+func (r *NotificationRepository) SavePolicy(ctx context.Context, policy *models.NotificationPolicy) error {
+	return database.Conn(ctx, r.db).Save(policy).Error
+}
+
+// FindTenantsWithEscalation lists policies that have escalation enabled
+func (r *NotificationRepository) FindTenantsWithEscalation(ctx context.Context) ([]models.NotificationPolicy, error) {
+	var policies []models.NotificationPolicy
+	err := database.Conn(ctx, r.db).
+		Where("escalate_after_minutes > 0").
+		Find(&policies).Error
+	return policies, err
+}
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// RolloutRepository handles rollout persistence
+type RolloutRepository struct {
+	db *gorm.DB
+}
+
+// NewRolloutRepository creates a new rollout repository
+func NewRolloutRepository(db *gorm.DB) *RolloutRepository {
+	return &RolloutRepository{db: db}
+}
+
+// Create creates a rollout together with its jobs
+func (r *RolloutRepository) Create(ctx context.Context, rollout *models.Rollout, jobs []models.RolloutJob) error {
+	return database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(rollout).Error; err != nil {
+			return err
+		}
+		return tx.CreateInBatches(jobs, 500).Error
+	})
+}
+
+// FindByID retrieves a rollout by ID
+func (r *RolloutRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Rollout, error) {
+	var rollout models.Rollout
+	err := database.Conn(ctx, r.db).First(&rollout, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rollout, nil
+}
+
+// List retrieves rollouts, newest first, optionally only those in a status
+func (r *RolloutRepository) List(ctx context.Context, status models.RolloutStatus) ([]models.Rollout, error) {
+	var rollouts []models.Rollout
+	query := database.Conn(ctx, r.db).Order("created_at DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&rollouts).Error
+	return rollouts, err
+}
+
+// FindJobs retrieves a rollout's jobs in the order they are changed
+func (r *RolloutRepository) FindJobs(ctx context.Context, rolloutID uuid.UUID) ([]models.RolloutJob, error) {
+	var jobs []models.RolloutJob
+	err := database.Conn(ctx, r.db).
+		Where("rollout_id = ?", rolloutID).
+		Order("position ASC").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// FindPending retrieves up to limit of a rollout's jobs that haven't been changed yet, in order
+func (r *RolloutRepository) FindPending(ctx context.Context, rolloutID uuid.UUID, limit int) ([]models.RolloutJob, error) {
+	var jobs []models.RolloutJob
+	err := database.Conn(ctx, r.db).
+		Where("rollout_id = ? AND applied_at IS NULL", rolloutID).
+		Order("position ASC").
+		Limit(limit).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// FindApplied retrieves a rollout's changed jobs that haven't been reverted
+func (r *RolloutRepository) FindApplied(ctx context.Context, rolloutID uuid.UUID) ([]models.RolloutJob, error) {
+	var jobs []models.RolloutJob
+	err := database.Conn(ctx, r.db).
+		Where("rollout_id = ? AND applied_at IS NOT NULL AND NOT skipped AND reverted_at IS NULL", rolloutID).
+		Order("position ASC").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// FindOpenJobIDs returns which of the jobs are in a rollout that is active or halted
+func (r *RolloutRepository) FindOpenJobIDs(ctx context.Context, jobIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if len(jobIDs) == 0 {
+		return ids, nil
+	}
+	err := database.Conn(ctx, r.db).
+		Model(&models.RolloutJob{}).
+		Joins("JOIN rollouts ON rollouts.id = rollout_jobs.rollout_id").
+		Where("rollout_jobs.job_id IN ? AND rollouts.status IN ?", jobIDs,
+			[]models.RolloutStatus{models.RolloutActive, models.RolloutHalted}).
+		Pluck("rollout_jobs.job_id", &ids).Error
+	return ids, err
+}
+
+// MarkApplied records that a rollout job was changed, with the schedule and endpoint it had
+// before, or skipped
+func (r *RolloutRepository) MarkApplied(ctx context.Context, job *models.RolloutJob) error {
+	return database.Conn(ctx, r.db).
+		Model(&models.RolloutJob{}).
+		Where("rollout_id = ? AND job_id = ?", job.RolloutID, job.JobID).
+		Updates(map[string]interface{}{
+			"previous_schedule": job.PreviousSchedule,
+			"previous_endpoint": job.PreviousEndpoint,
+			"applied_at":        job.AppliedAt,
+			"skipped":           job.Skipped,
+		}).Error
+}
+
+// MarkReverted records that a rollout job was put back on its previous schedule and endpoint
+func (r *RolloutRepository) MarkReverted(ctx context.Context, rolloutID, jobID uuid.UUID, at time.Time) error {
+	return database.Conn(ctx, r.db).
+		Model(&models.RolloutJob{}).
+		Where("rollout_id = ? AND job_id = ?", rolloutID, jobID).
+		Update("reverted_at", at).Error
+}
+
+// FindDue retrieves active rollouts whose next batch is due
+func (r *RolloutRepository) FindDue(ctx context.Context, before time.Time, limit int) ([]models.Rollout, error) {
+	var rollouts []models.Rollout
+	err := database.Conn(ctx, r.db).
+		Where("status = ? AND next_batch_at <= ?", models.RolloutActive, before).
+		Order("next_batch_at ASC").
+		Limit(limit).
+		Find(&rollouts).Error
+	return rollouts, err
+}
+
+// Advance records a batch of a rollout: how many of its jobs are changed so far and when the
+// next batch is due. It returns false when the rollout is no longer active.
+func (r *RolloutRepository) Advance(ctx context.Context, id uuid.UUID, applied int, nextBatchAt time.Time) (bool, error) {
+	result := database.Conn(ctx, r.db).
+		Model(&models.Rollout{}).
+		Where("id = ? AND status = ?", id, models.RolloutActive).
+		Updates(map[string]interface{}{
+			"applied_jobs":  applied,
+			"next_batch_at": nextBatchAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Halt stops an active rollout. It returns false when the rollout wasn't active.
+func (r *RolloutRepository) Halt(ctx context.Context, id uuid.UUID, reason string) (bool, error) {
+	result := database.Conn(ctx, r.db).
+		Model(&models.Rollout{}).
+		Where("id = ? AND status = ?", id, models.RolloutActive).
+		Updates(map[string]interface{}{
+			"status":        models.RolloutHalted,
+			"halt_reason":   reason,
+			"halted_at":     time.Now(),
+			"next_batch_at": nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Resume starts a halted rollout again, judging only executions from now on. It returns
+// false when the rollout wasn't halted.
+func (r *RolloutRepository) Resume(ctx context.Context, id uuid.UUID) (bool, error) {
+	now := time.Now()
+	result := database.Conn(ctx, r.db).
+		Model(&models.Rollout{}).
+		Where("id = ? AND status = ?", id, models.RolloutHalted).
+		Updates(map[string]interface{}{
+			"status":        models.RolloutActive,
+			"halt_reason":   "",
+			"halted_at":     nil,
+			"health_since":  now,
+			"next_batch_at": now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Complete marks an active rollout completed. It returns false when the rollout wasn't active.
+func (r *RolloutRepository) Complete(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := database.Conn(ctx, r.db).
+		Model(&models.Rollout{}).
+		Where("id = ? AND status = ?", id, models.RolloutActive).
+		Updates(map[string]interface{}{
+			"status":        models.RolloutCompleted,
+			"completed_at":  time.Now(),
+			"next_batch_at": nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkRolledBack marks a rollout rolled back. It returns false when it already was.
+func (r *RolloutRepository) MarkRolledBack(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := database.Conn(ctx, r.db).
+		Model(&models.Rollout{}).
+		Where("id = ? AND status != ?", id, models.RolloutRolledBack).
+		Updates(map[string]interface{}{
+			"status":         models.RolloutRolledBack,
+			"rolled_back_at": time.Now(),
+			"next_batch_at":  nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Health counts the finished executions of a rollout's changed jobs that started after the
+// job was changed and after since, and how many of them failed. Canaries and cancelled
+// executions aren't counted.
+func (r *RolloutRepository) Health(ctx context.Context, rolloutID uuid.UUID, since time.Time) (*models.RolloutHealth, error) {
+	var health models.RolloutHealth
+	err := database.Conn(ctx, r.db).Raw(`
+		SELECT COUNT(*) AS executions,
+		       COUNT(*) FILTER (WHERE e.status IN (?, ?)) AS failures
+		FROM rollout_jobs rj
+		JOIN job_executions e ON e.job_id = rj.job_id
+		WHERE rj.rollout_id = ? AND rj.applied_at IS NOT NULL AND NOT rj.skipped AND rj.reverted_at IS NULL
+		  AND e.created_at >= GREATEST(rj.applied_at, ?)
+		  AND e.status IN (?, ?, ?) AND NOT e.canary`,
+		models.ExecutionStatusFailed, models.ExecutionStatusTimeout,
+		rolloutID, since,
+		models.ExecutionStatusCompleted, models.ExecutionStatusFailed, models.ExecutionStatusTimeout,
+	).Scan(&health).Error
+	if err != nil {
+		return nil, err
+	}
+	if health.Executions > 0 {
+		health.FailureRate = float64(health.Failures) / float64(health.Executions)
+	}
+	return &health, nil
+}
+
+// Baseline counts the jobs' finished executions created between from and until, and how
+// many of them failed, to compare a rollout's changed jobs with
+func (r *RolloutRepository) Baseline(ctx context.Context, jobIDs []uuid.UUID, from, until time.Time) (*models.RolloutHealth, error) {
+	var health models.RolloutHealth
+	if len(jobIDs) == 0 {
+		return &health, nil
+	}
+	err := database.Conn(ctx, r.db).Raw(`
+		SELECT COUNT(*) AS executions,
+		       COUNT(*) FILTER (WHERE status IN (?, ?)) AS failures
+		FROM job_executions
+		WHERE job_id IN ? AND created_at >= ? AND created_at < ?
+		  AND status IN (?, ?, ?) AND NOT canary`,
+		models.ExecutionStatusFailed, models.ExecutionStatusTimeout,
+		jobIDs, from, until,
+		models.ExecutionStatusCompleted, models.ExecutionStatusFailed, models.ExecutionStatusTimeout,
+	).Scan(&health).Error
+	if err != nil {
+		return nil, err
+	}
+	if health.Executions > 0 {
+		health.FailureRate = float64(health.Failures) / float64(health.Executions)
+	}
+	return &health, nil
+}
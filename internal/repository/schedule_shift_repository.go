@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// ScheduleShiftRepository handles schedule shift persistence
+type ScheduleShiftRepository struct {
+	db *gorm.DB
+}
+
+// NewScheduleShiftRepository creates a new schedule shift repository
+func NewScheduleShiftRepository(db *gorm.DB) *ScheduleShiftRepository {
+	return &ScheduleShiftRepository{db: db}
+}
+
+// Create creates a schedule shift
+func (r *ScheduleShiftRepository) Create(ctx context.Context, shift *models.ScheduleShift) error {
+	return database.Conn(ctx, r.db).Create(shift).Error
+}
+
+// FindByTenantAndID retrieves a schedule shift by tenant and ID
+func (r *ScheduleShiftRepository) FindByTenantAndID(ctx context.Context, tenantID, id uuid.UUID) (*models.ScheduleShift, error) {
+	var shift models.ScheduleShift
+	err := database.Conn(ctx, r.db).First(&shift, "id = ? AND tenant_id = ?", id, tenantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &shift, nil
+}
+
+// FindByTenant retrieves a tenant's schedule shifts, newest first
+func (r *ScheduleShiftRepository) FindByTenant(ctx context.Context, tenantID uuid.UUID) ([]models.ScheduleShift, error) {
+	var shifts []models.ScheduleShift
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		Order("created_at DESC").
+		Find(&shifts).Error
+	return shifts, err
+}
+
+// FindDueForRevert retrieves active shifts whose moved occurrences have all passed
+func (r *ScheduleShiftRepository) FindDueForRevert(ctx context.Context, before time.Time, limit int) ([]models.ScheduleShift, error) {
+	var shifts []models.ScheduleShift
+	err := database.Conn(ctx, r.db).
+		Where("status = ? AND revert_at <= ?", models.ScheduleShiftActive, before).
+		Order("revert_at ASC").
+		Limit(limit).
+		Find(&shifts).Error
+	return shifts, err
+}
+
+// MarkReverted moves an active shift to reverted. It returns false when the shift was
+// already reverted.
+func (r *ScheduleShiftRepository) MarkReverted(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := database.Conn(ctx, r.db).
+		Model(&models.ScheduleShift{}).
+		Where("id = ? AND status = ?", id, models.ScheduleShiftActive).
+		Updates(map[string]interface{}{
+			"status":      models.ScheduleShiftReverted,
+			"reverted_at": time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
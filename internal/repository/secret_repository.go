@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// SecretRepository handles secret persistence
+type SecretRepository struct {
+	db *gorm.DB
+}
+
+// NewSecretRepository creates a new secret repository
+func NewSecretRepository(db *gorm.DB) *SecretRepository {
+	return &SecretRepository{db: db}
+}
+
+// Create creates a secret
+func (r *SecretRepository) Create(ctx context.Context, secret *models.Secret) error {
+	return database.Conn(ctx, r.db).Create(secret).Error
+}
+
+// Update updates a secret
+func (r *SecretRepository) Update(ctx context.Context, secret *models.Secret) error {
+	return database.Conn(ctx, r.db).Save(secret).Error
+}
+
+// Delete deletes a tenant's secret by name
+func (r *SecretRepository) Delete(ctx context.Context, tenantID uuid.UUID, name string) (int64, error) {
+	result := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND name = ?", tenantID, name).
+		Delete(&models.Secret{})
+	return result.RowsAffected, result.Error
+}
+
+// FindByTenantAndName retrieves a secret by tenant and name
+func (r *SecretRepository) FindByTenantAndName(ctx context.Context, tenantID uuid.UUID, name string) (*models.Secret, error) {
+	var secret models.Secret
+	err := database.Conn(ctx, r.db).First(&secret, "tenant_id = ? AND name = ?", tenantID, name).Error
+	if err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// FindByTenantAndNames retrieves a tenant's secrets with the given names
+func (r *SecretRepository) FindByTenantAndNames(ctx context.Context, tenantID uuid.UUID, names []string) ([]models.Secret, error) {
+	var secrets []models.Secret
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ? AND name IN ?", tenantID, names).
+		Find(&secrets).Error
+	return secrets, err
+}
+
+// FindByTenant retrieves all secrets for a tenant
+func (r *SecretRepository) FindByTenant(ctx context.Context, tenantID uuid.UUID) ([]models.Secret, error) {
+	var secrets []models.Secret
+	err := database.Conn(ctx, r.db).
+		Where("tenant_id = ?", tenantID).
+		Order("name ASC").
+		Find(&secrets).Error
+	return secrets, err
+}
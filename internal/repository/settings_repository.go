@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SettingsRepository handles runtime setting persistence
+type SettingsRepository struct {
+	db *gorm.DB
+}
+
+// NewSettingsRepository creates a new settings repository
+func NewSettingsRepository(db *gorm.DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+// FindAll retrieves every runtime setting override
+func (r *SettingsRepository) FindAll(ctx context.Context) ([]models.RuntimeSetting, error) {
+	var settings []models.RuntimeSetting
+	err := database.Conn(ctx, r.db).Order("name ASC").Find(&settings).Error
+	return settings, err
+}
+
+// Save stores setting overrides and records their changes in one transaction
+func (r *SettingsRepository) Save(ctx context.Context, settings []models.RuntimeSetting, changes []models.ConfigChange) error {
+	return database.Conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for i := range settings {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "name"}},
+				DoUpdates: clause.AssignmentColumns([]string{"value", "updated_by", "updated_at"}),
+			}).Create(&settings[i]).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		return tx.Create(&changes).Error
+	})
+}
+
+// FindChanges retrieves the latest runtime setting changes, newest first
+func (r *SettingsRepository) FindChanges(ctx context.Context, limit int) ([]models.ConfigChange, error) {
+	var changes []models.ConfigChange
+	err := database.Conn(ctx, r.db).Order("created_at DESC").Limit(limit).Find(&changes).Error
+	return changes, err
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// notPaused is a condition leaving out the rows of paused tenants
+const notPaused = "tenant_id NOT IN (SELECT tenant_id FROM tenant_settings WHERE paused_at IS NOT NULL)"
+
+// TenantRepository handles per-tenant settings persistence
+type TenantRepository struct {
+	db *gorm.DB
+}
+
+// NewTenantRepository creates a new tenant repository
+func NewTenantRepository(db *gorm.DB) *TenantRepository {
+	return &TenantRepository{db: db}
+}
+
+// FindSettings retrieves a tenant's settings
+func (r *TenantRepository) FindSettings(ctx context.Context, tenantID uuid.UUID) (*models.TenantSettings, error) {
+	var settings models.TenantSettings
+	err := database.Conn(ctx, r.db).First(&settings, "tenant_id = ?", tenantID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// SaveSettings creates or updates a tenant's settings
+func (r *TenantRepository) SaveSettings(ctx context.Context, settings *models.TenantSettings) error {
+	return database.Conn(ctx, r.db).Save(settings).Error
+}
+
+// SetPaused pauses a tenant with a reason, or resumes it when pausedAt is nil, leaving its
+// other settings alone
+func (r *TenantRepository) SetPaused(ctx context.Context, tenantID uuid.UUID, pausedAt *time.Time, reason string) error {
+	return database.Conn(ctx, r.db).Exec(`
+		INSERT INTO tenant_settings (tenant_id, paused_at, pause_reason, created_at, updated_at)
+		VALUES (?, ?, ?, NOW(), NOW())
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET paused_at = EXCLUDED.paused_at, pause_reason = EXCLUDED.pause_reason, updated_at = NOW()`,
+		tenantID, pausedAt, reason,
+	).Error
+}
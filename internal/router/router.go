@@ -1,35 +1,74 @@
 package router
 
 import (
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/compress"
+	"github.com/gofiber/fiber/v2/middleware/expvar"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/gofiber/swagger"
 	"github.com/minisource/scheduler/internal/handler"
+	"github.com/minisource/scheduler/internal/middleware"
+	"github.com/minisource/scheduler/internal/models"
 )
 
 // Handlers contains all HTTP handlers
 type Handlers struct {
-	Job       *handler.JobHandler
-	Execution *handler.ExecutionHandler
-	History   *handler.HistoryHandler
-	Health    *handler.HealthHandler
+	Job          *handler.JobHandler
+	Execution    *handler.ExecutionHandler
+	History      *handler.HistoryHandler
+	Incident     *handler.IncidentHandler
+	Notification *handler.NotificationHandler
+	Slack        *handler.SlackHandler
+	Share        *handler.ShareHandler
+	Auth         *handler.AuthHandler
+	Tenant       *handler.TenantHandler
+	Secret       *handler.SecretHandler
+	Shift        *handler.ShiftHandler
+	Rollout      *handler.RolloutHandler
+	Alert        *handler.AlertHandler
+	Callback     *handler.CallbackHandler
+	Admission    *handler.AdmissionHandler
+	Calendar     *handler.CalendarHandler
+	Audit        *handler.AuditHandler
+	System       *handler.SystemHandler
+	Cluster      *handler.ClusterHandler
+	Health       *handler.HealthHandler
+}
+
+// Middlewares contains middleware that depends on configuration or services
+type Middlewares struct {
+	AccessLog fiber.Handler
+	CORS      fiber.Handler
+	Security  fiber.Handler
+	Session   fiber.Handler
+	Token     fiber.Handler // Authenticates API clients by their bearer token
+	Auth      fiber.Handler // Requires a session or token when tokens are required
+	Actor     fiber.Handler // Attributes changes to the user a request acts for
+	Audit     fiber.Handler // Records mutating calls in the audit log
+	Tenant    fiber.Handler // Binds tenant-scoped routes to their tenant's rows
+	System    fiber.Handler // Lets routes that find their tenant from the data see all rows
+	Drain     fiber.Handler // Requires the drain token
 }
 
-// SetupRouter configures the Fiber router
-func SetupRouter(app *fiber.App, h *Handlers) {
+// SetupRouter configures the Fiber router. Instances that don't serve the API only get the
+// health and operational routes.
+func SetupRouter(app *fiber.App, h *Handlers, m *Middlewares, serveAPI bool) {
 	// Middleware
 	app.Use(recover.New())
 	app.Use(requestid.New())
-	app.Use(logger.New(logger.Config{
-		Format: "[${time}] ${status} - ${method} ${path} - ${latency}\n",
-	}))
-	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders: "Origin,Content-Type,Accept,Authorization,X-Tenant-ID,X-Request-ID",
+	app.Use(m.AccessLog)
+	app.Use(m.CORS)
+	app.Use(m.Security)
+	// Compress responses for clients that send Accept-Encoding (gzip, deflate, br), except
+	// event streams, which must reach the client as each event is written
+	app.Use(compress.New(compress.Config{
+		Next: func(c *fiber.Ctx) bool {
+			return strings.HasSuffix(c.Path(), "/events")
+		},
+		Level: compress.LevelBestSpeed,
 	}))
 
 	// Swagger route
@@ -39,33 +78,201 @@ func SetupRouter(app *fiber.App, h *Handlers) {
 	app.Get("/health", h.Health.Health)
 	app.Get("/ready", h.Health.Ready)
 	app.Get("/live", h.Health.Live)
+	app.Get("/startup", h.Health.Startup)
+	app.Get("/version", h.Health.Version)
+	app.Post("/drain", m.Drain, h.Health.Drain)
+
+	// Runtime and scheduling loop counters at /debug/vars, leader election in Prometheus format
+	app.Use(expvar.New())
+	app.Get("/metrics", h.Cluster.Metrics)
+
+	if !serveAPI {
+		return
+	}
+
+	// API v1 routes; browser sessions and API clients' bearer tokens are resolved here, and
+	// changes are attributed to the user each request acts for and recorded in the audit log
+	v1 := app.Group("/api/v1", m.Session, m.Token, m.Actor, m.Audit)
 
-	// API v1 routes
-	v1 := app.Group("/api/v1")
+	// Role gates. Every tenant route can be read by viewers; operators run and change jobs,
+	// and admins change the tenant's settings, secrets and integrations. Requests without a
+	// session or token pass as operators only when AUTH_MODE=header trusts their headers.
+	viewer := middleware.RequireRole(models.RoleViewer)
+	operatorWrites := middleware.RequireWriteRole(models.RoleOperator)
+	adminWrites := middleware.RequireWriteRole(models.RoleAdmin)
+	admin := middleware.RequireRole(models.RoleAdmin)
+	platform := middleware.RequireRole(models.RolePlatform)
 
 	// Job routes
-	jobs := v1.Group("/jobs")
+	jobs := v1.Group("/jobs", m.Auth, m.Tenant, viewer, operatorWrites)
 	jobs.Get("/stats", h.Job.GetStats)
+	jobs.Get("/stale", h.Job.GetStale)
+	jobs.Get("/export", h.Job.Export)
+	jobs.Post("/import", h.Job.Import)
+	jobs.Put("/batch", h.Job.BatchUpsert)
+	jobs.Post("/validate", h.Job.Validate)
 	jobs.Get("/", h.Job.List)
 	jobs.Post("/", h.Job.Create)
 	jobs.Get("/:id", h.Job.Get)
 	jobs.Put("/:id", h.Job.Update)
 	jobs.Delete("/:id", h.Job.Delete)
+	jobs.Post("/:id/simulate", h.Job.SimulateSchedule)
+	jobs.Post("/:id/restore", h.Job.Restore)
 	jobs.Post("/:id/trigger", h.Job.Trigger)
 	jobs.Post("/:id/pause", h.Job.Pause)
 	jobs.Post("/:id/resume", h.Job.Resume)
+	jobs.Post("/:id/canary", h.Job.StartCanary)
+	jobs.Post("/:id/canary/confirm", h.Job.ConfirmCanary)
+	jobs.Post("/:id/share", h.Share.CreateLink)
 	jobs.Get("/:job_id/executions", h.Execution.ListByJob)
 	jobs.Get("/:job_id/history", h.History.GetByJob)
+	jobs.Get("/:id/revisions", h.Job.ListRevisions)
+	jobs.Post("/:id/revisions/:rev/rollback", h.Job.Rollback)
+
+	// Schedule routes
+	schedule := v1.Group("/schedule", m.Auth, m.Tenant, viewer)
+	schedule.Get("/preview", h.Job.PreviewSchedule)
 
 	// Execution routes
-	executions := v1.Group("/executions")
+	executions := v1.Group("/executions", m.Auth, m.Tenant, viewer, operatorWrites)
 	executions.Get("/stats", h.Execution.GetStats)
+	executions.Get("/verify", h.Execution.VerifyChain)
+	executions.Get("/compare", h.Execution.Compare)
 	executions.Get("/", h.Execution.List)
 	executions.Get("/:id", h.Execution.Get)
+	executions.Get("/:id/events", h.Execution.Events)
 	executions.Post("/:id/cancel", h.Execution.Cancel)
+	executions.Post("/:id/acknowledge", h.Execution.Acknowledge)
+	executions.Post("/:id/replay", h.Execution.Replay)
 
 	// History routes
-	history := v1.Group("/history")
+	history := v1.Group("/history", m.Auth, m.Tenant, viewer)
 	history.Get("/stats", h.History.GetAggregated)
 	history.Get("/", h.History.GetDateRange)
+
+	// Incident routes
+	incidents := v1.Group("/incidents", m.Auth, m.Tenant, viewer, operatorWrites)
+	incidents.Get("/", h.Incident.List)
+	incidents.Get("/:id", h.Incident.Get)
+	incidents.Post("/:id/acknowledge", h.Incident.Acknowledge)
+
+	// Notification routes
+	notifications := v1.Group("/notifications", m.Auth, m.Tenant, viewer, adminWrites)
+	notifications.Get("/policy", h.Notification.GetPolicy)
+	notifications.Put("/policy", h.Notification.UpdatePolicy)
+	notifications.Get("/channels", h.Notification.ListChannels)
+	notifications.Post("/channels", h.Notification.CreateChannel)
+	notifications.Put("/channels/:id", h.Notification.UpdateChannel)
+	notifications.Delete("/channels/:id", h.Notification.DeleteChannel)
+	notifications.Get("/subscriptions", h.Notification.ListSubscriptions)
+	notifications.Post("/subscriptions", h.Notification.Subscribe)
+	notifications.Delete("/subscriptions/:id", h.Notification.Unsubscribe)
+
+	// Tenant routes
+	tenant := v1.Group("/tenant", m.Auth, m.Tenant, viewer, adminWrites)
+	tenant.Get("/settings", h.Tenant.GetSettings)
+	tenant.Put("/settings", h.Tenant.UpdateSettings)
+	tenant.Get("/usage", h.Tenant.GetUsage)
+
+	// Secret routes
+	secrets := v1.Group("/secrets", m.Auth, m.Tenant, viewer, adminWrites)
+	secrets.Get("/", h.Secret.List)
+	secrets.Post("/", h.Secret.Create)
+	secrets.Get("/:name", h.Secret.Get)
+	secrets.Put("/:name", h.Secret.Update)
+	secrets.Delete("/:name", h.Secret.Delete)
+
+	// Schedule shift routes
+	shifts := v1.Group("/shifts", m.Auth, m.Tenant, viewer, operatorWrites)
+	shifts.Get("/", h.Shift.List)
+	shifts.Post("/", h.Shift.Create)
+	shifts.Get("/:id", h.Shift.Get)
+	shifts.Post("/:id/revert", h.Shift.Revert)
+
+	// Alert routes
+	alerts := v1.Group("/alerts", m.Auth, m.Tenant, viewer, adminWrites)
+	alerts.Get("/", h.Alert.List)
+	alerts.Post("/", h.Alert.Create)
+	alerts.Get("/firing", h.Alert.ListFiring)
+	alerts.Get("/:id", h.Alert.Get)
+	alerts.Put("/:id", h.Alert.Update)
+	alerts.Delete("/:id", h.Alert.Delete)
+
+	// Callback routes
+	callbacks := v1.Group("/callbacks", m.Auth, m.Tenant, viewer, adminWrites)
+	callbacks.Get("/routes", h.Callback.ListRoutes)
+	callbacks.Post("/routes", h.Callback.CreateRoute)
+	callbacks.Get("/routes/:id", h.Callback.GetRoute)
+	callbacks.Put("/routes/:id", h.Callback.UpdateRoute)
+	callbacks.Delete("/routes/:id", h.Callback.DeleteRoute)
+	callbacks.Get("/deliveries", h.Callback.ListDeliveries)
+	callbacks.Post("/deliveries/:id/redeliver", h.Callback.Redeliver)
+
+	// Admission webhook routes
+	admission := v1.Group("/admission", m.Auth, m.Tenant, viewer, adminWrites)
+	admission.Get("/webhooks", h.Admission.List)
+	admission.Post("/webhooks", h.Admission.Create)
+	admission.Get("/webhooks/:id", h.Admission.Get)
+	admission.Put("/webhooks/:id", h.Admission.Update)
+	admission.Delete("/webhooks/:id", h.Admission.Delete)
+
+	// Calendar routes
+	calendars := v1.Group("/calendars", m.Auth, m.Tenant, viewer, operatorWrites)
+	calendars.Get("/", h.Calendar.List)
+	calendars.Post("/", h.Calendar.Create)
+	calendars.Get("/:id", h.Calendar.Get)
+	calendars.Put("/:id", h.Calendar.Update)
+	calendars.Delete("/:id", h.Calendar.Delete)
+
+	// Audit log
+	audit := v1.Group("/audit", m.Auth, m.Tenant, admin)
+	audit.Get("/", h.Audit.List)
+
+	// Service-wide configuration, for the service's operators only
+	system := v1.Group("/system", m.Auth, m.System, platform)
+	system.Get("/config", h.System.GetConfig)
+	system.Patch("/config", h.System.UpdateConfig)
+	system.Get("/config/changes", h.System.ListChanges)
+	system.Post("/history/rebuild", h.History.Rebuild)
+	system.Get("/usage", h.History.GetUsage)
+	system.Get("/features", h.System.ListFlags)
+	system.Put("/features/:name", h.System.SetFlag)
+	system.Delete("/features/:name", h.System.ClearFlag)
+
+	// Tenant administration, acting on any tenant, for the service's operators only
+	tenants := v1.Group("/tenants", m.Auth, m.System, platform)
+	tenants.Put("/:id/grants", h.Tenant.UpdateGrants)
+	tenants.Post("/:id/pause", h.Tenant.Pause)
+	tenants.Post("/:id/resume", h.Tenant.Resume)
+
+	// Staged rollouts of changes to fleets of jobs across tenants, for the service's operators only
+	rollouts := v1.Group("/rollouts", m.Auth, m.System, platform)
+	rollouts.Get("/", h.Rollout.List)
+	rollouts.Post("/", h.Rollout.Create)
+	rollouts.Get("/:id", h.Rollout.Get)
+	rollouts.Get("/:id/jobs", h.Rollout.ListJobs)
+	rollouts.Post("/:id/halt", h.Rollout.Halt)
+	rollouts.Post("/:id/resume", h.Rollout.Resume)
+	rollouts.Post("/:id/rollback", h.Rollout.Rollback)
+
+	// Scheduler cluster state
+	cluster := v1.Group("/cluster", m.Auth, m.System, platform)
+	cluster.Get("/leader", h.Cluster.Leader)
+	cluster.Get("/workers", h.Cluster.Workers)
+	cluster.Get("/shadow", h.Cluster.Shadow)
+
+	// Operator login and browser sessions
+	auth := v1.Group("/auth", m.System)
+	auth.Get("/oidc/login", h.Auth.OIDCLogin)
+	auth.Get("/oidc/callback", h.Auth.OIDCCallback)
+	auth.Get("/session", h.Auth.GetSession)
+	auth.Post("/logout", h.Auth.Logout)
+
+	// Public share links (authenticated by their signed token)
+	public := v1.Group("/public", m.System)
+	public.Get("/jobs/:token", h.Share.PublicStatus)
+
+	// Integration callbacks (authenticated by their own signatures)
+	integrations := v1.Group("/integrations", m.System)
+	integrations.Post("/slack/interactions", h.Slack.Interactions)
 }
@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// ErrTargetNotGranted is returned when a job's target names a command its tenant wasn't
+// granted, or a datasource that doesn't serve its tenant
+var ErrTargetNotGranted = errors.New("target is not granted to this tenant")
+
+// checkTargetAccess checks that a tenant was granted the command a job's target names, or
+// is served by its datasource. Targets that don't parse pass, for target validation to report.
+func checkTargetAccess(job *models.Job, limits models.TenantLimits, sqlConfig config.SQLConfig) error {
+	switch job.TargetType {
+	case models.TargetSQL:
+		target, err := ParseSQLTarget(job)
+		if err != nil {
+			return nil
+		}
+		if !slices.Contains(sqlConfig.DatasourceTenants()[target.Datasource], job.TenantID.String()) {
+			return fmt.Errorf("%w: datasource %q", ErrTargetNotGranted, target.Datasource)
+		}
+	case models.TargetCommand:
+		target, err := ParseCommandTarget(job)
+		if err != nil {
+			return nil
+		}
+		if !slices.Contains(limits.CommandAllow, target.Command) {
+			return fmt.Errorf("%w: command %q", ErrTargetNotGranted, target.Command)
+		}
+	}
+	return nil
+}
+
+// CheckTargetAccess checks that a job's tenant was granted the command its target names, or
+// is served by its datasource. Runs are checked again before they start, so revoking a grant
+// fails them.
+func (s *Scheduler) CheckTargetAccess(ctx context.Context, job *models.Job) error {
+	limits := models.TenantLimits{}
+	if s.tenants != nil {
+		limits = s.tenants.Limits(ctx, job.TenantID)
+	}
+	return checkTargetAccess(job, limits, s.config.SQL)
+}
+
+// checkTargetAccess checks that a job's tenant was granted the command its target names, or
+// is served by its datasource
+func (e *Executor) checkTargetAccess(ctx context.Context, job *models.Job) error {
+	limits := models.TenantLimits{}
+	if e.tenants != nil {
+		limits = e.tenants.Limits(ctx, job.TenantID)
+	}
+	return checkTargetAccess(job, limits, e.config.SQL)
+}
@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// recordOutcome ends an execution and records everything counted from it in one transaction:
+// the write that ends the execution, the job's run or failure counters, whether its endpoint
+// answered 404, the day's history and usage, and the result callback. If any of them fails
+// none is kept, so counters, history and callbacks always reconcile with the executions.
+// finish reports false when the execution had already ended, e.g. because it was cancelled or
+// timed out meanwhile; nothing is counted then. An attempt that is run again in a new
+// execution isn't final and gets no callback.
+// It returns the execution as recorded, or nil if it wasn't.
+func (s *Scheduler) recordOutcome(ctx context.Context, job *models.Job, executionID uuid.UUID, success, final bool, finish func(ctx context.Context) (bool, error)) *models.JobExecution {
+	var recorded *models.JobExecution
+	err := s.jobRepo.Transaction(ctx, func(ctx context.Context) error {
+		ended, err := finish(ctx)
+		if err != nil || !ended {
+			return err
+		}
+
+		// History counts the execution as recorded, as a rebuild from executions would
+		execution, err := s.executionRepo.FindByID(ctx, executionID)
+		if err != nil {
+			return err
+		}
+		completedAt := time.Now()
+		if execution.CompletedAt != nil {
+			completedAt = *execution.CompletedAt
+		}
+
+		if err := s.jobRepo.UpdateLastRunAt(ctx, job.ID, success); err != nil {
+			return fmt.Errorf("failed to update job counters: %w", err)
+		}
+		if execution.StatusCode != nil {
+			notFound := *execution.StatusCode == http.StatusNotFound
+			if err := s.jobRepo.TrackNotFound(ctx, job.ID, notFound, completedAt); err != nil {
+				return fmt.Errorf("failed to track not found responses: %w", err)
+			}
+		}
+		for _, zone := range s.historyZones(ctx, job.TenantID) {
+			if err := s.recordHistory(ctx, job, execution, success, zone, completedAt); err != nil {
+				return fmt.Errorf("failed to update history: %w", err)
+			}
+		}
+		if final {
+			if err := s.queueCallback(ctx, job, execution); err != nil {
+				return err
+			}
+		}
+
+		recorded = execution
+		return nil
+	})
+	if err != nil {
+		dispatchMetrics.Add("outcomes_failed", 1)
+		log.Printf("scheduler: failed to record the outcome of execution %s: %v", executionID, err)
+		return nil
+	}
+	return recorded
+}
+
+// historyZones returns the timezones a tenant's history days are counted in: UTC, which
+// metering and rebuilds use, and the tenant's own timezone if it has another
+func (s *Scheduler) historyZones(ctx context.Context, tenantID uuid.UUID) []*time.Location {
+	zones := []*time.Location{time.UTC}
+	name := s.tenants.Limits(ctx, tenantID).Timezone
+	if name == "" || name == models.HistoryTimezoneUTC {
+		return zones
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("scheduler: tenant %s has unknown timezone %q; history is kept in UTC only", tenantID, name)
+		return zones
+	}
+	return append(zones, loc)
+}
+
+// recordHistory counts an execution in its job's history for the day it completed on in a
+// timezone
+func (s *Scheduler) recordHistory(ctx context.Context, job *models.Job, execution *models.JobExecution, success bool, zone *time.Location, completedAt time.Time) error {
+	day := completedAt.In(zone)
+	var err error
+	if success {
+		var duration int64
+		if execution.Duration != nil {
+			duration = *execution.Duration
+		}
+		err = s.historyRepo.IncrementSuccess(ctx, job.TenantID, job.ID, zone.String(), day, duration)
+	} else {
+		err = s.historyRepo.IncrementFailure(ctx, job.TenantID, job.ID, zone.String(), day)
+	}
+	if err != nil {
+		return err
+	}
+	return s.historyRepo.AddUsage(ctx, job.ID, zone.String(), day, execution)
+}
@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// AdvisoryLocker provides distributed locking with PostgreSQL session advisory locks, for
+// deployments that coordinate through the database instead of Redis. Locks are held on one
+// dedicated connection; if it breaks or the instance dies, PostgreSQL releases them, so
+// they never outlive their holder and need no TTL.
+type AdvisoryLocker struct {
+	db *sql.DB
+
+	mu   sync.Mutex
+	conn *sql.Conn
+	held map[string]bool
+}
+
+// NewAdvisoryLocker creates a new advisory locker
+func NewAdvisoryLocker(db *sql.DB) *AdvisoryLocker {
+	return &AdvisoryLocker{
+		db:   db,
+		held: make(map[string]bool),
+	}
+}
+
+// advisoryKey maps a lock key to the 64-bit key of an advisory lock
+func advisoryKey(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("lock:" + key))
+	return int64(h.Sum64())
+}
+
+// session returns the connection holding this instance's locks, opening one if needed.
+// Callers hold l.mu.
+func (l *AdvisoryLocker) session(ctx context.Context) (*sql.Conn, error) {
+	if l.conn != nil {
+		return l.conn, nil
+	}
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l.conn = conn
+	return conn, nil
+}
+
+// drop closes a broken session. PostgreSQL releases its locks, so they are forgotten too.
+// Callers hold l.mu.
+func (l *AdvisoryLocker) drop() {
+	if l.conn != nil {
+		l.conn.Close()
+		l.conn = nil
+	}
+	l.held = make(map[string]bool)
+}
+
+// AcquireLock attempts to acquire a lock with the given key. The ttl is ignored: the lock
+// lasts until it is released or the session ends.
+func (l *AdvisoryLocker) AcquireLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Like a Redis lock, a lock already held can't be acquired again
+	if l.held[key] {
+		return false, nil
+	}
+
+	conn, err := l.session(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", advisoryKey(key)).Scan(&acquired); err != nil {
+		l.drop()
+		return false, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	if acquired {
+		l.held[key] = true
+	}
+	return acquired, nil
+}
+
+// ReleaseLock releases a lock if held by this instance
+func (l *AdvisoryLocker) ReleaseLock(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.held[key] || l.conn == nil {
+		return nil
+	}
+	delete(l.held, key)
+	if _, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", advisoryKey(key)); err != nil {
+		l.drop()
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
+
+// RefreshLock checks that the session holding a lock is still alive; advisory locks don't
+// expire, so there is nothing to extend
+func (l *AdvisoryLocker) RefreshLock(ctx context.Context, key string, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.held[key] || l.conn == nil {
+		return nil
+	}
+	if err := l.conn.PingContext(ctx); err != nil {
+		l.drop()
+		return fmt.Errorf("failed to refresh lock: lost database session: %w", err)
+	}
+	return nil
+}
+
+// IsLockHeld checks if a lock is currently held by this instance
+func (l *AdvisoryLocker) IsLockHeld(ctx context.Context, key string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.held[key] || l.conn == nil {
+		return false, nil
+	}
+	if err := l.conn.PingContext(ctx); err != nil {
+		l.drop()
+		return false, fmt.Errorf("failed to check lock: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,193 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/notification"
+)
+
+// checkFailureAlerts fires the consecutive failure rules a job has reached after a final
+// execution failure
+func (s *Scheduler) checkFailureAlerts(ctx context.Context, job *models.Job, executionID uuid.UUID, errMsg string) {
+	if s.alertRepo == nil || s.notifier == nil {
+		return
+	}
+
+	rules, err := s.alertRepo.FindEnabledRules(ctx, job.TenantID, models.AlertRuleConsecutiveFailures)
+	if err != nil || len(rules) == 0 {
+		return
+	}
+
+	// The task's copy predates this failure's counter update
+	current, err := s.jobRepo.FindByID(ctx, job.ID)
+	if err != nil {
+		return
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Matches(current) || current.ConsecutiveFailures < int64(rule.Threshold) {
+			continue
+		}
+		if !s.fireAlert(ctx, rule, current) {
+			continue
+		}
+
+		event := alertEvent(notification.EventAlertFiring, rule, current)
+		event.ExecutionID = &executionID
+		event.FailureCount = current.ConsecutiveFailures
+		event.Title = fmt.Sprintf("Job %q failed %d times in a row", current.Name, current.ConsecutiveFailures)
+		event.Message = errMsg
+		s.notifier.NotifyChannels(ctx, event, rule.Channels())
+	}
+}
+
+// resolveFailureAlerts resolves the consecutive failure rules firing for a job after it succeeds
+func (s *Scheduler) resolveFailureAlerts(ctx context.Context, job *models.Job) {
+	if s.alertRepo == nil || s.notifier == nil {
+		return
+	}
+
+	firings, err := s.alertRepo.FindFiringsByJob(ctx, job.ID)
+	if err != nil || len(firings) == 0 {
+		return
+	}
+
+	for _, firing := range firings {
+		rule, err := s.alertRepo.FindRuleByTenantAndID(ctx, job.TenantID, firing.RuleID)
+		if err != nil || rule.Type != models.AlertRuleConsecutiveFailures {
+			continue
+		}
+		resolved, err := s.alertRepo.Resolve(ctx, rule.ID, job.ID)
+		if err != nil || !resolved {
+			continue
+		}
+
+		event := alertEvent(notification.EventAlertResolved, rule, job)
+		event.Title = fmt.Sprintf("Job %q succeeded again", job.Name)
+		s.notifier.NotifyChannels(ctx, event, rule.Channels())
+	}
+}
+
+// alertLoop periodically evaluates not_run alert rules
+func (s *Scheduler) alertLoop() {
+	defer s.wg.Done()
+
+	if s.alertRepo == nil || s.notifier == nil {
+		return
+	}
+
+	interval := time.Duration(s.config.Notifications.AlertCheckSeconds) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkNotRunAlerts(s.ctx)
+		}
+	}
+}
+
+// checkNotRunAlerts fires not_run rules for active jobs that haven't finished a run within
+// the rule's window, and resolves them once the jobs run again. Every instance evaluates
+// the rules; recording the firing decides which one announces it.
+func (s *Scheduler) checkNotRunAlerts(ctx context.Context) {
+	rules, err := s.alertRepo.FindEnabledRulesByType(ctx, models.AlertRuleNotRun)
+	if err != nil {
+		log.Printf("scheduler: failed to load alert rules: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for i := range rules {
+		rule := &rules[i]
+
+		jobs, err := s.alertJobs(ctx, rule)
+		if err != nil {
+			continue
+		}
+
+		window := time.Duration(rule.WindowMinutes) * time.Minute
+		for j := range jobs {
+			job := &jobs[j]
+			if job.Status != models.JobStatusActive {
+				continue
+			}
+
+			since := job.CreatedAt
+			if job.LastRunAt != nil && job.LastRunAt.After(since) {
+				since = *job.LastRunAt
+			}
+
+			if now.Sub(since) < window {
+				resolved, err := s.alertRepo.Resolve(ctx, rule.ID, job.ID)
+				if err != nil || !resolved {
+					continue
+				}
+				event := alertEvent(notification.EventAlertResolved, rule, job)
+				event.Title = fmt.Sprintf("Job %q is running again", job.Name)
+				s.notifier.NotifyChannels(ctx, event, rule.Channels())
+				continue
+			}
+
+			if !s.fireAlert(ctx, rule, job) {
+				continue
+			}
+			event := alertEvent(notification.EventAlertFiring, rule, job)
+			event.Title = fmt.Sprintf("Job %q hasn't run in %d minutes", job.Name, int(now.Sub(since).Minutes()))
+			s.notifier.NotifyChannels(ctx, event, rule.Channels())
+		}
+	}
+}
+
+// alertJobs returns the jobs an alert rule selects
+func (s *Scheduler) alertJobs(ctx context.Context, rule *models.AlertRule) ([]models.Job, error) {
+	if rule.JobID != nil {
+		return s.jobRepo.FindByTenantAndIDs(ctx, rule.TenantID, []uuid.UUID{*rule.JobID})
+	}
+
+	var tags []string
+	if err := json.Unmarshal(rule.Tags, &tags); err != nil || len(tags) == 0 {
+		return nil, fmt.Errorf("alert rule %s has no job_id or tags", rule.ID)
+	}
+	return s.jobRepo.FindByTenantAndTags(ctx, rule.TenantID, tags)
+}
+
+// fireAlert records a rule firing for a job, returning true when it wasn't firing already
+func (s *Scheduler) fireAlert(ctx context.Context, rule *models.AlertRule, job *models.Job) bool {
+	fired, err := s.alertRepo.Fire(ctx, &models.AlertFiring{
+		RuleID:   rule.ID,
+		JobID:    job.ID,
+		TenantID: job.TenantID,
+		FiredAt:  time.Now(),
+	})
+	return err == nil && fired
+}
+
+// alertEvent builds the notification event of an alert rule for a job
+func alertEvent(kind notification.EventKind, rule *models.AlertRule, job *models.Job) notification.Event {
+	ruleID := rule.ID
+	jobID := job.ID
+	return notification.Event{
+		Kind:      kind,
+		TenantID:  job.TenantID,
+		JobID:     &jobID,
+		JobName:   job.Name,
+		JobTags:   job.TagList(),
+		RuleID:    &ruleID,
+		GroupKey:  fmt.Sprintf("alert:%s:job:%s", rule.ID, job.ID),
+		Timestamp: time.Now(),
+	}
+}
@@ -0,0 +1,230 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/tracing"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AMQPTarget is the target_config of amqp jobs. Fields missing from target_config are
+// read from the job's metadata under the same names.
+type AMQPTarget struct {
+	Exchange   string `json:"exchange,omitempty"` // Empty for the default exchange, which routes by queue name
+	RoutingKey string `json:"routing_key,omitempty"`
+}
+
+// destination names the exchange and routing key for spans and incident grouping
+func (t *AMQPTarget) destination() string {
+	if t.Exchange == "" {
+		return t.RoutingKey
+	}
+	return t.Exchange + "/" + t.RoutingKey
+}
+
+// ParseAMQPTarget reads an amqp job's target from its target_config and metadata
+func ParseAMQPTarget(job *models.Job) (*AMQPTarget, error) {
+	var target, fallback AMQPTarget
+	if len(job.TargetConfig) > 0 {
+		if err := json.Unmarshal(job.TargetConfig, &target); err != nil {
+			return nil, fmt.Errorf("invalid amqp target_config: %w", err)
+		}
+	}
+	if len(job.Metadata) > 0 {
+		// Metadata is free-form, so fields of the wrong type are ignored
+		_ = json.Unmarshal(job.Metadata, &fallback)
+	}
+
+	if target.Exchange == "" {
+		target.Exchange = fallback.Exchange
+	}
+	if target.RoutingKey == "" {
+		target.RoutingKey = fallback.RoutingKey
+	}
+
+	if target.Exchange == "" && target.RoutingKey == "" {
+		return nil, fmt.Errorf("amqp targets need an exchange or a routing_key (the queue name for the default exchange)")
+	}
+	return &target, nil
+}
+
+// amqpPublisher publishes amqp job messages over one shared connection
+type amqpPublisher struct {
+	config config.AMQPConfig
+	conn   *amqp.Connection
+	mu     sync.Mutex
+}
+
+// newAMQPPublisher creates a publisher; it connects on first use
+func newAMQPPublisher(cfg config.AMQPConfig) *amqpPublisher {
+	return &amqpPublisher{config: cfg}
+}
+
+// channel opens a confirm-mode channel, connecting or reconnecting if needed. Each publish
+// gets its own channel, so returns and confirms can't be mixed up between executions.
+func (p *amqpPublisher) channel() (*amqp.Channel, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil || p.conn.IsClosed() {
+		if p.config.URL == "" {
+			return nil, fmt.Errorf("no amqp broker: set AMQP_URL")
+		}
+		props := amqp.NewConnectionProperties()
+		props.SetClientConnectionName(p.config.ClientName)
+		conn, err := amqp.DialConfig(p.config.URL, amqp.Config{Properties: props})
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to amqp broker: %w", err)
+		}
+		p.conn = conn
+	}
+
+	ch, err := p.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open amqp channel: %w", err)
+	}
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+	return ch, nil
+}
+
+// Close closes the connection
+func (p *amqpPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil || p.conn.IsClosed() {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
+
+// executeAMQP publishes a job's payload or body to its exchange as a persistent, mandatory
+// message. The run succeeds once the broker confirms it; nacks and messages no queue is
+// bound for fail the run. The job's headers become message headers.
+func (e *Executor) executeAMQP(ctx context.Context, job *models.Job) (*ExecutionResult, error) {
+	startTime := time.Now()
+	result := &ExecutionResult{}
+
+	fail := func(span trace.Span, err error) (*ExecutionResult, error) {
+		result.Error = err.Error()
+		result.Duration = time.Since(startTime).Milliseconds()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, result.Error)
+		return result, err
+	}
+
+	target, err := ParseAMQPTarget(job)
+	if err != nil {
+		return fail(trace.SpanFromContext(ctx), err)
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, target.destination()+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.destination.name", target.Exchange),
+			attribute.String("messaging.rabbitmq.destination.routing_key", target.RoutingKey),
+		),
+	)
+	defer span.End()
+
+	job, err = e.resolveSecrets(ctx, job)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	body, contentType, err := RequestBody(job)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	headers := amqp.Table{
+		"X-Scheduler-Job-ID":    job.ID.String(),
+		"X-Scheduler-Tenant-ID": job.TenantID.String(),
+	}
+	if len(job.Headers) > 0 {
+		var custom map[string]string
+		if err := json.Unmarshal(job.Headers, &custom); err == nil {
+			for key, value := range custom {
+				if key == "Content-Type" {
+					contentType = value
+					continue
+				}
+				headers[key] = value
+			}
+		}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, amqpHeaderCarrier(headers))
+
+	ch, err := e.amqp.channel()
+	if err != nil {
+		return fail(span, err)
+	}
+	defer ch.Close()
+	returns := ch.NotifyReturn(make(chan amqp.Return, 1))
+
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, target.Exchange, target.RoutingKey, true, false, amqp.Publishing{
+		Headers:      headers,
+		ContentType:  contentType,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    job.ID.String(),
+		Timestamp:    time.Now(),
+		Body:         body,
+	})
+	if err != nil {
+		return fail(span, fmt.Errorf("failed to publish to %s: %w", target.destination(), err))
+	}
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fail(span, fmt.Errorf("no publisher confirm from %s: %w", target.destination(), err))
+	}
+	if !acked {
+		return fail(span, fmt.Errorf("broker rejected the message to %s", target.destination()))
+	}
+	// The broker returns unroutable mandatory messages before confirming them
+	select {
+	case ret := <-returns:
+		return fail(span, fmt.Errorf("message to %s was unroutable: %d %s", target.destination(), ret.ReplyCode, ret.ReplyText))
+	default:
+	}
+	CountBytes(ctx, int64(len(body)), 0)
+
+	result.Body, _ = json.Marshal(map[string]interface{}{
+		"exchange":    target.Exchange,
+		"routing_key": target.RoutingKey,
+		"bytes":       len(body),
+	})
+	result.Duration = time.Since(startTime).Milliseconds()
+	return result, nil
+}
+
+// amqpHeaderCarrier adapts message headers for trace context propagation
+type amqpHeaderCarrier amqp.Table
+
+func (c amqpHeaderCarrier) Get(key string) string {
+	value, _ := c[key].(string)
+	return value
+}
+func (c amqpHeaderCarrier) Set(key, value string) { c[key] = value }
+func (c amqpHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
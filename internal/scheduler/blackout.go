@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// blackouts holds the blackout calendars of the tenants in a dispatch batch
+type blackouts struct {
+	tenantWide map[uuid.UUID][]models.BlackoutWindow
+	named      map[uuid.UUID]map[string][]models.BlackoutWindow
+}
+
+// loadBlackouts loads the blackout calendars of the tenants whose jobs are about to be
+// dispatched. If they can't be loaded, only the jobs' own windows apply.
+func (s *Scheduler) loadBlackouts(ctx context.Context, jobs []models.Job) blackouts {
+	b := blackouts{
+		tenantWide: make(map[uuid.UUID][]models.BlackoutWindow),
+		named:      make(map[uuid.UUID]map[string][]models.BlackoutWindow),
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	var tenantIDs []uuid.UUID
+	for i := range jobs {
+		if !seen[jobs[i].TenantID] {
+			seen[jobs[i].TenantID] = true
+			tenantIDs = append(tenantIDs, jobs[i].TenantID)
+		}
+	}
+
+	calendars, err := s.calendarRepo.FindByTenants(ctx, tenantIDs, models.CalendarKindBlackout)
+	if err != nil {
+		log.Printf("scheduler: failed to load blackout calendars: %v", err)
+		return b
+	}
+	for i := range calendars {
+		calendar := &calendars[i]
+		windows := calendar.BlackoutWindowList()
+		if calendar.TenantWide {
+			b.tenantWide[calendar.TenantID] = append(b.tenantWide[calendar.TenantID], windows...)
+		}
+		if b.named[calendar.TenantID] == nil {
+			b.named[calendar.TenantID] = make(map[string][]models.BlackoutWindow)
+		}
+		b.named[calendar.TenantID][calendar.Name] = windows
+	}
+	return b
+}
+
+// windows returns the blackout windows that apply to a job: its own, its tenant's tenant-wide
+// calendars and the calendar it names
+func (b blackouts) windows(job *models.Job) []models.BlackoutWindow {
+	windows := job.BlackoutWindowList()
+	windows = append(windows, b.tenantWide[job.TenantID]...)
+	if job.BlackoutCalendar != "" {
+		windows = append(windows, b.named[job.TenantID][job.BlackoutCalendar]...)
+	}
+	return windows
+}
+
+// blackedOut skips or defers a job due inside a blackout window, reporting whether it did.
+// When windows overlap, skip wins over defer, and a deferred run waits for the last of them
+// to end.
+func (s *Scheduler) blackedOut(ctx context.Context, job *models.Job, b blackouts, now time.Time) bool {
+	var (
+		covered bool
+		skip    bool
+		until   time.Time
+	)
+	for _, window := range b.windows(job) {
+		end, ok := window.Covers(now)
+		if !ok {
+			continue
+		}
+		covered = true
+		if window.Action != models.BlackoutDefer {
+			skip = true
+		}
+		if end.After(until) {
+			until = end
+		}
+	}
+	if !covered {
+		return false
+	}
+
+	if skip {
+		dispatchMetrics.Add("blackout_skipped", 1)
+		log.Printf("scheduler: skipping job %s (%s) during a blackout window", job.ID, job.Name)
+		s.advanceSchedule(ctx, job)
+		if job.Type == models.JobTypeOneTime {
+			// A skipped one-time job has nothing left to run
+			s.jobRepo.MarkCompleted(ctx, job.ID)
+		}
+		return true
+	}
+
+	dispatchMetrics.Add("blackout_deferred", 1)
+	log.Printf("scheduler: deferring job %s (%s) to %s, the end of a blackout window", job.ID, job.Name, until.Format(time.RFC3339))
+	if err := s.jobRepo.UpdateNextRunAt(ctx, job.ID, until); err != nil {
+		log.Printf("scheduler: failed to defer job %s: %v", job.ID, err)
+	}
+	return true
+}
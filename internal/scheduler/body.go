@@ -0,0 +1,245 @@
+package scheduler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/minisource/scheduler/internal/models"
+)
+
+const (
+	contentTypeJSON      = "application/json"
+	contentTypeText      = "text/plain; charset=utf-8"
+	contentTypeBinary    = "application/octet-stream"
+	contentTypeForm      = "application/x-www-form-urlencoded"
+	contentTypeMultipart = "multipart/form-data"
+)
+
+// ErrMultipartBody is returned by RequestBody for multipart payloads, whose file parts are
+// fetched from object storage while the HTTP request is sent
+var ErrMultipartBody = errors.New("multipart/form-data payloads are only supported for http targets")
+
+// MultipartPart is a field of a multipart payload. Scalars become form fields; objects with
+// a url become file parts streamed from object storage.
+type MultipartPart struct {
+	Name  string
+	Value string
+	File  *MultipartFile
+}
+
+// MultipartFile is a file part of a multipart payload
+type MultipartFile struct {
+	URL         string `json:"url"`                    // s3://bucket/key
+	Filename    string `json:"filename,omitempty"`     // Defaults to the last segment of the key
+	ContentType string `json:"content_type,omitempty"` // Defaults to the object's content type
+
+	bucket string
+	key    string
+}
+
+// RequestBody returns the body a job sends and its Content-Type. A raw body is sent as is
+// (text/plain unless the job sets a content type), or decoded first when its body_encoding
+// is base64 (application/octet-stream by default). A JSON payload is sent as JSON, or
+// form-encoded when the content type is application/x-www-form-urlencoded. Multipart
+// payloads return ErrMultipartBody; the HTTP executor streams them instead.
+func RequestBody(job *models.Job) ([]byte, string, error) {
+	if len(job.Payload) > 0 && job.Body != "" {
+		return nil, "", fmt.Errorf("set either payload or body, not both")
+	}
+
+	var mediaType string
+	if job.ContentType != "" {
+		var err error
+		if mediaType, _, err = mime.ParseMediaType(job.ContentType); err != nil {
+			return nil, "", fmt.Errorf("invalid content_type: %w", err)
+		}
+	}
+
+	switch job.BodyEncoding {
+	case "", models.BodyEncodingText, models.BodyEncodingBase64:
+	default:
+		return nil, "", fmt.Errorf("invalid body_encoding %q: use text or base64", job.BodyEncoding)
+	}
+	if job.BodyEncoding == models.BodyEncodingBase64 && job.Body == "" {
+		return nil, "", fmt.Errorf("body_encoding base64 needs a body")
+	}
+
+	switch {
+	case job.Body != "" && job.BodyEncoding == models.BodyEncodingBase64:
+		content, err := base64.StdEncoding.DecodeString(job.Body)
+		if err != nil {
+			return nil, "", fmt.Errorf("body is not valid base64: %w", err)
+		}
+		if job.ContentType == "" {
+			return content, contentTypeBinary, nil
+		}
+		return content, job.ContentType, nil
+
+	case job.Body != "":
+		if job.ContentType == "" {
+			return []byte(job.Body), contentTypeText, nil
+		}
+		return []byte(job.Body), job.ContentType, nil
+
+	case len(job.Payload) > 0:
+		if mediaType == contentTypeForm {
+			form, err := formEncode(job.Payload)
+			if err != nil {
+				return nil, "", err
+			}
+			return []byte(form), job.ContentType, nil
+		}
+		if mediaType == contentTypeMultipart {
+			if _, err := ParseMultipart(job.Payload); err != nil {
+				return nil, "", err
+			}
+			return nil, "", ErrMultipartBody
+		}
+		if job.ContentType == "" {
+			return job.Payload, contentTypeJSON, nil
+		}
+		return job.Payload, job.ContentType, nil
+	}
+
+	return nil, "", nil
+}
+
+// ValidateRequestBody checks that a job's request body can be built for its target
+func ValidateRequestBody(job *models.Job) error {
+	switch job.TargetType {
+	case models.TargetGraphQL:
+		return validateGraphQLBody(job)
+	case models.TargetSOAP:
+		return validateSOAPBody(job)
+	case models.TargetReport:
+		return validateReportBody(job)
+	}
+	_, _, err := RequestBody(job)
+	if errors.Is(err, ErrMultipartBody) && (job.TargetType == "" || job.TargetType == models.TargetHTTP) {
+		return nil
+	}
+	return err
+}
+
+// isMultipart reports whether a job sends its payload as multipart/form-data
+func isMultipart(job *models.Job) bool {
+	mediaType, _, err := mime.ParseMediaType(job.ContentType)
+	return err == nil && mediaType == contentTypeMultipart && len(job.Payload) > 0
+}
+
+// ParseMultipart reads a multipart payload: a JSON object whose values are scalars, file
+// objects, or arrays of those, which become repeated parts. Parts are sorted by name.
+func ParseMultipart(payload json.RawMessage) ([]MultipartPart, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, fmt.Errorf("multipart payload must be a JSON object: %w", err)
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var parts []MultipartPart
+	for _, name := range names {
+		raw := fields[name]
+		values := []json.RawMessage{raw}
+		if trimmed := strings.TrimSpace(string(raw)); strings.HasPrefix(trimmed, "[") {
+			if err := json.Unmarshal(raw, &values); err != nil {
+				return nil, fmt.Errorf("multipart field %q: %w", name, err)
+			}
+		}
+
+		for _, value := range values {
+			part, err := parseMultipartPart(name, value)
+			if err != nil {
+				return nil, fmt.Errorf("multipart field %q: %w", name, err)
+			}
+			parts = append(parts, part)
+		}
+	}
+	return parts, nil
+}
+
+// parseMultipartPart reads a single value of a multipart payload field
+func parseMultipartPart(name string, raw json.RawMessage) (MultipartPart, error) {
+	if trimmed := strings.TrimSpace(string(raw)); !strings.HasPrefix(trimmed, "{") {
+		var v interface{}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return MultipartPart{}, err
+		}
+		value, err := formValue(v)
+		if err != nil {
+			return MultipartPart{}, err
+		}
+		return MultipartPart{Name: name, Value: value}, nil
+	}
+
+	var file MultipartFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return MultipartPart{}, err
+	}
+	location, err := url.Parse(file.URL)
+	if err != nil || location.Scheme != "s3" || location.Host == "" || strings.Trim(location.Path, "/") == "" {
+		return MultipartPart{}, fmt.Errorf("file parts need a url of the form s3://bucket/key")
+	}
+	file.bucket = location.Host
+	file.key = strings.TrimPrefix(location.Path, "/")
+	if file.Filename == "" {
+		file.Filename = path.Base(file.key)
+	}
+	if file.ContentType != "" {
+		if _, _, err := mime.ParseMediaType(file.ContentType); err != nil {
+			return MultipartPart{}, fmt.Errorf("invalid file content_type: %w", err)
+		}
+	}
+	return MultipartPart{Name: name, File: &file}, nil
+}
+
+// formEncode encodes a JSON object of scalars, or arrays of scalars, as form fields
+func formEncode(payload json.RawMessage) (string, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return "", fmt.Errorf("form-encoded payload must be a JSON object: %w", err)
+	}
+
+	form := url.Values{}
+	for key, value := range fields {
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+		for _, v := range values {
+			s, err := formValue(v)
+			if err != nil {
+				return "", fmt.Errorf("form field %q: %w", key, err)
+			}
+			form.Add(key, s)
+		}
+	}
+	return form.Encode(), nil
+}
+
+// formValue formats a scalar JSON value as a form value
+func formValue(v interface{}) (string, error) {
+	switch value := v.(type) {
+	case string:
+		return value, nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case nil:
+		return "", nil
+	}
+	return "", fmt.Errorf("nested objects can't be form-encoded")
+}
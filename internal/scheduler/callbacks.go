@@ -0,0 +1,238 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// Callback delivery limits
+const (
+	callbackBatchSize   = 50
+	callbackConcurrency = 8
+	callbackBaseBackoff = 30 * time.Second
+	callbackMaxBackoff  = time.Hour
+)
+
+// CallbackEvent is the event name of execution result callbacks
+const CallbackEvent = "execution.finished"
+
+// CallbackPayload is the JSON body posted to callback routes when an execution finishes
+type CallbackPayload struct {
+	Event         string                 `json:"event"`
+	ExecutionID   uuid.UUID              `json:"execution_id"`
+	JobID         uuid.UUID              `json:"job_id"`
+	JobName       string                 `json:"job_name"`
+	TenantID      uuid.UUID              `json:"tenant_id"`
+	CorrelationID string                 `json:"correlation_id,omitempty"`
+	Tags          []string               `json:"tags,omitempty"`
+	Status        models.ExecutionStatus `json:"status"`
+	Attempt       int                    `json:"attempt"`
+	ScheduledAt   time.Time              `json:"scheduled_at"`
+	StartedAt     *time.Time             `json:"started_at,omitempty"`
+	CompletedAt   *time.Time             `json:"completed_at,omitempty"`
+	Duration      *int64                 `json:"duration_ms,omitempty"`
+	StatusCode    *int                   `json:"status_code,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+	Response      json.RawMessage        `json:"response,omitempty"` // Only for routes with include_response
+	TraceID       string                 `json:"trace_id,omitempty"`
+}
+
+// queueCallback queues the result of a finished execution for the first of its tenant's
+// callback routes that matches the job. The execution is the one read back after its outcome
+// was recorded, so the callback reports what was recorded.
+func (s *Scheduler) queueCallback(ctx context.Context, job *models.Job, execution *models.JobExecution) error {
+	routes, err := s.callbackRepo.FindEnabledRoutes(ctx, job.TenantID)
+	if err != nil {
+		return fmt.Errorf("failed to find callback routes: %w", err)
+	}
+
+	var route *models.CallbackRoute
+	for i := range routes {
+		if routes[i].Matches(job) {
+			route = &routes[i]
+			break
+		}
+	}
+	if route == nil {
+		return nil
+	}
+
+	switch execution.Status {
+	case models.ExecutionStatusCompleted, models.ExecutionStatusFailed, models.ExecutionStatusTimeout:
+	default:
+		return nil
+	}
+	if !route.Sends(execution.Status) {
+		return nil
+	}
+
+	payload := CallbackPayload{
+		Event:         CallbackEvent,
+		ExecutionID:   execution.ID,
+		JobID:         job.ID,
+		JobName:       job.Name,
+		TenantID:      job.TenantID,
+		CorrelationID: execution.CorrelationID,
+		Tags:          job.TagList(),
+		Status:        execution.Status,
+		Attempt:       execution.Attempt,
+		ScheduledAt:   execution.ScheduledAt,
+		StartedAt:     execution.StartedAt,
+		CompletedAt:   execution.CompletedAt,
+		Duration:      execution.Duration,
+		StatusCode:    execution.StatusCode,
+		Error:         execution.Error,
+		TraceID:       execution.TraceID,
+	}
+	if route.IncludeResponse {
+		payload.Response = execution.Response
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	delivery := &models.CallbackDelivery{
+		ID:            uuid.New(),
+		TenantID:      job.TenantID,
+		RouteID:       route.ID,
+		JobID:         job.ID,
+		ExecutionID:   execution.ID,
+		URL:           route.URL,
+		Headers:       route.Headers,
+		Payload:       body,
+		Status:        models.CallbackStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := s.callbackRepo.Enqueue(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to queue callback: %w", err)
+	}
+	return nil
+}
+
+// callbackLoop delivers queued callbacks. Every instance delivers; each claims its own rows.
+func (s *Scheduler) callbackLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(s.config.Callbacks.PollSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.deliverCallbacks(s.ctx)
+		}
+	}
+}
+
+// deliverCallbacks attempts a batch of due callbacks, a few at a time
+func (s *Scheduler) deliverCallbacks(ctx context.Context) {
+	// A claim outlives the attempt, so the delivery isn't picked up again while it's in flight
+	lease := s.callbackClient.Timeout + time.Minute
+	deliveries, err := s.callbackRepo.ClaimDue(ctx, lease, callbackBatchSize)
+	if err != nil {
+		log.Printf("scheduler: failed to claim callbacks: %v", err)
+		return
+	}
+
+	slots := make(chan struct{}, callbackConcurrency)
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			s.deliverCallback(ctx, delivery)
+		}()
+	}
+	wg.Wait()
+}
+
+// deliverCallback makes one attempt at a callback and records the outcome. Failed attempts
+// back off exponentially until CALLBACK_MAX_ATTEMPTS is reached.
+func (s *Scheduler) deliverCallback(ctx context.Context, delivery *models.CallbackDelivery) {
+	attempts := delivery.Attempts + 1
+	err := s.postCallback(ctx, delivery)
+	if err == nil {
+		dispatchMetrics.Add("callbacks_delivered", 1)
+		if err := s.callbackRepo.MarkDelivered(ctx, delivery.ID, attempts); err != nil {
+			log.Printf("scheduler: failed to record callback %s as delivered: %v", delivery.ID, err)
+		}
+		return
+	}
+
+	var next *time.Time
+	if attempts < s.config.Callbacks.MaxAttempts {
+		backoff := callbackBaseBackoff << (attempts - 1)
+		if backoff > callbackMaxBackoff || backoff <= 0 {
+			backoff = callbackMaxBackoff
+		}
+		at := time.Now().Add(backoff)
+		next = &at
+	} else {
+		dispatchMetrics.Add("callbacks_failed", 1)
+	}
+	if err := s.callbackRepo.MarkAttemptFailed(ctx, delivery.ID, attempts, err.Error(), next); err != nil {
+		log.Printf("scheduler: failed to record callback %s attempt: %v", delivery.ID, err)
+	}
+}
+
+// postCallback posts a callback's payload to its URL, within the egress rules of its tenant;
+// any status other than 2xx fails it
+func (s *Scheduler) postCallback(ctx context.Context, delivery *models.CallbackDelivery) error {
+	ctx, err := s.WithEgress(ctx, delivery.TenantID)
+	if err != nil {
+		return err
+	}
+
+	var headers map[string]string
+	if len(delivery.Headers) > 0 {
+		if err := json.Unmarshal(delivery.Headers, &headers); err != nil {
+			return fmt.Errorf("invalid callback headers: %w", err)
+		}
+	}
+	headers, err = expandSecrets(ctx, s.secrets, delivery.TenantID, headers)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fmt.Errorf("failed to create callback request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentTypeJSON)
+	req.Header.Set("User-Agent", "Minisource-Scheduler/1.0")
+	req.Header.Set("X-Scheduler-Event", CallbackEvent)
+	req.Header.Set("X-Scheduler-Delivery-ID", delivery.ID.String())
+	req.Header.Set("X-Scheduler-Job-ID", delivery.JobID.String())
+	req.Header.Set("X-Scheduler-Tenant-ID", delivery.TenantID.String())
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.callbackClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback endpoint returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
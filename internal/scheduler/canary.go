@@ -0,0 +1,123 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// RunCanary holds a job's schedule and starts a canary execution of it right away. The canary
+// runs once, without retries, and isn't counted in the job's counters, history, incidents or
+// alerts, nor sent to result callbacks. When it passes the job's schedule starts; when it
+// fails the job keeps waiting for another canary or a confirmation. The canary is queued like
+// a manual trigger when the tenant is over its rate limit.
+func (s *Scheduler) RunCanary(ctx context.Context, job *models.Job) (*models.JobExecution, error) {
+	execution := &models.JobExecution{
+		ID:            uuid.New(),
+		JobID:         job.ID,
+		TenantID:      job.TenantID,
+		Status:        models.ExecutionStatusPending,
+		ScheduledAt:   time.Now(),
+		Attempt:       1,
+		Request:       SnapshotRequest(job),
+		Canary:        true,
+		WorkerGroup:   s.groupOf(job),
+		CorrelationID: job.CorrelationID,
+	}
+	if !s.admit(ctx, job.TenantID) {
+		execution.Status = models.ExecutionStatusQueued
+	} else {
+		execution.ClaimedBy, execution.ClaimedUntil = s.localClaim(execution.WorkerGroup)
+	}
+
+	if err := s.executionRepo.Create(ctx, execution); err != nil {
+		return nil, err
+	}
+	if err := s.jobRepo.HoldForCanary(ctx, job.ID, execution.ID); err != nil {
+		return nil, err
+	}
+	job.Status = models.JobStatusCanary
+	job.CanaryExecutionID = &execution.ID
+	job.ResumeAt = nil
+	if execution.Status == models.ExecutionStatusQueued {
+		return execution, nil
+	}
+
+	task := JobTask{
+		Job:       *job,
+		Execution: *execution,
+	}
+	database.AfterCommit(ctx, func() { s.submit(task) })
+
+	return execution, nil
+}
+
+// ActivateCanary starts the schedule of a job held for its canary, from the next run after
+// now, so runs that fell due while it waited aren't treated as missed. With executionID set,
+// the job is only activated while that execution is still its canary. It returns false when
+// the job is no longer held.
+func (s *Scheduler) ActivateCanary(ctx context.Context, job *models.Job, executionID *uuid.UUID) (bool, error) {
+	active := *job
+	active.Status = models.JobStatusActive
+	nextRunAt, err := s.CalculateNextRun(ctx, &active)
+	if err != nil {
+		return false, err
+	}
+	return s.jobRepo.ActivateCanary(ctx, job.ID, executionID, nextRunAt)
+}
+
+// finishCanary records the outcome of a canary execution and starts the job's schedule when
+// it passed
+func (s *Scheduler) finishCanary(ctx context.Context, task *JobTask, result *ExecutionResult, err error) {
+	id := task.Execution.ID
+	var ended bool
+	var finishErr error
+	switch {
+	case err == nil:
+		var statusCode int
+		var response []byte
+		if result != nil {
+			statusCode = result.StatusCode
+			response = result.Body
+			if projected, err := ApplyProjection(response, task.Job.ResponseProjection); err == nil {
+				response = projected
+			}
+		}
+		ended, finishErr = s.executionRepo.MarkAsCompleted(ctx, id, statusCode, response)
+	case errors.Is(err, errExecutionTimedOut):
+		ended, finishErr = s.executionRepo.MarkAsTimedOut(ctx, id, err.Error())
+	default:
+		var statusCode *int
+		if result != nil {
+			statusCode = &result.StatusCode
+		}
+		ended, finishErr = s.executionRepo.MarkAsFailed(ctx, id, err.Error(), statusCode)
+	}
+	if finishErr != nil {
+		log.Printf("scheduler: failed to record the outcome of canary execution %s: %v", id, finishErr)
+		return
+	}
+	if !ended {
+		return
+	}
+	if err != nil {
+		dispatchMetrics.Add("canaries_failed", 1)
+		return
+	}
+
+	dispatchMetrics.Add("canaries_passed", 1)
+	activated, err := s.ActivateCanary(ctx, &task.Job, &id)
+	if err != nil {
+		log.Printf("scheduler: failed to activate job %s after its canary passed: %v", task.Job.ID, err)
+		return
+	}
+	if activated {
+		log.Printf("scheduler: activated job %s (%s), whose canary execution %s passed", task.Job.ID, task.Job.Name, id)
+		s.recordRevision(ctx, task.Job.ID, models.JobRevisionActivated)
+	}
+}
@@ -0,0 +1,170 @@
+//go:build integration
+// +build integration
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// testDB connects to the database in TEST_POSTGRES_DSN and migrates it. Each test works on
+// a tenant of its own, so tests can share the database.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, database.AutoMigrate(db))
+	return db
+}
+
+// createDueJobs creates jobs of a tenant that came due a minute ago
+func createDueJobs(t *testing.T, db *gorm.DB, tenantID uuid.UUID, n int) []models.Job {
+	t.Helper()
+	dueAt := time.Now().Add(-time.Minute)
+	jobs := make([]models.Job, n)
+	for i := range jobs {
+		jobs[i] = models.Job{
+			TenantID:  tenantID,
+			Name:      fmt.Sprintf("due-%d", i),
+			Type:      models.JobTypeInterval,
+			Status:    models.JobStatusActive,
+			Schedule:  "1m",
+			Endpoint:  "https://api.example.com/run",
+			NextRunAt: &dueAt,
+		}
+		require.NoError(t, repository.NewJobRepository(db).Create(context.Background(), &jobs[i]))
+	}
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM job_executions WHERE tenant_id = ?", tenantID)
+		db.Exec("DELETE FROM jobs WHERE tenant_id = ?", tenantID)
+		db.Exec("DELETE FROM tenant_settings WHERE tenant_id = ?", tenantID)
+	})
+	return jobs
+}
+
+func TestClaimDueJobs(t *testing.T) {
+	db := testDB(t)
+	repo := repository.NewJobRepository(db)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	createDueJobs(t, db, tenantID, 40)
+	tenants := []uuid.UUID{tenantID}
+
+	// Instances claiming at once never get the same job
+	var mu sync.Mutex
+	claimedBy := make(map[uuid.UUID]string)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		owner := fmt.Sprintf("instance-%d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				jobs, err := repo.ClaimDueJobs(ctx, owner, time.Now(), time.Minute, tenants, 5)
+				if !assert.NoError(t, err) || len(jobs) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, job := range jobs {
+					assert.NotContains(t, claimedBy, job.ID, "job %s claimed twice", job.ID)
+					claimedBy[job.ID] = owner
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, claimedBy, 40)
+
+	// Leased jobs aren't claimed again until their claims are released
+	jobs, err := repo.ClaimDueJobs(ctx, "instance-late", time.Now(), time.Minute, tenants, 100)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	var released []uuid.UUID
+	for id, owner := range claimedBy {
+		if owner == "instance-0" {
+			released = append(released, id)
+		}
+	}
+	require.NoError(t, repo.ReleaseClaims(ctx, "instance-1", released), "releasing another instance's claims")
+	jobs, err = repo.ClaimDueJobs(ctx, "instance-late", time.Now(), time.Minute, tenants, 100)
+	require.NoError(t, err)
+	assert.Empty(t, jobs, "only the owner releases its claims")
+
+	require.NoError(t, repo.ReleaseClaims(ctx, "instance-0", released))
+	jobs, err = repo.ClaimDueJobs(ctx, "instance-late", time.Now(), time.Minute, tenants, 100)
+	require.NoError(t, err)
+	assert.Len(t, jobs, len(released))
+}
+
+func TestClaimDueJobsLeaseExpires(t *testing.T) {
+	db := testDB(t)
+	repo := repository.NewJobRepository(db)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	createDueJobs(t, db, tenantID, 3)
+	tenants := []uuid.UUID{tenantID}
+
+	// An instance that died holding claims loses them when the lease ends
+	jobs, err := repo.ClaimDueJobs(ctx, "crashed", time.Now(), 50*time.Millisecond, tenants, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+
+	time.Sleep(100 * time.Millisecond)
+	jobs, err = repo.ClaimDueJobs(ctx, "survivor", time.Now(), time.Minute, tenants, 10)
+	require.NoError(t, err)
+	assert.Len(t, jobs, 3)
+	for _, job := range jobs {
+		assert.Equal(t, "survivor", job.ClaimedBy)
+	}
+}
+
+func TestClaimDueJobsScope(t *testing.T) {
+	db := testDB(t)
+	repo := repository.NewJobRepository(db)
+	ctx := context.Background()
+	claiming, other, paused := uuid.New(), uuid.New(), uuid.New()
+	createDueJobs(t, db, claiming, 2)
+	createDueJobs(t, db, other, 2)
+	createDueJobs(t, db, paused, 2)
+
+	pausedAt := time.Now()
+	require.NoError(t, repository.NewTenantRepository(db).SetPaused(ctx, paused, &pausedAt, "frozen"))
+
+	// Only the tenants the claim_dispatch flag is on for are claimed
+	jobs, err := repo.ClaimDueJobs(ctx, "instance", time.Now(), time.Minute, []uuid.UUID{claiming}, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	for _, job := range jobs {
+		assert.Equal(t, claiming, job.TenantID)
+	}
+
+	// Paused tenants' jobs are never claimed
+	jobs, err = repo.ClaimDueJobs(ctx, "instance", time.Now(), time.Minute, []uuid.UUID{paused}, 10)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	// Jobs not yet due aren't claimed
+	jobs, err = repo.ClaimDueJobs(ctx, "instance", time.Now().Add(-time.Hour), time.Minute, []uuid.UUID{other}, 10)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
@@ -0,0 +1,264 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// commandWaitDelay bounds how long a cancelled command may keep its output pipes open
+const commandWaitDelay = 5 * time.Second
+
+// CommandTarget is the target_config of command jobs. A missing command is read from the
+// job's metadata.
+type CommandTarget struct {
+	Command string `json:"command"` // Name of an entry in COMMAND_ALLOWLIST the tenant was granted
+}
+
+// reservedCommandEnv are the variables command jobs can't set: the ones the scheduler sets
+// itself, and the ones that make the dynamic loader or a shell run code of the job's choosing
+var reservedCommandEnv = map[string]bool{
+	"PATH": true, "SCHEDULER_JOB_ID": true, "SCHEDULER_TENANT_ID": true,
+	"TRACEPARENT": true, "TRACESTATE": true, "BAGGAGE": true,
+	"BASH_ENV": true, "ENV": true, "IFS": true, "CDPATH": true, "GLOBIGNORE": true,
+	"SHELLOPTS": true, "BASHOPTS": true, "PS4": true, "PROMPT_COMMAND": true,
+	"GCONV_PATH": true, "LOCPATH": true, "HOSTALIASES": true, "RESOLV_HOST_CONF": true,
+	"PYTHONPATH": true, "PYTHONSTARTUP": true, "PERL5LIB": true, "PERL5OPT": true,
+	"RUBYLIB": true, "RUBYOPT": true, "NODE_OPTIONS": true, "JAVA_TOOL_OPTIONS": true,
+}
+
+// reservedCommandEnvPrefixes are prefixes of loader and shell variables command jobs can't set
+var reservedCommandEnvPrefixes = []string{"LD_", "DYLD_", "MALLOC_", "BASH_FUNC_"}
+
+// checkCommandEnv rejects variables command jobs can't set
+func checkCommandEnv(name string) error {
+	upper := strings.ToUpper(name)
+	if reservedCommandEnv[upper] {
+		return fmt.Errorf("command env can't set %s", name)
+	}
+	for _, prefix := range reservedCommandEnvPrefixes {
+		if strings.HasPrefix(upper, prefix) {
+			return fmt.Errorf("command env can't set %s: %s* variables are reserved", name, prefix)
+		}
+	}
+	return nil
+}
+
+// CommandPayload is the payload of command jobs
+type CommandPayload struct {
+	Args []string          `json:"args,omitempty"`
+	Env  map[string]string `json:"env,omitempty"`
+}
+
+// ParseCommandTarget reads a command job's target from its target_config and metadata
+func ParseCommandTarget(job *models.Job) (*CommandTarget, error) {
+	var target, fallback CommandTarget
+	if len(job.TargetConfig) > 0 {
+		if err := json.Unmarshal(job.TargetConfig, &target); err != nil {
+			return nil, fmt.Errorf("invalid command target_config: %w", err)
+		}
+	}
+	if len(job.Metadata) > 0 {
+		// Metadata is free-form, so fields of the wrong type are ignored
+		_ = json.Unmarshal(job.Metadata, &fallback)
+	}
+
+	if target.Command == "" {
+		target.Command = fallback.Command
+	}
+
+	if target.Command == "" {
+		return nil, fmt.Errorf("command targets need a command in target_config or metadata")
+	}
+	if job.Body != "" {
+		return nil, fmt.Errorf("command targets take args and env in payload, not a raw body")
+	}
+	if len(job.Payload) > 0 {
+		if _, err := parseCommandPayload(job.Payload); err != nil {
+			return nil, err
+		}
+	}
+	return &target, nil
+}
+
+// parseCommandPayload reads a command job's args and env
+func parseCommandPayload(payload json.RawMessage) (*CommandPayload, error) {
+	var parsed CommandPayload
+	if len(payload) == 0 {
+		return &parsed, nil
+	}
+	if err := json.Unmarshal(payload, &parsed); err != nil {
+		return nil, fmt.Errorf("command payload must be {\"args\": [...], \"env\": {...}}: %w", err)
+	}
+	for name := range parsed.Env {
+		if name == "" || strings.ContainsAny(name, "=\x00") {
+			return nil, fmt.Errorf("invalid command env name %q", name)
+		}
+		if err := checkCommandEnv(name); err != nil {
+			return nil, err
+		}
+	}
+	return &parsed, nil
+}
+
+// commandPath looks a command name up in COMMAND_ALLOWLIST
+func (e *Executor) commandPath(name string) (string, error) {
+	if strings.TrimSpace(e.config.Commands.Allowlist) == "" {
+		return "", fmt.Errorf("command targets are disabled: COMMAND_ALLOWLIST is empty")
+	}
+	for _, entry := range strings.Split(e.config.Commands.Allowlist, ",") {
+		entryName, path, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if ok && strings.TrimSpace(entryName) == name {
+			return strings.TrimSpace(path), nil
+		}
+	}
+	return "", fmt.Errorf("command %q is not in COMMAND_ALLOWLIST", name)
+}
+
+// executeCommand runs an allowlisted command with the job's args and env. The exit code is
+// recorded as the status code, and stdout and stderr are kept in the result body.
+func (e *Executor) executeCommand(ctx context.Context, job *models.Job) (*ExecutionResult, error) {
+	startTime := time.Now()
+	result := &ExecutionResult{}
+
+	fail := func(span trace.Span, err error) (*ExecutionResult, error) {
+		result.Error = err.Error()
+		result.Duration = time.Since(startTime).Milliseconds()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, result.Error)
+		return result, err
+	}
+
+	target, err := ParseCommandTarget(job)
+	if err != nil {
+		return fail(trace.SpanFromContext(ctx), err)
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, "command "+target.Command,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attribute.String("process.command", target.Command)),
+	)
+	defer span.End()
+
+	if err := e.checkTargetAccess(ctx, job); err != nil {
+		return fail(span, err)
+	}
+	path, err := e.commandPath(target.Command)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	job, err = e.resolveSecrets(ctx, job)
+	if err != nil {
+		return fail(span, err)
+	}
+	payload, err := parseCommandPayload(job.Payload)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	// The scheduler's own environment holds credentials, so commands get only PATH, the
+	// job's env and the job identity. The job's env goes first so the rest can't be replaced.
+	env := make(map[string]string, len(payload.Env)+4)
+	for key, value := range payload.Env {
+		env[key] = value
+	}
+	env["PATH"] = os.Getenv("PATH")
+	env["SCHEDULER_JOB_ID"] = job.ID.String()
+	env["SCHEDULER_TENANT_ID"] = job.TenantID.String()
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	for key, value := range carrier {
+		env[strings.ToUpper(key)] = value
+	}
+
+	cmd := exec.CommandContext(ctx, path, payload.Args...)
+	cmd.Dir = e.config.Commands.WorkDir
+	cmd.WaitDelay = commandWaitDelay
+	cmd.Env = make([]string, 0, len(env))
+	for key, value := range env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	sort.Strings(cmd.Env)
+
+	stdout := &cappedBuffer{limit: e.config.Commands.OutputBytes}
+	stderr := &cappedBuffer{limit: e.config.Commands.OutputBytes}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	runErr := cmd.Run()
+
+	exitCode := 0
+	var exitErr *exec.ExitError
+	switch {
+	case runErr == nil:
+	case errors.As(runErr, &exitErr) && exitErr.ExitCode() >= 0:
+		exitCode = exitErr.ExitCode()
+	default:
+		// Not started, killed by a signal or cancelled
+		if ctx.Err() != nil {
+			runErr = fmt.Errorf("command %s: %w", target.Command, ctx.Err())
+		}
+		return fail(span, fmt.Errorf("failed to run command %s: %w", target.Command, runErr))
+	}
+
+	result.StatusCode = exitCode
+	result.Body, _ = json.Marshal(map[string]interface{}{
+		"exit_code": exitCode,
+		"stdout":    stdout.String(),
+		"stderr":    stderr.String(),
+		"truncated": stdout.truncated || stderr.truncated,
+	})
+	result.Duration = time.Since(startTime).Milliseconds()
+	span.SetAttributes(attribute.Int("process.exit.code", exitCode))
+
+	if exitCode != 0 {
+		// A failed execution keeps only the error, so it carries the end of stderr
+		tail := strings.TrimSpace(stderr.String())
+		if len(tail) > 512 {
+			tail = "..." + tail[len(tail)-512:]
+		}
+		result.Error = fmt.Sprintf("command exited with code %d", exitCode)
+		if tail != "" {
+			result.Error += ": " + tail
+		}
+		span.SetStatus(codes.Error, result.Error)
+		return result, errors.New(result.Error)
+	}
+	return result, nil
+}
+
+// cappedBuffer keeps the first limit bytes written to it and discards the rest
+type cappedBuffer struct {
+	buf       []byte
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - len(b.buf); room < len(p) {
+		if room > 0 {
+			b.buf = append(b.buf, p[:room]...)
+		}
+		b.truncated = true
+		return len(p), nil
+	}
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string { return string(b.buf) }
@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommandPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		wantErr bool
+	}{
+		{name: "empty", payload: ""},
+		{name: "args and env", payload: `{"args": ["--force"], "env": {"LOG_LEVEL": "info"}}`},
+		{name: "not an object", payload: `["--force"]`, wantErr: true},
+		{name: "empty env name", payload: `{"env": {"": "x"}}`, wantErr: true},
+		{name: "env name with equals", payload: `{"env": {"A=B": "x"}}`, wantErr: true},
+		{name: "PATH", payload: `{"env": {"PATH": "/tmp/evil"}}`, wantErr: true},
+		{name: "lowercase path", payload: `{"env": {"path": "/tmp/evil"}}`, wantErr: true},
+		{name: "job identity", payload: `{"env": {"SCHEDULER_TENANT_ID": "other"}}`, wantErr: true},
+		{name: "LD_PRELOAD", payload: `{"env": {"LD_PRELOAD": "/tmp/evil.so"}}`, wantErr: true},
+		{name: "LD_LIBRARY_PATH", payload: `{"env": {"LD_LIBRARY_PATH": "/tmp"}}`, wantErr: true},
+		{name: "DYLD_INSERT_LIBRARIES", payload: `{"env": {"DYLD_INSERT_LIBRARIES": "/tmp/evil.dylib"}}`, wantErr: true},
+		{name: "BASH_ENV", payload: `{"env": {"BASH_ENV": "/tmp/evil.sh"}}`, wantErr: true},
+		{name: "ENV", payload: `{"env": {"ENV": "/tmp/evil.sh"}}`, wantErr: true},
+		{name: "IFS", payload: `{"env": {"IFS": "/"}}`, wantErr: true},
+		{name: "exported function", payload: `{"env": {"BASH_FUNC_ls%%": "() { id; }"}}`, wantErr: true},
+		{name: "lookalike", payload: `{"env": {"ENVIRONMENT": "prod", "OLD_PATH": "/usr/bin"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseCommandPayload(json.RawMessage(tt.payload))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckTargetAccessCommand(t *testing.T) {
+	job := func(targetConfig, metadata string) *models.Job {
+		job := &models.Job{TargetType: models.TargetCommand, TargetConfig: json.RawMessage(targetConfig)}
+		if metadata != "" {
+			job.Metadata = json.RawMessage(metadata)
+		}
+		return job
+	}
+	limits := models.TenantLimits{CommandAllow: []string{"logrotate"}}
+
+	assert.NoError(t, checkTargetAccess(job(`{"command": "logrotate"}`, ""), limits, config.SQLConfig{}))
+	assert.NoError(t, checkTargetAccess(job(`{}`, `{"command": "logrotate"}`), limits, config.SQLConfig{}))
+	assert.ErrorIs(t, checkTargetAccess(job(`{"command": "backup"}`, ""), limits, config.SQLConfig{}), ErrTargetNotGranted)
+	assert.ErrorIs(t, checkTargetAccess(job(`{}`, `{"command": "backup"}`), limits, config.SQLConfig{}), ErrTargetNotGranted)
+	assert.ErrorIs(t, checkTargetAccess(job(`{"command": "logrotate"}`, ""), models.TenantLimits{}, config.SQLConfig{}), ErrTargetNotGranted)
+
+	// Other targets need no grant
+	assert.NoError(t, checkTargetAccess(&models.Job{TargetType: models.TargetHTTP, Endpoint: "https://api.example.com"}, models.TenantLimits{}, config.SQLConfig{}))
+}
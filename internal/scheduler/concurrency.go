@@ -0,0 +1,160 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// errExecutionCancelled is the cancellation cause for executions stopped by a cancel request
+var errExecutionCancelled = errors.New("execution cancelled")
+
+// errExecutionTimedOut is the cancellation cause for executions that ran past their job's timeout
+var errExecutionTimedOut = errors.New("execution timed out")
+
+// trackExecution registers the cancel function of an execution running on this instance
+func (s *Scheduler) trackExecution(id uuid.UUID, cancel context.CancelCauseFunc) {
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+	s.active[id] = cancel
+}
+
+// untrackExecution removes a finished execution from the active set
+func (s *Scheduler) untrackExecution(id uuid.UUID) {
+	s.activeMu.Lock()
+	cancel, ok := s.active[id]
+	delete(s.active, id)
+	s.activeMu.Unlock()
+
+	if ok {
+		cancel(nil)
+	}
+}
+
+// retryWait is an execution on this instance waiting for its next attempt
+type retryWait struct {
+	timer *time.Timer
+	job   models.Job
+}
+
+// scheduleRetry runs an execution's next attempt after a delay. Until then the execution
+// can be cancelled like a running one.
+func (s *Scheduler) scheduleRetry(task JobTask, delay time.Duration) {
+	id := task.Execution.ID
+
+	s.activeMu.Lock()
+	defer s.activeMu.Unlock()
+	s.retrying[id] = retryWait{
+		job: task.Job,
+		timer: time.AfterFunc(delay, func() {
+			s.activeMu.Lock()
+			_, waiting := s.retrying[id]
+			delete(s.retrying, id)
+			s.activeMu.Unlock()
+
+			if !waiting {
+				return
+			}
+			task.Execution.Attempt++
+			s.workerPool.Submit(task)
+		}),
+	}
+}
+
+// CancelExecution stops an execution if it is running or waiting to retry on this instance.
+// Executions on other instances are stopped when they next sync cancellations.
+func (s *Scheduler) CancelExecution(id uuid.UUID) bool {
+	s.activeMu.Lock()
+	cancel, running := s.active[id]
+	wait, waiting := s.retrying[id]
+	delete(s.retrying, id)
+	s.activeMu.Unlock()
+
+	if running {
+		cancel(errExecutionCancelled)
+	}
+	if waiting && wait.timer.Stop() {
+		s.completeOneTime(s.ctx, &wait.job)
+	}
+	return running || waiting
+}
+
+// cancelSyncInterval is how often executions on this instance are checked for cancellation
+const cancelSyncInterval = 2 * time.Second
+
+// cancellationLoop stops local executions soon after they are cancelled on another instance
+func (s *Scheduler) cancellationLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(cancelSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.syncCancellations(s.ctx)
+		}
+	}
+}
+
+// syncCancellations stops local executions that were cancelled elsewhere
+func (s *Scheduler) syncCancellations(ctx context.Context) {
+	s.activeMu.Lock()
+	ids := make([]uuid.UUID, 0, len(s.active)+len(s.retrying))
+	for id := range s.active {
+		ids = append(ids, id)
+	}
+	for id := range s.retrying {
+		ids = append(ids, id)
+	}
+	s.activeMu.Unlock()
+
+	if len(ids) == 0 {
+		return
+	}
+	cancelled, err := s.executionRepo.FindCancelledIDs(ctx, ids)
+	if err != nil {
+		return
+	}
+	for _, id := range cancelled {
+		s.CancelExecution(id)
+	}
+}
+
+// applyConcurrencyPolicy enforces a job's concurrency policy before a scheduled run.
+// It reports whether the run should go ahead.
+func (s *Scheduler) applyConcurrencyPolicy(ctx context.Context, job *models.Job) bool {
+	if job.ConcurrencyPolicy != models.ConcurrencyForbid && job.ConcurrencyPolicy != models.ConcurrencyReplace {
+		return true
+	}
+
+	active, err := s.executionRepo.FindActiveByJobID(ctx, job.ID, time.Now().Add(-s.activeWindow(job)))
+	if err != nil || len(active) == 0 {
+		return true
+	}
+
+	if job.ConcurrencyPolicy == models.ConcurrencyForbid {
+		return false
+	}
+
+	for _, execution := range active {
+		if err := s.executionRepo.CancelExecution(ctx, execution.ID); err != nil {
+			continue
+		}
+		s.CancelExecution(execution.ID)
+	}
+	return true
+}
+
+// activeWindow bounds how long an execution can legitimately stay active, so executions
+// orphaned by a crashed worker don't block a forbid job forever
+func (s *Scheduler) activeWindow(job *models.Job) time.Duration {
+	attempts := time.Duration(job.MaxRetries + 1)
+	perAttempt := time.Duration(job.Timeout+s.config.Scheduler.RetryDelaySeconds) * time.Second
+	return attempts*perAttempt + time.Duration(s.config.Scheduler.LockTTLSeconds)*time.Second
+}
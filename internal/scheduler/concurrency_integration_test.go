@@ -0,0 +1,79 @@
+//go:build integration
+// +build integration
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyConcurrencyPolicy(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	executions := repository.NewExecutionRepository(db)
+	s := &Scheduler{
+		config:        &config.Config{Scheduler: config.SchedulerConfig{RetryDelaySeconds: 60, LockTTLSeconds: 30}},
+		executionRepo: executions,
+		active:        make(map[uuid.UUID]context.CancelCauseFunc),
+		retrying:      make(map[uuid.UUID]retryWait),
+		ctx:           ctx,
+	}
+
+	tests := []struct {
+		policy        models.ConcurrencyPolicy
+		wantRun       bool
+		wantCancelled bool
+	}{
+		{policy: models.ConcurrencyAllow, wantRun: true},
+		{policy: models.ConcurrencyForbid, wantRun: false},
+		{policy: models.ConcurrencyReplace, wantRun: true, wantCancelled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			tenantID := uuid.New()
+			job := createDueJobs(t, db, tenantID, 1)[0]
+			job.ConcurrencyPolicy = tt.policy
+			job.Timeout, job.MaxRetries = 30, 1
+
+			// Without an active execution every policy runs
+			assert.True(t, s.applyConcurrencyPolicy(ctx, &job))
+
+			running := &models.JobExecution{
+				ID:          uuid.New(),
+				JobID:       job.ID,
+				TenantID:    tenantID,
+				Status:      models.ExecutionStatusRunning,
+				ScheduledAt: time.Now().Add(-time.Minute),
+				Attempt:     1,
+			}
+			require.NoError(t, executions.Create(ctx, running))
+
+			// The running execution is cancelled on this instance too
+			var cause error
+			s.trackExecution(running.ID, func(err error) { cause = err })
+			defer s.untrackExecution(running.ID)
+
+			assert.Equal(t, tt.wantRun, s.applyConcurrencyPolicy(ctx, &job))
+
+			stored, err := executions.FindByID(ctx, running.ID)
+			require.NoError(t, err)
+			if tt.wantCancelled {
+				assert.Equal(t, models.ExecutionStatusCancelled, stored.Status)
+				assert.ErrorIs(t, cause, errExecutionCancelled)
+			} else {
+				assert.Equal(t, models.ExecutionStatusRunning, stored.Status)
+				assert.NoError(t, cause)
+			}
+		})
+	}
+}
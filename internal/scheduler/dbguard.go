@@ -0,0 +1,161 @@
+package scheduler
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"sync"
+	"time"
+)
+
+// Dispatch states reported by DispatchHealth
+const (
+	DispatchStateOK       = "ok"
+	DispatchStateDegraded = "degraded"
+	DispatchStatePaused   = "paused"
+)
+
+// dispatchMetrics counts scheduling loop outcomes, served at /debug/vars
+var dispatchMetrics = expvar.NewMap("scheduler_dispatch")
+
+// DispatchHealth is a snapshot of the scheduling loop's database health
+type DispatchHealth struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	PausedUntil         *time.Time `json:"paused_until,omitempty"`
+	DroppedTicks        int64      `json:"dropped_ticks"`
+}
+
+// dispatchGuard is a circuit breaker over the scheduling loop's database calls. After
+// too many failed ticks in a row it pauses dispatch for a cooldown, then lets one tick
+// through to probe whether the database is back.
+type dispatchGuard struct {
+	mu            sync.Mutex
+	failures      int
+	lastErr       string
+	lastErrAt     time.Time
+	lastSuccessAt time.Time
+	openUntil     time.Time
+	dropped       int64
+}
+
+// allow reports whether this tick may dispatch, counting it as dropped if not
+func (g *dispatchGuard) allow(now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if now.Before(g.openUntil) {
+		g.dropped++
+		dispatchMetrics.Add("dropped_ticks", 1)
+		return false
+	}
+	return true
+}
+
+// failure records a failed tick, opening the circuit once the threshold is reached
+func (g *dispatchGuard) failure(err error, threshold int, cooldown time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	g.failures++
+	g.lastErr = err.Error()
+	g.lastErrAt = now
+	g.dropped++
+	dispatchMetrics.Add("dropped_ticks", 1)
+
+	if threshold > 0 && g.failures >= threshold {
+		g.openUntil = now.Add(cooldown)
+		dispatchMetrics.Add("circuit_opens", 1)
+		log.Printf("scheduler: pausing dispatch until %s after %d failed ticks: %v",
+			g.openUntil.Format(time.RFC3339), g.failures, err)
+	}
+}
+
+// success records a tick that completed and closes the circuit
+func (g *dispatchGuard) success() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.failures > 0 {
+		log.Printf("scheduler: database recovered after %d failed ticks", g.failures)
+	}
+	g.failures = 0
+	g.openUntil = time.Time{}
+	g.lastSuccessAt = time.Now()
+}
+
+// snapshot returns the guard's current state
+func (g *dispatchGuard) snapshot() DispatchHealth {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	health := DispatchHealth{
+		State:               DispatchStateOK,
+		ConsecutiveFailures: g.failures,
+		LastError:           g.lastErr,
+		DroppedTicks:        g.dropped,
+	}
+	if !g.lastErrAt.IsZero() {
+		at := g.lastErrAt
+		health.LastErrorAt = &at
+	}
+	if !g.lastSuccessAt.IsZero() {
+		at := g.lastSuccessAt
+		health.LastSuccessAt = &at
+	}
+	switch {
+	case time.Now().Before(g.openUntil):
+		until := g.openUntil
+		health.State = DispatchStatePaused
+		health.PausedUntil = &until
+	case g.failures > 0:
+		health.State = DispatchStateDegraded
+	}
+	return health
+}
+
+// DispatchHealth reports whether the scheduling loop is reaching the database
+func (s *Scheduler) DispatchHealth() DispatchHealth {
+	return s.guard.snapshot()
+}
+
+// retryDB runs a scheduling loop database operation, retrying with exponential backoff
+func (s *Scheduler) retryDB(ctx context.Context, op string, fn func() error) error {
+	attempts := s.config.Scheduler.DBRetryAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := time.Duration(s.config.Scheduler.DBRetryBackoffMs) * time.Millisecond
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		dispatchMetrics.Add("db_errors", 1)
+		if attempt == attempts {
+			break
+		}
+
+		dispatchMetrics.Add("db_retries", 1)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	log.Printf("scheduler: %s failed after %d attempts: %v", op, attempts, err)
+	return err
+}
+
+// tickFailed records a scheduling tick lost to a database error
+func (s *Scheduler) tickFailed(err error) {
+	s.guard.failure(err, s.config.Scheduler.DBFailureThreshold,
+		time.Duration(s.config.Scheduler.DBCircuitCooldownSeconds)*time.Second)
+}
@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"context"
+	"net/url"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/egress"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// egressDestinations returns the hosts a job's requests go to, as far as its definition says
+func egressDestinations(job *models.Job) []string {
+	var urls []string
+	switch job.TargetType {
+	case "", models.TargetHTTP, models.TargetGraphQL, models.TargetSOAP:
+		urls = append(urls, job.Endpoint)
+	case models.TargetReport:
+		if target, err := ParseReportTarget(job); err == nil {
+			urls = append(urls, target.DataURL)
+			if target.Deliver.Webhook != nil {
+				urls = append(urls, target.Deliver.Webhook.URL)
+			}
+		}
+	}
+
+	var hosts []string
+	for _, raw := range urls {
+		if parsed, err := url.Parse(raw); err == nil && parsed.Hostname() != "" {
+			hosts = append(hosts, parsed.Hostname())
+		}
+	}
+	return hosts
+}
+
+// CheckEgress checks the destinations of a job against its tenant's egress policy without
+// resolving them. The requests are checked again when they are made, against the addresses
+// the hosts resolve to.
+func (s *Scheduler) CheckEgress(ctx context.Context, job *models.Job) error {
+	policy, err := s.tenantEgress(ctx, job.TenantID)
+	if err != nil {
+		return err
+	}
+	for _, host := range egressDestinations(job) {
+		if err := policy.CheckHost(host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithEgress returns a context whose requests, and endpoint checks with CheckReachable, are
+// checked against the egress policy of a tenant
+func (s *Scheduler) WithEgress(ctx context.Context, tenantID uuid.UUID) (context.Context, error) {
+	policy, err := s.tenantEgress(ctx, tenantID)
+	if err != nil {
+		return ctx, err
+	}
+	return egress.WithPolicy(ctx, policy), nil
+}
+
+// tenantEgress returns the egress policy of a tenant's requests
+func (s *Scheduler) tenantEgress(ctx context.Context, tenantID uuid.UUID) (*egress.Policy, error) {
+	limits := models.TenantLimits{}
+	if s.tenants != nil {
+		limits = s.tenants.Limits(ctx, tenantID)
+	}
+	return egressPolicy(egress.DenyRules(s.config.Egress), limits)
+}
+
+// egressPolicy returns the policy of a tenant's requests: the service-wide deny rules and
+// the tenant's own allow and deny rules
+func egressPolicy(deny []egress.Rule, limits models.TenantLimits) (*egress.Policy, error) {
+	tenantDeny, err := egress.ParseRules(limits.EgressDeny)
+	if err != nil {
+		return nil, err
+	}
+	allow, err := egress.ParseRules(limits.EgressAllow)
+	if err != nil {
+		return nil, err
+	}
+	return &egress.Policy{Deny: append(slices.Clip(deny), tenantDeny...), Allow: allow}, nil
+}
+
+// withEgress returns a context whose requests are checked against the egress policy of a
+// job's tenant
+func (e *Executor) withEgress(ctx context.Context, tenantID uuid.UUID) (context.Context, error) {
+	limits := models.TenantLimits{}
+	if e.tenants != nil {
+		limits = e.tenants.Limits(ctx, tenantID)
+	}
+	policy, err := egressPolicy(e.egressDeny, limits)
+	if err != nil {
+		return ctx, err
+	}
+	return egress.WithPolicy(ctx, policy), nil
+}
@@ -0,0 +1,180 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/mail"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EmailTarget is the target_config of email jobs. Subject, Text and HTML are Go templates
+// rendered with the job and its payload. Fields missing from target_config are read from
+// the job's metadata under the same names.
+type EmailTarget struct {
+	To      []string `json:"to"`
+	Cc      []string `json:"cc,omitempty"`
+	Subject string   `json:"subject"`
+	Text    string   `json:"text,omitempty"` // Plain text body template
+	HTML    string   `json:"html,omitempty"` // HTML body template, values are escaped
+}
+
+// templateData is what email and report templates are rendered with
+type templateData struct {
+	Job struct {
+		ID   uuid.UUID
+		Name string
+		Tags []string
+	}
+	Data interface{} // The job's payload, or the data a report was fetched from
+	Now  time.Time
+}
+
+// ParseEmailTarget reads an email job's target from its target_config and metadata
+func ParseEmailTarget(job *models.Job) (*EmailTarget, error) {
+	var target, fallback EmailTarget
+	if len(job.TargetConfig) > 0 {
+		if err := json.Unmarshal(job.TargetConfig, &target); err != nil {
+			return nil, fmt.Errorf("invalid email target_config: %w", err)
+		}
+	}
+	if len(job.Metadata) > 0 {
+		// Metadata is free-form, so fields of the wrong type are ignored
+		_ = json.Unmarshal(job.Metadata, &fallback)
+	}
+
+	if len(target.To) == 0 {
+		target.To = fallback.To
+	}
+	if len(target.Cc) == 0 {
+		target.Cc = fallback.Cc
+	}
+	if target.Subject == "" {
+		target.Subject = fallback.Subject
+	}
+	if target.Text == "" && target.HTML == "" {
+		target.Text, target.HTML = fallback.Text, fallback.HTML
+	}
+
+	if len(target.To) == 0 || target.Subject == "" {
+		return nil, fmt.Errorf("email targets need to and subject in target_config or metadata")
+	}
+	if target.Text == "" && target.HTML == "" {
+		return nil, fmt.Errorf("email targets need a text or html template")
+	}
+	if _, err := mail.ParseAddresses(append(append([]string(nil), target.To...), target.Cc...)); err != nil {
+		return nil, err
+	}
+	if job.Body != "" {
+		return nil, fmt.Errorf("email targets take template data in payload, not a raw body")
+	}
+	if _, err := renderEmail(target, job); err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// renderEmail renders an email target's templates for a job
+func renderEmail(target EmailTarget, job *models.Job) (*mail.Message, error) {
+	var data templateData
+	data.Job.ID, data.Job.Name, data.Job.Tags = job.ID, job.Name, job.TagList()
+	data.Now = time.Now()
+	if len(job.Payload) > 0 {
+		if err := json.Unmarshal(job.Payload, &data.Data); err != nil {
+			return nil, fmt.Errorf("invalid email payload: %w", err)
+		}
+	}
+
+	msg := &mail.Message{To: target.To, Cc: target.Cc}
+	for _, field := range []struct {
+		name string
+		src  string
+		dst  *string
+	}{
+		{"subject", target.Subject, &msg.Subject},
+		{"text", target.Text, &msg.Text},
+	} {
+		if field.src == "" {
+			continue
+		}
+		tmpl, err := texttemplate.New(field.name).Option("missingkey=zero").Parse(field.src)
+		if err != nil {
+			return nil, fmt.Errorf("invalid email %s template: %w", field.name, err)
+		}
+		var out strings.Builder
+		if err := tmpl.Execute(&out, data); err != nil {
+			return nil, fmt.Errorf("failed to render email %s: %w", field.name, err)
+		}
+		*field.dst = out.String()
+	}
+
+	if target.HTML != "" {
+		tmpl, err := htmltemplate.New("html").Option("missingkey=zero").Parse(target.HTML)
+		if err != nil {
+			return nil, fmt.Errorf("invalid email html template: %w", err)
+		}
+		var out strings.Builder
+		if err := tmpl.Execute(&out, data); err != nil {
+			return nil, fmt.Errorf("failed to render email html: %w", err)
+		}
+		msg.HTML = out.String()
+	}
+	return msg, nil
+}
+
+// executeEmail renders a job's email templates with its payload and sends the email. The
+// run succeeds once the SMTP server accepts the message for every recipient.
+func (e *Executor) executeEmail(ctx context.Context, job *models.Job) (*ExecutionResult, error) {
+	startTime := time.Now()
+	result := &ExecutionResult{}
+
+	fail := func(span trace.Span, err error) (*ExecutionResult, error) {
+		result.Error = err.Error()
+		result.Duration = time.Since(startTime).Milliseconds()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, result.Error)
+		return result, err
+	}
+
+	target, err := ParseEmailTarget(job)
+	if err != nil {
+		return fail(trace.SpanFromContext(ctx), err)
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, "email send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Int("email.recipients", len(target.To)+len(target.Cc))),
+	)
+	defer span.End()
+
+	job, err = e.resolveSecrets(ctx, job)
+	if err != nil {
+		return fail(span, err)
+	}
+	msg, err := renderEmail(*target, job)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	if err := e.mail.Send(ctx, msg); err != nil {
+		return fail(span, err)
+	}
+
+	result.Body, _ = json.Marshal(map[string]interface{}{
+		"to":      target.To,
+		"cc":      target.Cc,
+		"subject": msg.Subject,
+	})
+	result.Duration = time.Since(startTime).Milliseconds()
+	return result, nil
+}
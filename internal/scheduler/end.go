@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// ended reports whether a recurring job has passed its end time or had its maximum number of
+// successful runs
+func ended(job *models.Job, now time.Time) bool {
+	if job.EndAt != nil && !now.Before(*job.EndAt) {
+		return true
+	}
+	return job.MaxRuns > 0 && job.RunCount >= job.MaxRuns
+}
+
+// endJob disables a job that has ended, reporting whether it did. The check is repeated in
+// the update, so a job whose end was moved or whose limit was raised meanwhile keeps running.
+func (s *Scheduler) endJob(ctx context.Context, job *models.Job) bool {
+	disabled, err := s.jobRepo.DisableEnded(ctx, job.ID, time.Now())
+	if err != nil {
+		log.Printf("scheduler: failed to disable ended job %s: %v", job.ID, err)
+		return false
+	}
+	if !disabled {
+		return false
+	}
+	dispatchMetrics.Add("jobs_ended", 1)
+	log.Printf("scheduler: disabled job %s (%s), which reached its end date or maximum runs", job.ID, job.Name)
+	s.recordRevision(ctx, job.ID, models.JobRevisionEnded)
+	return true
+}
+
+// capRuns drops the runs beyond those a job still has left under its maximum
+func capRuns(job *models.Job, runs []time.Time) []time.Time {
+	if job.MaxRuns <= 0 {
+		return runs
+	}
+	if left := job.MaxRuns - job.RunCount; left < int64(len(runs)) {
+		return runs[:max(left, 1)]
+	}
+	return runs
+}
+
+// clampToEnd moves a recurring job's next run back to its end time when it falls after it,
+// so the job is disabled on time rather than at its next occurrence
+func clampToEnd(job *models.Job, next time.Time) time.Time {
+	if job.EndAt != nil && next.After(*job.EndAt) {
+		return *job.EndAt
+	}
+	return next
+}
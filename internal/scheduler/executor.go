@@ -4,13 +4,25 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/egress"
+	"github.com/minisource/scheduler/internal/mail"
 	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/secrets"
+	"github.com/minisource/scheduler/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ExecutionResult represents the result of a job execution
@@ -22,52 +34,193 @@ type ExecutionResult struct {
 	Error      string
 }
 
-// Executor executes HTTP-based jobs
+// SecretResolver decrypts the secrets a job references
+type SecretResolver interface {
+	ResolveSecrets(ctx context.Context, tenantID uuid.UUID, names []string) (map[string]string, error)
+}
+
+// Executor delivers jobs to their targets
 type Executor struct {
-	config *config.Config
-	client *http.Client
+	config     *config.Config
+	client     *http.Client
+	transport  http.RoundTripper // Transport of client before metering, cloned for jobs with TLS settings
+	transports *transportCache
+	proxy      func(*http.Request) (*url.URL, error) // Proxy of the requests, nil when there is none
+	egressDeny []egress.Rule                         // EGRESS_DENY, applied to every tenant's requests
+	secrets    SecretResolver
+	tenants    TenantLimits // Whether tenants may skip TLS verification and their egress rules
+	kafka      *kafkaProducer
+	nats       *natsPublisher
+	amqp       *amqpPublisher
+	sql        *sqlDatasources
+	mail       *mail.Mailer
+	objects    *objectStore
 }
 
-// NewExecutor creates a new executor
-func NewExecutor(cfg *config.Config, client *http.Client) *Executor {
+// NewExecutor creates a new executor. Traffic of requests made with client is counted in the
+// usage of the attempts making them.
+func NewExecutor(cfg *config.Config, client *http.Client, secrets SecretResolver, tenants TenantLimits) *Executor {
 	if client == nil {
 		client = &http.Client{
 			Timeout: 30 * time.Second,
 		}
 	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	var proxy func(*http.Request) (*url.URL, error)
+	if transport, ok := base.(*http.Transport); ok {
+		var proxies map[string]bool
+		proxy, proxies = egress.ProxyFromConfig(cfg.Egress)
+		base = egress.CheckedTransport(transport, proxy, proxies)
+	}
+	metered := *client
+	metered.Transport = meteredTransport{base: egress.Transport{Base: base, Proxy: proxy}}
 
 	return &Executor{
-		config: cfg,
-		client: client,
+		config:     cfg,
+		client:     &metered,
+		transport:  base,
+		transports: newTransportCache(),
+		proxy:      proxy,
+		egressDeny: egress.DenyRules(cfg.Egress),
+		secrets:    secrets,
+		tenants:    tenants,
+		kafka:      newKafkaProducer(cfg.Kafka),
+		nats:       newNATSPublisher(cfg.NATS),
+		amqp:       newAMQPPublisher(cfg.AMQP),
+		sql:        newSQLDatasources(cfg.SQL),
+		mail:       mail.NewMailer(cfg.SMTP),
+		objects:    newObjectStore(cfg.ObjectStorage),
 	}
 }
 
-// Execute executes a job and returns the result
+// Close releases connections held for message targets and jobs with TLS settings
+func (e *Executor) Close() error {
+	e.transports.close()
+	return errors.Join(e.kafka.Close(), e.nats.Close(), e.amqp.Close(), e.sql.Close())
+}
+
+// Validate checks that a job names a target the Executor can deliver to
+func (e *Executor) Validate(job *models.Job) error {
+	return ValidateTarget(job)
+}
+
+// Execute executes a job and returns the result. Jobs of target types registered with
+// RegisterExecutor are delivered by their executor, so the Executor is itself a TargetExecutor
+// for every target type.
 func (e *Executor) Execute(ctx context.Context, job *models.Job) (*ExecutionResult, error) {
+	switch job.TargetType {
+	case models.TargetKafka:
+		return e.executeKafka(ctx, job)
+	case models.TargetNATS:
+		return e.executeNATS(ctx, job)
+	case models.TargetAMQP:
+		return e.executeAMQP(ctx, job)
+	case models.TargetCommand:
+		return e.executeCommand(ctx, job)
+	case models.TargetSQL:
+		return e.executeSQL(ctx, job)
+	case models.TargetEmail:
+		return e.executeEmail(ctx, job)
+	case models.TargetGraphQL:
+		return e.executeGraphQL(ctx, job)
+	case models.TargetSOAP:
+		return e.executeSOAP(ctx, job)
+	case models.TargetReport:
+		return e.executeReport(ctx, job)
+	}
+	if executor := registeredExecutor(job.TargetType); executor != nil {
+		return executeRegistered(ctx, executor, job)
+	}
+	return e.executeHTTP(ctx, job)
+}
+
+// executeHTTP calls a job's endpoint and returns the response
+func (e *Executor) executeHTTP(ctx context.Context, job *models.Job) (*ExecutionResult, error) {
 	startTime := time.Now()
 	result := &ExecutionResult{}
 
+	sink, err := ParseResponseSink(job)
+	if err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+	if ctx, err = e.withEgress(ctx, job.TenantID); err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, "HTTP "+job.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", job.Method),
+			attribute.String("url.full", job.Endpoint),
+		),
+	)
+	defer span.End()
+
 	// Build request
 	req, err := e.buildRequest(ctx, job)
 	if err != nil {
 		result.Error = err.Error()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, result.Error)
+		return result, err
+	}
+
+	client, err := e.clientFor(ctx, job)
+	if err != nil {
+		result.Error = err.Error()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, result.Error)
 		return result, err
 	}
 
+	// Propagate the trace to the target endpoint
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
 	// Execute request
-	resp, err := e.client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		result.Error = err.Error()
 		result.Duration = time.Since(startTime).Milliseconds()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, result.Error)
 		return result, err
 	}
 	defer resp.Body.Close()
 
+	// Stream successful responses to the job's sink, keeping only where they went
+	if sink != nil && resp.StatusCode < 400 {
+		object, err := e.streamResponse(ctx, job, sink, resp)
+		if err != nil {
+			result.Error = err.Error()
+			result.Duration = time.Since(startTime).Milliseconds()
+			span.RecordError(err)
+			span.SetStatus(codes.Error, result.Error)
+			return result, err
+		}
+
+		result.StatusCode = resp.StatusCode
+		result.Body, _ = json.Marshal(map[string]interface{}{"sink": object})
+		result.Headers = resp.Header
+		result.Duration = time.Since(startTime).Milliseconds()
+		span.SetAttributes(
+			attribute.Int("http.response.status_code", resp.StatusCode),
+			attribute.Int64("http.response.body.size", object.Size),
+		)
+		return result, nil
+	}
+
 	// Read response
 	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20)) // Limit to 1MB
 	if err != nil {
 		result.Error = err.Error()
 		result.Duration = time.Since(startTime).Milliseconds()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, result.Error)
 		return result, err
 	}
 
@@ -76,9 +229,12 @@ func (e *Executor) Execute(ctx context.Context, job *models.Job) (*ExecutionResu
 	result.Headers = resp.Header
 	result.Duration = time.Since(startTime).Milliseconds()
 
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
 	// Check for error status codes
 	if resp.StatusCode >= 400 {
 		result.Error = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+		span.SetStatus(codes.Error, result.Error)
 		return result, fmt.Errorf("%s", result.Error)
 	}
 
@@ -89,14 +245,35 @@ func (e *Executor) Execute(ctx context.Context, job *models.Job) (*ExecutionResu
 func (e *Executor) buildRequest(ctx context.Context, job *models.Job) (*http.Request, error) {
 	var body io.Reader
 
-	// Parse payload
-	if len(job.Payload) > 0 {
-		body = bytes.NewReader(job.Payload)
+	// Resolve secret references on a copy so plaintext never reaches the stored job
+	job, err := e.resolveSecrets(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encode the payload or raw body; multipart file parts are streamed from object storage
+	var contentType string
+	if isMultipart(job) {
+		if body, contentType, err = e.multipartBody(ctx, job); err != nil {
+			return nil, err
+		}
+	} else {
+		content, ct, err := RequestBody(job)
+		if err != nil {
+			return nil, err
+		}
+		if content != nil {
+			body = bytes.NewReader(content)
+		}
+		contentType = ct
 	}
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, job.Method, job.Endpoint, body)
 	if err != nil {
+		if closer, ok := body.(io.Closer); ok {
+			closer.Close()
+		}
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
@@ -105,9 +282,9 @@ func (e *Executor) buildRequest(ctx context.Context, job *models.Job) (*http.Req
 	req.Header.Set("X-Scheduler-Job-ID", job.ID.String())
 	req.Header.Set("X-Scheduler-Tenant-ID", job.TenantID.String())
 
-	// Set content type if payload exists
-	if len(job.Payload) > 0 {
-		req.Header.Set("Content-Type", "application/json")
+	// Set content type if a body exists; custom headers may still override it
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
 	}
 
 	// Parse and apply custom headers
@@ -123,6 +300,59 @@ func (e *Executor) buildRequest(ctx context.Context, job *models.Job) (*http.Req
 	return req, nil
 }
 
+// resolveSecrets returns a copy of the job with {{secret:NAME}} references in its headers,
+// payload and body replaced by their values
+func (e *Executor) resolveSecrets(ctx context.Context, job *models.Job) (*models.Job, error) {
+	names := secrets.Refs(string(job.Headers), string(job.Payload), job.Body)
+	if len(names) == 0 {
+		return job, nil
+	}
+	if e.secrets == nil {
+		return nil, secrets.ErrDisabled
+	}
+
+	values, err := e.secrets.ResolveSecrets(ctx, job.TenantID, names)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := *job
+	resolved.Body = secrets.Expand(job.Body, values)
+	if len(job.Payload) > 0 {
+		resolved.Payload = json.RawMessage(secrets.ExpandJSON(string(job.Payload), values))
+	}
+	if len(job.Headers) > 0 {
+		resolved.Headers = json.RawMessage(secrets.ExpandJSON(string(job.Headers), values))
+	}
+	return &resolved, nil
+}
+
+// expandSecrets resolves secret references in a set of values of a tenant, such as the
+// headers of a report webhook or callback route
+func expandSecrets(ctx context.Context, resolver SecretResolver, tenantID uuid.UUID, values map[string]string) (map[string]string, error) {
+	var all []string
+	for _, value := range values {
+		all = append(all, value)
+	}
+	names := secrets.Refs(all...)
+	if len(names) == 0 {
+		return values, nil
+	}
+	if resolver == nil {
+		return nil, secrets.ErrDisabled
+	}
+
+	resolved, err := resolver.ResolveSecrets(ctx, tenantID, names)
+	if err != nil {
+		return nil, err
+	}
+	expanded := make(map[string]string, len(values))
+	for key, value := range values {
+		expanded[key] = secrets.Expand(value, resolved)
+	}
+	return expanded, nil
+}
+
 // ExecuteWithRetry executes a job with retry logic
 func (e *Executor) ExecuteWithRetry(ctx context.Context, job *models.Job, maxRetries int, retryDelay time.Duration) (*ExecutionResult, error) {
 	var lastErr error
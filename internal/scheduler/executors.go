@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// TargetExecutor delivers jobs of one target type. The Executor delivers the built-in target
+// types itself; deployments add their own, such as "snowflake_query" or "sap_rfc", by
+// registering an executor from an init function in a file of their own, e.g.
+// cmd/executor_snowflake.go:
+//
+//	func init() { scheduler.RegisterExecutor("snowflake_query", snowflakeExecutor{}) }
+//
+// Jobs then name the type in target_type and carry its settings in target_config.
+type TargetExecutor interface {
+	// Validate checks a job's target when the job is created or updated. Only TargetType,
+	// TargetConfig, Endpoint and Metadata are set.
+	Validate(job *models.Job) error
+
+	// Execute delivers the job within its timeout. An error fails the attempt, which is
+	// retried per the job's retry policy; return a result with Error set alongside it so the
+	// execution records what went wrong.
+	Execute(ctx context.Context, job *models.Job) (*ExecutionResult, error)
+}
+
+// TargetHoster is implemented by executors that can name where a job is delivered, such as
+// a warehouse or system ID, for grouping incidents by host. Jobs of executors that don't
+// implement it are grouped by their target type.
+type TargetHoster interface {
+	Host(job *models.Job) string
+}
+
+// builtinTargets are the target types the Executor delivers itself
+var builtinTargets = map[models.TargetType]bool{
+	"":                   true,
+	models.TargetHTTP:    true,
+	models.TargetKafka:   true,
+	models.TargetNATS:    true,
+	models.TargetAMQP:    true,
+	models.TargetCommand: true,
+	models.TargetSQL:     true,
+	models.TargetEmail:   true,
+	models.TargetGraphQL: true,
+	models.TargetSOAP:    true,
+	models.TargetReport:  true,
+}
+
+// maxTargetTypeLength is the longest target type the jobs table stores
+const maxTargetTypeLength = 20
+
+var (
+	executorsMu sync.RWMutex
+	executors   = map[models.TargetType]TargetExecutor{}
+)
+
+// RegisterExecutor adds an executor for jobs of a target type. Registering a built-in target
+// type, a type registered before or one longer than 20 characters panics.
+func RegisterExecutor(targetType models.TargetType, executor TargetExecutor) {
+	executorsMu.Lock()
+	defer executorsMu.Unlock()
+
+	switch {
+	case builtinTargets[targetType]:
+		panic(fmt.Sprintf("scheduler: target type %q is built in", targetType))
+	case len(targetType) > maxTargetTypeLength:
+		panic(fmt.Sprintf("scheduler: target type %q is longer than %d characters", targetType, maxTargetTypeLength))
+	case executors[targetType] != nil:
+		panic(fmt.Sprintf("scheduler: executor for target type %s registered twice", targetType))
+	}
+	executors[targetType] = executor
+}
+
+// ExecutorNames lists the target types registered with RegisterExecutor, sorted
+func ExecutorNames() []string {
+	executorsMu.RLock()
+	defer executorsMu.RUnlock()
+
+	names := make([]string, 0, len(executors))
+	for targetType := range executors {
+		names = append(names, string(targetType))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// registeredExecutor returns the executor registered for a target type, or nil
+func registeredExecutor(targetType models.TargetType) TargetExecutor {
+	executorsMu.RLock()
+	defer executorsMu.RUnlock()
+	return executors[targetType]
+}
+
+// executeRegistered delivers a job with a registered executor, timing the attempt if the
+// executor didn't. A panicking executor fails the attempt rather than the worker.
+func executeRegistered(ctx context.Context, executor TargetExecutor, job *models.Job) (result *ExecutionResult, err error) {
+	startTime := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("executor %s panicked: %v", job.TargetType, r)
+			result = &ExecutionResult{Error: err.Error()}
+		}
+	}()
+
+	result, err = executor.Execute(ctx, job)
+	if result == nil {
+		result = &ExecutionResult{}
+	}
+	if result.Duration == 0 {
+		result.Duration = time.Since(startTime).Milliseconds()
+	}
+	if err != nil && result.Error == "" {
+		result.Error = err.Error()
+	}
+	return result, err
+}
@@ -0,0 +1,170 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GraphQLTarget is the target_config of graphql jobs. The job's payload holds the
+// variables, so the document can stay the same while they change. Fields missing from
+// target_config are read from the job's metadata under the same names.
+type GraphQLTarget struct {
+	Query         string `json:"query"`                    // Query or mutation document
+	OperationName string `json:"operation_name,omitempty"` // Operation to run when the document holds several
+}
+
+// graphQLRequest is the body of a GraphQL request over HTTP
+type graphQLRequest struct {
+	Query         string          `json:"query"`
+	OperationName string          `json:"operationName,omitempty"`
+	Variables     json.RawMessage `json:"variables,omitempty"`
+}
+
+// graphQLResponse is the part of a GraphQL response that decides whether a run failed
+type graphQLResponse struct {
+	Errors []struct {
+		Message string        `json:"message"`
+		Path    []interface{} `json:"path,omitempty"`
+	} `json:"errors"`
+}
+
+// ParseGraphQLTarget reads a graphql job's target from its target_config and metadata
+func ParseGraphQLTarget(job *models.Job) (*GraphQLTarget, error) {
+	var target, fallback GraphQLTarget
+	if len(job.TargetConfig) > 0 {
+		if err := json.Unmarshal(job.TargetConfig, &target); err != nil {
+			return nil, fmt.Errorf("invalid graphql target_config: %w", err)
+		}
+	}
+	if len(job.Metadata) > 0 {
+		// Metadata is free-form, so fields of the wrong type are ignored
+		_ = json.Unmarshal(job.Metadata, &fallback)
+	}
+
+	if target.Query == "" {
+		target.Query = fallback.Query
+	}
+	if target.OperationName == "" {
+		target.OperationName = fallback.OperationName
+	}
+
+	if strings.TrimSpace(target.Query) == "" {
+		return nil, fmt.Errorf("graphql targets need a query in target_config or metadata")
+	}
+	if job.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required for graphql targets")
+	}
+	if parsed, err := url.Parse(job.Endpoint); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("endpoint must be an absolute URL")
+	}
+	return &target, nil
+}
+
+// validateGraphQLBody checks that a graphql job's payload can be sent as its variables
+func validateGraphQLBody(job *models.Job) error {
+	if job.Body != "" {
+		return fmt.Errorf("graphql targets take variables in payload, not a raw body")
+	}
+	if job.ContentType != "" {
+		if mediaType, _, err := mime.ParseMediaType(job.ContentType); err != nil || mediaType != contentTypeJSON {
+			return fmt.Errorf("graphql targets are sent as application/json")
+		}
+	}
+	if len(job.Payload) > 0 && string(job.Payload) != "null" {
+		var variables map[string]json.RawMessage
+		if err := json.Unmarshal(job.Payload, &variables); err != nil {
+			return fmt.Errorf("graphql variables must be a JSON object: %w", err)
+		}
+	}
+	return nil
+}
+
+// executeGraphQL posts a job's document and variables to its endpoint. GraphQL servers
+// report most errors with HTTP 200, so a response with entries in its errors array fails
+// the run like an error status does.
+func (e *Executor) executeGraphQL(ctx context.Context, job *models.Job) (*ExecutionResult, error) {
+	target, err := ParseGraphQLTarget(job)
+	if err != nil {
+		return &ExecutionResult{Error: err.Error()}, err
+	}
+
+	operation := target.OperationName
+	if operation == "" {
+		operation = "anonymous"
+	}
+	ctx, span := tracing.Tracer().Start(ctx, "graphql "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("graphql.operation.name", target.OperationName)),
+	)
+	defer span.End()
+
+	var variables json.RawMessage
+	if len(job.Payload) > 0 && string(job.Payload) != "null" {
+		variables = job.Payload
+	}
+	body, err := json.Marshal(graphQLRequest{
+		Query:         target.Query,
+		OperationName: target.OperationName,
+		Variables:     variables,
+	})
+	if err != nil {
+		return &ExecutionResult{Error: err.Error()}, err
+	}
+
+	// Send the document through the HTTP path so headers, secrets and limits apply as usual
+	request := *job
+	request.Method = http.MethodPost
+	request.Payload = body
+	request.Body = ""
+	request.BodyEncoding = ""
+	request.ContentType = contentTypeJSON
+
+	result, err := e.executeHTTP(ctx, &request)
+	if graphQLErr := graphQLErrors(result.Body); graphQLErr != "" {
+		if err != nil {
+			result.Error = err.Error() + ": " + graphQLErr
+		} else {
+			result.Error = graphQLErr
+		}
+		err = fmt.Errorf("%s", result.Error)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, result.Error)
+	}
+	return result, err
+}
+
+// graphQLErrors summarizes the errors array of a GraphQL response, or returns "" when it
+// has none
+func graphQLErrors(body []byte) string {
+	var response graphQLResponse
+	if len(body) == 0 || json.Unmarshal(body, &response) != nil || len(response.Errors) == 0 {
+		return ""
+	}
+
+	first := response.Errors[0]
+	message := "GraphQL error: " + first.Message
+	if len(first.Path) > 0 {
+		path := make([]string, len(first.Path))
+		for i, segment := range first.Path {
+			path[i] = fmt.Sprint(segment)
+		}
+		message += " (at " + strings.Join(path, ".") + ")"
+	}
+	if len(response.Errors) > 1 {
+		message += fmt.Sprintf(" and %d more", len(response.Errors)-1)
+	}
+	return message
+}
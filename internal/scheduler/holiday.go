@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// holidayMaxSkips bounds the holidays skipped in a row looking for a job's next occurrence
+const holidayMaxSkips = 1000
+
+// cronNext returns a cron job's schedule as a function from a time to the next occurrence
+// after it, skipping the dates of the job's holiday calendar and moved by its schedule shift
+func (s *Scheduler) cronNext(ctx context.Context, job *models.Job) (func(time.Time) time.Time, error) {
+	schedule, err := s.cronParser.Parse(job.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression: %w", err)
+	}
+
+	next := schedule.Next
+	if holidays := s.holidays(ctx, job); len(holidays) > 0 {
+		next = skipHolidays(next, holidays, jobLocation(job))
+	}
+	return shiftedNext(job, next), nil
+}
+
+// holidays loads the dates of a job's holiday calendar. If they can't be loaded, the job runs
+// on its plain schedule rather than stalling.
+func (s *Scheduler) holidays(ctx context.Context, job *models.Job) models.Holidays {
+	if job.HolidayCalendar == "" {
+		return nil
+	}
+	calendar, err := s.calendarRepo.FindByTenantAndName(ctx, job.TenantID, job.HolidayCalendar)
+	if err != nil {
+		log.Printf("scheduler: failed to load holiday calendar %s of job %s: %v", job.HolidayCalendar, job.ID, err)
+		return nil
+	}
+	return models.NewHolidays(calendar.HolidayList())
+}
+
+// skipHolidays wraps a schedule's next function so occurrences on a holiday, by their date in
+// loc, are skipped. A schedule that only fires on holidays has no next occurrence.
+func skipHolidays(next func(time.Time) time.Time, holidays models.Holidays, loc *time.Location) func(time.Time) time.Time {
+	return func(after time.Time) time.Time {
+		t := next(after)
+		for i := 0; !t.IsZero() && holidays.On(t.In(loc)); i++ {
+			if i == holidayMaxSkips {
+				return time.Time{}
+			}
+			// Move past the rest of the holiday at once
+			day := t.In(loc)
+			endOfDay := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, loc)
+			t = next(endOfDay.Add(-time.Nanosecond))
+		}
+		return t
+	}
+}
+
+// jobLocation returns the location of a job's timezone, UTC when it is unset or unknown
+func jobLocation(job *models.Job) *time.Location {
+	if loc, err := time.LoadLocation(job.Timezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// Hook runs company-specific logic around every execution attempt, such as adding an auth
+// header the organisation's gateways expect or recording attempts in a proprietary audit
+// system. Deployments compile their hooks in by registering them from an init function in a
+// file of their own, e.g. cmd/hooks_acme.go:
+//
+//	func init() { scheduler.RegisterHook(acmeAudit{}) }
+type Hook interface {
+	// Name identifies the hook in logs and errors
+	Name() string
+
+	// Before runs before an attempt calls its target, within the job's timeout. Changes to
+	// job apply to this attempt only, so a hook can e.g. add headers; replace Headers or
+	// Payload rather than editing them in place. An error fails the attempt without calling
+	// the target; it is retried like any other failed attempt.
+	Before(ctx context.Context, job *models.Job, execution *models.JobExecution) error
+
+	// After runs once the attempt has ended, with the target's result and the error it failed
+	// with, if any. It runs for attempts failed by a Before hook and for cancelled ones too,
+	// after the deadline, and can't change the outcome.
+	After(ctx context.Context, job *models.Job, execution *models.JobExecution, result *ExecutionResult, err error)
+}
+
+var (
+	hooksMu sync.RWMutex
+	hooks   []Hook
+)
+
+// RegisterHook adds a hook run around every execution attempt. Before hooks run in the order
+// they were registered and After hooks in reverse. Registering two hooks with the same name
+// panics.
+func RegisterHook(hook Hook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+
+	for _, registered := range hooks {
+		if registered.Name() == hook.Name() {
+			panic(fmt.Sprintf("scheduler: hook %s registered twice", hook.Name()))
+		}
+	}
+	hooks = append(hooks, hook)
+}
+
+// HookNames lists the registered hooks in the order they run before an attempt
+func HookNames() []string {
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+
+	names := make([]string, len(hooks))
+	for i, hook := range hooks {
+		names[i] = hook.Name()
+	}
+	return names
+}
+
+// registeredHooks returns the hooks registered so far
+func registeredHooks() []Hook {
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	return hooks
+}
+
+// runBeforeHooks runs the Before hooks until one fails. A hook that panics fails the attempt
+// rather than the worker.
+func runBeforeHooks(ctx context.Context, job *models.Job, execution *models.JobExecution) (err error) {
+	for _, hook := range registeredHooks() {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("panic: %v", r)
+				}
+			}()
+			err = hook.Before(ctx, job, execution)
+		}()
+		if err != nil {
+			dispatchMetrics.Add("hooks_failed", 1)
+			return fmt.Errorf("hook %s: %w", hook.Name(), err)
+		}
+	}
+	return nil
+}
+
+// runAfterHooks runs every After hook, last registered first. Panics are logged.
+func runAfterHooks(ctx context.Context, job *models.Job, execution *models.JobExecution, result *ExecutionResult, err error) {
+	registered := registeredHooks()
+	for i := len(registered) - 1; i >= 0; i-- {
+		hook := registered[i]
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					dispatchMetrics.Add("hooks_failed", 1)
+					log.Printf("scheduler: hook %s panicked after execution %s: %v", hook.Name(), execution.ID, r)
+				}
+			}()
+			hook.After(ctx, job, execution, result, err)
+		}()
+	}
+}
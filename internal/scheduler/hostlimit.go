@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// hostDeferDelay is how long an execution waits before trying a busy host again
+const hostDeferDelay = time.Second
+
+// hostLimiter caps the executions in flight per target host on this instance
+type hostLimiter struct {
+	limit    int
+	inFlight map[string]int
+	mu       sync.Mutex
+}
+
+// newHostLimiter creates a limiter; a limit below 1 disables it
+func newHostLimiter(limit int) *hostLimiter {
+	return &hostLimiter{
+		limit:    limit,
+		inFlight: make(map[string]int),
+	}
+}
+
+// tryAcquire takes a slot for a host without waiting. It returns false when the host is
+// at its limit.
+func (l *hostLimiter) tryAcquire(host string) (release func(), ok bool) {
+	if l.limit < 1 {
+		return func() {}, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight[host] >= l.limit {
+		return nil, false
+	}
+	l.inFlight[host]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			if l.inFlight[host]--; l.inFlight[host] <= 0 {
+				delete(l.inFlight, host)
+			}
+		})
+	}, true
+}
+
+// deferForHost puts an execution whose target host is busy back on the queue after a delay,
+// freeing the worker for executions bound elsewhere. The execution stays pending meanwhile.
+func (s *Scheduler) deferForHost(task JobTask) {
+	dispatchMetrics.Add("host_limited", 1)
+	time.AfterFunc(hostDeferDelay, func() {
+		if s.ctx.Err() != nil {
+			return
+		}
+		if !s.workerPool.Submit(task) {
+			// Queue full; keep the execution rather than dropping it
+			s.deferForHost(task)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package scheduler
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostLimiter(t *testing.T) {
+	l := newHostLimiter(2)
+
+	releaseA1, ok := l.tryAcquire("a.example.com")
+	require.True(t, ok)
+	releaseA2, ok := l.tryAcquire("a.example.com")
+	require.True(t, ok)
+
+	_, ok = l.tryAcquire("a.example.com")
+	assert.False(t, ok, "a host at its limit is refused")
+
+	releaseB, ok := l.tryAcquire("b.example.com")
+	assert.True(t, ok, "other hosts have slots of their own")
+
+	releaseA1()
+	releaseA1() // Releasing twice frees one slot only
+	releaseA3, ok := l.tryAcquire("a.example.com")
+	require.True(t, ok)
+	_, ok = l.tryAcquire("a.example.com")
+	assert.False(t, ok)
+
+	releaseA2()
+	releaseA3()
+	releaseB()
+	assert.Empty(t, l.inFlight, "idle hosts are forgotten")
+}
+
+func TestHostLimiterDisabled(t *testing.T) {
+	l := newHostLimiter(0)
+	for i := 0; i < 10; i++ {
+		release, ok := l.tryAcquire("a.example.com")
+		require.True(t, ok)
+		release()
+	}
+	assert.Empty(t, l.inFlight)
+}
+
+func TestHostLimiterConcurrent(t *testing.T) {
+	const limit = 3
+	l := newHostLimiter(limit)
+
+	var inFlight, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				release, ok := l.tryAcquire("a.example.com")
+				if !ok {
+					continue
+				}
+				n := inFlight.Add(1)
+				for {
+					seen := peak.Load()
+					if n <= seen || peak.CompareAndSwap(seen, n) {
+						break
+					}
+				}
+				inFlight.Add(-1)
+				release()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, peak.Load(), int32(limit))
+	assert.Empty(t, l.inFlight)
+}
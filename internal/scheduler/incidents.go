@@ -0,0 +1,136 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/notification"
+	"gorm.io/gorm"
+)
+
+// Incident grouping modes
+const (
+	IncidentGroupByJob  = "job"
+	IncidentGroupByHost = "host"
+)
+
+// incidentGroup returns the grouping key and target host for a job
+func (s *Scheduler) incidentGroup(job *models.Job) (string, string) {
+	host := targetHost(job)
+
+	if s.config.Incidents.GroupBy == IncidentGroupByHost {
+		return "host:" + host, host
+	}
+	return "job:" + job.ID.String(), host
+}
+
+// recordIncidentFailure groups a final execution failure into the open incident for its group
+func (s *Scheduler) recordIncidentFailure(ctx context.Context, job *models.Job, executionID uuid.UUID, errMsg string) {
+	if s.incidentRepo == nil {
+		return
+	}
+
+	groupKey, host := s.incidentGroup(job)
+	maxSamples := s.config.Incidents.SampleErrors
+
+	incident, err := s.incidentRepo.FindOpenByGroupKey(ctx, job.TenantID, groupKey)
+	if err == nil {
+		if err := s.incidentRepo.RecordFailure(ctx, incident, executionID, errMsg, maxSamples); err == nil {
+			s.notifyIncident(ctx, notification.EventIncidentUpdated, job, incident, executionID, errMsg)
+		}
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return
+	}
+
+	samples, _ := json.Marshal([]string{errMsg})
+	incident = &models.Incident{
+		ID:               uuid.New(),
+		TenantID:         job.TenantID,
+		GroupKey:         groupKey,
+		TargetHost:       host,
+		Status:           models.IncidentStatusOpen,
+		StartedAt:        time.Now(),
+		FailureCount:     1,
+		SampleErrors:     samples,
+		FirstExecutionID: executionID,
+		LastExecutionID:  executionID,
+	}
+	if s.config.Incidents.GroupBy != IncidentGroupByHost {
+		jobID := job.ID
+		incident.JobID = &jobID
+	}
+
+	if err := s.incidentRepo.Create(ctx, incident); err != nil {
+		// Another worker opened the incident concurrently; fold into it
+		if existing, findErr := s.incidentRepo.FindOpenByGroupKey(ctx, job.TenantID, groupKey); findErr == nil {
+			if err := s.incidentRepo.RecordFailure(ctx, existing, executionID, errMsg, maxSamples); err == nil {
+				s.notifyIncident(ctx, notification.EventIncidentUpdated, job, existing, executionID, errMsg)
+			}
+		}
+		return
+	}
+
+	s.notifyIncident(ctx, notification.EventIncidentOpened, job, incident, executionID, errMsg)
+}
+
+// resolveIncident closes the open incident for a job's group after a success
+func (s *Scheduler) resolveIncident(ctx context.Context, job *models.Job) {
+	if s.incidentRepo == nil {
+		return
+	}
+
+	groupKey, _ := s.incidentGroup(job)
+
+	incident, err := s.incidentRepo.FindOpenByGroupKey(ctx, job.TenantID, groupKey)
+	if err != nil {
+		return
+	}
+
+	resolved, err := s.incidentRepo.Resolve(ctx, job.TenantID, groupKey, time.Now())
+	if err != nil || resolved == 0 {
+		return
+	}
+
+	s.notifyIncident(ctx, notification.EventIncidentResolved, job, incident, uuid.Nil, "")
+}
+
+// notifyIncident hands an incident event to the notification dispatcher
+func (s *Scheduler) notifyIncident(ctx context.Context, kind notification.EventKind, job *models.Job, incident *models.Incident, executionID uuid.UUID, errMsg string) {
+	if s.notifier == nil {
+		return
+	}
+
+	incidentID := incident.ID
+	jobID := job.ID
+	event := notification.Event{
+		Kind:         kind,
+		TenantID:     job.TenantID,
+		JobID:        &jobID,
+		JobName:      job.Name,
+		JobTags:      job.TagList(),
+		IncidentID:   &incidentID,
+		GroupKey:     incident.GroupKey,
+		FailureCount: incident.FailureCount,
+		Message:      errMsg,
+		Timestamp:    time.Now(),
+	}
+	if executionID != uuid.Nil {
+		event.ExecutionID = &executionID
+	}
+
+	switch kind {
+	case notification.EventIncidentResolved:
+		event.Title = fmt.Sprintf("Job %q recovered after %d failures", job.Name, incident.FailureCount)
+	default:
+		event.Title = fmt.Sprintf("Job %q failing (%d consecutive failures)", job.Name, incident.FailureCount)
+	}
+
+	s.notifier.Notify(ctx, event)
+}
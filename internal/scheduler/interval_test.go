@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		want     time.Duration
+		wantErr  bool
+	}{
+		{name: "seconds", schedule: "90", want: 90 * time.Second},
+		{name: "seconds with spaces", schedule: " 30 ", want: 30 * time.Second},
+		{name: "duration", schedule: "5m", want: 5 * time.Minute},
+		{name: "compound duration", schedule: "2h30m", want: 150 * time.Minute},
+		{name: "one second", schedule: "1s", want: time.Second},
+		{name: "below one second", schedule: "500ms", wantErr: true},
+		{name: "zero", schedule: "0", wantErr: true},
+		{name: "negative", schedule: "-5", wantErr: true},
+		{name: "empty", schedule: "", wantErr: true},
+		{name: "cron expression", schedule: "*/5 * * * *", wantErr: true},
+		{name: "unknown unit", schedule: "5 minutes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseInterval(tt.schedule)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
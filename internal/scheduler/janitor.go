@@ -0,0 +1,231 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/notification"
+)
+
+const (
+	// overdueFloor is the least time a job must have gone without running to be reported, so
+	// the report is about dead schedules rather than outages, which alert rules cover
+	overdueFloor = 24 * time.Hour
+
+	// periodSamples is how many of a schedule's runs are examined for its longest gap
+	periodSamples = 8
+
+	// janitorNotifyLimit is the most jobs a janitor notification lists; the API lists all
+	janitorNotifyLimit = 20
+)
+
+// JanitorReport lists a tenant's jobs that look dead: active recurring jobs that haven't run
+// in JANITOR_OVERDUE_FACTOR times the longest gap of their schedule, jobs whose endpoint has
+// answered 404 for JANITOR_NOT_FOUND_DAYS and paused jobs unchanged for JANITOR_PAUSED_DAYS.
+// A job is listed once, for the first of these reasons that applies.
+func (s *Scheduler) JanitorReport(ctx context.Context, tenantID uuid.UUID) (*models.JanitorReport, error) {
+	now := time.Now()
+	cfg := s.config.Janitor
+	notFoundBefore := now.AddDate(0, 0, -cfg.NotFoundDays)
+	pausedBefore := now.AddDate(0, 0, -cfg.PausedDays)
+
+	jobs, err := s.jobRepo.FindStaleCandidates(ctx, tenantID, now.Add(-overdueFloor), notFoundBefore, pausedBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.JanitorReport{
+		TenantID:    tenantID,
+		GeneratedAt: now,
+		Jobs:        []models.StaleJob{},
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		stale := models.StaleJob{
+			JobID:     job.ID,
+			Name:      job.Name,
+			Status:    job.Status,
+			Schedule:  job.Schedule,
+			Endpoint:  job.Endpoint,
+			LastRunAt: job.LastRunAt,
+		}
+
+		switch {
+		case job.NotFoundSince != nil && !job.NotFoundSince.After(notFoundBefore):
+			stale.Reason = models.StaleNotFound
+			stale.Since = *job.NotFoundSince
+		case job.Status == models.JobStatusPaused && !job.UpdatedAt.After(pausedBefore):
+			stale.Reason = models.StalePaused
+			stale.Since = job.UpdatedAt
+		case job.Status == models.JobStatusActive:
+			since := lastActivity(job)
+			period, ok := s.schedulePeriod(job, since)
+			if !ok || now.Sub(since) < time.Duration(cfg.OverdueFactor)*period {
+				continue
+			}
+			stale.Reason = models.StaleOverdue
+			stale.Since = since
+			stale.Period = formatPeriod(period)
+		default:
+			continue
+		}
+		report.Jobs = append(report.Jobs, stale)
+	}
+	return report, nil
+}
+
+// lastActivity is when a job last ran, or when it was created or due to start if it hasn't
+func lastActivity(job *models.Job) time.Time {
+	since := job.CreatedAt
+	if job.LastRunAt != nil && job.LastRunAt.After(since) {
+		since = *job.LastRunAt
+	}
+	if job.StartAt != nil && job.StartAt.After(since) {
+		since = *job.StartAt
+	}
+	return since
+}
+
+// formatPeriod formats a duration without trailing zero units, e.g. 24h rather than 24h0m0s
+func formatPeriod(d time.Duration) string {
+	text := d.String()
+	if strings.HasSuffix(text, "m0s") {
+		text = strings.TrimSuffix(text, "0s")
+	}
+	if strings.HasSuffix(text, "h0m") {
+		text = strings.TrimSuffix(text, "0m")
+	}
+	return text
+}
+
+// schedulePeriod returns the longest gap between a recurring job's runs after a time, or false
+// when its schedule has no runs left or can't be parsed
+func (s *Scheduler) schedulePeriod(job *models.Job, after time.Time) (time.Duration, bool) {
+	switch job.Type {
+	case models.JobTypeInterval:
+		interval, err := ParseInterval(job.Schedule)
+		return interval, err == nil
+	case models.JobTypeCron:
+		schedule, err := s.cronParser.Parse(job.Schedule)
+		if err != nil {
+			return 0, false
+		}
+		var longest time.Duration
+		prev := schedule.Next(after.In(jobLocation(job)))
+		for i := 0; i < periodSamples && !prev.IsZero(); i++ {
+			next := schedule.Next(prev)
+			if next.IsZero() {
+				break
+			}
+			if gap := next.Sub(prev); gap > longest {
+				longest = gap
+			}
+			prev = next
+		}
+		return longest, longest > 0
+	}
+	return 0, false
+}
+
+// janitorLoop sends each tenant its janitor report every JANITOR_REPORT_HOURS
+func (s *Scheduler) janitorLoop() {
+	defer s.wg.Done()
+
+	if s.notifier == nil || s.janitorRepo == nil || s.config.Janitor.ReportHours <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.sendJanitorReports(s.ctx)
+		}
+	}
+}
+
+// sendJanitorReports notifies the tenants whose report is due of their stale jobs. Every
+// dispatcher checks; claiming the report decides which one sends it. Reports due during a
+// tenant's quiet hours wait for them to end, and tenants with no stale jobs aren't notified.
+func (s *Scheduler) sendJanitorReports(ctx context.Context) {
+	tenantIDs, err := s.jobRepo.FindTenantIDs(ctx)
+	if err != nil {
+		log.Printf("scheduler: failed to list tenants for janitor reports: %v", err)
+		return
+	}
+
+	every := time.Duration(s.config.Janitor.ReportHours) * time.Hour
+	for _, tenantID := range tenantIDs {
+		if ctx.Err() != nil {
+			return
+		}
+
+		now := time.Now()
+		policy := s.notifier.Policy(ctx, tenantID)
+		if notification.InQuietHours(&policy, now) {
+			continue
+		}
+		claimed, err := s.janitorRepo.ClaimReport(ctx, tenantID, now, now.Add(-every))
+		if err != nil {
+			log.Printf("scheduler: failed to claim the janitor report of tenant %s: %v", tenantID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		report, err := s.JanitorReport(ctx, tenantID)
+		if err != nil {
+			log.Printf("scheduler: failed to build the janitor report of tenant %s: %v", tenantID, err)
+			continue
+		}
+		if len(report.Jobs) == 0 {
+			continue
+		}
+		s.notifier.Notify(ctx, janitorEvent(report))
+	}
+}
+
+// janitorEvent builds the notification of a janitor report
+func janitorEvent(report *models.JanitorReport) notification.Event {
+	var lines []string
+	for i, job := range report.Jobs {
+		if i == janitorNotifyLimit {
+			lines = append(lines, fmt.Sprintf("...and %d more", len(report.Jobs)-i))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("- %s: %s", job.Name, staleDescription(job, report.GeneratedAt)))
+	}
+
+	return notification.Event{
+		Kind:      notification.EventJanitorReport,
+		TenantID:  report.TenantID,
+		GroupKey:  "janitor:" + report.TenantID.String(),
+		Title:     fmt.Sprintf("%d jobs look stale and may be ready to clean up", len(report.Jobs)),
+		Message:   strings.Join(lines, "\n"),
+		StaleJobs: report.Jobs,
+		Timestamp: report.GeneratedAt,
+	}
+}
+
+// staleDescription explains why a job is listed
+func staleDescription(job models.StaleJob, now time.Time) string {
+	days := int(now.Sub(job.Since).Hours() / 24)
+	switch job.Reason {
+	case models.StaleNotFound:
+		return fmt.Sprintf("%s has answered 404 for %d days", job.Endpoint, days)
+	case models.StalePaused:
+		return fmt.Sprintf("paused and unchanged for %d days", days)
+	default:
+		return fmt.Sprintf("hasn't run in %d days, though it runs at least every %s", days, job.Period)
+	}
+}
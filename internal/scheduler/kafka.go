@@ -0,0 +1,230 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/tracing"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// KafkaTarget is the target_config of kafka jobs, which produce to the KAFKA_BROKERS cluster.
+// Fields missing from target_config are read from the job's metadata under the same names.
+type KafkaTarget struct {
+	Topic string `json:"topic"`
+	Key   string `json:"key,omitempty"` // Message key, which picks the partition
+}
+
+// kafkaBrokers is the brokers setting jobs can't use: brokers a job named would get the
+// service's SASL credentials and be dialed outside the egress policy
+type kafkaBrokers struct {
+	Brokers json.RawMessage `json:"brokers"`
+}
+
+// ParseKafkaTarget reads a kafka job's target from its target_config and metadata
+func ParseKafkaTarget(job *models.Job) (*KafkaTarget, error) {
+	var target, fallback KafkaTarget
+	var brokers, fallbackBrokers kafkaBrokers
+	if len(job.TargetConfig) > 0 {
+		if err := json.Unmarshal(job.TargetConfig, &target); err != nil {
+			return nil, fmt.Errorf("invalid kafka target_config: %w", err)
+		}
+		_ = json.Unmarshal(job.TargetConfig, &brokers)
+	}
+	if len(job.Metadata) > 0 {
+		// Metadata is free-form, so fields of the wrong type are ignored
+		_ = json.Unmarshal(job.Metadata, &fallback)
+		_ = json.Unmarshal(job.Metadata, &fallbackBrokers)
+	}
+
+	// Fail rather than produce to a different cluster than the job names
+	if len(brokers.Brokers) > 0 || len(fallbackBrokers.Brokers) > 0 {
+		return nil, fmt.Errorf("kafka jobs can't set brokers: they produce to KAFKA_BROKERS")
+	}
+	if target.Topic == "" {
+		target.Topic = fallback.Topic
+	}
+	if target.Key == "" {
+		target.Key = fallback.Key
+	}
+
+	if target.Topic == "" {
+		return nil, fmt.Errorf("kafka targets need a topic in target_config or metadata")
+	}
+	return &target, nil
+}
+
+// kafkaProducer produces kafka job messages to KAFKA_BROKERS with a single writer, which
+// serves every topic
+type kafkaProducer struct {
+	config config.KafkaConfig
+	w      *kafka.Writer
+	mu     sync.Mutex
+}
+
+// newKafkaProducer creates a producer; the writer is created on first use
+func newKafkaProducer(cfg config.KafkaConfig) *kafkaProducer {
+	return &kafkaProducer{config: cfg}
+}
+
+// writer returns the writer, creating it if needed
+func (p *kafkaProducer) writer() (*kafka.Writer, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.w != nil {
+		return p.w, nil
+	}
+
+	var brokers []string
+	for _, broker := range strings.Split(p.config.Brokers, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			brokers = append(brokers, broker)
+		}
+	}
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("no kafka brokers: set KAFKA_BROKERS")
+	}
+
+	transport := &kafka.Transport{ClientID: p.config.ClientID}
+	if p.config.TLS {
+		transport.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if p.config.SASLUsername != "" {
+		transport.SASL = plain.Mechanism{Username: p.config.SASLUsername, Password: p.config.SASLPassword}
+	}
+
+	w := &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Balancer:     &kafka.Hash{}, // Same key, same partition
+		RequiredAcks: kafka.RequireAll,
+		MaxAttempts:  3, // Further retries follow the job's retry policy
+		BatchTimeout: 10 * time.Millisecond,
+		Transport:    transport,
+	}
+	p.w = w
+	return w, nil
+}
+
+// Close flushes and closes the writer
+func (p *kafkaProducer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.w == nil {
+		return nil
+	}
+	err := p.w.Close()
+	p.w = nil
+	return err
+}
+
+// executeKafka produces a job's payload or body as a message to its topic. The job's headers
+// become message headers.
+func (e *Executor) executeKafka(ctx context.Context, job *models.Job) (*ExecutionResult, error) {
+	startTime := time.Now()
+	result := &ExecutionResult{}
+
+	fail := func(span trace.Span, err error) (*ExecutionResult, error) {
+		result.Error = err.Error()
+		result.Duration = time.Since(startTime).Milliseconds()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, result.Error)
+		return result, err
+	}
+
+	target, err := ParseKafkaTarget(job)
+	if err != nil {
+		return fail(trace.SpanFromContext(ctx), err)
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, target.Topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", target.Topic),
+		),
+	)
+	defer span.End()
+
+	job, err = e.resolveSecrets(ctx, job)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	value, contentType, err := RequestBody(job)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	message := kafka.Message{
+		Topic: target.Topic,
+		Value: value,
+		Time:  time.Now(),
+	}
+	if target.Key != "" {
+		message.Key = []byte(target.Key)
+	}
+
+	headers := map[string]string{
+		"X-Scheduler-Job-ID":    job.ID.String(),
+		"X-Scheduler-Tenant-ID": job.TenantID.String(),
+	}
+	if contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+	if len(job.Headers) > 0 {
+		var custom map[string]string
+		if err := json.Unmarshal(job.Headers, &custom); err == nil {
+			for key, value := range custom {
+				headers[key] = value
+			}
+		}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, kafkaHeaderCarrier(headers))
+	for key, value := range headers {
+		message.Headers = append(message.Headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+
+	w, err := e.kafka.writer()
+	if err != nil {
+		return fail(span, err)
+	}
+	if err := w.WriteMessages(ctx, message); err != nil {
+		return fail(span, fmt.Errorf("failed to produce to %s: %w", target.Topic, err))
+	}
+	CountBytes(ctx, int64(len(value)), 0)
+
+	result.Body, _ = json.Marshal(map[string]interface{}{
+		"topic": target.Topic,
+		"key":   target.Key,
+		"bytes": len(value),
+	})
+	result.Duration = time.Since(startTime).Milliseconds()
+	return result, nil
+}
+
+// kafkaHeaderCarrier adapts message headers for trace context propagation
+type kafkaHeaderCarrier map[string]string
+
+func (c kafkaHeaderCarrier) Get(key string) string { return c[key] }
+func (c kafkaHeaderCarrier) Set(key, value string) { c[key] = value }
+func (c kafkaHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
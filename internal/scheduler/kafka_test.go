@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKafkaTarget(t *testing.T) {
+	tests := []struct {
+		name         string
+		targetConfig string
+		metadata     string
+		want         KafkaTarget
+		wantErr      bool
+	}{
+		{name: "topic and key", targetConfig: `{"topic": "billing", "key": "nightly"}`, want: KafkaTarget{Topic: "billing", Key: "nightly"}},
+		{name: "from metadata", metadata: `{"topic": "billing", "owner": "finance"}`, want: KafkaTarget{Topic: "billing"}},
+		{name: "target_config wins", targetConfig: `{"topic": "billing"}`, metadata: `{"topic": "audit", "key": "k"}`, want: KafkaTarget{Topic: "billing", Key: "k"}},
+		{name: "no topic", targetConfig: `{"key": "nightly"}`, wantErr: true},
+		{name: "brokers in target_config", targetConfig: `{"topic": "billing", "brokers": ["evil.example.com:9092"]}`, wantErr: true},
+		{name: "brokers in metadata", targetConfig: `{"topic": "billing"}`, metadata: `{"brokers": ["evil.example.com:9092"]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &models.Job{TargetType: models.TargetKafka}
+			if tt.targetConfig != "" {
+				job.TargetConfig = json.RawMessage(tt.targetConfig)
+			}
+			if tt.metadata != "" {
+				job.Metadata = json.RawMessage(tt.metadata)
+			}
+
+			target, err := ParseKafkaTarget(job)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, *target)
+		})
+	}
+}
+
+func TestKafkaProducerWriter(t *testing.T) {
+	_, err := newKafkaProducer(config.KafkaConfig{}).writer()
+	assert.Error(t, err)
+
+	p := newKafkaProducer(config.KafkaConfig{Brokers: "kafka-1:9092, kafka-2:9092,", SASLUsername: "svc", SASLPassword: "secret"})
+	w, err := p.writer()
+	require.NoError(t, err)
+	assert.Equal(t, "kafka-1:9092,kafka-2:9092", w.Addr.String())
+
+	again, err := p.writer()
+	require.NoError(t, err)
+	assert.Same(t, w, again)
+
+	require.NoError(t, p.Close())
+	assert.Nil(t, p.w)
+	require.NoError(t, p.Close())
+}
@@ -0,0 +1,200 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// leaderLockKey is the lock held by the instance leading the cluster
+const leaderLockKey = "scheduler:leader"
+
+// LeaderStatus reports which instance holds the leader lock, as recorded by the leader
+type LeaderStatus struct {
+	LockKey             string     `json:"lock_key"`
+	Elected             bool       `json:"elected"` // An instance holds the lock and is heartbeating
+	InstanceID          string     `json:"instance_id,omitempty"`
+	Hostname            string     `json:"hostname,omitempty"`
+	AcquiredAt          *time.Time `json:"acquired_at,omitempty"`
+	HeldSeconds         float64    `json:"held_seconds,omitempty"`
+	HeartbeatAt         *time.Time `json:"heartbeat_at,omitempty"`
+	HeartbeatAgeSeconds float64    `json:"heartbeat_age_seconds,omitempty"`
+	Stale               bool       `json:"stale"` // The recorded leader stopped heartbeating without resigning
+
+	// The instance serving the request
+	Instance  string `json:"instance"`
+	IsLeader  bool   `json:"is_leader"`
+	Elections int64  `json:"elections"` // Times the instance has taken the lead since it started
+}
+
+// leadership tracks whether this instance holds the leader lock. The leader keeps the lock
+// across ticks, refreshing it on every heartbeat, until it resigns or loses it.
+type leadership struct {
+	mu        sync.Mutex
+	held      bool
+	since     time.Time
+	elections int64
+}
+
+// isHeld reports whether this instance believes it holds the leader lock
+func (l *leadership) isHeld() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.held
+}
+
+// take records that this instance took the leader lock
+func (l *leadership) take(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.held = true
+	l.since = now
+	l.elections++
+}
+
+// drop records that this instance no longer holds the leader lock, reporting whether it did
+func (l *leadership) drop() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	held := l.held
+	l.held = false
+	return held
+}
+
+// leaderTTL is how long the leader lock outlives the leader's last heartbeat
+func (s *Scheduler) leaderTTL() time.Duration {
+	return time.Duration(s.config.Scheduler.LeaderTTLSeconds) * time.Second
+}
+
+// lead reports whether this instance leads, taking the leader lock if no instance holds it
+func (s *Scheduler) lead(ctx context.Context) (bool, error) {
+	if s.leadership.isHeld() {
+		held, err := s.locker.IsLockHeld(ctx, leaderLockKey)
+		if err != nil {
+			return false, err
+		}
+		if held {
+			return true, nil
+		}
+		s.leadership.drop()
+		dispatchMetrics.Add("leader_lost", 1)
+		log.Printf("scheduler: lost the leader lock")
+	}
+	// A draining instance doesn't take the lead back after resigning
+	if s.lifecycle.draining() {
+		return false, nil
+	}
+
+	acquired, err := s.locker.AcquireLock(ctx, leaderLockKey, s.leaderTTL())
+	if err != nil || !acquired {
+		return false, err
+	}
+
+	now := time.Now()
+	s.leadership.take(now)
+	dispatchMetrics.Add("leader_elections", 1)
+	log.Printf("scheduler: %s (%s) took the leader lock", s.instanceID, s.hostname)
+
+	// A single node has no other instance to tell who leads
+	if s.singleNode() {
+		return true, nil
+	}
+	err = s.clusterRepo.SaveLeader(ctx, &models.ClusterLeader{
+		LockKey:     leaderLockKey,
+		InstanceID:  s.instanceID,
+		Hostname:    s.hostname,
+		AcquiredAt:  now,
+		HeartbeatAt: now,
+	})
+	if err != nil {
+		log.Printf("scheduler: failed to record leadership: %v", err)
+	}
+	return true, nil
+}
+
+// leaderHeartbeat keeps the leader lock from lapsing and records the heartbeat
+func (s *Scheduler) leaderHeartbeat(ctx context.Context) {
+	if !s.leadership.isHeld() {
+		return
+	}
+	if err := s.locker.RefreshLock(ctx, leaderLockKey, s.leaderTTL()); err != nil {
+		log.Printf("scheduler: failed to refresh the leader lock: %v", err)
+		return
+	}
+	if s.singleNode() {
+		return
+	}
+	// The next tick finds out whether a lapsed lock was taken over meanwhile
+	if _, err := s.clusterRepo.TouchLeader(ctx, leaderLockKey, s.instanceID, time.Now()); err != nil {
+		log.Printf("scheduler: failed to record leader heartbeat: %v", err)
+	}
+}
+
+// resign releases the leader lock so another instance can take the lead right away
+func (s *Scheduler) resign(ctx context.Context) {
+	if !s.leadership.drop() {
+		return
+	}
+	if err := s.locker.ReleaseLock(ctx, leaderLockKey); err != nil {
+		log.Printf("scheduler: failed to release the leader lock: %v", err)
+	}
+	if s.singleNode() {
+		return
+	}
+	if err := s.clusterRepo.DeleteLeader(ctx, leaderLockKey, s.instanceID); err != nil {
+		log.Printf("scheduler: failed to clear leadership record: %v", err)
+	}
+	log.Printf("scheduler: resigned the leader lock")
+}
+
+// Leader reports which instance holds the leader lock. A leader that died without
+// resigning shows as stale once its heartbeat is older than SCHEDULER_LEADER_TTL_SECONDS,
+// when its lock lapses too. A single node keeps no record and reports its own leadership.
+func (s *Scheduler) Leader(ctx context.Context) (LeaderStatus, error) {
+	s.leadership.mu.Lock()
+	status := LeaderStatus{
+		LockKey:   leaderLockKey,
+		Instance:  s.instanceID,
+		IsLeader:  s.leadership.held,
+		Elections: s.leadership.elections,
+	}
+	since := s.leadership.since
+	s.leadership.mu.Unlock()
+
+	if s.singleNode() {
+		if status.IsLeader {
+			now := time.Now()
+			status.Elected = true
+			status.InstanceID = s.instanceID
+			status.Hostname = s.hostname
+			status.AcquiredAt = &since
+			status.HeldSeconds = now.Sub(since).Seconds()
+			status.HeartbeatAt = &now
+		}
+		return status, nil
+	}
+
+	record, err := s.clusterRepo.FindLeader(ctx, leaderLockKey)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return status, nil
+	}
+	if err != nil {
+		return status, err
+	}
+
+	now := time.Now()
+	status.InstanceID = record.InstanceID
+	status.Hostname = record.Hostname
+	status.AcquiredAt = &record.AcquiredAt
+	status.HeldSeconds = now.Sub(record.AcquiredAt).Seconds()
+	status.HeartbeatAt = &record.HeartbeatAt
+	status.HeartbeatAgeSeconds = now.Sub(record.HeartbeatAt).Seconds()
+	status.Stale = now.Sub(record.HeartbeatAt) > s.leaderTTL()
+	status.Elected = !status.Stale
+	return status, nil
+}
@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Lifecycle phases reported by Lifecycle
+const (
+	PhaseStarting = "starting" // Recovering orphaned executions and catching up on missed runs
+	PhaseReady    = "ready"
+	PhaseDraining = "draining" // No longer dispatching; finishing executions before shutdown
+)
+
+// drainPollInterval is how often a drain checks whether local executions have finished
+const drainPollInterval = 250 * time.Millisecond
+
+// LifecycleStatus is a snapshot of where an instance is between startup and shutdown
+type LifecycleStatus struct {
+	Phase         string     `json:"phase"`
+	StartedAt     time.Time  `json:"started_at"`
+	Recovered     bool       `json:"recovered"`    // Orphaned executions have been checked
+	CaughtUp      bool       `json:"caught_up"`    // A tick found no backlog of due jobs
+	GateExpired   bool       `json:"gate_expired"` // Ready because SCHEDULER_STARTUP_GATE_SECONDS passed
+	ReadyAt       *time.Time `json:"ready_at,omitempty"`
+	DrainingSince *time.Time `json:"draining_since,omitempty"`
+	Running       int        `json:"running"`  // Executions running on this instance
+	Queued        int        `json:"queued"`   // Executions waiting for a worker on this instance
+	Retrying      int        `json:"retrying"` // Executions waiting on this instance for their next attempt
+}
+
+// lifecycle tracks the startup gate and drain of an instance. The gate opens once the
+// reaper has checked for executions orphaned by a previous instance and the scheduling
+// loop has dispatched the backlog of runs missed while no instance was dispatching.
+type lifecycle struct {
+	mu        sync.Mutex
+	startedAt time.Time
+	recovered bool
+	caughtUp  bool
+	readyAt   time.Time
+	drainedAt time.Time
+}
+
+// start resets the lifecycle when the scheduler starts
+func (l *lifecycle) start(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.startedAt = now
+	l.recovered, l.caughtUp = false, false
+	l.readyAt, l.drainedAt = time.Time{}, time.Time{}
+}
+
+// markRecovered records that orphaned executions have been checked
+func (l *lifecycle) markRecovered() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.recovered = true
+	l.openIfDone()
+}
+
+// markCaughtUp records a tick that left no backlog of due jobs
+func (l *lifecycle) markCaughtUp() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.caughtUp = true
+	l.openIfDone()
+}
+
+// openIfDone opens the gate once recovery and catch-up are both done. Callers hold l.mu.
+func (l *lifecycle) openIfDone() {
+	if l.readyAt.IsZero() && l.recovered && l.caughtUp {
+		l.readyAt = time.Now()
+		log.Printf("scheduler: recovered and caught up after %s; ready", l.readyAt.Sub(l.startedAt).Round(time.Millisecond))
+	}
+}
+
+// draining reports whether the instance has started draining
+func (l *lifecycle) draining() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return !l.drainedAt.IsZero()
+}
+
+// Lifecycle reports the instance's phase. With SCHEDULER_STARTUP_GATE_SECONDS set to 0 the
+// instance is ready as soon as it starts; otherwise the gate opens after recovery and
+// catch-up, or when that many seconds have passed.
+func (s *Scheduler) Lifecycle() LifecycleStatus {
+	s.lifecycle.mu.Lock()
+	status := LifecycleStatus{
+		Phase:     PhaseStarting,
+		StartedAt: s.lifecycle.startedAt,
+		Recovered: s.lifecycle.recovered,
+		CaughtUp:  s.lifecycle.caughtUp,
+	}
+	gate := time.Duration(s.config.Scheduler.StartupGateSeconds) * time.Second
+	switch {
+	case !s.lifecycle.drainedAt.IsZero():
+		at := s.lifecycle.drainedAt
+		status.Phase = PhaseDraining
+		status.DrainingSince = &at
+	case !s.lifecycle.readyAt.IsZero():
+		at := s.lifecycle.readyAt
+		status.Phase = PhaseReady
+		status.ReadyAt = &at
+	case gate <= 0:
+		status.Phase = PhaseReady
+	case time.Since(s.lifecycle.startedAt) >= gate:
+		status.Phase = PhaseReady
+		status.GateExpired = true
+	}
+	s.lifecycle.mu.Unlock()
+
+	s.activeMu.Lock()
+	status.Running = len(s.active)
+	status.Retrying = len(s.retrying)
+	s.activeMu.Unlock()
+	if s.workerPool != nil {
+		status.Queued = s.workerPool.QueueSize()
+	}
+	return status
+}
+
+// Drain stops this instance from dispatching due jobs, so other instances take them over,
+// and waits until the executions running or queued on it finish, the timeout passes or ctx
+// is done. It is meant for a Kubernetes preStop hook: the pod leaves its Service while
+// draining and receives SIGTERM once Drain returns. Draining again only waits.
+func (s *Scheduler) Drain(ctx context.Context, timeout time.Duration) LifecycleStatus {
+	s.lifecycle.mu.Lock()
+	if s.lifecycle.drainedAt.IsZero() {
+		s.lifecycle.drainedAt = time.Now()
+		log.Printf("scheduler: draining; no longer dispatching due jobs")
+	}
+	s.lifecycle.mu.Unlock()
+
+	// Another instance takes over the leader's duties
+	s.resign(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		status := s.Lifecycle()
+		if status.Running == 0 && status.Queued == 0 {
+			return status
+		}
+		select {
+		case <-ctx.Done():
+			log.Printf("scheduler: drain timed out with %d executions running and %d queued", status.Running, status.Queued)
+			return status
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LocalLocker provides locks within this process, for a single instance running without
+// Redis. Nothing else competes for them, so a lock is only unavailable while this instance
+// already holds it; like a Redis lock it lapses once its TTL passes without a refresh.
+type LocalLocker struct {
+	mu   sync.Mutex
+	held map[string]time.Time // Key to when its lock lapses
+}
+
+// NewLocalLocker creates a new in-process locker
+func NewLocalLocker() *LocalLocker {
+	return &LocalLocker{held: make(map[string]time.Time)}
+}
+
+// AcquireLock takes a lock unless it is held and hasn't lapsed
+func (l *LocalLocker) AcquireLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if until, ok := l.held[key]; ok && now.Before(until) {
+		return false, nil
+	}
+	l.held[key] = now.Add(ttl)
+	return true, nil
+}
+
+// ReleaseLock releases a lock
+func (l *LocalLocker) ReleaseLock(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.held, key)
+	return nil
+}
+
+// RefreshLock keeps a held lock from lapsing for another ttl
+func (l *LocalLocker) RefreshLock(ctx context.Context, key string, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until, ok := l.held[key]; ok && time.Now().Before(until) {
+		l.held[key] = time.Now().Add(ttl)
+	}
+	return nil
+}
+
+// IsLockHeld reports whether a lock is held and hasn't lapsed
+func (l *LocalLocker) IsLockHeld(ctx context.Context, key string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until, ok := l.held[key]
+	return ok && time.Now().Before(until), nil
+}
@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalLocker(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, l *LocalLocker)
+	}{
+		{
+			name: "acquires a free lock once",
+			run: func(t *testing.T, l *LocalLocker) {
+				ok, err := l.AcquireLock(ctx, "job", time.Minute)
+				require.NoError(t, err)
+				assert.True(t, ok)
+
+				ok, err = l.AcquireLock(ctx, "job", time.Minute)
+				require.NoError(t, err)
+				assert.False(t, ok)
+			},
+		},
+		{
+			name: "keys are independent",
+			run: func(t *testing.T, l *LocalLocker) {
+				ok, _ := l.AcquireLock(ctx, "a", time.Minute)
+				assert.True(t, ok)
+				ok, _ = l.AcquireLock(ctx, "b", time.Minute)
+				assert.True(t, ok)
+			},
+		},
+		{
+			name: "release frees the lock",
+			run: func(t *testing.T, l *LocalLocker) {
+				ok, _ := l.AcquireLock(ctx, "job", time.Minute)
+				require.True(t, ok)
+				require.NoError(t, l.ReleaseLock(ctx, "job"))
+
+				held, err := l.IsLockHeld(ctx, "job")
+				require.NoError(t, err)
+				assert.False(t, held)
+
+				ok, _ = l.AcquireLock(ctx, "job", time.Minute)
+				assert.True(t, ok)
+			},
+		},
+		{
+			name: "releasing a free lock is a no-op",
+			run: func(t *testing.T, l *LocalLocker) {
+				assert.NoError(t, l.ReleaseLock(ctx, "job"))
+			},
+		},
+		{
+			name: "a lapsed lock can be taken again",
+			run: func(t *testing.T, l *LocalLocker) {
+				ok, _ := l.AcquireLock(ctx, "job", 10*time.Millisecond)
+				require.True(t, ok)
+				time.Sleep(20 * time.Millisecond)
+
+				held, _ := l.IsLockHeld(ctx, "job")
+				assert.False(t, held)
+				ok, _ = l.AcquireLock(ctx, "job", time.Minute)
+				assert.True(t, ok)
+			},
+		},
+		{
+			name: "refresh keeps a held lock",
+			run: func(t *testing.T, l *LocalLocker) {
+				ok, _ := l.AcquireLock(ctx, "job", 30*time.Millisecond)
+				require.True(t, ok)
+				time.Sleep(15 * time.Millisecond)
+				require.NoError(t, l.RefreshLock(ctx, "job", time.Minute))
+				time.Sleep(30 * time.Millisecond)
+
+				held, _ := l.IsLockHeld(ctx, "job")
+				assert.True(t, held)
+			},
+		},
+		{
+			name: "refresh doesn't take a free lock",
+			run: func(t *testing.T, l *LocalLocker) {
+				require.NoError(t, l.RefreshLock(ctx, "job", time.Minute))
+
+				held, _ := l.IsLockHeld(ctx, "job")
+				assert.False(t, held)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, NewLocalLocker())
+		})
+	}
+}
@@ -8,31 +8,127 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-// DistributedLocker provides distributed locking using Redis
+// Locker provides the locks instances coordinate with, such as the leader lock. A lock is
+// held by one instance at a time and lapses if its holder dies.
+type Locker interface {
+	// AcquireLock takes a lock if no instance holds it, reporting whether it did
+	AcquireLock(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// ReleaseLock releases a lock if this instance holds it
+	ReleaseLock(ctx context.Context, key string) error
+	// RefreshLock keeps a held lock from lapsing for another ttl
+	RefreshLock(ctx context.Context, key string, ttl time.Duration) error
+	// IsLockHeld reports whether this instance holds a lock
+	IsLockHeld(ctx context.Context, key string) (bool, error)
+}
+
+// Locker backends for LOCKER_BACKEND
+const (
+	LockerBackendRedis    = "redis"
+	LockerBackendPostgres = "postgres"
+)
+
+// Lua scripts that only touch a lock still held by this worker
+var (
+	releaseScript = redis.NewScript(`
+		if redis.call("get", KEYS[1]) == ARGV[1] then
+			return redis.call("del", KEYS[1])
+		else
+			return 0
+		end
+	`)
+	refreshScript = redis.NewScript(`
+		if redis.call("get", KEYS[1]) == ARGV[1] then
+			return redis.call("pexpire", KEYS[1], ARGV[2])
+		else
+			return 0
+		end
+	`)
+)
+
+// redlockDriftFactor is the share of a lock's TTL set aside for clock drift between nodes
+const redlockDriftFactor = 0.01
+
+// DistributedLocker provides distributed locking using Redis. With one client, which may
+// be a Sentinel or Cluster client, a lock is a single key. With several independent Redis
+// primaries it follows the Redlock algorithm: a lock is held once a majority of the nodes
+// granted it within its TTL, so losing a minority of the nodes doesn't lose the lock.
 type DistributedLocker struct {
-	client   *redis.Client
+	clients  []redis.UniversalClient
+	quorum   int
 	workerID string
 }
 
 // NewDistributedLocker creates a new distributed locker
-func NewDistributedLocker(client *redis.Client, workerID string) *DistributedLocker {
+func NewDistributedLocker(client redis.UniversalClient, workerID string) *DistributedLocker {
+	return NewRedlockLocker([]redis.UniversalClient{client}, workerID)
+}
+
+// NewRedlockLocker creates a distributed locker over independent Redis primaries
+func NewRedlockLocker(clients []redis.UniversalClient, workerID string) *DistributedLocker {
 	return &DistributedLocker{
-		client:   client,
+		clients:  clients,
+		quorum:   len(clients)/2 + 1,
 		workerID: workerID,
 	}
 }
 
+// each runs fn against every node at once, returning how many nodes succeeded and the
+// errors of the others
+func (l *DistributedLocker) each(fn func(client redis.UniversalClient) (bool, error)) (int, []error) {
+	type outcome struct {
+		ok  bool
+		err error
+	}
+	outcomes := make(chan outcome, len(l.clients))
+	for _, client := range l.clients {
+		go func(client redis.UniversalClient) {
+			ok, err := fn(client)
+			outcomes <- outcome{ok, err}
+		}(client)
+	}
+
+	succeeded := 0
+	var errs []error
+	for range l.clients {
+		o := <-outcomes
+		if o.err != nil {
+			errs = append(errs, o.err)
+		} else if o.ok {
+			succeeded++
+		}
+	}
+	return succeeded, errs
+}
+
+// unreachable reports whether node errors leave too few nodes to reach a quorum
+func (l *DistributedLocker) unreachable(errs []error) bool {
+	return len(errs) > len(l.clients)-l.quorum
+}
+
 // AcquireLock attempts to acquire a lock with the given key
 func (l *DistributedLocker) AcquireLock(ctx context.Context, key string, ttl time.Duration) (bool, error) {
 	lockKey := fmt.Sprintf("lock:%s", key)
 
+	start := time.Now()
 	// Try to set the lock with NX (only if not exists)
-	result, err := l.client.SetNX(ctx, lockKey, l.workerID, ttl).Result()
-	if err != nil {
-		return false, fmt.Errorf("failed to acquire lock: %w", err)
+	granted, errs := l.each(func(client redis.UniversalClient) (bool, error) {
+		return client.SetNX(ctx, lockKey, l.workerID, ttl).Result()
+	})
+
+	// A lock granted by a majority only counts while time remains on it
+	drift := time.Duration(float64(ttl)*redlockDriftFactor) + 2*time.Millisecond
+	if granted >= l.quorum && time.Since(start)+drift < ttl {
+		return true, nil
 	}
 
-	return result, nil
+	// Give back what the minority granted so the next attempt isn't blocked by it
+	if granted > 0 {
+		l.ReleaseLock(ctx, key)
+	}
+	if l.unreachable(errs) {
+		return false, fmt.Errorf("failed to acquire lock: %w", errs[0])
+	}
+	return false, nil
 }
 
 // ReleaseLock releases a lock if held by this worker
@@ -40,17 +136,15 @@ func (l *DistributedLocker) ReleaseLock(ctx context.Context, key string) error {
 	lockKey := fmt.Sprintf("lock:%s", key)
 
 	// Use Lua script to ensure atomic check-and-delete
-	script := redis.NewScript(`
-		if redis.call("get", KEYS[1]) == ARGV[1] then
-			return redis.call("del", KEYS[1])
-		else
-			return 0
-		end
-	`)
-
-	_, err := script.Run(ctx, l.client, []string{lockKey}, l.workerID).Result()
-	if err != nil && err != redis.Nil {
-		return fmt.Errorf("failed to release lock: %w", err)
+	_, errs := l.each(func(client redis.UniversalClient) (bool, error) {
+		_, err := releaseScript.Run(ctx, client, []string{lockKey}, l.workerID).Result()
+		if err != nil && err != redis.Nil {
+			return false, err
+		}
+		return true, nil
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to release lock: %w", errs[0])
 	}
 
 	return nil
@@ -61,35 +155,42 @@ func (l *DistributedLocker) RefreshLock(ctx context.Context, key string, ttl tim
 	lockKey := fmt.Sprintf("lock:%s", key)
 
 	// Use Lua script to ensure atomic check-and-extend
-	script := redis.NewScript(`
-		if redis.call("get", KEYS[1]) == ARGV[1] then
-			return redis.call("pexpire", KEYS[1], ARGV[2])
-		else
-			return 0
-		end
-	`)
-
-	_, err := script.Run(ctx, l.client, []string{lockKey}, l.workerID, ttl.Milliseconds()).Result()
-	if err != nil && err != redis.Nil {
-		return fmt.Errorf("failed to refresh lock: %w", err)
+	_, errs := l.each(func(client redis.UniversalClient) (bool, error) {
+		_, err := refreshScript.Run(ctx, client, []string{lockKey}, l.workerID, ttl.Milliseconds()).Result()
+		if err != nil && err != redis.Nil {
+			return false, err
+		}
+		return true, nil
+	})
+	if l.unreachable(errs) {
+		return fmt.Errorf("failed to refresh lock: %w", errs[0])
 	}
 
 	return nil
 }
 
-// IsLockHeld checks if a lock is currently held by this worker
+// IsLockHeld checks if a lock is currently held by this worker on a majority of the nodes
 func (l *DistributedLocker) IsLockHeld(ctx context.Context, key string) (bool, error) {
 	lockKey := fmt.Sprintf("lock:%s", key)
 
-	value, err := l.client.Get(ctx, lockKey).Result()
-	if err == redis.Nil {
-		return false, nil
+	held, errs := l.each(func(client redis.UniversalClient) (bool, error) {
+		value, err := client.Get(ctx, lockKey).Result()
+		if err == redis.Nil {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return value == l.workerID, nil
+	})
+	if held >= l.quorum {
+		return true, nil
 	}
-	if err != nil {
-		return false, fmt.Errorf("failed to check lock: %w", err)
+	if l.unreachable(errs) {
+		return false, fmt.Errorf("failed to check lock: %w", errs[0])
 	}
 
-	return value == l.workerID, nil
+	return false, nil
 }
 
 // WaitForLock waits until a lock can be acquired or context is cancelled
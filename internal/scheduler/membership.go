@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// Worker registry timing
+const (
+	memberMissedHeartbeats = 3              // Heartbeats a member can miss before it counts as dead
+	memberRetention        = 24 * time.Hour // How long dead members stay listed before cleanup removes them
+)
+
+// memberLoop keeps this instance's entry in the worker registry current
+func (s *Scheduler) memberLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(s.config.Scheduler.HeartbeatSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.registerMember(s.ctx)
+		}
+	}
+}
+
+// registerMember records this instance's role, capacity and current load in the registry
+func (s *Scheduler) registerMember(ctx context.Context) {
+	if err := s.clusterRepo.SaveMember(ctx, s.member()); err != nil {
+		log.Printf("scheduler: failed to register in the worker registry: %v", err)
+	}
+}
+
+// member describes this instance's role, capacity and current load
+func (s *Scheduler) member() *models.ClusterMember {
+	status := s.Lifecycle()
+	member := &models.ClusterMember{
+		InstanceID:  s.instanceID,
+		Hostname:    s.hostname,
+		Role:        s.Role(),
+		WorkerGroup: s.WorkerGroup(),
+		Phase:       status.Phase,
+		Running:     status.Running,
+		Queued:      status.Queued,
+		StartedAt:   status.StartedAt,
+		HeartbeatAt: time.Now(),
+	}
+	if s.runsWorkers() {
+		member.Capacity = int(s.tuning.workerCount.Load())
+	}
+	return member
+}
+
+// leaveCluster removes this instance from the registry when it shuts down
+func (s *Scheduler) leaveCluster(ctx context.Context) {
+	if err := s.clusterRepo.DeleteMember(ctx, s.instanceID); err != nil {
+		log.Printf("scheduler: failed to leave the worker registry: %v", err)
+	}
+}
+
+// Members lists the instances in the worker registry. Members that missed several
+// heartbeats without leaving are reported dead until cleanup removes them a day later. A
+// single node keeps no registry and lists only itself.
+func (s *Scheduler) Members(ctx context.Context) ([]models.ClusterMember, error) {
+	if s.singleNode() {
+		member := s.member()
+		member.Status = models.MemberStatusAlive
+		member.Leader = s.leadership.isHeld()
+		return []models.ClusterMember{*member}, nil
+	}
+
+	members, err := s.clusterRepo.FindMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	leader, err := s.Leader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	deadAfter := memberMissedHeartbeats * time.Duration(s.config.Scheduler.HeartbeatSeconds) * time.Second
+	for i := range members {
+		member := &members[i]
+		age := now.Sub(member.HeartbeatAt)
+		member.HeartbeatAgeSeconds = age.Seconds()
+		member.Status = models.MemberStatusAlive
+		if age > deadAfter {
+			member.Status = models.MemberStatusDead
+		}
+		member.Leader = leader.Elected && leader.InstanceID == member.InstanceID
+	}
+	return members, nil
+}
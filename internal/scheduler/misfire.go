@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// dueRuns returns the scheduled times to run for a due job, applying its misfire policy.
+// A run is misfired when it is more than the misfire threshold late, e.g. after downtime.
+func (s *Scheduler) dueRuns(job *models.Job, now time.Time) []time.Time {
+	if job.NextRunAt == nil {
+		return []time.Time{now}
+	}
+
+	threshold := time.Duration(s.config.Scheduler.MisfireThresholdSeconds) * time.Second
+	if now.Sub(*job.NextRunAt) <= threshold {
+		return []time.Time{now}
+	}
+
+	switch job.MisfirePolicy {
+	case models.MisfireSkip:
+		log.Printf("scheduler: skipping misfired run of job %s due at %s", job.ID, job.NextRunAt.Format(time.RFC3339))
+		return nil
+
+	case models.MisfireFireAll:
+		// Overlapping catch-up runs would only be skipped or cancelled under forbid or replace
+		if job.ConcurrencyPolicy == models.ConcurrencyForbid || job.ConcurrencyPolicy == models.ConcurrencyReplace {
+			return []time.Time{now}
+		}
+		return s.missedRuns(job, now)
+
+	default:
+		return []time.Time{now}
+	}
+}
+
+// missedRuns lists occurrences from the job's next run up to now, oldest first,
+// capped at the configured maximum number of catch-up runs
+func (s *Scheduler) missedRuns(job *models.Job, now time.Time) []time.Time {
+	limit := s.config.Scheduler.MaxCatchUpRuns
+	if limit < 1 {
+		limit = 1
+	}
+
+	first := *job.NextRunAt
+	runs := []time.Time{first}
+
+	switch job.Type {
+	case models.JobTypeCron:
+		next, err := s.cronNext(s.ctx, job)
+		if err != nil {
+			return runs
+		}
+		for t := next(first); !t.After(now) && len(runs) < limit; t = next(t) {
+			runs = append(runs, t)
+		}
+
+	case models.JobTypeInterval:
+		step, err := ParseInterval(job.Schedule)
+		if err != nil {
+			return runs
+		}
+		for next := first.Add(step); !next.After(now) && len(runs) < limit; next = next.Add(step) {
+			runs = append(runs, next)
+		}
+	}
+
+	return runs
+}
@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// misfireScheduler returns a scheduler with just what misfire handling needs
+func misfireScheduler(threshold, maxCatchUp int) *Scheduler {
+	return &Scheduler{
+		config: &config.Config{Scheduler: config.SchedulerConfig{
+			MisfireThresholdSeconds: threshold,
+			MaxCatchUpRuns:          maxCatchUp,
+		}},
+		cronParser: cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor),
+		ctx:        context.Background(),
+	}
+}
+
+func TestDueRuns(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 30, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(-d)
+		return &t
+	}
+
+	tests := []struct {
+		name        string
+		job         models.Job
+		maxCatchUp  int
+		want        []time.Time
+		wantSkipped bool
+	}{
+		{
+			name: "first run",
+			job:  models.Job{Type: models.JobTypeInterval, Schedule: "5m"},
+			want: []time.Time{now},
+		},
+		{
+			name: "on time",
+			job:  models.Job{Type: models.JobTypeInterval, Schedule: "5m", NextRunAt: at(30 * time.Second), MisfirePolicy: models.MisfireSkip},
+			want: []time.Time{now},
+		},
+		{
+			name: "fire_once runs once however late",
+			job:  models.Job{Type: models.JobTypeInterval, Schedule: "5m", NextRunAt: at(time.Hour), MisfirePolicy: models.MisfireFireOnce},
+			want: []time.Time{now},
+		},
+		{
+			name:        "skip drops the misfired run",
+			job:         models.Job{Type: models.JobTypeInterval, Schedule: "5m", NextRunAt: at(time.Hour), MisfirePolicy: models.MisfireSkip},
+			wantSkipped: true,
+		},
+		{
+			name: "fire_all replays interval runs",
+			job:  models.Job{Type: models.JobTypeInterval, Schedule: "5m", NextRunAt: at(12 * time.Minute), MisfirePolicy: models.MisfireFireAll},
+			want: []time.Time{*at(12 * time.Minute), *at(7 * time.Minute), *at(2 * time.Minute)},
+		},
+		{
+			name: "fire_all replays cron runs",
+			job:  models.Job{Type: models.JobTypeCron, Schedule: "0 */10 * * * *", NextRunAt: at(30*time.Minute + 30*time.Second), MisfirePolicy: models.MisfireFireAll},
+			want: []time.Time{
+				time.Date(2025, 3, 10, 11, 30, 0, 0, time.UTC),
+				time.Date(2025, 3, 10, 11, 40, 0, 0, time.UTC),
+				time.Date(2025, 3, 10, 11, 50, 0, 0, time.UTC),
+				time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:       "fire_all is capped",
+			job:        models.Job{Type: models.JobTypeInterval, Schedule: "1m", NextRunAt: at(time.Hour), MisfirePolicy: models.MisfireFireAll},
+			maxCatchUp: 3,
+			want:       []time.Time{*at(time.Hour), *at(59 * time.Minute), *at(58 * time.Minute)},
+		},
+		{
+			name: "fire_all runs once under forbid",
+			job: models.Job{Type: models.JobTypeInterval, Schedule: "5m", NextRunAt: at(time.Hour), MisfirePolicy: models.MisfireFireAll,
+				ConcurrencyPolicy: models.ConcurrencyForbid},
+			want: []time.Time{now},
+		},
+		{
+			name: "fire_all runs once under replace",
+			job: models.Job{Type: models.JobTypeInterval, Schedule: "5m", NextRunAt: at(time.Hour), MisfirePolicy: models.MisfireFireAll,
+				ConcurrencyPolicy: models.ConcurrencyReplace},
+			want: []time.Time{now},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxCatchUp := tt.maxCatchUp
+			if maxCatchUp == 0 {
+				maxCatchUp = 100
+			}
+			runs := misfireScheduler(60, maxCatchUp).dueRuns(&tt.job, now)
+			if tt.wantSkipped {
+				assert.Empty(t, runs)
+				return
+			}
+			assert.Equal(t, tt.want, runs)
+		})
+	}
+}
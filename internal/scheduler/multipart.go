@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// multipartBody opens the file parts of a job's multipart payload and returns a body that
+// streams the fields and files as they are sent, with its Content-Type. Files are checked
+// before the request starts so a missing object fails the run without calling the target.
+func (e *Executor) multipartBody(ctx context.Context, job *models.Job) (io.ReadCloser, string, error) {
+	parts, err := ParseMultipart(job.Payload)
+	if err != nil {
+		return nil, "", err
+	}
+
+	objects := make(map[int]*minio.Object)
+	closeObjects := func() {
+		for _, object := range objects {
+			object.Close()
+		}
+	}
+	for i, part := range parts {
+		if part.File == nil {
+			continue
+		}
+		if err := e.checkStorage(ctx, job.TenantID, part.File.bucket, part.File.key); err != nil {
+			closeObjects()
+			return nil, "", err
+		}
+		client, err := e.objects.connect()
+		if err != nil {
+			closeObjects()
+			return nil, "", err
+		}
+		object, err := client.GetObject(ctx, part.File.bucket, part.File.key, minio.GetObjectOptions{})
+		if err != nil {
+			closeObjects()
+			return nil, "", fmt.Errorf("failed to open %s: %w", part.File.URL, err)
+		}
+		objects[i] = object
+		info, err := object.Stat()
+		if err != nil {
+			closeObjects()
+			return nil, "", fmt.Errorf("failed to open %s: %w", part.File.URL, err)
+		}
+		if part.File.ContentType == "" {
+			part.File.ContentType = info.ContentType
+		}
+	}
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+
+	go func() {
+		defer closeObjects()
+		pw.CloseWithError(writeMultipart(writer, parts, objects))
+	}()
+
+	return pr, writer.FormDataContentType(), nil
+}
+
+// writeMultipart writes the parts of a multipart body, copying file parts from their objects
+func writeMultipart(writer *multipart.Writer, parts []MultipartPart, objects map[int]*minio.Object) error {
+	for i, part := range parts {
+		if part.File == nil {
+			if err := writer.WriteField(part.Name, part.Value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		contentType := part.File.ContentType
+		if contentType == "" {
+			contentType = contentTypeBinary
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(part.Name), escapeQuotes(part.File.Filename)))
+		header.Set("Content-Type", contentType)
+
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, objects[i]); err != nil {
+			return fmt.Errorf("failed to stream %s: %w", part.File.URL, err)
+		}
+	}
+	return writer.Close()
+}
+
+// quoteEscaper escapes the characters mime/multipart escapes in Content-Disposition values
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// escapeQuotes escapes a Content-Disposition parameter value
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
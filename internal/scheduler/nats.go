@@ -0,0 +1,220 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/tracing"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NATSTarget is the target_config of nats jobs. Fields missing from target_config are
+// read from the job's metadata under the same names.
+type NATSTarget struct {
+	Subject   string `json:"subject"`
+	JetStream bool   `json:"jetstream,omitempty"` // Wait for a JetStream ack before counting the run as a success
+	Stream    string `json:"stream,omitempty"`    // Expected stream; the publish fails if the subject is bound to another
+}
+
+// ParseNATSTarget reads a nats job's target from its target_config and metadata
+func ParseNATSTarget(job *models.Job) (*NATSTarget, error) {
+	var target, fallback NATSTarget
+	if len(job.TargetConfig) > 0 {
+		if err := json.Unmarshal(job.TargetConfig, &target); err != nil {
+			return nil, fmt.Errorf("invalid nats target_config: %w", err)
+		}
+	}
+	if len(job.Metadata) > 0 {
+		// Metadata is free-form, so fields of the wrong type are ignored
+		_ = json.Unmarshal(job.Metadata, &fallback)
+	}
+
+	if target.Subject == "" {
+		target.Subject = fallback.Subject
+	}
+	if !target.JetStream {
+		target.JetStream = fallback.JetStream
+	}
+	if target.Stream == "" {
+		target.Stream = fallback.Stream
+	}
+
+	if target.Subject == "" {
+		return nil, fmt.Errorf("nats targets need a subject in target_config or metadata")
+	}
+	if target.Stream != "" && !target.JetStream {
+		return nil, fmt.Errorf("nats stream is only used with jetstream")
+	}
+	return &target, nil
+}
+
+// natsPublisher publishes nats job messages over one shared connection
+type natsPublisher struct {
+	config config.NATSConfig
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	mu     sync.Mutex
+}
+
+// newNATSPublisher creates a publisher; it connects on first use
+func newNATSPublisher(cfg config.NATSConfig) *natsPublisher {
+	return &natsPublisher{config: cfg}
+}
+
+// connect returns the shared connection and JetStream context, connecting if needed. The
+// client reconnects on its own after the connection is established.
+func (p *natsPublisher) connect() (*nats.Conn, jetstream.JetStream, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn != nil && !p.conn.IsClosed() {
+		return p.conn, p.js, nil
+	}
+	if p.config.URL == "" {
+		return nil, nil, fmt.Errorf("no nats servers: set NATS_URL")
+	}
+
+	opts := []nats.Option{
+		nats.Name(p.config.ClientName),
+		nats.MaxReconnects(-1),
+	}
+	if p.config.Username != "" {
+		opts = append(opts, nats.UserInfo(p.config.Username, p.config.Password))
+	}
+	if p.config.Token != "" {
+		opts = append(opts, nats.Token(p.config.Token))
+	}
+	if p.config.CredsFile != "" {
+		opts = append(opts, nats.UserCredentials(p.config.CredsFile))
+	}
+
+	conn, err := nats.Connect(p.config.URL, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+	p.conn, p.js = conn, js
+	return conn, js, nil
+}
+
+// Close drains pending messages and closes the connection
+func (p *natsPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Drain()
+	p.conn, p.js = nil, nil
+	return err
+}
+
+// executeNATS publishes a job's payload or body to its subject. The job's headers become
+// message headers. Core NATS publishes succeed once the server has received the message;
+// JetStream publishes once the stream has stored it.
+func (e *Executor) executeNATS(ctx context.Context, job *models.Job) (*ExecutionResult, error) {
+	startTime := time.Now()
+	result := &ExecutionResult{}
+
+	fail := func(span trace.Span, err error) (*ExecutionResult, error) {
+		result.Error = err.Error()
+		result.Duration = time.Since(startTime).Milliseconds()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, result.Error)
+		return result, err
+	}
+
+	target, err := ParseNATSTarget(job)
+	if err != nil {
+		return fail(trace.SpanFromContext(ctx), err)
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, target.Subject+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "nats"),
+			attribute.String("messaging.destination.name", target.Subject),
+		),
+	)
+	defer span.End()
+
+	job, err = e.resolveSecrets(ctx, job)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	data, contentType, err := RequestBody(job)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	msg := nats.NewMsg(target.Subject)
+	msg.Data = data
+	msg.Header.Set("X-Scheduler-Job-ID", job.ID.String())
+	msg.Header.Set("X-Scheduler-Tenant-ID", job.TenantID.String())
+	if contentType != "" {
+		msg.Header.Set("Content-Type", contentType)
+	}
+	if len(job.Headers) > 0 {
+		var custom map[string]string
+		if err := json.Unmarshal(job.Headers, &custom); err == nil {
+			for key, value := range custom {
+				msg.Header.Set(key, value)
+			}
+		}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(http.Header(msg.Header)))
+
+	conn, js, err := e.nats.connect()
+	if err != nil {
+		return fail(span, err)
+	}
+
+	body := map[string]interface{}{
+		"subject": target.Subject,
+		"bytes":   len(data),
+	}
+	if target.JetStream {
+		var opts []jetstream.PublishOpt
+		if target.Stream != "" {
+			opts = append(opts, jetstream.WithExpectStream(target.Stream))
+		}
+		ack, err := js.PublishMsg(ctx, msg, opts...)
+		if err != nil {
+			return fail(span, fmt.Errorf("failed to publish to %s: %w", target.Subject, err))
+		}
+		body["stream"] = ack.Stream
+		body["sequence"] = ack.Sequence
+		body["duplicate"] = ack.Duplicate
+	} else {
+		if err := conn.PublishMsg(msg); err != nil {
+			return fail(span, fmt.Errorf("failed to publish to %s: %w", target.Subject, err))
+		}
+		// Core NATS has no acks; a flush confirms the server received the message
+		if err := conn.FlushWithContext(ctx); err != nil {
+			return fail(span, fmt.Errorf("failed to flush publish to %s: %w", target.Subject, err))
+		}
+	}
+
+	CountBytes(ctx, int64(len(data)), 0)
+	result.Body, _ = json.Marshal(body)
+	result.Duration = time.Since(startTime).Milliseconds()
+	return result, nil
+}
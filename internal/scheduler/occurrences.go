@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// Occurrences lists a job's fire times from its next run up to until, oldest first, stopping
+// after limit. The next run is the job's stored next_run_at, or the one a save would compute.
+// truncated reports whether occurrences were left out because of the limit.
+func (s *Scheduler) Occurrences(ctx context.Context, job *models.Job, until time.Time, limit int) (runs []time.Time, truncated bool, err error) {
+	first := job.NextRunAt
+	if first == nil {
+		first, err = s.CalculateNextRun(ctx, job)
+		if err != nil || first == nil {
+			return nil, false, err
+		}
+	}
+
+	next := func(t time.Time) time.Time { return time.Time{} }
+	switch job.Type {
+	case models.JobTypeCron:
+		next, err = s.cronNext(ctx, job)
+		if err != nil {
+			return nil, false, err
+		}
+	case models.JobTypeInterval:
+		step, err := ParseInterval(job.Schedule)
+		if err != nil {
+			return nil, false, err
+		}
+		next = func(t time.Time) time.Time { return t.Add(step) }
+	}
+
+	// A zero time ends one-time jobs after their only run
+	for t := *first; !t.IsZero() && !t.After(until); t = next(t) {
+		if len(runs) == limit {
+			return runs, true, nil
+		}
+		runs = append(runs, t)
+	}
+	return runs, false, nil
+}
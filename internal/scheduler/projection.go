@@ -0,0 +1,180 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// projectionSegment is a single step of a projection path
+type projectionSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// projectionPath is a parsed JSONPath-style expression such as $.data.items[*].id
+type projectionPath struct {
+	expr     string
+	segments []projectionSegment
+}
+
+// ValidateProjection checks that a response projection expression is well-formed
+func ValidateProjection(expr string) error {
+	_, err := parseProjection(expr)
+	return err
+}
+
+// ApplyProjection reduces a JSON response body to the fields selected by expr.
+// The result is a JSON object keyed by each path expression. Bodies that are
+// not valid JSON, and empty expressions, are returned unchanged.
+func ApplyProjection(body []byte, expr string) ([]byte, error) {
+	if strings.TrimSpace(expr) == "" || len(body) == 0 {
+		return body, nil
+	}
+
+	paths, err := parseProjection(expr)
+	if err != nil {
+		return body, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return body, nil
+	}
+
+	projected := make(map[string]interface{}, len(paths))
+	for _, path := range paths {
+		projected[path.expr] = path.extract(document)
+	}
+
+	return json.Marshal(projected)
+}
+
+// parseProjection parses a comma-separated list of paths
+func parseProjection(expr string) ([]projectionPath, error) {
+	var paths []projectionPath
+
+	for _, raw := range strings.Split(expr, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		segments, err := parseProjectionPath(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid projection path %q: %w", raw, err)
+		}
+
+		paths = append(paths, projectionPath{expr: raw, segments: segments})
+	}
+
+	if len(paths) == 0 && strings.TrimSpace(expr) != "" {
+		return nil, fmt.Errorf("projection contains no paths")
+	}
+
+	return paths, nil
+}
+
+// parseProjectionPath parses a single path into segments
+func parseProjectionPath(path string) ([]projectionSegment, error) {
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	var segments []projectionSegment
+	for _, part := range strings.Split(path, ".") {
+		if part == "" {
+			return nil, fmt.Errorf("empty segment")
+		}
+
+		key := part
+		var indexes []string
+		if open := strings.Index(part, "["); open >= 0 {
+			key = part[:open]
+			rest := part[open:]
+			for rest != "" {
+				if rest[0] != '[' {
+					return nil, fmt.Errorf("unexpected %q", rest)
+				}
+				end := strings.Index(rest, "]")
+				if end < 0 {
+					return nil, fmt.Errorf("unterminated index")
+				}
+				indexes = append(indexes, rest[1:end])
+				rest = rest[end+1:]
+			}
+		}
+
+		if key != "" {
+			segments = append(segments, projectionSegment{key: key})
+		}
+
+		for _, idx := range indexes {
+			if idx == "*" {
+				segments = append(segments, projectionSegment{wildcard: true})
+				continue
+			}
+			n, err := strconv.Atoi(idx)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid index %q", idx)
+			}
+			segments = append(segments, projectionSegment{index: n, isIndex: true})
+		}
+	}
+
+	return segments, nil
+}
+
+// extract walks the document and returns the selected value, or nil if absent
+func (p projectionPath) extract(document interface{}) interface{} {
+	return extractSegments(document, p.segments)
+}
+
+func extractSegments(value interface{}, segments []projectionSegment) interface{} {
+	if len(segments) == 0 {
+		return value
+	}
+
+	segment := segments[0]
+	rest := segments[1:]
+
+	switch {
+	case segment.wildcard:
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil
+		}
+		values := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			values = append(values, extractSegments(item, rest))
+		}
+		return values
+
+	case segment.isIndex:
+		items, ok := value.([]interface{})
+		if !ok || segment.index >= len(items) {
+			return nil
+		}
+		return extractSegments(items[segment.index], rest)
+
+	default:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		child, ok := object[segment.key]
+		if !ok {
+			return nil
+		}
+		return extractSegments(child, rest)
+	}
+}
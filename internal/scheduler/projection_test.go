@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateProjection(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{
+		{name: "empty", expr: ""},
+		{name: "key", expr: "$.status"},
+		{name: "without root", expr: "data.id"},
+		{name: "nested with index", expr: "$.data.items[0].id"},
+		{name: "wildcard", expr: "$.items[*].id"},
+		{name: "several paths", expr: "$.status, $.data.id"},
+		{name: "only commas", expr: " , ", wantErr: true},
+		{name: "only root", expr: "$", wantErr: true},
+		{name: "empty segment", expr: "$.data..id", wantErr: true},
+		{name: "unterminated index", expr: "$.items[0", wantErr: true},
+		{name: "negative index", expr: "$.items[-1]", wantErr: true},
+		{name: "non-numeric index", expr: "$.items[a]", wantErr: true},
+		{name: "text after index", expr: "$.items[0]x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProjection(tt.expr)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestApplyProjection(t *testing.T) {
+	body := `{"status":"ok","data":{"id":7,"items":[{"id":"a"},{"id":"b"}],"big":12345678901234567890}}`
+
+	tests := []struct {
+		name    string
+		body    string
+		expr    string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty expression keeps the body", body: body, expr: " ", want: body},
+		{name: "empty body", body: "", expr: "$.status", want: ""},
+		{name: "invalid JSON is kept", body: "not json", expr: "$.status", want: "not json"},
+		{name: "key", body: body, expr: "$.status", want: `{"$.status":"ok"}`},
+		{name: "nested key", body: body, expr: "$.data.id", want: `{"$.data.id":7}`},
+		{name: "index", body: body, expr: "$.data.items[1].id", want: `{"$.data.items[1].id":"b"}`},
+		{name: "wildcard", body: body, expr: "$.data.items[*].id", want: `{"$.data.items[*].id":["a","b"]}`},
+		{name: "index out of range", body: body, expr: "$.data.items[5]", want: `{"$.data.items[5]":null}`},
+		{name: "missing key", body: body, expr: "$.missing", want: `{"$.missing":null}`},
+		{name: "index of an object", body: body, expr: "$.data[0]", want: `{"$.data[0]":null}`},
+		{name: "large numbers keep their precision", body: body, expr: "$.data.big", want: `{"$.data.big":12345678901234567890}`},
+		{name: "several paths", body: body, expr: "$.status,$.data.id", want: `{"$.data.id":7,"$.status":"ok"}`},
+		{name: "invalid expression", body: body, expr: "$.items[", want: body, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyProjection([]byte(tt.body), tt.expr)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// throughputWindow is how far back a worker group's throughput is measured for start estimates
+const throughputWindow = 5 * time.Minute
+
+// EstimateStart fills in a pending or queued execution's position in the dispatch queue and
+// when it is expected to start. The position counts the due pending executions of its worker
+// group that workers pick up first and, for a queued execution, the tenant's queued executions
+// released before it. The estimate assumes the group keeps starting executions at the rate of
+// the last few minutes, and that queued executions are released as the tenant's rate limit
+// windows allow. It is left out when the group started nothing recently but has executions
+// ahead.
+func (s *Scheduler) EstimateStart(ctx context.Context, execution *models.JobExecution) {
+	if execution.Status != models.ExecutionStatusPending && execution.Status != models.ExecutionStatusQueued {
+		return
+	}
+
+	now := time.Now()
+	start := now
+	var queued, pending int64
+	var err error
+	if execution.Status == models.ExecutionStatusQueued {
+		queued, err = s.executionRepo.CountQueuedAhead(ctx, execution.TenantID, execution.ScheduledAt, execution.ID)
+		if err != nil {
+			log.Printf("scheduler: failed to count executions queued ahead of %s: %v", execution.ID, err)
+			return
+		}
+		start = s.releaseEstimate(ctx, execution.TenantID, queued, now)
+		// Once released it joins the end of the pending queue
+		pending, err = s.executionRepo.CountPendingAhead(ctx, execution.WorkerGroup, now, uuid.Max)
+	} else {
+		pending, err = s.executionRepo.CountPendingAhead(ctx, execution.WorkerGroup, execution.ScheduledAt, execution.ID)
+	}
+	if err != nil {
+		log.Printf("scheduler: failed to count executions pending ahead of %s: %v", execution.ID, err)
+		return
+	}
+
+	position := queued + pending
+	execution.QueuePosition = &position
+
+	if execution.ScheduledAt.After(start) {
+		start = execution.ScheduledAt
+	}
+	if pending > 0 {
+		started, err := s.executionRepo.CountStartedSince(ctx, execution.WorkerGroup, now.Add(-throughputWindow))
+		if err != nil {
+			log.Printf("scheduler: failed to measure throughput of worker group %q: %v", execution.WorkerGroup, err)
+			return
+		}
+		if started == 0 {
+			return
+		}
+		wait := time.Duration(float64(pending) / float64(started) * float64(throughputWindow))
+		if drained := now.Add(wait); drained.After(start) {
+			start = drained
+		}
+	}
+	start = start.Truncate(time.Second)
+	execution.EstimatedStart = &start
+}
+
+// releaseEstimate returns when a tenant's execution with ahead queued executions before it
+// will be released, given how much of the current rate limit window is used. Without Redis
+// it assumes the next release.
+func (s *Scheduler) releaseEstimate(ctx context.Context, tenantID uuid.UUID, ahead int64, now time.Time) time.Time {
+	if s.rateLimiter == nil || s.tenants == nil {
+		return now
+	}
+	limit := int64(s.tenants.Limits(ctx, tenantID).ExecutionsPerMinute)
+	if limit <= 0 {
+		return now
+	}
+	used, resetsAt, err := s.rateLimiter.Used(ctx, tenantID)
+	if err != nil {
+		return now
+	}
+
+	free := limit - used
+	if free < 0 {
+		free = 0
+	}
+	if ahead < free {
+		return now
+	}
+	windows := (ahead - free) / limit
+	return resetsAt.Add(time.Duration(windows) * rateWindow)
+}
@@ -0,0 +1,193 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/localstore"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// rateWindow is the length of a rate limit window
+const rateWindow = time.Minute
+
+// reserveScript takes one slot in a window if it is below the limit
+var reserveScript = redis.NewScript(`
+	local used = redis.call("incr", KEYS[1])
+	if used == 1 then
+		redis.call("expire", KEYS[1], ARGV[2])
+	end
+	if used > tonumber(ARGV[1]) then
+		redis.call("decr", KEYS[1])
+		return 0
+	end
+	return 1
+`)
+
+// TenantLimits looks up the limits in effect for a tenant
+type TenantLimits interface {
+	Limits(ctx context.Context, tenantID uuid.UUID) models.TenantLimits
+}
+
+// RateLimiter counts executions started per tenant, and manual triggers per job, in fixed
+// one-minute windows shared across instances. Without Redis, on a single node, the windows
+// are counted in process memory.
+type RateLimiter struct {
+	client redis.UniversalClient
+	local  *localstore.Store
+}
+
+// NewRateLimiter creates a new rate limiter; a nil client counts in process memory
+func NewRateLimiter(client redis.UniversalClient) *RateLimiter {
+	if client == nil {
+		return &RateLimiter{local: localstore.New()}
+	}
+	return &RateLimiter{client: client}
+}
+
+// Reserve takes a slot in the tenant's current window, reporting false if the window is full.
+// A limit of 0 or less is unlimited.
+func (r *RateLimiter) Reserve(ctx context.Context, tenantID uuid.UUID, limit int) (bool, error) {
+	if limit <= 0 {
+		return true, nil
+	}
+
+	key, _ := r.window("executions", tenantID, time.Now())
+	return r.reserve(ctx, key, limit)
+}
+
+// ReserveTrigger takes a slot in the job's current manual trigger window, reporting false and
+// when the window resets if it is full. A limit of 0 or less is unlimited.
+func (r *RateLimiter) ReserveTrigger(ctx context.Context, jobID uuid.UUID, limit int) (bool, time.Time, error) {
+	key, resetsAt := r.window("triggers", jobID, time.Now())
+	if limit <= 0 {
+		return true, resetsAt, nil
+	}
+	ok, err := r.reserve(ctx, key, limit)
+	return ok, resetsAt, err
+}
+
+// reserve takes a slot in a window's counter if it is below the limit
+func (r *RateLimiter) reserve(ctx context.Context, key string, limit int) (bool, error) {
+	if r.local != nil {
+		return r.local.Reserve(key, int64(limit), 2*rateWindow), nil
+	}
+	ok, err := reserveScript.Run(ctx, r.client, []string{key}, limit, int(2*rateWindow/time.Second)).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve rate limit slot: %w", err)
+	}
+	return ok == 1, nil
+}
+
+// RecentTrigger returns the execution created by the last manual trigger of a job with a
+// correlation ID, if it was remembered within the dedupe window, or uuid.Nil
+func (r *RateLimiter) RecentTrigger(ctx context.Context, jobID uuid.UUID, correlationID string) (uuid.UUID, error) {
+	if r.local != nil {
+		value, ok := r.local.Get(triggerKey(jobID, correlationID))
+		if !ok {
+			return uuid.Nil, nil
+		}
+		return uuid.Parse(value)
+	}
+	value, err := r.client.Get(ctx, triggerKey(jobID, correlationID)).Result()
+	if err == redis.Nil {
+		return uuid.Nil, nil
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return uuid.Parse(value)
+}
+
+// RememberTrigger remembers the execution a manual trigger created for the dedupe window
+func (r *RateLimiter) RememberTrigger(ctx context.Context, jobID uuid.UUID, correlationID string, executionID uuid.UUID, window time.Duration) error {
+	if r.local != nil {
+		r.local.Set(triggerKey(jobID, correlationID), executionID.String(), window)
+		return nil
+	}
+	return r.client.Set(ctx, triggerKey(jobID, correlationID), executionID.String(), window).Err()
+}
+
+// triggerKey is the key remembering a job's last manual trigger with a correlation ID
+func triggerKey(jobID uuid.UUID, correlationID string) string {
+	return fmt.Sprintf("triggers:recent:%s:%s", jobID, correlationID)
+}
+
+// Used returns how many executions the tenant started in the current window and when it resets
+func (r *RateLimiter) Used(ctx context.Context, tenantID uuid.UUID) (int64, time.Time, error) {
+	key, resetsAt := r.window("executions", tenantID, time.Now())
+	if r.local != nil {
+		return r.local.Count(key), resetsAt, nil
+	}
+	used, err := r.client.Get(ctx, key).Int64()
+	if err == redis.Nil {
+		return 0, resetsAt, nil
+	}
+	return used, resetsAt, err
+}
+
+// window returns the key of a tenant's or job's counter of a kind for the window containing
+// now and when that window ends
+func (r *RateLimiter) window(kind string, id uuid.UUID, now time.Time) (string, time.Time) {
+	start := now.Truncate(rateWindow)
+	return fmt.Sprintf("ratelimit:%s:%s:%d", kind, id, start.Unix()), start.Add(rateWindow)
+}
+
+// admit reports whether a new execution for the tenant may start now. If Redis is
+// unavailable executions are admitted rather than stalled.
+func (s *Scheduler) admit(ctx context.Context, tenantID uuid.UUID) bool {
+	if s.rateLimiter == nil || s.tenants == nil {
+		return true
+	}
+	ok, err := s.rateLimiter.Reserve(ctx, tenantID, s.tenants.Limits(ctx, tenantID).ExecutionsPerMinute)
+	return err != nil || ok
+}
+
+// releaseQueued starts queued executions, oldest first, as their tenants' windows free up
+func (s *Scheduler) releaseQueued(ctx context.Context) error {
+	var queued []models.JobExecution
+	err := s.retryDB(ctx, "finding queued executions", func() error {
+		var err error
+		queued, err = s.executionRepo.FindQueued(ctx, 100)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	full := make(map[uuid.UUID]bool)
+	for _, execution := range queued {
+		if full[execution.TenantID] {
+			continue
+		}
+		if !s.admit(ctx, execution.TenantID) {
+			// Keep the tenant's queue in order until the next window
+			full[execution.TenantID] = true
+			continue
+		}
+
+		job, err := s.jobRepo.FindByID(ctx, execution.JobID)
+		if err != nil {
+			s.executionRepo.CancelExecution(ctx, execution.ID)
+			continue
+		}
+		task, err := newTask(*job, execution)
+		if err != nil {
+			s.executionRepo.CancelExecution(ctx, execution.ID)
+			continue
+		}
+
+		claimedBy, claimedUntil := s.localClaim(execution.WorkerGroup)
+		released, err := s.executionRepo.MarkQueuedAsPending(ctx, execution.ID, claimedBy, claimedUntil)
+		if err != nil || !released {
+			continue
+		}
+		task.Execution.Status = models.ExecutionStatusPending
+
+		s.submit(task)
+	}
+	return nil
+}
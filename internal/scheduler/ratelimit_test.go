@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterWithoutRedis(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		limit    int
+		attempts int
+		allowed  int
+	}{
+		{name: "unlimited", limit: 0, attempts: 5, allowed: 5},
+		{name: "negative is unlimited", limit: -1, attempts: 5, allowed: 5},
+		{name: "below the limit", limit: 3, attempts: 2, allowed: 2},
+		{name: "over the limit", limit: 3, attempts: 5, allowed: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(nil)
+			tenantID := uuid.New()
+
+			allowed := 0
+			for i := 0; i < tt.attempts; i++ {
+				ok, err := limiter.Reserve(ctx, tenantID, tt.limit)
+				require.NoError(t, err)
+				if ok {
+					allowed++
+				}
+			}
+			assert.Equal(t, tt.allowed, allowed)
+
+			if tt.limit > 0 {
+				used, _, err := limiter.Used(ctx, tenantID)
+				require.NoError(t, err)
+				assert.EqualValues(t, tt.allowed, used)
+			}
+		})
+	}
+}
+
+func TestRateLimiterWithoutRedisSeparatesTenants(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewRateLimiter(nil)
+	first, second := uuid.New(), uuid.New()
+
+	ok, _ := limiter.Reserve(ctx, first, 1)
+	require.True(t, ok)
+	ok, _ = limiter.Reserve(ctx, first, 1)
+	assert.False(t, ok)
+
+	ok, _ = limiter.Reserve(ctx, second, 1)
+	assert.True(t, ok)
+}
+
+func TestRateLimiterWithoutRedisTriggers(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewRateLimiter(nil)
+	jobID := uuid.New()
+
+	ok, resetsAt, err := limiter.ReserveTrigger(ctx, jobID, 1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, resetsAt.IsZero())
+
+	ok, _, err = limiter.ReserveTrigger(ctx, jobID, 1)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Triggers don't count as executions
+	used, _, _ := limiter.Used(ctx, jobID)
+	assert.Zero(t, used)
+}
+
+func TestRateLimiterWithoutRedisRemembersTriggers(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewRateLimiter(nil)
+	jobID, executionID := uuid.New(), uuid.New()
+
+	id, err := limiter.RecentTrigger(ctx, jobID, "deploy-42")
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, id)
+
+	require.NoError(t, limiter.RememberTrigger(ctx, jobID, "deploy-42", executionID, time.Minute))
+
+	id, err = limiter.RecentTrigger(ctx, jobID, "deploy-42")
+	require.NoError(t, err)
+	assert.Equal(t, executionID, id)
+
+	id, _ = limiter.RecentTrigger(ctx, jobID, "deploy-43")
+	assert.Equal(t, uuid.Nil, id)
+}
@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// reaperInterval is how often running executions are checked for dead workers
+const reaperInterval = 30 * time.Second
+
+// reaperBatchSize bounds the stuck executions handled per check
+const reaperBatchSize = 100
+
+// reaperLoop times out executions left running by workers that died mid-run
+func (s *Scheduler) reaperLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(reaperInterval)
+	defer ticker.Stop()
+
+	// Executions orphaned by instances that stopped before this one started are handled
+	// first; the startup gate waits for a check that reached the database
+	if s.reapStuck(s.ctx) == nil {
+		s.lifecycle.markRecovered()
+	}
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if s.reapStuck(s.ctx) == nil {
+				s.lifecycle.markRecovered()
+			}
+		}
+	}
+}
+
+// reapStuck marks running executions past their job's timeout plus SCHEDULER_STUCK_GRACE_SECONDS
+// as timed out, counting them as failed runs. Every instance checks; marking the execution
+// decides which one handles it. It returns an error only when the stuck executions couldn't
+// be found.
+func (s *Scheduler) reapStuck(ctx context.Context) error {
+	grace := time.Duration(s.config.Scheduler.StuckGraceSeconds) * time.Second
+	stuck, err := s.executionRepo.FindStuck(ctx, grace, reaperBatchSize)
+	if err != nil {
+		log.Printf("scheduler: failed to find stuck executions: %v", err)
+		return err
+	}
+
+	for i := range stuck {
+		execution := &stuck[i]
+		errMsg := fmt.Sprintf("worker %s stopped reporting; execution timed out after %s", execution.WorkerID, time.Since(*execution.StartedAt).Round(time.Second))
+		timeOut := func(ctx context.Context) (bool, error) {
+			return s.executionRepo.MarkAsTimedOut(ctx, execution.ID, errMsg)
+		}
+
+		job, err := s.jobRepo.FindByID(ctx, execution.JobID)
+		if err != nil || execution.Canary {
+			// Nothing to count it on, or a canary, which isn't counted or run again
+			if reaped, err := timeOut(ctx); err == nil && reaped {
+				dispatchMetrics.Add("stuck_reaped", 1)
+				s.CancelExecution(execution.ID)
+			}
+			continue
+		}
+
+		reschedule := s.config.Scheduler.RescheduleStuck && execution.Attempt < job.MaxRetries
+		if s.recordOutcome(ctx, job, execution.ID, false, !reschedule, timeOut) == nil {
+			continue
+		}
+		dispatchMetrics.Add("stuck_reaped", 1)
+
+		// A worker on this instance that is somehow still going stops without recording anything
+		s.CancelExecution(execution.ID)
+
+		if reschedule {
+			if err := s.rescheduleStuck(ctx, job, execution); err != nil {
+				log.Printf("scheduler: failed to reschedule stuck execution %s: %v", execution.ID, err)
+			}
+			continue
+		}
+
+		s.recordIncidentFailure(ctx, job, execution.ID, errMsg)
+		s.checkFailureAlerts(ctx, job, execution.ID, errMsg)
+		s.completeOneTime(ctx, job)
+	}
+	return nil
+}
+
+// rescheduleStuck runs a timed out stuck execution again as a new execution counting as its
+// next attempt. Replays keep sending their original request.
+func (s *Scheduler) rescheduleStuck(ctx context.Context, job *models.Job, stuck *models.JobExecution) error {
+	if job.Status != models.JobStatusActive && job.Status != models.JobStatusPaused {
+		return fmt.Errorf("job status is %s", job.Status)
+	}
+
+	execution := &models.JobExecution{
+		ID:            uuid.New(),
+		JobID:         job.ID,
+		TenantID:      job.TenantID,
+		Status:        models.ExecutionStatusPending,
+		ScheduledAt:   time.Now(),
+		Attempt:       stuck.Attempt + 1,
+		Request:       stuck.Request,
+		ReplayOf:      stuck.ReplayOf,
+		WorkerGroup:   s.groupOf(job),
+		CorrelationID: stuck.CorrelationID,
+	}
+	execution.ClaimedBy, execution.ClaimedUntil = s.localClaim(execution.WorkerGroup)
+	task, err := newTask(*job, *execution)
+	if err != nil {
+		return err
+	}
+
+	if err := s.executionRepo.Create(ctx, execution); err != nil {
+		return err
+	}
+	database.AfterCommit(ctx, func() { s.submit(task) })
+	return nil
+}
@@ -0,0 +1,510 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minisource/scheduler/internal/mail"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Report formats
+const (
+	ReportFormatHTML = "html"
+	ReportFormatCSV  = "csv"
+)
+
+// reportDataLimit bounds the data response a report is rendered from
+const reportDataLimit = 10 << 20
+
+// ReportTarget is the target_config of report jobs: where the data comes from, how the
+// document is rendered and where it is delivered
+type ReportTarget struct {
+	DataURL  string         `json:"data_url,omitempty"` // JSON fetched with GET and the job's headers; the payload is the data when empty
+	Format   string         `json:"format"`             // html or csv
+	Template string         `json:"template,omitempty"` // Renders the document; csv reports can list columns instead
+	Columns  []string       `json:"columns,omitempty"`  // Fields of each object in a csv report's data array
+	Filename string         `json:"filename,omitempty"` // Template; defaults to <job name>-<date>.<format>
+	Deliver  ReportDelivery `json:"deliver"`
+}
+
+// ReportDelivery lists where a report is delivered; at least one destination is required
+type ReportDelivery struct {
+	Email   *ReportEmail   `json:"email,omitempty"`
+	S3      *ResponseSink  `json:"s3,omitempty"` // Object key is <prefix><job id>/<execution id>/<filename>
+	Webhook *ReportWebhook `json:"webhook,omitempty"`
+}
+
+// ReportEmail sends a report as an attachment; html reports are also the email's body
+type ReportEmail struct {
+	To      []string `json:"to"`
+	Cc      []string `json:"cc,omitempty"`
+	Subject string   `json:"subject"`        // Template
+	Text    string   `json:"text,omitempty"` // Plain text body template
+}
+
+// ReportWebhook posts a report to a URL
+type ReportWebhook struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"` // May reference secrets
+}
+
+// ParseReportTarget reads a report job's target from its target_config
+func ParseReportTarget(job *models.Job) (*ReportTarget, error) {
+	var target ReportTarget
+	if len(job.TargetConfig) == 0 {
+		return nil, fmt.Errorf("report targets need a target_config")
+	}
+	if err := json.Unmarshal(job.TargetConfig, &target); err != nil {
+		return nil, fmt.Errorf("invalid report target_config: %w", err)
+	}
+
+	switch target.Format {
+	case ReportFormatHTML:
+		if target.Template == "" {
+			return nil, fmt.Errorf("html reports need a template")
+		}
+	case ReportFormatCSV:
+		if (target.Template == "") == (len(target.Columns) == 0) {
+			return nil, fmt.Errorf("csv reports need either a template or columns")
+		}
+	default:
+		return nil, fmt.Errorf("invalid report format %q: use html or csv", target.Format)
+	}
+	if _, err := target.parseTemplate(); err != nil {
+		return nil, err
+	}
+	if _, err := texttemplate.New("filename").Parse(target.Filename); err != nil {
+		return nil, fmt.Errorf("invalid report filename template: %w", err)
+	}
+	if target.DataURL != "" {
+		if parsed, err := url.Parse(target.DataURL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("report data_url must be an absolute URL")
+		}
+	}
+
+	deliver := target.Deliver
+	if deliver.Email == nil && deliver.S3 == nil && deliver.Webhook == nil {
+		return nil, fmt.Errorf("reports need at least one of deliver.email, deliver.s3 and deliver.webhook")
+	}
+	if email := deliver.Email; email != nil {
+		if len(email.To) == 0 || email.Subject == "" {
+			return nil, fmt.Errorf("report emails need to and subject")
+		}
+		if _, err := mail.ParseAddresses(append(append([]string(nil), email.To...), email.Cc...)); err != nil {
+			return nil, err
+		}
+		for name, src := range map[string]string{"subject": email.Subject, "text": email.Text} {
+			if _, err := texttemplate.New(name).Parse(src); err != nil {
+				return nil, fmt.Errorf("invalid report email %s template: %w", name, err)
+			}
+		}
+	}
+	if deliver.S3 != nil && deliver.S3.Bucket == "" {
+		return nil, fmt.Errorf("report deliver.s3 needs a bucket")
+	}
+	if webhook := deliver.Webhook; webhook != nil {
+		if parsed, err := url.Parse(webhook.URL); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("report webhook url must be an absolute URL")
+		}
+	}
+	return &target, nil
+}
+
+// validateReportBody checks that a report job doesn't set a raw body
+func validateReportBody(job *models.Job) error {
+	if job.Body != "" {
+		return fmt.Errorf("report targets take data from data_url or payload, not a raw body")
+	}
+	return nil
+}
+
+// reportTemplate renders a report document
+type reportTemplate interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// parseTemplate parses a report's document template; csv reports without one have none
+func (t *ReportTarget) parseTemplate() (reportTemplate, error) {
+	if t.Template == "" {
+		return nil, nil
+	}
+	var tmpl reportTemplate
+	var err error
+	if t.Format == ReportFormatHTML {
+		tmpl, err = htmltemplate.New("report").Option("missingkey=zero").Parse(t.Template)
+	} else {
+		tmpl, err = texttemplate.New("report").Option("missingkey=zero").
+			Funcs(texttemplate.FuncMap{"csv": csvField}).Parse(t.Template)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid report template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// contentType returns the media type of a report document
+func (t *ReportTarget) contentType() string {
+	if t.Format == ReportFormatHTML {
+		return "text/html; charset=utf-8"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// executeReport fetches a report's data, renders the document and delivers it to every
+// destination. The run fails if any delivery fails; retries deliver to all of them again.
+func (e *Executor) executeReport(ctx context.Context, job *models.Job) (*ExecutionResult, error) {
+	startTime := time.Now()
+	result := &ExecutionResult{}
+
+	fail := func(span trace.Span, err error) (*ExecutionResult, error) {
+		result.Error = err.Error()
+		result.Duration = time.Since(startTime).Milliseconds()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, result.Error)
+		return result, err
+	}
+
+	target, err := ParseReportTarget(job)
+	if err != nil {
+		return fail(trace.SpanFromContext(ctx), err)
+	}
+	if ctx, err = e.withEgress(ctx, job.TenantID); err != nil {
+		return fail(trace.SpanFromContext(ctx), err)
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, "report "+target.Format,
+		trace.WithAttributes(attribute.String("report.format", target.Format)),
+	)
+	defer span.End()
+
+	job, err = e.resolveSecrets(ctx, job)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	var data templateData
+	data.Job.ID, data.Job.Name, data.Job.Tags = job.ID, job.Name, job.TagList()
+	data.Now = time.Now()
+	if data.Data, err = e.reportData(ctx, job, target); err != nil {
+		return fail(span, err)
+	}
+
+	document, err := target.render(data)
+	if err != nil {
+		return fail(span, err)
+	}
+	filename, err := target.filename(job, data)
+	if err != nil {
+		return fail(span, err)
+	}
+	span.SetAttributes(attribute.Int("report.size", len(document)))
+
+	deliveries := map[string]interface{}{}
+	var failures []error
+	if sink := target.Deliver.S3; sink != nil {
+		if object, err := e.deliverReportS3(ctx, job, sink, filename, target.contentType(), document); err != nil {
+			failures = append(failures, fmt.Errorf("s3: %w", err))
+		} else {
+			deliveries["s3"] = object
+		}
+	}
+	if webhook := target.Deliver.Webhook; webhook != nil {
+		if status, err := e.deliverReportWebhook(ctx, job, webhook, filename, target.contentType(), document); err != nil {
+			failures = append(failures, fmt.Errorf("webhook: %w", err))
+		} else {
+			deliveries["webhook"] = map[string]interface{}{"status_code": status}
+		}
+	}
+	if email := target.Deliver.Email; email != nil {
+		if err := e.deliverReportEmail(ctx, target, email, filename, document, data); err != nil {
+			failures = append(failures, fmt.Errorf("email: %w", err))
+		} else {
+			deliveries["email"] = map[string]interface{}{"to": email.To, "cc": email.Cc}
+		}
+	}
+
+	result.Body, _ = json.Marshal(map[string]interface{}{
+		"filename":   filename,
+		"size":       len(document),
+		"deliveries": deliveries,
+	})
+	if len(failures) > 0 {
+		return fail(span, fmt.Errorf("report delivery failed: %w", errors.Join(failures...)))
+	}
+	result.Duration = time.Since(startTime).Milliseconds()
+	return result, nil
+}
+
+// reportData fetches the JSON a report is rendered from, or reads it from the payload
+func (e *Executor) reportData(ctx context.Context, job *models.Job, target *ReportTarget) (interface{}, error) {
+	var raw []byte
+	if target.DataURL == "" {
+		raw = job.Payload
+	} else {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.DataURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create data request: %w", err)
+		}
+		req.Header.Set("User-Agent", "Minisource-Scheduler/1.0")
+		req.Header.Set("Accept", contentTypeJSON)
+		req.Header.Set("X-Scheduler-Job-ID", job.ID.String())
+		req.Header.Set("X-Scheduler-Tenant-ID", job.TenantID.String())
+		if len(job.Headers) > 0 {
+			var headers map[string]string
+			if err := json.Unmarshal(job.Headers, &headers); err == nil {
+				for key, value := range headers {
+					req.Header.Set(key, value)
+				}
+			}
+		}
+
+		client, err := e.clientFor(ctx, job)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch report data: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return nil, fmt.Errorf("report data_url answered HTTP %d", resp.StatusCode)
+		}
+		if raw, err = io.ReadAll(io.LimitReader(resp.Body, reportDataLimit+1)); err != nil {
+			return nil, fmt.Errorf("failed to read report data: %w", err)
+		}
+		if len(raw) > reportDataLimit {
+			return nil, fmt.Errorf("report data is larger than %d bytes", reportDataLimit)
+		}
+	}
+
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var data interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("report data is not JSON: %w", err)
+	}
+	return data, nil
+}
+
+// render renders a report document from its data
+func (t *ReportTarget) render(data templateData) ([]byte, error) {
+	tmpl, err := t.parseTemplate()
+	if err != nil {
+		return nil, err
+	}
+	if tmpl == nil {
+		return csvTable(t.Columns, data.Data)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// filename renders a report's filename, keeping it to a single path segment
+func (t *ReportTarget) filename(job *models.Job, data templateData) (string, error) {
+	if t.Filename == "" {
+		name := strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+				return r
+			}
+			return '-'
+		}, job.Name)
+		name = strings.Trim(name, "-")
+		return fmt.Sprintf("%s-%s.%s", name, data.Now.UTC().Format("2006-01-02"), t.Format), nil
+	}
+
+	tmpl, err := texttemplate.New("filename").Option("missingkey=zero").Parse(t.Filename)
+	if err != nil {
+		return "", fmt.Errorf("invalid report filename template: %w", err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render report filename: %w", err)
+	}
+	filename := path.Base(strings.TrimSpace(out.String()))
+	if filename == "." || filename == "/" {
+		return "", fmt.Errorf("report filename rendered empty")
+	}
+	return filename, nil
+}
+
+// deliverReportS3 uploads a report to object storage, under its job and execution so runs
+// don't overwrite each other's reports and retries replace their own
+func (e *Executor) deliverReportS3(ctx context.Context, job *models.Job, sink *ResponseSink, filename, contentType string, document []byte) (*SinkObject, error) {
+	key := strings.TrimPrefix(sink.Prefix, "/") + job.ID.String() + "/" + executionFrom(ctx).String() + "/" + filename
+	if err := e.checkStorage(ctx, job.TenantID, sink.Bucket, key); err != nil {
+		return nil, err
+	}
+
+	client, err := e.objects.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := client.PutObject(ctx, sink.Bucket, key, bytes.NewReader(document), int64(len(document)), minio.PutObjectOptions{
+		ContentType: contentType,
+		UserMetadata: map[string]string{
+			"scheduler-job-id":    job.ID.String(),
+			"scheduler-tenant-id": job.TenantID.String(),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload s3://%s/%s: %w", sink.Bucket, key, err)
+	}
+	return &SinkObject{
+		Bucket:      sink.Bucket,
+		Key:         key,
+		Location:    "s3://" + sink.Bucket + "/" + key,
+		Size:        info.Size,
+		ContentType: contentType,
+		ETag:        info.ETag,
+	}, nil
+}
+
+// deliverReportWebhook posts a report to a webhook, returning its status code
+func (e *Executor) deliverReportWebhook(ctx context.Context, job *models.Job, webhook *ReportWebhook, filename, contentType string, document []byte) (int, error) {
+	headers, err := expandSecrets(ctx, e.secrets, job.TenantID, webhook.Headers)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(document))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Minisource-Scheduler/1.0")
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, escapeQuotes(filename)))
+	req.Header.Set("X-Scheduler-Job-ID", job.ID.String())
+	req.Header.Set("X-Scheduler-Tenant-ID", job.TenantID.String())
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	client, err := e.clientFor(ctx, job)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode >= 400 {
+		return resp.StatusCode, fmt.Errorf("HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return resp.StatusCode, nil
+}
+
+// deliverReportEmail emails a report as an attachment, and as the body of html reports
+func (e *Executor) deliverReportEmail(ctx context.Context, target *ReportTarget, email *ReportEmail, filename string, document []byte, data templateData) error {
+	msg := &mail.Message{
+		To: email.To,
+		Cc: email.Cc,
+		Attachments: []mail.Attachment{{
+			Filename:    filename,
+			ContentType: target.contentType(),
+			Data:        document,
+		}},
+	}
+	for _, field := range []struct {
+		name string
+		src  string
+		dst  *string
+	}{
+		{"subject", email.Subject, &msg.Subject},
+		{"text", email.Text, &msg.Text},
+	} {
+		tmpl, err := texttemplate.New(field.name).Option("missingkey=zero").Parse(field.src)
+		if err != nil {
+			return fmt.Errorf("invalid report email %s template: %w", field.name, err)
+		}
+		var out strings.Builder
+		if err := tmpl.Execute(&out, data); err != nil {
+			return fmt.Errorf("failed to render report email %s: %w", field.name, err)
+		}
+		*field.dst = out.String()
+	}
+	if target.Format == ReportFormatHTML {
+		msg.HTML = string(document)
+	}
+	if msg.Text == "" && msg.HTML == "" {
+		msg.Text = fmt.Sprintf("%s is attached.", filename)
+	}
+	return e.mail.Send(ctx, msg)
+}
+
+// csvTable renders a csv report from an array of objects, one row per object
+func csvTable(columns []string, data interface{}) ([]byte, error) {
+	rows, ok := data.([]interface{})
+	if !ok && data != nil {
+		return nil, fmt.Errorf("csv reports with columns need a JSON array of objects as data")
+	}
+
+	var out bytes.Buffer
+	w := csv.NewWriter(&out)
+	w.Write(columns)
+	for i, row := range rows {
+		fields, ok := row.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("csv report row %d is not an object", i)
+		}
+		record := make([]string, len(columns))
+		for j, column := range columns {
+			record[j] = csvValue(fields[column])
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return out.Bytes(), w.Error()
+}
+
+// csvValue formats a JSON value as a csv field; objects and arrays are written as JSON
+func csvValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	encoded, _ := json.Marshal(v)
+	return string(encoded)
+}
+
+// csvField quotes a value for a csv template when it needs it
+func csvField(v interface{}) string {
+	field := csvValue(v)
+	if strings.ContainsAny(field, "\",\r\n") || strings.HasPrefix(field, " ") || strings.HasSuffix(field, " ") {
+		return `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+	}
+	return field
+}
@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// resumeBatchSize bounds the paused jobs resumed per tick
+const resumeBatchSize = 100
+
+// resumeDueJobs resumes paused jobs whose resume time has passed. Runs missed while a job was
+// paused are handled by its misfire policy, as when it is resumed through the API.
+func (s *Scheduler) resumeDueJobs(ctx context.Context) {
+	now := time.Now()
+	jobs, err := s.jobRepo.FindDueForResume(ctx, now, resumeBatchSize)
+	if err != nil {
+		log.Printf("scheduler: failed to find paused jobs due to resume: %v", err)
+		return
+	}
+
+	for _, job := range jobs {
+		resumed, err := s.jobRepo.ResumeDue(ctx, job.ID, now)
+		if err != nil {
+			log.Printf("scheduler: failed to resume job %s: %v", job.ID, err)
+			continue
+		}
+		if !resumed {
+			continue // Resumed or paused again meanwhile
+		}
+		dispatchMetrics.Add("jobs_auto_resumed", 1)
+		log.Printf("scheduler: resumed job %s (%s), paused until %s", job.ID, job.Name, job.ResumeAt.Format(time.RFC3339))
+		s.recordRevision(ctx, job.ID, models.JobRevisionResumed)
+	}
+}
+
+// recordRevision records the revision of a job the scheduler changed on its own
+func (s *Scheduler) recordRevision(ctx context.Context, id uuid.UUID, change models.JobRevisionChange) {
+	job, err := s.jobRepo.FindByID(ctx, id)
+	if err != nil {
+		log.Printf("scheduler: failed to record %s revision of job %s: %v", change, id, err)
+		return
+	}
+	snapshot, err := json.Marshal(job)
+	if err != nil {
+		return
+	}
+	if err := s.revisionRepo.Create(ctx, &models.JobRevision{
+		JobID:    job.ID,
+		TenantID: job.TenantID,
+		Change:   change,
+		Snapshot: snapshot,
+	}); err != nil {
+		log.Printf("scheduler: failed to record %s revision of job %s: %v", change, id, err)
+	}
+}
@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+// Process roles for SCHEDULER_ROLE
+const (
+	RoleAll        = "all"        // Serves the API, dispatches due jobs and runs executions
+	RoleAPI        = "api"        // Only serves the API; triggered runs are left to workers
+	RoleDispatcher = "dispatcher" // Only dispatches due jobs and runs background maintenance
+	RoleWorker     = "worker"     // Only runs executions and delivers callbacks
+)
+
+// pickupInterval is how often workers look for pending executions left to any instance
+const pickupInterval = time.Second
+
+// Role returns the instance's SCHEDULER_ROLE
+func (s *Scheduler) Role() string {
+	return s.config.Scheduler.Role
+}
+
+// ServesAPI reports whether the instance's role serves the HTTP API
+func (s *Scheduler) ServesAPI() bool {
+	return s.config.Scheduler.Role == RoleAll || s.config.Scheduler.Role == RoleAPI
+}
+
+// dispatches reports whether the instance's role dispatches due jobs and runs maintenance
+func (s *Scheduler) dispatches() bool {
+	return s.config.Scheduler.Role == RoleAll || s.config.Scheduler.Role == RoleDispatcher
+}
+
+// runsWorkers reports whether the instance's role runs executions
+func (s *Scheduler) runsWorkers() bool {
+	return s.config.Scheduler.Role == RoleAll || s.config.Scheduler.Role == RoleWorker
+}
+
+// singleNode reports whether the instance runs alone without Redis, with no other instance
+// to coordinate with through the database
+func (s *Scheduler) singleNode() bool {
+	return s.config.Scheduler.SingleNode
+}
+
+// WorkerGroup returns the instance's WORKER_GROUP; empty is the default group
+func (s *Scheduler) WorkerGroup() string {
+	return s.config.Scheduler.WorkerGroup
+}
+
+// groupOf returns the worker group a job's executions are pinned to: the job's worker_group,
+// or else the first of its tags named in WORKER_GROUPS. Jobs without one run in the default
+// group.
+func (s *Scheduler) groupOf(job *models.Job) string {
+	if job.WorkerGroup != "" {
+		return job.WorkerGroup
+	}
+	groups := s.config.Scheduler.GroupList()
+	for _, tag := range job.TagList() {
+		for _, group := range groups {
+			if tag == group {
+				return group
+			}
+		}
+	}
+	return ""
+}
+
+// localClaim returns the claim an instance puts on executions it creates: its own workers
+// run them unless the claim lapses first. Instances without workers, or whose workers are in
+// another group than the execution, leave them unclaimed for a matching worker to pick up.
+func (s *Scheduler) localClaim(group string) (string, *time.Time) {
+	if !s.runsWorkers() || group != s.WorkerGroup() {
+		return "", nil
+	}
+	until := time.Now().Add(time.Duration(s.config.Scheduler.LockTTLSeconds) * time.Second)
+	return s.instanceID, &until
+}
+
+// submit hands a pending execution to this instance's workers. Without workers of its group
+// it stays pending in the database until a matching worker instance picks it up.
+func (s *Scheduler) submit(task JobTask) bool {
+	if s.workerPool == nil || task.Execution.WorkerGroup != s.WorkerGroup() {
+		return false
+	}
+	return s.workerPool.Submit(task)
+}
+
+// pickupLoop runs pending executions created by instances without workers, and executions
+// whose instance's claim lapsed before they started
+func (s *Scheduler) pickupLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(pickupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if !s.lifecycle.draining() {
+				s.pickupPending(s.ctx)
+			}
+		}
+	}
+}
+
+// pickupPending claims as many pending executions as the worker queue has room for
+func (s *Scheduler) pickupPending(ctx context.Context) {
+	room := s.workerPool.Available()
+	if room > dispatchBatchSize {
+		room = dispatchBatchSize
+	}
+	if room == 0 {
+		return
+	}
+
+	lease := time.Duration(s.config.Scheduler.LockTTLSeconds) * time.Second
+	executions, err := s.executionRepo.ClaimPending(ctx, s.instanceID, s.WorkerGroup(), lease, room)
+	if err != nil {
+		log.Printf("scheduler: failed to claim pending executions: %v", err)
+		return
+	}
+	if len(executions) == 0 {
+		return
+	}
+	dispatchMetrics.Add("executions_picked_up", int64(len(executions)))
+
+	jobs := make(map[uuid.UUID]*models.Job)
+	for _, execution := range executions {
+		job, ok := jobs[execution.JobID]
+		if !ok {
+			job, err = s.jobRepo.FindByID(ctx, execution.JobID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				s.executionRepo.CancelExecution(ctx, execution.ID)
+				continue
+			}
+			if err != nil {
+				// Left for the claim to lapse
+				continue
+			}
+			jobs[execution.JobID] = job
+		}
+		task, err := newTask(*job, execution)
+		if err != nil {
+			s.executionRepo.CancelExecution(ctx, execution.ID)
+			continue
+		}
+		// An execution that doesn't fit is picked up again once the claim lapses
+		s.submit(task)
+	}
+}
@@ -0,0 +1,215 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// rolloutBatchInterval is how long each batch of a rollout runs before the next one
+	rolloutBatchInterval = time.Hour
+	// rolloutAdvanceBatchSize bounds the rollouts advanced per tick
+	rolloutAdvanceBatchSize = 5
+)
+
+// advanceRollouts applies the next batch of each active rollout that is due, halting those
+// whose changed jobs started failing more than before
+func (s *Scheduler) advanceRollouts(ctx context.Context) {
+	rollouts, err := s.rolloutRepo.FindDue(ctx, time.Now(), rolloutAdvanceBatchSize)
+	if err != nil {
+		log.Printf("scheduler: failed to find due rollouts: %v", err)
+		return
+	}
+
+	for i := range rollouts {
+		if err := s.advanceRollout(ctx, &rollouts[i]); err != nil {
+			log.Printf("scheduler: failed to advance rollout %s: %v", rollouts[i].ID, err)
+		}
+	}
+}
+
+// advanceRollout judges the jobs a rollout changed so far and, while they are healthy,
+// changes the next batch, or completes the rollout once every job was changed
+func (s *Scheduler) advanceRollout(ctx context.Context, rollout *models.Rollout) error {
+	if rollout.AppliedJobs > 0 {
+		health, err := s.rolloutRepo.Health(ctx, rollout.ID, rollout.HealthSince)
+		if err != nil {
+			return err
+		}
+		if health.Executions >= int64(rollout.MinExecutions) && health.FailureRate > rollout.BaselineFailureRate+rollout.FailureThreshold {
+			reason := fmt.Sprintf("failure rate of the changed jobs rose to %.1f%% (%d of %d executions), %.1f%% before the rollout",
+				health.FailureRate*100, health.Failures, health.Executions, rollout.BaselineFailureRate*100)
+			halted, err := s.rolloutRepo.Halt(ctx, rollout.ID, reason)
+			if err != nil || !halted {
+				return err
+			}
+			dispatchMetrics.Add("rollouts_halted", 1)
+			log.Printf("scheduler: halted rollout %s of %q: %s", rollout.ID, rollout.ExternalID, reason)
+			return nil
+		}
+	}
+
+	pending, err := s.rolloutRepo.FindPending(ctx, rollout.ID, rolloutBatch(rollout))
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		completed, err := s.rolloutRepo.Complete(ctx, rollout.ID)
+		if err != nil || !completed {
+			return err
+		}
+		dispatchMetrics.Add("rollouts_completed", 1)
+		log.Printf("scheduler: completed rollout %s of %q to %d jobs", rollout.ID, rollout.ExternalID, rollout.TotalJobs)
+		return nil
+	}
+
+	applied := rollout.AppliedJobs
+	for i := range pending {
+		if err := s.applyRollout(ctx, rollout, &pending[i]); err != nil {
+			log.Printf("scheduler: failed to roll %s out to job %s: %v", rollout.ID, pending[i].JobID, err)
+			break
+		}
+		applied++
+	}
+
+	advanced, err := s.rolloutRepo.Advance(ctx, rollout.ID, applied, time.Now().Add(rolloutBatchInterval))
+	if err != nil {
+		return err
+	}
+	if !advanced {
+		// Rolled back while the batch was applied; put back the jobs it changed too
+		current, err := s.rolloutRepo.FindByID(ctx, rollout.ID)
+		if err == nil && current.Status == models.RolloutRolledBack {
+			return s.revertRolloutJobs(ctx, current)
+		}
+		return err
+	}
+	dispatchMetrics.Add("rollout_batches", 1)
+	log.Printf("scheduler: rolled %s out to %d of %d jobs of %q", rollout.ID, applied, rollout.TotalJobs, rollout.ExternalID)
+	return nil
+}
+
+// rolloutBatch returns how many jobs a rollout changes per batch
+func rolloutBatch(rollout *models.Rollout) int {
+	return max((rollout.TotalJobs*rollout.PercentPerHour+99)/100, 1)
+}
+
+// applyRollout changes a job's schedule and endpoint to the rollout's, keeping the ones it had
+// so they can be put back. Jobs deleted since the rollout started are skipped.
+func (s *Scheduler) applyRollout(ctx context.Context, rollout *models.Rollout, item *models.RolloutJob) error {
+	now := time.Now()
+	item.AppliedAt = &now
+
+	job, err := s.jobRepo.FindByID(ctx, item.JobID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && job.Status == models.JobStatusDeleted) {
+		item.Skipped = true
+		return s.rolloutRepo.MarkApplied(ctx, item)
+	}
+	if err != nil {
+		return err
+	}
+
+	item.PreviousSchedule, item.PreviousEndpoint = job.Schedule, job.Endpoint
+	if rollout.Schedule != "" {
+		job.Schedule = rollout.Schedule
+	}
+	if rollout.Endpoint != "" {
+		job.Endpoint = rollout.Endpoint
+	}
+	nextRunAt := job.NextRunAt
+	if rollout.Schedule != "" && nextRunAt != nil {
+		if next, err := s.CalculateNextRun(ctx, job); err == nil {
+			nextRunAt = next
+		}
+	}
+
+	err = s.jobRepo.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.jobRepo.SetScheduleAndEndpoint(ctx, job.ID, job.Schedule, job.Endpoint, nextRunAt); err != nil {
+			return err
+		}
+		return s.rolloutRepo.MarkApplied(ctx, item)
+	})
+	if err != nil {
+		return err
+	}
+	s.recordRevision(ctx, job.ID, models.JobRevisionRolledOut)
+	return nil
+}
+
+// RollbackRollout stops a rollout and puts the jobs it changed back on their previous schedule
+// and endpoint. Jobs whose schedule or endpoint was changed again since keep the newer one. It
+// returns false when the rollout had already been rolled back.
+func (s *Scheduler) RollbackRollout(ctx context.Context, id uuid.UUID) (bool, error) {
+	rolledBack, err := s.rolloutRepo.MarkRolledBack(ctx, id)
+	if err != nil || !rolledBack {
+		return false, err
+	}
+	rollout, err := s.rolloutRepo.FindByID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if err := s.revertRolloutJobs(ctx, rollout); err != nil {
+		return false, err
+	}
+	log.Printf("scheduler: rolled back rollout %s of %q", rollout.ID, rollout.ExternalID)
+	return true, nil
+}
+
+// revertRolloutJobs puts the jobs a rollout changed back on what they had before
+func (s *Scheduler) revertRolloutJobs(ctx context.Context, rollout *models.Rollout) error {
+	items, err := s.rolloutRepo.FindApplied(ctx, rollout.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		job, err := s.jobRepo.FindByID(ctx, item.JobID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := s.rolloutRepo.MarkReverted(ctx, rollout.ID, item.JobID, time.Now()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		reverted := false
+		if rollout.Schedule != "" && job.Schedule == rollout.Schedule && item.PreviousSchedule != job.Schedule {
+			job.Schedule, reverted = item.PreviousSchedule, true
+		}
+		rescheduled := reverted
+		if rollout.Endpoint != "" && job.Endpoint == rollout.Endpoint && item.PreviousEndpoint != job.Endpoint {
+			job.Endpoint, reverted = item.PreviousEndpoint, true
+		}
+		nextRunAt := job.NextRunAt
+		if rescheduled && nextRunAt != nil {
+			if next, err := s.CalculateNextRun(ctx, job); err == nil {
+				nextRunAt = next
+			}
+		}
+
+		err = s.jobRepo.Transaction(ctx, func(ctx context.Context) error {
+			if reverted {
+				if err := s.jobRepo.SetScheduleAndEndpoint(ctx, job.ID, job.Schedule, job.Endpoint, nextRunAt); err != nil {
+					return err
+				}
+			}
+			return s.rolloutRepo.MarkReverted(ctx, rollout.ID, job.ID, time.Now())
+		})
+		if err != nil {
+			return err
+		}
+		if reverted {
+			s.recordRevision(ctx, job.ID, models.JobRevisionRolloutReverted)
+		}
+	}
+	return nil
+}
@@ -2,30 +2,95 @@ package scheduler
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/egress"
+	"github.com/minisource/scheduler/internal/features"
 	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/notification"
 	"github.com/minisource/scheduler/internal/repository"
+	"github.com/minisource/scheduler/internal/tracing"
 	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// Dispatch modes for SCHEDULER_DISPATCH_MODE
+const (
+	DispatchModeLeader = "leader" // One instance at a time dispatches every due job
+	DispatchModeClaim  = "claim"  // Every instance dispatches the due jobs it claims
+)
+
+// dispatchBatchSize bounds the due jobs an instance dispatches per tick
+const dispatchBatchSize = 100
+
 // Scheduler is the core scheduler engine
 type Scheduler struct {
 	config        *config.Config
 	jobRepo       *repository.JobRepository
 	executionRepo *repository.ExecutionRepository
 	historyRepo   *repository.HistoryRepository
-	locker        *DistributedLocker
+	incidentRepo  *repository.IncidentRepository
+	sealRepo      *repository.ExecutionSealRepository
+	shiftRepo     *repository.ScheduleShiftRepository
+	rolloutRepo   *repository.RolloutRepository
+	alertRepo     *repository.AlertRepository
+	callbackRepo  *repository.CallbackRepository
+	settingsRepo  *repository.SettingsRepository
+	clusterRepo   *repository.ClusterRepository
+	revisionRepo  *repository.JobRevisionRepository
+	calendarRepo  *repository.CalendarRepository
+	janitorRepo   *repository.JanitorRepository
+	locker        Locker
+	rateLimiter   *RateLimiter
+	flags         *features.Store
+	tenants       TenantLimits
+	notifier      *notification.Dispatcher
+	secrets       SecretResolver
 	executor      *Executor
 	workerPool    *WorkerPool
+	hosts         *hostLimiter
 	cronParser    cron.Parser
 
+	// Client posting execution result callbacks
+	callbackClient *http.Client
+
+	// Identifies this instance's claims on due jobs
+	instanceID string
+	hostname   string
+
+	// Database health of the scheduling loop
+	guard dispatchGuard
+
+	// Startup gate and drain state
+	lifecycle lifecycle
+
+	// Whether this instance holds the leader lock
+	leadership leadership
+
+	// Comparisons of the leader's dispatch with claim dispatch
+	shadow shadow
+
+	// Settings changeable at runtime
+	tuning tunables
+
+	// Cancel functions of executions running on this instance, and executions waiting to retry
+	active   map[uuid.UUID]context.CancelCauseFunc
+	retrying map[uuid.UUID]retryWait
+	activeMu sync.Mutex
+
 	ctx     context.Context
 	cancel  context.CancelFunc
 	wg      sync.WaitGroup
@@ -39,18 +104,59 @@ func NewScheduler(
 	jobRepo *repository.JobRepository,
 	executionRepo *repository.ExecutionRepository,
 	historyRepo *repository.HistoryRepository,
-	locker *DistributedLocker,
+	incidentRepo *repository.IncidentRepository,
+	sealRepo *repository.ExecutionSealRepository,
+	shiftRepo *repository.ScheduleShiftRepository,
+	rolloutRepo *repository.RolloutRepository,
+	alertRepo *repository.AlertRepository,
+	callbackRepo *repository.CallbackRepository,
+	settingsRepo *repository.SettingsRepository,
+	clusterRepo *repository.ClusterRepository,
+	revisionRepo *repository.JobRevisionRepository,
+	calendarRepo *repository.CalendarRepository,
+	janitorRepo *repository.JanitorRepository,
+	locker Locker,
+	rateLimiter *RateLimiter,
+	flags *features.Store,
+	tenants TenantLimits,
+	notifier *notification.Dispatcher,
+	secrets SecretResolver,
 ) *Scheduler {
 	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
 
-	return &Scheduler{
+	s := &Scheduler{
 		config:        cfg,
 		jobRepo:       jobRepo,
 		executionRepo: executionRepo,
 		historyRepo:   historyRepo,
+		incidentRepo:  incidentRepo,
+		sealRepo:      sealRepo,
+		shiftRepo:     shiftRepo,
+		rolloutRepo:   rolloutRepo,
+		alertRepo:     alertRepo,
+		callbackRepo:  callbackRepo,
+		settingsRepo:  settingsRepo,
+		clusterRepo:   clusterRepo,
+		revisionRepo:  revisionRepo,
+		calendarRepo:  calendarRepo,
+		janitorRepo:   janitorRepo,
 		locker:        locker,
+		rateLimiter:   rateLimiter,
+		flags:         flags,
+		tenants:       tenants,
+		notifier:      notifier,
+		secrets:       secrets,
 		cronParser:    parser,
+		hosts:         newHostLimiter(cfg.Scheduler.MaxPerHost),
+		active:        make(map[uuid.UUID]context.CancelCauseFunc),
+		retrying:      make(map[uuid.UUID]retryWait),
+		instanceID:    fmt.Sprintf("instance-%s", uuid.New().String()[:8]),
 	}
+	s.hostname, _ = os.Hostname()
+	s.tuning.workerCount.Store(int64(cfg.Scheduler.WorkerCount))
+	s.tuning.tickIntervalMs.Store(int64(cfg.Scheduler.TickIntervalMs))
+	s.tuning.cleanupDays.Store(int64(cfg.Scheduler.CleanupDays))
+	return s
 }
 
 // Start starts the scheduler
@@ -64,23 +170,65 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	s.ctx, s.cancel = context.WithCancel(ctx)
 	s.running = true
 	s.mu.Unlock()
+	s.lifecycle.start(time.Now())
 
 	// Initialize executor
 	s.executor = NewExecutor(s.config, &http.Client{
-		Timeout: time.Duration(s.config.Scheduler.LockTTLSeconds) * time.Second,
-	})
+		Timeout: time.Duration(s.config.Scheduler.HTTPClientTimeoutSeconds) * time.Second,
+	}, s.secrets, s.tenants)
+	s.callbackClient = egress.NewClient(s.config.Egress, time.Duration(s.config.Callbacks.TimeoutSeconds)*time.Second)
+	if names := HookNames(); len(names) > 0 {
+		log.Printf("scheduler: execution hooks: %s", strings.Join(names, ", "))
+	}
+	if names := ExecutorNames(); len(names) > 0 {
+		log.Printf("scheduler: custom target types: %s", strings.Join(names, ", "))
+	}
+
+	// Runtime setting overrides apply from the start and are kept in sync
+	s.syncSettings(s.ctx)
+	s.wg.Add(1)
+	go s.settingsLoop()
+
+	// Start the dispatcher's scheduling and maintenance loops
+	if s.dispatches() {
+		s.wg.Add(8)
+		go s.schedulerLoop()
+		go s.heartbeatLoop()
+		go s.cleanupLoop()
+		go s.escalationLoop()
+		go s.alertLoop()
+		go s.janitorLoop()
+		go s.sealLoop()
+		go s.reaperLoop()
+	} else {
+		// Recovery and catch-up are the dispatchers' job
+		s.lifecycle.markRecovered()
+		s.lifecycle.markCaughtUp()
+	}
 
-	// Initialize worker pool
-	s.workerPool = NewWorkerPool(s.config.Scheduler.WorkerCount, s.processJob)
+	// Start the worker pool and the loops serving running executions
+	if s.runsWorkers() {
+		s.workerPool = NewWorkerPool(int(s.tuning.workerCount.Load()), s.processJob)
+		s.workerPool.Start(s.ctx)
 
-	// Start worker pool
-	s.workerPool.Start(s.ctx)
+		s.wg.Add(2)
+		go s.pickupLoop()
+		go s.callbackLoop()
+
+		// A single node stops cancelled executions itself, with no other instance to hear from
+		if !s.singleNode() {
+			s.wg.Add(1)
+			go s.cancellationLoop()
+		}
+	}
 
-	// Start scheduler loops
-	s.wg.Add(3)
-	go s.schedulerLoop()
-	go s.heartbeatLoop()
-	go s.cleanupLoop()
+	// Join the worker registry once the worker pool is up, so capacity is reported. A single
+	// node keeps no registry and reports only itself.
+	if !s.singleNode() {
+		s.registerMember(s.ctx)
+		s.wg.Add(1)
+		go s.memberLoop()
+	}
 
 	return nil
 }
@@ -104,6 +252,16 @@ func (s *Scheduler) Stop() {
 	}
 
 	s.wg.Wait()
+
+	// Let another instance take the lead without waiting for the lock to lapse
+	s.resign(context.Background())
+	if !s.singleNode() {
+		s.leaveCluster(context.Background())
+	}
+
+	if s.executor != nil {
+		s.executor.Close()
+	}
 }
 
 // IsRunning returns whether the scheduler is running
@@ -117,7 +275,8 @@ func (s *Scheduler) IsRunning() bool {
 func (s *Scheduler) schedulerLoop() {
 	defer s.wg.Done()
 
-	ticker := time.NewTicker(time.Second)
+	interval := s.tickInterval()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -126,71 +285,338 @@ func (s *Scheduler) schedulerLoop() {
 			return
 		case <-ticker.C:
 			s.processScheduledJobs()
+			if next := s.tickInterval(); next != interval {
+				interval = next
+				ticker.Reset(interval)
+			}
 		}
 	}
 }
 
-// processScheduledJobs processes jobs that are due
+// processScheduledJobs processes jobs that are due. In leader mode only the instance holding
+// the leader lock dispatches. In claim mode every instance dispatches the due jobs it claims,
+// and the leader lock only guards work that must not run twice per tick. A single node has
+// no one to share due jobs with, so it always dispatches as the leader without claiming.
 func (s *Scheduler) processScheduledJobs() {
-	// Try to acquire leader lock
-	lockKey := "scheduler:leader"
-	acquired, err := s.locker.AcquireLock(s.ctx, lockKey, time.Duration(s.config.Scheduler.LockTTLSeconds)*time.Second)
-	if err != nil || !acquired {
+	// A draining instance leaves due jobs to the others
+	if s.lifecycle.draining() {
+		return
+	}
+
+	leader, err := s.lead(s.ctx)
+	claim := s.config.Scheduler.DispatchMode == DispatchModeClaim && !s.singleNode()
+	if !leader && !claim {
+		if err == nil {
+			// Catching up is the leader's job
+			s.lifecycle.markCaughtUp()
+		}
 		return // Another instance is the leader
 	}
-	defer s.locker.ReleaseLock(s.ctx, lockKey)
+
+	// While the database is failing, skip ticks; missed runs are caught up by the misfire policy
+	if !s.guard.allow(time.Now()) {
+		return
+	}
+	dispatchMetrics.Add("ticks", 1)
+
+	if leader {
+		// Start executions held back by rate limits before new ones take their slots
+		if err := s.releaseQueued(s.ctx); err != nil {
+			s.tickFailed(err)
+			return
+		}
+
+		// Put jobs whose schedule shift has ended back on their own schedules
+		s.revertEndedShifts(s.ctx)
+
+		// Change the next batch of each rollout that is due, or halt it if its changed jobs fail
+		s.advanceRollouts(s.ctx)
+
+		// Resume paused jobs whose resume time has passed, so they are dispatched below
+		s.resumeDueJobs(s.ctx)
+	}
 
 	// Find jobs due for execution
-	jobs, err := s.jobRepo.FindJobsDueForExecution(s.ctx, time.Now(), 100)
+	now := time.Now()
+	var jobs []models.Job
+	backlog := false
+	err = s.retryDB(s.ctx, "finding due jobs", func() error {
+		var err error
+		jobs, backlog, err = s.findDueJobs(now, leader, claim)
+		return err
+	})
 	if err != nil {
+		s.tickFailed(err)
+		return
+	}
+	if claim && len(jobs) > 0 {
+		// Jobs left undispatched by a failing database are claimable again next tick. Jobs
+		// the leader found without claiming them aren't claimed by this instance, so
+		// releasing them leaves them alone.
+		defer s.releaseClaims(jobs)
+	}
+
+	if err := s.dispatchJobs(jobs, now); err != nil {
+		s.tickFailed(err)
 		return
 	}
+	s.guard.success()
+	if !backlog {
+		s.lifecycle.markCaughtUp()
+	}
+}
+
+// findDueJobs finds the due jobs this instance dispatches this tick, and whether a full
+// batch was found so more may be due. In claim mode, jobs of tenants with the
+// claim_dispatch flag on are claimed by every instance, and the leader dispatches the other
+// tenants' jobs as in leader mode.
+func (s *Scheduler) findDueJobs(now time.Time, leader, claim bool) ([]models.Job, bool, error) {
+	if !claim {
+		jobs, err := s.jobRepo.FindJobsDueForExecution(s.ctx, now, nil, dispatchBatchSize)
+		if err != nil {
+			return nil, false, err
+		}
+		s.shadowDispatch(now, jobs, nil)
+		return jobs, len(jobs) >= dispatchBatchSize, nil
+	}
+
+	rule := s.flags.Rule(features.ClaimDispatch)
+	var jobs []models.Job
+	backlog := false
+	if rule.Enabled {
+		lease := time.Duration(s.config.Scheduler.LockTTLSeconds) * time.Second
+		claimed, err := s.jobRepo.ClaimDueJobs(s.ctx, s.instanceID, now, lease, rule.Tenants, dispatchBatchSize)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(claimed) > 0 {
+			dispatchMetrics.Add("jobs_claimed", int64(len(claimed)))
+		}
+		jobs = claimed
+		backlog = len(claimed) >= dispatchBatchSize
+	}
+
+	if leader && !rule.All() {
+		// Tenants the flag is on for are claimed above; with the flag off, none are
+		var claiming []uuid.UUID
+		if rule.Enabled {
+			claiming = rule.Tenants
+		}
+		due, err := s.jobRepo.FindJobsDueForExecution(s.ctx, now, claiming, dispatchBatchSize)
+		if err != nil {
+			// Release the claims taken above, so other instances can dispatch them
+			s.releaseClaims(jobs)
+			return nil, false, err
+		}
+		s.shadowDispatch(now, due, claiming)
+		jobs = append(jobs, due...)
+		backlog = backlog || len(due) >= dispatchBatchSize
+	}
+	return jobs, backlog, nil
+}
+
+// dispatchJobs creates executions for due jobs, advances their schedules and hands the
+// executions to the worker pool. It stops at the first database error.
+func (s *Scheduler) dispatchJobs(jobs []models.Job, now time.Time) error {
+	var tickErr error
+	blackouts := s.loadBlackouts(s.ctx, jobs)
 
 	for _, job := range jobs {
-		// Create execution record
-		execution := &models.JobExecution{
-			ID:          uuid.New(),
-			JobID:       job.ID,
-			TenantID:    job.TenantID,
-			Status:      models.ExecutionStatusPending,
-			ScheduledAt: time.Now(),
-			Attempt:     1,
+		if ended(&job, now) {
+			s.endJob(s.ctx, &job)
+			continue
+		}
+		if s.blackedOut(s.ctx, &job, blackouts, now) {
+			continue
 		}
 
-		if err := s.executionRepo.Create(s.ctx, execution); err != nil {
+		// Apply the misfire policy, then the concurrency policy to active executions
+		runs := capRuns(&job, s.dueRuns(&job, now))
+		if len(runs) == 0 || !s.applyConcurrencyPolicy(s.ctx, &job) {
+			// Skip this run but keep the schedule moving
+			s.advanceSchedule(s.ctx, &job)
+			if job.Type == models.JobTypeOneTime {
+				// A skipped one-time job has nothing left to run
+				s.jobRepo.MarkCompleted(s.ctx, job.ID)
+			}
 			continue
 		}
 
-		// Calculate next run time
-		nextRunAt, err := s.CalculateNextRun(&job)
-		if err == nil && nextRunAt != nil {
-			s.jobRepo.UpdateNextRunAt(s.ctx, job.ID, *nextRunAt)
+		var tasks []JobTask
+		created := 0
+		for _, scheduledAt := range runs {
+			// Create execution record, queued if the tenant is over its rate limit
+			execution := &models.JobExecution{
+				ID:            uuid.New(),
+				JobID:         job.ID,
+				TenantID:      job.TenantID,
+				Status:        models.ExecutionStatusPending,
+				ScheduledAt:   scheduledAt,
+				Attempt:       1,
+				Request:       SnapshotRequest(&job),
+				WorkerGroup:   s.groupOf(&job),
+				CorrelationID: job.CorrelationID,
+			}
+			if !s.admit(s.ctx, job.TenantID) {
+				execution.Status = models.ExecutionStatusQueued
+			} else {
+				execution.ClaimedBy, execution.ClaimedUntil = s.localClaim(execution.WorkerGroup)
+			}
+
+			err := s.retryDB(s.ctx, "creating execution", func() error {
+				return s.executionRepo.Create(s.ctx, execution)
+			})
+			if err != nil {
+				// The job's next run isn't advanced, so it's picked up again next tick
+				tickErr = err
+				break
+			}
+			created++
+			if execution.Status == models.ExecutionStatusPending {
+				tasks = append(tasks, JobTask{
+					Job:       job,
+					Execution: *execution,
+				})
+			}
+		}
+		if created == 0 {
+			if tickErr != nil {
+				break
+			}
+			continue
 		}
 
+		// Calculate next run time
+		s.advanceSchedule(s.ctx, &job)
+
 		// Submit to worker pool
-		s.workerPool.Submit(JobTask{
-			Job:       job,
-			Execution: *execution,
-		})
+		for _, task := range tasks {
+			s.submit(task)
+		}
+		if tickErr != nil {
+			// Leave the remaining jobs for a later tick rather than retrying each one
+			break
+		}
+	}
+
+	return tickErr
+}
+
+// releaseClaims lets other instances claim jobs again once they have been dispatched
+func (s *Scheduler) releaseClaims(jobs []models.Job) {
+	ids := make([]uuid.UUID, len(jobs))
+	for i := range jobs {
+		ids[i] = jobs[i].ID
+	}
+	if err := s.jobRepo.ReleaseClaims(s.ctx, s.instanceID, ids); err != nil {
+		log.Printf("scheduler: failed to release claimed jobs: %v", err)
+	}
+}
+
+// advanceSchedule moves a job's next run past the run just dispatched or skipped
+func (s *Scheduler) advanceSchedule(ctx context.Context, job *models.Job) {
+	if job.Type == models.JobTypeOneTime {
+		s.jobRepo.ClearNextRunAt(ctx, job.ID)
+		return
+	}
+
+	nextRunAt, err := s.CalculateNextRun(ctx, job)
+	if err == nil && nextRunAt != nil {
+		s.jobRepo.UpdateNextRunAt(ctx, job.ID, *nextRunAt)
 	}
 }
 
 // processJob processes a single job execution
 func (s *Scheduler) processJob(task JobTask) {
-	ctx, cancel := context.WithTimeout(s.ctx, time.Duration(task.Job.Timeout)*time.Second)
-	defer cancel()
+	// A slow target host only holds up to its share of the workers; later runs wait their turn
+	release, ok := s.hosts.tryAcquire(targetHost(&task.Job))
+	if !ok {
+		s.deferForHost(task)
+		return
+	}
+	defer release()
+
+	ctx, cancelCause := context.WithCancelCause(s.ctx)
+	s.trackExecution(task.Execution.ID, cancelCause)
+	defer s.untrackExecution(task.Execution.ID)
 
 	workerID := fmt.Sprintf("worker-%s", uuid.New().String()[:8])
 
+	ctx, span := tracing.Tracer().Start(ctx, "scheduler.execute",
+		trace.WithAttributes(
+			attribute.String("job.id", task.Job.ID.String()),
+			attribute.String("job.name", task.Job.Name),
+			attribute.String("job.type", string(task.Job.Type)),
+			attribute.String("tenant.id", task.Job.TenantID.String()),
+			attribute.String("execution.id", task.Execution.ID.String()),
+			attribute.Int("execution.attempt", task.Execution.Attempt),
+		),
+	)
+	defer span.End()
+
 	// Mark as running
-	if err := s.executionRepo.MarkAsRunning(ctx, task.Execution.ID, workerID); err != nil {
+	claimed, err := s.executionRepo.MarkAsRunning(ctx, task.Execution.ID, workerID, tracing.TraceID(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to mark execution as running")
 		return
 	}
+	if !claimed {
+		// Cancelled before it started
+		return
+	}
+
+	// Execute the job within its timeout, with the registered hooks around it. Hooks get a
+	// copy of the job, so their changes don't outlive the attempt.
+	runCtx, cancel := context.WithTimeoutCause(ctx, time.Duration(task.Job.Timeout)*time.Second, errExecutionTimedOut)
+	defer cancel()
+	runCtx, meter := withMeter(withExecution(runCtx, task.Execution.ID))
+	attemptStart := time.Now()
+	job := task.Job
+	var result *ExecutionResult
+	err = runBeforeHooks(runCtx, &job, &task.Execution)
+	if err == nil {
+		result, err = s.executor.Execute(runCtx, &job)
+	}
+
+	// Recording the outcome must outlive the run's deadline and cancellation
+	ctx = context.WithoutCancel(ctx)
 
-	// Execute the job
-	result, err := s.executor.Execute(ctx, &task.Job)
+	// Every attempt's usage counts, whatever its outcome, so it's added before the outcome is
+	// recorded and counted in history
+	wallTime := time.Since(attemptStart).Milliseconds()
+	if err := s.executionRepo.AddUsage(ctx, task.Execution.ID, wallTime, meter.sent.Load(), meter.received.Load()); err != nil {
+		log.Printf("scheduler: failed to record the usage of execution %s: %v", task.Execution.ID, err)
+	}
+
+	switch cause := context.Cause(runCtx); {
+	case errors.Is(cause, errExecutionCancelled):
+		// Already recorded as cancelled, even if the target answered before the request
+		// stopped; don't retry or count the run
+		runAfterHooks(ctx, &job, &task.Execution, result, errExecutionCancelled)
+		span.SetStatus(codes.Error, errExecutionCancelled.Error())
+		if !task.Execution.Canary {
+			s.completeOneTime(ctx, &task.Job)
+		}
+		return
+	case errors.Is(cause, errExecutionTimedOut):
+		// Targets that ignore the deadline still lose their late answer
+		err = fmt.Errorf("%w after %ds", errExecutionTimedOut, task.Job.Timeout)
+	}
+	runAfterHooks(ctx, &job, &task.Execution, result, err)
+
+	if task.Execution.Canary {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		s.finishCanary(ctx, &task, result, err)
+		return
+	}
 
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		s.handleExecutionFailure(ctx, &task, err, result)
 		return
 	}
@@ -199,6 +625,9 @@ func (s *Scheduler) processJob(task JobTask) {
 	var response []byte
 	if result != nil {
 		response = result.Body
+		if projected, err := ApplyProjection(response, task.Job.ResponseProjection); err == nil {
+			response = projected
+		}
 	}
 
 	statusCode := 0
@@ -206,17 +635,25 @@ func (s *Scheduler) processJob(task JobTask) {
 		statusCode = result.StatusCode
 	}
 
-	if err := s.executionRepo.MarkAsCompleted(ctx, task.Execution.ID, statusCode, response); err != nil {
+	// Record the success with the job's counters, history and callback. An execution that
+	// was cancelled or timed out meanwhile keeps that outcome, counted where it was set.
+	recorded := s.recordOutcome(ctx, &task.Job, task.Execution.ID, true, true, func(ctx context.Context) (bool, error) {
+		return s.executionRepo.MarkAsCompleted(ctx, task.Execution.ID, statusCode, response)
+	})
+	if recorded == nil {
 		return
 	}
 
-	// Update job counters
-	s.jobRepo.UpdateLastRunAt(ctx, task.Job.ID, true)
-
-	// Update history
-	if result != nil {
-		s.historyRepo.IncrementSuccess(ctx, task.Job.ID, time.Now(), result.Duration)
+	// Disable the job once it has had its maximum runs
+	if task.Job.MaxRuns > 0 {
+		s.endJob(ctx, &task.Job)
 	}
+
+	// Close any open incident for this job
+	s.resolveIncident(ctx, &task.Job)
+	s.resolveFailureAlerts(ctx, &task.Job)
+
+	s.completeOneTime(ctx, &task.Job)
 }
 
 // handleExecutionFailure handles a failed execution
@@ -234,20 +671,35 @@ func (s *Scheduler) handleExecutionFailure(ctx context.Context, task *JobTask, e
 
 		// Schedule retry
 		retryDelay := time.Duration(s.config.Scheduler.RetryDelaySeconds) * time.Second
-		time.AfterFunc(retryDelay, func() {
-			task.Execution.Attempt++
-			s.workerPool.Submit(*task)
-		})
+		s.scheduleRetry(*task, retryDelay)
 		return
 	}
 
 	// Max retries exceeded
-	s.executionRepo.MarkAsFailed(ctx, task.Execution.ID, errMsg, statusCode)
-	s.jobRepo.UpdateLastRunAt(ctx, task.Job.ID, false)
-	s.historyRepo.IncrementFailure(ctx, task.Job.ID, time.Now())
+	recorded := s.recordOutcome(ctx, &task.Job, task.Execution.ID, false, true, func(ctx context.Context) (bool, error) {
+		if errors.Is(err, errExecutionTimedOut) {
+			return s.executionRepo.MarkAsTimedOut(ctx, task.Execution.ID, errMsg)
+		}
+		return s.executionRepo.MarkAsFailed(ctx, task.Execution.ID, errMsg, statusCode)
+	})
+	if recorded == nil {
+		return
+	}
+	s.recordIncidentFailure(ctx, &task.Job, task.Execution.ID, errMsg)
+	s.checkFailureAlerts(ctx, &task.Job, task.Execution.ID, errMsg)
+	s.completeOneTime(ctx, &task.Job)
+}
+
+// completeOneTime marks a one-time job completed after its scheduled execution has finished.
+// Manual triggers before the scheduled time leave the job active.
+func (s *Scheduler) completeOneTime(ctx context.Context, job *models.Job) {
+	if job.Type != models.JobTypeOneTime {
+		return
+	}
+	s.jobRepo.MarkCompleted(ctx, job.ID)
 }
 
-// heartbeatLoop maintains scheduler heartbeat
+// heartbeatLoop keeps the leader lock of a leading instance from lapsing
 func (s *Scheduler) heartbeatLoop() {
 	defer s.wg.Done()
 
@@ -260,7 +712,7 @@ func (s *Scheduler) heartbeatLoop() {
 		case <-s.ctx.Done():
 			return
 		case <-ticker.C:
-			s.locker.RefreshLock(s.ctx, "scheduler:leader", time.Duration(s.config.Scheduler.LockTTLSeconds)*time.Second)
+			s.leaderHeartbeat(s.ctx)
 		}
 	}
 }
@@ -282,68 +734,239 @@ func (s *Scheduler) cleanupLoop() {
 	}
 }
 
-// cleanup removes old execution records
+// escalationLoop periodically escalates unacknowledged incidents
+func (s *Scheduler) escalationLoop() {
+	defer s.wg.Done()
+
+	if s.notifier == nil {
+		return
+	}
+
+	interval := time.Duration(s.config.Notifications.EscalationCheckSeconds) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.notifier.Escalate(s.ctx)
+		}
+	}
+}
+
+// purgeBatchSize bounds the deleted jobs purged per transaction
+const purgeBatchSize = 100
+
+// sealBatchSize bounds the executions sealed per transaction
+const sealBatchSize = 500
+
+// sealLoop appends finished executions to their tenants' hash chains when chaining is enabled
+func (s *Scheduler) sealLoop() {
+	defer s.wg.Done()
+
+	if !s.config.Scheduler.ExecutionChain {
+		return
+	}
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			for s.ctx.Err() == nil {
+				sealed, err := s.sealRepo.SealPending(s.ctx, sealBatchSize)
+				if err != nil {
+					log.Printf("scheduler: failed to seal executions: %v", err)
+					break
+				}
+				if sealed < sealBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// cleanup removes old execution records and purges deleted jobs past their grace period
 func (s *Scheduler) cleanup() {
-	cutoff := time.Now().AddDate(0, 0, -s.config.Scheduler.CleanupDays)
+	cutoff := time.Now().AddDate(0, 0, -int(s.tuning.cleanupDays.Load()))
 	s.executionRepo.CleanupOld(s.ctx, cutoff)
 	s.historyRepo.CleanupOld(s.ctx, cutoff)
+	s.callbackRepo.CleanupOld(s.ctx, cutoff)
+	if _, err := s.clusterRepo.DeleteMembersBefore(s.ctx, time.Now().Add(-memberRetention)); err != nil {
+		log.Printf("scheduler: failed to remove dead members from the worker registry: %v", err)
+	}
+
+	for s.ctx.Err() == nil {
+		purged, err := s.jobRepo.PurgeDeleted(s.ctx, time.Now(), purgeBatchSize)
+		if err != nil {
+			log.Printf("scheduler: failed to purge deleted jobs: %v", err)
+			return
+		}
+		if purged > 0 {
+			log.Printf("scheduler: purged %d deleted jobs", purged)
+		}
+		if purged < purgeBatchSize {
+			return
+		}
+	}
+}
+
+// PurgeGracePeriod is how long a deleted job can be restored before it is purged
+func (s *Scheduler) PurgeGracePeriod() time.Duration {
+	return time.Duration(s.config.Scheduler.PurgeGraceDays) * 24 * time.Hour
 }
 
-// CalculateNextRun calculates the next run time for a job
-func (s *Scheduler) CalculateNextRun(job *models.Job) (*time.Time, error) {
+// MaxJobTimeout is the largest timeout a job can set
+func (s *Scheduler) MaxJobTimeout() time.Duration {
+	return time.Duration(s.config.Scheduler.MaxJobTimeoutSeconds) * time.Second
+}
+
+// DrainTimeout is the longest a drain waits for local executions to finish
+func (s *Scheduler) DrainTimeout() time.Duration {
+	return time.Duration(s.config.Scheduler.DrainTimeoutSeconds) * time.Second
+}
+
+// CalculateNextRun calculates the next run time for a job, no earlier than its start time and
+// no later than its end time
+func (s *Scheduler) CalculateNextRun(ctx context.Context, job *models.Job) (*time.Time, error) {
 	now := time.Now()
+	starting := job.StartAt != nil && job.StartAt.After(now)
 
 	switch job.Type {
 	case models.JobTypeCron:
-		schedule, err := s.cronParser.Parse(job.Schedule)
+		next, err := s.cronNext(ctx, job)
 		if err != nil {
-			return nil, fmt.Errorf("invalid cron expression: %w", err)
+			return nil, err
 		}
-		next := schedule.Next(now)
-		return &next, nil
+		after := now
+		if starting {
+			// The first occurrence at or after the start time
+			after = job.StartAt.Add(-time.Nanosecond)
+		}
+		nextRunAt := next(after)
+		if nextRunAt.IsZero() {
+			return nil, fmt.Errorf("cron schedule has no upcoming occurrence")
+		}
+		nextRunAt = clampToEnd(job, nextRunAt)
+		return &nextRunAt, nil
 
 	case models.JobTypeInterval:
-		var interval int
-		if err := json.Unmarshal([]byte(job.Schedule), &interval); err != nil {
-			return nil, fmt.Errorf("invalid interval: %w", err)
+		interval, err := ParseInterval(job.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		next := now.Add(interval)
+		if starting {
+			next = *job.StartAt
 		}
-		next := now.Add(time.Duration(interval) * time.Second)
+		next = clampToEnd(job, next)
 		return &next, nil
 
 	case models.JobTypeOneTime:
-		// One-time jobs don't have a next run
-		return nil, nil
+		// One-time jobs run at their timestamp, even if it has passed, until they have run
+		if job.LastRunAt != nil || job.Status == models.JobStatusCompleted {
+			return nil, nil
+		}
+		runAt, err := ParseRunAt(job.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		runAt = shiftTime(job, runAt)
+		return &runAt, nil
 
 	default:
 		return nil, fmt.Errorf("unknown job type: %s", job.Type)
 	}
 }
 
-// TriggerJob manually triggers a job
-func (s *Scheduler) TriggerJob(ctx context.Context, jobID uuid.UUID) (*models.JobExecution, error) {
+// ParseRunAt parses a one-time job schedule, an RFC3339 timestamp
+func ParseRunAt(schedule string) (time.Time, error) {
+	runAt, err := time.Parse(time.RFC3339, schedule)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid one-time schedule (should be an RFC3339 timestamp): %w", err)
+	}
+	return runAt, nil
+}
+
+// ParseInterval parses an interval job schedule, either a Go duration string ("90s", "2h30m")
+// or a bare integer of seconds
+func ParseInterval(schedule string) (time.Duration, error) {
+	schedule = strings.TrimSpace(schedule)
+
+	var interval time.Duration
+	if seconds, err := strconv.Atoi(schedule); err == nil {
+		interval = time.Duration(seconds) * time.Second
+	} else if interval, err = time.ParseDuration(schedule); err != nil {
+		return 0, fmt.Errorf("invalid interval (should be a duration like \"5m\" or seconds as integer): %w", err)
+	}
+
+	if interval < time.Second {
+		return 0, fmt.Errorf("interval must be at least 1 second")
+	}
+	return interval, nil
+}
+
+// TriggerJob manually triggers a job. The execution carries correlationID, or the job's
+// correlation ID when it is empty. An identical trigger within the job's dedupe window whose
+// execution hasn't started returns that execution instead, reporting true; triggers over the
+// job's rate limit fail with a *TriggerLimitError.
+func (s *Scheduler) TriggerJob(ctx context.Context, jobID uuid.UUID, correlationID string) (*models.JobExecution, bool, error) {
 	job, err := s.jobRepo.FindByID(ctx, jobID)
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+	if correlationID == "" {
+		correlationID = job.CorrelationID
+	}
+
+	if pending := s.pendingTrigger(ctx, job, correlationID); pending != nil {
+		dispatchMetrics.Add("triggers_deduplicated", 1)
+		return pending, true, nil
+	}
+	if err := s.reserveTrigger(ctx, job); err != nil {
+		return nil, false, err
 	}
 
 	execution := &models.JobExecution{
-		ID:          uuid.New(),
-		JobID:       job.ID,
-		TenantID:    job.TenantID,
-		Status:      models.ExecutionStatusPending,
-		ScheduledAt: time.Now(),
-		Attempt:     1,
+		ID:            uuid.New(),
+		JobID:         job.ID,
+		TenantID:      job.TenantID,
+		Status:        models.ExecutionStatusPending,
+		ScheduledAt:   time.Now(),
+		Attempt:       1,
+		Request:       SnapshotRequest(job),
+		WorkerGroup:   s.groupOf(job),
+		CorrelationID: correlationID,
+	}
+	if !s.admit(ctx, job.TenantID) {
+		execution.Status = models.ExecutionStatusQueued
+	} else {
+		execution.ClaimedBy, execution.ClaimedUntil = s.localClaim(execution.WorkerGroup)
 	}
 
 	if err := s.executionRepo.Create(ctx, execution); err != nil {
-		return nil, err
+		return nil, false, err
+	}
+	s.rememberTrigger(ctx, job, correlationID, execution.ID)
+	if execution.Status == models.ExecutionStatusQueued {
+		return execution, false, nil
 	}
 
-	// Submit to worker pool
-	s.workerPool.Submit(JobTask{
+	// Submit to worker pool once the execution is committed and visible to workers
+	task := JobTask{
 		Job:       *job,
 		Execution: *execution,
-	})
+	}
+	database.AfterCommit(ctx, func() { s.submit(task) })
 
-	return execution, nil
+	return execution, false, nil
 }
@@ -0,0 +1,153 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/features"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// Limits of shadow dispatch reports
+const (
+	shadowRecentReports = 20 // Mismatching comparisons kept for the API
+	shadowLoggedJobs    = 10 // Job IDs listed per difference in logs
+)
+
+// ShadowReport compares the due jobs the leader dispatched in one tick with those claim
+// dispatch would have claimed at the same moment
+type ShadowReport struct {
+	At        time.Time   `json:"at"`
+	Leader    int         `json:"leader"`            // Jobs the leader dispatched
+	Claimable int         `json:"claimable"`         // Jobs claim dispatch would have claimed
+	Matched   int         `json:"matched"`           // Jobs both picked
+	Missing   []uuid.UUID `json:"missing,omitempty"` // Dispatched by the leader but not claimable, e.g. held by another instance's claim
+	Extra     []uuid.UUID `json:"extra,omitempty"`   // Claimable but not dispatched by the leader
+	Truncated bool        `json:"truncated"`         // A full batch was found, so jobs past it weren't compared
+}
+
+// ShadowStatus summarises shadow dispatch on this instance
+type ShadowStatus struct {
+	Rule        string         `json:"rule"` // Tenants the claim_dispatch_shadow flag is on for
+	Comparisons int64          `json:"comparisons"`
+	Mismatches  int64          `json:"mismatches"`
+	LastAt      *time.Time     `json:"last_at,omitempty"`
+	Recent      []ShadowReport `json:"recent"` // Latest mismatching comparisons, newest first
+}
+
+// shadow keeps the outcome of shadow dispatch comparisons
+type shadow struct {
+	mu          sync.Mutex
+	comparisons int64
+	mismatches  int64
+	lastAt      time.Time
+	recent      []ShadowReport
+}
+
+// record adds a comparison, keeping it if the engines disagreed
+func (sh *shadow) record(report ShadowReport) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.comparisons++
+	sh.lastAt = report.At
+	if len(report.Missing) == 0 && len(report.Extra) == 0 {
+		return
+	}
+	sh.mismatches++
+	sh.recent = append([]ShadowReport{report}, sh.recent...)
+	if len(sh.recent) > shadowRecentReports {
+		sh.recent = sh.recent[:shadowRecentReports]
+	}
+}
+
+// shadowDispatch compares the due jobs the leader found for dispatch with those claim
+// dispatch would claim for the tenants the claim_dispatch_shadow flag is on for, without
+// claiming them, so claim dispatch can be checked against the leader before a tenant is
+// moved onto it. Jobs of the excluded tenants are claimed for real and left out.
+func (s *Scheduler) shadowDispatch(now time.Time, dispatched []models.Job, excluded []uuid.UUID) {
+	rule := s.flags.Rule(features.ClaimDispatchShadow)
+	if !rule.Enabled {
+		return
+	}
+
+	claimable, err := s.jobRepo.FindClaimableJobs(s.ctx, now, rule.Tenants, excluded, dispatchBatchSize)
+	if err != nil {
+		log.Printf("scheduler: shadow dispatch failed to find claimable jobs: %v", err)
+		return
+	}
+
+	report := ShadowReport{At: now, Claimable: len(claimable)}
+	picked := make(map[uuid.UUID]bool, len(claimable))
+	for _, job := range claimable {
+		picked[job.ID] = true
+	}
+	compared := make(map[uuid.UUID]bool, len(dispatched))
+	for _, job := range dispatched {
+		if !rule.On(job.TenantID) {
+			continue
+		}
+		report.Leader++
+		compared[job.ID] = true
+		if picked[job.ID] {
+			report.Matched++
+		} else {
+			report.Missing = append(report.Missing, job.ID)
+		}
+	}
+	for _, job := range claimable {
+		if !compared[job.ID] {
+			report.Extra = append(report.Extra, job.ID)
+		}
+	}
+	report.Truncated = len(dispatched) >= dispatchBatchSize || len(claimable) >= dispatchBatchSize
+
+	s.shadow.record(report)
+	dispatchMetrics.Add("shadow_comparisons", 1)
+	if len(report.Missing) > 0 || len(report.Extra) > 0 {
+		dispatchMetrics.Add("shadow_mismatches", 1)
+		log.Printf("scheduler: shadow claim dispatch differs from the leader: %d of %d dispatched jobs not claimable %s, %d claimable jobs not dispatched %s",
+			len(report.Missing), report.Leader, jobList(report.Missing), len(report.Extra), jobList(report.Extra))
+	}
+}
+
+// Shadow reports how shadow claim dispatch compared with the leader on this instance
+func (s *Scheduler) Shadow() ShadowStatus {
+	s.shadow.mu.Lock()
+	defer s.shadow.mu.Unlock()
+
+	status := ShadowStatus{
+		Rule:        s.flags.Rule(features.ClaimDispatchShadow).String(),
+		Comparisons: s.shadow.comparisons,
+		Mismatches:  s.shadow.mismatches,
+		Recent:      append([]ShadowReport{}, s.shadow.recent...),
+	}
+	if !s.shadow.lastAt.IsZero() {
+		at := s.shadow.lastAt
+		status.LastAt = &at
+	}
+	return status
+}
+
+// jobList formats up to shadowLoggedJobs job IDs for a log line
+func jobList(ids []uuid.UUID) string {
+	if len(ids) == 0 {
+		return "[]"
+	}
+	shown := ids
+	if len(shown) > shadowLoggedJobs {
+		shown = shown[:shadowLoggedJobs]
+	}
+	parts := make([]string, len(shown))
+	for i, id := range shown {
+		parts[i] = id.String()
+	}
+	list := strings.Join(parts, " ")
+	if len(ids) > len(shown) {
+		list += fmt.Sprintf(" and %d more", len(ids)-len(shown))
+	}
+	return "[" + list + "]"
+}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// shiftRevertBatchSize bounds the ended shifts reverted per tick
+const shiftRevertBatchSize = 10
+
+// shiftWindow returns the offset and window of a job's active schedule shift
+func shiftWindow(job *models.Job) (offset time.Duration, from, until time.Time, ok bool) {
+	if job.ShiftID == nil || job.ShiftStartsAt == nil || job.ShiftEndsAt == nil || job.ShiftOffsetSeconds == 0 {
+		return 0, time.Time{}, time.Time{}, false
+	}
+	return time.Duration(job.ShiftOffsetSeconds) * time.Second, *job.ShiftStartsAt, *job.ShiftEndsAt, true
+}
+
+// shiftTime moves a single occurrence by the job's shift if it falls in the shift window
+func shiftTime(job *models.Job, t time.Time) time.Time {
+	offset, from, until, ok := shiftWindow(job)
+	if ok && !t.Before(from) && t.Before(until) {
+		return t.Add(offset)
+	}
+	return t
+}
+
+// shiftedNext wraps a schedule's next function so occurrences in the job's shift window
+// are moved by the shift's offset. Occurrences that land on the same time run once.
+func shiftedNext(job *models.Job, next func(time.Time) time.Time) func(time.Time) time.Time {
+	offset, from, until, ok := shiftWindow(job)
+	if !ok {
+		return next
+	}
+	inWindow := func(t time.Time) bool { return !t.Before(from) && t.Before(until) }
+
+	return func(after time.Time) time.Time {
+		// Next occurrence outside the window, left in place
+		plain := next(after)
+		if inWindow(plain) {
+			plain = next(until.Add(-time.Nanosecond))
+		}
+
+		// Next occurrence inside the window that, once moved, is still ahead
+		moved := next(after.Add(-offset))
+		if moved.Before(from) {
+			moved = next(from.Add(-time.Nanosecond))
+		}
+		if !inWindow(moved) {
+			return plain
+		}
+		moved = moved.Add(offset)
+
+		if plain.IsZero() || moved.Before(plain) {
+			return moved
+		}
+		return plain
+	}
+}
+
+// RevertShift takes a schedule shift off the jobs it still applies to and recalculates their
+// next runs. It returns false when the shift had already been reverted.
+func (s *Scheduler) RevertShift(ctx context.Context, shiftID uuid.UUID) (bool, error) {
+	jobs, err := s.jobRepo.FindByShift(ctx, shiftID)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		job.ShiftID, job.ShiftOffsetSeconds, job.ShiftStartsAt, job.ShiftEndsAt = nil, 0, nil, nil
+
+		nextRunAt := job.NextRunAt
+		if nextRunAt != nil {
+			if next, err := s.CalculateNextRun(ctx, job); err == nil {
+				nextRunAt = next
+			}
+		}
+		if err := s.jobRepo.SetShift(ctx, job.ID, nil, nextRunAt); err != nil {
+			return false, err
+		}
+	}
+
+	return s.shiftRepo.MarkReverted(ctx, shiftID)
+}
+
+// revertEndedShifts reverts shifts whose moved occurrences have all passed
+func (s *Scheduler) revertEndedShifts(ctx context.Context) {
+	shifts, err := s.shiftRepo.FindDueForRevert(ctx, time.Now(), shiftRevertBatchSize)
+	if err != nil {
+		log.Printf("scheduler: failed to find ended schedule shifts: %v", err)
+		return
+	}
+
+	for _, shift := range shifts {
+		if _, err := s.RevertShift(ctx, shift.ID); err != nil {
+			log.Printf("scheduler: failed to revert schedule shift %s: %v", shift.ID, err)
+			continue
+		}
+		log.Printf("scheduler: reverted schedule shift %s", shift.ID)
+	}
+}
@@ -0,0 +1,145 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// ErrObjectStorageDisabled is returned when streaming to a sink while OBJECT_STORAGE_ENDPOINT is not set
+var ErrObjectStorageDisabled = errors.New("response sinks are disabled: set OBJECT_STORAGE_ENDPOINT")
+
+// ResponseSink is the response_sink of jobs whose responses are streamed to object storage
+// instead of being stored on the execution
+type ResponseSink struct {
+	Bucket string `json:"bucket"`
+	Prefix string `json:"prefix,omitempty"` // Prepended to <job id>/<timestamp> to form the object key
+}
+
+// SinkObject is stored as the execution's response when a response was streamed to a sink
+type SinkObject struct {
+	Bucket      string `json:"bucket"`
+	Key         string `json:"key"`
+	Location    string `json:"location"` // s3://bucket/key
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+	ContentType string `json:"content_type,omitempty"`
+	ETag        string `json:"etag,omitempty"`
+}
+
+// ParseResponseSink reads a job's response sink; it returns nil when the job has none
+func ParseResponseSink(job *models.Job) (*ResponseSink, error) {
+	if len(job.ResponseSink) == 0 || string(job.ResponseSink) == "null" || string(job.ResponseSink) == "{}" {
+		return nil, nil
+	}
+
+	var sink ResponseSink
+	if err := json.Unmarshal(job.ResponseSink, &sink); err != nil {
+		return nil, fmt.Errorf("invalid response_sink: %w", err)
+	}
+	if sink.Bucket == "" {
+		return nil, fmt.Errorf("response_sink needs a bucket")
+	}
+	return &sink, nil
+}
+
+// ValidateResponseSink checks a job's response sink against the rest of its definition
+func ValidateResponseSink(job *models.Job) error {
+	sink, err := ParseResponseSink(job)
+	if err != nil || sink == nil {
+		return err
+	}
+	if job.TargetType != "" && job.TargetType != models.TargetHTTP {
+		return fmt.Errorf("response_sink is only supported for http targets")
+	}
+	if job.ResponseProjection != "" {
+		return fmt.Errorf("response_sink and response_projection can't be combined")
+	}
+	return nil
+}
+
+// objectStore holds the object storage client, created on first use
+type objectStore struct {
+	config config.ObjectStorageConfig
+	client *minio.Client
+	mu     sync.Mutex
+}
+
+// newObjectStore creates an object store; it connects on first use
+func newObjectStore(cfg config.ObjectStorageConfig) *objectStore {
+	return &objectStore{config: cfg}
+}
+
+// connect returns the object storage client, creating it if needed
+func (o *objectStore) connect() (*minio.Client, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.client != nil {
+		return o.client, nil
+	}
+	if o.config.Endpoint == "" {
+		return nil, ErrObjectStorageDisabled
+	}
+
+	client, err := minio.New(o.config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(o.config.AccessKey, o.config.SecretKey, ""),
+		Secure: o.config.UseSSL,
+		Region: o.config.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object storage client: %w", err)
+	}
+	o.client = client
+	return client, nil
+}
+
+// streamResponse uploads a response body to a job's sink as it is read, hashing it on the
+// way, and returns the stored object's description
+func (e *Executor) streamResponse(ctx context.Context, job *models.Job, sink *ResponseSink, resp *http.Response) (*SinkObject, error) {
+	key := strings.TrimPrefix(sink.Prefix, "/") + job.ID.String() + "/" + time.Now().UTC().Format("20060102T150405.000000000Z")
+	if err := e.checkStorage(ctx, job.TenantID, sink.Bucket, key); err != nil {
+		return nil, err
+	}
+
+	client, err := e.objects.connect()
+	if err != nil {
+		return nil, err
+	}
+	contentType := resp.Header.Get("Content-Type")
+
+	hash := sha256.New()
+	info, err := client.PutObject(ctx, sink.Bucket, key, io.TeeReader(resp.Body, hash), resp.ContentLength, minio.PutObjectOptions{
+		ContentType: contentType,
+		UserMetadata: map[string]string{
+			"scheduler-job-id":    job.ID.String(),
+			"scheduler-tenant-id": job.TenantID.String(),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream response to s3://%s/%s: %w", sink.Bucket, key, err)
+	}
+
+	return &SinkObject{
+		Bucket:      sink.Bucket,
+		Key:         key,
+		Location:    "s3://" + sink.Bucket + "/" + key,
+		Size:        info.Size,
+		SHA256:      hex.EncodeToString(hash.Sum(nil)),
+		ContentType: contentType,
+		ETag:        info.ETag,
+	}, nil
+}
@@ -0,0 +1,132 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/database"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// RequestSnapshot is the part of a job definition that decides the request an execution
+// sends. Executions record it when they are created so they can be replayed as they ran,
+// whatever has happened to the job since. Secret references are kept unresolved.
+type RequestSnapshot struct {
+	TargetType   models.TargetType   `json:"target_type"`
+	TargetConfig json.RawMessage     `json:"target_config,omitempty"`
+	Endpoint     string              `json:"endpoint,omitempty"`
+	Method       string              `json:"method,omitempty"`
+	Headers      json.RawMessage     `json:"headers,omitempty"`
+	Payload      json.RawMessage     `json:"payload,omitempty"`
+	ContentType  string              `json:"content_type,omitempty"`
+	Body         string              `json:"body,omitempty"`
+	BodyEncoding models.BodyEncoding `json:"body_encoding,omitempty"`
+	Timeout      int                 `json:"timeout"`
+	Metadata     json.RawMessage     `json:"metadata,omitempty"` // Fallback settings of non-http targets
+	ResponseSink json.RawMessage     `json:"response_sink,omitempty"`
+	TLS          json.RawMessage     `json:"tls,omitempty"` // Secret names only, never key material
+}
+
+// SnapshotRequest captures a job's request definition for an execution record
+func SnapshotRequest(job *models.Job) json.RawMessage {
+	snapshot, _ := json.Marshal(RequestSnapshot{
+		TargetType:   job.TargetType,
+		TargetConfig: job.TargetConfig,
+		Endpoint:     job.Endpoint,
+		Method:       job.Method,
+		Headers:      job.Headers,
+		Payload:      job.Payload,
+		ContentType:  job.ContentType,
+		Body:         job.Body,
+		BodyEncoding: job.BodyEncoding,
+		Timeout:      job.Timeout,
+		Metadata:     job.Metadata,
+		ResponseSink: job.ResponseSink,
+		TLS:          job.TLS,
+	})
+	return snapshot
+}
+
+// withSnapshot returns a copy of the job with its request definition replaced by a snapshot
+func withSnapshot(job models.Job, raw json.RawMessage) (models.Job, error) {
+	var snapshot RequestSnapshot
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return job, fmt.Errorf("invalid request snapshot: %w", err)
+	}
+
+	job.TargetType = snapshot.TargetType
+	job.TargetConfig = snapshot.TargetConfig
+	job.Endpoint = snapshot.Endpoint
+	job.Method = snapshot.Method
+	job.Headers = snapshot.Headers
+	job.Payload = snapshot.Payload
+	job.ContentType = snapshot.ContentType
+	job.Body = snapshot.Body
+	job.BodyEncoding = snapshot.BodyEncoding
+	job.Timeout = snapshot.Timeout
+	job.Metadata = snapshot.Metadata
+	job.ResponseSink = snapshot.ResponseSink
+	job.TLS = snapshot.TLS
+	return job, nil
+}
+
+// newTask builds the worker task of an execution. Replays run the request their original
+// execution recorded rather than the job's current definition.
+func newTask(job models.Job, execution models.JobExecution) (JobTask, error) {
+	if execution.ReplayOf != nil {
+		replayed, err := withSnapshot(job, execution.Request)
+		if err != nil {
+			return JobTask{}, err
+		}
+		job = replayed
+	}
+	return JobTask{Job: job, Execution: execution}, nil
+}
+
+// ReplayExecution creates a new execution of a job that sends the request recorded by an
+// earlier execution. Like a manual trigger, it takes one of the job's triggers for the current
+// window, failing with a *TriggerLimitError when there are none left, and it is queued when the
+// tenant is over its rate limit.
+func (s *Scheduler) ReplayExecution(ctx context.Context, job *models.Job, original *models.JobExecution) (*models.JobExecution, error) {
+	originalID := original.ID
+	execution := &models.JobExecution{
+		ID:            uuid.New(),
+		JobID:         job.ID,
+		TenantID:      job.TenantID,
+		Status:        models.ExecutionStatusPending,
+		ScheduledAt:   time.Now(),
+		Attempt:       1,
+		Request:       original.Request,
+		ReplayOf:      &originalID,
+		WorkerGroup:   s.groupOf(job),
+		CorrelationID: original.CorrelationID,
+	}
+
+	task, err := newTask(*job, *execution)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reserveTrigger(ctx, job); err != nil {
+		return nil, err
+	}
+
+	if !s.admit(ctx, job.TenantID) {
+		execution.Status = models.ExecutionStatusQueued
+	} else {
+		execution.ClaimedBy, execution.ClaimedUntil = s.localClaim(execution.WorkerGroup)
+	}
+	if err := s.executionRepo.Create(ctx, execution); err != nil {
+		return nil, err
+	}
+	if execution.Status == models.ExecutionStatusQueued {
+		return execution, nil
+	}
+
+	task.Execution = *execution
+	database.AfterCommit(ctx, func() { s.submit(task) })
+
+	return execution, nil
+}
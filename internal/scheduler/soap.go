@@ -0,0 +1,253 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SOAP versions, which differ in how the action and faults are sent
+const (
+	SOAPVersion11 = "1.1"
+	SOAPVersion12 = "1.2"
+)
+
+// SOAPTarget is the target_config of soap jobs. The job's body holds the envelope. Fields
+// missing from target_config are read from the job's metadata under the same names.
+type SOAPTarget struct {
+	Action       string            `json:"action,omitempty"`        // SOAPAction header (1.1) or action parameter of the Content-Type (1.2)
+	Version      string            `json:"version,omitempty"`       // 1.1 (default) or 1.2
+	SuccessXPath string            `json:"success_xpath,omitempty"` // Must match the response for the run to succeed
+	Namespaces   map[string]string `json:"namespaces,omitempty"`    // Prefixes used in success_xpath
+}
+
+// ParseSOAPTarget reads a soap job's target from its target_config and metadata
+func ParseSOAPTarget(job *models.Job) (*SOAPTarget, error) {
+	var target, fallback SOAPTarget
+	if len(job.TargetConfig) > 0 {
+		if err := json.Unmarshal(job.TargetConfig, &target); err != nil {
+			return nil, fmt.Errorf("invalid soap target_config: %w", err)
+		}
+	}
+	if len(job.Metadata) > 0 {
+		// Metadata is free-form, so fields of the wrong type are ignored
+		_ = json.Unmarshal(job.Metadata, &fallback)
+	}
+
+	if target.Action == "" {
+		target.Action = fallback.Action
+	}
+	if target.Version == "" {
+		target.Version = fallback.Version
+	}
+	if target.SuccessXPath == "" {
+		target.SuccessXPath = fallback.SuccessXPath
+	}
+	if len(target.Namespaces) == 0 {
+		target.Namespaces = fallback.Namespaces
+	}
+	if target.Version == "" {
+		target.Version = SOAPVersion11
+	}
+
+	if target.Version != SOAPVersion11 && target.Version != SOAPVersion12 {
+		return nil, fmt.Errorf("invalid soap version %q: use 1.1 or 1.2", target.Version)
+	}
+	if strings.ContainsAny(target.Action, "\"\r\n") {
+		return nil, fmt.Errorf("soap action can't contain quotes or line breaks")
+	}
+	if target.SuccessXPath != "" {
+		if _, err := target.successExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if job.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required for soap targets")
+	}
+	if parsed, err := url.Parse(job.Endpoint); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("endpoint must be an absolute URL")
+	}
+	return &target, nil
+}
+
+// successExpr compiles the target's success assertion
+func (t *SOAPTarget) successExpr() (*xpath.Expr, error) {
+	expr, err := xpath.CompileWithNS(t.SuccessXPath, t.Namespaces)
+	if err != nil {
+		return nil, fmt.Errorf("invalid success_xpath: %w", err)
+	}
+	return expr, nil
+}
+
+// contentType returns the Content-Type a target's envelope is sent with by default
+func (t *SOAPTarget) contentType() string {
+	if t.Version == SOAPVersion12 {
+		if t.Action != "" {
+			return fmt.Sprintf(`application/soap+xml; charset=utf-8; action="%s"`, t.Action)
+		}
+		return "application/soap+xml; charset=utf-8"
+	}
+	return "text/xml; charset=utf-8"
+}
+
+// validateSOAPBody checks that a soap job's body is a well-formed XML envelope
+func validateSOAPBody(job *models.Job) error {
+	if len(job.Payload) > 0 {
+		return fmt.Errorf("soap targets take the envelope in body, not a payload")
+	}
+	if job.Body == "" {
+		return fmt.Errorf("soap targets need the envelope in body")
+	}
+	if job.BodyEncoding == models.BodyEncodingBase64 {
+		return fmt.Errorf("soap envelopes are sent as text")
+	}
+	if job.ContentType != "" {
+		if _, _, err := mime.ParseMediaType(job.ContentType); err != nil {
+			return fmt.Errorf("invalid content_type: %w", err)
+		}
+	}
+	// Secret references are plain text inside elements, so the envelope parses before resolving
+	if _, err := xmlquery.Parse(strings.NewReader(job.Body)); err != nil {
+		return fmt.Errorf("body is not well-formed XML: %w", err)
+	}
+	return nil
+}
+
+// executeSOAP posts a job's envelope to its endpoint. A response holding a SOAP fault fails
+// the run, and so does one that doesn't match the job's success_xpath, whatever the status.
+func (e *Executor) executeSOAP(ctx context.Context, job *models.Job) (*ExecutionResult, error) {
+	target, err := ParseSOAPTarget(job)
+	if err != nil {
+		return &ExecutionResult{Error: err.Error()}, err
+	}
+
+	action := target.Action
+	if action == "" {
+		action = "request"
+	}
+	ctx, span := tracing.Tracer().Start(ctx, "soap "+action,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("soap.action", target.Action),
+			attribute.String("soap.version", target.Version),
+		),
+	)
+	defer span.End()
+
+	// Send the envelope through the HTTP path so headers, secrets and limits apply as usual
+	request := *job
+	request.Method = http.MethodPost
+	request.BodyEncoding = ""
+	if request.ContentType == "" {
+		request.ContentType = target.contentType()
+	}
+	if target.Version == SOAPVersion11 {
+		headers := map[string]string{}
+		if len(job.Headers) > 0 {
+			_ = json.Unmarshal(job.Headers, &headers)
+		}
+		if !hasHeader(headers, "SOAPAction") {
+			headers["SOAPAction"] = `"` + target.Action + `"`
+		}
+		request.Headers, _ = json.Marshal(headers)
+	}
+
+	result, err := e.executeHTTP(ctx, &request)
+	if soapErr := checkSOAPResponse(target, result.Body, err == nil); soapErr != "" {
+		if err != nil {
+			result.Error = err.Error() + ": " + soapErr
+		} else {
+			result.Error = soapErr
+		}
+		err = fmt.Errorf("%s", result.Error)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, result.Error)
+	}
+	return result, err
+}
+
+// checkSOAPResponse describes why a SOAP response failed, or returns "" when it didn't.
+// Faults are reported whatever the status; the success assertion only applies to responses
+// that otherwise succeeded.
+func checkSOAPResponse(target *SOAPTarget, body []byte, succeeded bool) string {
+	doc, err := xmlquery.Parse(bytes.NewReader(body))
+	if err != nil {
+		if succeeded && target.SuccessXPath != "" {
+			return "SOAP response is not XML"
+		}
+		return ""
+	}
+
+	if fault := xmlquery.FindOne(doc, "//*[local-name()='Envelope']/*[local-name()='Body']/*[local-name()='Fault']"); fault != nil {
+		return "SOAP fault: " + soapFaultMessage(fault)
+	}
+
+	if !succeeded || target.SuccessXPath == "" {
+		return ""
+	}
+	expr, err := target.successExpr()
+	if err != nil {
+		return err.Error()
+	}
+	if !xpathTruthy(expr.Evaluate(xmlquery.CreateXPathNavigator(doc))) {
+		return fmt.Sprintf("SOAP response did not match success_xpath %s", target.SuccessXPath)
+	}
+	return ""
+}
+
+// soapFaultMessage reads the reason of a SOAP 1.1 or 1.2 fault
+func soapFaultMessage(fault *xmlquery.Node) string {
+	// SOAP 1.1 faults have a faultstring, SOAP 1.2 faults a Reason with Text per language
+	for _, path := range []string{
+		"*[local-name()='faultstring']",
+		"*[local-name()='Reason']/*[local-name()='Text']",
+	} {
+		if node := xmlquery.FindOne(fault, path); node != nil {
+			if text := strings.TrimSpace(node.InnerText()); text != "" {
+				return text
+			}
+		}
+	}
+	return strings.Join(strings.Fields(fault.InnerText()), " ")
+}
+
+// xpathTruthy converts an XPath result to a boolean the way the boolean() function does
+func xpathTruthy(result interface{}) bool {
+	switch value := result.(type) {
+	case bool:
+		return value
+	case float64:
+		return value != 0 && !math.IsNaN(value)
+	case string:
+		return value != ""
+	case *xpath.NodeIterator:
+		return value.MoveNext()
+	}
+	return false
+}
+
+// hasHeader reports whether a header is set, ignoring case
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // Registers the "pgx" database/sql driver
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SQLTarget is the target_config of sql jobs. Fields missing from target_config are read
+// from the job's metadata under the same names.
+type SQLTarget struct {
+	Datasource string `json:"datasource"` // Name of an entry in SQL_DATASOURCES that serves the job's tenant
+	Statement  string `json:"statement"`  // A single statement with $1, $2, ... placeholders
+}
+
+// SQLPayload is the payload of sql jobs
+type SQLPayload struct {
+	Params []interface{} `json:"params,omitempty"` // Values bound to the statement's placeholders, in order
+}
+
+// ParseSQLTarget reads a sql job's target from its target_config and metadata
+func ParseSQLTarget(job *models.Job) (*SQLTarget, error) {
+	var target, fallback SQLTarget
+	if len(job.TargetConfig) > 0 {
+		if err := json.Unmarshal(job.TargetConfig, &target); err != nil {
+			return nil, fmt.Errorf("invalid sql target_config: %w", err)
+		}
+	}
+	if len(job.Metadata) > 0 {
+		// Metadata is free-form, so fields of the wrong type are ignored
+		_ = json.Unmarshal(job.Metadata, &fallback)
+	}
+
+	if target.Datasource == "" {
+		target.Datasource = fallback.Datasource
+	}
+	if target.Statement == "" {
+		target.Statement = fallback.Statement
+	}
+
+	if target.Datasource == "" || strings.TrimSpace(target.Statement) == "" {
+		return nil, fmt.Errorf("sql targets need a datasource and statement in target_config or metadata")
+	}
+	if job.Body != "" {
+		return nil, fmt.Errorf("sql targets take params in payload, not a raw body")
+	}
+	if _, err := parseSQLPayload(job.Payload); err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// parseSQLPayload reads a sql job's params. Whole numbers are bound as integers, and
+// objects and arrays as JSON text.
+func parseSQLPayload(payload json.RawMessage) ([]interface{}, error) {
+	if len(payload) == 0 {
+		return nil, nil
+	}
+
+	var parsed SQLPayload
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("sql payload must be {\"params\": [...]}: %w", err)
+	}
+
+	params := make([]interface{}, len(parsed.Params))
+	for i, param := range parsed.Params {
+		switch value := param.(type) {
+		case json.Number:
+			if n, err := value.Int64(); err == nil {
+				params[i] = n
+			} else if f, err := value.Float64(); err == nil && !math.IsInf(f, 0) {
+				params[i] = f
+			} else {
+				return nil, fmt.Errorf("sql param %d: invalid number %s", i+1, value)
+			}
+		case map[string]interface{}, []interface{}:
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("sql param %d: %w", i+1, err)
+			}
+			params[i] = string(encoded)
+		default:
+			params[i] = value
+		}
+	}
+	return params, nil
+}
+
+// sqlDatasources holds a connection pool per configured datasource
+type sqlDatasources struct {
+	config config.SQLConfig
+	dsns   map[string]string
+	pools  map[string]*sql.DB
+	mu     sync.Mutex
+}
+
+// newSQLDatasources creates the datasources; pools are opened on first use
+func newSQLDatasources(cfg config.SQLConfig) *sqlDatasources {
+	dsns := make(map[string]string)
+	for _, entry := range strings.Split(cfg.Datasources, ",") {
+		if name, dsn, ok := strings.Cut(strings.TrimSpace(entry), "="); ok {
+			dsns[strings.TrimSpace(name)] = strings.TrimSpace(dsn)
+		}
+	}
+	return &sqlDatasources{
+		config: cfg,
+		dsns:   dsns,
+		pools:  make(map[string]*sql.DB),
+	}
+}
+
+// pool returns the connection pool of a datasource, opening it if needed
+func (d *sqlDatasources) pool(name string) (*sql.DB, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if db, ok := d.pools[name]; ok {
+		return db, nil
+	}
+	dsn, ok := d.dsns[name]
+	if !ok {
+		return nil, fmt.Errorf("sql datasource %q is not in SQL_DATASOURCES", name)
+	}
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sql datasource %s: %w", name, err)
+	}
+	db.SetMaxOpenConns(d.config.MaxOpenConns)
+	db.SetMaxIdleConns(d.config.MaxOpenConns)
+	db.SetConnMaxIdleTime(5 * time.Minute)
+	d.pools[name] = db
+	return db, nil
+}
+
+// Close closes all connection pools
+func (d *sqlDatasources) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var firstErr error
+	for name, db := range d.pools {
+		if err := db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(d.pools, name)
+	}
+	return firstErr
+}
+
+// executeSQL runs a job's statement against its datasource with the payload's params and
+// records the rows affected. The job's timeout cancels the statement.
+func (e *Executor) executeSQL(ctx context.Context, job *models.Job) (*ExecutionResult, error) {
+	startTime := time.Now()
+	result := &ExecutionResult{}
+
+	fail := func(span trace.Span, err error) (*ExecutionResult, error) {
+		result.Error = err.Error()
+		result.Duration = time.Since(startTime).Milliseconds()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, result.Error)
+		return result, err
+	}
+
+	target, err := ParseSQLTarget(job)
+	if err != nil {
+		return fail(trace.SpanFromContext(ctx), err)
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, "sql "+target.Datasource,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.namespace", target.Datasource),
+			attribute.String("db.query.text", target.Statement),
+		),
+	)
+	defer span.End()
+
+	job, err = e.resolveSecrets(ctx, job)
+	if err != nil {
+		return fail(span, err)
+	}
+	params, err := parseSQLPayload(job.Payload)
+	if err != nil {
+		return fail(span, err)
+	}
+
+	if err := e.checkTargetAccess(ctx, job); err != nil {
+		return fail(span, err)
+	}
+	db, err := e.sql.pool(target.Datasource)
+	if err != nil {
+		return fail(span, err)
+	}
+	res, err := db.ExecContext(ctx, target.Statement, params...)
+	if err != nil {
+		return fail(span, fmt.Errorf("sql statement on %s failed: %w", target.Datasource, err))
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fail(span, fmt.Errorf("failed to read rows affected on %s: %w", target.Datasource, err))
+	}
+
+	span.SetAttributes(attribute.Int64("db.response.rows_affected", rows))
+	result.Body, _ = json.Marshal(map[string]interface{}{
+		"datasource":    target.Datasource,
+		"rows_affected": rows,
+	})
+	result.Duration = time.Since(startTime).Milliseconds()
+	return result, nil
+}
@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckTargetAccessSQL(t *testing.T) {
+	billing := uuid.MustParse("3f2b8c1e-5d4a-4e6f-9a7b-1c2d3e4f5a6b")
+	reporting := uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+	cfg := config.SQLConfig{
+		Datasources: "app=postgres://app@app-db:5432/app, warehouse=postgres://wh@wh-db:5432/wh, orphan=postgres://o@o-db:5432/o",
+		Tenants:     "app=" + billing.String() + ", warehouse=" + billing.String() + ",warehouse=" + reporting.String() + ",unknown=" + reporting.String(),
+	}
+	job := func(tenantID uuid.UUID, datasource string) *models.Job {
+		return &models.Job{
+			TenantID:     tenantID,
+			TargetType:   models.TargetSQL,
+			TargetConfig: json.RawMessage(`{"datasource": "` + datasource + `", "statement": "SELECT 1"}`),
+		}
+	}
+
+	tests := []struct {
+		name       string
+		tenantID   uuid.UUID
+		datasource string
+		allowed    bool
+	}{
+		{name: "paired", tenantID: billing, datasource: "app", allowed: true},
+		{name: "shared datasource", tenantID: reporting, datasource: "warehouse", allowed: true},
+		{name: "other tenant's datasource", tenantID: reporting, datasource: "app"},
+		{name: "datasource without tenants", tenantID: billing, datasource: "orphan"},
+		{name: "pairing with an unknown datasource", tenantID: reporting, datasource: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTargetAccess(job(tt.tenantID, tt.datasource), models.TenantLimits{}, cfg)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrTargetNotGranted)
+			}
+		})
+	}
+}
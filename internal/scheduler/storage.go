@@ -0,0 +1,140 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// ErrStorageNotAllowed is returned when a job reads or writes an object outside the buckets
+// and prefixes its tenant was granted
+var ErrStorageNotAllowed = errors.New("object location is not allowed for this tenant")
+
+// bucketPattern is the syntax of S3 bucket names
+var bucketPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// StorageRule grants a tenant's jobs a bucket, or the keys under a prefix in it
+type StorageRule struct {
+	Bucket string
+	Prefix string // Empty grants the whole bucket
+}
+
+// ParseStorageRule reads a rule written as bucket or bucket/prefix
+func ParseStorageRule(entry string) (StorageRule, error) {
+	bucket, prefix, _ := strings.Cut(strings.TrimSpace(entry), "/")
+	if !bucketPattern.MatchString(bucket) {
+		return StorageRule{}, fmt.Errorf("invalid storage rule %q: use bucket or bucket/prefix", entry)
+	}
+	if err := checkObjectKey(prefix); err != nil {
+		return StorageRule{}, fmt.Errorf("invalid storage rule %q: %v", entry, err)
+	}
+	return StorageRule{Bucket: bucket, Prefix: prefix}, nil
+}
+
+// ParseStorageRules reads a list of rules, skipping blank entries
+func ParseStorageRules(entries []string) ([]StorageRule, error) {
+	var rules []StorageRule
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		rule, err := ParseStorageRule(entry)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// checkObjectKey rejects keys with . or .. segments, which some stores resolve, so a key
+// can't climb out of the prefix it was checked against
+func checkObjectKey(key string) error {
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return fmt.Errorf("object keys can't contain . or .. segments")
+		}
+	}
+	return nil
+}
+
+// checkStorage checks an object key, or the prefix of the keys a job writes, against a
+// tenant's storage rules. Without rules the tenant can't use object storage.
+func checkStorage(rules []StorageRule, bucket, key string) error {
+	key = strings.TrimPrefix(key, "/")
+	if err := checkObjectKey(key); err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if rule.Bucket == bucket && strings.HasPrefix(key, rule.Prefix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: s3://%s/%s", ErrStorageNotAllowed, bucket, key)
+}
+
+// objectLocation is an object, or the bucket and prefix a job writes objects under
+type objectLocation struct {
+	bucket string
+	key    string
+}
+
+// storageLocations returns the object locations a job's definition reads or writes
+func storageLocations(job *models.Job) []objectLocation {
+	var locations []objectLocation
+	if sink, err := ParseResponseSink(job); err == nil && sink != nil {
+		locations = append(locations, objectLocation{bucket: sink.Bucket, key: sink.Prefix})
+	}
+	if job.TargetType == models.TargetReport {
+		if target, err := ParseReportTarget(job); err == nil && target.Deliver.S3 != nil {
+			locations = append(locations, objectLocation{bucket: target.Deliver.S3.Bucket, key: target.Deliver.S3.Prefix})
+		}
+	}
+	if isMultipart(job) {
+		if parts, err := ParseMultipart(job.Payload); err == nil {
+			for _, part := range parts {
+				if part.File != nil {
+					locations = append(locations, objectLocation{bucket: part.File.bucket, key: part.File.key})
+				}
+			}
+		}
+	}
+	return locations
+}
+
+// CheckStorage checks the object locations a job's definition reads or writes against the
+// storage rules its tenant was granted. The objects are checked again when they are used.
+func (s *Scheduler) CheckStorage(ctx context.Context, job *models.Job) error {
+	rules, err := tenantStorage(ctx, s.tenants, job.TenantID)
+	if err != nil {
+		return err
+	}
+	for _, location := range storageLocations(job) {
+		if err := checkStorage(rules, location.bucket, location.key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStorage checks an object location against the storage rules of a job's tenant
+func (e *Executor) checkStorage(ctx context.Context, tenantID uuid.UUID, bucket, key string) error {
+	rules, err := tenantStorage(ctx, e.tenants, tenantID)
+	if err != nil {
+		return err
+	}
+	return checkStorage(rules, bucket, key)
+}
+
+// tenantStorage returns the storage rules a tenant was granted
+func tenantStorage(ctx context.Context, tenants TenantLimits, tenantID uuid.UUID) ([]StorageRule, error) {
+	if tenants == nil {
+		return nil, nil
+	}
+	return ParseStorageRules(tenants.Limits(ctx, tenantID).StorageAllow)
+}
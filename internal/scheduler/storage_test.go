@@ -0,0 +1,140 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedLimits gives every tenant the same limits
+type fixedLimits models.TenantLimits
+
+func (f fixedLimits) Limits(context.Context, uuid.UUID) models.TenantLimits {
+	return models.TenantLimits(f)
+}
+
+func TestParseStorageRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   string
+		want    StorageRule
+		wantErr bool
+	}{
+		{name: "bucket", entry: "exports", want: StorageRule{Bucket: "exports"}},
+		{name: "bucket and prefix", entry: " exports/team-a/ ", want: StorageRule{Bucket: "exports", Prefix: "team-a/"}},
+		{name: "uppercase bucket", entry: "Exports", wantErr: true},
+		{name: "short bucket", entry: "ab", wantErr: true},
+		{name: "no bucket", entry: "/team-a/", wantErr: true},
+		{name: "dot dot in prefix", entry: "exports/team-a/../team-b/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseStorageRule(tt.entry)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rule)
+		})
+	}
+}
+
+func TestCheckStorage(t *testing.T) {
+	rules, err := ParseStorageRules([]string{"exports/team-a/", "", "scratch"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		bucket  string
+		key     string
+		allowed bool
+	}{
+		{name: "under granted prefix", bucket: "exports", key: "team-a/nightly/report.csv", allowed: true},
+		{name: "leading slash", bucket: "exports", key: "/team-a/report.csv", allowed: true},
+		{name: "whole bucket", bucket: "scratch", key: "anything", allowed: true},
+		{name: "other prefix", bucket: "exports", key: "team-b/report.csv"},
+		{name: "bucket root", bucket: "exports", key: ""},
+		{name: "other bucket", bucket: "billing", key: "team-a/report.csv"},
+		{name: "climbs out of prefix", bucket: "exports", key: "team-a/../team-b/report.csv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkStorage(rules, tt.bucket, tt.key)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	assert.ErrorIs(t, checkStorage(nil, "exports", "team-a/report.csv"), ErrStorageNotAllowed)
+}
+
+func TestSchedulerCheckStorage(t *testing.T) {
+	sink := json.RawMessage(`{"bucket": "exports", "prefix": "team-a/nightly/"}`)
+
+	granted := &Scheduler{tenants: fixedLimits{StorageAllow: []string{"exports/team-a/"}}}
+	assert.NoError(t, granted.CheckStorage(context.Background(), &models.Job{ResponseSink: sink}))
+	assert.NoError(t, granted.CheckStorage(context.Background(), &models.Job{}))
+
+	other := &Scheduler{tenants: fixedLimits{StorageAllow: []string{"exports/team-b/"}}}
+	assert.ErrorIs(t, other.CheckStorage(context.Background(), &models.Job{ResponseSink: sink}), ErrStorageNotAllowed)
+
+	ungranted := &Scheduler{tenants: fixedLimits{}}
+	assert.ErrorIs(t, ungranted.CheckStorage(context.Background(), &models.Job{ResponseSink: sink}), ErrStorageNotAllowed)
+}
+
+func TestSchedulerCheckStorageMultipart(t *testing.T) {
+	s := &Scheduler{tenants: fixedLimits{StorageAllow: []string{"uploads/team-a/"}}}
+
+	tests := []struct {
+		name        string
+		contentType string
+		payload     string
+		allowed     bool
+	}{
+		{name: "granted file", contentType: "multipart/form-data", payload: `{"title": "q3", "file": {"url": "s3://uploads/team-a/q3.pdf"}}`, allowed: true},
+		{name: "fields only", contentType: "multipart/form-data", payload: `{"title": "q3"}`, allowed: true},
+		{name: "other tenant's file", contentType: "multipart/form-data", payload: `{"file": {"url": "s3://uploads/team-b/q3.pdf"}}`},
+		{name: "one of several files", contentType: "multipart/form-data", payload: `{"files": [{"url": "s3://uploads/team-a/a.pdf"}, {"url": "s3://billing/b.pdf"}]}`},
+		{name: "json payload", contentType: "application/json", payload: `{"file": {"url": "s3://billing/b.pdf"}}`, allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.CheckStorage(context.Background(), &models.Job{ContentType: tt.contentType, Payload: json.RawMessage(tt.payload)})
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrStorageNotAllowed)
+			}
+		})
+	}
+}
+
+func TestSchedulerCheckStorageReport(t *testing.T) {
+	s := &Scheduler{tenants: fixedLimits{StorageAllow: []string{"reports/team-a/"}}}
+	report := func(bucket, prefix string) *models.Job {
+		return &models.Job{
+			TargetType: models.TargetReport,
+			TargetConfig: json.RawMessage(`{"data_url": "https://api.example.com/sales", "format": "csv", "columns": ["id"],
+				"deliver": {"s3": {"bucket": "` + bucket + `", "prefix": "` + prefix + `"}}}`),
+		}
+	}
+
+	_, err := ParseReportTarget(report("reports", "team-a/sales/"))
+	require.NoError(t, err)
+
+	assert.NoError(t, s.CheckStorage(context.Background(), report("reports", "team-a/sales/")))
+	assert.ErrorIs(t, s.CheckStorage(context.Background(), report("reports", "team-b/")), ErrStorageNotAllowed)
+	assert.ErrorIs(t, s.CheckStorage(context.Background(), report("billing", "team-a/")), ErrStorageNotAllowed)
+}
@@ -0,0 +1,151 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/egress"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// ValidateTarget checks that a job names a target it can be delivered to
+func ValidateTarget(job *models.Job) error {
+	switch job.TargetType {
+	case "", models.TargetHTTP:
+		if job.Endpoint == "" {
+			return fmt.Errorf("endpoint is required for http targets")
+		}
+		if parsed, err := url.Parse(job.Endpoint); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return fmt.Errorf("endpoint must be an absolute URL")
+		}
+		return nil
+	case models.TargetKafka:
+		_, err := ParseKafkaTarget(job)
+		return err
+	case models.TargetNATS:
+		_, err := ParseNATSTarget(job)
+		return err
+	case models.TargetAMQP:
+		_, err := ParseAMQPTarget(job)
+		return err
+	case models.TargetCommand:
+		_, err := ParseCommandTarget(job)
+		return err
+	case models.TargetSQL:
+		_, err := ParseSQLTarget(job)
+		return err
+	case models.TargetEmail:
+		_, err := ParseEmailTarget(job)
+		return err
+	case models.TargetGraphQL:
+		_, err := ParseGraphQLTarget(job)
+		return err
+	case models.TargetSOAP:
+		_, err := ParseSOAPTarget(job)
+		return err
+	case models.TargetReport:
+		_, err := ParseReportTarget(job)
+		return err
+	}
+	if executor := registeredExecutor(job.TargetType); executor != nil {
+		return executor.Validate(job)
+	}
+	return fmt.Errorf("invalid target_type: %s", job.TargetType)
+}
+
+// targetHost names where a job is delivered, for grouping incidents by host
+func targetHost(job *models.Job) string {
+	switch job.TargetType {
+	case models.TargetKafka:
+		if target, err := ParseKafkaTarget(job); err == nil {
+			return "kafka:" + target.Topic
+		}
+		return "kafka"
+	case models.TargetNATS:
+		if target, err := ParseNATSTarget(job); err == nil {
+			return "nats:" + target.Subject
+		}
+		return "nats"
+	case models.TargetAMQP:
+		if target, err := ParseAMQPTarget(job); err == nil {
+			return "amqp:" + target.destination()
+		}
+		return "amqp"
+	case models.TargetCommand:
+		if target, err := ParseCommandTarget(job); err == nil {
+			return "command:" + target.Command
+		}
+		return "command"
+	case models.TargetSQL:
+		if target, err := ParseSQLTarget(job); err == nil {
+			return "sql:" + target.Datasource
+		}
+		return "sql"
+	case models.TargetEmail:
+		return "email"
+	case models.TargetReport:
+		if target, err := ParseReportTarget(job); err == nil && target.DataURL != "" {
+			if parsed, err := url.Parse(target.DataURL); err == nil {
+				return parsed.Host
+			}
+		}
+		return "report"
+	}
+	if executor := registeredExecutor(job.TargetType); executor != nil {
+		if hoster, ok := executor.(TargetHoster); ok {
+			return hoster.Host(job)
+		}
+		return string(job.TargetType)
+	}
+
+	if parsed, err := url.Parse(job.Endpoint); err == nil && parsed.Host != "" {
+		return parsed.Host
+	}
+	return job.Endpoint
+}
+
+// CheckReachable reports whether a TCP connection can be opened to an endpoint URL's host,
+// without sending it a request, so checking has no effect on the target. Hosts the egress
+// policy on ctx denies aren't dialed, and failures don't say why, so checks can't be used to
+// map what the service can reach.
+func CheckReachable(ctx context.Context, endpoint string, timeout time.Duration) error {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("endpoint must be an absolute URL")
+	}
+	port := parsed.Port()
+	if port == "" {
+		port = "80"
+		if parsed.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	if err := egress.PolicyFrom(ctx).CheckResolved(ctx, parsed.Hostname()); err != nil {
+		return err
+	}
+
+	conn, err := egress.DialContext(ctx, &net.Dialer{Timeout: timeout}, "tcp", net.JoinHostPort(parsed.Hostname(), port))
+	if err != nil {
+		return fmt.Errorf("endpoint is not reachable")
+	}
+	return conn.Close()
+}
+
+// CheckEndpoint checks that a tenant's job could connect to an endpoint, like CheckReachable,
+// within the tenant's egress policy. Each check counts as an execution against the tenant's
+// rate limit, and none is made while the tenant's window is full.
+func (s *Scheduler) CheckEndpoint(ctx context.Context, tenantID uuid.UUID, endpoint string, timeout time.Duration) error {
+	ctx, err := s.WithEgress(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if !s.admit(ctx, tenantID) {
+		return fmt.Errorf("endpoint checks are rate limited, try again in a minute")
+	}
+	return CheckReachable(ctx, endpoint, timeout)
+}
@@ -0,0 +1,218 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/minisource/scheduler/internal/egress"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/secrets"
+)
+
+// ErrInsecureTLS is returned when a job skips certificate verification but its tenant isn't
+// allowed to
+var ErrInsecureTLS = errors.New("tls insecure_skip_verify is not allowed for this tenant")
+
+// transportIdleTTL is how long a job's TLS transport is kept after its last request
+const transportIdleTTL = 10 * time.Minute
+
+// JobTLS is a job's TLS settings for the HTTP requests it makes. The client certificate, key
+// and CA bundle are read from the tenant's secrets, so key material never appears in job
+// definitions.
+type JobTLS struct {
+	ClientCertSecret   string `json:"client_cert_secret,omitempty"`   // Secret holding the PEM client certificate, followed by any intermediates
+	ClientKeySecret    string `json:"client_key_secret,omitempty"`    // Secret holding the PEM private key of the certificate
+	CASecret           string `json:"ca_secret,omitempty"`            // Secret holding the PEM CA certificates trusted instead of the system roots
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"` // Accept any server certificate; tenants need allow_insecure_tls
+}
+
+// secretNames returns the names of the secrets the settings read
+func (t *JobTLS) secretNames() []string {
+	var names []string
+	for _, name := range []string{t.ClientCertSecret, t.ClientKeySecret, t.CASecret} {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ParseJobTLS reads a job's TLS settings; it returns nil when the job has none
+func ParseJobTLS(job *models.Job) (*JobTLS, error) {
+	if len(job.TLS) == 0 || string(job.TLS) == "null" || string(job.TLS) == "{}" {
+		return nil, nil
+	}
+
+	var settings JobTLS
+	if err := json.Unmarshal(job.TLS, &settings); err != nil {
+		return nil, fmt.Errorf("invalid tls: %w", err)
+	}
+	return &settings, nil
+}
+
+// ValidateJobTLS checks a job's TLS settings against the rest of its definition
+func ValidateJobTLS(job *models.Job) error {
+	settings, err := ParseJobTLS(job)
+	if err != nil || settings == nil {
+		return err
+	}
+	switch job.TargetType {
+	case "", models.TargetHTTP, models.TargetGraphQL, models.TargetSOAP, models.TargetReport:
+	default:
+		return fmt.Errorf("tls is only supported for http, graphql, soap and report targets")
+	}
+	if (settings.ClientCertSecret == "") != (settings.ClientKeySecret == "") {
+		return fmt.Errorf("tls needs both client_cert_secret and client_key_secret")
+	}
+	if settings.CASecret != "" && settings.InsecureSkipVerify {
+		return fmt.Errorf("tls can't combine ca_secret with insecure_skip_verify")
+	}
+	for _, name := range settings.secretNames() {
+		if !secrets.NamePattern.MatchString(name) {
+			return fmt.Errorf("invalid secret name %q in tls", name)
+		}
+	}
+	return nil
+}
+
+// clientFor returns the HTTP client for a job's requests: the shared client, or one set up with
+// the job's TLS settings. Certificates are read from secrets on every run, so a rotated
+// certificate is used from the next run on. Skipping verification is refused at run time too
+// once the tenant is no longer allowed to.
+func (e *Executor) clientFor(ctx context.Context, job *models.Job) (*http.Client, error) {
+	settings, err := ParseJobTLS(job)
+	if err != nil || settings == nil || (len(settings.secretNames()) == 0 && !settings.InsecureSkipVerify) {
+		return e.client, err
+	}
+	if settings.InsecureSkipVerify && (e.tenants == nil || !e.tenants.Limits(ctx, job.TenantID).AllowInsecureTLS) {
+		return nil, ErrInsecureTLS
+	}
+
+	var values map[string]string
+	if names := settings.secretNames(); len(names) > 0 {
+		if e.secrets == nil {
+			return nil, secrets.ErrDisabled
+		}
+		if values, err = e.secrets.ResolveSecrets(ctx, job.TenantID, names); err != nil {
+			return nil, err
+		}
+	}
+	certPEM, keyPEM, caPEM := values[settings.ClientCertSecret], values[settings.ClientKeySecret], values[settings.CASecret]
+
+	key := transportKey(certPEM, keyPEM, caPEM, strconv.FormatBool(settings.InsecureSkipVerify))
+	transport, err := e.transports.get(key, func() (*http.Transport, error) {
+		transport := e.cloneTransport()
+		config := transport.TLSClientConfig
+		if settings.ClientCertSecret != "" {
+			cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+			if err != nil {
+				return nil, fmt.Errorf("invalid client certificate in secrets %q and %q: %w", settings.ClientCertSecret, settings.ClientKeySecret, err)
+			}
+			config.Certificates = []tls.Certificate{cert}
+		}
+		if settings.CASecret != "" {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM([]byte(caPEM)) {
+				return nil, fmt.Errorf("secret %q holds no PEM CA certificates", settings.CASecret)
+			}
+			config.RootCAs = pool
+		}
+		config.InsecureSkipVerify = settings.InsecureSkipVerify
+		return transport, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	client := *e.client
+	client.Transport = meteredTransport{base: egress.Transport{Base: transport, Proxy: e.proxy}}
+	return &client, nil
+}
+
+// cloneTransport returns a copy of the executor's transport to configure for a job
+func (e *Executor) cloneTransport() *http.Transport {
+	base, ok := e.transport.(*http.Transport)
+	if !ok {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	transport := base.Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return transport
+}
+
+// transportKey identifies a transport by its TLS settings, so jobs sharing them share
+// connections and a rotated certificate gets a new transport
+func transportKey(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// transportCache keeps the TLS transports of jobs so their connections are reused between
+// runs. Transports unused for transportIdleTTL are closed.
+type transportCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedTransport
+}
+
+// cachedTransport is a transport and when it was last used
+type cachedTransport struct {
+	transport *http.Transport
+	usedAt    time.Time
+}
+
+// newTransportCache creates an empty transport cache
+func newTransportCache() *transportCache {
+	return &transportCache{entries: make(map[string]*cachedTransport)}
+}
+
+// get returns the transport cached under a key, building it if there is none
+func (c *transportCache) get(key string, build func() (*http.Transport, error)) (*http.Transport, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if k != key && now.Sub(entry.usedAt) > transportIdleTTL {
+			entry.transport.CloseIdleConnections()
+			delete(c.entries, k)
+		}
+	}
+
+	if entry, ok := c.entries[key]; ok {
+		entry.usedAt = now
+		return entry.transport, nil
+	}
+	transport, err := build()
+	if err != nil {
+		return nil, err
+	}
+	c.entries[key] = &cachedTransport{transport: transport, usedAt: now}
+	return transport, nil
+}
+
+// close closes the idle connections of every cached transport
+func (c *transportCache) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		entry.transport.CloseIdleConnections()
+		delete(c.entries, key)
+	}
+}
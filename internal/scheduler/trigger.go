@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// TriggerLimitError is returned when a job has used up its manual triggers for the current
+// one-minute window
+type TriggerLimitError struct {
+	Limit   int
+	RetryAt time.Time // When the next window starts
+}
+
+func (e *TriggerLimitError) Error() string {
+	return fmt.Sprintf("job can be triggered manually at most %d times per minute", e.Limit)
+}
+
+// triggerLimit returns the manual triggers per minute allowed for a job, 0 for unlimited
+func (s *Scheduler) triggerLimit(job *models.Job) int {
+	if job.TriggerLimit != nil {
+		return *job.TriggerLimit
+	}
+	return s.config.Limits.TriggersPerMinute
+}
+
+// triggerDedupe returns the window in which identical manual triggers of a job collapse
+func (s *Scheduler) triggerDedupe(job *models.Job) time.Duration {
+	seconds := s.config.Limits.TriggerDedupeSeconds
+	if job.TriggerDedupe != nil {
+		seconds = *job.TriggerDedupe
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// pendingTrigger returns the execution of an identical manual trigger within the job's dedupe
+// window that hasn't started yet, or nil. If Redis is unavailable nothing is deduplicated.
+func (s *Scheduler) pendingTrigger(ctx context.Context, job *models.Job, correlationID string) *models.JobExecution {
+	if s.rateLimiter == nil || s.triggerDedupe(job) <= 0 {
+		return nil
+	}
+	id, err := s.rateLimiter.RecentTrigger(ctx, job.ID, correlationID)
+	if err != nil || id == uuid.Nil {
+		return nil
+	}
+	execution, err := s.executionRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil
+	}
+	if execution.Status != models.ExecutionStatusPending && execution.Status != models.ExecutionStatusQueued {
+		return nil
+	}
+	return execution
+}
+
+// reserveTrigger takes one of the job's manual triggers for the current window. If Redis is
+// unavailable triggers are allowed rather than refused.
+func (s *Scheduler) reserveTrigger(ctx context.Context, job *models.Job) error {
+	limit := s.triggerLimit(job)
+	if s.rateLimiter == nil || limit <= 0 {
+		return nil
+	}
+	ok, resetsAt, err := s.rateLimiter.ReserveTrigger(ctx, job.ID, limit)
+	if err != nil || ok {
+		return nil
+	}
+	dispatchMetrics.Add("triggers_rate_limited", 1)
+	return &TriggerLimitError{Limit: limit, RetryAt: resetsAt}
+}
+
+// rememberTrigger starts the dedupe window of a manual trigger's execution
+func (s *Scheduler) rememberTrigger(ctx context.Context, job *models.Job, correlationID string, executionID uuid.UUID) {
+	window := s.triggerDedupe(job)
+	if s.rateLimiter == nil || window <= 0 {
+		return
+	}
+	if err := s.rateLimiter.RememberTrigger(ctx, job.ID, correlationID, executionID, window); err != nil {
+		log.Printf("scheduler: failed to remember trigger of job %s: %v", job.ID, err)
+	}
+}
@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// settingsSyncInterval is how often instances pick up runtime setting overrides
+const settingsSyncInterval = 5 * time.Second
+
+// tunables are the settings that can be changed at runtime through the configuration API.
+// They start out with the values from the environment.
+type tunables struct {
+	workerCount    atomic.Int64
+	tickIntervalMs atomic.Int64
+	cleanupDays    atomic.Int64
+}
+
+// setting returns the tunable a runtime setting controls
+func (t *tunables) setting(name string) *atomic.Int64 {
+	switch name {
+	case models.SettingWorkerCount:
+		return &t.workerCount
+	case models.SettingTickIntervalMs:
+		return &t.tickIntervalMs
+	case models.SettingCleanupDays:
+		return &t.cleanupDays
+	}
+	return nil
+}
+
+// Settings returns the runtime settings in effect on this instance
+func (s *Scheduler) Settings() map[string]int {
+	return map[string]int{
+		models.SettingWorkerCount:    int(s.tuning.workerCount.Load()),
+		models.SettingTickIntervalMs: int(s.tuning.tickIntervalMs.Load()),
+		models.SettingCleanupDays:    int(s.tuning.cleanupDays.Load()),
+	}
+}
+
+// ApplySettings puts runtime setting overrides into effect on this instance. A new worker
+// count resizes the worker pool; a new tick interval applies from the next tick.
+func (s *Scheduler) ApplySettings(settings []models.RuntimeSetting) {
+	for _, setting := range settings {
+		tunable := s.tuning.setting(setting.Name)
+		if tunable == nil {
+			continue
+		}
+		old := tunable.Swap(int64(setting.Value))
+		if old == int64(setting.Value) {
+			continue
+		}
+		log.Printf("scheduler: %s changed from %d to %d", setting.Name, old, setting.Value)
+
+		if setting.Name == models.SettingWorkerCount && s.workerPool != nil {
+			s.workerPool.Resize(setting.Value)
+		}
+	}
+}
+
+// settingsLoop keeps this instance's runtime settings and feature flags in line with the
+// stored overrides
+func (s *Scheduler) settingsLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(settingsSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.syncSettings(s.ctx)
+		}
+	}
+}
+
+// syncSettings loads and applies the stored runtime setting and feature flag overrides
+func (s *Scheduler) syncSettings(ctx context.Context) {
+	if err := s.flags.Sync(ctx); err != nil {
+		log.Printf("scheduler: %v", err)
+	}
+
+	settings, err := s.settingsRepo.FindAll(ctx)
+	if err != nil {
+		log.Printf("scheduler: failed to load runtime settings: %v", err)
+		return
+	}
+	s.ApplySettings(settings)
+}
+
+// tickInterval is how often the scheduling loop looks for due jobs
+func (s *Scheduler) tickInterval() time.Duration {
+	return time.Duration(s.tuning.tickIntervalMs.Load()) * time.Millisecond
+}
@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// byteMeter counts the bytes an attempt sends to and receives from its target
+type byteMeter struct {
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+// meterKey is the context key of an attempt's byte meter
+type meterKey struct{}
+
+// withMeter returns a context whose target traffic is counted by the returned meter
+func withMeter(ctx context.Context) (context.Context, *byteMeter) {
+	meter := &byteMeter{}
+	return context.WithValue(ctx, meterKey{}, meter), meter
+}
+
+// executionKey is the context key of the execution an attempt belongs to
+type executionKey struct{}
+
+// withExecution returns a context that names the execution its attempt belongs to
+func withExecution(ctx context.Context, executionID uuid.UUID) context.Context {
+	return context.WithValue(ctx, executionKey{}, executionID)
+}
+
+// executionFrom returns the execution of the attempt running under ctx, or uuid.Nil outside
+// of one
+func executionFrom(ctx context.Context) uuid.UUID {
+	executionID, _ := ctx.Value(executionKey{}).(uuid.UUID)
+	return executionID
+}
+
+// CountBytes adds traffic to the usage of the attempt running under ctx. HTTP requests made
+// with the Executor's client are counted already; executors registered with RegisterExecutor
+// call it for traffic of their own, such as a message published.
+func CountBytes(ctx context.Context, sent, received int64) {
+	if meter, ok := ctx.Value(meterKey{}).(*byteMeter); ok {
+		meter.sent.Add(sent)
+		meter.received.Add(received)
+	}
+}
+
+// meteredTransport counts the request and response bodies of requests made for an attempt
+type meteredTransport struct {
+	base http.RoundTripper
+}
+
+func (t meteredTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	meter, ok := req.Context().Value(meterKey{}).(*byteMeter)
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		counted := *req
+		counted.Body = &countingBody{ReadCloser: req.Body, count: &meter.sent}
+		req = &counted
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, count: &meter.received}
+	return resp, nil
+}
+
+// countingBody counts the bytes read from a body
+type countingBody struct {
+	io.ReadCloser
+	count *atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.count.Add(int64(n))
+	return n, err
+}
@@ -25,6 +25,7 @@ type WorkerPool struct {
 	ctx        context.Context
 	cancel     context.CancelFunc
 	running    bool
+	stops      []chan struct{} // One per running worker; closing it retires the worker
 	mu         sync.RWMutex
 }
 
@@ -51,11 +52,41 @@ func (p *WorkerPool) Start(ctx context.Context) {
 
 	p.ctx, p.cancel = context.WithCancel(ctx)
 	p.running = true
+	for i := 0; i < p.workers; i++ {
+		p.spawn()
+	}
 	p.mu.Unlock()
+}
 
-	for i := 0; i < p.workers; i++ {
-		p.wg.Add(1)
-		go p.worker(i)
+// spawn starts one more worker. Callers hold p.mu.
+func (p *WorkerPool) spawn() {
+	stop := make(chan struct{})
+	p.stops = append(p.stops, stop)
+	p.wg.Add(1)
+	go p.worker(len(p.stops)-1, stop)
+}
+
+// Resize changes the number of workers. Retired workers finish the task they are running.
+// The queue keeps the size it was created with.
+func (p *WorkerPool) Resize(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.workers = workers
+	if !p.running {
+		return
+	}
+	for len(p.stops) < workers {
+		p.spawn()
+	}
+	for len(p.stops) > workers {
+		last := len(p.stops) - 1
+		close(p.stops[last])
+		p.stops = p.stops[:last]
 	}
 }
 
@@ -67,6 +98,7 @@ func (p *WorkerPool) Stop() {
 		return
 	}
 	p.running = false
+	p.stops = nil
 	p.mu.Unlock()
 
 	if p.cancel != nil {
@@ -96,13 +128,15 @@ func (p *WorkerPool) Submit(task JobTask) bool {
 }
 
 // worker is the main worker loop
-func (p *WorkerPool) worker(id int) {
+func (p *WorkerPool) worker(id int, stop <-chan struct{}) {
 	defer p.wg.Done()
 
 	for {
 		select {
 		case <-p.ctx.Done():
 			return
+		case <-stop:
+			return
 		case task, ok := <-p.taskQueue:
 			if !ok {
 				return
@@ -117,8 +151,15 @@ func (p *WorkerPool) QueueSize() int {
 	return len(p.taskQueue)
 }
 
+// Available returns how many more tasks the queue can take
+func (p *WorkerPool) Available() int {
+	return cap(p.taskQueue) - len(p.taskQueue)
+}
+
 // WorkerCount returns the number of workers
 func (p *WorkerPool) WorkerCount() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	return p.workers
 }
 
@@ -0,0 +1,101 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/minisource/scheduler/config"
+)
+
+// ErrDisabled is returned when no encryption key is configured
+var ErrDisabled = errors.New("secrets are disabled: no encryption key configured")
+
+// Cipher encrypts secret values with AES-256-GCM
+type Cipher struct {
+	aead  cipher.AEAD
+	keyID string
+}
+
+// NewCipher creates a cipher from the configured key. It returns a nil cipher when no key
+// is configured, which disables secrets.
+func NewCipher(cfg config.SecretsConfig) (*Cipher, error) {
+	encoded := cfg.Key
+	if cfg.KeyFile != "" {
+		data, err := os.ReadFile(cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secrets key file: %w", err)
+		}
+		encoded = string(data)
+	}
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("secrets key must be base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("secrets key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Identify the key without revealing it, so values sealed with another key are detected
+	sum := sha256.Sum256(key)
+	return &Cipher{aead: aead, keyID: hex.EncodeToString(sum[:4])}, nil
+}
+
+// KeyID identifies the key values are sealed with
+func (c *Cipher) KeyID() string {
+	return c.keyID
+}
+
+// Encrypt seals a value, prefixing the ciphertext with a random nonce
+func (c *Cipher) Encrypt(plaintext string) ([]byte, error) {
+	if c == nil {
+		return nil, ErrDisabled
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return c.aead.Seal(nonce, nonce, []byte(plaintext), nil), nil
+}
+
+// Decrypt opens a value sealed by Encrypt with the key identified by keyID
+func (c *Cipher) Decrypt(ciphertext []byte, keyID string) (string, error) {
+	if c == nil {
+		return "", ErrDisabled
+	}
+	if keyID != c.keyID {
+		return "", fmt.Errorf("secret was encrypted with key %s, but key %s is configured", keyID, c.keyID)
+	}
+
+	size := c.aead.NonceSize()
+	if len(ciphertext) < size {
+		return "", fmt.Errorf("ciphertext too short")
+	}
+	plaintext, err := c.aead.Open(nil, ciphertext[:size], ciphertext[size:], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plaintext), nil
+}
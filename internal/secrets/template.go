@@ -0,0 +1,54 @@
+package secrets
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// NamePattern is the syntax of secret names
+var NamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,100}$`)
+
+// refPattern matches {{secret:NAME}} references, allowing spaces inside the braces
+var refPattern = regexp.MustCompile(`\{\{\s*secret:([A-Za-z0-9_.-]{1,100})\s*\}\}`)
+
+// Refs returns the distinct secret names referenced in the given texts
+func Refs(texts ...string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, text := range texts {
+		for _, match := range refPattern.FindAllStringSubmatch(text, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+	}
+	return names
+}
+
+// Expand replaces secret references with their values. References to names missing from
+// values are left as is.
+func Expand(text string, values map[string]string) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+	return refPattern.ReplaceAllStringFunc(text, func(ref string) string {
+		value, ok := values[refPattern.FindStringSubmatch(ref)[1]]
+		if !ok {
+			return ref
+		}
+		return value
+	})
+}
+
+// ExpandJSON replaces secret references inside JSON string literals, escaping the values
+// so the document stays valid
+func ExpandJSON(text string, values map[string]string) string {
+	escaped := make(map[string]string, len(values))
+	for name, value := range values {
+		quoted, _ := json.Marshal(value)
+		escaped[name] = string(quoted[1 : len(quoted)-1])
+	}
+	return Expand(text, escaped)
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// actorKey is the context key of the user a request acts for
+type actorKey struct{}
+
+// BindActor records the user a request acts for on its context, such as
+// *fasthttp.RequestCtx, whose Value method reads user values. Changes the request makes,
+// such as job revisions, are attributed to that user.
+func BindActor(c interface{ SetUserValue(key, value any) }, userID *uuid.UUID) {
+	if userID != nil {
+		c.SetUserValue(actorKey{}, *userID)
+	}
+}
+
+// actorFrom returns the user bound to ctx, or nil when the change isn't made for a user
+func actorFrom(ctx context.Context) *uuid.UUID {
+	if userID, ok := ctx.Value(actorKey{}).(uuid.UUID); ok {
+		return &userID
+	}
+	return nil
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/egress"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/repository"
+	"github.com/minisource/scheduler/internal/secrets"
+)
+
+var (
+	// ErrInvalidAdmissionWebhook is returned when an admission webhook fails validation
+	ErrInvalidAdmissionWebhook = invalid("invalid admission webhook")
+	// ErrAdmissionDenied is returned when an admission webhook rejects a job change
+	ErrAdmissionDenied = invalid("denied by admission webhook")
+	// ErrAdmissionUnavailable is returned when a job change can't be admitted because an
+	// admission webhook whose failure policy is fail didn't answer
+	ErrAdmissionUnavailable = errors.New("admission webhook unavailable")
+)
+
+// operatorWebhookName names the operator's admission webhook in errors
+const operatorWebhookName = "operator"
+
+// admissionResponseLimit bounds the admission webhook responses read
+const admissionResponseLimit = 1 << 20
+
+// AdmissionService manages tenants' admission webhooks and reviews job changes with them
+type AdmissionService struct {
+	config        config.AdmissionConfig
+	admissionRepo *repository.AdmissionRepository
+	secrets       *SecretService
+	client        *http.Client
+}
+
+// NewAdmissionService creates a new admission service. Tenants' webhooks are called within
+// the service-wide egress rules.
+func NewAdmissionService(cfg config.AdmissionConfig, egressCfg config.EgressConfig, admissionRepo *repository.AdmissionRepository, secretService *SecretService) *AdmissionService {
+	return &AdmissionService{
+		config:        cfg,
+		admissionRepo: admissionRepo,
+		secrets:       secretService,
+		client:        egress.NewClient(egressCfg, time.Duration(cfg.TimeoutSeconds)*time.Second),
+	}
+}
+
+// List lists a tenant's admission webhooks in the order they are called
+func (s *AdmissionService) List(ctx context.Context, tenantID uuid.UUID) ([]models.AdmissionWebhook, error) {
+	return s.admissionRepo.FindByTenant(ctx, tenantID)
+}
+
+// Get retrieves an admission webhook
+func (s *AdmissionService) Get(ctx context.Context, tenantID, id uuid.UUID) (*models.AdmissionWebhook, error) {
+	return s.admissionRepo.FindByTenantAndID(ctx, tenantID, id)
+}
+
+// Create creates an admission webhook
+func (s *AdmissionService) Create(ctx context.Context, tenantID uuid.UUID, req *models.CreateAdmissionWebhookRequest) (*models.AdmissionWebhook, error) {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	policy := req.FailurePolicy
+	if policy == "" {
+		policy = models.AdmissionFail
+	}
+
+	webhook := &models.AdmissionWebhook{
+		ID:            uuid.New(),
+		TenantID:      tenantID,
+		Name:          req.Name,
+		Position:      req.Position,
+		URL:           req.URL,
+		FailurePolicy: policy,
+		Enabled:       enabled,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if err := setWebhookHeaders(webhook, req.Headers); err != nil {
+		return nil, err
+	}
+	if err := validateAdmissionWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	if err := s.admissionRepo.Create(ctx, webhook); err != nil {
+		return nil, fmt.Errorf("failed to create admission webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// Update updates an admission webhook
+func (s *AdmissionService) Update(ctx context.Context, tenantID, id uuid.UUID, req *models.UpdateAdmissionWebhookRequest) (*models.AdmissionWebhook, error) {
+	webhook, err := s.admissionRepo.FindByTenantAndID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		webhook.Name = *req.Name
+	}
+	if req.Position != nil {
+		webhook.Position = *req.Position
+	}
+	if req.URL != nil {
+		webhook.URL = *req.URL
+	}
+	if req.FailurePolicy != nil {
+		webhook.FailurePolicy = *req.FailurePolicy
+	}
+	if req.Enabled != nil {
+		webhook.Enabled = *req.Enabled
+	}
+	if req.Headers != nil {
+		if err := setWebhookHeaders(webhook, *req.Headers); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateAdmissionWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	webhook.UpdatedAt = time.Now()
+	if err := s.admissionRepo.Update(ctx, webhook); err != nil {
+		return nil, fmt.Errorf("failed to update admission webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// Delete deletes an admission webhook
+func (s *AdmissionService) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	_, err := s.admissionRepo.Delete(ctx, tenantID, id)
+	return err
+}
+
+// Review sends a job create or update request to the operator's admission webhook, then to
+// the tenant's enabled webhooks in order. req, a pointer to the request, is replaced by the
+// request as patched by the webhooks; job is the job before an update. The first rejection
+// is returned as a ValidationError wrapping ErrAdmissionDenied, and a webhook that doesn't
+// answer fails the change with ErrAdmissionUnavailable unless its failure policy is ignore.
+func (s *AdmissionService) Review(ctx context.Context, tenantID uuid.UUID, operation models.AdmissionOperation, dryRun bool, req interface{}, job *models.Job) error {
+	var webhooks []models.AdmissionWebhook
+	if s.config.WebhookURL != "" {
+		webhooks = append(webhooks, models.AdmissionWebhook{
+			Name:          operatorWebhookName,
+			URL:           s.config.WebhookURL,
+			FailurePolicy: models.AdmissionFailurePolicy(s.config.FailurePolicy),
+		})
+	}
+	tenantWebhooks, err := s.admissionRepo.FindEnabled(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to load admission webhooks: %w", err)
+	}
+	webhooks = append(webhooks, tenantWebhooks...)
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	review := models.AdmissionReview{
+		UID:       uuid.New(),
+		Operation: operation,
+		DryRun:    dryRun,
+		TenantID:  tenantID,
+		UserID:    actorFrom(ctx),
+		Job:       job,
+	}
+	if job != nil {
+		review.JobID = &job.ID
+	}
+
+	for i := range webhooks {
+		webhook := &webhooks[i]
+		// Only the operator's webhook, which tenants can't see, gets the operator's token. Its
+		// URL comes from the configuration, so egress rules meant for tenants don't apply.
+		token := ""
+		callCtx := ctx
+		if webhook.ID == uuid.Nil {
+			token = s.config.WebhookToken
+			callCtx = egress.WithPolicy(ctx, &egress.Policy{})
+		}
+
+		review.Request = body
+		answer, err := s.call(callCtx, webhook, token, &review)
+		patched := body
+		if err == nil && answer.Allowed && len(answer.Patch) > 0 {
+			if patched, err = mergePatch(body, answer.Patch); err != nil {
+				err = fmt.Errorf("invalid patch: %w", err)
+			}
+		}
+		if err != nil {
+			if webhook.FailurePolicy == models.AdmissionIgnore {
+				continue
+			}
+			return fmt.Errorf("%w: %s: %v", ErrAdmissionUnavailable, webhook.Name, err)
+		}
+		if !answer.Allowed {
+			message := answer.Message
+			if message == "" {
+				message = "request not allowed"
+			}
+			return invalidField(answer.Field, fmt.Errorf("%w: %s: %s", ErrAdmissionDenied, webhook.Name, message))
+		}
+		body = patched
+	}
+
+	// Replace the request rather than decoding over it, so fields the patches removed are unset
+	target := reflect.ValueOf(req).Elem()
+	target.Set(reflect.Zero(target.Type()))
+	if err := json.Unmarshal(body, req); err != nil {
+		return invalidField("", fmt.Errorf("%w: request as patched is invalid: %v", ErrAdmissionDenied, err))
+	}
+	return nil
+}
+
+// call posts a review to a webhook and reads its answer; any status other than 2xx fails it.
+// A token is sent as a bearer token.
+func (s *AdmissionService) call(ctx context.Context, webhook *models.AdmissionWebhook, token string, review *models.AdmissionReview) (*models.AdmissionResponse, error) {
+	var headers map[string]string
+	if len(webhook.Headers) > 0 {
+		if err := json.Unmarshal(webhook.Headers, &headers); err != nil {
+			return nil, fmt.Errorf("invalid headers: %w", err)
+		}
+	}
+	headers, err := s.expandHeaders(ctx, review.TenantID, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(review)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Minisource-Scheduler/1.0")
+	req.Header.Set("X-Scheduler-Event", "admission")
+	req.Header.Set("X-Scheduler-Tenant-ID", review.TenantID.String())
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, admissionResponseLimit))
+		return nil, fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	var answer models.AdmissionResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, admissionResponseLimit)).Decode(&answer); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return &answer, nil
+}
+
+// expandHeaders resolves secret references in a tenant webhook's headers
+func (s *AdmissionService) expandHeaders(ctx context.Context, tenantID uuid.UUID, headers map[string]string) (map[string]string, error) {
+	var all []string
+	for _, value := range headers {
+		all = append(all, value)
+	}
+	names := secrets.Refs(all...)
+	if len(names) == 0 {
+		return headers, nil
+	}
+	if s.secrets == nil {
+		return nil, secrets.ErrDisabled
+	}
+
+	resolved, err := s.secrets.ResolveSecrets(ctx, tenantID, names)
+	if err != nil {
+		return nil, err
+	}
+	expanded := make(map[string]string, len(headers))
+	for key, value := range headers {
+		expanded[key] = secrets.Expand(value, resolved)
+	}
+	return expanded, nil
+}
+
+// mergePatch applies a JSON merge patch (RFC 7386) to a document
+func mergePatch(document, patch []byte) ([]byte, error) {
+	var target, changes interface{}
+	if err := json.Unmarshal(document, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeValue(target, changes))
+}
+
+// mergeValue merges a patch value into a target value: objects are merged member by member,
+// null members are removed and anything else replaces the target
+func mergeValue(target, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	merged, ok := target.(map[string]interface{})
+	if !ok {
+		merged = map[string]interface{}{}
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = mergeValue(merged[key], value)
+	}
+	return merged
+}
+
+// setWebhookHeaders stores a webhook's headers, leaving empty ones unset
+func setWebhookHeaders(webhook *models.AdmissionWebhook, headers map[string]string) error {
+	webhook.Headers = nil
+	for name, value := range headers {
+		if strings.TrimSpace(name) == "" || strings.ContainsAny(name+value, "\r\n") {
+			return fmt.Errorf("%w: invalid header %q", ErrInvalidAdmissionWebhook, name)
+		}
+	}
+	if len(headers) == 0 {
+		return nil
+	}
+	var err error
+	webhook.Headers, err = json.Marshal(headers)
+	return err
+}
+
+// validateAdmissionWebhook checks a webhook's name, URL and failure policy
+func validateAdmissionWebhook(webhook *models.AdmissionWebhook) error {
+	if strings.TrimSpace(webhook.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidAdmissionWebhook)
+	}
+	parsed, err := url.Parse(webhook.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidAdmissionWebhook)
+	}
+	switch webhook.FailurePolicy {
+	case models.AdmissionFail, models.AdmissionIgnore:
+	default:
+		return fmt.Errorf("%w: failure_policy must be fail or ignore", ErrInvalidAdmissionWebhook)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/repository"
+)
+
+// ErrInvalidAlertRule is returned when an alert rule fails validation
+var ErrInvalidAlertRule = errors.New("invalid alert rule")
+
+// Limits of alert rules
+const (
+	alertMaxThreshold     = 1000
+	alertMaxWindowMinutes = 30 * 24 * 60
+)
+
+// AlertService handles alert rule business logic
+type AlertService struct {
+	alertRepo        *repository.AlertRepository
+	notificationRepo *repository.NotificationRepository
+	jobRepo          *repository.JobRepository
+}
+
+// NewAlertService creates a new alert service
+func NewAlertService(
+	alertRepo *repository.AlertRepository,
+	notificationRepo *repository.NotificationRepository,
+	jobRepo *repository.JobRepository,
+) *AlertService {
+	return &AlertService{
+		alertRepo:        alertRepo,
+		notificationRepo: notificationRepo,
+		jobRepo:          jobRepo,
+	}
+}
+
+// ListRules lists a tenant's alert rules
+func (s *AlertService) ListRules(ctx context.Context, tenantID uuid.UUID) ([]models.AlertRule, error) {
+	return s.alertRepo.FindRulesByTenant(ctx, tenantID)
+}
+
+// GetRule retrieves an alert rule
+func (s *AlertService) GetRule(ctx context.Context, tenantID, id uuid.UUID) (*models.AlertRule, error) {
+	return s.alertRepo.FindRuleByTenantAndID(ctx, tenantID, id)
+}
+
+// ListFiring lists a tenant's firing alerts
+func (s *AlertService) ListFiring(ctx context.Context, tenantID uuid.UUID) ([]models.AlertFiring, error) {
+	return s.alertRepo.FindFiringsByTenant(ctx, tenantID)
+}
+
+// CreateRule creates an alert rule
+func (s *AlertService) CreateRule(ctx context.Context, tenantID uuid.UUID, req *models.CreateAlertRuleRequest) (*models.AlertRule, error) {
+	if req.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidAlertRule)
+	}
+	if (req.JobID == nil) == (len(req.Tags) == 0) {
+		return nil, fmt.Errorf("%w: exactly one of job_id or tags is required", ErrInvalidAlertRule)
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	rule := &models.AlertRule{
+		ID:            uuid.New(),
+		TenantID:      tenantID,
+		Name:          req.Name,
+		Type:          req.Type,
+		Threshold:     req.Threshold,
+		WindowMinutes: req.WindowMinutes,
+		Enabled:       enabled,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if req.JobID != nil {
+		if _, err := s.jobRepo.FindByTenantAndID(ctx, tenantID, *req.JobID); err != nil {
+			return nil, fmt.Errorf("%w: job not found", ErrInvalidAlertRule)
+		}
+		rule.JobID = req.JobID
+	} else {
+		tags, err := json.Marshal(req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		rule.Tags = tags
+	}
+
+	if err := validateAlertCondition(rule); err != nil {
+		return nil, err
+	}
+	channelIDs, err := s.checkChannels(ctx, tenantID, req.ChannelIDs)
+	if err != nil {
+		return nil, err
+	}
+	rule.ChannelIDs = channelIDs
+
+	if err := s.alertRepo.CreateRule(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to create alert rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// UpdateRule updates an alert rule. Disabling a rule clears its firings without announcing
+// them as resolved.
+func (s *AlertService) UpdateRule(ctx context.Context, tenantID, id uuid.UUID, req *models.UpdateAlertRuleRequest) (*models.AlertRule, error) {
+	rule, err := s.alertRepo.FindRuleByTenantAndID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil && *req.Name != "" {
+		rule.Name = *req.Name
+	}
+	if req.Threshold != nil {
+		rule.Threshold = *req.Threshold
+	}
+	if req.WindowMinutes != nil {
+		rule.WindowMinutes = *req.WindowMinutes
+	}
+	if req.ChannelIDs != nil {
+		channelIDs, err := s.checkChannels(ctx, tenantID, *req.ChannelIDs)
+		if err != nil {
+			return nil, err
+		}
+		rule.ChannelIDs = channelIDs
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if err := validateAlertCondition(rule); err != nil {
+		return nil, err
+	}
+
+	rule.UpdatedAt = time.Now()
+	if err := s.alertRepo.UpdateRule(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to update alert rule: %w", err)
+	}
+
+	if !rule.Enabled {
+		if err := s.alertRepo.ResolveRule(ctx, rule.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return rule, nil
+}
+
+// DeleteRule deletes an alert rule
+func (s *AlertService) DeleteRule(ctx context.Context, tenantID, id uuid.UUID) error {
+	_, err := s.alertRepo.DeleteRule(ctx, tenantID, id)
+	return err
+}
+
+// validateAlertCondition checks a rule's type and the setting that type uses
+func validateAlertCondition(rule *models.AlertRule) error {
+	switch rule.Type {
+	case models.AlertRuleConsecutiveFailures:
+		if rule.Threshold < 1 || rule.Threshold > alertMaxThreshold {
+			return fmt.Errorf("%w: threshold must be between 1 and %d", ErrInvalidAlertRule, alertMaxThreshold)
+		}
+		rule.WindowMinutes = 0
+	case models.AlertRuleNotRun:
+		if rule.WindowMinutes < 1 || rule.WindowMinutes > alertMaxWindowMinutes {
+			return fmt.Errorf("%w: window_minutes must be between 1 and %d", ErrInvalidAlertRule, alertMaxWindowMinutes)
+		}
+		rule.Threshold = 0
+	default:
+		return fmt.Errorf("%w: type must be %s or %s", ErrInvalidAlertRule, models.AlertRuleConsecutiveFailures, models.AlertRuleNotRun)
+	}
+	return nil
+}
+
+// checkChannels verifies that a rule's channels belong to the tenant
+func (s *AlertService) checkChannels(ctx context.Context, tenantID uuid.UUID, ids []uuid.UUID) (json.RawMessage, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: channel_ids needs at least one channel", ErrInvalidAlertRule)
+	}
+	for _, id := range ids {
+		if _, err := s.notificationRepo.FindChannelByTenantAndID(ctx, tenantID, id); err != nil {
+			return nil, fmt.Errorf("%w: channel %s not found", ErrInvalidAlertRule, id)
+		}
+	}
+	return json.Marshal(ids)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/repository"
+	"gorm.io/gorm"
+)
+
+// AuditLoader loads an entity of a tenant so audit entries can record it before and after a
+// call
+type AuditLoader func(ctx context.Context, tenantID, id uuid.UUID) (interface{}, error)
+
+// AuditService records and queries the audit log
+type AuditService struct {
+	auditRepo *repository.AuditRepository
+	loaders   map[string]AuditLoader
+}
+
+// NewAuditService creates a new audit service. Entities of the types in loaders, keyed by the
+// first segment of their routes such as jobs, are recorded before and after each call.
+func NewAuditService(auditRepo *repository.AuditRepository, loaders map[string]AuditLoader) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+		loaders:   loaders,
+	}
+}
+
+// Snapshot returns an entity as JSON, or nil when its type isn't snapshotted or it doesn't
+// exist
+func (s *AuditService) Snapshot(ctx context.Context, entityType string, tenantID, id uuid.UUID) (json.RawMessage, error) {
+	load, ok := s.loaders[entityType]
+	if !ok {
+		return nil, nil
+	}
+	entity, err := load(ctx, tenantID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(entity)
+}
+
+// Record appends an entry to the audit log
+func (s *AuditService) Record(ctx context.Context, entry *models.AuditLog) error {
+	return s.auditRepo.Create(ctx, entry)
+}
+
+// List queries the audit log
+func (s *AuditService) List(ctx context.Context, filter models.AuditFilter) (*models.AuditListResult, error) {
+	return s.auditRepo.Query(ctx, filter)
+}
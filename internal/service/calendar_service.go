@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/repository"
+	"github.com/minisource/scheduler/internal/scheduler"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidCalendar is returned when a calendar, or a job's blackout settings, fail validation
+	ErrInvalidCalendar = invalid("invalid calendar")
+	// ErrCalendarExists is returned when creating a calendar with a name the tenant already uses
+	ErrCalendarExists = conflict("calendar already exists")
+	// ErrCalendarInUse is returned when deleting a calendar that jobs still reference
+	ErrCalendarInUse = conflict("calendar is in use")
+)
+
+// calendarMaxWindows bounds the windows of a calendar or job
+const calendarMaxWindows = 100
+
+// calendarMaxDates bounds the dates of a holiday calendar
+const calendarMaxDates = 1000
+
+// CalendarService manages tenants' calendars
+type CalendarService struct {
+	calendarRepo *repository.CalendarRepository
+	jobRepo      *repository.JobRepository
+	scheduler    *scheduler.Scheduler
+}
+
+// NewCalendarService creates a new calendar service
+func NewCalendarService(calendarRepo *repository.CalendarRepository, jobRepo *repository.JobRepository, sched *scheduler.Scheduler) *CalendarService {
+	return &CalendarService{
+		calendarRepo: calendarRepo,
+		jobRepo:      jobRepo,
+		scheduler:    sched,
+	}
+}
+
+// List lists a tenant's calendars by name
+func (s *CalendarService) List(ctx context.Context, tenantID uuid.UUID) ([]models.Calendar, error) {
+	return s.calendarRepo.FindByTenant(ctx, tenantID)
+}
+
+// Get retrieves a calendar
+func (s *CalendarService) Get(ctx context.Context, tenantID, id uuid.UUID) (*models.Calendar, error) {
+	return s.calendarRepo.FindByTenantAndID(ctx, tenantID, id)
+}
+
+// Create creates a calendar
+func (s *CalendarService) Create(ctx context.Context, tenantID uuid.UUID, req *models.CreateCalendarRequest) (*models.Calendar, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return nil, fmt.Errorf("%w: name must be 1 to 100 characters", ErrInvalidCalendar)
+	}
+	kind := req.Kind
+	if kind == "" {
+		kind = models.CalendarKindBlackout
+	}
+	windows, dates, err := calendarContents(kind, req.TenantWide, req.Windows, req.Dates)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.calendarRepo.FindByTenantAndName(ctx, tenantID, name); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCalendarExists, name)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	calendar := &models.Calendar{
+		ID:          uuid.New(),
+		TenantID:    tenantID,
+		Name:        name,
+		Description: req.Description,
+		Kind:        kind,
+		TenantWide:  req.TenantWide,
+		Windows:     windows,
+		Dates:       dates,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := s.calendarRepo.Create(ctx, calendar); err != nil {
+		return nil, fmt.Errorf("failed to create calendar: %w", err)
+	}
+	return calendar, nil
+}
+
+// Update updates a calendar. Jobs following a blackout calendar use the new windows from
+// their next tick; jobs following a holiday calendar have their next run recalculated.
+func (s *CalendarService) Update(ctx context.Context, tenantID, id uuid.UUID, req *models.UpdateCalendarRequest) (*models.Calendar, error) {
+	calendar, err := s.calendarRepo.FindByTenantAndID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Description != nil {
+		calendar.Description = *req.Description
+	}
+	if req.TenantWide != nil || req.Windows != nil || req.Dates != nil {
+		tenantWide, windows, dates := calendar.TenantWide, calendar.BlackoutWindowList(), calendar.HolidayList()
+		if req.TenantWide != nil {
+			tenantWide = *req.TenantWide
+		}
+		if req.Windows != nil {
+			windows = *req.Windows
+		}
+		if req.Dates != nil {
+			dates = *req.Dates
+		}
+		calendar.Windows, calendar.Dates, err = calendarContents(calendar.Kind, tenantWide, windows, dates)
+		if err != nil {
+			return nil, err
+		}
+		calendar.TenantWide = tenantWide
+	}
+
+	calendar.UpdatedAt = time.Now()
+	if err := s.calendarRepo.Update(ctx, calendar); err != nil {
+		return nil, fmt.Errorf("failed to update calendar: %w", err)
+	}
+
+	if calendar.Kind == models.CalendarKindHoliday && req.Dates != nil {
+		if err := s.rescheduleHolidayJobs(ctx, tenantID, calendar.Name); err != nil {
+			return nil, err
+		}
+	}
+	return calendar, nil
+}
+
+// rescheduleHolidayJobs recalculates the next run of the jobs following a holiday calendar,
+// so added holidays are skipped and removed ones run again
+func (s *CalendarService) rescheduleHolidayJobs(ctx context.Context, tenantID uuid.UUID, name string) error {
+	jobs, err := s.jobRepo.FindByHolidayCalendar(ctx, tenantID, name)
+	if err != nil {
+		return err
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		if job.NextRunAt == nil {
+			continue
+		}
+		nextRunAt, err := s.scheduler.CalculateNextRun(ctx, job)
+		if err != nil || nextRunAt == nil {
+			continue
+		}
+		if err := s.jobRepo.UpdateNextRunAt(ctx, job.ID, *nextRunAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete deletes a calendar that no job references
+func (s *CalendarService) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
+	calendar, err := s.calendarRepo.FindByTenantAndID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.jobRepo.CountByCalendar(ctx, tenantID, calendar.Name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: %d jobs follow %s", ErrCalendarInUse, count, calendar.Name)
+	}
+	return s.calendarRepo.Delete(ctx, tenantID, id)
+}
+
+// validateBlackout checks a job's blackout calendar, which must exist, and its own windows,
+// returning the windows as stored
+func (s *CalendarService) validateBlackout(ctx context.Context, tenantID uuid.UUID, calendar string, windows []models.BlackoutWindow) (json.RawMessage, error) {
+	if calendar != "" {
+		found, err := s.calendarRepo.FindByTenantAndName(ctx, tenantID, calendar)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: blackout calendar %s doesn't exist", ErrInvalidCalendar, calendar)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if found.Kind != models.CalendarKindBlackout {
+			return nil, fmt.Errorf("%w: %s is not a blackout calendar", ErrInvalidCalendar, calendar)
+		}
+	}
+	if len(windows) == 0 {
+		return nil, nil
+	}
+	return marshalBlackoutWindows(windows)
+}
+
+// validateHoliday checks a job's holiday calendar, which must exist and can only be followed
+// by cron jobs
+func (s *CalendarService) validateHoliday(ctx context.Context, tenantID uuid.UUID, jobType models.JobType, calendar string) error {
+	if calendar == "" {
+		return nil
+	}
+	if jobType != models.JobTypeCron {
+		return fmt.Errorf("%w: only cron jobs can follow a holiday calendar", ErrInvalidCalendar)
+	}
+	found, err := s.calendarRepo.FindByTenantAndName(ctx, tenantID, calendar)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: holiday calendar %s doesn't exist", ErrInvalidCalendar, calendar)
+	}
+	if err != nil {
+		return err
+	}
+	if found.Kind != models.CalendarKindHoliday {
+		return fmt.Errorf("%w: %s is not a holiday calendar", ErrInvalidCalendar, calendar)
+	}
+	return nil
+}
+
+// calendarContents validates what a calendar of a kind holds, blackout windows or holiday
+// dates, and encodes it for storage
+func calendarContents(kind models.CalendarKind, tenantWide bool, windows []models.BlackoutWindow, dates []string) (json.RawMessage, json.RawMessage, error) {
+	switch kind {
+	case models.CalendarKindBlackout:
+		if len(dates) > 0 {
+			return nil, nil, fmt.Errorf("%w: blackout calendars have windows, not dates", ErrInvalidCalendar)
+		}
+		if len(windows) == 0 {
+			return nil, nil, fmt.Errorf("%w: at least one window is required", ErrInvalidCalendar)
+		}
+		stored, err := marshalBlackoutWindows(windows)
+		return stored, nil, err
+
+	case models.CalendarKindHoliday:
+		if len(windows) > 0 {
+			return nil, nil, fmt.Errorf("%w: holiday calendars have dates, not windows", ErrInvalidCalendar)
+		}
+		if tenantWide {
+			return nil, nil, fmt.Errorf("%w: holiday calendars can't be tenant-wide", ErrInvalidCalendar)
+		}
+		if len(dates) == 0 {
+			return nil, nil, fmt.Errorf("%w: at least one date is required", ErrInvalidCalendar)
+		}
+		if len(dates) > calendarMaxDates {
+			return nil, nil, fmt.Errorf("%w: at most %d dates", ErrInvalidCalendar, calendarMaxDates)
+		}
+		for _, date := range dates {
+			if err := models.ValidateHolidayDate(date); err != nil {
+				return nil, nil, fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+			}
+		}
+		stored, err := json.Marshal(dates)
+		return nil, stored, err
+	}
+	return nil, nil, fmt.Errorf("%w: kind must be blackout or holiday", ErrInvalidCalendar)
+}
+
+// marshalBlackoutWindows validates blackout windows and encodes them for storage
+func marshalBlackoutWindows(windows []models.BlackoutWindow) (json.RawMessage, error) {
+	if len(windows) > calendarMaxWindows {
+		return nil, fmt.Errorf("%w: at most %d windows", ErrInvalidCalendar, calendarMaxWindows)
+	}
+	for i := range windows {
+		if err := windows[i].Validate(); err != nil {
+			return nil, fmt.Errorf("%w: window %d: %v", ErrInvalidCalendar, i+1, err)
+		}
+	}
+	return json.Marshal(windows)
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/repository"
+)
+
+var (
+	// ErrInvalidCallbackRoute is returned when a callback route fails validation
+	ErrInvalidCallbackRoute = errors.New("invalid callback route")
+	// ErrNotRedeliverable is returned when redelivering a callback that hasn't failed
+	ErrNotRedeliverable = errors.New("callback delivery has not failed")
+)
+
+// Limits of callback delivery listings
+const (
+	callbackDefaultLimit = 50
+	callbackMaxLimit     = 500
+)
+
+// CallbackService handles callback route business logic
+type CallbackService struct {
+	callbackRepo *repository.CallbackRepository
+}
+
+// NewCallbackService creates a new callback service
+func NewCallbackService(callbackRepo *repository.CallbackRepository) *CallbackService {
+	return &CallbackService{
+		callbackRepo: callbackRepo,
+	}
+}
+
+// ListRoutes lists a tenant's callback routes in the order they are matched
+func (s *CallbackService) ListRoutes(ctx context.Context, tenantID uuid.UUID) ([]models.CallbackRoute, error) {
+	return s.callbackRepo.FindRoutesByTenant(ctx, tenantID)
+}
+
+// GetRoute retrieves a callback route
+func (s *CallbackService) GetRoute(ctx context.Context, tenantID, id uuid.UUID) (*models.CallbackRoute, error) {
+	return s.callbackRepo.FindRouteByTenantAndID(ctx, tenantID, id)
+}
+
+// CreateRoute creates a callback route
+func (s *CallbackService) CreateRoute(ctx context.Context, tenantID uuid.UUID, req *models.CreateCallbackRouteRequest) (*models.CallbackRoute, error) {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	route := &models.CallbackRoute{
+		ID:              uuid.New(),
+		TenantID:        tenantID,
+		Name:            req.Name,
+		Position:        req.Position,
+		URL:             req.URL,
+		IncludeResponse: req.IncludeResponse,
+		Enabled:         enabled,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	if err := setRouteSelectors(route, req.Tags, req.Headers, req.Statuses); err != nil {
+		return nil, err
+	}
+	if err := validateCallbackRoute(route); err != nil {
+		return nil, err
+	}
+
+	if err := s.callbackRepo.CreateRoute(ctx, route); err != nil {
+		return nil, fmt.Errorf("failed to create callback route: %w", err)
+	}
+	return route, nil
+}
+
+// UpdateRoute updates a callback route. Callbacks already queued keep the URL and headers
+// they were queued with.
+func (s *CallbackService) UpdateRoute(ctx context.Context, tenantID, id uuid.UUID, req *models.UpdateCallbackRouteRequest) (*models.CallbackRoute, error) {
+	route, err := s.callbackRepo.FindRouteByTenantAndID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		route.Name = *req.Name
+	}
+	if req.Position != nil {
+		route.Position = *req.Position
+	}
+	if req.URL != nil {
+		route.URL = *req.URL
+	}
+	if req.IncludeResponse != nil {
+		route.IncludeResponse = *req.IncludeResponse
+	}
+	if req.Enabled != nil {
+		route.Enabled = *req.Enabled
+	}
+
+	// Selectors left out of the request keep their current values
+	var tags []string
+	var headers map[string]string
+	var statuses []models.ExecutionStatus
+	_ = json.Unmarshal(route.Tags, &tags)
+	_ = json.Unmarshal(route.Headers, &headers)
+	_ = json.Unmarshal(route.Statuses, &statuses)
+	if req.Tags != nil {
+		tags = *req.Tags
+	}
+	if req.Headers != nil {
+		headers = *req.Headers
+	}
+	if req.Statuses != nil {
+		statuses = *req.Statuses
+	}
+	if err := setRouteSelectors(route, tags, headers, statuses); err != nil {
+		return nil, err
+	}
+	if err := validateCallbackRoute(route); err != nil {
+		return nil, err
+	}
+
+	route.UpdatedAt = time.Now()
+	if err := s.callbackRepo.UpdateRoute(ctx, route); err != nil {
+		return nil, fmt.Errorf("failed to update callback route: %w", err)
+	}
+	return route, nil
+}
+
+// DeleteRoute deletes a callback route
+func (s *CallbackService) DeleteRoute(ctx context.Context, tenantID, id uuid.UUID) error {
+	_, err := s.callbackRepo.DeleteRoute(ctx, tenantID, id)
+	return err
+}
+
+// ListDeliveries lists a tenant's queued and past callbacks, newest first
+func (s *CallbackService) ListDeliveries(ctx context.Context, filter models.CallbackDeliveryFilter) ([]models.CallbackDelivery, error) {
+	switch filter.Status {
+	case "", models.CallbackStatusPending, models.CallbackStatusDelivered, models.CallbackStatusFailed:
+	default:
+		return nil, fmt.Errorf("%w: status must be pending, delivered or failed", ErrInvalidCallbackRoute)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = callbackDefaultLimit
+	}
+	if filter.Limit > callbackMaxLimit {
+		filter.Limit = callbackMaxLimit
+	}
+	return s.callbackRepo.FindDeliveries(ctx, filter)
+}
+
+// Redeliver queues a failed callback for another round of attempts
+func (s *CallbackService) Redeliver(ctx context.Context, tenantID, id uuid.UUID) (*models.CallbackDelivery, error) {
+	if _, err := s.callbackRepo.FindDeliveryByTenantAndID(ctx, tenantID, id); err != nil {
+		return nil, err
+	}
+
+	requeued, err := s.callbackRepo.Redeliver(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !requeued {
+		return nil, ErrNotRedeliverable
+	}
+	return s.callbackRepo.FindDeliveryByTenantAndID(ctx, tenantID, id)
+}
+
+// setRouteSelectors stores a route's tags, headers and statuses, leaving empty ones unset
+func setRouteSelectors(route *models.CallbackRoute, tags []string, headers map[string]string, statuses []models.ExecutionStatus) error {
+	route.Tags, route.Headers, route.Statuses = nil, nil, nil
+
+	for _, tag := range tags {
+		if strings.TrimSpace(tag) == "" {
+			return fmt.Errorf("%w: tags can't be empty", ErrInvalidCallbackRoute)
+		}
+	}
+	for name, value := range headers {
+		if strings.TrimSpace(name) == "" || strings.ContainsAny(name+value, "\r\n") {
+			return fmt.Errorf("%w: invalid header %q", ErrInvalidCallbackRoute, name)
+		}
+	}
+	for _, status := range statuses {
+		switch status {
+		case models.ExecutionStatusCompleted, models.ExecutionStatusFailed, models.ExecutionStatusTimeout:
+		default:
+			return fmt.Errorf("%w: statuses can be completed, failed or timeout", ErrInvalidCallbackRoute)
+		}
+	}
+
+	var err error
+	if len(tags) > 0 {
+		if route.Tags, err = json.Marshal(tags); err != nil {
+			return err
+		}
+	}
+	if len(headers) > 0 {
+		if route.Headers, err = json.Marshal(headers); err != nil {
+			return err
+		}
+	}
+	if len(statuses) > 0 {
+		if route.Statuses, err = json.Marshal(statuses); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCallbackRoute checks a route's name and URL
+func validateCallbackRoute(route *models.CallbackRoute) error {
+	if strings.TrimSpace(route.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCallbackRoute)
+	}
+	parsed, err := url.Parse(route.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidCallbackRoute)
+	}
+	return nil
+}
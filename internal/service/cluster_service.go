@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/scheduler"
+)
+
+// ClusterService reports the state of the scheduler cluster
+type ClusterService struct {
+	scheduler *scheduler.Scheduler
+}
+
+// NewClusterService creates a new cluster service
+func NewClusterService(sched *scheduler.Scheduler) *ClusterService {
+	return &ClusterService{
+		scheduler: sched,
+	}
+}
+
+// Leader reports which instance holds the leader lock
+func (s *ClusterService) Leader(ctx context.Context) (scheduler.LeaderStatus, error) {
+	return s.scheduler.Leader(ctx)
+}
+
+// Workers lists the instances in the worker registry with their capacity and load
+func (s *ClusterService) Workers(ctx context.Context) ([]models.ClusterMember, error) {
+	return s.scheduler.Members(ctx)
+}
+
+// Shadow reports how shadow claim dispatch compared with the leader on this instance
+func (s *ClusterService) Shadow() scheduler.ShadowStatus {
+	return s.scheduler.Shadow()
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/validation"
+	"gorm.io/gorm"
+)
+
+// Kinds of service errors. Handlers answer ErrValidation with 400, ErrNotFound with 404 and
+// ErrConflict with 409. The sentinels of a kind, such as ErrInvalidLifetime, match both
+// themselves and their kind with errors.Is.
+var (
+	ErrValidation = errors.New("validation failed")
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+)
+
+// kindError is an error of a kind, optionally caused by another error
+type kindError struct {
+	msg   string
+	kind  error
+	cause error
+}
+
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+func (e *kindError) Unwrap() []error {
+	if e.cause == nil {
+		return []error{e.kind}
+	}
+	return []error{e.kind, e.cause}
+}
+
+// invalid creates a sentinel for requests failing a validation
+func invalid(msg string) error {
+	return &kindError{msg: msg, kind: ErrValidation}
+}
+
+// conflict creates a sentinel for requests that conflict with the current state
+func conflict(msg string) error {
+	return &kindError{msg: msg, kind: ErrConflict}
+}
+
+// notFound turns a record not found by a repository into ErrNotFound naming what wasn't found,
+// still matching gorm.ErrRecordNotFound. Other errors are returned unchanged.
+func notFound(err error, what string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &kindError{msg: what + " not found", kind: ErrNotFound, cause: err}
+	}
+	return err
+}
+
+// ValidationError lists the request fields a validation failed on. It matches ErrValidation
+// and the errors found for each field with errors.Is.
+type ValidationError struct {
+	Fields []models.FieldError
+	errs   []error
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return append([]error{ErrValidation}, e.errs...)
+}
+
+// add records a problem with a field
+func (e *ValidationError) add(field string, err error) {
+	e.Fields = append(e.Fields, models.FieldError{Field: field, Message: err.Error()})
+	e.errs = append(e.errs, err)
+}
+
+// has reports whether a problem with a field was recorded
+func (e *ValidationError) has(field string) bool {
+	for _, f := range e.Fields {
+		if f.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTags checks a request against its validate tags, recording a problem for each field
+// failing one
+func checkTags(req interface{}) *ValidationError {
+	problems := &ValidationError{}
+	for _, field := range validation.Struct(req) {
+		problems.add(field.Field, invalid(field.Message))
+	}
+	return problems
+}
+
+// invalidField reports a validation failure of one request field
+func invalidField(field string, err error) error {
+	verr := &ValidationError{}
+	verr.add(field, err)
+	return verr
+}
@@ -2,28 +2,59 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/minisource/scheduler/internal/models"
 	"github.com/minisource/scheduler/internal/repository"
+	"github.com/minisource/scheduler/internal/scheduler"
+	"gorm.io/gorm"
 )
 
+// ErrNotAcknowledgeable is returned when acknowledging an execution that has not failed
+var ErrNotAcknowledgeable = errors.New("only failed or timed out executions can be acknowledged")
+
+// ErrNotReplayable is returned when replaying an execution without a recorded request or
+// whose job can no longer run
+var ErrNotReplayable = errors.New("execution cannot be replayed")
+
+// ErrNotComparable is returned when comparing executions of different jobs
+var ErrNotComparable = errors.New("only executions of the same job can be compared")
+
 // ExecutionService handles execution business logic
 type ExecutionService struct {
 	executionRepo *repository.ExecutionRepository
+	jobRepo       *repository.JobRepository
+	incidentRepo  *repository.IncidentRepository
+	sealRepo      *repository.ExecutionSealRepository
+	tenantService *TenantService
+	scheduler     *scheduler.Scheduler
 }
 
 // NewExecutionService creates a new execution service
-func NewExecutionService(executionRepo *repository.ExecutionRepository) *ExecutionService {
+func NewExecutionService(executionRepo *repository.ExecutionRepository, jobRepo *repository.JobRepository, incidentRepo *repository.IncidentRepository, sealRepo *repository.ExecutionSealRepository, tenantService *TenantService, sched *scheduler.Scheduler) *ExecutionService {
 	return &ExecutionService{
 		executionRepo: executionRepo,
+		jobRepo:       jobRepo,
+		incidentRepo:  incidentRepo,
+		sealRepo:      sealRepo,
+		tenantService: tenantService,
+		scheduler:     sched,
 	}
 }
 
-// GetByID retrieves an execution by ID
-func (s *ExecutionService) GetByID(ctx context.Context, id uuid.UUID) (*models.JobExecution, error) {
-	return s.executionRepo.FindByID(ctx, id)
+// GetByID retrieves an execution by ID, with its queue position and estimated start while it
+// is pending or queued
+func (s *ExecutionService) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.JobExecution, error) {
+	execution, err := s.executionRepo.FindByTenantAndID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	s.scheduler.EstimateStart(ctx, execution)
+	return execution, nil
 }
 
 // List lists executions with filtering
@@ -32,13 +63,157 @@ func (s *ExecutionService) List(ctx context.Context, filter models.ExecutionFilt
 }
 
 // GetByJobID retrieves executions for a job
-func (s *ExecutionService) GetByJobID(ctx context.Context, jobID uuid.UUID, limit int) ([]models.JobExecution, error) {
-	return s.executionRepo.FindByJobID(ctx, jobID, limit)
+func (s *ExecutionService) GetByJobID(ctx context.Context, tenantID, jobID uuid.UUID, limit int) ([]models.JobExecution, error) {
+	return s.executionRepo.FindByTenantAndJobID(ctx, tenantID, jobID, limit)
 }
 
 // Cancel cancels an execution
-func (s *ExecutionService) Cancel(ctx context.Context, id uuid.UUID) error {
-	return s.executionRepo.CancelExecution(ctx, id)
+func (s *ExecutionService) Cancel(ctx context.Context, tenantID, id uuid.UUID) error {
+	execution, err := s.executionRepo.FindByTenantAndID(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.executionRepo.CancelExecution(ctx, execution.ID); err != nil {
+		return err
+	}
+
+	// Stop it now if it runs here; other instances pick the cancellation up within seconds
+	s.scheduler.CancelExecution(execution.ID)
+	return nil
+}
+
+// Acknowledge marks a failed execution as being handled and attaches a comment
+func (s *ExecutionService) Acknowledge(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID, comment string) (*models.JobExecution, error) {
+	execution, err := s.executionRepo.FindByTenantAndID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if execution.Status != models.ExecutionStatusFailed && execution.Status != models.ExecutionStatusTimeout {
+		return nil, ErrNotAcknowledgeable
+	}
+
+	if err := s.executionRepo.Acknowledge(ctx, execution.ID, userID, comment); err != nil {
+		return nil, err
+	}
+
+	// Acknowledging the latest failure also acknowledges its open incident, silencing repeat alerts
+	if s.incidentRepo != nil {
+		_ = s.incidentRepo.AcknowledgeOpenForExecution(ctx, tenantID, execution.JobID, execution.ID, userID)
+	}
+
+	return s.executionRepo.FindByTenantAndID(ctx, tenantID, id)
+}
+
+// Replay runs an execution's recorded request again as a new execution of its job. The
+// replay sends the payload and headers the original execution was created with, not the
+// job's current definition. Like a manual trigger, it is refused while the tenant is paused
+// and counts against the job's trigger limit.
+func (s *ExecutionService) Replay(ctx context.Context, tenantID, id uuid.UUID) (*models.JobExecution, error) {
+	original, err := s.executionRepo.FindByTenantAndID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(original.Request) == 0 || string(original.Request) == "null" {
+		return nil, fmt.Errorf("%w: no request was recorded for this execution", ErrNotReplayable)
+	}
+
+	job, err := s.jobRepo.FindByTenantAndID(ctx, tenantID, original.JobID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: the job no longer exists", ErrNotReplayable)
+		}
+		return nil, err
+	}
+	if job.Status != models.JobStatusActive && job.Status != models.JobStatusPaused {
+		return nil, fmt.Errorf("%w: job status is %s", ErrNotReplayable, job.Status)
+	}
+	if s.tenantService.Paused(ctx, tenantID) {
+		return nil, ErrTenantPaused
+	}
+
+	return s.scheduler.ReplayExecution(ctx, job, original)
+}
+
+// executionOutcome is the part of an execution an execution comparison lists under outcome
+type executionOutcome struct {
+	Status        models.ExecutionStatus `json:"status"`
+	StatusCode    *int                   `json:"status_code"`
+	Error         string                 `json:"error"`
+	Attempt       int                    `json:"attempt"`
+	WorkerGroup   string                 `json:"worker_group"`
+	CorrelationID string                 `json:"correlation_id"`
+}
+
+// Compare diffs two executions of the same job: their outcome, recorded request, response and
+// timing. Changes are listed from a to b, so pass the good run as a and the failing one as b.
+func (s *ExecutionService) Compare(ctx context.Context, tenantID, a, b uuid.UUID) (*models.ExecutionComparison, error) {
+	first, err := s.executionRepo.FindByTenantAndID(ctx, tenantID, a)
+	if err != nil {
+		return nil, err
+	}
+	second, err := s.executionRepo.FindByTenantAndID(ctx, tenantID, b)
+	if err != nil {
+		return nil, err
+	}
+	if first.JobID != second.JobID {
+		return nil, ErrNotComparable
+	}
+
+	comparison := &models.ExecutionComparison{
+		JobID:    first.JobID,
+		A:        comparedExecution(first),
+		B:        comparedExecution(second),
+		Outcome:  models.DiffJSON(outcomeJSON(first), outcomeJSON(second)),
+		Request:  models.DiffJSON(first.Request, second.Request),
+		Response: models.DiffJSON(first.Response, second.Response),
+		Timing: models.ExecutionTimingDiff{
+			Duration:      models.NewMetricDiff(first.Duration, second.Duration),
+			StartDelay:    models.NewMetricDiff(startDelay(first), startDelay(second)),
+			WallTime:      models.NewMetricDiff(&first.WallTime, &second.WallTime),
+			BytesSent:     models.NewMetricDiff(&first.BytesSent, &second.BytesSent),
+			BytesReceived: models.NewMetricDiff(&first.BytesReceived, &second.BytesReceived),
+		},
+	}
+	comparison.Same = len(comparison.Outcome.Changes) == 0 &&
+		len(comparison.Request.Changes) == 0 &&
+		len(comparison.Response.Changes) == 0
+	return comparison, nil
+}
+
+// comparedExecution identifies an execution in a comparison
+func comparedExecution(e *models.JobExecution) models.ComparedExecution {
+	return models.ComparedExecution{
+		ID:          e.ID,
+		Status:      e.Status,
+		StatusCode:  e.StatusCode,
+		ScheduledAt: e.ScheduledAt,
+		StartedAt:   e.StartedAt,
+		CompletedAt: e.CompletedAt,
+	}
+}
+
+// outcomeJSON encodes the outcome fields of an execution for diffing
+func outcomeJSON(e *models.JobExecution) json.RawMessage {
+	outcome, _ := json.Marshal(executionOutcome{
+		Status:        e.Status,
+		StatusCode:    e.StatusCode,
+		Error:         e.Error,
+		Attempt:       e.Attempt,
+		WorkerGroup:   e.WorkerGroup,
+		CorrelationID: e.CorrelationID,
+	})
+	return outcome
+}
+
+// startDelay returns how long after its scheduled time an execution started, in milliseconds
+func startDelay(e *models.JobExecution) *int64 {
+	if e.StartedAt == nil {
+		return nil
+	}
+	delay := e.StartedAt.Sub(e.ScheduledAt).Milliseconds()
+	return &delay
 }
 
 // GetStats retrieves execution statistics
@@ -55,3 +230,61 @@ func (s *ExecutionService) GetRunning(ctx context.Context) ([]models.JobExecutio
 func (s *ExecutionService) GetPending(ctx context.Context, before time.Time, limit int) ([]models.JobExecution, error) {
 	return s.executionRepo.FindPending(ctx, before, limit)
 }
+
+// verifyBatchSize is the number of seals checked per query
+const verifyBatchSize = 500
+
+// VerifyChain walks a tenant's execution chain from the first seal, checking every link and
+// recomputing the content hash of each sealed execution that still exists
+func (s *ExecutionService) VerifyChain(ctx context.Context, tenantID uuid.UUID) (*models.ChainVerification, error) {
+	result := &models.ChainVerification{CheckedAt: time.Now()}
+
+	for {
+		seals, err := s.sealRepo.FindByTenant(ctx, tenantID, result.HeadSeq, verifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(seals) == 0 {
+			break
+		}
+
+		ids := make([]uuid.UUID, len(seals))
+		for i, seal := range seals {
+			ids[i] = seal.ExecutionID
+		}
+		executions, err := s.sealRepo.FindExecutions(ctx, tenantID, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, seal := range seals {
+			result.Seals++
+			if result.BrokenAtSeq == nil &&
+				(seal.Seq != result.HeadSeq+1 || seal.PrevHash != result.HeadHash || seal.ComputeHash() != seal.Hash) {
+				brokenAt := seal.Seq
+				result.BrokenAtSeq = &brokenAt
+			}
+
+			execution, ok := executions[seal.ExecutionID]
+			switch {
+			case !ok:
+				result.Missing++
+			case models.ExecutionContentHash(&execution) != seal.ContentHash:
+				result.Altered = append(result.Altered, execution.ID)
+			default:
+				result.Verified++
+			}
+
+			result.HeadSeq = seal.Seq
+			result.HeadHash = seal.Hash
+		}
+	}
+
+	unsealed, err := s.sealRepo.CountUnsealed(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	result.Unsealed = unsealed
+	result.Valid = result.BrokenAtSeq == nil && len(result.Altered) == 0
+	return result, nil
+}
@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,41 +12,145 @@ import (
 	"github.com/minisource/scheduler/internal/repository"
 )
 
+// ErrInvalidRebuild is returned when a history rebuild's date range is invalid
+var ErrInvalidRebuild = errors.New("invalid history rebuild")
+
+// ErrInvalidHistoryTimezone is returned when reading history in a timezone it isn't kept in
+var ErrInvalidHistoryTimezone = errors.New("invalid history timezone")
+
+// historyRebuildMaxDays bounds the days rebuilt by one request
+const historyRebuildMaxDays = 366
+
 // HistoryService handles history business logic
 type HistoryService struct {
-	historyRepo *repository.HistoryRepository
+	historyRepo   *repository.HistoryRepository
+	tenantService *TenantService
 }
 
 // NewHistoryService creates a new history service
-func NewHistoryService(historyRepo *repository.HistoryRepository) *HistoryService {
+func NewHistoryService(historyRepo *repository.HistoryRepository, tenantService *TenantService) *HistoryService {
 	return &HistoryService{
-		historyRepo: historyRepo,
+		historyRepo:   historyRepo,
+		tenantService: tenantService,
+	}
+}
+
+// GetByJobID retrieves a job's history for the last days, in a timezone
+func (s *HistoryService) GetByJobID(ctx context.Context, tenantID, jobID uuid.UUID, timezone string, days int) ([]models.JobHistory, error) {
+	zone, err := s.zone(ctx, tenantID, timezone)
+	if err != nil {
+		return nil, err
+	}
+	startDate := calendarDay(time.Now().In(zone)).AddDate(0, 0, -days)
+	return s.historyRepo.FindByTenantAndJobID(ctx, tenantID, jobID, zone.String(), startDate)
+}
+
+// GetByDateRange retrieves history for a date range, in a timezone
+func (s *HistoryService) GetByDateRange(ctx context.Context, tenantID uuid.UUID, timezone string, startDate, endDate time.Time) ([]models.JobHistory, error) {
+	zone, err := s.zone(ctx, tenantID, timezone)
+	if err != nil {
+		return nil, err
 	}
+	return s.historyRepo.FindByDateRange(ctx, tenantID, zone.String(), startDate, endDate)
 }
 
-// GetByJobID retrieves history for a job
-func (s *HistoryService) GetByJobID(ctx context.Context, jobID uuid.UUID, days int) ([]models.JobHistory, error) {
-	return s.historyRepo.FindByJobID(ctx, jobID, days)
+// GetAggregated retrieves aggregated history stats, in a timezone. The period defaults to the
+// last 30 days of the timezone.
+func (s *HistoryService) GetAggregated(ctx context.Context, tenantID uuid.UUID, jobID *uuid.UUID, timezone string, startDate, endDate time.Time) (*models.AggregatedHistoryStats, error) {
+	zone, err := s.zone(ctx, tenantID, timezone)
+	if err != nil {
+		return nil, err
+	}
+	if endDate.IsZero() {
+		endDate = calendarDay(time.Now().In(zone))
+	}
+	if startDate.IsZero() {
+		startDate = endDate.AddDate(0, 0, -30)
+	}
+	return s.historyRepo.GetAggregatedStats(ctx, tenantID, jobID, zone.String(), startDate, endDate)
 }
 
-// GetByDateRange retrieves history for a date range
-func (s *HistoryService) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]models.JobHistory, error) {
-	return s.historyRepo.FindByDateRange(ctx, startDate, endDate)
+// zone returns the timezone a tenant's history is read in: the requested one, which must be
+// UTC or the tenant's own, or the tenant's own by default
+func (s *HistoryService) zone(ctx context.Context, tenantID uuid.UUID, requested string) (*time.Location, error) {
+	own := s.tenantService.Limits(ctx, tenantID).Timezone
+	name := requested
+	if name == "" {
+		name = own
+	}
+	if name != models.HistoryTimezoneUTC && name != own {
+		if own == models.HistoryTimezoneUTC {
+			return nil, fmt.Errorf("%w: history is only kept in UTC; set the tenant's timezone to keep it in %s", ErrInvalidHistoryTimezone, name)
+		}
+		return nil, fmt.Errorf("%w: history is kept in UTC and %s", ErrInvalidHistoryTimezone, own)
+	}
+	return time.LoadLocation(name)
 }
 
-// GetAggregated retrieves aggregated history stats
-func (s *HistoryService) GetAggregated(ctx context.Context, jobID *uuid.UUID, startDate, endDate time.Time) (*models.AggregatedHistoryStats, error) {
-	return s.historyRepo.GetAggregatedStats(ctx, jobID, startDate, endDate)
+// calendarDay returns the calendar day of a time in its own location, as a date
+func calendarDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
 }
 
-// RecordSuccess records a successful execution in history
-func (s *HistoryService) RecordSuccess(ctx context.Context, jobID uuid.UUID, date time.Time, duration int64) error {
-	return s.historyRepo.IncrementSuccess(ctx, jobID, date, duration)
+// UsageByTenant sums the resources every tenant's executions used over a period, busiest
+// tenant first, for metering and capacity planning
+func (s *HistoryService) UsageByTenant(ctx context.Context, startDate, endDate time.Time) ([]models.TenantResourceUsage, error) {
+	return s.historyRepo.GetUsageByTenant(ctx, startDate, endDate)
+}
+
+// RecordSuccess records a successful execution in the UTC history
+func (s *HistoryService) RecordSuccess(ctx context.Context, tenantID, jobID uuid.UUID, date time.Time, duration int64) error {
+	return s.historyRepo.IncrementSuccess(ctx, tenantID, jobID, models.HistoryTimezoneUTC, date.UTC(), duration)
 }
 
 // RecordFailure records a failed execution in history
-func (s *HistoryService) RecordFailure(ctx context.Context, jobID uuid.UUID, date time.Time) error {
-	return s.historyRepo.IncrementFailure(ctx, jobID, date)
+func (s *HistoryService) RecordFailure(ctx context.Context, tenantID, jobID uuid.UUID, date time.Time) error {
+	return s.historyRepo.IncrementFailure(ctx, tenantID, jobID, models.HistoryTimezoneUTC, date.UTC())
+}
+
+// Rebuild replaces the history of a range of days in a timezone, UTC by default, with records
+// aggregated from the executions that finished on them, repairing counters that drifted or
+// filling in the history of a tenant that changed its timezone. Each day is rebuilt in its
+// own transaction, so a failure leaves the days before it rebuilt.
+func (s *HistoryService) Rebuild(ctx context.Context, req *models.RebuildHistoryRequest) (*models.HistoryRebuildResult, error) {
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = models.HistoryTimezoneUTC
+	}
+	zone, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "Local" {
+		return nil, fmt.Errorf("%w: invalid timezone %q", ErrInvalidRebuild, timezone)
+	}
+	from, err := time.Parse("2006-01-02", req.From)
+	if err != nil {
+		return nil, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidRebuild)
+	}
+	to, err := time.Parse("2006-01-02", req.To)
+	if err != nil {
+		return nil, fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidRebuild)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidRebuild)
+	}
+	if to.After(calendarDay(time.Now().In(zone))) {
+		return nil, fmt.Errorf("%w: to must not be in the future", ErrInvalidRebuild)
+	}
+	days := int(to.Sub(from).Hours()/24) + 1
+	if days > historyRebuildMaxDays {
+		return nil, fmt.Errorf("%w: at most %d days can be rebuilt at once", ErrInvalidRebuild, historyRebuildMaxDays)
+	}
+
+	result := &models.HistoryRebuildResult{From: req.From, To: req.To, Timezone: timezone, Days: days}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		deleted, created, err := s.historyRepo.RebuildDay(ctx, day, zone, req.TenantID, req.JobID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rebuild history of %s: %w", day.Format("2006-01-02"), err)
+		}
+		result.Deleted += deleted
+		result.Created += created
+	}
+	log.Printf("history: rebuilt %d %s days from %s to %s, replacing %d records with %d", days, timezone, req.From, req.To, result.Deleted, result.Created)
+	return result, nil
 }
 
 // Cleanup removes old history records
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/repository"
+)
+
+// ErrIncidentResolved is returned when acknowledging an incident that is already resolved
+var ErrIncidentResolved = errors.New("incident is already resolved")
+
+// IncidentService handles incident business logic
+type IncidentService struct {
+	incidentRepo *repository.IncidentRepository
+}
+
+// NewIncidentService creates a new incident service
+func NewIncidentService(incidentRepo *repository.IncidentRepository) *IncidentService {
+	return &IncidentService{
+		incidentRepo: incidentRepo,
+	}
+}
+
+// GetByID retrieves an incident by ID
+func (s *IncidentService) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.Incident, error) {
+	return s.incidentRepo.FindByTenantAndID(ctx, tenantID, id)
+}
+
+// List lists incidents with filtering
+func (s *IncidentService) List(ctx context.Context, filter models.IncidentFilter) (*models.IncidentListResult, error) {
+	return s.incidentRepo.Query(ctx, filter)
+}
+
+// Acknowledge marks an incident as being handled, suppressing repeat alerts and escalation
+func (s *IncidentService) Acknowledge(ctx context.Context, tenantID, id uuid.UUID, userID *uuid.UUID) (*models.Incident, error) {
+	incident, err := s.incidentRepo.FindByTenantAndID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if incident.Status != models.IncidentStatusOpen {
+		return nil, ErrIncidentResolved
+	}
+
+	if err := s.incidentRepo.Acknowledge(ctx, tenantID, id, userID); err != nil {
+		return nil, err
+	}
+
+	return s.incidentRepo.FindByTenantAndID(ctx, tenantID, id)
+}
@@ -1,52 +1,314 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/minisource/scheduler/config"
 	"github.com/minisource/scheduler/internal/models"
 	"github.com/minisource/scheduler/internal/repository"
 	"github.com/minisource/scheduler/internal/scheduler"
 	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
 )
 
+// ErrPayloadTooLarge is returned when a job's payload or headers exceed the tenant's limits
+var ErrPayloadTooLarge = errors.New("job definition too large")
+
+// ErrInsecureTLSNotAllowed is returned when a job skips TLS verification but its tenant isn't
+// allowed to
+var ErrInsecureTLSNotAllowed = invalid("insecure_skip_verify is not allowed for this tenant")
+
+// ErrJobNotDeleted is returned when restoring a job that isn't deleted
+var ErrJobNotDeleted = conflict("job is not deleted")
+
+// ErrInvalidResumeAt is returned when a job can't be paused until the requested time
+var ErrInvalidResumeAt = invalid("invalid resume time")
+
+// ErrInvalidCorrelationID is returned when a job or trigger's correlation ID is too long
+var ErrInvalidCorrelationID = invalid("invalid correlation ID")
+
+// correlationIDMaxLength is the longest correlation ID stored on jobs and executions
+const correlationIDMaxLength = 255
+
+// ErrInvalidExternalID is returned when a job's external ID is too long
+var ErrInvalidExternalID = invalid("invalid external ID")
+
+// externalIDMaxLength is the longest external ID stored on jobs
+const externalIDMaxLength = 255
+
+// ErrInvalidLifetime is returned when a job's start time, end time or maximum runs can't be applied
+var ErrInvalidLifetime = invalid("invalid job lifetime")
+
+// ErrInvalidTriggerLimit is returned when a job's manual trigger limits are negative
+var ErrInvalidTriggerLimit = invalid("invalid trigger limit")
+
+// ErrNotTriggerable is returned when triggering a job that is neither active nor paused
+var ErrNotTriggerable = conflict("job cannot be triggered")
+
+// ErrCanaryNotAllowed is returned when starting a canary of a job that is already running
+// its schedule or can't run
+var ErrCanaryNotAllowed = conflict("job cannot start a canary")
+
+// ErrNotHeldForCanary is returned when confirming a job that isn't held for its canary
+var ErrNotHeldForCanary = conflict("job is not held for a canary")
+
+// ErrJobDeleted is returned when rolling back a job that is deleted
+var ErrJobDeleted = conflict("job is deleted")
+
+// ErrRevisionNotFound is returned when a job has no revision of the requested number
+var ErrRevisionNotFound = &kindError{msg: "job revision not found", kind: ErrNotFound}
+
+// ErrRevisionNotRestorable is returned when a job can't be rolled back to a revision, e.g.
+// because a calendar it names no longer exists
+var ErrRevisionNotRestorable = conflict("revision can't be restored")
+
+// Limits of revision listings
+const (
+	revisionDefaultLimit = 20
+	revisionMaxLimit     = 100
+)
+
+// ErrInvalidBatch is returned when a batch upsert is empty or holds too many jobs
+var ErrInvalidBatch = invalid("invalid batch")
+
+// batchUpsertMaxJobs bounds the jobs of one batch upsert
+const batchUpsertMaxJobs = 5000
+
 // JobService handles job business logic
 type JobService struct {
-	jobRepo    *repository.JobRepository
-	scheduler  *scheduler.Scheduler
-	cronParser cron.Parser
+	jobRepo       *repository.JobRepository
+	executionRepo *repository.ExecutionRepository
+	historyRepo   *repository.HistoryRepository
+	revisionRepo  *repository.JobRevisionRepository
+	tenantService *TenantService
+	calendars     *CalendarService
+	admission     *AdmissionService
+	scheduler     *scheduler.Scheduler
+	cronParser    cron.Parser
 }
 
 // NewJobService creates a new job service
 func NewJobService(
 	jobRepo *repository.JobRepository,
+	executionRepo *repository.ExecutionRepository,
+	historyRepo *repository.HistoryRepository,
+	revisionRepo *repository.JobRevisionRepository,
+	tenantService *TenantService,
+	calendarService *CalendarService,
+	admissionService *AdmissionService,
 	sched *scheduler.Scheduler,
 ) *JobService {
 	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
 
 	return &JobService{
-		jobRepo:    jobRepo,
-		scheduler:  sched,
-		cronParser: parser,
+		jobRepo:       jobRepo,
+		executionRepo: executionRepo,
+		historyRepo:   historyRepo,
+		revisionRepo:  revisionRepo,
+		tenantService: tenantService,
+		calendars:     calendarService,
+		admission:     admissionService,
+		scheduler:     sched,
+		cronParser:    parser,
 	}
 }
 
 // Create creates a new job
 func (s *JobService) Create(ctx context.Context, tenantID uuid.UUID, req *models.CreateJobRequest) (*models.Job, error) {
-	// Validate job type and schedule
-	if err := s.validateSchedule(req.Type, req.Schedule); err != nil {
+	job, _, err := s.CreateIdempotent(ctx, tenantID, req)
+	return job, err
+}
+
+// CreateIdempotent creates a new job, or returns the existing job if one was already created
+// with the same client reference. The boolean reports whether a job was created.
+func (s *JobService) CreateIdempotent(ctx context.Context, tenantID uuid.UUID, req *models.CreateJobRequest) (*models.Job, bool, error) {
+	if req.ClientReference != "" {
+		if existing, err := s.jobRepo.FindByTenantAndClientReference(ctx, tenantID, req.ClientReference); err == nil {
+			return existing, false, nil
+		}
+	}
+
+	job, err := s.create(ctx, tenantID, req)
+	if err != nil && req.ClientReference != "" {
+		// A concurrent request with the same key won the insert
+		if existing, findErr := s.jobRepo.FindByTenantAndClientReference(ctx, tenantID, req.ClientReference); findErr == nil {
+			return existing, false, nil
+		}
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return job, true, nil
+}
+
+// create validates a request, as changed by the admission webhooks, and inserts the job. A
+// request failing validation returns a ValidationError listing every field at fault.
+func (s *JobService) create(ctx context.Context, tenantID uuid.UUID, req *models.CreateJobRequest) (*models.Job, error) {
+	if err := s.admission.Review(ctx, tenantID, models.AdmissionCreate, false, req, nil); err != nil {
+		return nil, err
+	}
+	job, problems, err := s.build(ctx, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+	if problems != nil {
+		return nil, problems
+	}
+
+	if req.Canary && s.tenantService.Paused(ctx, tenantID) {
+		return nil, ErrTenantPaused
+	}
+
+	err = s.jobRepo.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.jobRepo.Create(ctx, job); err != nil {
+			return fmt.Errorf("failed to create job: %w", err)
+		}
+		if err := s.recordRevision(ctx, job.ID, models.JobRevisionCreated); err != nil {
+			return err
+		}
+		if req.Canary {
+			if _, err := s.scheduler.RunCanary(ctx, job); err != nil {
+				return fmt.Errorf("failed to start canary execution: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// endpointCheckTimeout bounds how long validation waits to connect to a job's endpoint
+const endpointCheckTimeout = 5 * time.Second
+
+// Validate runs every check of a create request without creating the job, reporting each
+// problem with the field it concerns. The admission webhooks review the request as a dry run,
+// and a rejection is reported as a problem. With checkEndpoint, an http job's endpoint must
+// also accept a connection, within the tenant's egress rules and rate limit. An error is only returned when the checks themselves fail.
+func (s *JobService) Validate(ctx context.Context, tenantID uuid.UUID, req *models.CreateJobRequest, checkEndpoint bool) (*models.JobValidation, error) {
+	validation := &models.JobValidation{Errors: []models.FieldError{}}
+	var denied *ValidationError
+	if err := s.admission.Review(ctx, tenantID, models.AdmissionCreate, true, req, nil); errors.As(err, &denied) {
+		validation.Errors = append(validation.Errors, denied.Fields...)
+	} else if err != nil {
+		return nil, err
+	}
+
+	job, problems, err := s.build(ctx, tenantID, req)
+	if err != nil {
 		return nil, err
 	}
 
+	endpointValid := true
+	if problems != nil {
+		validation.Errors = append(validation.Errors, problems.Fields...)
+		for _, field := range problems.Fields {
+			if field.Field == "endpoint" {
+				endpointValid = false
+			}
+		}
+	}
+	if checkEndpoint && endpointValid && req.Endpoint != "" && (req.TargetType == "" || req.TargetType == models.TargetHTTP) {
+		if err := s.scheduler.CheckEndpoint(ctx, tenantID, req.Endpoint, endpointCheckTimeout); err != nil {
+			validation.Errors = append(validation.Errors, models.FieldError{Field: "endpoint", Message: err.Error()})
+		}
+	}
+
+	validation.Valid = len(validation.Errors) == 0
+	if job != nil {
+		validation.NextRunAt = job.NextRunAt
+	}
+	return validation, nil
+}
+
+// build validates a create request and builds the job it describes, with its next run. Every
+// check runs, so all of the request's problems are returned in a ValidationError; the job is
+// only returned when there are none. The error reports a check that couldn't run, such as a
+// calendar that couldn't be loaded.
+func (s *JobService) build(ctx context.Context, tenantID uuid.UUID, req *models.CreateJobRequest) (*models.Job, *ValidationError, error) {
+	problems := checkTags(req)
+	// A field is reported once, with its first problem
+	check := func(field string, err error) {
+		if err != nil && !problems.has(field) {
+			problems.add(field, err)
+		}
+	}
+	var failure error
+	checkCalendar := func(field string, err error) {
+		if err != nil && !errors.Is(err, ErrInvalidCalendar) {
+			// The calendar couldn't be loaded
+			failure = err
+			return
+		}
+		check(field, err)
+	}
+
+	// Validate job type and schedule
+	scheduleField := "schedule"
+	switch req.Type {
+	case models.JobTypeCron, models.JobTypeInterval, models.JobTypeOneTime:
+	default:
+		scheduleField = "type"
+	}
+	check(scheduleField, s.validateSchedule(req.Type, req.Schedule))
+
+	// Validate response projection
+	check("response_projection", scheduler.ValidateProjection(req.ResponseProjection))
+
+	// Validate concurrency policy
+	concurrencyPolicy := req.ConcurrencyPolicy
+	if concurrencyPolicy == "" {
+		concurrencyPolicy = models.ConcurrencyAllow
+	}
+	check("concurrency_policy", validateConcurrencyPolicy(concurrencyPolicy))
+
+	// Validate misfire policy
+	misfirePolicy := req.MisfirePolicy
+	if misfirePolicy == "" {
+		misfirePolicy = models.MisfireFireOnce
+	}
+	check("misfire_policy", validateMisfirePolicy(misfirePolicy))
+
+	check("worker_group", validateWorkerGroup(req.WorkerGroup))
+	check("correlation_id", validateCorrelationID(req.CorrelationID))
+	check("external_id", validateExternalID(req.ExternalID))
+
+	check(lifetimeField(req), validateLifetime(req.Type, req.StartAt, req.EndAt, req.MaxRuns))
+	if req.EndAt != nil && !req.EndAt.After(time.Now()) {
+		check("end_at", fmt.Errorf("%w: end_at must be in the future", ErrInvalidLifetime))
+	}
+
+	triggerField := "trigger_limit"
+	if req.TriggerLimit == nil || *req.TriggerLimit >= 0 {
+		triggerField = "trigger_dedupe_seconds"
+	}
+	check(triggerField, validateTriggerLimits(req.TriggerLimit, req.TriggerDedupe))
+
+	_, err := s.calendars.validateBlackout(ctx, tenantID, req.BlackoutCalendar, nil)
+	checkCalendar("blackout_calendar", err)
+	var blackoutWindows json.RawMessage
+	if len(req.BlackoutWindows) > 0 {
+		blackoutWindows, err = marshalBlackoutWindows(req.BlackoutWindows)
+		check("blackout_windows", err)
+	}
+	checkCalendar("holiday_calendar", s.calendars.validateHoliday(ctx, tenantID, req.Type, req.HolidayCalendar))
+
 	// Parse headers
 	var headers json.RawMessage
 	if req.Headers != nil {
 		h, _ := json.Marshal(req.Headers)
 		headers = h
 	}
+	check("headers", validateHeaders(headers))
 
 	// Parse payload
 	var payload json.RawMessage
@@ -62,17 +324,51 @@ func (s *JobService) Create(ctx context.Context, tenantID uuid.UUID, req *models
 		metadata = m
 	}
 
+	targetType := req.TargetType
+	if targetType == "" {
+		targetType = models.TargetHTTP
+	}
+	check(targetField(targetType), validateTarget(targetType, req.TargetConfig, req.Endpoint, metadata))
+	check(targetField(targetType), s.checkEgress(ctx, tenantID, targetType, req.TargetConfig, req.Endpoint, metadata))
+	check(targetField(targetType), s.checkTargetAccess(ctx, tenantID, targetType, req.TargetConfig, metadata))
+	check(targetField(targetType), s.checkStorage(ctx, tenantID, &models.Job{TargetType: targetType, TargetConfig: req.TargetConfig}))
+
+	check("payload", validateBody(targetType, req.ContentType, payload, req.Body, req.BodyEncoding))
+	check("payload", s.checkStorage(ctx, tenantID, &models.Job{ContentType: req.ContentType, Payload: payload}))
+
+	check("response_sink", validateResponseSink(targetType, req.ResponseSink, req.ResponseProjection))
+	check("response_sink", s.checkStorage(ctx, tenantID, &models.Job{ResponseSink: req.ResponseSink}))
+	check("tls", validateTLS(targetType, req.TLS))
+	check("tls", s.checkTLS(ctx, tenantID, req.TLS))
+
+	// Enforce the tenant's size limits
+	check("payload", s.checkSize(ctx, tenantID, payload, req.Body, headers))
+
 	// Set defaults
 	timeout := req.Timeout
 	if timeout == 0 {
 		timeout = 30
 	}
+	check("timeout", s.validateTimeout(timeout))
+
+	if failure != nil {
+		return nil, nil, failure
+	}
+	if len(problems.Fields) > 0 {
+		return nil, problems, nil
+	}
 
 	maxRetries := req.MaxRetries
 	if maxRetries == 0 {
 		maxRetries = 3
 	}
 
+	// A job created with a canary holds its schedule until the canary passes or is confirmed
+	status := models.JobStatusActive
+	if req.Canary {
+		status = models.JobStatusCanary
+	}
+
 	priority := req.Priority
 	if priority == 0 {
 		priority = 5
@@ -84,43 +380,77 @@ func (s *JobService) Create(ctx context.Context, tenantID uuid.UUID, req *models
 	}
 
 	job := &models.Job{
-		ID:          uuid.New(),
-		TenantID:    tenantID,
-		Name:        req.Name,
-		Description: req.Description,
-		Type:        req.Type,
-		Status:      models.JobStatusActive,
-		Schedule:    req.Schedule,
-		Timezone:    req.Timezone,
-		Endpoint:    req.Endpoint,
-		Method:      method,
-		Headers:     headers,
-		Payload:     payload,
-		Timeout:     timeout,
-		MaxRetries:  maxRetries,
-		Priority:    priority,
-		Tags:        req.Tags,
-		Metadata:    metadata,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:                 uuid.New(),
+		TenantID:           tenantID,
+		CorrelationID:      req.CorrelationID,
+		Name:               req.Name,
+		Description:        req.Description,
+		Type:               req.Type,
+		Status:             status,
+		Schedule:           req.Schedule,
+		Timezone:           req.Timezone,
+		TargetType:         targetType,
+		TargetConfig:       req.TargetConfig,
+		Endpoint:           req.Endpoint,
+		Method:             method,
+		Headers:            headers,
+		Payload:            payload,
+		ContentType:        req.ContentType,
+		BodyEncoding:       req.BodyEncoding,
+		Body:               req.Body,
+		Timeout:            timeout,
+		MaxRetries:         maxRetries,
+		RetryDelay:         req.RetryDelay,
+		Priority:           priority,
+		Tags:               req.Tags,
+		Metadata:           metadata,
+		ResponseProjection: req.ResponseProjection,
+		ResponseSink:       req.ResponseSink,
+		TLS:                req.TLS,
+		ConcurrencyPolicy:  concurrencyPolicy,
+		MisfirePolicy:      misfirePolicy,
+		WorkerGroup:        req.WorkerGroup,
+		BlackoutCalendar:   req.BlackoutCalendar,
+		BlackoutWindows:    blackoutWindows,
+		HolidayCalendar:    req.HolidayCalendar,
+		StartAt:            req.StartAt,
+		EndAt:              req.EndAt,
+		MaxRuns:            req.MaxRuns,
+		TriggerLimit:       req.TriggerLimit,
+		TriggerDedupe:      req.TriggerDedupe,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+	if req.ClientReference != "" {
+		clientReference := req.ClientReference
+		job.ClientReference = &clientReference
+	}
+	if req.ExternalID != "" {
+		externalID := req.ExternalID
+		job.ExternalID = &externalID
 	}
 
 	// Calculate next run time
-	nextRunAt, err := s.calculateNextRun(job)
+	nextRunAt, err := s.calculateNextRun(ctx, job)
 	if err == nil && nextRunAt != nil {
 		job.NextRunAt = nextRunAt
 	}
 
-	if err := s.jobRepo.Create(ctx, job); err != nil {
-		return nil, fmt.Errorf("failed to create job: %w", err)
-	}
-
-	return job, nil
+	return job, nil, nil
 }
 
 // GetByID retrieves a job by ID
 func (s *JobService) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*models.Job, error) {
-	return s.jobRepo.FindByTenantAndID(ctx, tenantID, id)
+	return s.find(ctx, tenantID, id)
+}
+
+// find loads a tenant's job, reporting a missing one as ErrNotFound
+func (s *JobService) find(ctx context.Context, tenantID, id uuid.UUID) (*models.Job, error) {
+	job, err := s.jobRepo.FindByTenantAndID(ctx, tenantID, id)
+	if err != nil {
+		return nil, notFound(err, "job")
+	}
+	return job, nil
 }
 
 // List lists jobs with filtering
@@ -128,12 +458,59 @@ func (s *JobService) List(ctx context.Context, filter models.JobFilter) (*models
 	return s.jobRepo.Query(ctx, filter)
 }
 
+// Embed attaches the requested related resources to a page of jobs, loading each kind in one query
+func (s *JobService) Embed(ctx context.Context, tenantID uuid.UUID, jobs []models.Job, include []string) ([]models.JobView, error) {
+	views := make([]models.JobView, len(jobs))
+	jobIDs := make([]uuid.UUID, len(jobs))
+	for i, job := range jobs {
+		views[i].Job = job
+		jobIDs[i] = job.ID
+	}
+
+	for _, name := range include {
+		switch name {
+		case models.JobIncludeLastExecution:
+			latest, err := s.executionRepo.FindLatestByJobIDs(ctx, tenantID, jobIDs)
+			if err != nil {
+				return nil, err
+			}
+			for i := range views {
+				if execution, ok := latest[views[i].ID]; ok {
+					views[i].LastExecution = &execution
+				}
+			}
+
+		case models.JobIncludeHistory7d:
+			now := time.Now()
+			statsByJob, err := s.historyRepo.GetAggregatedStatsByJob(ctx, tenantID, jobIDs, now.AddDate(0, 0, -7), now)
+			if err != nil {
+				return nil, err
+			}
+			for i := range views {
+				stats, ok := statsByJob[views[i].ID]
+				if !ok {
+					stats = &models.AggregatedHistoryStats{}
+				}
+				views[i].History7d = stats
+			}
+		}
+	}
+
+	return views, nil
+}
+
 // Update updates a job
 func (s *JobService) Update(ctx context.Context, tenantID, id uuid.UUID, req *models.UpdateJobRequest) (*models.Job, error) {
-	job, err := s.jobRepo.FindByTenantAndID(ctx, tenantID, id)
+	job, err := s.find(ctx, tenantID, id)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.admission.Review(ctx, tenantID, models.AdmissionUpdate, false, req, job); err != nil {
+		return nil, err
+	}
+	if problems := checkTags(req); len(problems.Fields) > 0 {
+		return nil, problems
+	}
 
 	// Update fields
 	if req.Name != nil && *req.Name != "" {
@@ -142,12 +519,24 @@ func (s *JobService) Update(ctx context.Context, tenantID, id uuid.UUID, req *mo
 	if req.Description != nil && *req.Description != "" {
 		job.Description = *req.Description
 	}
+	if req.CorrelationID != nil {
+		if err := validateCorrelationID(*req.CorrelationID); err != nil {
+			return nil, invalidField("correlation_id", err)
+		}
+		job.CorrelationID = *req.CorrelationID
+	}
 	if req.Schedule != nil && *req.Schedule != "" {
 		if err := s.validateSchedule(job.Type, *req.Schedule); err != nil {
-			return nil, err
+			return nil, invalidField("schedule", err)
 		}
 		job.Schedule = *req.Schedule
 	}
+	if req.TargetType != nil && *req.TargetType != "" {
+		job.TargetType = *req.TargetType
+	}
+	if req.TargetConfig != nil {
+		job.TargetConfig = *req.TargetConfig
+	}
 	if req.Endpoint != nil && *req.Endpoint != "" {
 		job.Endpoint = *req.Endpoint
 	}
@@ -160,7 +549,19 @@ func (s *JobService) Update(ctx context.Context, tenantID, id uuid.UUID, req *mo
 	if req.Payload != nil {
 		job.Payload = *req.Payload
 	}
-	if req.Timeout != nil && *req.Timeout > 0 {
+	if req.ContentType != nil {
+		job.ContentType = *req.ContentType
+	}
+	if req.BodyEncoding != nil {
+		job.BodyEncoding = *req.BodyEncoding
+	}
+	if req.Body != nil {
+		job.Body = *req.Body
+	}
+	if req.Timeout != nil && *req.Timeout != 0 {
+		if err := s.validateTimeout(*req.Timeout); err != nil {
+			return nil, invalidField("timeout", err)
+		}
 		job.Timeout = *req.Timeout
 	}
 	if req.MaxRetries != nil && *req.MaxRetries > 0 {
@@ -172,12 +573,155 @@ func (s *JobService) Update(ctx context.Context, tenantID, id uuid.UUID, req *mo
 	if req.Tags != nil {
 		job.Tags = *req.Tags
 	}
+	if req.ResponseProjection != nil {
+		if err := scheduler.ValidateProjection(*req.ResponseProjection); err != nil {
+			return nil, invalidField("response_projection", err)
+		}
+		job.ResponseProjection = *req.ResponseProjection
+	}
+	if req.ResponseSink != nil {
+		job.ResponseSink = *req.ResponseSink
+	}
+	if req.TLS != nil {
+		job.TLS = *req.TLS
+	}
+	if req.ConcurrencyPolicy != nil {
+		if err := validateConcurrencyPolicy(*req.ConcurrencyPolicy); err != nil {
+			return nil, invalidField("concurrency_policy", err)
+		}
+		job.ConcurrencyPolicy = *req.ConcurrencyPolicy
+	}
+	if req.MisfirePolicy != nil {
+		if err := validateMisfirePolicy(*req.MisfirePolicy); err != nil {
+			return nil, invalidField("misfire_policy", err)
+		}
+		job.MisfirePolicy = *req.MisfirePolicy
+	}
+	if req.WorkerGroup != nil {
+		if err := validateWorkerGroup(*req.WorkerGroup); err != nil {
+			return nil, invalidField("worker_group", err)
+		}
+		job.WorkerGroup = *req.WorkerGroup
+	}
+	if req.BlackoutCalendar != nil || req.BlackoutWindows != nil {
+		calendar, windows := job.BlackoutCalendar, job.BlackoutWindowList()
+		if req.BlackoutCalendar != nil {
+			calendar = *req.BlackoutCalendar
+		}
+		if req.BlackoutWindows != nil {
+			windows = *req.BlackoutWindows
+		}
+		if _, err := s.calendars.validateBlackout(ctx, tenantID, calendar, nil); err != nil {
+			return nil, calendarProblem("blackout_calendar", err)
+		}
+		var stored json.RawMessage
+		if len(windows) > 0 {
+			if stored, err = marshalBlackoutWindows(windows); err != nil {
+				return nil, invalidField("blackout_windows", err)
+			}
+		}
+		job.BlackoutCalendar, job.BlackoutWindows = calendar, stored
+	}
+	if req.HolidayCalendar != nil {
+		if err := s.calendars.validateHoliday(ctx, tenantID, job.Type, *req.HolidayCalendar); err != nil {
+			return nil, calendarProblem("holiday_calendar", err)
+		}
+		job.HolidayCalendar = *req.HolidayCalendar
+	}
+	if req.StartAt != nil {
+		if job.StartAt, err = parseLifetimeTime("start_at", *req.StartAt); err != nil {
+			return nil, invalidField("start_at", err)
+		}
+	}
+	if req.EndAt != nil {
+		if job.EndAt, err = parseLifetimeTime("end_at", *req.EndAt); err != nil {
+			return nil, invalidField("end_at", err)
+		}
+		if job.EndAt != nil && !job.EndAt.After(time.Now()) {
+			return nil, invalidField("end_at", fmt.Errorf("%w: end_at must be in the future", ErrInvalidLifetime))
+		}
+	}
+	if req.MaxRuns != nil {
+		job.MaxRuns = *req.MaxRuns
+	}
+	if req.StartAt != nil || req.EndAt != nil || req.MaxRuns != nil {
+		if err := validateLifetime(job.Type, job.StartAt, job.EndAt, job.MaxRuns); err != nil {
+			field := "max_runs"
+			switch {
+			case job.MaxRuns < 0:
+			case req.StartAt != nil:
+				field = "start_at"
+			case req.EndAt != nil:
+				field = "end_at"
+			}
+			return nil, invalidField(field, err)
+		}
+	}
+	if req.TriggerLimit != nil || req.TriggerDedupe != nil {
+		if err := validateTriggerLimits(req.TriggerLimit, req.TriggerDedupe); err != nil {
+			field := "trigger_dedupe_seconds"
+			if req.TriggerLimit != nil && *req.TriggerLimit < 0 {
+				field = "trigger_limit"
+			}
+			return nil, invalidField(field, err)
+		}
+		if req.TriggerLimit != nil {
+			job.TriggerLimit = req.TriggerLimit
+		}
+		if req.TriggerDedupe != nil {
+			job.TriggerDedupe = req.TriggerDedupe
+		}
+	}
+
+	if req.TargetType != nil || req.TargetConfig != nil || req.Endpoint != nil {
+		if err := validateTarget(job.TargetType, job.TargetConfig, job.Endpoint, job.Metadata); err != nil {
+			return nil, invalidField(targetField(job.TargetType), err)
+		}
+		if err := s.checkEgress(ctx, tenantID, job.TargetType, job.TargetConfig, job.Endpoint, job.Metadata); err != nil {
+			return nil, invalidField(targetField(job.TargetType), err)
+		}
+		if err := s.checkTargetAccess(ctx, tenantID, job.TargetType, job.TargetConfig, job.Metadata); err != nil {
+			return nil, invalidField(targetField(job.TargetType), err)
+		}
+		if err := s.checkStorage(ctx, tenantID, &models.Job{TargetType: job.TargetType, TargetConfig: job.TargetConfig}); err != nil {
+			return nil, invalidField(targetField(job.TargetType), err)
+		}
+	}
+	if req.Payload != nil || req.ContentType != nil || req.Body != nil || req.BodyEncoding != nil || req.TargetType != nil {
+		if err := validateBody(job.TargetType, job.ContentType, job.Payload, job.Body, job.BodyEncoding); err != nil {
+			return nil, invalidField("payload", err)
+		}
+		if err := s.checkStorage(ctx, tenantID, &models.Job{ContentType: job.ContentType, Payload: job.Payload}); err != nil {
+			return nil, invalidField("payload", err)
+		}
+	}
+	if req.ResponseSink != nil || req.ResponseProjection != nil || req.TargetType != nil {
+		if err := validateResponseSink(job.TargetType, job.ResponseSink, job.ResponseProjection); err != nil {
+			return nil, invalidField("response_sink", err)
+		}
+		if err := s.checkStorage(ctx, tenantID, &models.Job{ResponseSink: job.ResponseSink}); err != nil {
+			return nil, invalidField("response_sink", err)
+		}
+	}
+	if req.TLS != nil || req.TargetType != nil {
+		if err := validateTLS(job.TargetType, job.TLS); err != nil {
+			return nil, invalidField("tls", err)
+		}
+		if err := s.checkTLS(ctx, tenantID, job.TLS); err != nil {
+			return nil, invalidField("tls", err)
+		}
+	}
+	if req.Headers != nil || req.Payload != nil || req.Body != nil {
+		if err := s.checkSize(ctx, tenantID, job.Payload, job.Body, job.Headers); err != nil {
+			return nil, invalidField("payload", err)
+		}
+	}
 
 	job.UpdatedAt = time.Now()
 
-	// Recalculate next run time if schedule changed
-	if req.Schedule != nil && *req.Schedule != "" {
-		nextRunAt, err := s.calculateNextRun(job)
+	// Recalculate next run time if schedule, holidays, start or end time changed
+	if (req.Schedule != nil && *req.Schedule != "") || req.HolidayCalendar != nil || req.StartAt != nil || req.EndAt != nil {
+		nextRunAt, err := s.calculateNextRun(ctx, job)
 		if err == nil && nextRunAt != nil {
 			job.NextRunAt = nextRunAt
 		}
@@ -186,80 +730,1265 @@ func (s *JobService) Update(ctx context.Context, tenantID, id uuid.UUID, req *mo
 	if err := s.jobRepo.Update(ctx, job); err != nil {
 		return nil, fmt.Errorf("failed to update job: %w", err)
 	}
+	if err := s.recordRevision(ctx, job.ID, models.JobRevisionUpdated); err != nil {
+		return nil, err
+	}
 
 	return job, nil
 }
 
 // Delete soft-deletes a job
 func (s *JobService) Delete(ctx context.Context, tenantID, id uuid.UUID) error {
-	job, err := s.jobRepo.FindByTenantAndID(ctx, tenantID, id)
+	job, err := s.find(ctx, tenantID, id)
 	if err != nil {
 		return err
 	}
 
-	return s.jobRepo.Delete(ctx, job.ID)
+	if job.Status == models.JobStatusDeleted {
+		// Deleting again must not push the purge back
+		return nil
+	}
+
+	if err := s.jobRepo.Delete(ctx, job.ID, time.Now().Add(s.scheduler.PurgeGracePeriod())); err != nil {
+		return err
+	}
+	return s.recordRevision(ctx, job.ID, models.JobRevisionDeleted)
 }
 
-// Trigger manually triggers a job
-func (s *JobService) Trigger(ctx context.Context, tenantID, id uuid.UUID) (*models.JobExecution, error) {
-	job, err := s.jobRepo.FindByTenantAndID(ctx, tenantID, id)
+// Restore undeletes a job before it is purged. The job comes back paused, so it only runs
+// again once resumed.
+func (s *JobService) Restore(ctx context.Context, tenantID, id uuid.UUID) (*models.Job, error) {
+	job, err := s.find(ctx, tenantID, id)
 	if err != nil {
 		return nil, err
 	}
 
-	if job.Status != models.JobStatusActive && job.Status != models.JobStatusPaused {
-		return nil, fmt.Errorf("job cannot be triggered in status: %s", job.Status)
+	restored, err := s.jobRepo.Restore(ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, ErrJobNotDeleted
+	}
+	if err := s.recordRevision(ctx, job.ID, models.JobRevisionRestored); err != nil {
+		return nil, err
 	}
 
-	return s.scheduler.TriggerJob(ctx, job.ID)
+	return s.find(ctx, tenantID, id)
 }
 
-// UpdateStatus updates job status
-func (s *JobService) UpdateStatus(ctx context.Context, tenantID, id uuid.UUID, status models.JobStatus) (*models.Job, error) {
-	job, err := s.jobRepo.FindByTenantAndID(ctx, tenantID, id)
+// GetAsOf returns a job's definition as it was at the given time, with the revision number
+func (s *JobService) GetAsOf(ctx context.Context, tenantID, id uuid.UUID, at time.Time) (*models.Job, int, error) {
+	revision, err := s.revisionRepo.FindAsOf(ctx, tenantID, id, at)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var job models.Job
+	if err := json.Unmarshal(revision.Snapshot, &job); err != nil {
+		return nil, 0, fmt.Errorf("invalid job revision %d: %w", revision.Revision, err)
+	}
+	return &job, revision.Revision, nil
+}
+
+// ListRevisions lists a job's revisions, newest first
+func (s *JobService) ListRevisions(ctx context.Context, tenantID, id uuid.UUID, limit int) ([]models.JobRevision, error) {
+	if _, err := s.find(ctx, tenantID, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = revisionDefaultLimit
+	}
+	if limit > revisionMaxLimit {
+		limit = revisionMaxLimit
+	}
+	return s.revisionRepo.FindByJob(ctx, tenantID, id, limit)
+}
+
+// Rollback restores a job's definition as it was at an earlier revision and records the
+// result as a new revision, so the rollback itself can be rolled back. The job's status,
+// external ID and counters are kept.
+func (s *JobService) Rollback(ctx context.Context, tenantID, id uuid.UUID, number int) (*models.Job, error) {
+	job, err := s.find(ctx, tenantID, id)
 	if err != nil {
 		return nil, err
 	}
+	if job.Status == models.JobStatusDeleted {
+		return nil, ErrJobDeleted
+	}
 
-	job.Status = status
-	job.UpdatedAt = time.Now()
+	revision, err := s.revisionRepo.FindByRevision(ctx, tenantID, id, number)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snapshot models.Job
+	if err := json.Unmarshal(revision.Snapshot, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid job revision %d: %w", revision.Revision, err)
+	}
 
-	if err := s.jobRepo.Update(ctx, job); err != nil {
+	spec := jobSpec(&snapshot)
+	spec.Status = ""
+	if err := s.validateSpec(&spec); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRevisionNotRestorable, err)
+	}
+	if err := s.admitSpec(ctx, job, &spec); err != nil {
+		return nil, err
+	}
+	job.Name = spec.Name
+	if err := s.apply(ctx, job, &spec); err != nil {
+		if errors.Is(err, ErrInvalidCalendar) {
+			return nil, fmt.Errorf("%w: %v", ErrRevisionNotRestorable, err)
+		}
 		return nil, err
 	}
 
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to update job: %w", err)
+	}
+	if err := s.recordRevision(ctx, job.ID, models.JobRevisionRolledBack); err != nil {
+		return nil, err
+	}
 	return job, nil
 }
 
-// GetStats retrieves job statistics
-func (s *JobService) GetStats(ctx context.Context, tenantID *uuid.UUID) (*models.JobStats, error) {
-	return s.jobRepo.GetStats(ctx, tenantID)
+// Limits of schedule simulations
+const (
+	simulationDefaultDays    = 30
+	simulationMaxDays        = 366
+	simulationMaxOccurrences = 10000
+)
+
+// ErrInvalidSimulation is returned when a proposed schedule can't be simulated
+var ErrInvalidSimulation = invalid("invalid schedule simulation")
+
+// Limits of schedule previews
+const (
+	previewDefaultCount = 10
+	previewMaxCount     = 100
+	previewMaxYears     = 100 // How far ahead rare schedules are looked at
+)
+
+// ErrInvalidPreview is returned when a schedule can't be previewed
+var ErrInvalidPreview = invalid("invalid schedule preview")
+
+// PreviewSchedule lists the next fire times of a schedule that isn't saved yet, computed the
+// way the scheduler computes a new job's runs
+func (s *JobService) PreviewSchedule(ctx context.Context, tenantID uuid.UUID, req *models.SchedulePreviewRequest) (*models.SchedulePreview, error) {
+	jobType := req.Type
+	if jobType == "" {
+		jobType = models.JobTypeCron
+	}
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %s", ErrInvalidPreview, timezone)
+	}
+	count := req.Count
+	if count == 0 {
+		count = previewDefaultCount
+	}
+	if count < 1 || count > previewMaxCount {
+		return nil, fmt.Errorf("%w: count must be between 1 and %d", ErrInvalidPreview, previewMaxCount)
+	}
+	if err := s.validateSchedule(jobType, req.Schedule); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPreview, err)
+	}
+	if err := s.calendars.validateHoliday(ctx, tenantID, jobType, req.HolidayCalendar); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPreview, err)
+	}
+
+	job := &models.Job{
+		TenantID:        tenantID,
+		Type:            jobType,
+		Schedule:        req.Schedule,
+		Timezone:        timezone,
+		HolidayCalendar: req.HolidayCalendar,
+	}
+	runs, _, err := s.scheduler.Occurrences(ctx, job, time.Now().AddDate(previewMaxYears, 0, 0), count)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPreview, err)
+	}
+
+	preview := &models.SchedulePreview{
+		Type:     jobType,
+		Schedule: req.Schedule,
+		Timezone: timezone,
+		Runs:     make([]time.Time, 0, len(runs)),
+	}
+	for _, run := range runs {
+		preview.Runs = append(preview.Runs, run.In(loc))
+	}
+	return preview, nil
 }
 
-// validateSchedule validates the schedule based on job type
-func (s *JobService) validateSchedule(jobType models.JobType, schedule string) error {
-	switch jobType {
-	case models.JobTypeCron:
-		if _, err := s.cronParser.Parse(schedule); err != nil {
-			return fmt.Errorf("invalid cron expression: %w", err)
-		}
-	case models.JobTypeInterval:
-		var interval int
-		if err := json.Unmarshal([]byte(schedule), &interval); err != nil {
-			return fmt.Errorf("invalid interval (should be seconds as integer): %w", err)
-		}
-		if interval < 1 {
-			return fmt.Errorf("interval must be at least 1 second")
-		}
-	case models.JobTypeOneTime:
-		// One-time jobs don't need schedule validation
+// SimulateSchedule compares a job's upcoming occurrences under its current schedule with those
+// under a proposed one, without changing the job. Occurrences missing from one schedule and
+// present in the other within half the current schedule's shortest gap count as moved.
+func (s *JobService) SimulateSchedule(ctx context.Context, tenantID, id uuid.UUID, req *models.SimulateScheduleRequest) (*models.ScheduleSimulation, error) {
+	job, err := s.find(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	days := req.Days
+	if days == 0 {
+		days = simulationDefaultDays
+	}
+	if days < 1 || days > simulationMaxDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidSimulation, simulationMaxDays)
+	}
+	if err := s.validateSchedule(job.Type, req.Schedule); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSimulation, err)
+	}
+
+	now := time.Now()
+	result := &models.ScheduleSimulation{From: now, Until: now.AddDate(0, 0, days)}
+
+	// Saving a new schedule recalculates the next run, so the proposal starts from scratch
+	proposedJob := *job
+	proposedJob.Schedule = req.Schedule
+	proposedJob.NextRunAt = nil
+
+	current, currentCut, err := s.scheduler.Occurrences(ctx, job, result.Until, simulationMaxOccurrences)
+	if err != nil {
+		return nil, err
+	}
+	proposed, proposedCut, err := s.scheduler.Occurrences(ctx, &proposedJob, result.Until, simulationMaxOccurrences)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSimulation, err)
+	}
+
+	// Compare only the window both schedules cover in full
+	if currentCut && current[len(current)-1].Before(result.Until) {
+		result.Until = current[len(current)-1]
+		result.Truncated = true
+	}
+	if proposedCut && proposed[len(proposed)-1].Before(result.Until) {
+		result.Until = proposed[len(proposed)-1]
+		result.Truncated = true
+	}
+	current = occurrencesBetween(current, now, result.Until)
+	proposed = occurrencesBetween(proposed, now, result.Until)
+	result.Current = len(current)
+	result.Proposed = len(proposed)
+
+	var removed, added []time.Time
+	i, j := 0, 0
+	for i < len(current) || j < len(proposed) {
+		switch {
+		case j == len(proposed) || (i < len(current) && current[i].Before(proposed[j])):
+			removed = append(removed, current[i])
+			i++
+		case i == len(current) || proposed[j].Before(current[i]):
+			added = append(added, proposed[j])
+			j++
+		default:
+			result.Unchanged++
+			i++
+			j++
+		}
+	}
+
+	window := 12 * time.Hour
+	for k := 1; k < len(current); k++ {
+		if gap := current[k].Sub(current[k-1]) / 2; gap < window {
+			window = gap
+		}
+	}
+
+	result.Added, result.Removed, result.Moved = []time.Time{}, []time.Time{}, []models.ScheduleMove{}
+	i, j = 0, 0
+	for i < len(removed) && j < len(added) {
+		distance := added[j].Sub(removed[i])
+		switch {
+		case distance.Abs() < window:
+			result.Moved = append(result.Moved, models.ScheduleMove{From: removed[i], To: added[j]})
+			i++
+			j++
+		case distance > 0:
+			result.Removed = append(result.Removed, removed[i])
+			i++
+		default:
+			result.Added = append(result.Added, added[j])
+			j++
+		}
+	}
+	result.Removed = append(result.Removed, removed[i:]...)
+	result.Added = append(result.Added, added[j:]...)
+
+	return result, nil
+}
+
+// occurrencesBetween keeps the occurrences of a sorted list from from to until. Overdue runs
+// of paused jobs are dropped.
+func occurrencesBetween(runs []time.Time, from, until time.Time) []time.Time {
+	kept := runs[:0]
+	for _, run := range runs {
+		if !run.Before(from) && !run.After(until) {
+			kept = append(kept, run)
+		}
+	}
+	return kept
+}
+
+// recordRevision snapshots a job as stored after a change
+func (s *JobService) recordRevision(ctx context.Context, id uuid.UUID, change models.JobRevisionChange) error {
+	job, err := s.jobRepo.FindByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to record job revision: %w", err)
+	}
+	snapshot, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to record job revision: %w", err)
+	}
+
+	if err := s.revisionRepo.Create(ctx, &models.JobRevision{
+		JobID:     job.ID,
+		TenantID:  job.TenantID,
+		Change:    change,
+		Snapshot:  snapshot,
+		ChangedBy: actorFrom(ctx),
+	}); err != nil {
+		return fmt.Errorf("failed to record job revision: %w", err)
+	}
+	return nil
+}
+
+// Trigger manually triggers a job. The execution carries correlationID, or the job's
+// correlation ID when it is empty. The boolean reports that an identical trigger's pending
+// execution was returned instead of creating one.
+func (s *JobService) Trigger(ctx context.Context, tenantID, id uuid.UUID, correlationID string) (*models.JobExecution, bool, error) {
+	if err := validateCorrelationID(correlationID); err != nil {
+		return nil, false, err
+	}
+
+	job, err := s.find(ctx, tenantID, id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if job.Status != models.JobStatusActive && job.Status != models.JobStatusPaused {
+		return nil, false, fmt.Errorf("%w in status: %s", ErrNotTriggerable, job.Status)
+	}
+	if s.tenantService.Paused(ctx, tenantID) {
+		return nil, false, ErrTenantPaused
+	}
+
+	return s.scheduler.TriggerJob(ctx, job.ID, correlationID)
+}
+
+// StartCanary holds a paused, disabled or held job's schedule and runs a canary execution of it
+// right away. The job is activated once the canary passes, or when ConfirmCanary is called.
+func (s *JobService) StartCanary(ctx context.Context, tenantID, id uuid.UUID) (*models.JobExecution, error) {
+	job, err := s.find(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch job.Status {
+	case models.JobStatusPaused, models.JobStatusDisabled, models.JobStatusCanary:
+	default:
+		return nil, fmt.Errorf("%w in status: %s", ErrCanaryNotAllowed, job.Status)
+	}
+	if s.tenantService.Paused(ctx, tenantID) {
+		return nil, ErrTenantPaused
+	}
+
+	var execution *models.JobExecution
+	err = s.jobRepo.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if execution, err = s.scheduler.RunCanary(ctx, job); err != nil {
+			return err
+		}
+		return s.recordRevision(ctx, job.ID, models.JobRevisionCanary)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return execution, nil
+}
+
+// ConfirmCanary starts the schedule of a job held for its canary, whatever the canary's
+// outcome, e.g. after a failure the user has looked into and accepted
+func (s *JobService) ConfirmCanary(ctx context.Context, tenantID, id uuid.UUID) (*models.Job, error) {
+	job, err := s.find(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != models.JobStatusCanary {
+		return nil, fmt.Errorf("%w in status: %s", ErrNotHeldForCanary, job.Status)
+	}
+
+	activated, err := s.scheduler.ActivateCanary(ctx, job, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !activated {
+		return nil, fmt.Errorf("%w: it was changed meanwhile", ErrNotHeldForCanary)
+	}
+	if err := s.recordRevision(ctx, job.ID, models.JobRevisionActivated); err != nil {
+		return nil, err
+	}
+	return s.find(ctx, tenantID, id)
+}
+
+// UpdateStatus updates job status. A time the job was paused until no longer applies.
+func (s *JobService) UpdateStatus(ctx context.Context, tenantID, id uuid.UUID, status models.JobStatus) (*models.Job, error) {
+	return s.setStatus(ctx, tenantID, id, status, nil)
+}
+
+// Pause pauses a job. With resumeAt set, the job resumes on its own at that time; pausing a
+// paused job again replaces the time.
+func (s *JobService) Pause(ctx context.Context, tenantID, id uuid.UUID, resumeAt *time.Time) (*models.Job, error) {
+	if resumeAt != nil && !resumeAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: resume_at must be in the future", ErrInvalidResumeAt)
+	}
+	return s.setStatus(ctx, tenantID, id, models.JobStatusPaused, resumeAt)
+}
+
+// PauseTenant suspends dispatch of all of a tenant's jobs, e.g. for a delinquent account or a
+// freeze the tenant asked for. Job definitions and schedules are kept, and executions already
+// handed to workers finish. Pausing a paused tenant again replaces the reason.
+func (s *JobService) PauseTenant(ctx context.Context, tenantID uuid.UUID, req *models.PauseTenantRequest) (*models.TenantPause, error) {
+	pausedAt := time.Now()
+	if current := s.tenantService.Settings(ctx, tenantID); current.PausedAt != nil {
+		pausedAt = *current.PausedAt
+	}
+	if err := s.tenantService.setPaused(ctx, tenantID, &pausedAt, req.Reason); err != nil {
+		return nil, err
+	}
+	return &models.TenantPause{TenantID: tenantID, Paused: true, PausedAt: &pausedAt, Reason: req.Reason}, nil
+}
+
+// ResumeTenant dispatches a paused tenant's jobs again. Jobs whose next run passed while the
+// tenant was paused have it recalculated from now, so they resume on their schedule instead
+// of firing for every missed run.
+func (s *JobService) ResumeTenant(ctx context.Context, tenantID uuid.UUID) (*models.TenantPause, error) {
+	result := &models.TenantPause{TenantID: tenantID}
+	if !s.tenantService.Paused(ctx, tenantID) {
+		// Overdue jobs of a running tenant are about to be dispatched, not missed
+		return result, nil
+	}
+	err := s.jobRepo.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.tenantService.setPaused(ctx, tenantID, nil, ""); err != nil {
+			return err
+		}
+		overdue, err := s.jobRepo.FindOverdueByTenant(ctx, tenantID, time.Now())
+		if err != nil {
+			return err
+		}
+		for i := range overdue {
+			nextRunAt, err := s.calculateNextRun(ctx, &overdue[i])
+			if err != nil || nextRunAt == nil {
+				// The dispatcher ends or skips jobs that have no next run
+				continue
+			}
+			if err := s.jobRepo.UpdateNextRunAt(ctx, overdue[i].ID, *nextRunAt); err != nil {
+				return err
+			}
+			result.Rescheduled++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// setStatus updates a job's status and the time it is paused until
+func (s *JobService) setStatus(ctx context.Context, tenantID, id uuid.UUID, status models.JobStatus, resumeAt *time.Time) (*models.Job, error) {
+	job, err := s.find(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if resumeAt != nil && job.Status != models.JobStatusActive && job.Status != models.JobStatusPaused {
+		return nil, fmt.Errorf("%w: a job in status %s can't be paused until a time", ErrInvalidResumeAt, job.Status)
+	}
+
+	job.Status = status
+	job.ResumeAt = resumeAt
+	job.UpdatedAt = time.Now()
+
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		return nil, err
+	}
+
+	change := models.JobRevisionUpdated
+	switch status {
+	case models.JobStatusPaused:
+		change = models.JobRevisionPaused
+	case models.JobStatusActive:
+		change = models.JobRevisionResumed
+	}
+	if err := s.recordRevision(ctx, job.ID, change); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// GetStats retrieves job statistics
+func (s *JobService) GetStats(ctx context.Context, tenantID *uuid.UUID) (*models.JobStats, error) {
+	return s.jobRepo.GetStats(ctx, tenantID)
+}
+
+// StaleReport lists the tenant's jobs that look dead and may be ready to clean up
+func (s *JobService) StaleReport(ctx context.Context, tenantID uuid.UUID) (*models.JanitorReport, error) {
+	return s.scheduler.JanitorReport(ctx, tenantID)
+}
+
+// Export serializes all of a tenant's jobs into a portable document
+func (s *JobService) Export(ctx context.Context, tenantID uuid.UUID) (*models.JobExport, error) {
+	jobs, err := s.jobRepo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &models.JobExport{
+		Version:    models.JobExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Jobs:       make([]models.JobSpec, 0, len(jobs)),
+	}
+
+	for i := range jobs {
+		export.Jobs = append(export.Jobs, jobSpec(&jobs[i]))
+	}
+
+	return export, nil
+}
+
+// jobSpec returns a job's portable definition, as exported
+func jobSpec(job *models.Job) models.JobSpec {
+	spec := models.JobSpec{
+		CreateJobRequest: models.CreateJobRequest{
+			CorrelationID:      job.CorrelationID,
+			Name:               job.Name,
+			Description:        job.Description,
+			Type:               job.Type,
+			Schedule:           job.Schedule,
+			Timezone:           job.Timezone,
+			TargetType:         job.TargetType,
+			TargetConfig:       job.TargetConfig,
+			Endpoint:           job.Endpoint,
+			Method:             job.Method,
+			Headers:            job.Headers,
+			Payload:            job.Payload,
+			ContentType:        job.ContentType,
+			BodyEncoding:       job.BodyEncoding,
+			Body:               job.Body,
+			Timeout:            job.Timeout,
+			MaxRetries:         job.MaxRetries,
+			RetryDelay:         job.RetryDelay,
+			Priority:           job.Priority,
+			Tags:               job.Tags,
+			Metadata:           job.Metadata,
+			ResponseProjection: job.ResponseProjection,
+			ResponseSink:       job.ResponseSink,
+			TLS:                job.TLS,
+			ConcurrencyPolicy:  job.ConcurrencyPolicy,
+			MisfirePolicy:      job.MisfirePolicy,
+			WorkerGroup:        job.WorkerGroup,
+			BlackoutCalendar:   job.BlackoutCalendar,
+			BlackoutWindows:    job.BlackoutWindowList(),
+			HolidayCalendar:    job.HolidayCalendar,
+			StartAt:            job.StartAt,
+			EndAt:              job.EndAt,
+			MaxRuns:            job.MaxRuns,
+			TriggerLimit:       job.TriggerLimit,
+			TriggerDedupe:      job.TriggerDedupe,
+		},
+		Status: job.Status,
+	}
+	if job.Status == models.JobStatusCanary {
+		// Held jobs are exported as paused rather than activated by an import
+		spec.Status = models.JobStatusPaused
+	}
+
+	if job.ExternalID != nil {
+		spec.ExternalID = *job.ExternalID
+	}
+	return spec
+}
+
+// Import recreates jobs from an export document. Every job is validated before any is written,
+// and jobs whose name already exists are handled according to onConflict.
+func (s *JobService) Import(ctx context.Context, tenantID uuid.UUID, doc *models.JobExport, onConflict string) (*models.JobImportResult, error) {
+	if doc.Version > models.JobExportVersion {
+		return nil, fmt.Errorf("unsupported export version: %d", doc.Version)
+	}
+
+	switch onConflict {
+	case "":
+		onConflict = models.ImportConflictSkip
+	case models.ImportConflictSkip, models.ImportConflictUpdate, models.ImportConflictCreate:
+	default:
+		return nil, fmt.Errorf("invalid on_conflict mode: %s", onConflict)
+	}
+
+	result := &models.JobImportResult{}
+
+	for i := range doc.Jobs {
+		err := s.validateSpec(&doc.Jobs[i])
+		if err == nil {
+			err = s.checkSize(ctx, tenantID, doc.Jobs[i].Payload, doc.Jobs[i].Body, doc.Jobs[i].Headers)
+		}
+		if err == nil {
+			err = s.checkTLS(ctx, tenantID, doc.Jobs[i].TLS)
+		}
+		if err == nil {
+			err = s.checkEgress(ctx, tenantID, doc.Jobs[i].TargetType, doc.Jobs[i].TargetConfig, doc.Jobs[i].Endpoint, doc.Jobs[i].Metadata)
+		}
+		if err == nil {
+			err = s.checkTargetAccess(ctx, tenantID, doc.Jobs[i].TargetType, doc.Jobs[i].TargetConfig, doc.Jobs[i].Metadata)
+		}
+		if err == nil {
+			err = s.checkStorage(ctx, tenantID, &models.Job{
+				TargetType:   doc.Jobs[i].TargetType,
+				TargetConfig: doc.Jobs[i].TargetConfig,
+				ResponseSink: doc.Jobs[i].ResponseSink,
+				ContentType:  doc.Jobs[i].ContentType,
+				Payload:      doc.Jobs[i].Payload,
+			})
+		}
+		if err == nil {
+			_, err = s.calendars.validateBlackout(ctx, tenantID, doc.Jobs[i].BlackoutCalendar, doc.Jobs[i].BlackoutWindows)
+		}
+		if err == nil {
+			err = s.calendars.validateHoliday(ctx, tenantID, doc.Jobs[i].Type, doc.Jobs[i].HolidayCalendar)
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, models.JobImportError{Index: i, Name: doc.Jobs[i].Name, Error: err.Error()})
+		}
+	}
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	for i := range doc.Jobs {
+		spec := &doc.Jobs[i]
+
+		if onConflict != models.ImportConflictCreate {
+			existing, err := s.jobRepo.FindByTenantAndName(ctx, tenantID, spec.Name)
+			if err == nil {
+				if onConflict == models.ImportConflictSkip {
+					result.Skipped++
+					continue
+				}
+				err := s.overwrite(ctx, existing, spec)
+				if err == nil {
+					err = s.recordRevision(ctx, existing.ID, models.JobRevisionImported)
+				}
+				if err != nil {
+					result.Errors = append(result.Errors, models.JobImportError{Index: i, Name: spec.Name, Error: err.Error()})
+					continue
+				}
+				result.Updated++
+				continue
+			}
+		}
+
+		job, err := s.Create(ctx, tenantID, &spec.CreateJobRequest)
+		if err != nil {
+			result.Errors = append(result.Errors, models.JobImportError{Index: i, Name: spec.Name, Error: err.Error()})
+			continue
+		}
+		if spec.Status == models.JobStatusPaused || spec.Status == models.JobStatusDisabled || spec.Status == models.JobStatusCompleted {
+			s.jobRepo.UpdateStatus(ctx, job.ID, spec.Status)
+			if err := s.recordRevision(ctx, job.ID, models.JobRevisionImported); err != nil {
+				result.Errors = append(result.Errors, models.JobImportError{Index: i, Name: spec.Name, Error: err.Error()})
+				continue
+			}
+		}
+		result.Created++
+	}
+
+	return result, nil
+}
+
+// BatchUpsert creates or updates many jobs keyed by external_id in one transaction. Every job
+// is validated before any is written; jobs whose definition already matches are left alone,
+// and a failure writing any job rolls the whole batch back.
+func (s *JobService) BatchUpsert(ctx context.Context, tenantID uuid.UUID, req *models.BatchUpsertJobsRequest) (*models.BatchUpsertResult, error) {
+	if len(req.Jobs) == 0 {
+		return nil, fmt.Errorf("%w: no jobs to upsert", ErrInvalidBatch)
+	}
+	if len(req.Jobs) > batchUpsertMaxJobs {
+		return nil, fmt.Errorf("%w: at most %d jobs can be upserted at once", ErrInvalidBatch, batchUpsertMaxJobs)
+	}
+
+	result := &models.BatchUpsertResult{}
+
+	externalIDs := make([]string, 0, len(req.Jobs))
+	seen := make(map[string]bool, len(req.Jobs))
+	for i := range req.Jobs {
+		spec := &req.Jobs[i]
+		var err error
+		switch {
+		case spec.ExternalID == "":
+			err = fmt.Errorf("external_id is required")
+		case seen[spec.ExternalID]:
+			err = fmt.Errorf("external_id %s appears more than once", spec.ExternalID)
+		default:
+			err = s.validateSpec(spec)
+		}
+		if err == nil {
+			err = s.checkSize(ctx, tenantID, spec.Payload, spec.Body, spec.Headers)
+		}
+		if err == nil {
+			err = s.checkTLS(ctx, tenantID, spec.TLS)
+		}
+		if err == nil {
+			err = s.checkEgress(ctx, tenantID, spec.TargetType, spec.TargetConfig, spec.Endpoint, spec.Metadata)
+		}
+		if err == nil {
+			err = s.checkTargetAccess(ctx, tenantID, spec.TargetType, spec.TargetConfig, spec.Metadata)
+		}
+		if err == nil {
+			err = s.checkStorage(ctx, tenantID, &models.Job{
+				TargetType:   spec.TargetType,
+				TargetConfig: spec.TargetConfig,
+				ResponseSink: spec.ResponseSink,
+				ContentType:  spec.ContentType,
+				Payload:      spec.Payload,
+			})
+		}
+		if err == nil {
+			_, err = s.calendars.validateBlackout(ctx, tenantID, spec.BlackoutCalendar, spec.BlackoutWindows)
+		}
+		if err == nil {
+			err = s.calendars.validateHoliday(ctx, tenantID, spec.Type, spec.HolidayCalendar)
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, models.BatchUpsertError{Index: i, ExternalID: spec.ExternalID, Error: err.Error()})
+		}
+		if spec.ExternalID != "" && !seen[spec.ExternalID] {
+			seen[spec.ExternalID] = true
+			externalIDs = append(externalIDs, spec.ExternalID)
+		}
+	}
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	jobs, err := s.jobRepo.FindByTenantAndExternalIDs(ctx, tenantID, externalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load jobs: %w", err)
+	}
+	existing := make(map[string]*models.Job, len(jobs))
+	for i := range jobs {
+		existing[*jobs[i].ExternalID] = &jobs[i]
+	}
+
+	items := make([]models.BatchUpsertItem, 0, len(req.Jobs))
+	err = s.jobRepo.Transaction(ctx, func(ctx context.Context) error {
+		for i := range req.Jobs {
+			spec := &req.Jobs[i]
+			item := models.BatchUpsertItem{Index: i, ExternalID: spec.ExternalID}
+
+			if job, ok := existing[spec.ExternalID]; ok {
+				item.JobID = job.ID
+				changed, err := s.upsertExisting(ctx, job, spec)
+				if err != nil {
+					return fmt.Errorf("jobs[%d] %s: %w", i, spec.ExternalID, err)
+				}
+				item.Result = models.UpsertUnchanged
+				if changed {
+					item.Result = models.UpsertUpdated
+				}
+				items = append(items, item)
+				continue
+			}
+
+			job, err := s.create(ctx, tenantID, &spec.CreateJobRequest)
+			if err == nil && (spec.Status == models.JobStatusPaused || spec.Status == models.JobStatusDisabled || spec.Status == models.JobStatusCompleted) {
+				err = s.jobRepo.UpdateStatus(ctx, job.ID, spec.Status)
+				if err == nil {
+					err = s.recordRevision(ctx, job.ID, models.JobRevisionSynced)
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("jobs[%d] %s: %w", i, spec.ExternalID, err)
+			}
+			item.JobID = job.ID
+			item.Result = models.UpsertCreated
+			items = append(items, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		switch item.Result {
+		case models.UpsertCreated:
+			result.Created++
+		case models.UpsertUpdated:
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+	}
+	result.Items = items
+	return result, nil
+}
+
+// upsertExisting applies a batch upsert's spec to a job matched by external ID, saving it and
+// recording a revision only when its definition changed
+func (s *JobService) upsertExisting(ctx context.Context, job *models.Job, spec *models.JobSpec) (bool, error) {
+	before, err := canonicalSpec(job)
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.admitSpec(ctx, job, spec); err != nil {
+		return false, err
+	}
+	job.Name = spec.Name
+	if err := s.apply(ctx, job, spec); err != nil {
+		return false, err
+	}
+
+	after, err := canonicalSpec(job)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(before, after) {
+		return false, nil
+	}
+
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		return false, fmt.Errorf("failed to update job: %w", err)
+	}
+	if err := s.recordRevision(ctx, job.ID, models.JobRevisionSynced); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// canonicalSpec encodes a job's definition so equal definitions encode to the same bytes,
+// whatever the key order or spacing of its JSON fields
+func canonicalSpec(job *models.Job) ([]byte, error) {
+	raw, err := json.Marshal(jobSpec(job))
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// validateSpec checks an imported job definition before anything is written
+func (s *JobService) validateSpec(spec *models.JobSpec) error {
+	if spec.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if err := validateTarget(spec.TargetType, spec.TargetConfig, spec.Endpoint, spec.Metadata); err != nil {
+		return err
+	}
+	if err := s.validateSchedule(spec.Type, spec.Schedule); err != nil {
+		return err
+	}
+	if err := scheduler.ValidateProjection(spec.ResponseProjection); err != nil {
+		return err
+	}
+	if err := validateBody(spec.TargetType, spec.ContentType, spec.Payload, spec.Body, spec.BodyEncoding); err != nil {
+		return err
+	}
+	if spec.Timeout != 0 {
+		if err := s.validateTimeout(spec.Timeout); err != nil {
+			return err
+		}
+	}
+	if err := validateResponseSink(spec.TargetType, spec.ResponseSink, spec.ResponseProjection); err != nil {
+		return err
+	}
+	if err := validateTLS(spec.TargetType, spec.TLS); err != nil {
+		return err
+	}
+	if spec.ConcurrencyPolicy != "" {
+		if err := validateConcurrencyPolicy(spec.ConcurrencyPolicy); err != nil {
+			return err
+		}
+	}
+	if spec.MisfirePolicy != "" {
+		if err := validateMisfirePolicy(spec.MisfirePolicy); err != nil {
+			return err
+		}
+	}
+	if err := validateWorkerGroup(spec.WorkerGroup); err != nil {
+		return err
+	}
+	if err := validateCorrelationID(spec.CorrelationID); err != nil {
+		return err
+	}
+	if err := validateExternalID(spec.ExternalID); err != nil {
+		return err
+	}
+	if err := validateLifetime(spec.Type, spec.StartAt, spec.EndAt, spec.MaxRuns); err != nil {
+		return err
+	}
+	if err := validateTriggerLimits(spec.TriggerLimit, spec.TriggerDedupe); err != nil {
+		return err
+	}
+	switch spec.Status {
+	case "", models.JobStatusActive, models.JobStatusPaused, models.JobStatusDisabled, models.JobStatusCompleted:
+		return nil
+	}
+	return fmt.Errorf("invalid status: %s", spec.Status)
+}
+
+// admitSpec sends a definition replacing an existing job's to the admission webhooks, then
+// checks it again in case they changed it
+func (s *JobService) admitSpec(ctx context.Context, job *models.Job, spec *models.JobSpec) error {
+	if err := s.admission.Review(ctx, job.TenantID, models.AdmissionUpdate, false, spec, job); err != nil {
+		return err
+	}
+	if err := s.validateSpec(spec); err != nil {
+		return invalidField("", fmt.Errorf("%w: definition as patched is invalid: %v", ErrAdmissionDenied, err))
+	}
+	return nil
+}
+
+// overwrite replaces an existing job's definition with an imported one, keeping its ID and counters
+func (s *JobService) overwrite(ctx context.Context, job *models.Job, spec *models.JobSpec) error {
+	if err := s.admitSpec(ctx, job, spec); err != nil {
+		return err
+	}
+	if err := s.apply(ctx, job, spec); err != nil {
+		return err
+	}
+	return s.jobRepo.Update(ctx, job)
+}
+
+// apply sets a job's definition from a spec without saving it, recalculating its next run
+func (s *JobService) apply(ctx context.Context, job *models.Job, spec *models.JobSpec) error {
+	blackoutWindows, err := s.calendars.validateBlackout(ctx, job.TenantID, spec.BlackoutCalendar, spec.BlackoutWindows)
+	if err != nil {
+		return err
+	}
+	if err := s.calendars.validateHoliday(ctx, job.TenantID, spec.Type, spec.HolidayCalendar); err != nil {
+		return err
+	}
+
+	job.CorrelationID = spec.CorrelationID
+	job.Description = spec.Description
+	job.Type = spec.Type
+	job.Schedule = spec.Schedule
+	job.Timezone = spec.Timezone
+	job.TargetType = spec.TargetType
+	if job.TargetType == "" {
+		job.TargetType = models.TargetHTTP
+	}
+	job.TargetConfig = spec.TargetConfig
+	job.Endpoint = spec.Endpoint
+	job.Headers = spec.Headers
+	job.Payload = spec.Payload
+	job.ContentType = spec.ContentType
+	job.BodyEncoding = spec.BodyEncoding
+	job.Body = spec.Body
+	job.Tags = spec.Tags
+	job.Metadata = spec.Metadata
+	job.ResponseProjection = spec.ResponseProjection
+	job.ResponseSink = spec.ResponseSink
+	job.TLS = spec.TLS
+	job.WorkerGroup = spec.WorkerGroup
+	job.BlackoutCalendar = spec.BlackoutCalendar
+	job.BlackoutWindows = blackoutWindows
+	job.HolidayCalendar = spec.HolidayCalendar
+	job.StartAt = spec.StartAt
+	job.EndAt = spec.EndAt
+	job.MaxRuns = spec.MaxRuns
+	job.TriggerLimit = spec.TriggerLimit
+	job.TriggerDedupe = spec.TriggerDedupe
+	if spec.Method != "" {
+		job.Method = spec.Method
+	}
+	if spec.Timeout > 0 {
+		job.Timeout = spec.Timeout
+	}
+	if spec.MaxRetries > 0 {
+		job.MaxRetries = spec.MaxRetries
+	}
+	if spec.RetryDelay > 0 {
+		job.RetryDelay = spec.RetryDelay
+	}
+	if spec.Priority > 0 {
+		job.Priority = spec.Priority
+	}
+	if spec.Status != "" {
+		job.Status = spec.Status
+		job.ResumeAt = nil
+	}
+	if spec.ConcurrencyPolicy != "" {
+		job.ConcurrencyPolicy = spec.ConcurrencyPolicy
+	}
+	if spec.MisfirePolicy != "" {
+		job.MisfirePolicy = spec.MisfirePolicy
+	}
+	job.UpdatedAt = time.Now()
+
+	if nextRunAt, err := s.calculateNextRun(ctx, job); err == nil && nextRunAt != nil {
+		job.NextRunAt = nextRunAt
+	}
+	return nil
+}
+
+// validateSchedule validates the schedule based on job type
+func (s *JobService) validateSchedule(jobType models.JobType, schedule string) error {
+	switch jobType {
+	case models.JobTypeCron:
+		if _, err := s.cronParser.Parse(schedule); err != nil {
+			return fmt.Errorf("invalid cron expression: %w", err)
+		}
+	case models.JobTypeInterval:
+		if _, err := scheduler.ParseInterval(schedule); err != nil {
+			return err
+		}
+	case models.JobTypeOneTime:
+		if _, err := scheduler.ParseRunAt(schedule); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("unknown job type: %s", jobType)
 	}
 	return nil
 }
 
+// checkSize rejects payloads, raw bodies and headers larger than the tenant's limits
+func (s *JobService) checkSize(ctx context.Context, tenantID uuid.UUID, payload json.RawMessage, body string, headers json.RawMessage) error {
+	limits := s.tenantService.Limits(ctx, tenantID)
+
+	if size := len(payload) + len(body); limits.MaxPayloadBytes > 0 && size > limits.MaxPayloadBytes {
+		return fmt.Errorf("%w: payload is %d bytes, the limit is %d", ErrPayloadTooLarge, size, limits.MaxPayloadBytes)
+	}
+	if limits.MaxHeadersBytes > 0 && len(headers) > limits.MaxHeadersBytes {
+		return fmt.Errorf("%w: headers are %d bytes, the limit is %d", ErrPayloadTooLarge, len(headers), limits.MaxHeadersBytes)
+	}
+	return nil
+}
+
+// checkTLS rejects TLS settings skipping certificate verification unless the tenant is
+// allowed to
+func (s *JobService) checkTLS(ctx context.Context, tenantID uuid.UUID, settings json.RawMessage) error {
+	parsed, err := scheduler.ParseJobTLS(&models.Job{TLS: settings})
+	if err != nil || parsed == nil || !parsed.InsecureSkipVerify {
+		return nil
+	}
+	if !s.tenantService.Limits(ctx, tenantID).AllowInsecureTLS {
+		return ErrInsecureTLSNotAllowed
+	}
+	return nil
+}
+
+// checkEgress rejects destinations the tenant's egress rules refuse
+func (s *JobService) checkEgress(ctx context.Context, tenantID uuid.UUID, targetType models.TargetType, targetConfig json.RawMessage, endpoint string, metadata json.RawMessage) error {
+	return s.scheduler.CheckEgress(ctx, &models.Job{
+		TenantID:     tenantID,
+		TargetType:   targetType,
+		TargetConfig: targetConfig,
+		Endpoint:     endpoint,
+		Metadata:     metadata,
+	})
+}
+
+// checkTargetAccess rejects commands the tenant wasn't granted and datasources that don't
+// serve it
+func (s *JobService) checkTargetAccess(ctx context.Context, tenantID uuid.UUID, targetType models.TargetType, targetConfig json.RawMessage, metadata json.RawMessage) error {
+	return s.scheduler.CheckTargetAccess(ctx, &models.Job{
+		TenantID:     tenantID,
+		TargetType:   targetType,
+		TargetConfig: targetConfig,
+		Metadata:     metadata,
+	})
+}
+
+// checkStorage rejects object locations outside the buckets and prefixes the tenant was granted
+func (s *JobService) checkStorage(ctx context.Context, tenantID uuid.UUID, job *models.Job) error {
+	job.TenantID = tenantID
+	return s.scheduler.CheckStorage(ctx, job)
+}
+
+// validateTarget checks that a job's target type and its settings are usable
+func validateTarget(targetType models.TargetType, targetConfig json.RawMessage, endpoint string, metadata json.RawMessage) error {
+	return scheduler.ValidateTarget(&models.Job{
+		TargetType:   targetType,
+		TargetConfig: targetConfig,
+		Endpoint:     endpoint,
+		Metadata:     metadata,
+	})
+}
+
+// targetField names the request field a target problem is reported on: the endpoint for http
+// targets, the target settings otherwise
+func targetField(targetType models.TargetType) string {
+	if targetType == models.TargetHTTP {
+		return "endpoint"
+	}
+	return "target_config"
+}
+
+// validateHeaders checks that a job's headers are an object of header names to values
+func validateHeaders(headers json.RawMessage) error {
+	if len(headers) == 0 || string(headers) == "null" {
+		return nil
+	}
+	var values map[string]string
+	if err := json.Unmarshal(headers, &values); err != nil {
+		return fmt.Errorf("headers must be an object of header names to string values")
+	}
+	return nil
+}
+
+// validateBody checks that a job's request body can be built for its target and content type
+func validateBody(targetType models.TargetType, contentType string, payload json.RawMessage, body string, encoding models.BodyEncoding) error {
+	return scheduler.ValidateRequestBody(&models.Job{
+		TargetType:   targetType,
+		ContentType:  contentType,
+		Payload:      payload,
+		Body:         body,
+		BodyEncoding: encoding,
+	})
+}
+
+// validateResponseSink checks that a job's response sink fits its target and projection
+func validateResponseSink(targetType models.TargetType, sink json.RawMessage, projection string) error {
+	return scheduler.ValidateResponseSink(&models.Job{
+		TargetType:         targetType,
+		ResponseSink:       sink,
+		ResponseProjection: projection,
+	})
+}
+
+// validateTLS checks that a job's TLS settings fit its target
+func validateTLS(targetType models.TargetType, settings json.RawMessage) error {
+	return scheduler.ValidateJobTLS(&models.Job{
+		TargetType: targetType,
+		TLS:        settings,
+	})
+}
+
+// validateTimeout checks a job timeout against SCHEDULER_MAX_JOB_TIMEOUT_SECONDS
+func (s *JobService) validateTimeout(timeout int) error {
+	maxTimeout := int(s.scheduler.MaxJobTimeout().Seconds())
+	if timeout < 1 || timeout > maxTimeout {
+		return fmt.Errorf("timeout must be between 1 and %d seconds", maxTimeout)
+	}
+	return nil
+}
+
+// validateConcurrencyPolicy checks a job concurrency policy
+func validateConcurrencyPolicy(policy models.ConcurrencyPolicy) error {
+	switch policy {
+	case models.ConcurrencyAllow, models.ConcurrencyForbid, models.ConcurrencyReplace:
+		return nil
+	}
+	return fmt.Errorf("invalid concurrency policy: %s (use allow, forbid or replace)", policy)
+}
+
+// validateMisfirePolicy checks a job misfire policy
+func validateMisfirePolicy(policy models.MisfirePolicy) error {
+	switch policy {
+	case models.MisfireFireOnce, models.MisfireFireAll, models.MisfireSkip:
+		return nil
+	}
+	return fmt.Errorf("invalid misfire policy: %s (use fire_once, fire_all or skip)", policy)
+}
+
+// validateWorkerGroup checks the worker group a job is pinned to; empty leaves it unpinned
+func validateWorkerGroup(group string) error {
+	if group == "" || config.ValidWorkerGroup(group) {
+		return nil
+	}
+	return fmt.Errorf("invalid worker group: %s (use lowercase letters, digits, - and _)", group)
+}
+
+// validateCorrelationID checks a correlation ID; empty leaves a job or execution uncorrelated
+func validateCorrelationID(id string) error {
+	if len(id) > correlationIDMaxLength {
+		return fmt.Errorf("%w: at most %d characters", ErrInvalidCorrelationID, correlationIDMaxLength)
+	}
+	return nil
+}
+
+// validateExternalID checks an external ID; empty leaves a job out of batch upserts
+func validateExternalID(id string) error {
+	if len(id) > externalIDMaxLength {
+		return fmt.Errorf("%w: at most %d characters", ErrInvalidExternalID, externalIDMaxLength)
+	}
+	return nil
+}
+
+// validateLifetime checks that a start time, end time or maximum number of runs is only set on
+// a recurring job, and that it starts before it ends
+func validateLifetime(jobType models.JobType, startAt, endAt *time.Time, maxRuns int64) error {
+	if maxRuns < 0 {
+		return fmt.Errorf("%w: max_runs can't be negative", ErrInvalidLifetime)
+	}
+	if jobType == models.JobTypeOneTime && (startAt != nil || endAt != nil || maxRuns > 0) {
+		return fmt.Errorf("%w: start_at, end_at and max_runs only apply to cron and interval jobs", ErrInvalidLifetime)
+	}
+	if startAt != nil && endAt != nil && !startAt.Before(*endAt) {
+		return fmt.Errorf("%w: start_at must be before end_at", ErrInvalidLifetime)
+	}
+	return nil
+}
+
+// calendarProblem reports a failed calendar check as a problem with a request field, unless
+// the calendar couldn't be loaded
+func calendarProblem(field string, err error) error {
+	if errors.Is(err, ErrInvalidCalendar) {
+		return invalidField(field, err)
+	}
+	return err
+}
+
+// lifetimeField names the request field a lifetime problem is reported on
+func lifetimeField(req *models.CreateJobRequest) string {
+	switch {
+	case req.MaxRuns < 0:
+		return "max_runs"
+	case req.StartAt != nil:
+		return "start_at"
+	case req.EndAt != nil:
+		return "end_at"
+	}
+	return "max_runs"
+}
+
+// parseLifetimeTime parses an RFC3339 start or end time from an update; empty removes it
+func parseLifetimeTime(field, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be an RFC3339 time", ErrInvalidLifetime, field)
+	}
+	return &t, nil
+}
+
+// validateTriggerLimits checks a job's manual trigger limits; unset ones use the defaults
+func validateTriggerLimits(limit, dedupe *int) error {
+	if limit != nil && *limit < 0 {
+		return fmt.Errorf("%w: trigger_limit can't be negative", ErrInvalidTriggerLimit)
+	}
+	if dedupe != nil && *dedupe < 0 {
+		return fmt.Errorf("%w: trigger_dedupe_seconds can't be negative", ErrInvalidTriggerLimit)
+	}
+	return nil
+}
+
 // calculateNextRun calculates the next run time for a job
-func (s *JobService) calculateNextRun(job *models.Job) (*time.Time, error) {
-	return s.scheduler.CalculateNextRun(job)
+func (s *JobService) calculateNextRun(ctx context.Context, job *models.Job) (*time.Time, error) {
+	return s.scheduler.CalculateNextRun(ctx, job)
 }
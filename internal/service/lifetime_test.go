@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLifetime(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		jobType models.JobType
+		startAt *time.Time
+		endAt   *time.Time
+		maxRuns int64
+		wantErr bool
+	}{
+		{name: "no lifetime", jobType: models.JobTypeCron},
+		{name: "cron window", jobType: models.JobTypeCron, startAt: &start, endAt: &end},
+		{name: "interval with max runs", jobType: models.JobTypeInterval, maxRuns: 10},
+		{name: "only an end", jobType: models.JobTypeCron, endAt: &end},
+		{name: "one-time without a lifetime", jobType: models.JobTypeOneTime},
+		{name: "negative max runs", jobType: models.JobTypeCron, maxRuns: -1, wantErr: true},
+		{name: "one-time with a start", jobType: models.JobTypeOneTime, startAt: &start, wantErr: true},
+		{name: "one-time with max runs", jobType: models.JobTypeOneTime, maxRuns: 1, wantErr: true},
+		{name: "ends before it starts", jobType: models.JobTypeCron, startAt: &end, endAt: &start, wantErr: true},
+		{name: "ends as it starts", jobType: models.JobTypeCron, startAt: &start, endAt: &start, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateLifetime(tt.jobType, tt.startAt, tt.endAt, tt.maxRuns)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidLifetime)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseLifetimeTime(t *testing.T) {
+	got, err := parseLifetimeTime("start_at", "")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	got, err = parseLifetimeTime("start_at", "2026-01-01T10:00:00+02:00")
+	assert.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)))
+
+	_, err = parseLifetimeTime("end_at", "tomorrow")
+	assert.ErrorIs(t, err, ErrInvalidLifetime)
+}
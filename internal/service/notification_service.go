@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/notification"
+	"github.com/minisource/scheduler/internal/repository"
+)
+
+// ErrInvalidNotificationConfig is returned when a channel or policy fails validation
+var ErrInvalidNotificationConfig = errors.New("invalid notification configuration")
+
+// NotificationService handles notification channel and policy business logic
+type NotificationService struct {
+	notificationRepo *repository.NotificationRepository
+	jobRepo          *repository.JobRepository
+	dispatcher       *notification.Dispatcher
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(
+	notificationRepo *repository.NotificationRepository,
+	jobRepo *repository.JobRepository,
+	dispatcher *notification.Dispatcher,
+) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		jobRepo:          jobRepo,
+		dispatcher:       dispatcher,
+	}
+}
+
+// ListChannels lists a tenant's notification channels
+func (s *NotificationService) ListChannels(ctx context.Context, tenantID uuid.UUID) ([]models.NotificationChannel, error) {
+	return s.notificationRepo.FindChannelsByTenant(ctx, tenantID)
+}
+
+// CreateChannel creates a notification channel
+func (s *NotificationService) CreateChannel(ctx context.Context, tenantID uuid.UUID, req *models.CreateChannelRequest) (*models.NotificationChannel, error) {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	channel := &models.NotificationChannel{
+		ID:                uuid.New(),
+		TenantID:          tenantID,
+		Name:              req.Name,
+		Type:              req.Type,
+		Config:            req.Config,
+		Enabled:           enabled,
+		SubscriptionsOnly: req.SubscriptionsOnly,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+
+	if err := s.dispatcher.ValidateChannel(channel); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNotificationConfig, err)
+	}
+
+	if err := s.notificationRepo.CreateChannel(ctx, channel); err != nil {
+		return nil, fmt.Errorf("failed to create channel: %w", err)
+	}
+
+	return channel, nil
+}
+
+// UpdateChannel updates a notification channel
+func (s *NotificationService) UpdateChannel(ctx context.Context, tenantID, id uuid.UUID, req *models.UpdateChannelRequest) (*models.NotificationChannel, error) {
+	channel, err := s.notificationRepo.FindChannelByTenantAndID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil && *req.Name != "" {
+		channel.Name = *req.Name
+	}
+	if req.Config != nil {
+		channel.Config = *req.Config
+	}
+	if req.Enabled != nil {
+		channel.Enabled = *req.Enabled
+	}
+	if req.SubscriptionsOnly != nil {
+		channel.SubscriptionsOnly = *req.SubscriptionsOnly
+	}
+
+	if err := s.dispatcher.ValidateChannel(channel); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNotificationConfig, err)
+	}
+
+	channel.UpdatedAt = time.Now()
+	if err := s.notificationRepo.UpdateChannel(ctx, channel); err != nil {
+		return nil, fmt.Errorf("failed to update channel: %w", err)
+	}
+
+	return channel, nil
+}
+
+// DeleteChannel deletes a notification channel
+func (s *NotificationService) DeleteChannel(ctx context.Context, tenantID, id uuid.UUID) error {
+	_, err := s.notificationRepo.DeleteChannel(ctx, tenantID, id)
+	return err
+}
+
+// ListSubscriptions lists a user's notification subscriptions
+func (s *NotificationService) ListSubscriptions(ctx context.Context, tenantID, userID uuid.UUID) ([]models.NotificationSubscription, error) {
+	return s.notificationRepo.FindSubscriptionsByUser(ctx, tenantID, userID)
+}
+
+// Subscribe subscribes a user's channel to a job or tag selector
+func (s *NotificationService) Subscribe(ctx context.Context, tenantID, userID uuid.UUID, req *models.CreateSubscriptionRequest) (*models.NotificationSubscription, error) {
+	if (req.JobID == nil) == (len(req.Tags) == 0) {
+		return nil, fmt.Errorf("%w: exactly one of job_id or tags is required", ErrInvalidNotificationConfig)
+	}
+
+	if _, err := s.notificationRepo.FindChannelByTenantAndID(ctx, tenantID, req.ChannelID); err != nil {
+		return nil, fmt.Errorf("%w: channel not found", ErrInvalidNotificationConfig)
+	}
+
+	subscription := &models.NotificationSubscription{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		UserID:    userID,
+		ChannelID: req.ChannelID,
+		CreatedAt: time.Now(),
+	}
+
+	if req.JobID != nil {
+		if _, err := s.jobRepo.FindByTenantAndID(ctx, tenantID, *req.JobID); err != nil {
+			return nil, fmt.Errorf("%w: job not found", ErrInvalidNotificationConfig)
+		}
+		subscription.JobID = req.JobID
+	} else {
+		tags, err := json.Marshal(req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		subscription.Tags = tags
+	}
+
+	if err := s.notificationRepo.CreateSubscription(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// Unsubscribe deletes a user's notification subscription
+func (s *NotificationService) Unsubscribe(ctx context.Context, tenantID, userID, id uuid.UUID) error {
+	_, err := s.notificationRepo.DeleteSubscription(ctx, tenantID, userID, id)
+	return err
+}
+
+// GetPolicy retrieves a tenant's effective notification policy
+func (s *NotificationService) GetPolicy(ctx context.Context, tenantID uuid.UUID) models.NotificationPolicy {
+	return s.dispatcher.Policy(ctx, tenantID)
+}
+
+// UpdatePolicy updates a tenant's notification policy
+func (s *NotificationService) UpdatePolicy(ctx context.Context, tenantID uuid.UUID, req *models.UpdatePolicyRequest) (*models.NotificationPolicy, error) {
+	policy := s.dispatcher.Policy(ctx, tenantID)
+
+	if req.DedupeWindowSeconds != nil {
+		if *req.DedupeWindowSeconds < 0 {
+			return nil, fmt.Errorf("%w: dedupe_window_seconds cannot be negative", ErrInvalidNotificationConfig)
+		}
+		policy.DedupeWindowSeconds = *req.DedupeWindowSeconds
+	}
+	if req.MinFailures != nil {
+		if *req.MinFailures < 1 {
+			return nil, fmt.Errorf("%w: min_failures must be at least 1", ErrInvalidNotificationConfig)
+		}
+		policy.MinFailures = *req.MinFailures
+	}
+	if req.EscalateAfterMinutes != nil {
+		if *req.EscalateAfterMinutes < 0 {
+			return nil, fmt.Errorf("%w: escalate_after_minutes cannot be negative", ErrInvalidNotificationConfig)
+		}
+		policy.EscalateAfterMinutes = *req.EscalateAfterMinutes
+	}
+	if req.EscalationChannelID != nil {
+		if _, err := s.notificationRepo.FindChannelByTenantAndID(ctx, tenantID, *req.EscalationChannelID); err != nil {
+			return nil, fmt.Errorf("%w: escalation channel not found", ErrInvalidNotificationConfig)
+		}
+		policy.EscalationChannelID = req.EscalationChannelID
+	}
+	if req.QuietHoursStart != nil {
+		if *req.QuietHoursStart != "" {
+			if err := notification.ValidateClock(*req.QuietHoursStart); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidNotificationConfig, err)
+			}
+		}
+		policy.QuietHoursStart = *req.QuietHoursStart
+	}
+	if req.QuietHoursEnd != nil {
+		if *req.QuietHoursEnd != "" {
+			if err := notification.ValidateClock(*req.QuietHoursEnd); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidNotificationConfig, err)
+			}
+		}
+		policy.QuietHoursEnd = *req.QuietHoursEnd
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil {
+			return nil, fmt.Errorf("%w: invalid timezone %q", ErrInvalidNotificationConfig, *req.Timezone)
+		}
+		policy.Timezone = *req.Timezone
+	}
+
+	policy.TenantID = tenantID
+	policy.UpdatedAt = time.Now()
+
+	if err := s.notificationRepo.SavePolicy(ctx, &policy); err != nil {
+		return nil, fmt.Errorf("failed to save policy: %w", err)
+	}
+
+	return &policy, nil
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/models"
+	"golang.org/x/oauth2"
+)
+
+// ErrOIDCNotConfigured is returned when no OIDC issuer is configured
+var ErrOIDCNotConfigured = errors.New("OIDC login is not configured")
+
+// ErrOIDCLoginFailed is returned when the code exchange or ID token verification fails
+var ErrOIDCLoginFailed = errors.New("OIDC login failed")
+
+// ErrOIDCAccessDenied is returned when an authenticated user maps to no role or tenant
+var ErrOIDCAccessDenied = errors.New("user is not permitted to access the scheduler")
+
+// OIDCService runs the OpenID Connect authorization code flow and maps groups to roles
+type OIDCService struct {
+	config  config.OIDCConfig
+	scopes  []string
+	mapping map[string]models.Role
+
+	// The provider is discovered on first use so an unreachable issuer doesn't block startup
+	provider *oidc.Provider
+	verifier *oidc.IDTokenVerifier
+	mu       sync.Mutex
+}
+
+// NewOIDCService creates a new OIDC service
+func NewOIDCService(cfg config.OIDCConfig) *OIDCService {
+	scopes := []string{oidc.ScopeOpenID}
+	for _, scope := range strings.Split(cfg.Scopes, ",") {
+		scope = strings.TrimSpace(scope)
+		if scope != "" && scope != oidc.ScopeOpenID {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return &OIDCService{
+		config:  cfg,
+		scopes:  scopes,
+		mapping: parseRoleMapping(cfg.RoleMapping),
+	}
+}
+
+// Enabled reports whether OIDC login is configured
+func (s *OIDCService) Enabled() bool {
+	return s.config.IssuerURL != "" && s.config.ClientID != ""
+}
+
+// Begin starts a login, returning the provider authorization URL with its state and nonce
+func (s *OIDCService) Begin(ctx context.Context) (string, string, string, error) {
+	oauthConfig, _, err := s.oauthConfig(ctx)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return "", "", "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return oauthConfig.AuthCodeURL(state, oidc.Nonce(nonce)), state, nonce, nil
+}
+
+// Complete exchanges an authorization code and returns the verified identity
+func (s *OIDCService) Complete(ctx context.Context, code, nonce string) (*models.Identity, error) {
+	oauthConfig, verifier, err := s.oauthConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauthConfig.Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: no id_token in token response", ErrOIDCLoginFailed)
+	}
+
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrOIDCLoginFailed)
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	return s.identity(idToken.Subject, claims)
+}
+
+// identity builds an identity from ID token claims
+func (s *OIDCService) identity(subject string, claims map[string]interface{}) (*models.Identity, error) {
+	identity := &models.Identity{
+		UserID:  uuid.NewSHA1(uuid.NameSpaceURL, []byte(s.config.IssuerURL+"#"+subject)),
+		Subject: subject,
+		Email:   stringClaim(claims, "email"),
+		Name:    stringClaim(claims, "name"),
+		Groups:  stringsClaim(claims, s.config.GroupsClaim),
+	}
+
+	tenantID, err := uuid.Parse(stringClaim(claims, s.config.TenantClaim))
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing or invalid %q claim", ErrOIDCAccessDenied, s.config.TenantClaim)
+	}
+	identity.TenantID = tenantID
+
+	identity.Roles = s.MapRoles(identity.Groups)
+	if len(identity.Roles) == 0 {
+		return nil, fmt.Errorf("%w: no role mapped for groups %v", ErrOIDCAccessDenied, identity.Groups)
+	}
+
+	return identity, nil
+}
+
+// MapRoles returns the roles granted to a set of groups
+func (s *OIDCService) MapRoles(groups []string) []models.Role {
+	seen := make(map[models.Role]bool)
+	var roles []models.Role
+	for _, group := range groups {
+		if role, ok := s.mapping[group]; ok && !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+
+	if len(roles) == 0 {
+		if role := models.Role(s.config.DefaultRole); role.Valid() {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// oauthConfig discovers the provider if needed and returns the OAuth2 config and token verifier
+func (s *OIDCService) oauthConfig(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	if !s.Enabled() {
+		return nil, nil, ErrOIDCNotConfigured
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider == nil {
+		provider, err := oidc.NewProvider(ctx, s.config.IssuerURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
+		}
+		s.provider = provider
+		s.verifier = provider.Verifier(&oidc.Config{ClientID: s.config.ClientID})
+	}
+
+	return &oauth2.Config{
+		ClientID:     s.config.ClientID,
+		ClientSecret: s.config.ClientSecret,
+		RedirectURL:  s.config.RedirectURL,
+		Endpoint:     s.provider.Endpoint(),
+		Scopes:       s.scopes,
+	}, s.verifier, nil
+}
+
+// parseRoleMapping parses comma-separated group=role pairs
+func parseRoleMapping(value string) map[string]models.Role {
+	mapping := make(map[string]models.Role)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		group, role, ok := strings.Cut(pair, "=")
+		if !ok || !models.Role(strings.TrimSpace(role)).Valid() {
+			log.Printf("oidc: ignoring invalid role mapping %q", pair)
+			continue
+		}
+		mapping[strings.TrimSpace(group)] = models.Role(strings.TrimSpace(role))
+	}
+	return mapping
+}
+
+// stringClaim returns a string claim, or empty if missing
+func stringClaim(claims map[string]interface{}, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// stringsClaim returns a claim that is either a list of strings or a single string
+func stringsClaim(claims map[string]interface{}, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// randomToken returns a URL-safe random token
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
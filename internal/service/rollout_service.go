@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/repository"
+	"github.com/minisource/scheduler/internal/scheduler"
+)
+
+var (
+	// ErrInvalidRollout is returned when a rollout fails validation
+	ErrInvalidRollout = errors.New("invalid rollout")
+	// ErrJobInRollout is returned when a job of a new rollout is in another active or halted one
+	ErrJobInRollout = errors.New("job is already in a rollout")
+	// ErrRolloutNotActive is returned when halting a rollout that isn't active
+	ErrRolloutNotActive = errors.New("rollout is not active")
+	// ErrRolloutNotHalted is returned when resuming a rollout that isn't halted
+	ErrRolloutNotHalted = errors.New("rollout is not halted")
+	// ErrRolloutRolledBack is returned when rolling back a rollout that already was
+	ErrRolloutRolledBack = errors.New("rollout already rolled back")
+)
+
+// Defaults and limits of rollouts
+const (
+	rolloutDefaultPercent       = 10
+	rolloutDefaultThreshold     = 0.1
+	rolloutDefaultMinExecutions = 10
+	rolloutMaxJobs              = 5000
+	rolloutBaselineWindow       = 24 * time.Hour
+)
+
+// RolloutService rolls schedule and endpoint changes out to fleets of jobs in stages
+type RolloutService struct {
+	rolloutRepo *repository.RolloutRepository
+	jobRepo     *repository.JobRepository
+	jobService  *JobService
+	scheduler   *scheduler.Scheduler
+}
+
+// NewRolloutService creates a new rollout service
+func NewRolloutService(rolloutRepo *repository.RolloutRepository, jobRepo *repository.JobRepository, jobService *JobService, sched *scheduler.Scheduler) *RolloutService {
+	return &RolloutService{
+		rolloutRepo: rolloutRepo,
+		jobRepo:     jobRepo,
+		jobService:  jobService,
+		scheduler:   sched,
+	}
+}
+
+// List lists rollouts, newest first, optionally only those in a status
+func (s *RolloutService) List(ctx context.Context, status models.RolloutStatus) ([]models.Rollout, error) {
+	return s.rolloutRepo.List(ctx, status)
+}
+
+// Get retrieves a rollout with the failure rate of the jobs it changed so far
+func (s *RolloutService) Get(ctx context.Context, id uuid.UUID) (*models.Rollout, error) {
+	rollout, err := s.rolloutRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rollout.AppliedJobs > 0 {
+		if rollout.Health, err = s.rolloutRepo.Health(ctx, rollout.ID, rollout.HealthSince); err != nil {
+			return nil, err
+		}
+	}
+	return rollout, nil
+}
+
+// ListJobs lists a rollout's jobs in the order they are changed
+func (s *RolloutService) ListJobs(ctx context.Context, id uuid.UUID) ([]models.RolloutJob, error) {
+	if _, err := s.rolloutRepo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.rolloutRepo.FindJobs(ctx, id)
+}
+
+// Create starts rolling a schedule or endpoint change out to every job with an external ID.
+// The change is checked against every job, and their tenants' egress rules, up front; the
+// scheduler changes the first batch on its next tick.
+func (s *RolloutService) Create(ctx context.Context, userID *uuid.UUID, req *models.CreateRolloutRequest) (*models.Rollout, error) {
+	if req.Schedule == "" && req.Endpoint == "" {
+		return nil, fmt.Errorf("%w: set schedule, endpoint or both", ErrInvalidRollout)
+	}
+
+	jobs, err := s.jobRepo.FindByExternalID(ctx, req.ExternalID, req.TenantIDs)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(jobs) == 0:
+		return nil, fmt.Errorf("%w: no jobs with external_id %q", ErrInvalidRollout, req.ExternalID)
+	case len(jobs) > rolloutMaxJobs:
+		return nil, fmt.Errorf("%w: at most %d jobs can be in a rollout", ErrInvalidRollout, rolloutMaxJobs)
+	}
+
+	ids := make([]uuid.UUID, 0, len(jobs))
+	for i := range jobs {
+		if err := s.checkJob(ctx, &jobs[i], req); err != nil {
+			return nil, err
+		}
+		ids = append(ids, jobs[i].ID)
+	}
+
+	open, err := s.rolloutRepo.FindOpenJobIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(open) > 0 {
+		return nil, fmt.Errorf("%w: job %s and %d more are in an active or halted rollout", ErrJobInRollout, open[0], len(open)-1)
+	}
+
+	now := time.Now()
+	baseline, err := s.rolloutRepo.Baseline(ctx, ids, now.Add(-rolloutBaselineWindow), now)
+	if err != nil {
+		return nil, err
+	}
+
+	rollout := &models.Rollout{
+		ID:                  uuid.New(),
+		ExternalID:          req.ExternalID,
+		Schedule:            req.Schedule,
+		Endpoint:            req.Endpoint,
+		PercentPerHour:      req.PercentPerHour,
+		FailureThreshold:    rolloutDefaultThreshold,
+		MinExecutions:       req.MinExecutions,
+		BaselineFailureRate: baseline.FailureRate,
+		Status:              models.RolloutActive,
+		TotalJobs:           len(jobs),
+		NextBatchAt:         &now,
+		HealthSince:         now,
+		Reason:              req.Reason,
+		CreatedBy:           userID,
+		CreatedAt:           now,
+	}
+	if rollout.PercentPerHour == 0 {
+		rollout.PercentPerHour = rolloutDefaultPercent
+	}
+	if req.FailureThreshold != nil {
+		rollout.FailureThreshold = *req.FailureThreshold
+	}
+	if rollout.MinExecutions == 0 {
+		rollout.MinExecutions = rolloutDefaultMinExecutions
+	}
+
+	items := make([]models.RolloutJob, 0, len(jobs))
+	for i, job := range jobs {
+		items = append(items, models.RolloutJob{
+			RolloutID: rollout.ID,
+			JobID:     job.ID,
+			TenantID:  job.TenantID,
+			Position:  i,
+		})
+	}
+	if err := s.rolloutRepo.Create(ctx, rollout, items); err != nil {
+		return nil, fmt.Errorf("failed to create rollout: %w", err)
+	}
+
+	return rollout, nil
+}
+
+// checkJob checks that a rollout's change can be applied to a job
+func (s *RolloutService) checkJob(ctx context.Context, job *models.Job, req *models.CreateRolloutRequest) error {
+	if req.Schedule != "" {
+		if err := s.jobService.validateSchedule(job.Type, req.Schedule); err != nil {
+			return fmt.Errorf("%w: schedule doesn't suit job %s of tenant %s: %v", ErrInvalidRollout, job.ID, job.TenantID, err)
+		}
+	}
+	if req.Endpoint != "" {
+		switch job.TargetType {
+		case "", models.TargetHTTP, models.TargetGraphQL, models.TargetSOAP:
+		default:
+			return fmt.Errorf("%w: job %s of tenant %s is a %s job, which has no endpoint", ErrInvalidRollout, job.ID, job.TenantID, job.TargetType)
+		}
+		if err := validateTarget(job.TargetType, job.TargetConfig, req.Endpoint, job.Metadata); err != nil {
+			return fmt.Errorf("%w: endpoint doesn't suit job %s of tenant %s: %v", ErrInvalidRollout, job.ID, job.TenantID, err)
+		}
+		if err := s.jobService.checkEgress(ctx, job.TenantID, job.TargetType, job.TargetConfig, req.Endpoint, job.Metadata); err != nil {
+			return fmt.Errorf("%w: tenant %s: %v", ErrInvalidRollout, job.TenantID, err)
+		}
+	}
+	return nil
+}
+
+// Halt stops a rollout before its next batch. Jobs it already changed keep the change until
+// it is rolled back.
+func (s *RolloutService) Halt(ctx context.Context, id uuid.UUID, reason string) (*models.Rollout, error) {
+	if reason == "" {
+		reason = "halted by an operator"
+	}
+	if _, err := s.rolloutRepo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	halted, err := s.rolloutRepo.Halt(ctx, id, reason)
+	if err != nil {
+		return nil, err
+	}
+	if !halted {
+		return nil, ErrRolloutNotActive
+	}
+	return s.Get(ctx, id)
+}
+
+// Resume starts a halted rollout again with its next batch. Only executions from now on are
+// judged, so the failures that halted it don't halt it again.
+func (s *RolloutService) Resume(ctx context.Context, id uuid.UUID) (*models.Rollout, error) {
+	if _, err := s.rolloutRepo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	resumed, err := s.rolloutRepo.Resume(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !resumed {
+		return nil, ErrRolloutNotHalted
+	}
+	return s.Get(ctx, id)
+}
+
+// Rollback stops a rollout and puts the jobs it changed back on their previous schedule and
+// endpoint
+func (s *RolloutService) Rollback(ctx context.Context, id uuid.UUID) (*models.Rollout, error) {
+	if _, err := s.rolloutRepo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	rolledBack, err := s.scheduler.RollbackRollout(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !rolledBack {
+		return nil, ErrRolloutRolledBack
+	}
+	return s.Get(ctx, id)
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/repository"
+	"github.com/minisource/scheduler/internal/secrets"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidSecret is returned when a secret fails validation
+	ErrInvalidSecret = errors.New("invalid secret")
+	// ErrSecretExists is returned when a tenant already has a secret with the name
+	ErrSecretExists = errors.New("secret already exists")
+)
+
+// SecretService manages encrypted secrets referenced by jobs
+type SecretService struct {
+	secretRepo *repository.SecretRepository
+	cipher     *secrets.Cipher
+}
+
+// NewSecretService creates a new secret service. A nil cipher disables secrets.
+func NewSecretService(secretRepo *repository.SecretRepository, cipher *secrets.Cipher) *SecretService {
+	return &SecretService{
+		secretRepo: secretRepo,
+		cipher:     cipher,
+	}
+}
+
+// List lists a tenant's secrets, without their values
+func (s *SecretService) List(ctx context.Context, tenantID uuid.UUID) ([]models.Secret, error) {
+	return s.secretRepo.FindByTenant(ctx, tenantID)
+}
+
+// Get retrieves a secret, without its value
+func (s *SecretService) Get(ctx context.Context, tenantID uuid.UUID, name string) (*models.Secret, error) {
+	return s.secretRepo.FindByTenantAndName(ctx, tenantID, name)
+}
+
+// Create encrypts and stores a secret
+func (s *SecretService) Create(ctx context.Context, tenantID uuid.UUID, userID *uuid.UUID, req *models.CreateSecretRequest) (*models.Secret, error) {
+	if !secrets.NamePattern.MatchString(req.Name) {
+		return nil, fmt.Errorf("%w: name must be 1-100 letters, digits, '_', '.' or '-'", ErrInvalidSecret)
+	}
+	if req.Value == "" {
+		return nil, fmt.Errorf("%w: value is required", ErrInvalidSecret)
+	}
+
+	if _, err := s.secretRepo.FindByTenantAndName(ctx, tenantID, req.Name); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSecretExists, req.Name)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	ciphertext, err := s.cipher.Encrypt(req.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := &models.Secret{
+		ID:          uuid.New(),
+		TenantID:    tenantID,
+		Name:        req.Name,
+		Description: req.Description,
+		Ciphertext:  ciphertext,
+		KeyID:       s.cipher.KeyID(),
+		CreatedBy:   userID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := s.secretRepo.Create(ctx, secret); err != nil {
+		return nil, fmt.Errorf("failed to create secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// Update replaces a secret's value or description. Running executions that already
+// resolved the old value are unaffected.
+func (s *SecretService) Update(ctx context.Context, tenantID uuid.UUID, name string, req *models.UpdateSecretRequest) (*models.Secret, error) {
+	secret, err := s.secretRepo.FindByTenantAndName(ctx, tenantID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Value != nil {
+		if *req.Value == "" {
+			return nil, fmt.Errorf("%w: value cannot be empty", ErrInvalidSecret)
+		}
+		ciphertext, err := s.cipher.Encrypt(*req.Value)
+		if err != nil {
+			return nil, err
+		}
+		secret.Ciphertext = ciphertext
+		secret.KeyID = s.cipher.KeyID()
+	}
+	if req.Description != nil {
+		secret.Description = *req.Description
+	}
+
+	secret.UpdatedAt = time.Now()
+	if err := s.secretRepo.Update(ctx, secret); err != nil {
+		return nil, fmt.Errorf("failed to update secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// Delete deletes a secret. Jobs still referencing it fail at their next run.
+func (s *SecretService) Delete(ctx context.Context, tenantID uuid.UUID, name string) error {
+	deleted, err := s.secretRepo.Delete(ctx, tenantID, name)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ResolveSecrets decrypts the named secrets of a tenant for the executor, failing if any is missing
+func (s *SecretService) ResolveSecrets(ctx context.Context, tenantID uuid.UUID, names []string) (map[string]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	stored, err := s.secretRepo.FindByTenantAndNames(ctx, tenantID, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secrets: %w", err)
+	}
+
+	values := make(map[string]string, len(stored))
+	for _, secret := range stored {
+		value, err := s.cipher.Decrypt(secret.Ciphertext, secret.KeyID)
+		if err != nil {
+			return nil, fmt.Errorf("secret %q: %w", secret.Name, err)
+		}
+		values[secret.Name] = value
+	}
+	for _, name := range names {
+		if _, ok := values[name]; !ok {
+			return nil, fmt.Errorf("secret %q not found", name)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/localstore"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// sessionKeyPrefix namespaces browser sessions in Redis
+const sessionKeyPrefix = "session:"
+
+// ErrSessionNotFound is returned for unknown, expired or revoked sessions
+var ErrSessionNotFound = errors.New("session not found or expired")
+
+// SessionService stores browser sessions server-side so they can be revoked. Without Redis,
+// on a single node, they are kept in process memory and end when it restarts.
+type SessionService struct {
+	config config.SessionConfig
+	redis  redis.UniversalClient
+	local  *localstore.Store
+}
+
+// NewSessionService creates a new session service; a nil Redis client keeps sessions in
+// process memory
+func NewSessionService(cfg config.SessionConfig, redisClient redis.UniversalClient) *SessionService {
+	s := &SessionService{
+		config: cfg,
+		redis:  redisClient,
+	}
+	if redisClient == nil {
+		s.local = localstore.New()
+	}
+	return s
+}
+
+// TTL returns how long a session lasts
+func (s *SessionService) TTL() time.Duration {
+	return time.Duration(s.config.TTLHours) * time.Hour
+}
+
+// CookieSecure reports whether session cookies are restricted to HTTPS
+func (s *SessionService) CookieSecure() bool {
+	return s.config.CookieSecure
+}
+
+// PostLoginURL returns where the browser goes after logging in
+func (s *SessionService) PostLoginURL() string {
+	return s.config.PostLoginURL
+}
+
+// Create starts a session for an authenticated identity
+func (s *SessionService) Create(ctx context.Context, identity *models.Identity) (*models.Session, error) {
+	id, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	csrfToken, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	session := &models.Session{
+		ID:        id,
+		Identity:  *identity,
+		CSRFToken: csrfToken,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.TTL()),
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+	if s.local != nil {
+		s.local.Set(sessionKeyPrefix+id, string(data), s.TTL())
+	} else if err := s.redis.Set(ctx, sessionKeyPrefix+id, data, s.TTL()).Err(); err != nil {
+		return nil, fmt.Errorf("failed to store session: %w", err)
+	}
+
+	return session, nil
+}
+
+// Get loads a session by ID
+func (s *SessionService) Get(ctx context.Context, id string) (*models.Session, error) {
+	if id == "" {
+		return nil, ErrSessionNotFound
+	}
+
+	data, err := s.load(ctx, id)
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session models.Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, ErrSessionNotFound
+	}
+	session.ID = id
+
+	return &session, nil
+}
+
+// load reads a stored session, returning redis.Nil for one that isn't stored
+func (s *SessionService) load(ctx context.Context, id string) ([]byte, error) {
+	if s.local != nil {
+		data, ok := s.local.Get(sessionKeyPrefix + id)
+		if !ok {
+			return nil, redis.Nil
+		}
+		return []byte(data), nil
+	}
+	return s.redis.Get(ctx, sessionKeyPrefix+id).Bytes()
+}
+
+// Delete revokes a session
+func (s *SessionService) Delete(ctx context.Context, id string) error {
+	if s.local != nil {
+		s.local.Delete(sessionKeyPrefix + id)
+		return nil
+	}
+	return s.redis.Del(ctx, sessionKeyPrefix+id).Err()
+}
+
+// VerifyCSRF checks a request's CSRF token against the session's
+func (s *SessionService) VerifyCSRF(session *models.Session, token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(session.CSRFToken), []byte(token)) == 1
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionServiceWithoutRedis(t *testing.T) {
+	ctx := context.Background()
+	sessions := NewSessionService(config.SessionConfig{TTLHours: 1}, nil)
+	identity := &models.Identity{Subject: "alice", Roles: []models.Role{models.RoleOperator}}
+
+	session, err := sessions.Create(ctx, identity)
+	require.NoError(t, err)
+	require.NotEmpty(t, session.ID)
+	assert.NotEmpty(t, session.CSRFToken)
+
+	loaded, err := sessions.Get(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, loaded.ID)
+	assert.Equal(t, identity.Subject, loaded.Identity.Subject)
+	assert.True(t, sessions.VerifyCSRF(loaded, session.CSRFToken))
+
+	require.NoError(t, sessions.Delete(ctx, session.ID))
+	_, err = sessions.Get(ctx, session.ID)
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestSessionServiceWithoutRedisUnknown(t *testing.T) {
+	ctx := context.Background()
+	sessions := NewSessionService(config.SessionConfig{TTLHours: 1}, nil)
+
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{name: "empty", id: ""},
+		{name: "never created", id: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sessions.Get(ctx, tt.id)
+			assert.ErrorIs(t, err, ErrSessionNotFound)
+		})
+	}
+}
+
+func TestSessionServiceVerifyCSRF(t *testing.T) {
+	session := &models.Session{CSRFToken: "token"}
+
+	tests := []struct {
+		name  string
+		token string
+		want  bool
+	}{
+		{name: "matching", token: "token", want: true},
+		{name: "different", token: "other", want: false},
+		{name: "empty", token: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, (&SessionService{}).VerifyCSRF(session, tt.token))
+		})
+	}
+}
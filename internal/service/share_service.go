@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/repository"
+)
+
+// publicExecutionLimit is the number of recent executions shown on a share link
+const publicExecutionLimit = 20
+
+// ErrSharingDisabled is returned when no share link secret is configured
+var ErrSharingDisabled = errors.New("share links are not enabled")
+
+// ErrInvalidShareToken is returned for malformed, tampered or expired share tokens
+var ErrInvalidShareToken = errors.New("invalid or expired share link")
+
+// ShareService issues and resolves signed, read-only job share links
+type ShareService struct {
+	config        config.SharingConfig
+	jobRepo       *repository.JobRepository
+	executionRepo *repository.ExecutionRepository
+}
+
+// NewShareService creates a new share service
+func NewShareService(
+	cfg config.SharingConfig,
+	jobRepo *repository.JobRepository,
+	executionRepo *repository.ExecutionRepository,
+) *ShareService {
+	return &ShareService{
+		config:        cfg,
+		jobRepo:       jobRepo,
+		executionRepo: executionRepo,
+	}
+}
+
+// CreateLink issues a share link for a job
+func (s *ShareService) CreateLink(ctx context.Context, tenantID, jobID uuid.UUID, req *models.CreateShareLinkRequest) (*models.ShareLink, error) {
+	if s.config.Secret == "" {
+		return nil, ErrSharingDisabled
+	}
+
+	job, err := s.jobRepo.FindByTenantAndID(ctx, tenantID, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	hours := req.ExpiresInHours
+	if hours <= 0 {
+		hours = s.config.DefaultTTLHours
+	}
+	if hours > s.config.MaxTTLHours {
+		return nil, fmt.Errorf("expires_in_hours cannot exceed %d", s.config.MaxTTLHours)
+	}
+
+	expiresAt := time.Now().Add(time.Duration(hours) * time.Hour).UTC().Truncate(time.Second)
+	token := s.sign(job.TenantID, job.ID, expiresAt)
+
+	return &models.ShareLink{
+		URL:       strings.TrimRight(s.config.BaseURL, "/") + "/api/v1/public/jobs/" + token,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Resolve verifies a share token and returns the job's public status
+func (s *ShareService) Resolve(ctx context.Context, token string) (*models.PublicJobStatus, error) {
+	if s.config.Secret == "" {
+		return nil, ErrSharingDisabled
+	}
+
+	tenantID, jobID, expiresAt, err := s.verify(token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.jobRepo.FindByTenantAndID(ctx, tenantID, jobID)
+	if err != nil || job.Status == models.JobStatusDeleted {
+		return nil, ErrInvalidShareToken
+	}
+
+	executions, err := s.executionRepo.FindByTenantAndJobID(ctx, tenantID, jobID, publicExecutionLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.PublicJobStatus{
+		Name:             job.Name,
+		Type:             job.Type,
+		Schedule:         job.Schedule,
+		Timezone:         job.Timezone,
+		Status:           job.Status,
+		NextRunAt:        job.NextRunAt,
+		LastRunAt:        job.LastRunAt,
+		RecentExecutions: make([]models.PublicExecution, 0, len(executions)),
+		LinkExpiresAt:    expiresAt,
+	}
+
+	var finished, succeeded int
+	for _, execution := range executions {
+		status.RecentExecutions = append(status.RecentExecutions, models.PublicExecution{
+			Status:      execution.Status,
+			ScheduledAt: execution.ScheduledAt,
+			StartedAt:   execution.StartedAt,
+			CompletedAt: execution.CompletedAt,
+			Duration:    execution.Duration,
+			StatusCode:  execution.StatusCode,
+		})
+
+		switch execution.Status {
+		case models.ExecutionStatusCompleted:
+			finished++
+			succeeded++
+		case models.ExecutionStatusFailed, models.ExecutionStatusTimeout:
+			finished++
+		}
+	}
+
+	if finished > 0 {
+		status.SuccessRate = float64(succeeded) / float64(finished) * 100
+	}
+	// Healthy when the most recent finished execution succeeded
+	status.Healthy = true
+	for _, execution := range executions {
+		if execution.Status == models.ExecutionStatusCompleted {
+			break
+		}
+		if execution.Status == models.ExecutionStatusFailed || execution.Status == models.ExecutionStatusTimeout {
+			status.Healthy = false
+			break
+		}
+	}
+
+	return status, nil
+}
+
+// sign builds a token of the form <tenant>.<job>.<expiry>.<signature>
+func (s *ShareService) sign(tenantID, jobID uuid.UUID, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%s.%s.%d", encodeUUID(tenantID), encodeUUID(jobID), expiresAt.Unix())
+	return payload + "." + s.signature(payload)
+}
+
+// verify checks a token's signature and expiry
+func (s *ShareService) verify(token string, now time.Time) (uuid.UUID, uuid.UUID, time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return uuid.Nil, uuid.Nil, time.Time{}, ErrInvalidShareToken
+	}
+
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(s.signature(payload)), []byte(parts[3])) {
+		return uuid.Nil, uuid.Nil, time.Time{}, ErrInvalidShareToken
+	}
+
+	tenantID, err1 := decodeUUID(parts[0])
+	jobID, err2 := decodeUUID(parts[1])
+	expiry, err3 := strconv.ParseInt(parts[2], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return uuid.Nil, uuid.Nil, time.Time{}, ErrInvalidShareToken
+	}
+
+	expiresAt := time.Unix(expiry, 0).UTC()
+	if !now.Before(expiresAt) {
+		return uuid.Nil, uuid.Nil, time.Time{}, ErrInvalidShareToken
+	}
+
+	return tenantID, jobID, expiresAt, nil
+}
+
+// signature returns the URL-safe HMAC-SHA256 of a token payload
+func (s *ShareService) signature(payload string) string {
+	mac := hmac.New(sha256.New, []byte(s.config.Secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// encodeUUID encodes a UUID compactly for use in URLs
+func encodeUUID(id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString(id[:])
+}
+
+// decodeUUID decodes a UUID encoded by encodeUUID
+func decodeUUID(s string) (uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return uuid.FromBytes(raw)
+}
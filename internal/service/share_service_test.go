@@ -0,0 +1,69 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareToken(t *testing.T) {
+	shares := &ShareService{config: config.SharingConfig{Secret: "share-secret"}}
+	tenantID, jobID := uuid.New(), uuid.New()
+	now := time.Now().UTC().Truncate(time.Second)
+	expiresAt := now.Add(time.Hour)
+	token := shares.sign(tenantID, jobID, expiresAt)
+
+	// replacePart replaces one dot-separated part of the token
+	replacePart := func(i int, value string) string {
+		parts := strings.Split(token, ".")
+		parts[i] = value
+		return strings.Join(parts, ".")
+	}
+
+	tests := []struct {
+		name    string
+		service *ShareService
+		token   string
+		now     time.Time
+		valid   bool
+	}{
+		{name: "valid", service: shares, token: token, now: now, valid: true},
+		{name: "expires at its expiry", service: shares, token: token, now: expiresAt},
+		{name: "expired", service: shares, token: token, now: expiresAt.Add(time.Minute)},
+		{name: "other secret", service: &ShareService{config: config.SharingConfig{Secret: "other"}}, token: token, now: now},
+		{name: "extended expiry", service: shares, token: replacePart(2, "99999999999"), now: now},
+		{name: "other job", service: shares, token: replacePart(1, encodeUUID(uuid.New())), now: now},
+		{name: "tampered signature", service: shares, token: replacePart(3, "AAAA"), now: now},
+		{name: "missing signature", service: shares, token: strings.Join(strings.Split(token, ".")[:3], "."), now: now},
+		{name: "empty", service: shares, token: "", now: now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotTenant, gotJob, gotExpiry, err := tt.service.verify(tt.token, tt.now)
+			if !tt.valid {
+				assert.ErrorIs(t, err, ErrInvalidShareToken)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tenantID, gotTenant)
+			assert.Equal(t, jobID, gotJob)
+			assert.True(t, expiresAt.Equal(gotExpiry))
+		})
+	}
+}
+
+func TestShareUUIDEncoding(t *testing.T) {
+	id := uuid.New()
+	decoded, err := decodeUUID(encodeUUID(id))
+	require.NoError(t, err)
+	assert.Equal(t, id, decoded)
+
+	_, err = decodeUUID("short")
+	assert.Error(t, err)
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/repository"
+	"github.com/minisource/scheduler/internal/scheduler"
+)
+
+var (
+	// ErrInvalidShift is returned when a schedule shift fails validation
+	ErrInvalidShift = errors.New("invalid schedule shift")
+	// ErrJobShifted is returned when a job picked for a shift is already in an active one
+	ErrJobShifted = errors.New("job is already shifted")
+	// ErrShiftReverted is returned when reverting a shift that is no longer active
+	ErrShiftReverted = errors.New("schedule shift already reverted")
+)
+
+// Limits of schedule shifts
+const (
+	shiftMaxOffset = 7 * 24 * time.Hour
+	shiftMaxWindow = 31 * 24 * time.Hour
+	shiftMaxJobs   = 1000
+)
+
+// ShiftService moves the schedules of sets of jobs for a bounded period
+type ShiftService struct {
+	shiftRepo *repository.ScheduleShiftRepository
+	jobRepo   *repository.JobRepository
+	scheduler *scheduler.Scheduler
+}
+
+// NewShiftService creates a new schedule shift service
+func NewShiftService(shiftRepo *repository.ScheduleShiftRepository, jobRepo *repository.JobRepository, sched *scheduler.Scheduler) *ShiftService {
+	return &ShiftService{
+		shiftRepo: shiftRepo,
+		jobRepo:   jobRepo,
+		scheduler: sched,
+	}
+}
+
+// List lists a tenant's schedule shifts, newest first
+func (s *ShiftService) List(ctx context.Context, tenantID uuid.UUID) ([]models.ScheduleShift, error) {
+	return s.shiftRepo.FindByTenant(ctx, tenantID)
+}
+
+// Get retrieves a schedule shift
+func (s *ShiftService) Get(ctx context.Context, tenantID, id uuid.UUID) (*models.ScheduleShift, error) {
+	return s.shiftRepo.FindByTenantAndID(ctx, tenantID, id)
+}
+
+// Create shifts the occurrences of the picked jobs that fall in the requested window and
+// recalculates their next runs. Jobs picked by tag that can't be shifted are skipped; jobs
+// picked by ID must all be shiftable.
+func (s *ShiftService) Create(ctx context.Context, tenantID uuid.UUID, userID *uuid.UUID, req *models.CreateScheduleShiftRequest) (*models.ScheduleShift, error) {
+	offset, err := time.ParseDuration(req.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("%w: offset must be a duration such as \"2h\" or \"-30m\"", ErrInvalidShift)
+	}
+	offset = offset.Truncate(time.Second)
+	if offset == 0 || offset.Abs() > shiftMaxOffset {
+		return nil, fmt.Errorf("%w: offset must be at least 1s and at most %s either way", ErrInvalidShift, shiftMaxOffset)
+	}
+
+	now := time.Now()
+	startsAt := now
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+	startsAt = startsAt.Truncate(time.Second)
+	endsAt := req.EndsAt.Truncate(time.Second)
+	switch {
+	case req.EndsAt.IsZero():
+		return nil, fmt.Errorf("%w: ends_at is required", ErrInvalidShift)
+	case !endsAt.After(startsAt) || !endsAt.After(now):
+		return nil, fmt.Errorf("%w: ends_at must be in the future and after starts_at", ErrInvalidShift)
+	case endsAt.Sub(startsAt) > shiftMaxWindow:
+		return nil, fmt.Errorf("%w: the window can't be longer than %d days", ErrInvalidShift, int(shiftMaxWindow.Hours()/24))
+	}
+
+	jobs, err := s.pickJobs(ctx, tenantID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.ID)
+	}
+	jobIDs, err := json.Marshal(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	shift := &models.ScheduleShift{
+		ID:            uuid.New(),
+		TenantID:      tenantID,
+		OffsetSeconds: int64(offset / time.Second),
+		StartsAt:      startsAt,
+		EndsAt:        endsAt,
+		RevertAt:      endsAt.Add(max(offset, 0)),
+		Reason:        req.Reason,
+		JobIDs:        jobIDs,
+		Status:        models.ScheduleShiftActive,
+		CreatedBy:     userID,
+		CreatedAt:     now,
+	}
+	if err := s.shiftRepo.Create(ctx, shift); err != nil {
+		return nil, fmt.Errorf("failed to create schedule shift: %w", err)
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		job.ShiftID = &shift.ID
+		job.ShiftOffsetSeconds = shift.OffsetSeconds
+		job.ShiftStartsAt = &shift.StartsAt
+		job.ShiftEndsAt = &shift.EndsAt
+
+		nextRunAt := job.NextRunAt
+		if nextRunAt != nil {
+			if nextRunAt, err = s.scheduler.CalculateNextRun(ctx, job); err != nil {
+				return nil, err
+			}
+		}
+		if err := s.jobRepo.SetShift(ctx, job.ID, shift, nextRunAt); err != nil {
+			return nil, fmt.Errorf("failed to shift job %s: %w", job.ID, err)
+		}
+	}
+
+	return shift, nil
+}
+
+// pickJobs resolves the jobs a shift request applies to
+func (s *ShiftService) pickJobs(ctx context.Context, tenantID uuid.UUID, req *models.CreateScheduleShiftRequest) ([]models.Job, error) {
+	if len(req.JobIDs) == 0 && req.Tag == "" {
+		return nil, fmt.Errorf("%w: pick jobs with job_ids or tag", ErrInvalidShift)
+	}
+
+	var jobs []models.Job
+	picked := make(map[uuid.UUID]bool)
+
+	if len(req.JobIDs) > 0 {
+		byID, err := s.jobRepo.FindByTenantAndIDs(ctx, tenantID, req.JobIDs)
+		if err != nil {
+			return nil, err
+		}
+		found := make(map[uuid.UUID]bool, len(byID))
+		for _, job := range byID {
+			found[job.ID] = true
+		}
+		for _, id := range req.JobIDs {
+			if !found[id] {
+				return nil, fmt.Errorf("%w: job %s not found", ErrInvalidShift, id)
+			}
+		}
+		for _, job := range byID {
+			if err := shiftable(&job); err != nil {
+				return nil, err
+			}
+			if !picked[job.ID] {
+				picked[job.ID] = true
+				jobs = append(jobs, job)
+			}
+		}
+	}
+
+	if req.Tag != "" {
+		byTag, err := s.jobRepo.FindByTenantAndTag(ctx, tenantID, req.Tag)
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range byTag {
+			if err := shiftable(&job); err != nil {
+				if errors.Is(err, ErrJobShifted) {
+					return nil, err
+				}
+				continue
+			}
+			if !picked[job.ID] {
+				picked[job.ID] = true
+				jobs = append(jobs, job)
+			}
+		}
+	}
+
+	switch {
+	case len(jobs) == 0:
+		return nil, fmt.Errorf("%w: no shiftable jobs picked", ErrInvalidShift)
+	case len(jobs) > shiftMaxJobs:
+		return nil, fmt.Errorf("%w: at most %d jobs can be shifted at once", ErrInvalidShift, shiftMaxJobs)
+	}
+	return jobs, nil
+}
+
+// shiftable checks that a job's occurrences can be shifted
+func shiftable(job *models.Job) error {
+	switch {
+	case job.ShiftID != nil:
+		return fmt.Errorf("%w: job %s is in schedule shift %s", ErrJobShifted, job.ID, *job.ShiftID)
+	case job.Type == models.JobTypeInterval:
+		return fmt.Errorf("%w: job %s is an interval job, which runs relative to its last run", ErrInvalidShift, job.ID)
+	case job.Status == models.JobStatusCompleted:
+		return fmt.Errorf("%w: job %s has completed", ErrInvalidShift, job.ID)
+	}
+	return nil
+}
+
+// Revert ends a schedule shift early, putting its jobs back on their own schedules
+func (s *ShiftService) Revert(ctx context.Context, tenantID, id uuid.UUID) (*models.ScheduleShift, error) {
+	shift, err := s.shiftRepo.FindByTenantAndID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if shift.Status != models.ScheduleShiftActive {
+		return nil, ErrShiftReverted
+	}
+
+	reverted, err := s.scheduler.RevertShift(ctx, shift.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !reverted {
+		return nil, ErrShiftReverted
+	}
+
+	return s.shiftRepo.FindByTenantAndID(ctx, tenantID, id)
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/egress"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/notification"
+	"github.com/minisource/scheduler/internal/repository"
+)
+
+// ErrSlackNotConfigured is returned when Slack interactions arrive without a signing secret
+var ErrSlackNotConfigured = errors.New("slack integration is not configured")
+
+// ErrInvalidSlackAction is returned for malformed or unknown Slack actions
+var ErrInvalidSlackAction = errors.New("invalid slack action")
+
+// SlackService handles Slack interaction callbacks
+type SlackService struct {
+	config           config.NotificationConfig
+	notificationRepo *repository.NotificationRepository
+	jobService       *JobService
+	executionService *ExecutionService
+	incidentService  *IncidentService
+	client           *http.Client
+}
+
+// NewSlackService creates a new Slack service. Replies to response URLs are sent within the
+// service-wide egress rules.
+func NewSlackService(
+	cfg config.NotificationConfig,
+	egressCfg config.EgressConfig,
+	notificationRepo *repository.NotificationRepository,
+	jobService *JobService,
+	executionService *ExecutionService,
+	incidentService *IncidentService,
+) *SlackService {
+	return &SlackService{
+		config:           cfg,
+		notificationRepo: notificationRepo,
+		jobService:       jobService,
+		executionService: executionService,
+		incidentService:  incidentService,
+		client:           egress.NewClient(egressCfg, time.Duration(cfg.TimeoutSeconds)*time.Second),
+	}
+}
+
+// Verify checks the signature of an interaction callback
+func (s *SlackService) Verify(timestamp, signature string, body []byte) error {
+	if s.config.SlackSigningSecret == "" {
+		return ErrSlackNotConfigured
+	}
+	return notification.VerifySlackSignature(s.config.SlackSigningSecret, timestamp, signature, body, time.Now())
+}
+
+// HandleInteraction performs the button action in a Slack payload and returns a reply
+func (s *SlackService) HandleInteraction(ctx context.Context, payload *notification.SlackInteraction) (string, error) {
+	if len(payload.Actions) == 0 {
+		return "", ErrInvalidSlackAction
+	}
+	action := payload.Actions[0]
+
+	var value notification.SlackActionValue
+	if err := json.Unmarshal([]byte(action.Value), &value); err != nil || value.JobID == nil {
+		return "", ErrInvalidSlackAction
+	}
+
+	// Only honour buttons sent to a Slack channel the tenant still has configured
+	channel, err := s.notificationRepo.FindChannelByTenantAndID(ctx, value.TenantID, value.ChannelID)
+	if err != nil || channel.Type != models.ChannelTypeSlack {
+		return "", ErrInvalidSlackAction
+	}
+
+	user := payload.User.Username
+	if user == "" {
+		user = payload.User.ID
+	}
+
+	switch action.ActionID {
+	case notification.SlackActionRetry:
+		execution, _, err := s.jobService.Trigger(ctx, value.TenantID, *value.JobID, "")
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Retry queued by @%s (execution %s)", user, execution.ID), nil
+
+	case notification.SlackActionPause:
+		job, err := s.jobService.UpdateStatus(ctx, value.TenantID, *value.JobID, models.JobStatusPaused)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Job %q paused by @%s", job.Name, user), nil
+
+	case notification.SlackActionAck:
+		comment := fmt.Sprintf("Acknowledged in Slack by @%s", user)
+		if value.ExecutionID != nil {
+			if _, err := s.executionService.Acknowledge(ctx, value.TenantID, *value.ExecutionID, nil, comment); err != nil {
+				return "", err
+			}
+		} else if value.IncidentID != nil {
+			if _, err := s.incidentService.Acknowledge(ctx, value.TenantID, *value.IncidentID, nil); err != nil {
+				return "", err
+			}
+		} else {
+			return "", ErrInvalidSlackAction
+		}
+		return comment, nil
+	}
+
+	return "", ErrInvalidSlackAction
+}
+
+// Respond posts a follow-up message to the interaction's response_url
+func (s *SlackService) Respond(ctx context.Context, responseURL, text string) error {
+	if responseURL == "" {
+		return nil
+	}
+	return notification.RespondSlack(ctx, s.client, responseURL, text)
+}
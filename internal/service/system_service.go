@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/features"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/repository"
+	"github.com/minisource/scheduler/internal/scheduler"
+)
+
+var (
+	// ErrInvalidSetting is returned when a runtime setting is out of range
+	ErrInvalidSetting = errors.New("invalid runtime setting")
+	// ErrUnknownFlag is returned for a feature flag this version doesn't have
+	ErrUnknownFlag = errors.New("unknown feature flag")
+)
+
+// Limits of configuration change listings
+const (
+	configChangeDefaultLimit = 50
+	configChangeMaxLimit     = 500
+)
+
+// settingRange is the range a runtime setting can be changed within
+type settingRange struct {
+	min, max int
+}
+
+// settingRanges keeps runtime changes within values that are safe to apply without a restart
+var settingRanges = map[string]settingRange{
+	models.SettingWorkerCount:    {1, 1000},
+	models.SettingTickIntervalMs: {100, 60000},
+	models.SettingCleanupDays:    {1, 3650},
+}
+
+// SystemService handles the configuration API
+type SystemService struct {
+	config       *config.Config
+	settingsRepo *repository.SettingsRepository
+	flags        *features.Store
+	scheduler    *scheduler.Scheduler
+}
+
+// NewSystemService creates a new system service
+func NewSystemService(cfg *config.Config, settingsRepo *repository.SettingsRepository, flags *features.Store, sched *scheduler.Scheduler) *SystemService {
+	return &SystemService{
+		config:       cfg,
+		settingsRepo: settingsRepo,
+		flags:        flags,
+		scheduler:    sched,
+	}
+}
+
+// GetConfig returns the effective configuration with secrets redacted. Runtime settings show
+// the values in effect on this instance rather than those from the environment.
+func (s *SystemService) GetConfig() map[string]interface{} {
+	cfg := s.config.Redacted()
+	if section, ok := cfg["scheduler"].(map[string]interface{}); ok {
+		for name, value := range s.scheduler.Settings() {
+			section[name] = value
+		}
+	}
+	return cfg
+}
+
+// UpdateConfig changes runtime settings for every instance and records each change. Settings
+// left out of the request, or set to their current value, are unchanged.
+func (s *SystemService) UpdateConfig(ctx context.Context, req *models.UpdateRuntimeConfigRequest, userID *uuid.UUID) (map[string]interface{}, error) {
+	requested := map[string]*int{
+		models.SettingWorkerCount:    req.WorkerCount,
+		models.SettingTickIntervalMs: req.TickIntervalMs,
+		models.SettingCleanupDays:    req.CleanupDays,
+	}
+
+	current := s.scheduler.Settings()
+	var settings []models.RuntimeSetting
+	var changes []models.ConfigChange
+	for name, value := range requested {
+		if value == nil {
+			continue
+		}
+		limits := settingRanges[name]
+		if *value < limits.min || *value > limits.max {
+			return nil, fmt.Errorf("%w: %s must be between %d and %d", ErrInvalidSetting, name, limits.min, limits.max)
+		}
+		if *value == current[name] {
+			continue
+		}
+		settings = append(settings, models.RuntimeSetting{
+			Name:      name,
+			Value:     *value,
+			UpdatedBy: userID,
+			UpdatedAt: time.Now(),
+		})
+		changes = append(changes, models.ConfigChange{
+			ID:        uuid.New(),
+			Setting:   name,
+			OldValue:  current[name],
+			NewValue:  *value,
+			ChangedBy: userID,
+			CreatedAt: time.Now(),
+		})
+	}
+
+	if len(settings) > 0 {
+		if err := s.settingsRepo.Save(ctx, settings, changes); err != nil {
+			return nil, fmt.Errorf("failed to save runtime settings: %w", err)
+		}
+		for _, change := range changes {
+			log.Printf("config: %s changed from %d to %d by %s", change.Setting, change.OldValue, change.NewValue, changedBy(change.ChangedBy))
+		}
+		// Other instances pick the change up on their next settings sync
+		s.scheduler.ApplySettings(settings)
+	}
+
+	return s.GetConfig(), nil
+}
+
+// ListChanges lists the latest runtime setting changes, newest first
+func (s *SystemService) ListChanges(ctx context.Context, limit int) ([]models.ConfigChange, error) {
+	if limit <= 0 {
+		limit = configChangeDefaultLimit
+	}
+	if limit > configChangeMaxLimit {
+		limit = configChangeMaxLimit
+	}
+	return s.settingsRepo.FindChanges(ctx, limit)
+}
+
+// ListFlags lists the feature flags with their rules from each source and the one in effect
+func (s *SystemService) ListFlags() []features.Status {
+	return s.flags.List()
+}
+
+// SetFlag overrides a feature flag's rule on every instance
+func (s *SystemService) SetFlag(ctx context.Context, name string, rule features.Rule, userID *uuid.UUID) (*features.Status, error) {
+	if features.Lookup(name) == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFlag, name)
+	}
+	if !rule.Enabled && len(rule.Tenants) > 0 {
+		return nil, fmt.Errorf("%w: tenants only apply to an enabled flag", ErrInvalidSetting)
+	}
+	old := s.flags.Rule(name)
+	if err := s.flags.Set(ctx, name, rule); err != nil {
+		return nil, err
+	}
+	// Other instances pick the override up on their next settings sync
+	log.Printf("config: feature flag %s changed from %s to %s by %s", name, old, rule, changedBy(userID))
+	return s.flagStatus(name), nil
+}
+
+// ClearFlag removes a feature flag's override, so FEATURE_FLAGS or its default applies again
+func (s *SystemService) ClearFlag(ctx context.Context, name string, userID *uuid.UUID) (*features.Status, error) {
+	if features.Lookup(name) == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFlag, name)
+	}
+	old := s.flags.Rule(name)
+	if err := s.flags.Clear(ctx, name); err != nil {
+		return nil, err
+	}
+	log.Printf("config: feature flag %s override cleared by %s; changed from %s to %s", name, changedBy(userID), old, s.flags.Rule(name))
+	return s.flagStatus(name), nil
+}
+
+// flagStatus returns the status of a known flag
+func (s *SystemService) flagStatus(name string) *features.Status {
+	for _, status := range s.flags.List() {
+		if status.Name == name {
+			return &status
+		}
+	}
+	return nil
+}
+
+// changedBy describes who made a change, for logs
+func changedBy(userID *uuid.UUID) string {
+	if userID == nil {
+		return "an anonymous client"
+	}
+	return userID.String()
+}
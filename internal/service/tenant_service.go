@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/egress"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/minisource/scheduler/internal/repository"
+	"github.com/minisource/scheduler/internal/scheduler"
+)
+
+// ErrInvalidTenantSettings is returned when tenant settings fail validation
+var ErrInvalidTenantSettings = errors.New("invalid tenant settings")
+
+// ErrTenantPaused is returned when triggering a job of a paused tenant
+var ErrTenantPaused = conflict("tenant is paused")
+
+// TenantService manages per-tenant overrides of service-wide limits
+type TenantService struct {
+	config        config.LimitsConfig
+	tenantRepo    *repository.TenantRepository
+	executionRepo *repository.ExecutionRepository
+	rateLimiter   *scheduler.RateLimiter
+}
+
+// NewTenantService creates a new tenant service
+func NewTenantService(cfg config.LimitsConfig, tenantRepo *repository.TenantRepository, executionRepo *repository.ExecutionRepository, rateLimiter *scheduler.RateLimiter) *TenantService {
+	return &TenantService{
+		config:        cfg,
+		tenantRepo:    tenantRepo,
+		executionRepo: executionRepo,
+		rateLimiter:   rateLimiter,
+	}
+}
+
+// Settings returns a tenant's overrides, or empty overrides if none are stored
+func (s *TenantService) Settings(ctx context.Context, tenantID uuid.UUID) models.TenantSettings {
+	settings, err := s.tenantRepo.FindSettings(ctx, tenantID)
+	if err != nil {
+		return models.TenantSettings{TenantID: tenantID}
+	}
+	return *settings
+}
+
+// Paused reports whether a tenant is paused, so none of its jobs are dispatched
+func (s *TenantService) Paused(ctx context.Context, tenantID uuid.UUID) bool {
+	return s.Settings(ctx, tenantID).PausedAt != nil
+}
+
+// setPaused pauses a tenant with a reason, or resumes it when pausedAt is nil
+func (s *TenantService) setPaused(ctx context.Context, tenantID uuid.UUID, pausedAt *time.Time, reason string) error {
+	return s.tenantRepo.SetPaused(ctx, tenantID, pausedAt, reason)
+}
+
+// Limits returns the limits in effect for a tenant
+func (s *TenantService) Limits(ctx context.Context, tenantID uuid.UUID) models.TenantLimits {
+	return s.effective(s.Settings(ctx, tenantID))
+}
+
+// Get returns a tenant's overrides and effective limits
+func (s *TenantService) Get(ctx context.Context, tenantID uuid.UUID) *models.TenantSettingsView {
+	settings := s.Settings(ctx, tenantID)
+	return &models.TenantSettingsView{
+		Settings:  settings,
+		Effective: s.effective(settings),
+	}
+}
+
+// Update updates a tenant's overrides
+func (s *TenantService) Update(ctx context.Context, tenantID uuid.UUID, req *models.UpdateTenantSettingsRequest) (*models.TenantSettingsView, error) {
+	settings := s.Settings(ctx, tenantID)
+
+	if req.MaxPayloadBytes != nil {
+		if *req.MaxPayloadBytes < 0 {
+			return nil, fmt.Errorf("%w: max_payload_bytes cannot be negative", ErrInvalidTenantSettings)
+		}
+		settings.MaxPayloadBytes = *req.MaxPayloadBytes
+	}
+	if req.MaxHeadersBytes != nil {
+		if *req.MaxHeadersBytes < 0 {
+			return nil, fmt.Errorf("%w: max_headers_bytes cannot be negative", ErrInvalidTenantSettings)
+		}
+		settings.MaxHeadersBytes = *req.MaxHeadersBytes
+	}
+	if req.ExecutionsPerMinute != nil {
+		if *req.ExecutionsPerMinute < 0 {
+			return nil, fmt.Errorf("%w: executions_per_minute cannot be negative", ErrInvalidTenantSettings)
+		}
+		settings.ExecutionsPerMinute = *req.ExecutionsPerMinute
+	}
+	if req.AllowInsecureTLS != nil {
+		settings.AllowInsecureTLS = *req.AllowInsecureTLS
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "Local" {
+			return nil, fmt.Errorf("%w: invalid timezone %q", ErrInvalidTenantSettings, *req.Timezone)
+		}
+		settings.Timezone = *req.Timezone
+		if settings.Timezone == models.HistoryTimezoneUTC {
+			settings.Timezone = ""
+		}
+	}
+	if req.EgressAllow != nil {
+		rules, err := egressRules(*req.EgressAllow)
+		if err != nil {
+			return nil, fmt.Errorf("%w: egress_allow: %v", ErrInvalidTenantSettings, err)
+		}
+		settings.EgressAllow = rules
+	}
+	if req.EgressDeny != nil {
+		rules, err := egressRules(*req.EgressDeny)
+		if err != nil {
+			return nil, fmt.Errorf("%w: egress_deny: %v", ErrInvalidTenantSettings, err)
+		}
+		settings.EgressDeny = rules
+	}
+
+	if err := s.tenantRepo.SaveSettings(ctx, &settings); err != nil {
+		return nil, err
+	}
+
+	return &models.TenantSettingsView{
+		Settings:  settings,
+		Effective: s.effective(settings),
+	}, nil
+}
+
+// UpdateGrants changes what a tenant's jobs may access with the service's own credentials.
+// Only platform operators grant access, since tenants could otherwise reach each other's data.
+func (s *TenantService) UpdateGrants(ctx context.Context, tenantID uuid.UUID, req *models.UpdateTenantGrantsRequest) (*models.TenantSettingsView, error) {
+	settings := s.Settings(ctx, tenantID)
+
+	if req.StorageAllow != nil {
+		if _, err := scheduler.ParseStorageRules(*req.StorageAllow); err != nil {
+			return nil, fmt.Errorf("%w: storage_allow: %v", ErrInvalidTenantSettings, err)
+		}
+		settings.StorageAllow = encodeRules(*req.StorageAllow)
+	}
+	if req.CommandAllow != nil {
+		for _, name := range *req.CommandAllow {
+			if strings.ContainsAny(name, ",=") {
+				return nil, fmt.Errorf("%w: command_allow: invalid command name %q", ErrInvalidTenantSettings, name)
+			}
+		}
+		settings.CommandAllow = encodeRules(*req.CommandAllow)
+	}
+
+	if err := s.tenantRepo.SaveSettings(ctx, &settings); err != nil {
+		return nil, err
+	}
+
+	return &models.TenantSettingsView{
+		Settings:  settings,
+		Effective: s.effective(settings),
+	}, nil
+}
+
+// Usage returns the tenant's execution rate in the current window
+func (s *TenantService) Usage(ctx context.Context, tenantID uuid.UUID) (*models.TenantUsage, error) {
+	limit := s.Limits(ctx, tenantID).ExecutionsPerMinute
+
+	started, resetsAt, err := s.rateLimiter.Used(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	queued, err := s.executionRepo.CountQueued(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := int64(-1)
+	if limit > 0 {
+		remaining = int64(limit) - started
+		if remaining < 0 {
+			remaining = 0
+		}
+	}
+
+	return &models.TenantUsage{
+		ExecutionsPerMinute: limit,
+		Started:             started,
+		Remaining:           remaining,
+		Queued:              queued,
+		WindowResetsAt:      resetsAt,
+	}, nil
+}
+
+// effective applies a tenant's overrides to the global defaults
+func (s *TenantService) effective(settings models.TenantSettings) models.TenantLimits {
+	limits := models.TenantLimits{
+		MaxPayloadBytes: s.config.MaxPayloadBytes,
+		MaxHeadersBytes: s.config.MaxHeadersBytes,
+
+		ExecutionsPerMinute: s.config.ExecutionsPerMinute,
+		AllowInsecureTLS:    settings.AllowInsecureTLS,
+		Timezone:            models.HistoryTimezoneUTC,
+	}
+	if settings.MaxPayloadBytes > 0 {
+		limits.MaxPayloadBytes = settings.MaxPayloadBytes
+	}
+	if settings.MaxHeadersBytes > 0 {
+		limits.MaxHeadersBytes = settings.MaxHeadersBytes
+	}
+	if settings.ExecutionsPerMinute > 0 {
+		limits.ExecutionsPerMinute = settings.ExecutionsPerMinute
+	}
+	if settings.Timezone != "" {
+		limits.Timezone = settings.Timezone
+	}
+	_ = json.Unmarshal(settings.EgressAllow, &limits.EgressAllow)
+	_ = json.Unmarshal(settings.EgressDeny, &limits.EgressDeny)
+	_ = json.Unmarshal(settings.StorageAllow, &limits.StorageAllow)
+	_ = json.Unmarshal(settings.CommandAllow, &limits.CommandAllow)
+	return limits
+}
+
+// egressRules validates a list of egress rules and encodes it for storage; an empty list is
+// stored as none
+func egressRules(entries []string) (json.RawMessage, error) {
+	if _, err := egress.ParseRules(entries); err != nil {
+		return nil, err
+	}
+	return encodeRules(entries), nil
+}
+
+// encodeRules encodes a list of validated rules for storage, dropping blank entries; an
+// empty list is stored as none
+func encodeRules(entries []string) json.RawMessage {
+	var kept []string
+	for _, entry := range entries {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			kept = append(kept, entry)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	encoded, _ := json.Marshal(kept)
+	return encoded
+}
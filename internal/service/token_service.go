@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// ErrTokenInvalid is returned when a bearer token's signature or claims don't verify
+var ErrTokenInvalid = errors.New("invalid bearer token")
+
+// ErrTokenAccessDenied is returned when a verified token names no tenant, user or role
+var ErrTokenAccessDenied = errors.New("token is not permitted to access the scheduler")
+
+// tokenAlgorithms are the signature algorithms accepted on bearer tokens; "none" never is
+var tokenAlgorithms = []jose.SignatureAlgorithm{
+	jose.HS256, jose.HS384, jose.HS512,
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// TokenService verifies the JWT bearer tokens of API clients and reads the tenant, user and
+// roles they act for from the verified claims
+type TokenService struct {
+	config config.AuthConfig
+	keySet *oidc.RemoteKeySet // Nil without JWT_JWKS_URL
+}
+
+// NewTokenService creates a new token service. Keys from JWT_JWKS_URL are fetched on first
+// use and again whenever a token names a key ID not seen yet, so keys can be rotated.
+func NewTokenService(cfg config.AuthConfig) *TokenService {
+	s := &TokenService{config: cfg}
+	if cfg.JWKSURL != "" {
+		s.keySet = oidc.NewRemoteKeySet(context.Background(), cfg.JWKSURL)
+	}
+	return s
+}
+
+// Enabled reports whether bearer tokens can be verified
+func (s *TokenService) Enabled() bool {
+	return s.config.JWTSecret != "" || s.keySet != nil
+}
+
+// Required reports whether API requests must carry a verified token, rather than naming their
+// tenant and user in the X-Tenant-ID and X-User-ID headers
+func (s *TokenService) Required() bool {
+	return s.config.Mode == "jwt"
+}
+
+// Verify checks a bearer token's signature, expiry, issuer and audience and returns the
+// identity it carries
+func (s *TokenService) Verify(ctx context.Context, raw string) (*models.Identity, error) {
+	payload, err := s.verifySignature(ctx, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenInvalid, err)
+	}
+
+	var registered jwt.Claims
+	if err := json.Unmarshal(payload, &registered); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenInvalid, err)
+	}
+	if registered.Expiry == nil {
+		return nil, fmt.Errorf("%w: no exp claim", ErrTokenInvalid)
+	}
+	expected := jwt.Expected{Issuer: s.config.Issuer, Time: time.Now()}
+	if s.config.Audience != "" {
+		expected.AnyAudience = jwt.Audience{s.config.Audience}
+	}
+	leeway := time.Duration(s.config.LeewaySeconds) * time.Second
+	if err := registered.ValidateWithLeeway(expected, leeway); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenInvalid, err)
+	}
+
+	var claims map[string]interface{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenInvalid, err)
+	}
+	return s.identity(registered, claims)
+}
+
+// verifySignature returns the payload of a token signed with the shared secret or a key from
+// the key set, depending on its algorithm
+func (s *TokenService) verifySignature(ctx context.Context, raw string) ([]byte, error) {
+	signed, err := jose.ParseSignedCompact(raw, tokenAlgorithms)
+	if err != nil {
+		return nil, err
+	}
+	if len(signed.Signatures) != 1 {
+		return nil, errors.New("token must carry exactly one signature")
+	}
+
+	if strings.HasPrefix(signed.Signatures[0].Header.Algorithm, "HS") {
+		if s.config.JWTSecret == "" {
+			return nil, errors.New("HMAC tokens are not accepted without JWT_SECRET")
+		}
+		return signed.Verify([]byte(s.config.JWTSecret))
+	}
+	if s.keySet == nil {
+		return nil, errors.New("asymmetric tokens are not accepted without JWT_JWKS_URL")
+	}
+	return s.keySet.VerifySignature(ctx, raw)
+}
+
+// identity builds an identity from verified token claims. A user claim that isn't a UUID,
+// such as a sub naming an account, maps to a stable ID derived from the issuer and value.
+func (s *TokenService) identity(registered jwt.Claims, claims map[string]interface{}) (*models.Identity, error) {
+	tenantID, err := uuid.Parse(stringClaim(claims, s.config.TenantClaim))
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing or invalid %q claim", ErrTokenAccessDenied, s.config.TenantClaim)
+	}
+
+	user := stringClaim(claims, s.config.UserClaim)
+	if user == "" {
+		return nil, fmt.Errorf("%w: missing %q claim", ErrTokenAccessDenied, s.config.UserClaim)
+	}
+	userID, err := uuid.Parse(user)
+	if err != nil {
+		userID = uuid.NewSHA1(uuid.NameSpaceURL, []byte(registered.Issuer+"#"+user))
+	}
+
+	identity := &models.Identity{
+		UserID:   userID,
+		Subject:  registered.Subject,
+		Email:    stringClaim(claims, "email"),
+		Name:     stringClaim(claims, "name"),
+		TenantID: tenantID,
+	}
+
+	seen := make(map[models.Role]bool)
+	for _, value := range stringsClaim(claims, s.config.RolesClaim) {
+		if role := models.Role(value); role.Valid() && !seen[role] {
+			seen[role] = true
+			identity.Roles = append(identity.Roles, role)
+		}
+	}
+	if len(identity.Roles) == 0 {
+		if role := models.Role(s.config.DefaultRole); role.Valid() {
+			identity.Roles = append(identity.Roles, role)
+		}
+	}
+	if len(identity.Roles) == 0 {
+		return nil, fmt.Errorf("%w: no known role in %q claim", ErrTokenAccessDenied, s.config.RolesClaim)
+	}
+
+	return identity, nil
+}
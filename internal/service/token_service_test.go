@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/google/uuid"
+	"github.com/minisource/scheduler/config"
+	"github.com/minisource/scheduler/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJWTSecret = "0123456789abcdef0123456789abcdef"
+
+// testTokenConfig is a token configuration verifying HMAC tokens with testJWTSecret
+func testTokenConfig() config.AuthConfig {
+	return config.AuthConfig{
+		Mode:          "jwt",
+		JWTSecret:     testJWTSecret,
+		Issuer:        "https://issuer.example.com",
+		Audience:      "scheduler",
+		TenantClaim:   "tenant_id",
+		UserClaim:     "sub",
+		RolesClaim:    "roles",
+		DefaultRole:   string(models.RoleViewer),
+		LeewaySeconds: 30,
+	}
+}
+
+// signToken signs claims with an algorithm and key
+func signToken(t *testing.T, alg jose.SignatureAlgorithm, key interface{}, claims map[string]interface{}) string {
+	t.Helper()
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key}, nil)
+	require.NoError(t, err)
+	raw, err := jwt.Signed(signer).Claims(claims).Serialize()
+	require.NoError(t, err)
+	return raw
+}
+
+// tokenClaims returns valid claims, changed by edit
+func tokenClaims(tenantID uuid.UUID, edit func(map[string]interface{})) map[string]interface{} {
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":       "https://issuer.example.com",
+		"aud":       "scheduler",
+		"sub":       "alice",
+		"tenant_id": tenantID.String(),
+		"roles":     []string{"operator"},
+		"iat":       now.Unix(),
+		"exp":       now.Add(time.Hour).Unix(),
+	}
+	if edit != nil {
+		edit(claims)
+	}
+	return claims
+}
+
+func TestTokenServiceVerify(t *testing.T) {
+	tenantID := uuid.New()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"alice"}`)) + "."
+
+	tests := []struct {
+		name      string
+		token     func(t *testing.T) string
+		wantErr   error
+		wantRoles []models.Role
+	}{
+		{
+			name: "valid HMAC token",
+			token: func(t *testing.T) string {
+				return signToken(t, jose.HS256, []byte(testJWTSecret), tokenClaims(tenantID, nil))
+			},
+			wantRoles: []models.Role{models.RoleOperator},
+		},
+		{
+			name:    "none algorithm",
+			token:   func(t *testing.T) string { return unsigned },
+			wantErr: ErrTokenInvalid,
+		},
+		{
+			name: "wrong secret",
+			token: func(t *testing.T) string {
+				return signToken(t, jose.HS256, []byte("another-secret-another-secret-xx"), tokenClaims(tenantID, nil))
+			},
+			wantErr: ErrTokenInvalid,
+		},
+		{
+			name:    "asymmetric token without a key set",
+			token:   func(t *testing.T) string { return signToken(t, jose.RS256, rsaKey, tokenClaims(tenantID, nil)) },
+			wantErr: ErrTokenInvalid,
+		},
+		{
+			name:    "malformed",
+			token:   func(t *testing.T) string { return "not.a.token" },
+			wantErr: ErrTokenInvalid,
+		},
+		{
+			name: "no expiry",
+			token: func(t *testing.T) string {
+				return signToken(t, jose.HS256, []byte(testJWTSecret), tokenClaims(tenantID, func(c map[string]interface{}) { delete(c, "exp") }))
+			},
+			wantErr: ErrTokenInvalid,
+		},
+		{
+			name: "expired",
+			token: func(t *testing.T) string {
+				return signToken(t, jose.HS256, []byte(testJWTSecret), tokenClaims(tenantID, func(c map[string]interface{}) {
+					c["exp"] = time.Now().Add(-time.Minute).Unix()
+				}))
+			},
+			wantErr: ErrTokenInvalid,
+		},
+		{
+			name: "expired within the leeway",
+			token: func(t *testing.T) string {
+				return signToken(t, jose.HS256, []byte(testJWTSecret), tokenClaims(tenantID, func(c map[string]interface{}) {
+					c["exp"] = time.Now().Add(-10 * time.Second).Unix()
+				}))
+			},
+			wantRoles: []models.Role{models.RoleOperator},
+		},
+		{
+			name: "not yet valid",
+			token: func(t *testing.T) string {
+				return signToken(t, jose.HS256, []byte(testJWTSecret), tokenClaims(tenantID, func(c map[string]interface{}) {
+					c["nbf"] = time.Now().Add(time.Minute).Unix()
+				}))
+			},
+			wantErr: ErrTokenInvalid,
+		},
+		{
+			name: "wrong issuer",
+			token: func(t *testing.T) string {
+				return signToken(t, jose.HS256, []byte(testJWTSecret), tokenClaims(tenantID, func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" }))
+			},
+			wantErr: ErrTokenInvalid,
+		},
+		{
+			name: "wrong audience",
+			token: func(t *testing.T) string {
+				return signToken(t, jose.HS256, []byte(testJWTSecret), tokenClaims(tenantID, func(c map[string]interface{}) { c["aud"] = "other" }))
+			},
+			wantErr: ErrTokenInvalid,
+		},
+		{
+			name: "no tenant",
+			token: func(t *testing.T) string {
+				return signToken(t, jose.HS256, []byte(testJWTSecret), tokenClaims(tenantID, func(c map[string]interface{}) { delete(c, "tenant_id") }))
+			},
+			wantErr: ErrTokenAccessDenied,
+		},
+		{
+			name: "no role falls back to the default",
+			token: func(t *testing.T) string {
+				return signToken(t, jose.HS256, []byte(testJWTSecret), tokenClaims(tenantID, func(c map[string]interface{}) { delete(c, "roles") }))
+			},
+			wantRoles: []models.Role{models.RoleViewer},
+		},
+		{
+			name: "unknown roles are dropped",
+			token: func(t *testing.T) string {
+				return signToken(t, jose.HS256, []byte(testJWTSecret), tokenClaims(tenantID, func(c map[string]interface{}) {
+					c["roles"] = []string{"root", "admin", "admin"}
+				}))
+			},
+			wantRoles: []models.Role{models.RoleAdmin},
+		},
+	}
+
+	tokens := NewTokenService(testTokenConfig())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := tokens.Verify(context.Background(), tt.token(t))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tenantID, identity.TenantID)
+			assert.Equal(t, tt.wantRoles, identity.Roles)
+		})
+	}
+}
+
+func TestTokenServiceWithoutDefaultRole(t *testing.T) {
+	cfg := testTokenConfig()
+	cfg.DefaultRole = ""
+	tokens := NewTokenService(cfg)
+
+	raw := signToken(t, jose.HS256, []byte(testJWTSecret), tokenClaims(uuid.New(), func(c map[string]interface{}) { delete(c, "roles") }))
+	_, err := tokens.Verify(context.Background(), raw)
+	assert.ErrorIs(t, err, ErrTokenAccessDenied)
+}
+
+func TestTokenServiceUserID(t *testing.T) {
+	tokens := NewTokenService(testTokenConfig())
+	userID := uuid.New()
+
+	tests := []struct {
+		name string
+		sub  string
+		want uuid.UUID
+	}{
+		{name: "UUID subject", sub: userID.String(), want: userID},
+		{name: "account subject", sub: "alice", want: uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://issuer.example.com#alice"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := signToken(t, jose.HS256, []byte(testJWTSecret), tokenClaims(uuid.New(), func(c map[string]interface{}) { c["sub"] = tt.sub }))
+			identity, err := tokens.Verify(context.Background(), raw)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, identity.UserID)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTriggerLimits(t *testing.T) {
+	zero, positive, negative := 0, 5, -1
+
+	tests := []struct {
+		name    string
+		limit   *int
+		dedupe  *int
+		wantErr bool
+	}{
+		{name: "defaults"},
+		{name: "zero", limit: &zero, dedupe: &zero},
+		{name: "positive", limit: &positive, dedupe: &positive},
+		{name: "negative limit", limit: &negative, wantErr: true},
+		{name: "negative dedupe", dedupe: &negative, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTriggerLimits(tt.limit, tt.dedupe)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidTriggerLimit)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
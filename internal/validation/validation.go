@@ -0,0 +1,68 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/minisource/scheduler/internal/models"
+)
+
+// validate checks structs against their validate tags, naming fields by their JSON names
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+
+// Struct checks a request against its validate tags, returning a problem for each field that
+// fails one. Nested fields are named by their path, such as blackout_windows[0].days.
+func Struct(req interface{}) []models.FieldError {
+	var failed validator.ValidationErrors
+	if !errors.As(validate.Struct(req), &failed) {
+		return nil
+	}
+
+	fields := make([]models.FieldError, 0, len(failed))
+	for _, e := range failed {
+		// The namespace starts with the request type's name
+		_, field, _ := strings.Cut(e.Namespace(), ".")
+		fields = append(fields, models.FieldError{Field: field, Message: message(field, e)})
+	}
+	return fields
+}
+
+// message describes why a field failed a validate tag
+func message(field string, e validator.FieldError) string {
+	switch e.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "min", "max":
+		bound := "at least"
+		if e.Tag() == "max" {
+			bound = "at most"
+		}
+		switch e.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("%s must be %s %s characters", field, bound, e.Param())
+		case reflect.Slice, reflect.Map:
+			return fmt.Sprintf("%s must have %s %s items", field, bound, e.Param())
+		}
+		return fmt.Sprintf("%s must be %s %s", field, bound, e.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", field, strings.ReplaceAll(e.Param(), " ", ", "))
+	case "url":
+		return fmt.Sprintf("%s must be a URL", field)
+	}
+	return fmt.Sprintf("%s fails the %s check", field, e.Tag())
+}
@@ -0,0 +1,2 @@
+-- +migrate Up
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS response_projection TEXT;
@@ -0,0 +1,9 @@
+-- +migrate Up
+-- Backfill tenant ownership on history rows so history queries can be tenant scoped
+UPDATE job_history h
+SET tenant_id = j.tenant_id
+FROM jobs j
+WHERE h.job_id = j.id
+  AND (h.tenant_id IS NULL OR h.tenant_id = '00000000-0000-0000-0000-000000000000');
+
+CREATE INDEX IF NOT EXISTS idx_job_executions_tenant_id_id ON job_executions(tenant_id, id);
@@ -0,0 +1,4 @@
+-- +migrate Down
+ALTER TABLE job_executions DROP COLUMN IF EXISTS ack_comment;
+ALTER TABLE job_executions DROP COLUMN IF EXISTS acknowledged_by;
+ALTER TABLE job_executions DROP COLUMN IF EXISTS acknowledged_at;
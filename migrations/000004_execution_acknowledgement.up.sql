@@ -0,0 +1,4 @@
+-- +migrate Up
+ALTER TABLE job_executions ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMPTZ;
+ALTER TABLE job_executions ADD COLUMN IF NOT EXISTS acknowledged_by UUID;
+ALTER TABLE job_executions ADD COLUMN IF NOT EXISTS ack_comment TEXT;
@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TRIGGER IF EXISTS update_incidents_updated_at ON incidents;
+DROP TABLE IF EXISTS incidents;
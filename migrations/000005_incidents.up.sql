@@ -0,0 +1,28 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS incidents (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL,
+    group_key VARCHAR(600) NOT NULL,
+    job_id UUID REFERENCES jobs(id) ON DELETE CASCADE,
+    target_host VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved')),
+    started_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ,
+    failure_count BIGINT DEFAULT 0,
+    sample_errors JSONB,
+    first_execution_id UUID,
+    last_execution_id UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_incidents_tenant_id ON incidents(tenant_id);
+CREATE INDEX idx_incidents_job_id ON incidents(job_id);
+CREATE INDEX idx_incidents_status ON incidents(status);
+-- At most one open incident per group
+CREATE UNIQUE INDEX idx_incidents_open_group ON incidents(tenant_id, group_key) WHERE status = 'open';
+
+CREATE TRIGGER update_incidents_updated_at
+    BEFORE UPDATE ON incidents
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,10 @@
+-- +migrate Down
+ALTER TABLE incidents
+    DROP COLUMN IF EXISTS escalated_at,
+    DROP COLUMN IF EXISTS acknowledged_by,
+    DROP COLUMN IF EXISTS acknowledged_at;
+
+DROP TRIGGER IF EXISTS update_notification_policies_updated_at ON notification_policies;
+DROP TRIGGER IF EXISTS update_notification_channels_updated_at ON notification_channels;
+DROP TABLE IF EXISTS notification_policies;
+DROP TABLE IF EXISTS notification_channels;
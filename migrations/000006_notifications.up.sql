@@ -0,0 +1,41 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS notification_channels (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    config JSONB,
+    enabled BOOLEAN DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_channels_tenant ON notification_channels(tenant_id);
+
+CREATE TABLE IF NOT EXISTS notification_policies (
+    tenant_id UUID PRIMARY KEY,
+    dedupe_window_seconds INTEGER DEFAULT 900,
+    min_failures INTEGER DEFAULT 1,
+    escalate_after_minutes INTEGER DEFAULT 0,
+    escalation_channel_id UUID REFERENCES notification_channels(id) ON DELETE SET NULL,
+    quiet_hours_start VARCHAR(5),
+    quiet_hours_end VARCHAR(5),
+    timezone VARCHAR(50) DEFAULT 'UTC',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+ALTER TABLE incidents
+    ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS acknowledged_by UUID,
+    ADD COLUMN IF NOT EXISTS escalated_at TIMESTAMPTZ;
+
+CREATE TRIGGER update_notification_channels_updated_at
+    BEFORE UPDATE ON notification_channels
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_notification_policies_updated_at
+    BEFORE UPDATE ON notification_policies
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
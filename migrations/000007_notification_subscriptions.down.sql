@@ -0,0 +1,5 @@
+-- +migrate Down
+DROP TABLE IF EXISTS notification_subscriptions;
+
+ALTER TABLE notification_channels
+    DROP COLUMN IF EXISTS subscriptions_only;
@@ -0,0 +1,16 @@
+-- +migrate Up
+ALTER TABLE notification_channels
+    ADD COLUMN IF NOT EXISTS subscriptions_only BOOLEAN DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS notification_subscriptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    channel_id UUID NOT NULL REFERENCES notification_channels(id) ON DELETE CASCADE,
+    job_id UUID REFERENCES jobs(id) ON DELETE CASCADE,
+    tags JSONB,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_subscriptions_tenant_user ON notification_subscriptions(tenant_id, user_id);
+CREATE INDEX idx_subscriptions_channel ON notification_subscriptions(channel_id);
@@ -0,0 +1,5 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_jobs_client_reference;
+
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS client_reference;
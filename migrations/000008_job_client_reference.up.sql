@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS client_reference VARCHAR(255);
+
+-- Idempotency keys are unique per tenant; NULLs don't conflict
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_client_reference ON jobs(tenant_id, client_reference);
@@ -0,0 +1,5 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_job_executions_job_status;
+
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS concurrency_policy;
@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS concurrency_policy VARCHAR(20) DEFAULT 'allow'
+        CHECK (concurrency_policy IN ('allow', 'forbid', 'replace'));
+
+CREATE INDEX IF NOT EXISTS idx_job_executions_job_status ON job_executions(job_id, status);
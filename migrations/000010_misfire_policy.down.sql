@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS misfire_policy;
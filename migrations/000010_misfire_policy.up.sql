@@ -0,0 +1,4 @@
+-- +migrate Up
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS misfire_policy VARCHAR(20) DEFAULT 'fire_once'
+        CHECK (misfire_policy IN ('fire_once', 'fire_all', 'skip'));
@@ -0,0 +1,7 @@
+-- +migrate Down
+UPDATE jobs SET status = 'disabled' WHERE status = 'completed';
+
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS jobs_status_check;
+ALTER TABLE jobs
+    ADD CONSTRAINT jobs_status_check
+        CHECK (status IN ('active', 'paused', 'disabled', 'deleted'));
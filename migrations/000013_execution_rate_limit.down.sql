@@ -0,0 +1,11 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_job_executions_queued;
+
+UPDATE job_executions SET status = 'pending' WHERE status = 'queued';
+
+ALTER TABLE job_executions DROP CONSTRAINT IF EXISTS job_executions_status_check;
+ALTER TABLE job_executions
+    ADD CONSTRAINT job_executions_status_check
+        CHECK (status IN ('pending', 'running', 'completed', 'failed', 'retrying', 'cancelled', 'timeout'));
+
+ALTER TABLE tenant_settings DROP COLUMN IF EXISTS executions_per_minute;
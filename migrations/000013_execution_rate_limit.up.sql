@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE tenant_settings ADD COLUMN IF NOT EXISTS executions_per_minute INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE job_executions DROP CONSTRAINT IF EXISTS job_executions_status_check;
+ALTER TABLE job_executions
+    ADD CONSTRAINT job_executions_status_check
+        CHECK (status IN ('queued', 'pending', 'running', 'completed', 'failed', 'retrying', 'cancelled', 'timeout'));
+
+CREATE INDEX IF NOT EXISTS idx_job_executions_queued ON job_executions(scheduled_at) WHERE status = 'queued';
@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE jobs DROP COLUMN IF EXISTS body;
+ALTER TABLE jobs DROP COLUMN IF EXISTS content_type;
@@ -0,0 +1,3 @@
+-- +migrate Up
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS content_type VARCHAR(255);
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS body TEXT;
@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE jobs DROP COLUMN IF EXISTS target_config;
+ALTER TABLE jobs DROP COLUMN IF EXISTS target_type;
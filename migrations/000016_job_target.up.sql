@@ -0,0 +1,3 @@
+-- +migrate Up
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS target_type VARCHAR(20) DEFAULT 'http';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS target_config JSONB;
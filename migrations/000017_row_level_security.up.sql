@@ -0,0 +1,30 @@
+-- +migrate Up
+-- Row-level security for POSTGRES_TENANT_ISOLATION=rls. Sessions only see rows of the tenant
+-- in app.tenant_id; the scheduler's system pool sets app.bypass_rls. With no tenant bound,
+-- NULLIF yields NULL and no rows match, so a missing scope fails closed.
+-- FORCE applies the policies to the table owner too; superusers and BYPASSRLS roles still
+-- bypass them, so run the service as a plain role.
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'jobs', 'job_executions', 'job_history', 'incidents',
+        'notification_channels', 'notification_policies', 'notification_subscriptions',
+        'tenant_settings', 'secrets'
+    ] LOOP
+        IF to_regclass(t) IS NULL THEN
+            CONTINUE;
+        END IF;
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', t);
+        EXECUTE format(
+            'CREATE POLICY tenant_isolation ON %I
+                USING (current_setting(''app.bypass_rls'', true) = ''on''
+                       OR tenant_id = NULLIF(current_setting(''app.tenant_id'', true), '''')::uuid)
+                WITH CHECK (current_setting(''app.bypass_rls'', true) = ''on''
+                       OR tenant_id = NULLIF(current_setting(''app.tenant_id'', true), '''')::uuid)',
+            t);
+    END LOOP;
+END $$;
@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_jobs_purge_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS purge_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS deleted_at;
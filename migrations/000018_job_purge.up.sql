@@ -0,0 +1,11 @@
+-- +migrate Up
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS purge_at TIMESTAMPTZ;
+
+-- Jobs deleted before purging existed get the default 30 day grace period from their last update
+UPDATE jobs
+SET deleted_at = updated_at,
+    purge_at = updated_at + INTERVAL '30 days'
+WHERE status = 'deleted' AND purge_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_purge_at ON jobs(purge_at) WHERE purge_at IS NOT NULL;
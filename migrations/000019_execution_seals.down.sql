@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE IF EXISTS execution_seals;
+DROP FUNCTION IF EXISTS execution_seals_append_only();
@@ -0,0 +1,40 @@
+-- +migrate Up
+-- Hash chain of finished executions (SCHEDULER_EXECUTION_CHAIN). Seals are append-only.
+CREATE TABLE IF NOT EXISTS execution_seals (
+    tenant_id UUID NOT NULL,
+    seq BIGINT NOT NULL,
+    execution_id UUID NOT NULL,
+    content_hash VARCHAR(64) NOT NULL,
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    sealed_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (tenant_id, seq)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_execution_seals_execution ON execution_seals(execution_id);
+
+CREATE OR REPLACE FUNCTION execution_seals_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'execution_seals is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS execution_seals_no_change ON execution_seals;
+CREATE TRIGGER execution_seals_no_change
+    BEFORE UPDATE OR DELETE ON execution_seals
+    FOR EACH ROW EXECUTE FUNCTION execution_seals_append_only();
+
+DROP TRIGGER IF EXISTS execution_seals_no_truncate ON execution_seals;
+CREATE TRIGGER execution_seals_no_truncate
+    BEFORE TRUNCATE ON execution_seals
+    FOR EACH STATEMENT EXECUTE FUNCTION execution_seals_append_only();
+
+-- Same tenant isolation policy as the other tenant tables (000017)
+ALTER TABLE execution_seals ENABLE ROW LEVEL SECURITY;
+ALTER TABLE execution_seals FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON execution_seals;
+CREATE POLICY tenant_isolation ON execution_seals
+    USING (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid);
@@ -0,0 +1,29 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS job_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_id UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+    revision INTEGER NOT NULL,
+    change VARCHAR(20) NOT NULL,
+    snapshot JSONB NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_job_revisions_job_revision ON job_revisions(job_id, revision);
+CREATE INDEX IF NOT EXISTS idx_job_revisions_tenant ON job_revisions(tenant_id);
+
+-- Existing jobs start from a baseline of their current state; earlier states are unknown
+INSERT INTO job_revisions (job_id, tenant_id, revision, change, snapshot, created_at)
+SELECT j.id, j.tenant_id, 1, 'baseline', to_jsonb(j), NOW()
+FROM jobs j
+WHERE NOT EXISTS (SELECT 1 FROM job_revisions r WHERE r.job_id = j.id);
+
+-- Same tenant isolation policy as the other tenant tables (000017)
+ALTER TABLE job_revisions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE job_revisions FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON job_revisions;
+CREATE POLICY tenant_isolation ON job_revisions
+    USING (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid);
@@ -0,0 +1,7 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_jobs_shift;
+ALTER TABLE jobs DROP COLUMN IF EXISTS shift_ends_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS shift_starts_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS shift_offset_seconds;
+ALTER TABLE jobs DROP COLUMN IF EXISTS shift_id;
+DROP TABLE IF EXISTS schedule_shifts;
@@ -0,0 +1,35 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS schedule_shifts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    offset_seconds BIGINT NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    revert_at TIMESTAMPTZ NOT NULL,
+    reason TEXT,
+    job_ids JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    reverted_at TIMESTAMPTZ,
+    created_by UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_shifts_tenant ON schedule_shifts(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_schedule_shifts_revert ON schedule_shifts(revert_at) WHERE status = 'active';
+
+-- Jobs carry their active shift, so next runs are calculated without a join
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS shift_id UUID REFERENCES schedule_shifts(id) ON DELETE SET NULL;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS shift_offset_seconds BIGINT DEFAULT 0;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS shift_starts_at TIMESTAMPTZ;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS shift_ends_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_jobs_shift ON jobs(shift_id) WHERE shift_id IS NOT NULL;
+
+-- Same tenant isolation policy as the other tenant tables (000017)
+ALTER TABLE schedule_shifts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE schedule_shifts FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON schedule_shifts;
+CREATE POLICY tenant_isolation ON schedule_shifts
+    USING (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid);
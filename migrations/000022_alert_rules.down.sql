@@ -0,0 +1,4 @@
+-- +migrate Down
+ALTER TABLE jobs DROP COLUMN IF EXISTS consecutive_failures;
+DROP TABLE IF EXISTS alert_firings;
+DROP TABLE IF EXISTS alert_rules;
@@ -0,0 +1,50 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(30) NOT NULL,
+    job_id UUID REFERENCES jobs(id) ON DELETE CASCADE,
+    tags JSONB,
+    threshold INTEGER DEFAULT 0,
+    window_minutes INTEGER DEFAULT 0,
+    channel_ids JSONB NOT NULL,
+    enabled BOOLEAN DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_rules_tenant ON alert_rules(tenant_id);
+
+CREATE TABLE IF NOT EXISTS alert_firings (
+    rule_id UUID NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    job_id UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL,
+    fired_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (rule_id, job_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_firings_tenant ON alert_firings(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_alert_firings_job ON alert_firings(job_id);
+
+-- Reset by a successful run, so consecutive failure rules don't have to scan executions
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS consecutive_failures BIGINT DEFAULT 0;
+
+-- Same tenant isolation policy as the other tenant tables (000017)
+ALTER TABLE alert_rules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE alert_rules FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON alert_rules;
+CREATE POLICY tenant_isolation ON alert_rules
+    USING (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid);
+
+ALTER TABLE alert_firings ENABLE ROW LEVEL SECURITY;
+ALTER TABLE alert_firings FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON alert_firings;
+CREATE POLICY tenant_isolation ON alert_firings
+    USING (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid);
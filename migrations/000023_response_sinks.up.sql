@@ -0,0 +1,3 @@
+-- +migrate Up
+-- Where successful responses are streamed instead of being stored on the execution
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS response_sink JSONB;
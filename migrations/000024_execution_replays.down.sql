@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_job_executions_replay_of;
+ALTER TABLE job_executions DROP COLUMN IF EXISTS replay_of;
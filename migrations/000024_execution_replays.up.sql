@@ -0,0 +1,4 @@
+-- +migrate Up
+-- Replays point at the execution whose recorded request they sent again
+ALTER TABLE job_executions ADD COLUMN IF NOT EXISTS replay_of UUID;
+CREATE INDEX IF NOT EXISTS idx_job_executions_replay_of ON job_executions(replay_of) WHERE replay_of IS NOT NULL;
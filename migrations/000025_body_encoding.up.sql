@@ -0,0 +1,3 @@
+-- +migrate Up
+-- base64 bodies are decoded before sending, for binary request bodies
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS body_encoding VARCHAR(10);
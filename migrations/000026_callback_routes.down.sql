@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE IF EXISTS callback_deliveries;
+DROP TABLE IF EXISTS callback_routes;
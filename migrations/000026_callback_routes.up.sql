@@ -0,0 +1,57 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS callback_routes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    position INTEGER DEFAULT 0,
+    tags JSONB,
+    url TEXT NOT NULL,
+    headers JSONB,
+    statuses JSONB,
+    include_response BOOLEAN DEFAULT false,
+    enabled BOOLEAN DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_callback_routes_tenant ON callback_routes(tenant_id);
+
+-- Outbox of execution results waiting to be posted to a callback route
+CREATE TABLE IF NOT EXISTS callback_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    route_id UUID NOT NULL,
+    job_id UUID NOT NULL,
+    execution_id UUID NOT NULL,
+    url TEXT NOT NULL,
+    headers JSONB,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_callback_deliveries_tenant ON callback_deliveries(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_callback_deliveries_due ON callback_deliveries(next_attempt_at) WHERE status = 'pending';
+
+-- Same tenant isolation policy as the other tenant tables (000017)
+ALTER TABLE callback_routes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE callback_routes FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON callback_routes;
+CREATE POLICY tenant_isolation ON callback_routes
+    USING (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid);
+
+ALTER TABLE callback_deliveries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE callback_deliveries FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON callback_deliveries;
+CREATE POLICY tenant_isolation ON callback_deliveries
+    USING (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid);
@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_jobs_due;
+ALTER TABLE jobs DROP COLUMN IF EXISTS claimed_until;
+ALTER TABLE jobs DROP COLUMN IF EXISTS claimed_by;
@@ -0,0 +1,6 @@
+-- +migrate Up
+-- Claims on due jobs under SCHEDULER_DISPATCH_MODE=claim
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS claimed_by VARCHAR(100);
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_due ON jobs(priority DESC, next_run_at ASC) WHERE status = 'active';
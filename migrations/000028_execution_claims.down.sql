@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_executions_pending;
+ALTER TABLE job_executions DROP COLUMN IF EXISTS claimed_until;
+ALTER TABLE job_executions DROP COLUMN IF EXISTS claimed_by;
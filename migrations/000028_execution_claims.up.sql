@@ -0,0 +1,6 @@
+-- +migrate Up
+-- Claims on pending executions, so workers on other instances can pick them up
+ALTER TABLE job_executions ADD COLUMN IF NOT EXISTS claimed_by VARCHAR(100);
+ALTER TABLE job_executions ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_executions_pending ON job_executions(scheduled_at ASC) WHERE status = 'pending';
@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE IF EXISTS config_changes;
+DROP TABLE IF EXISTS runtime_settings;
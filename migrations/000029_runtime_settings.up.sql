@@ -0,0 +1,20 @@
+-- +migrate Up
+-- Runtime overrides of settings from the environment, shared by every instance
+CREATE TABLE IF NOT EXISTS runtime_settings (
+    name VARCHAR(100) PRIMARY KEY,
+    value INTEGER NOT NULL,
+    updated_by UUID,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Audit trail of runtime setting changes
+CREATE TABLE IF NOT EXISTS config_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    setting VARCHAR(100) NOT NULL,
+    old_value INTEGER,
+    new_value INTEGER,
+    changed_by UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_config_changes_created ON config_changes(created_at);
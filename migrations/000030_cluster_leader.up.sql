@@ -0,0 +1,9 @@
+-- +migrate Up
+-- Which instance holds each cluster-wide lock, so any instance can report the leader
+CREATE TABLE IF NOT EXISTS cluster_leaders (
+    lock_key VARCHAR(100) PRIMARY KEY,
+    instance_id VARCHAR(100) NOT NULL,
+    hostname VARCHAR(255),
+    acquired_at TIMESTAMPTZ NOT NULL,
+    heartbeat_at TIMESTAMPTZ NOT NULL
+);
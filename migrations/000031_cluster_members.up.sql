@@ -0,0 +1,15 @@
+-- +migrate Up
+-- Registry of running instances, kept current by their heartbeats
+CREATE TABLE IF NOT EXISTS cluster_members (
+    instance_id VARCHAR(100) PRIMARY KEY,
+    hostname VARCHAR(255),
+    role VARCHAR(20) NOT NULL,
+    phase VARCHAR(20) NOT NULL,
+    capacity INTEGER NOT NULL DEFAULT 0,
+    running INTEGER NOT NULL DEFAULT 0,
+    queued INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMPTZ NOT NULL,
+    heartbeat_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_cluster_members_heartbeat ON cluster_members(heartbeat_at);
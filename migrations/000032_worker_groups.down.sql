@@ -0,0 +1,4 @@
+-- +migrate Down
+ALTER TABLE cluster_members DROP COLUMN IF EXISTS worker_group;
+ALTER TABLE job_executions DROP COLUMN IF EXISTS worker_group;
+ALTER TABLE jobs DROP COLUMN IF EXISTS worker_group;
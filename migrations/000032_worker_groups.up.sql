@@ -0,0 +1,5 @@
+-- +migrate Up
+-- Pin jobs to the workers of a group, e.g. for data residency
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS worker_group VARCHAR(100);
+ALTER TABLE job_executions ADD COLUMN IF NOT EXISTS worker_group VARCHAR(100);
+ALTER TABLE cluster_members ADD COLUMN IF NOT EXISTS worker_group VARCHAR(100);
@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_jobs_resume_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS resume_at;
@@ -0,0 +1,4 @@
+-- +migrate Up
+-- Paused jobs can resume on their own at a set time
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS resume_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_jobs_resume_at ON jobs (resume_at);
@@ -0,0 +1,4 @@
+-- +migrate Down
+ALTER TABLE jobs DROP COLUMN IF EXISTS blackout_windows;
+ALTER TABLE jobs DROP COLUMN IF EXISTS blackout_calendar;
+DROP TABLE IF EXISTS calendars;
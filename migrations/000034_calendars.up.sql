@@ -0,0 +1,28 @@
+-- +migrate Up
+-- Named calendars of blackout windows during which jobs don't run
+CREATE TABLE IF NOT EXISTS calendars (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    kind VARCHAR(20) NOT NULL DEFAULT 'blackout',
+    tenant_wide BOOLEAN DEFAULT false,
+    windows JSONB NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_calendars_tenant_name ON calendars(tenant_id, name);
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS blackout_calendar VARCHAR(100);
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS blackout_windows JSONB;
+
+-- Same tenant isolation policy as the other tenant tables (000017)
+ALTER TABLE calendars ENABLE ROW LEVEL SECURITY;
+ALTER TABLE calendars FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON calendars;
+CREATE POLICY tenant_isolation ON calendars
+    USING (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid);
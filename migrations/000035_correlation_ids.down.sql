@@ -0,0 +1,5 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_executions_correlation;
+ALTER TABLE job_executions DROP COLUMN IF EXISTS correlation_id;
+DROP INDEX IF EXISTS idx_jobs_correlation;
+ALTER TABLE jobs DROP COLUMN IF EXISTS correlation_id;
@@ -0,0 +1,6 @@
+-- +migrate Up
+-- Jobs and their executions can be tied to the business entity they act on
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS correlation_id VARCHAR(255);
+CREATE INDEX IF NOT EXISTS idx_jobs_correlation ON jobs (correlation_id);
+ALTER TABLE job_executions ADD COLUMN IF NOT EXISTS correlation_id VARCHAR(255);
+CREATE INDEX IF NOT EXISTS idx_executions_correlation ON job_executions (correlation_id);
@@ -0,0 +1,5 @@
+-- +migrate Down
+ALTER TABLE jobs DROP COLUMN IF EXISTS holiday_calendar;
+DELETE FROM calendars WHERE kind = 'holiday';
+ALTER TABLE calendars DROP COLUMN IF EXISTS dates;
+ALTER TABLE calendars ALTER COLUMN windows SET NOT NULL;
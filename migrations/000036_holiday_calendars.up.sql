@@ -0,0 +1,5 @@
+-- +migrate Up
+-- Calendars can also hold holiday dates that cron jobs skip
+ALTER TABLE calendars ALTER COLUMN windows DROP NOT NULL;
+ALTER TABLE calendars ADD COLUMN IF NOT EXISTS dates JSONB;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS holiday_calendar VARCHAR(100);
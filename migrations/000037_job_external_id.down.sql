@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_jobs_external_id;
+ALTER TABLE jobs DROP COLUMN IF EXISTS external_id;
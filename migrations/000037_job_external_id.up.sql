@@ -0,0 +1,4 @@
+-- +migrate Up
+-- Jobs synced from an upstream catalog are keyed by its ID; deleted jobs free their key
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS external_id VARCHAR(255);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_external_id ON jobs (tenant_id, external_id) WHERE status <> 'deleted';
@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE jobs DROP COLUMN IF EXISTS max_runs;
+ALTER TABLE jobs DROP COLUMN IF EXISTS end_at;
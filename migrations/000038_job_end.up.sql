@@ -0,0 +1,4 @@
+-- +migrate Up
+-- Recurring jobs can be disabled at a date or after a number of successful runs
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS end_at TIMESTAMPTZ;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS max_runs BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE jobs DROP COLUMN IF EXISTS trigger_dedupe;
+ALTER TABLE jobs DROP COLUMN IF EXISTS trigger_limit;
@@ -0,0 +1,4 @@
+-- +migrate Up
+-- Per-job overrides of the manual trigger rate limit and dedupe window; NULL uses the defaults
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS trigger_limit INTEGER;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS trigger_dedupe INTEGER;
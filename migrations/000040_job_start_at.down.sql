@@ -0,0 +1,2 @@
+-- +migrate Down
+ALTER TABLE jobs DROP COLUMN IF EXISTS start_at;
@@ -0,0 +1,3 @@
+-- +migrate Up
+-- Recurring jobs can begin firing at a future date
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS start_at TIMESTAMPTZ;
@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE tenant_settings DROP COLUMN IF EXISTS pause_reason;
+ALTER TABLE tenant_settings DROP COLUMN IF EXISTS paused_at;
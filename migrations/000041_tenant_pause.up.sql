@@ -0,0 +1,4 @@
+-- +migrate Up
+-- Operators can suspend dispatch of all of a tenant's jobs
+ALTER TABLE tenant_settings ADD COLUMN IF NOT EXISTS paused_at TIMESTAMPTZ;
+ALTER TABLE tenant_settings ADD COLUMN IF NOT EXISTS pause_reason TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE job_revisions DROP COLUMN IF EXISTS diff;
+ALTER TABLE job_revisions DROP COLUMN IF EXISTS changed_by;
@@ -0,0 +1,4 @@
+-- +migrate Up
+-- Revisions name the user who made the change and the fields it changed
+ALTER TABLE job_revisions ADD COLUMN IF NOT EXISTS changed_by UUID;
+ALTER TABLE job_revisions ADD COLUMN IF NOT EXISTS diff JSONB;
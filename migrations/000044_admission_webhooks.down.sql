@@ -0,0 +1,2 @@
+-- +migrate Down
+DROP TABLE IF EXISTS admission_webhooks;
@@ -0,0 +1,25 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS admission_webhooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    position INTEGER DEFAULT 0,
+    url TEXT NOT NULL,
+    headers JSONB,
+    failure_policy VARCHAR(10) NOT NULL DEFAULT 'fail',
+    enabled BOOLEAN DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admission_webhooks_tenant ON admission_webhooks(tenant_id);
+
+-- Same tenant isolation policy as the other tenant tables (000017)
+ALTER TABLE admission_webhooks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE admission_webhooks FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON admission_webhooks;
+CREATE POLICY tenant_isolation ON admission_webhooks
+    USING (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid);
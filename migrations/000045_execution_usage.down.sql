@@ -0,0 +1,9 @@
+-- +migrate Down
+ALTER TABLE job_history DROP COLUMN IF EXISTS retries;
+ALTER TABLE job_history DROP COLUMN IF EXISTS bytes_received;
+ALTER TABLE job_history DROP COLUMN IF EXISTS bytes_sent;
+ALTER TABLE job_history DROP COLUMN IF EXISTS wall_time;
+
+ALTER TABLE job_executions DROP COLUMN IF EXISTS bytes_received;
+ALTER TABLE job_executions DROP COLUMN IF EXISTS bytes_sent;
+ALTER TABLE job_executions DROP COLUMN IF EXISTS wall_time;
@@ -0,0 +1,10 @@
+-- +migrate Up
+-- Resources each execution used across its attempts, summed per job and day in history
+ALTER TABLE job_executions ADD COLUMN IF NOT EXISTS wall_time BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE job_executions ADD COLUMN IF NOT EXISTS bytes_sent BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE job_executions ADD COLUMN IF NOT EXISTS bytes_received BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE job_history ADD COLUMN IF NOT EXISTS wall_time BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE job_history ADD COLUMN IF NOT EXISTS bytes_sent BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE job_history ADD COLUMN IF NOT EXISTS bytes_received BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE job_history ADD COLUMN IF NOT EXISTS retries BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP TABLE IF EXISTS janitor_reports;
+
+ALTER TABLE jobs DROP COLUMN IF EXISTS not_found_since;
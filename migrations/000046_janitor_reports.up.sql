@@ -0,0 +1,19 @@
+-- +migrate Up
+-- When a job's endpoint started answering 404, kept across execution retention
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS not_found_since TIMESTAMPTZ;
+
+-- When each tenant was last sent its stale job report
+CREATE TABLE IF NOT EXISTS janitor_reports (
+    tenant_id UUID PRIMARY KEY,
+    sent_at TIMESTAMPTZ NOT NULL
+);
+
+-- Same tenant isolation policy as the other tenant tables (000017)
+ALTER TABLE janitor_reports ENABLE ROW LEVEL SECURITY;
+ALTER TABLE janitor_reports FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON janitor_reports;
+CREATE POLICY tenant_isolation ON janitor_reports
+    USING (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid);
@@ -0,0 +1,3 @@
+-- +migrate Up
+-- Secrets holding the client certificate jobs present to mutual TLS endpoints
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS tls JSONB;
@@ -0,0 +1,3 @@
+-- +migrate Up
+-- Whether the tenant's jobs may skip verifying the certificates of their endpoints
+ALTER TABLE tenant_settings ADD COLUMN IF NOT EXISTS allow_insecure_tls BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,5 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_history_tenant_timezone_date;
+DELETE FROM job_history WHERE timezone <> 'UTC';
+ALTER TABLE job_history DROP COLUMN IF EXISTS timezone;
+ALTER TABLE tenant_settings DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,5 @@
+-- +migrate Up
+-- History is kept per day in UTC and, for tenants with a timezone, per day in theirs too
+ALTER TABLE tenant_settings ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE job_history ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+CREATE INDEX IF NOT EXISTS idx_history_tenant_timezone_date ON job_history (tenant_id, timezone, date);
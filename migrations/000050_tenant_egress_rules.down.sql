@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE tenant_settings DROP COLUMN IF EXISTS egress_deny;
+ALTER TABLE tenant_settings DROP COLUMN IF EXISTS egress_allow;
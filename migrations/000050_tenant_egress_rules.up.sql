@@ -0,0 +1,4 @@
+-- +migrate Up
+-- Hosts, domains and address ranges the tenant's jobs may and may not call
+ALTER TABLE tenant_settings ADD COLUMN IF NOT EXISTS egress_allow JSONB;
+ALTER TABLE tenant_settings ADD COLUMN IF NOT EXISTS egress_deny JSONB;
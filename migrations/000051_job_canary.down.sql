@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE job_executions DROP COLUMN IF EXISTS canary;
+ALTER TABLE jobs DROP COLUMN IF EXISTS canary_execution_id;
@@ -0,0 +1,4 @@
+-- +migrate Up
+-- Canary executions run before a job's schedule starts and aren't counted in its history
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS canary_execution_id UUID;
+ALTER TABLE job_executions ADD COLUMN IF NOT EXISTS canary BOOLEAN NOT NULL DEFAULT FALSE;
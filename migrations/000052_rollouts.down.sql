@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE IF EXISTS rollout_jobs;
+DROP TABLE IF EXISTS rollouts;
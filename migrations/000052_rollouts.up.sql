@@ -0,0 +1,54 @@
+-- +migrate Up
+-- Rollouts change the schedule or endpoint of jobs sharing an external ID across tenants in
+-- hourly batches; they span tenants, so only their jobs are tenant rows
+CREATE TABLE IF NOT EXISTS rollouts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    external_id VARCHAR(255) NOT NULL,
+    schedule VARCHAR(100),
+    endpoint VARCHAR(500),
+    percent_per_hour INTEGER NOT NULL,
+    failure_threshold DOUBLE PRECISION NOT NULL,
+    min_executions INTEGER NOT NULL,
+    baseline_failure_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    total_jobs INTEGER NOT NULL,
+    applied_jobs INTEGER NOT NULL DEFAULT 0,
+    next_batch_at TIMESTAMPTZ,
+    health_since TIMESTAMPTZ NOT NULL,
+    halt_reason TEXT,
+    halted_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    rolled_back_at TIMESTAMPTZ,
+    reason TEXT,
+    created_by UUID,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rollouts_status ON rollouts(status);
+CREATE INDEX IF NOT EXISTS idx_rollouts_due ON rollouts(next_batch_at) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS rollout_jobs (
+    rollout_id UUID NOT NULL REFERENCES rollouts(id) ON DELETE CASCADE,
+    job_id UUID NOT NULL,
+    tenant_id UUID NOT NULL,
+    position INTEGER NOT NULL,
+    previous_schedule VARCHAR(100),
+    previous_endpoint VARCHAR(500),
+    applied_at TIMESTAMPTZ,
+    skipped BOOLEAN NOT NULL DEFAULT FALSE,
+    reverted_at TIMESTAMPTZ,
+    PRIMARY KEY (rollout_id, job_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rollout_jobs_job ON rollout_jobs(job_id);
+
+-- Same tenant isolation policy as the other tenant tables (000017)
+ALTER TABLE rollout_jobs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE rollout_jobs FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON rollout_jobs;
+CREATE POLICY tenant_isolation ON rollout_jobs
+    USING (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid)
+    WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+           OR tenant_id = NULLIF(current_setting('app.tenant_id', true), '')::uuid);
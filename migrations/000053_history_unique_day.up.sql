@@ -0,0 +1,35 @@
+-- +migrate Up
+-- Each job has one history record per day and timezone, which executions increment in place
+-- with an upsert. Records duplicated by concurrent executions are merged into the oldest.
+UPDATE job_history h SET
+    success_count = d.success_count,
+    failure_count = d.failure_count,
+    total_duration = d.total_duration,
+    avg_duration = d.total_duration / GREATEST(d.success_count + d.failure_count, 1),
+    min_duration = d.min_duration,
+    max_duration = d.max_duration,
+    wall_time = d.wall_time,
+    bytes_sent = d.bytes_sent,
+    bytes_received = d.bytes_received,
+    retries = d.retries
+FROM (
+    SELECT (ARRAY_AGG(id ORDER BY created_at, id))[1] AS id,
+        SUM(success_count) AS success_count, SUM(failure_count) AS failure_count,
+        SUM(total_duration) AS total_duration,
+        COALESCE(MIN(min_duration) FILTER (WHERE min_duration > 0), 0) AS min_duration,
+        MAX(max_duration) AS max_duration, SUM(wall_time) AS wall_time,
+        SUM(bytes_sent) AS bytes_sent, SUM(bytes_received) AS bytes_received, SUM(retries) AS retries
+    FROM job_history
+    GROUP BY job_id, timezone, date
+    HAVING COUNT(*) > 1
+) d
+WHERE h.id = d.id;
+DELETE FROM job_history h USING job_history k
+WHERE h.job_id = k.job_id AND h.timezone = k.timezone AND h.date = k.date
+    AND (k.created_at, k.id) < (h.created_at, h.id);
+
+-- Records kept as executions finished didn't count their runs
+UPDATE job_history SET total_runs = success_count + failure_count
+WHERE total_runs <> success_count + failure_count;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_history_job_timezone_date ON job_history (job_id, timezone, date);
@@ -0,0 +1,3 @@
+-- +migrate Up
+-- Buckets and prefixes in object storage the tenant's jobs may read and write
+ALTER TABLE tenant_settings ADD COLUMN IF NOT EXISTS storage_allow JSONB;
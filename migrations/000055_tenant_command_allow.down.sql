@@ -0,0 +1,2 @@
+-- +migrate Down
+ALTER TABLE tenant_settings DROP COLUMN IF EXISTS command_allow;
@@ -0,0 +1,3 @@
+-- +migrate Up
+-- Commands of COMMAND_ALLOWLIST the tenant's command jobs may run
+ALTER TABLE tenant_settings ADD COLUMN IF NOT EXISTS command_allow JSONB;